@@ -0,0 +1,304 @@
+package ci
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/browser"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/pkg/notify"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+func Command() *cli.Command {
+	subcommands := []*cli.Command{
+		statusCmd(),
+		logsCmd(),
+	}
+
+	return &cli.Command{
+		Name:        "ci",
+		Usage:       "GitLab CI pipelines for the current repository",
+		Subcommands: subcommands,
+		Before:      cmd.Require(cmd.RequireTool("git"), cmd.RequireGitRepo()),
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				if !cmd.ValidateSubcommand(c, subcommands) {
+					return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+				}
+				return nil
+			}
+			return prompt.SelectCommand(c, subcommands, "Select a subcommand:", cli.ShowSubcommandHelp)
+		},
+	}
+}
+
+// repoContext holds what the ci commands need to know about the current repository.
+type repoContext struct {
+	ctx       context.Context // run context, watching stops when it is stopped
+	client    *gitlab.Client
+	projectID string
+	branch    string
+	commit    string
+}
+
+// loadRepoContext detects the GitLab project, branch and commit of the current repository.
+func loadRepoContext(ctx context.Context) (*repoContext, error) {
+	projectID, err := git.ExtractProjectID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	head, err := git.GetCurrentBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if head.Detached {
+		return nil, fmt.Errorf("HEAD is %s, checkout a branch to see its pipeline", head)
+	}
+	commit, err := git.GetHeadCommit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client, err := gitlab.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &repoContext{ctx: ctx, client: client, projectID: projectID, branch: head.Branch, commit: commit}, nil
+}
+
+// statusCmd shows the latest pipeline of the current branch and offers follow-up actions.
+func statusCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "status",
+		Usage: "Show the latest pipeline and its jobs for the current branch",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "watch",
+				Aliases: []string{"w"},
+				Usage:   "Refresh until the pipeline finishes",
+			},
+			&cli.BoolFlag{
+				Name:  "web",
+				Usage: "Open the pipeline in the browser",
+			},
+			&cli.DurationFlag{
+				Name:  "interval",
+				Usage: "Refresh interval used by --watch",
+				Value: 5 * time.Second,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			rc, err := loadRepoContext(c.Context)
+			if err != nil {
+				return err
+			}
+
+			pipeline, jobs, err := latestPipeline(rc)
+			if err != nil {
+				return err
+			}
+
+			if c.Bool("web") {
+				return browser.Open(pipeline.WebURL)
+			}
+			if c.Bool("watch") {
+				return watchPipeline(rc, pipeline.ID, c.Duration("interval"), 0)
+			}
+			renderPipeline(rc, pipeline, jobs)
+
+			if !term.IsTerminal(int(os.Stdin.Fd())) {
+				return nil
+			}
+			return pipelineActions(rc, pipeline, jobs, c.Duration("interval"))
+		},
+	}
+}
+
+// latestPipeline returns the newest pipeline of the branch at HEAD with its
+// jobs, always asked to GitLab: a pipeline rerun for the same commit replaces
+// a finished one. Until GitLab created one for HEAD, the latest pipeline of
+// the branch is returned.
+func latestPipeline(rc *repoContext) (*gitlab.Pipeline, []gitlab.Job, error) {
+	pipeline, err := rc.client.LatestCommitPipeline(rc.projectID, rc.branch, rc.commit)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pipeline == nil {
+		if pipeline, err = rc.client.LatestPipeline(rc.projectID, rc.branch); err != nil {
+			return nil, nil, err
+		}
+		fmt.Fprintf(os.Stderr, "[!] No pipeline for %s yet, showing #%d of %s\n", git.ShortSHA(rc.commit), pipeline.ID, git.ShortSHA(pipeline.SHA))
+	}
+	jobs, err := rc.client.PipelineJobs(rc.projectID, pipeline.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pipeline, jobs, nil
+}
+
+// pipelineActions lets the user open, retry or watch the pipeline after it was rendered.
+func pipelineActions(rc *repoContext, pipeline *gitlab.Pipeline, jobs []gitlab.Job, interval time.Duration) error {
+	const (
+		actionOpen  = "Open in browser"
+		actionRetry = "Retry a failed job"
+		actionWatch = "Watch until completion"
+		actionQuit  = "Quit"
+	)
+
+	var failed []gitlab.Job
+	for _, job := range jobs {
+		if job.Status == "failed" {
+			failed = append(failed, job)
+		}
+	}
+
+	options := []string{actionOpen}
+	if len(failed) > 0 {
+		options = append(options, actionRetry)
+	}
+	if !gitlab.IsFinished(pipeline.Status) {
+		options = append(options, actionWatch)
+	}
+	options = append(options, actionQuit)
+
+	_, action, err := prompt.SelectWithFuzzy("What next?", options, actionQuit, false)
+	if err != nil {
+		return nil
+	}
+
+	switch action {
+	case actionOpen:
+		return browser.Open(pipeline.WebURL)
+	case actionRetry:
+		labels := make([]string, len(failed))
+		for i, job := range failed {
+			labels[i] = fmt.Sprintf("%s (%s)", job.Name, job.Stage)
+		}
+		idx, _, err := prompt.Select("Select job to retry:", labels, "")
+		if err != nil {
+			return fmt.Errorf("selection cancelled: %w", err)
+		}
+		job, err := rc.client.RetryJob(rc.projectID, failed[idx].ID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("[+] Retried job '%s' (new job #%d)\n", job.Name, job.ID)
+		return watchPipeline(rc, pipeline.ID, interval, job.ID)
+	case actionWatch:
+		return watchPipeline(rc, pipeline.ID, interval, 0)
+	}
+	return nil
+}
+
+// watchPipeline re-renders the pipeline every interval until it reaches a
+// terminal status. retried is the job just retried, 0 if none: the pipeline
+// keeps its previous status until GitLab picks the new job up, so a terminal
+// status only counts once the pipeline left it or the new job finished.
+func watchPipeline(rc *repoContext, pipelineID int, interval time.Duration, retried int) error {
+	for {
+		pipeline, err := rc.client.GetPipeline(rc.projectID, pipelineID)
+		if err != nil {
+			return err
+		}
+		jobs, err := rc.client.PipelineJobs(rc.projectID, pipelineID)
+		if err != nil {
+			return err
+		}
+
+		if !ui.Plain() && !ui.Accessible() {
+			fmt.Print("\033[H\033[2J")
+		}
+		renderPipeline(rc, pipeline, jobs)
+
+		if retried != 0 && (!gitlab.IsFinished(pipeline.Status) || retriedJobFinished(jobs, retried)) {
+			retried = 0
+		}
+		if gitlab.IsFinished(pipeline.Status) && retried == 0 {
+			notify.Announce(notify.EventCI, notify.Message{
+				Title: fmt.Sprintf("Pipeline #%d %s", pipeline.ID, pipeline.Status),
+				Text:  fmt.Sprintf("%s (%s)\n%s", rc.projectID, rc.branch, pipeline.WebURL),
+				OK:    pipeline.Status == "success",
+			})
+			if pipeline.Status != "success" {
+				return fmt.Errorf("pipeline #%d finished with status %s", pipeline.ID, pipeline.Status)
+			}
+			return nil
+		}
+		fmt.Printf("\n%s\n", ui.Colorize(ui.Gray, fmt.Sprintf("Refreshing every %s, Ctrl+C to stop...", interval)))
+		if !rc.wait(interval) {
+			fmt.Printf("[!] Stopped watching pipeline #%d (%s)\n", pipeline.ID, pipeline.Status)
+			return nil
+		}
+	}
+}
+
+// retriedJobFinished reports whether the job id is among jobs and finished.
+func retriedJobFinished(jobs []gitlab.Job, id int) bool {
+	for _, job := range jobs {
+		if job.ID == id {
+			return gitlab.IsFinished(job.Status)
+		}
+	}
+	return false
+}
+
+// wait sleeps for d, returning false early when the user pressed Ctrl+C.
+func (rc *repoContext) wait(d time.Duration) bool {
+	select {
+	case <-cmd.Stopped(rc.ctx):
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// renderPipeline prints the pipeline summary and its jobs grouped by stage order.
+func renderPipeline(rc *repoContext, pipeline *gitlab.Pipeline, jobs []gitlab.Job) {
+	fmt.Printf("Project: %s\n", rc.projectID)
+	fmt.Printf("Branch:  %s\n", rc.branch)
+	fmt.Printf("Pipeline #%d %s  %s\n", pipeline.ID, colorStatus(pipeline.Status), pipeline.WebURL)
+	if pipeline.SHA != rc.commit {
+		fmt.Printf("%s\n", ui.Colorize(ui.Yellow, fmt.Sprintf("[!] Pipeline is for %s, HEAD is %s (not pushed yet?)", git.ShortSHA(pipeline.SHA), git.ShortSHA(rc.commit))))
+	}
+	fmt.Println()
+
+	// GitLab returns jobs newest first; print them in pipeline order
+	maxName := 0
+	for _, job := range jobs {
+		if len(job.Name) > maxName {
+			maxName = len(job.Name)
+		}
+	}
+	for i := len(jobs) - 1; i >= 0; i-- {
+		job := jobs[i]
+		duration := ""
+		if job.Duration > 0 {
+			duration = (time.Duration(job.Duration) * time.Second).String()
+		}
+		fmt.Printf("  %-12s %-*s  %s  %s\n", job.Stage, maxName, job.Name, colorStatus(job.Status), duration)
+	}
+}
+
+func colorStatus(status string) string {
+	color := ui.Gray
+	switch status {
+	case "success":
+		color = ui.Green
+	case "failed":
+		color = ui.Red
+	case "running":
+		color = ui.Blue
+	case "pending", "created", "preparing", "waiting_for_resource", "scheduled":
+		color = ui.Yellow
+	case "manual":
+		color = ui.Cyan
+	}
+	return ui.Colorize(color, fmt.Sprintf("%-8s", status))
+}
@@ -0,0 +1,195 @@
+package ci
+
+import (
+	"bytes"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// sectionMarker matches GitLab's collapsible section markers in job traces.
+	sectionMarker = regexp.MustCompile(`section_(?:start|end):\d+:[^\r\n]*\r\x1b\[0K`)
+	// ansiEscape matches ANSI escape sequences, stripped when output is not a terminal.
+	ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+)
+
+// logsCmd lists the jobs of the current branch's latest pipeline and streams the selected job's log.
+func logsCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "logs",
+		Usage:     "Stream the log of a job from the current branch's latest pipeline",
+		ArgsUsage: "[job name]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "no-follow",
+				Usage: "Print the current log and exit instead of following until the job finishes",
+			},
+			&cli.DurationFlag{
+				Name:  "interval",
+				Usage: "Polling interval while following",
+				Value: 2 * time.Second,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			rc, err := loadRepoContext(c.Context)
+			if err != nil {
+				return err
+			}
+
+			pipeline, jobs, err := latestPipeline(rc)
+			if err != nil {
+				return err
+			}
+			if len(jobs) == 0 {
+				return fmt.Errorf("pipeline #%d has no jobs", pipeline.ID)
+			}
+
+			job, err := pickJob(jobs, c.Args().First())
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "Job #%d %s (%s) %s\n\n", job.ID, job.Name, job.Stage, colorStatus(job.Status))
+			return streamTrace(rc, job.ID, !c.Bool("no-follow"), c.Duration("interval"))
+		},
+	}
+}
+
+// pickJob returns the job matching name, or prompts the user to select one.
+// Failed jobs are offered as default since they are usually the reason to look at logs.
+func pickJob(jobs []gitlab.Job, name string) (*gitlab.Job, error) {
+	if name != "" {
+		for i := range jobs {
+			if jobs[i].Name == name {
+				return &jobs[i], nil
+			}
+		}
+		return nil, fmt.Errorf("job '%s' not found in the latest pipeline", name)
+	}
+
+	labels := make([]string, len(jobs))
+	defaultLabel := ""
+	for i, job := range jobs {
+		labels[i] = fmt.Sprintf("%-10s %s (%s)", job.Status, job.Name, job.Stage)
+		if job.Status == "failed" && defaultLabel == "" {
+			defaultLabel = labels[i]
+		}
+	}
+	idx, _, err := prompt.Select("Select job:", labels, defaultLabel)
+	if err != nil {
+		return nil, fmt.Errorf("selection cancelled: %w", err)
+	}
+	return &jobs[idx], nil
+}
+
+// streamTrace prints the job trace and, when follow is set, keeps polling for
+// new output until the job reaches a terminal status.
+func streamTrace(rc *repoContext, jobID int, follow bool, interval time.Duration) error {
+	t := &traceStream{}
+	for {
+		if err := t.poll(rc, jobID); err != nil {
+			return err
+		}
+		if !follow {
+			t.flush()
+			return nil
+		}
+
+		job, err := rc.client.GetJob(rc.projectID, jobID)
+		if err != nil {
+			return err
+		}
+		if gitlab.IsFinished(job.Status) {
+			// Fetch once more so the tail written after the status change is not lost
+			t.poll(rc, jobID)
+			t.flush()
+			fmt.Fprintf(os.Stderr, "\nJob #%d finished: %s\n", job.ID, colorStatus(job.Status))
+			if job.Status == "failed" {
+				return fmt.Errorf("job '%s' failed", job.Name)
+			}
+			return nil
+		}
+		if !rc.wait(interval) {
+			t.flush()
+			fmt.Fprintf(os.Stderr, "\n[!] Stopped following job #%d (%s)\n", job.ID, job.Status)
+			return nil
+		}
+	}
+}
+
+// traceStream prints a growing job trace, asking GitLab only for the bytes
+// after offset. A chunk may end in the middle of an escape sequence or a
+// section marker: that tail is held back until the next chunk completes it,
+// so cleanTrace sees it whole.
+type traceStream struct {
+	offset  int
+	pending []byte
+}
+
+// poll prints what was added to the trace since the last poll.
+func (t *traceStream) poll(rc *repoContext, jobID int) error {
+	chunk, err := rc.client.JobTraceFrom(rc.projectID, jobID, t.offset)
+	if err != nil {
+		return err
+	}
+	t.offset += len(chunk)
+	fmt.Print(t.add(chunk))
+	return nil
+}
+
+// add returns chunk cleaned, after the tail held back from the last chunk
+// and without the tail the next one may complete.
+func (t *traceStream) add(chunk []byte) string {
+	data := append(t.pending, chunk...)
+	cut := len(data) - len(incompleteTail(data))
+	t.pending = append([]byte(nil), data[cut:]...)
+	return cleanTrace(data[:cut])
+}
+
+// flush prints the tail held back, once no chunk will complete it.
+func (t *traceStream) flush() {
+	fmt.Print(cleanTrace(t.pending))
+	t.pending = nil
+}
+
+var (
+	// cutEscape matches the end of a chunk cut in an escape sequence or a
+	// section marker (up to its closing "\r\x1b[0K").
+	cutEscape = regexp.MustCompile(`(?:section_(?:start|end):[^\r\n]*(?:\r(?:\x1b(?:\[0?)?)?)?|\x1b(?:\[[0-9;]*)?)$`)
+	// markerNames start the section markers, to hold back a chunk ending in
+	// "sect" too.
+	markerNames = []string{"section_start:", "section_end:"}
+)
+
+// incompleteTail returns the end of data that may be the start of an escape
+// sequence or section marker continued in the next chunk.
+func incompleteTail(data []byte) []byte {
+	tail := []byte{}
+	if loc := cutEscape.FindIndex(data); loc != nil {
+		tail = data[loc[0]:]
+	}
+	for _, name := range markerNames {
+		for n := len(name) - 1; n > len(tail); n-- {
+			if bytes.HasSuffix(data, []byte(name[:n])) {
+				tail = data[len(data)-n:]
+				break
+			}
+		}
+	}
+	return tail
+}
+
+func cleanTrace(data []byte) string {
+	out := sectionMarker.ReplaceAll(data, nil)
+	if !ui.ColorEnabled() {
+		out = ansiEscape.ReplaceAll(out, nil)
+	}
+	return string(out)
+}
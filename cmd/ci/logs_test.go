@@ -0,0 +1,62 @@
+package ci
+
+import (
+	"cli-aio/internal/ui"
+	"strings"
+	"testing"
+)
+
+// trace is a GitLab job log with section markers and colors.
+const trace = "\x1b[0KRunning with gitlab-runner 16.5.0\n" +
+	"section_start:1700000000:prepare_script\r\x1b[0K\x1b[0K\x1b[36;1mPreparing the environment\x1b[0;m\n" +
+	"Running on runner-abc\n" +
+	"section_end:1700000001:prepare_script\r\x1b[0K" +
+	"section_start:1700000002:step_script\r\x1b[0K\x1b[32;1m$ go test ./...\x1b[0;m\n" +
+	"ok  \tcli-aio\t0.5s\n" +
+	"section_end:1700000003:step_script\r\x1b[0K\x1b[32;1mJob succeeded\x1b[0;m\n"
+
+// However the trace is cut into chunks, it prints the same as cleaned whole.
+func TestTraceStreamChunks(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	for _, color := range []bool{true, false} {
+		// Colors are kept on a terminal, stripped in plain mode
+		ui.SetPlain(!color)
+		want := cleanTrace([]byte(trace))
+		if strings.Contains(want, "section_") {
+			t.Fatalf("cleanTrace left a section marker: %q", want)
+		}
+		for size := 1; size <= len(trace); size++ {
+			s := &traceStream{}
+			var got strings.Builder
+			for start := 0; start < len(trace); start += size {
+				got.WriteString(s.add([]byte(trace[start:min(start+size, len(trace))])))
+			}
+			got.WriteString(cleanTrace(s.pending))
+			if got.String() != want {
+				t.Fatalf("chunks of %d bytes (color %v) print\n%q\nwant\n%q", size, color, got.String(), want)
+			}
+		}
+	}
+}
+
+func TestIncompleteTail(t *testing.T) {
+	tests := []struct {
+		data string
+		tail string
+	}{
+		{"done\n", ""},
+		{"done\x1b", "\x1b"},
+		{"done\x1b[32;", "\x1b[32;"},
+		{"done\x1b[32;1m", ""},
+		{"done\nsect", "sect"},
+		{"done\nsection_end:17000", "section_end:17000"},
+		{"done\nsection_start:1:build\r\x1b[", "section_start:1:build\r\x1b["},
+		{"done\nsection_start:1:build\r\x1b[0K", ""},
+		{"section_start:1:build\r\x1b[0Kok", ""},
+	}
+	for _, tt := range tests {
+		if tail := string(incompleteTail([]byte(tt.data))); tail != tt.tail {
+			t.Errorf("incompleteTail(%q) = %q, want %q", tt.data, tail, tt.tail)
+		}
+	}
+}
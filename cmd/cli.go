@@ -1,17 +1,56 @@
 package cmd
 
 import (
+	"cli-aio/cmd/ci"
+	"cli-aio/cmd/daemon"
+	"cli-aio/cmd/db"
+	"cli-aio/cmd/devtool"
+	"cli-aio/cmd/do"
+	"cli-aio/cmd/docs"
+	"cli-aio/cmd/format"
+	"cli-aio/cmd/gen"
 	"cli-aio/cmd/gencmd"
 	"cli-aio/cmd/git"
+	"cli-aio/cmd/gl"
+	"cli-aio/cmd/grep"
+	"cli-aio/cmd/jira"
+	"cli-aio/cmd/newproj"
+	"cli-aio/cmd/ping"
 	"cli-aio/cmd/prj"
+	"cli-aio/cmd/queue"
+	"cli-aio/cmd/release"
+	"cli-aio/cmd/remind"
+	"cli-aio/cmd/scratch"
+	"cli-aio/cmd/secrets"
+	"cli-aio/cmd/shellwrap"
+	"cli-aio/cmd/snip"
+	"cli-aio/cmd/task"
+	"cli-aio/cmd/tour"
 	"cli-aio/cmd/version"
 	"cli-aio/cmd/ztag"
+	internalcmd "cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/crash"
+	"cli-aio/internal/pkg/explain"
+	gitpkg "cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/notify"
+	"cli-aio/internal/pkg/offline"
+	"cli-aio/internal/pkg/plugin"
+	"cli-aio/internal/pkg/profile"
+	"cli-aio/internal/pkg/project"
+	remindpkg "cli-aio/internal/pkg/remind"
 	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
 )
 
 // findCommand recursively searches for a command in the command tree
@@ -114,6 +153,61 @@ func showUnknownCommandWarning(c *cli.Context, commands []*cli.Command, isSubcom
 	}
 }
 
+// runPlugin executes an external aio-<name> plugin, passing the plugin context as JSON.
+func runPlugin(c *cli.Context, pluginPath string, name string, args []string) error {
+	ctx := plugin.Context{
+		ContextVersion: plugin.ContextVersion,
+		AioVersion:     version.Version,
+		Name:           name,
+		Args:           args,
+		Interactive:    c.Bool("interactive") || term.IsTerminal(int(os.Stdin.Fd())),
+	}
+	if wd, err := os.Getwd(); err == nil {
+		ctx.Cwd = wd
+	}
+	if dir, err := config.Dir(); err == nil {
+		ctx.ConfigDir = dir
+	}
+	if cfg, err := config.Load(); err == nil {
+		ctx.GitLabURL = cfg.GitLab.BaseURL
+	}
+	return plugin.Run(pluginPath, ctx)
+}
+
+// chdir changes the working directory for -C, expanding a leading ~.
+func chdir(dir string) error {
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("cannot determine home directory: %w", err)
+		}
+		dir = filepath.Join(home, dir[1:])
+	}
+	if err := os.Chdir(dir); err != nil {
+		return fmt.Errorf("cannot change directory to %s: %w", dir, err)
+	}
+	return nil
+}
+
+// bannerShown keeps chained commands ('aio do') from repeating the banner.
+var bannerShown bool
+
+// showReminderBanner prints due reminders to stderr. It only reads a small local
+// file and ignores every error so it never blocks or fails the actual command.
+func showReminderBanner() {
+	if bannerShown || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return
+	}
+	bannerShown = true
+	store, err := remindpkg.Load()
+	if err != nil {
+		return
+	}
+	for _, r := range remindpkg.Due(store, time.Now()) {
+		fmt.Fprintf(os.Stderr, "[!] Reminder #%d: %s (run 'aio remind done %d')\n", r.ID, r.Message, r.ID)
+	}
+}
+
 // Execute initializes and runs the CLI application.
 // This is the central wiring point where all commands are registered.
 // To add a new command:
@@ -121,14 +215,43 @@ func showUnknownCommandWarning(c *cli.Context, commands []*cli.Command, isSubcom
 //  2. Implement a Command() function that returns *cli.Command
 //  3. Import the package here and add it to the Commands slice
 func Execute() error {
+	// A panic ends with a crash report instead of a raw stack trace
+	crash.Setup(crash.Build{Version: version.Version, Commit: version.GitCommit, BuildTime: version.BuildTime}, os.Args[1:])
+	defer crash.Guard()
+
 	commands := []*cli.Command{
 		version.Command(),
 		ztag.Command(),
 		git.Command(),
 		gencmd.Command(),
 		prj.Command(),
+		format.Command(),
+		ci.Command(),
+		gl.Command(),
+		jira.Command(),
+		secrets.Command(),
+		remind.Command(),
+		snip.Command(),
+		scratch.Command(),
+		db.Command(),
+		newproj.Command(),
+		gen.Command(),
+		task.Command(),
+		ping.Command(),
+		release.Command(),
+		docs.Command(),
+		do.Command(),
+		daemon.Command(),
+		queue.Command(),
+		grep.Command(),
+		shellwrap.Command(),
+		devtool.Command(),
+		tour.Command(),
 	}
 
+	// Prompt for missing required flags in interactive mode, for every command
+	internalcmd.InstallFlagPrompts(commands)
+
 	app := &cli.App{
 		Name:  "cli-aio",
 		Usage: "A modular CLI application built with urfave/cli",
@@ -143,6 +266,70 @@ func Execute() error {
 				Usage:   "Force enable interactive mode (auto-enabled when params missing)",
 				Value:   false,
 			},
+			&cli.BoolFlag{
+				Name:    "plain",
+				Usage:   "Plain output for scripts: no colors, no prompts, bare values (auto-enabled when stdout is piped)",
+				EnvVars: []string{ui.PlainEnv},
+			},
+			&cli.BoolFlag{
+				Name:    "a11y",
+				Usage:   "Accessible mode for screen readers and dumb terminals: numbered plain-text menus, no colors or redraws",
+				EnvVars: []string{ui.AccessibleEnv, "A11Y"},
+			},
+			&cli.BoolFlag{
+				Name:    "offline",
+				Usage:   "Don't touch the network: use cached tags and queue pushes, releases and notifications",
+				EnvVars: []string{offline.Env},
+			},
+			&cli.BoolFlag{
+				Name:    "explain",
+				Usage:   "Print the equivalent git commands and curl calls to stderr as they run",
+				EnvVars: []string{explain.Env},
+			},
+			&cli.BoolFlag{
+				Name:    "profile",
+				Usage:   "Print where the time went after the command: git calls, API calls and prompts",
+				EnvVars: []string{profile.Env},
+			},
+			&cli.StringFlag{
+				Name:    "chdir",
+				Aliases: []string{"C"},
+				Usage:   "Run as if aio was started in `path` (like git -C)",
+			},
+		},
+		// Before runs ahead of every command
+		Before: func(c *cli.Context) error {
+			// Plain mode is decided once here; -i keeps prompts even when piped
+			if c.Bool("plain") {
+				ui.SetPlain(true)
+			} else if c.Bool("interactive") {
+				ui.SetPlain(false)
+			}
+			if c.Bool("a11y") {
+				ui.SetAccessible(true)
+			} else if cfg, err := config.Load(); err == nil && cfg.Prompt.Accessible {
+				ui.SetAccessible(true)
+			}
+			if c.Bool("offline") {
+				offline.Set(true)
+			}
+			if c.Bool("explain") {
+				explain.Set(true)
+			}
+			if c.Bool("profile") {
+				profile.Set(true)
+			}
+			profile.Begin(c.Args().Slice())
+			if dir := c.String("chdir"); dir != "" {
+				if err := chdir(dir); err != nil {
+					return err
+				}
+			}
+			showReminderBanner()
+			if !ui.Plain() {
+				notify.StartCommand(c.Args().Slice())
+			}
+			return nil
 		},
 		// Action is called when no command is provided.
 		// It allows interactive selection of commands.
@@ -159,8 +346,18 @@ func Execute() error {
 					return nil
 				}
 
-				// Unknown command - show warning
+				// Unknown top-level command provided by an aio-<name> executable on PATH
+				if foundCmd == nil {
+					if pluginPath, ok := plugin.Find(path[0]); ok {
+						return runPlugin(c, pluginPath, path[0], path[1:])
+					}
+				}
+
+				// Unknown command - show warning and the closest command
 				showUnknownCommandWarning(c, commands, false)
+				if foundCmd == nil && internalcmd.OfferSuggestion(c, nil, path[0], path[1:]) {
+					return nil
+				}
 				return fmt.Errorf("unknown command: %s", strings.Join(path, " "))
 			}
 
@@ -186,11 +383,36 @@ func Execute() error {
 				os.Exit(1)
 			}
 
+			// Commands passing on the exit status of a process that already reported the failure
+			var exitCoder cli.ExitCoder
+			if errors.As(err, &exitCoder) && errMsg == "" {
+				notify.FinishCommand(err)
+				profile.Finish()
+				os.Exit(exitCoder.ExitCode())
+			}
+
 			// For other errors, show the error message
 			fmt.Fprintf(os.Stderr, "[-] Error: %v\n", err)
+			notify.FinishCommand(err)
+			profile.Finish()
 			os.Exit(1)
 		},
 	}
 
-	return app.Run(os.Args)
+	// Ctrl+C stops commands between steps, then cancels git processes and API calls
+	ctx, release := internalcmd.WithInterrupts(context.Background())
+	defer release()
+	// Commands share one handle on the project store; os.Exit on failure
+	// skips this, with nothing left uncommitted
+	defer project.CloseBackend()
+	if err := gitpkg.UseRunnerFromEnv(); err != nil {
+		return err
+	}
+	if err := app.RunContext(ctx, os.Args); err != nil {
+		return err
+	}
+	// Failures are announced by ExitErrHandler, which exits
+	notify.FinishCommand(nil)
+	profile.Finish()
+	return nil
 }
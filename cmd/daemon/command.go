@@ -0,0 +1,187 @@
+package daemon
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/daemon"
+	"cli-aio/internal/prompt"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// defaultInterval is how often cached values are refreshed.
+const defaultInterval = time.Minute
+
+func Command() *cli.Command {
+	subcommands := []*cli.Command{
+		startCmd(),
+		runCmd(),
+		stopCmd(),
+		statusCmd(),
+	}
+
+	return &cli.Command{
+		Name:        "daemon",
+		Usage:       "Background process keeping branch lists, MRs, pipelines and project scans warm",
+		Subcommands: subcommands,
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				if !cmd.ValidateSubcommand(c, subcommands) {
+					return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+				}
+				return nil
+			}
+			return prompt.SelectCommand(c, subcommands, "Select a subcommand:", cli.ShowSubcommandHelp)
+		},
+	}
+}
+
+func intervalFlag() cli.Flag {
+	return &cli.DurationFlag{
+		Name:  "interval",
+		Usage: "How often cached values are refreshed",
+		Value: defaultInterval,
+	}
+}
+
+// startCmd runs the daemon detached, logging to daemon.log.
+func startCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "start",
+		Usage: "Start the daemon in the background",
+		Flags: []cli.Flag{intervalFlag()},
+		Action: func(c *cli.Context) error {
+			if daemon.Running() {
+				fmt.Println("[+] Daemon is already running")
+				return nil
+			}
+			exe, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("cannot locate the aio executable: %w", err)
+			}
+			logPath, err := daemon.LogPath()
+			if err != nil {
+				return err
+			}
+			logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+			if err != nil {
+				return fmt.Errorf("failed to open %s: %w", logPath, err)
+			}
+			defer logFile.Close()
+
+			run := exec.Command(exe, "--plain", "daemon", "run", "--detached", "--interval", c.Duration("interval").String())
+			run.Stdout = logFile
+			run.Stderr = logFile
+			if err := run.Start(); err != nil {
+				return fmt.Errorf("failed to start daemon: %w", err)
+			}
+
+			// Wait for the socket so the next command already benefits
+			for i := 0; i < 50 && !daemon.Running(); i++ {
+				time.Sleep(100 * time.Millisecond)
+			}
+			if !daemon.Running() {
+				return fmt.Errorf("daemon did not start, see %s", logPath)
+			}
+			fmt.Printf("[+] Daemon started (pid %d, log %s)\n", run.Process.Pid, logPath)
+			return nil
+		},
+	}
+}
+
+// runCmd runs the daemon in the foreground.
+func runCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "run",
+		Usage: "Run the daemon in the foreground",
+		Flags: []cli.Flag{
+			intervalFlag(),
+			&cli.BoolFlag{
+				Name:   "detached",
+				Usage:  "Started by 'daemon start': keep running when the terminal closes",
+				Hidden: true,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.Duration("interval") <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+			if c.Bool("detached") {
+				signal.Ignore(syscall.SIGHUP)
+			}
+			l, err := daemon.Listen()
+			if err != nil {
+				return err
+			}
+			// Ctrl+C and SIGTERM still remove the socket
+			terminate := make(chan os.Signal, 1)
+			signal.Notify(terminate, syscall.SIGTERM)
+			go func() {
+				select {
+				case <-cmd.Stopped(c.Context):
+				case <-terminate:
+				}
+				l.Close()
+			}()
+
+			// The server logs to daemon.log, where a detached daemon's output goes too
+			if !c.Bool("detached") {
+				fmt.Printf("[+] Daemon listening on %s (pid %d, refresh every %s)\n", l.Addr(), os.Getpid(), c.Duration("interval"))
+			}
+			server := &daemon.Server{Interval: c.Duration("interval")}
+			return server.Serve(l)
+		},
+	}
+}
+
+func stopCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "stop",
+		Usage: "Stop the running daemon",
+		Action: func(c *cli.Context) error {
+			if err := daemon.Stop(); err != nil {
+				return err
+			}
+			fmt.Println("[+] Daemon stopped")
+			return nil
+		},
+	}
+}
+
+// statusCmd lists the cached values and their age.
+func statusCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "status",
+		Usage: "Show whether the daemon runs and what it has cached",
+		Action: func(c *cli.Context) error {
+			if !daemon.Running() {
+				fmt.Println("[!] Daemon is not running, start it with 'aio daemon start'")
+				return nil
+			}
+			entries, err := daemon.Entries()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("[+] Daemon is running, %d cached value(s)\n", len(entries))
+			now := time.Now()
+			for _, e := range entries {
+				age := "not fetched yet"
+				if !e.UpdatedAt.IsZero() {
+					age = fmt.Sprintf("%s ago", now.Sub(e.UpdatedAt).Round(time.Second))
+				}
+				line := fmt.Sprintf("  %-13s %-40s %s", e.Kind, strings.Join(e.Args, " "), age)
+				if e.Error != "" {
+					line += "  [-] " + e.Error
+				}
+				fmt.Println(line)
+			}
+			return nil
+		},
+	}
+}
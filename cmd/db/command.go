@@ -0,0 +1,279 @@
+package db
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/dbprofile"
+	"cli-aio/internal/pkg/secrets"
+	"cli-aio/internal/prompt"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Command returns the db command. Without a subcommand it opens the profile
+// selector and connects, which is the most common use.
+func Command() *cli.Command {
+	connect := connectCmd()
+	subcommands := []*cli.Command{
+		connect,
+		dsnCmd(),
+		addCmd(),
+		listCmd(),
+		removeCmd(),
+	}
+
+	return &cli.Command{
+		Name:        "db",
+		Usage:       "Named database connection profiles per project",
+		Subcommands: subcommands,
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				if !cmd.ValidateSubcommand(c, subcommands) {
+					return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+				}
+				return nil
+			}
+			return connect.Action(c)
+		},
+	}
+}
+
+// selectProfile returns the profile named by the first argument or lets the user pick one.
+func selectProfile(c *cli.Context) (*dbprofile.Profile, string, error) {
+	projectKey, err := dbprofile.ProjectKey(c.Context)
+	if err != nil {
+		return nil, "", err
+	}
+	store, err := dbprofile.Load()
+	if err != nil {
+		return nil, "", err
+	}
+	profiles := store.Profiles(projectKey)
+	if len(profiles) == 0 {
+		return nil, "", fmt.Errorf("no database profiles for %s, use 'aio db add' to create one", projectKey)
+	}
+
+	if name := c.Args().First(); name != "" {
+		p, ok := store.Find(projectKey, name)
+		if !ok {
+			return nil, "", fmt.Errorf("profile '%s' not found for %s", name, projectKey)
+		}
+		return p, projectKey, nil
+	}
+
+	labels := make([]string, len(profiles))
+	for i, p := range profiles {
+		labels[i] = profileLabel(p)
+	}
+	idx, _, err := prompt.Select("Select database profile:", labels, "")
+	if err != nil {
+		return nil, "", fmt.Errorf("selection cancelled: %w", err)
+	}
+	return &profiles[idx], projectKey, nil
+}
+
+func profileLabel(p dbprofile.Profile) string {
+	return fmt.Sprintf("%-16s %-9s %s:%d/%s", p.Name, p.Driver, p.Host, p.Port, p.Database)
+}
+
+// profilePassword reads the profile's password from the secrets store (empty when unset).
+func profilePassword(p *dbprofile.Profile) (string, error) {
+	if p.PasswordSecret == "" {
+		return "", nil
+	}
+	return secrets.Get(p.PasswordSecret)
+}
+
+func connectCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "connect",
+		Usage:     "Launch psql/mysql/redis-cli for a profile",
+		ArgsUsage: "[profile]",
+		Action: func(c *cli.Context) error {
+			p, _, err := selectProfile(c)
+			if err != nil {
+				return err
+			}
+			password, err := profilePassword(p)
+			if err != nil {
+				return err
+			}
+
+			bin, args, env := p.ClientCommand(password)
+			if _, err := exec.LookPath(bin); err != nil {
+				return fmt.Errorf("%s is not installed (needed for %s profiles)", bin, p.Driver)
+			}
+
+			fmt.Fprintf(os.Stderr, "-> Connecting to %s (%s)\n", p.Name, p.Driver)
+			client := exec.Command(bin, args...)
+			client.Env = append(os.Environ(), env...)
+			client.Stdin = os.Stdin
+			client.Stdout = os.Stdout
+			client.Stderr = os.Stderr
+			return client.Run()
+		},
+	}
+}
+
+func dsnCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "dsn",
+		Usage:     "Print the connection string of a profile (password masked unless --reveal)",
+		ArgsUsage: "[profile]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "reveal",
+				Usage: "Print the real password",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			p, _, err := selectProfile(c)
+			if err != nil {
+				return err
+			}
+			password, err := profilePassword(p)
+			if err != nil {
+				return err
+			}
+			if password != "" && !c.Bool("reveal") {
+				password = "****"
+			}
+			fmt.Println(p.DSN(password))
+			return nil
+		},
+	}
+}
+
+func addCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "add",
+		Usage:     "Add or update a profile for the current project",
+		ArgsUsage: "[profile]",
+		Action: func(c *cli.Context) error {
+			projectKey, err := dbprofile.ProjectKey(c.Context)
+			if err != nil {
+				return err
+			}
+
+			name := c.Args().First()
+			if name == "" {
+				if name, err = prompt.Input("Profile name:", "local", true); err != nil {
+					return fmt.Errorf("input cancelled: %w", err)
+				}
+			}
+			_, driver, err := prompt.SelectWithFuzzy("Driver:", dbprofile.Drivers, dbprofile.DriverPostgres, false)
+			if err != nil {
+				return fmt.Errorf("selection cancelled: %w", err)
+			}
+			host, err := prompt.Input("Host:", "localhost", true)
+			if err != nil {
+				return fmt.Errorf("input cancelled: %w", err)
+			}
+			portStr, err := prompt.Input("Port:", strconv.Itoa(dbprofile.DefaultPort(driver)), true)
+			if err != nil {
+				return fmt.Errorf("input cancelled: %w", err)
+			}
+			port, err := strconv.Atoi(portStr)
+			if err != nil {
+				return fmt.Errorf("invalid port: %s", portStr)
+			}
+			user, err := prompt.Input("User (optional):", "", false)
+			if err != nil {
+				return fmt.Errorf("input cancelled: %w", err)
+			}
+			database, err := prompt.Input("Database (optional):", "", false)
+			if err != nil {
+				return fmt.Errorf("input cancelled: %w", err)
+			}
+
+			p := dbprofile.Profile{
+				Name:     name,
+				Driver:   driver,
+				Host:     host,
+				Port:     port,
+				User:     user,
+				Database: database,
+			}
+
+			if withPassword, _ := prompt.Confirm("Store a password in the secrets store?", true); withPassword {
+				password, err := prompt.Password("Password:")
+				if err != nil {
+					return fmt.Errorf("input cancelled: %w", err)
+				}
+				p.PasswordSecret = dbprofile.SecretName(projectKey, name)
+				if err := secrets.Set(p.PasswordSecret, password); err != nil {
+					return err
+				}
+			}
+
+			store, err := dbprofile.Load()
+			if err != nil {
+				return err
+			}
+			store.Put(projectKey, p)
+			if err := dbprofile.Save(store); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Saved profile '%s' for %s\n", name, projectKey)
+			return nil
+		},
+	}
+}
+
+func listCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List profiles of the current project",
+		Action: func(c *cli.Context) error {
+			projectKey, err := dbprofile.ProjectKey(c.Context)
+			if err != nil {
+				return err
+			}
+			store, err := dbprofile.Load()
+			if err != nil {
+				return err
+			}
+			profiles := store.Profiles(projectKey)
+			if len(profiles) == 0 {
+				fmt.Printf("[!] No database profiles for %s\n", projectKey)
+				return nil
+			}
+			for _, p := range profiles {
+				fmt.Println(profileLabel(p))
+			}
+			return nil
+		},
+	}
+}
+
+func removeCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "rm",
+		Usage:     "Remove a profile (and its stored password)",
+		ArgsUsage: "[profile]",
+		Action: func(c *cli.Context) error {
+			p, projectKey, err := selectProfile(c)
+			if err != nil {
+				return err
+			}
+			store, err := dbprofile.Load()
+			if err != nil {
+				return err
+			}
+			store.Remove(projectKey, p.Name)
+			if err := dbprofile.Save(store); err != nil {
+				return err
+			}
+			if p.PasswordSecret != "" {
+				if _, err := secrets.Delete(p.PasswordSecret); err != nil {
+					fmt.Printf("[!] Warning: Failed to remove password secret: %v\n", err)
+				}
+			}
+			fmt.Printf("[+] Removed profile '%s'\n", p.Name)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,20 @@
+package devtool
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+// Command returns the devtool command, holding the tools used to develop and
+// check aio itself. It's hidden from the help of users.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:   "devtool",
+		Usage:  "Tools for developing aio",
+		Hidden: true,
+		Subcommands: []*cli.Command{
+			goldenCommand(),
+			mkrepoCommand(),
+			crashCommand(),
+		},
+	}
+}
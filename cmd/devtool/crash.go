@@ -0,0 +1,29 @@
+package devtool
+
+import (
+	"cli-aio/internal/cmd"
+
+	"github.com/urfave/cli/v2"
+)
+
+// crashCommand panics on purpose, to check the crash report end to end.
+func crashCommand() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:  "crash",
+		Usage: "Panic on purpose to check the crash report",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "token",
+				Usage: "A credential that must not end up in the report",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			panic("devtool crash: panicking on purpose")
+		},
+	}, `
+Panics inside a command the way a bug would, so the crash report (the message and the bundle in
+crashes/ of the config dir) can be checked, e.g. that the --token value is redacted.
+AIO_NO_CRASH_REPORT=1 shows the raw panic instead.`,
+		cmd.Example{Command: "aio devtool crash --token glpat-0123456789abcdef", Comment: "Crash and print where the report is"},
+	)
+}
@@ -0,0 +1,118 @@
+package devtool
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/golden"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// goldenDir is where the session scripts and their transcripts live.
+const goldenDir = "testdata/golden"
+
+// goldenCommand replays the interactive sessions of testdata/golden and
+// compares their screens with the recorded transcripts.
+func goldenCommand() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:      "golden",
+		Usage:     "Replay scripted interactive sessions and compare them with their golden transcripts",
+		ArgsUsage: "[script...]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "update",
+				Aliases: []string{"u"},
+				Usage:   "Record the transcripts instead of comparing them",
+			},
+			&cli.StringFlag{
+				Name:  "bin",
+				Usage: "aio binary to run the sessions with (default: this one)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			bin := c.String("bin")
+			if bin == "" {
+				exe, err := os.Executable()
+				if err != nil {
+					return fmt.Errorf("cannot locate the aio binary: %w", err)
+				}
+				bin = exe
+			}
+			bin, err := filepath.Abs(bin)
+			if err != nil {
+				return err
+			}
+
+			paths, err := scriptPaths(c.Args().Slice())
+			if err != nil {
+				return err
+			}
+			failed := 0
+			for _, path := range paths {
+				if err := replay(c, bin, path); err != nil {
+					fmt.Printf("[-] %s: %v\n", path, err)
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d sessions failed", failed, len(paths))
+			}
+			return nil
+		},
+	}, `
+Each script of testdata/golden (*.yaml) runs a command line in a pseudo-terminal
+with a temporary work directory and home, types its keys one entry at a time and
+records the screen after each. The screens must match the transcript next to the
+script (*.golden); --update records them instead, to review with git diff.
+
+  setup:                  # shell commands run first, e.g. to build a repository
+    - git init -q -b main && git commit -q --allow-empty -m init
+  run: aio git ckl        # the session, aio is on the PATH
+  keys: ["<down>", "<enter>"]
+
+Named keys are <enter>, <up>, <down>, <left>, <right>, <space>, <tab>, <bs>,
+<esc>, <ctrl-c>, <ctrl-d> and <ctrl-r>.`,
+		cmd.Example{Command: "aio devtool golden", Comment: "Check every session"},
+		cmd.Example{Command: "aio devtool golden --update testdata/golden/rmerge.yaml", Comment: "Record one transcript again"},
+	)
+}
+
+// scriptPaths returns the scripts named by args (paths or names in
+// testdata/golden), all of testdata/golden without args.
+func scriptPaths(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return golden.Glob(goldenDir)
+	}
+	var paths []string
+	for _, arg := range args {
+		if !strings.ContainsRune(arg, filepath.Separator) && filepath.Ext(arg) == "" {
+			arg = filepath.Join(goldenDir, arg+".yaml")
+		}
+		paths = append(paths, arg)
+	}
+	return paths, nil
+}
+
+// replay runs the script at path and checks (or records) its transcript.
+func replay(c *cli.Context, bin string, path string) error {
+	script, err := golden.Load(path)
+	if err != nil {
+		return err
+	}
+	result, err := golden.Run(c.Context, bin, script)
+	if err != nil {
+		return err
+	}
+	if err := golden.Check(script, result.String(), c.Bool("update")); err != nil {
+		return err
+	}
+	if c.Bool("update") {
+		fmt.Printf("[+] Recorded %s\n", script.Transcript())
+	} else {
+		fmt.Printf("[+] %s\n", script.Name)
+	}
+	return nil
+}
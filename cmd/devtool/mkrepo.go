@@ -0,0 +1,108 @@
+package devtool
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/sandbox"
+	"cli-aio/internal/ui"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// mkrepoCommand builds a throwaway repository to try commands on.
+func mkrepoCommand() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:      "mkrepo",
+		Usage:     "Build a throwaway git repository with branches, tags, conflicts and an origin",
+		ArgsUsage: "[dir]",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "branch",
+				Aliases: []string{"b"},
+				Usage:   "Branch created from main with a commit of its own (repeatable)",
+			},
+			&cli.StringSliceFlag{
+				Name:    "tag",
+				Aliases: []string{"t"},
+				Usage:   "Annotated tag on main, each on a new commit, in order (repeatable)",
+			},
+			&cli.StringSliceFlag{
+				Name:    "conflict",
+				Aliases: []string{"c"},
+				Usage:   "Two branches changing the same line, as `a:b`, so merging them conflicts (repeatable)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "diverged",
+				Usage: "Branch whose local and origin sides both have a commit the other lacks, so pulling it fails (repeatable)",
+			},
+			&cli.StringFlag{
+				Name:  "main",
+				Usage: "Default branch",
+				Value: "main",
+			},
+			&cli.BoolFlag{
+				Name:  "no-remote",
+				Usage: "Don't create the bare origin",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			spec := sandbox.Spec{
+				Main:     c.String("main"),
+				Branches: c.StringSlice("branch"),
+				Tags:     c.StringSlice("tag"),
+				Diverged: c.StringSlice("diverged"),
+				Remote:   !c.Bool("no-remote"),
+			}
+			for _, pair := range c.StringSlice("conflict") {
+				a, b, ok := strings.Cut(pair, ":")
+				if !ok || a == "" || b == "" || a == b {
+					return fmt.Errorf("invalid --conflict value: %s (expected two branches as a:b)", pair)
+				}
+				spec.Conflicts = append(spec.Conflicts, [2]string{a, b})
+			}
+			if len(spec.Branches)+len(spec.Tags)+len(spec.Conflicts)+len(spec.Diverged) == 0 {
+				demo := sandbox.DemoSpec()
+				demo.Main, demo.Remote = spec.Main, spec.Remote
+				spec = demo
+			}
+
+			dir := c.Args().First()
+			if dir == "" {
+				tmp, err := os.MkdirTemp("", "aio-sandbox-")
+				if err != nil {
+					return fmt.Errorf("failed to create a directory: %w", err)
+				}
+				dir = tmp
+			}
+			dir, err := filepath.Abs(dir)
+			if err != nil {
+				return err
+			}
+
+			repo, err := sandbox.Build(c.Context, dir, spec)
+			if err != nil {
+				return err
+			}
+			if !ui.Plain() {
+				fmt.Printf("[+] Built a sandbox repository on '%s'\n", spec.Main)
+				if repo.Origin != "" {
+					fmt.Printf("    origin: %s\n", repo.Origin)
+				}
+			}
+			ui.PrintValue("Repository", repo.Dir)
+			return nil
+		},
+	}, `
+Builds a repository in dir/repo (a new temporary directory without dir) with a bare origin in
+dir/origin.git, to try rmerge, ztag or ckl without touching real work. Commits have fixed authors
+and dates, so the same options always give the same hashes: the golden sessions build their
+repositories with it. Without branch, tag, conflict or diverged options it builds a demo service:
+develop, qc, staging and a feature branch, and a few environment tags.`,
+		cmd.Example{Command: `cd "$(aio devtool mkrepo)"`, Comment: "Try commands in the demo repository"},
+		cmd.Example{Command: "aio devtool mkrepo -b develop -b qc -c develop:qc /tmp/conflicts", Comment: "Merging develop into qc conflicts"},
+		cmd.Example{Command: "aio devtool mkrepo -b develop --diverged qc /tmp/diverged", Comment: "Pulling qc fails"},
+	)
+}
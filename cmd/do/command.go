@@ -0,0 +1,95 @@
+package do
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/notify"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/urfave/cli/v2"
+)
+
+// placeholder matches a {key} left after expansion, i.e. nothing published it.
+var placeholder = regexp.MustCompile(`\{[a-z_]+\}`)
+
+func Command() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:      "do",
+		Usage:     "Run aio commands one after another, each only if the previous one succeeded",
+		ArgsUsage: `"<command> && <command> ..." | <command>...`,
+		Action: func(c *cli.Context) error {
+			steps := splitSteps(c.Args().Slice())
+			if len(steps) == 0 {
+				return fmt.Errorf("no commands to run, e.g. aio do \"git ckl && ztag qc\"")
+			}
+
+			for i, step := range steps {
+				if cmd.Stopping(c.Context) {
+					return fmt.Errorf("interrupted after %d of %d step(s), not run: %s", i, len(steps), strings.Join(steps[i:], " && "))
+				}
+				publishBranch(c.Context)
+				expanded := cmd.ExpandShared(step)
+				if missing := placeholder.FindString(expanded); missing != "" {
+					return fmt.Errorf("step %d: %s is not known yet", i+1, missing)
+				}
+				args, err := shellquote.Split(expanded)
+				if err != nil {
+					return fmt.Errorf("step %d: %w", i+1, err)
+				}
+				if len(args) > 0 && args[0] == "aio" {
+					args = args[1:]
+				}
+				if len(args) == 0 {
+					continue
+				}
+				if args[0] == c.Command.Name {
+					return fmt.Errorf("step %d: 'do' cannot be chained", i+1)
+				}
+
+				fmt.Fprintf(os.Stderr, "-> [%d/%d] aio %s\n", i+1, len(steps), strings.Join(args, " "))
+				// A failing step exits through the app's error handler, so later steps never run
+				if err := c.App.RunContext(c.Context, append([]string{os.Args[0]}, args...)); err != nil {
+					return err
+				}
+			}
+			notify.Announce(notify.EventBatch, notify.Message{
+				Title: fmt.Sprintf("aio do: %d step(s) done", len(steps)),
+				Text:  strings.Join(steps, " && "),
+				OK:    true,
+			})
+			return nil
+		},
+	}, `Runs each step as if it was typed after 'aio'. Steps are separated by && (or given as separate
+arguments) and stop at the first failure. Values published by earlier steps can be used as
+placeholders: {branch} is the branch checked out after the previous step, ztag publishes {tag},
+{previous_tag} and {env}. They are also exported as AIO_BRANCH, AIO_TAG, ... to plugins.`,
+		cmd.Example{Command: `aio do "git ckl && ztag qc"`, Comment: "Switch branch, then tag it for QC"},
+		cmd.Example{Command: `aio do "ztag qc && remind add --every 1d Check {tag} on QC"`, Comment: "Use a value from an earlier step"},
+		cmd.Example{Command: `aio do "git nb fix login" "git mr list"`, Comment: "Steps as separate arguments"},
+	)
+}
+
+// splitSteps splits the arguments on && into steps.
+func splitSteps(args []string) []string {
+	var steps []string
+	for _, arg := range args {
+		for _, step := range strings.Split(arg, "&&") {
+			if step = strings.TrimSpace(step); step != "" {
+				steps = append(steps, step)
+			}
+		}
+	}
+	return steps
+}
+
+// publishBranch shares the current branch, which earlier steps may have changed.
+func publishBranch(ctx context.Context) {
+	if head, err := git.GetCurrentBranch(ctx); err == nil && head.Branch != "" {
+		cmd.Publish("branch", head.Branch)
+	}
+}
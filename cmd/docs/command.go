@@ -0,0 +1,101 @@
+package docs
+
+import (
+	"cli-aio/internal/cmd"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cpuguy83/go-md2man/v2/md2man"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "docs",
+		Usage: "Generate the command reference as Markdown or a man page",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "man",
+				Usage: "Render a man page (roff) instead of Markdown",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Write to this file instead of stdout",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			doc := []byte(markdown(c.App))
+			if c.Bool("man") {
+				doc = md2man.Render(doc)
+			}
+			output := c.String("output")
+			if output == "" {
+				_, err := os.Stdout.Write(doc)
+				return err
+			}
+			if err := os.WriteFile(output, doc, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", output, err)
+			}
+			fmt.Printf("[+] Wrote %s\n", output)
+			return nil
+		},
+	}
+}
+
+// markdown renders the whole command tree, with the descriptions and examples
+// registered through cmd.Describe.
+func markdown(app *cli.App) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%% %s 1\n\n# NAME\n\n%s - %s\n\n", app.Name, app.Name, app.Usage)
+	fmt.Fprintf(&b, "# GLOBAL OPTIONS\n\n")
+	writeFlags(&b, app.VisibleFlags())
+	fmt.Fprintf(&b, "# COMMANDS\n\n")
+	for _, command := range app.VisibleCommands() {
+		writeCommand(&b, []string{"aio"}, command)
+	}
+	return b.String()
+}
+
+func writeCommand(b *strings.Builder, parents []string, command *cli.Command) {
+	if command.Name == "help" {
+		return
+	}
+	path := append(append([]string{}, parents...), command.Name)
+	fmt.Fprintf(b, "## %s\n\n%s\n\n", strings.Join(path, " "), command.Usage)
+	if command.Description != "" {
+		fmt.Fprintf(b, "%s\n\n", command.Description)
+	}
+
+	usage := strings.Join(path, " ")
+	if len(command.VisibleFlags()) > 0 {
+		usage += " [options]"
+	}
+	if command.ArgsUsage != "" {
+		usage += " " + command.ArgsUsage
+	}
+	fmt.Fprintf(b, "```\n%s\n```\n\n", usage)
+	writeFlags(b, command.VisibleFlags())
+
+	if examples := cmd.ExamplesOf(command); len(examples) > 0 {
+		fmt.Fprintf(b, "**Examples**:\n\n```sh\n%s```\n\n", cmd.FormatExamples(examples, ""))
+	}
+	for _, sub := range command.VisibleCommands() {
+		writeCommand(b, path, sub)
+	}
+}
+
+func writeFlags(b *strings.Builder, flags []cli.Flag) {
+	var lines []string
+	for _, flag := range flags {
+		if flag.Names()[0] == "help" {
+			continue
+		}
+		lines = append(lines, "- `"+strings.ReplaceAll(flag.String(), "\t", "` ")+"\n")
+	}
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s\n", strings.Join(lines, ""))
+}
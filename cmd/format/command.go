@@ -0,0 +1,120 @@
+package format
+
+import (
+	"cli-aio/internal/pkg/document"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+// Command returns the fmt command which pretty-prints, converts, validates
+// and queries JSON/YAML documents read from a file or stdin.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:      "fmt",
+		Usage:     "Pretty-print, convert, validate and query JSON/YAML (file or stdin)",
+		ArgsUsage: "[file|-]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "to",
+				Aliases: []string{"t"},
+				Usage:   "Output format: json or yaml (default: same as input)",
+			},
+			&cli.StringFlag{
+				Name:    "query",
+				Aliases: []string{"q"},
+				Usage:   "Path to extract, e.g. .spec.containers[0].image",
+			},
+			&cli.BoolFlag{
+				Name:  "check",
+				Usage: "Only validate syntax, print nothing on success",
+			},
+			&cli.BoolFlag{
+				Name:  "no-color",
+				Usage: "Disable colored output",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			data, source, err := readInput(c)
+			if err != nil {
+				return err
+			}
+
+			node, inputFormat, err := document.Parse(data)
+			if err != nil {
+				return fmt.Errorf("%s: %w", source, err)
+			}
+
+			if c.Bool("check") {
+				fmt.Fprintf(os.Stderr, "[+] %s is valid %s\n", source, inputFormat)
+				return nil
+			}
+
+			if query := c.String("query"); query != "" {
+				node, err = document.Query(node, query)
+				if err != nil {
+					return err
+				}
+				// Scalars are printed raw so they can be consumed by scripts
+				if document.IsScalar(node) {
+					fmt.Println(document.ScalarString(node))
+					return nil
+				}
+			}
+
+			outputFormat := inputFormat
+			if to := c.String("to"); to != "" {
+				outputFormat, err = document.ParseFormat(to)
+				if err != nil {
+					return err
+				}
+			}
+
+			color := ui.ColorEnabled() && !c.Bool("no-color")
+			var out string
+			if outputFormat == document.JSON {
+				out, err = document.EncodeJSON(node, color)
+			} else {
+				out, err = document.EncodeYAML(node, color)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Print(out)
+			return nil
+		},
+	}
+}
+
+// readInput reads the document from the file argument, or from stdin when the
+// argument is "-" or omitted while stdin is piped. In a terminal without
+// arguments the user is prompted for a file path.
+func readInput(c *cli.Context) ([]byte, string, error) {
+	path := c.Args().First()
+	if path == "" && term.IsTerminal(int(os.Stdin.Fd())) {
+		var err error
+		path, err = prompt.Input("Enter file path:", "", true)
+		if err != nil {
+			return nil, "", fmt.Errorf("input cancelled: %w", err)
+		}
+	}
+
+	if path == "" || path == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return data, "stdin", nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return data, path, nil
+}
@@ -0,0 +1,234 @@
+package gen
+
+import (
+	"bytes"
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/prompt"
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// templateNames lists the bundled templates of a kind (license, gitignore) without extension.
+func templateNames(kind string) ([]string, error) {
+	entries, err := templates.ReadDir(path.Join("templates", kind))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s templates: %w", kind, err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), path.Ext(e.Name())))
+	}
+	return names, nil
+}
+
+// findTemplate returns the file name of a bundled template, matching case-insensitively.
+func findTemplate(kind string, name string) (string, bool) {
+	entries, err := templates.ReadDir(path.Join("templates", kind))
+	if err != nil {
+		return "", false
+	}
+	for _, e := range entries {
+		if strings.EqualFold(strings.TrimSuffix(e.Name(), path.Ext(e.Name())), name) {
+			return path.Join("templates", kind, e.Name()), true
+		}
+	}
+	return "", false
+}
+
+func Command() *cli.Command {
+	subcommands := []*cli.Command{
+		licenseCmd(),
+		gitignoreCmd(),
+	}
+
+	return &cli.Command{
+		Name:        "gen",
+		Usage:       "Generate common project files (LICENSE, .gitignore) from bundled templates",
+		Subcommands: subcommands,
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				if !cmd.ValidateSubcommand(c, subcommands) {
+					return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+				}
+				return nil
+			}
+			return prompt.SelectCommand(c, subcommands, "Select a subcommand:", cli.ShowSubcommandHelp)
+		},
+	}
+}
+
+func licenseCmd() *cli.Command {
+	return cmd.AskFlags(&cli.Command{
+		Name:      "license",
+		Usage:     "Write a LICENSE file (MIT, Apache-2.0)",
+		ArgsUsage: "[license]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "author",
+				Usage: "Copyright holder (default: git config user.name)",
+			},
+			&cli.IntFlag{
+				Name:  "year",
+				Usage: "Copyright year (default: current year)",
+			},
+			&cli.StringFlag{
+				Name:    "out",
+				Aliases: []string{"o"},
+				Value:   "LICENSE",
+				Usage:   "Output file",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Overwrite an existing file without asking",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			names, err := templateNames("license")
+			if err != nil {
+				return err
+			}
+
+			name := c.Args().First()
+			if name == "" {
+				_, name, err = prompt.Select("Select license:", names, "MIT")
+				if err != nil {
+					return fmt.Errorf("selection cancelled: %w", err)
+				}
+			}
+			file, ok := findTemplate("license", name)
+			if !ok {
+				return fmt.Errorf("unknown license: %s (available: %s)", name, strings.Join(names, ", "))
+			}
+
+			author := c.String("author")
+			year := c.Int("year")
+
+			tmpl, err := template.ParseFS(templates, file)
+			if err != nil {
+				return fmt.Errorf("failed to parse license template: %w", err)
+			}
+			var buf bytes.Buffer
+			data := struct {
+				Author string
+				Year   int
+			}{Author: author, Year: year}
+			if err := tmpl.Execute(&buf, data); err != nil {
+				return fmt.Errorf("failed to render license: %w", err)
+			}
+
+			out := c.String("out")
+			if !c.Bool("force") && fileExists(out) {
+				overwrite, err := prompt.Confirm(fmt.Sprintf("%s already exists. Overwrite?", out), false)
+				if err != nil || !overwrite {
+					fmt.Println("[!] Aborted")
+					return nil
+				}
+			}
+			if err := os.WriteFile(out, buf.Bytes(), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", out, err)
+			}
+			fmt.Printf("[+] Wrote %s license to %s\n", strings.TrimSuffix(path.Base(file), path.Ext(file)), out)
+			return nil
+		},
+	},
+		cmd.Ask{Flag: "author", Message: "Author:", DefaultFunc: func(c *cli.Context) string { return git.GetConfig(c.Context, "user.name") }},
+		cmd.Ask{Flag: "year", Message: "Year:", DefaultFunc: func(*cli.Context) string { return strconv.Itoa(time.Now().Year()) }},
+	)
+}
+
+func gitignoreCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "gitignore",
+		Usage:     "Write a .gitignore combining templates (Go, Node, Python)",
+		ArgsUsage: "[template...]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "out",
+				Aliases: []string{"o"},
+				Value:   ".gitignore",
+				Usage:   "Output file",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Overwrite an existing file instead of appending to it",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			names, err := templateNames("gitignore")
+			if err != nil {
+				return err
+			}
+
+			selected := c.Args().Slice()
+			if len(selected) == 0 {
+				selected, err = prompt.MultiSelect("Select templates:", names, nil)
+				if err != nil {
+					return fmt.Errorf("selection cancelled: %w", err)
+				}
+				if len(selected) == 0 {
+					return fmt.Errorf("no templates selected")
+				}
+			}
+
+			var buf bytes.Buffer
+			for _, name := range selected {
+				file, ok := findTemplate("gitignore", name)
+				if !ok {
+					return fmt.Errorf("unknown gitignore template: %s (available: %s)", name, strings.Join(names, ", "))
+				}
+				data, err := templates.ReadFile(file)
+				if err != nil {
+					return fmt.Errorf("failed to read %s: %w", file, err)
+				}
+				if buf.Len() > 0 {
+					buf.WriteString("\n")
+				}
+				fmt.Fprintf(&buf, "### %s ###\n", strings.TrimSuffix(path.Base(file), path.Ext(file)))
+				buf.Write(data)
+			}
+
+			out := c.String("out")
+			content := buf.Bytes()
+			flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+			if !c.Bool("force") && fileExists(out) {
+				_, action, err := prompt.Select(fmt.Sprintf("%s already exists:", out), []string{"Append", "Overwrite", "Cancel"}, "Append")
+				if err != nil || action == "Cancel" {
+					fmt.Println("[!] Aborted")
+					return nil
+				}
+				if action == "Append" {
+					flags = os.O_APPEND | os.O_WRONLY
+					content = append([]byte("\n"), content...)
+				}
+			}
+
+			f, err := os.OpenFile(out, flags, 0644)
+			if err != nil {
+				return fmt.Errorf("failed to open %s: %w", out, err)
+			}
+			defer f.Close()
+			if _, err := f.Write(content); err != nil {
+				return fmt.Errorf("failed to write %s: %w", out, err)
+			}
+			fmt.Printf("[+] Wrote %s to %s\n", strings.Join(selected, ", "), out)
+			return nil
+		},
+	}
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
@@ -0,0 +1,86 @@
+package gencmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// reservedNames are added by urfave/cli to every command with subcommands.
+var reservedNames = []string{"help", "h"}
+
+// reservedOwner marks reserved names in takenNames.
+const reservedOwner = ""
+
+// nameSuffixes are tried in order to suggest a free name.
+var nameSuffixes = []string{"-cmd", "-tool", "-x", "2"}
+
+// takenNames maps every name and alias in commands to the command that owns it.
+func takenNames(commands []*cli.Command) map[string]string {
+	taken := make(map[string]string)
+	for _, name := range reservedNames {
+		taken[name] = reservedOwner
+	}
+	for _, cmd := range commands {
+		for _, name := range cmd.Names() {
+			if owner, ok := taken[name]; !ok || owner != reservedOwner {
+				taken[name] = cmd.Name
+			}
+		}
+	}
+	return taken
+}
+
+// checkName returns an error with suggestions when name is already used by a
+// registered command or alias, or is reserved.
+func checkName(kind string, name string, taken map[string]string) error {
+	owner, ok := taken[name]
+	if !ok {
+		return nil
+	}
+	var msg string
+	switch owner {
+	case reservedOwner:
+		msg = fmt.Sprintf("%s name '%s' is reserved by urfave/cli", kind, name)
+	case name:
+		msg = fmt.Sprintf("%s name '%s' is already used by an existing command", kind, name)
+	default:
+		msg = fmt.Sprintf("%s name '%s' is already an alias of the '%s' command", kind, name, owner)
+	}
+	if suggestions := suggestNames(name, taken); len(suggestions) > 0 {
+		msg += fmt.Sprintf(" (try %s)", strings.Join(suggestions, ", "))
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// suggestNames returns free variants of name.
+func suggestNames(name string, taken map[string]string) []string {
+	var suggestions []string
+	for _, suffix := range nameSuffixes {
+		candidate := name + suffix
+		if _, ok := taken[candidate]; !ok {
+			suggestions = append(suggestions, candidate)
+		}
+		if len(suggestions) == 2 {
+			break
+		}
+	}
+	return suggestions
+}
+
+// checkSubcommandNames rejects reserved and duplicate subcommand names.
+func checkSubcommandNames(subcommands []string) error {
+	taken := takenNames(nil)
+	for i, name := range subcommands {
+		if err := checkName("subcommand", name, taken); err != nil {
+			return err
+		}
+		for _, other := range subcommands[:i] {
+			if other == name {
+				return fmt.Errorf("subcommand '%s' is listed twice", name)
+			}
+		}
+	}
+	return nil
+}
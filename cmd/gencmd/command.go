@@ -14,6 +14,9 @@ func Command() *cli.Command {
 	return &cli.Command{
 		Name:  "gencmd",
 		Usage: "Generate a new command or subcommand",
+		Subcommands: []*cli.Command{
+			pluginCommand(),
+		},
 		Flags: []cli.Flag{
 			&cli.StringSliceFlag{
 				Name:    "subcommand",
@@ -46,6 +49,10 @@ func Command() *cli.Command {
 			if !isValidCommandName(cmdName) {
 				return fmt.Errorf("invalid command name: %s (must contain only alphanumeric characters, hyphens, or underscores)", cmdName)
 			}
+			// The running binary's command tree is the registered one
+			if err := checkName("command", cmdName, takenNames(c.App.Commands)); err != nil {
+				return err
+			}
 
 			// Get subcommands from flags or prompt
 			subcommands = c.StringSlice("subcommand")
@@ -74,16 +81,9 @@ func Command() *cli.Command {
 							fmt.Printf("[!] Invalid subcommand name: %s (skipping)\n", subcmd)
 							continue
 						}
-						// Check for duplicates
-						duplicate := false
-						for _, existing := range subcommands {
-							if existing == subcmd {
-								fmt.Printf("[!] Subcommand '%s' already added (skipping)\n", subcmd)
-								duplicate = true
-								break
-							}
-						}
-						if duplicate {
+						// Check for reserved names and duplicates
+						if err := checkSubcommandNames(append(subcommands, subcmd)); err != nil {
+							fmt.Printf("[!] %v (skipping)\n", err)
 							continue
 						}
 						subcommands = append(subcommands, subcmd)
@@ -92,6 +92,10 @@ func Command() *cli.Command {
 				}
 			}
 
+			if err := checkSubcommandNames(subcommands); err != nil {
+				return err
+			}
+
 			// Get usage from flag or prompt
 			usage = c.String("usage")
 			if usage == "" {
@@ -280,8 +284,9 @@ func registerCommandInCLI(workspaceRoot, cmdName string) error {
 	}
 	commandsEnd += commandsStart
 
-	// Insert the new command before the closing brace
+	// Insert the new command on its own line before the closing brace
 	newCommand := fmt.Sprintf("\t\t%s.Command(),\n", cmdName)
+	commandsEnd++ // after the newline of the last command
 	contentStr = contentStr[:commandsEnd] + newCommand + contentStr[commandsEnd:]
 
 	// Write back
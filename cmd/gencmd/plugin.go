@@ -0,0 +1,152 @@
+package gencmd
+
+import (
+	"cli-aio/internal/pkg/plugin"
+	"cli-aio/internal/prompt"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// pluginCommand scaffolds a standalone aio-<name> plugin module. Plugins live
+// outside this repository and are run by 'aio <name>' when found on PATH.
+func pluginCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "plugin",
+		Usage:     "Scaffold a standalone aio-<name> plugin module",
+		ArgsUsage: "<name>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "module",
+				Aliases: []string{"m"},
+				Usage:   "Go module path (default: aio-<name>)",
+			},
+			&cli.StringFlag{
+				Name:    "dir",
+				Aliases: []string{"d"},
+				Usage:   "Target directory (default: ./aio-<name>)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			var err error
+			name := strings.TrimPrefix(c.Args().First(), plugin.Prefix)
+			if name == "" {
+				name, err = prompt.Input("Enter plugin name:", "", true)
+				if err != nil {
+					return fmt.Errorf("plugin name is required")
+				}
+				name = strings.TrimPrefix(name, plugin.Prefix)
+			}
+			if !isValidCommandName(name) {
+				return fmt.Errorf("invalid plugin name: %s (must contain only alphanumeric characters, hyphens, or underscores)", name)
+			}
+			// Built-in commands win over plugins, so 'aio <name>' would never reach it
+			if err := checkName("plugin", name, takenNames(c.App.Commands)); err != nil {
+				return err
+			}
+
+			binary := plugin.Prefix + name
+			module := c.String("module")
+			if module == "" {
+				module = binary
+			}
+			dir := c.String("dir")
+			if dir == "" {
+				dir = binary
+			}
+			if _, err := os.Stat(dir); err == nil {
+				return fmt.Errorf("directory already exists: %s", dir)
+			}
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+
+			files := map[string]string{
+				"go.mod":    fmt.Sprintf("module %s\n\ngo 1.21\n", module),
+				"main.go":   generatePluginMain(name),
+				"Makefile":  generatePluginMakefile(binary),
+				"README.md": generatePluginReadme(name, binary),
+			}
+			for file, content := range files {
+				if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+					return fmt.Errorf("failed to write %s: %w", file, err)
+				}
+			}
+
+			fmt.Printf("[+] Generated plugin '%s' at %s\n", binary, dir)
+			fmt.Printf("   cd %s && make install, then run: aio %s\n", dir, name)
+			return nil
+		},
+	}
+}
+
+func generatePluginMain(name string) string {
+	return fmt.Sprintf(`package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// pluginContext mirrors the JSON aio passes in %[1]s.
+type pluginContext struct {
+	ContextVersion int      `+"`json:\"context_version\"`"+`
+	AioVersion     string   `+"`json:\"aio_version\"`"+`
+	Name           string   `+"`json:\"name\"`"+`
+	Args           []string `+"`json:\"args\"`"+`
+	Cwd            string   `+"`json:\"cwd\"`"+`
+	ConfigDir      string   `+"`json:\"config_dir\"`"+`
+	GitLabURL      string   `+"`json:\"gitlab_url\"`"+`
+	Interactive    bool     `+"`json:\"interactive\"`"+`
+}
+
+// loadContext reads the aio plugin context. When the plugin is run directly
+// (not through 'aio %[2]s') it falls back to the process arguments.
+func loadContext() (pluginContext, error) {
+	ctx := pluginContext{Name: %[2]q, Args: os.Args[1:]}
+	raw := os.Getenv(%[1]q)
+	if raw == "" {
+		return ctx, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &ctx); err != nil {
+		return ctx, fmt.Errorf("invalid %[1]s: %%w", err)
+	}
+	return ctx, nil
+}
+
+func main() {
+	ctx, err := loadContext()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[-] Error: %%v\n", err)
+		os.Exit(1)
+	}
+
+	// TODO: Implement your logic here
+	fmt.Printf("Executing %%s plugin with args %%v (aio %%s, cwd %%s)\n", ctx.Name, ctx.Args, ctx.AioVersion, ctx.Cwd)
+}
+`, plugin.ContextEnv, name)
+}
+
+func generatePluginMakefile(binary string) string {
+	return fmt.Sprintf(`BINARY := %s
+
+build:
+	go build -o $(BINARY) .
+
+install: build
+	mv ./$(BINARY) /usr/local/bin/$(BINARY)
+
+.PHONY: build install
+`, binary)
+}
+
+func generatePluginReadme(name string, binary string) string {
+	return fmt.Sprintf("# %[2]s\n\nPlugin for cli-aio. Install it on your PATH and run it as `aio %[1]s`.\n\n"+
+		"```sh\nmake install\naio %[1]s\n```\n\n"+
+		"aio passes its context (version, args, cwd, config dir, GitLab URL) as JSON in `%[3]s`.\n",
+		name, binary, plugin.ContextEnv)
+}
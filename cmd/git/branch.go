@@ -0,0 +1,102 @@
+package git
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/convention"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/jira"
+	"cli-aio/internal/prompt"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// invalidBranchChars matches runs of characters that are replaced by '-' in branch names.
+	invalidBranchChars = regexp.MustCompile(`[^A-Za-z0-9._/-]+`)
+	repeatedDashes     = regexp.MustCompile(`-{2,}`)
+)
+
+// slugifyBranch turns free text (e.g. "ABC-123 Fix login bug") into a branch-safe name.
+func slugifyBranch(s string) string {
+	s = invalidBranchChars.ReplaceAllString(strings.TrimSpace(s), "-")
+	s = repeatedDashes.ReplaceAllString(s, "-")
+	return strings.Trim(s, "-./")
+}
+
+// branchSuggestions completes the branch type while typing the first word,
+// then the Jira keys of the recent branches and commits.
+func branchSuggestions(ctx context.Context) func(string) []string {
+	keys := prompt.CompleteWords(jira.RecentKeys(ctx))
+	var types []string
+	if cfg, err := config.Load(); err == nil {
+		if rules, err := convention.NewRules(cfg.Conventions); err == nil {
+			for _, t := range rules.BranchTypes() {
+				types = append(types, t+"/")
+			}
+		}
+	}
+	first := prompt.CompleteWords(types)
+	return func(toComplete string) []string {
+		if strings.ContainsAny(toComplete, "/ ") {
+			return keys(toComplete)
+		}
+		return append(first(toComplete), keys(toComplete)...)
+	}
+}
+
+// newBranch creates a new branch (from HEAD or --from) and checks it out.
+// Other commands (e.g. 'aio jira branch') delegate to it so naming rules live in one place.
+func newBranch() *cli.Command {
+	return &cli.Command{
+		Name:      "nb",
+		Usage:     "Create a new branch from HEAD (or --from) and check it out",
+		ArgsUsage: "[name]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "from",
+				Aliases: []string{"f"},
+				Usage:   "Base branch to create the new branch from (fetched from origin first)",
+			},
+		},
+		Before: cmd.Require(cmd.RequireTool("git"), cmd.RequireGitRepo()),
+		Action: func(c *cli.Context) error {
+			name := strings.Join(c.Args().Slice(), " ")
+			if name == "" {
+				var err error
+				name, err = prompt.InputWithSuggestions("Enter branch name:", "", true, branchSuggestions(c.Context))
+				if err != nil {
+					return fmt.Errorf("input cancelled: %w", err)
+				}
+			}
+
+			branch := slugifyBranch(name)
+			if branch == "" || !git.IsValidBranchName(c.Context, branch) {
+				return fmt.Errorf("invalid branch name: %s", name)
+			}
+			if exists, _ := git.BranchExists(c.Context, branch); exists {
+				return fmt.Errorf("branch '%s' already exists", branch)
+			}
+
+			base := c.String("from")
+			if base != "" {
+				fmt.Printf("Fetching branch '%s'...\n", base)
+				if err := git.FetchBranch(c.Context, base); err != nil {
+					fmt.Printf("[!] Warning: Failed to fetch branch: %v\n", err)
+				} else {
+					base = "origin/" + base
+				}
+			}
+
+			if err := git.CreateBranch(c.Context, branch, base); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Created and checked out to branch '%s'\n", branch)
+			return nil
+		},
+	}
+}
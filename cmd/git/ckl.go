@@ -0,0 +1,194 @@
+package git
+
+import (
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/prompt"
+	"context"
+	"fmt"
+)
+
+// Actions offered by 'git ckl' on the selected branch.
+const (
+	branchActionCheckout = "Checkout"
+	branchActionNew      = "Create new branch from here"
+	branchActionRename   = "Rename"
+	branchActionDelete   = "Delete"
+	branchActionCancel   = "Cancel"
+)
+
+// branchActions asks what to do with the branch selected in 'git ckl'.
+// Checkout is the default, so Enter twice keeps the old checkout-only flow.
+func branchActions(ctx context.Context, gc git.GitClient, selected string, currentBranch string) error {
+	localBranches, err := gc.LocalBranches(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check local branches: %w", err)
+	}
+	isLocal := false
+	for _, branch := range localBranches {
+		if branch == selected {
+			isLocal = true
+			break
+		}
+	}
+
+	options := []string{branchActionCheckout, branchActionNew}
+	if isLocal {
+		options = append(options, branchActionRename)
+	}
+	if selected != currentBranch {
+		options = append(options, branchActionDelete)
+	}
+	options = append(options, branchActionCancel)
+
+	_, action, err := prompt.SelectWithFuzzy(fmt.Sprintf("What to do with '%s'?", selected), options, branchActionCheckout, false)
+	if err != nil {
+		return fmt.Errorf("selection cancelled: %w", err)
+	}
+
+	switch action {
+	case branchActionCheckout:
+		return checkoutSelected(ctx, gc, selected, currentBranch, isLocal)
+	case branchActionNew:
+		return branchFrom(ctx, gc, selected, isLocal)
+	case branchActionRename:
+		return renameBranch(ctx, gc, selected)
+	case branchActionDelete:
+		return deleteBranch(ctx, gc, selected, currentBranch, isLocal)
+	}
+	return nil
+}
+
+func checkoutSelected(ctx context.Context, gc git.GitClient, selected string, currentBranch string, isLocal bool) error {
+	// Check if already on the selected branch
+	if selected == currentBranch {
+		fmt.Printf("Already on branch '%s'\n", currentBranch)
+		return nil
+	}
+
+	// If it's a remote branch, create a local tracking branch
+	if !isLocal {
+		fmt.Printf("Branch '%s' is a remote branch. Creating local tracking branch...\n", selected)
+		// Fetch the remote branch first
+		if err := gc.FetchBranch(ctx, selected); err != nil {
+			if git.KindOf(err) == git.ErrCancelled {
+				return err
+			}
+			fmt.Printf("[-] Failed to fetch branch: %v\n", err)
+		}
+		if err := gc.CheckoutTracking(ctx, selected); err != nil {
+			if git.KindOf(err) == git.ErrExists {
+				return fmt.Errorf("a local branch '%s' already exists, check it out instead", selected)
+			}
+			return fmt.Errorf("failed to checkout remote branch: %w", git.WithHint(err))
+		}
+		fmt.Printf("[+] Created and checked out to branch '%s' (tracking origin/%s)\n", selected, selected)
+		return nil
+	}
+
+	// It's a local branch, just checkout
+	fmt.Printf("Checking out to branch '%s'...\n", selected)
+	if err := gc.Checkout(ctx, selected); err != nil {
+		return fmt.Errorf("failed to checkout branch: %v", git.WithHint(err))
+	}
+
+	fmt.Printf("[+] Checked out to branch '%s'\n", selected)
+	return nil
+}
+
+// branchFrom creates a new branch based on the selected one and checks it out.
+func branchFrom(ctx context.Context, gc git.GitClient, selected string, isLocal bool) error {
+	name, err := prompt.Input(fmt.Sprintf("New branch name (from '%s'):", selected), "", true)
+	if err != nil {
+		return fmt.Errorf("input cancelled: %w", err)
+	}
+	branch := slugifyBranch(name)
+	if branch == "" || !gc.IsValidBranchName(ctx, branch) {
+		return fmt.Errorf("invalid branch name: %s", name)
+	}
+	if exists, _ := gc.BranchExists(ctx, branch); exists {
+		return fmt.Errorf("branch '%s' already exists", branch)
+	}
+
+	base := selected
+	if !isLocal {
+		base = "origin/" + selected
+	}
+	if err := gc.CreateBranch(ctx, branch, base); err != nil {
+		return err
+	}
+	fmt.Printf("[+] Created and checked out to branch '%s' (from %s)\n", branch, base)
+	return nil
+}
+
+// renameBranch renames a local branch. The remote branch, if any, is left untouched.
+func renameBranch(ctx context.Context, gc git.GitClient, selected string) error {
+	name, err := prompt.Input(fmt.Sprintf("Rename '%s' to:", selected), selected, true)
+	if err != nil {
+		return fmt.Errorf("input cancelled: %w", err)
+	}
+	branch := slugifyBranch(name)
+	if branch == selected {
+		return nil
+	}
+	if branch == "" || !gc.IsValidBranchName(ctx, branch) {
+		return fmt.Errorf("invalid branch name: %s", name)
+	}
+	if exists, _ := gc.BranchExists(ctx, branch); exists {
+		return fmt.Errorf("branch '%s' already exists", branch)
+	}
+
+	if err := gc.RenameBranch(ctx, selected, branch); err != nil {
+		return err
+	}
+	fmt.Printf("[+] Renamed branch '%s' to '%s'\n", selected, branch)
+	if gc.RemoteBranchExists(ctx, selected) {
+		fmt.Printf("[!] origin/%s still exists, push '%s' and delete the old remote branch if needed\n", selected, branch)
+	}
+	return nil
+}
+
+// deleteBranch deletes the local branch (after checking it is merged into the
+// current branch) and optionally its remote counterpart.
+func deleteBranch(ctx context.Context, gc git.GitClient, selected string, currentBranch string, isLocal bool) error {
+	if isLocal {
+		// The merge check is done here against HEAD, so delete with -D afterwards:
+		// 'git branch -d' would also refuse branches merged into HEAD but not their upstream
+		if !gc.IsAncestor(ctx, selected, "HEAD") {
+			into := currentBranch
+			if into == "" {
+				into = "HEAD"
+			}
+			fmt.Printf("[!] Branch '%s' is not merged into '%s'\n", selected, into)
+			confirmed, err := prompt.Confirm("Delete it anyway? Unmerged commits will be lost", false)
+			if err != nil || !confirmed {
+				fmt.Println("[!] Aborted")
+				return nil
+			}
+		}
+		if err := gc.DeleteBranch(ctx, selected, true); err != nil {
+			return err
+		}
+		fmt.Printf("[+] Deleted branch '%s'\n", selected)
+	}
+
+	if !gc.RemoteBranchExists(ctx, selected) {
+		return nil
+	}
+	deleteRemote, err := prompt.Confirm(fmt.Sprintf("Delete remote branch origin/%s too?", selected), !isLocal)
+	if err != nil || !deleteRemote {
+		return nil
+	}
+	if err := gc.DeleteRemoteBranch(ctx, selected); err != nil {
+		switch git.KindOf(err) {
+		case git.ErrNotFound:
+			// Someone deleted it since the last fetch
+			fmt.Printf("[!] 'origin/%s' was already deleted on origin\n", selected)
+			return nil
+		case git.ErrRejected:
+			return fmt.Errorf("origin refused to delete '%s', it may be protected: %w", selected, err)
+		}
+		return git.WithHint(err)
+	}
+	fmt.Printf("[+] Deleted remote branch 'origin/%s'\n", selected)
+	return nil
+}
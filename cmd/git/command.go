@@ -2,10 +2,13 @@ package git
 
 import (
 	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/daemon"
 	"cli-aio/internal/pkg/git"
 	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"context"
 	"fmt"
-	"os/exec"
+	"strings"
 
 	"github.com/urfave/cli/v2"
 )
@@ -15,6 +18,22 @@ func Command() *cli.Command {
 		extractProjectFullName(),
 		reversedMergeBranch(),
 		checkoutList(),
+		pruneRemoteCmd(),
+		newBranch(),
+		mrCmd(),
+		cutReleaseCmd(),
+		idCmd(),
+		patchCmd(),
+		lfsCmd(),
+		staleCmd(),
+		ownersCmd(),
+		pushCmd(),
+		migrateRemoteCmd(),
+		rawCmd(),
+		auditCmd(),
+		lintBranchCmd(),
+		lintCommitsCmd(),
+		hooksCmd(),
 	}
 
 	return &cli.Command{
@@ -37,158 +56,56 @@ func Command() *cli.Command {
 
 func extractProjectFullName() *cli.Command {
 	return &cli.Command{
-		Name:  "fname",
-		Usage: "Extract project full name from git repository",
+		Name:   "fname",
+		Usage:  "Extract project full name (host/path of origin) from git repository",
+		Before: cmd.Require(cmd.RequireTool("git"), cmd.RequireGitRepo()),
 		Action: func(c *cli.Context) error {
-			projectFullName, err := git.ExtractProjectFullName()
+			projectFullName, err := git.ExtractProjectFullName(c.Context)
 			if err != nil {
 				return err
 			}
-			fmt.Printf("Project full name: %s\n", projectFullName)
+			ui.PrintValue("Project full name", projectFullName)
 			return nil
 		},
 	}
 }
 
-func reversedMergeBranch() *cli.Command {
+func checkoutList() *cli.Command {
 	return &cli.Command{
-		Name:  "rmerge",
-		Usage: "Reverse merge current branch into target branch (checkout to target, then merge current into it)",
+		Name:  "ckl",
+		Usage: "Checkout list - list all available branches (local and remote) to checkout, delete, rename or branch from",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "prune",
+				Usage: "Prune stale origin/* branches first and flag local branches whose remote is gone",
+			},
+		},
+		Before: cmd.Require(cmd.RequireGitRepo()),
 		Action: func(c *cli.Context) error {
-			// Get current branch (A)
-			currentBranch, err := git.GetCurrentBranch()
+			gc := git.NewClient()
+			// Get current branch (empty when detached, so nothing is preselected)
+			head, err := gc.Head(c.Context)
 			if err != nil {
-				return err
+				return fmt.Errorf("failed to get current branch: %w", err)
 			}
-			fmt.Printf("Current branch: %s\n", currentBranch)
-
-			// Get target branch (B) from args or prompt
-			var targetBranch string
-			if c.Args().Len() > 0 {
-				targetBranch = c.Args().First()
-			} else {
-				// Prompt user to select from local branches
-				localBranches, err := git.GetLocalBranches()
-				if err != nil {
-					return err
-				}
-
-				// Filter out current branch from the list
-				availableBranches := []string{}
-				for _, branch := range localBranches {
-					if branch != currentBranch {
-						availableBranches = append(availableBranches, branch)
-					}
-				}
-
-				if len(availableBranches) == 0 {
-					return fmt.Errorf("no other local branches available to merge into")
-				}
-
-				_, selected, err := prompt.Select("Select target branch:", availableBranches, "")
-				if err != nil {
-					return fmt.Errorf("failed to select branch: %v", err)
-				}
-				targetBranch = selected
+			currentBranch := head.Branch
+			if head.Detached {
+				fmt.Printf("HEAD is %s\n", head)
 			}
 
-			// Check if target branch exists
-			branchExists, err := git.BranchExists(targetBranch)
-			if err != nil {
-				return err
-			}
-			if !branchExists {
-				// Branch doesn't exist, prompt user to select from available branches
-				localBranches, err := git.GetLocalBranches()
+			gone := map[string]bool{}
+			if c.Bool("prune") {
+				goneBranches, err := pruneRemote(c.Context, gc, false)
 				if err != nil {
-					return fmt.Errorf("branch '%s' does not exist and failed to get local branches: %w", targetBranch, err)
-				}
-
-				// Filter out current branch from the list
-				availableBranches := []string{}
-				for _, branch := range localBranches {
-					if branch != currentBranch {
-						availableBranches = append(availableBranches, branch)
-					}
-				}
-
-				if len(availableBranches) == 0 {
-					return fmt.Errorf("branch '%s' does not exist and no other local branches available", targetBranch)
+					return err
 				}
-
-				fmt.Printf("[!] Branch '%s' does not exist.\n", targetBranch)
-				_, selected, err := prompt.Select("Select target branch from available branches:", availableBranches, "")
-				if err != nil {
-					return fmt.Errorf("failed to select branch: %w", err)
+				for _, branch := range goneBranches {
+					gone[branch] = true
 				}
-				targetBranch = selected
-			}
-
-			fmt.Printf("Target branch: %s\n", targetBranch)
-
-			// Check if we're already on the target branch
-			if currentBranch == targetBranch {
-				return fmt.Errorf("already on target branch '%s'", targetBranch)
-			}
-
-			// Fetch the target branch to make sure we have latest info
-			fmt.Printf("Fetching branch '%s'...\n", targetBranch)
-			if err := git.FetchBranch(targetBranch); err != nil {
-				fmt.Printf("[!] Warning: Failed to fetch branch: %v\n", err)
-				// Continue anyway, might be a local branch
-			}
-
-			// Checkout to target branch
-			fmt.Printf("Checking out to branch '%s'...\n", targetBranch)
-			if err := git.CheckoutBranch(targetBranch); err != nil {
-				return err
-			}
-
-			// Pull latest changes
-			fmt.Printf("Pulling latest changes for '%s'...\n", targetBranch)
-			if err := git.PullBranch(); err != nil {
-				return err
-			}
-
-			// Check for merge conflicts before merging
-			fmt.Printf("Checking for potential merge conflicts...\n")
-			hasConflicts, err := git.CheckMergeConflicts(currentBranch)
-			if err != nil {
-				return fmt.Errorf("failed to check merge conflicts: %w", err)
-			}
-
-			if hasConflicts {
-				return fmt.Errorf("merge conflicts detected! Cannot merge '%s' into '%s', please resolve conflicts manually", currentBranch, targetBranch)
-			}
-
-			// Merge current branch into target branch
-			fmt.Printf("Merging '%s' into '%s'...\n", currentBranch, targetBranch)
-			if err := git.MergeBranch(currentBranch, false); err != nil {
-				return fmt.Errorf("failed to merge branch: %w", err)
-			}
-
-			// Show success result
-			fmt.Printf("[+] Successfully merged '%s' into '%s'\n", currentBranch, targetBranch)
-			fmt.Printf("Current branch: %s\n", targetBranch)
-
-			return nil
-		},
-	}
-}
-
-func checkoutList() *cli.Command {
-	return &cli.Command{
-		Name:  "ckl",
-		Usage: "Checkout list - list all available branches (local and remote) and checkout to selected one",
-		Action: func(c *cli.Context) error {
-			// Get current branch
-			currentBranch, err := git.GetCurrentBranch()
-			if err != nil {
-				return fmt.Errorf("failed to get current branch: %w", err)
 			}
 
 			// Get all available branches (local + remote branches not in local)
-			allBranches, err := git.GetAllAvailableBranches()
+			allBranches, err := availableBranches(c.Context, gc)
 			if err != nil {
 				return fmt.Errorf("failed to get branches: %w", err)
 			}
@@ -198,56 +115,59 @@ func checkoutList() *cli.Command {
 			}
 
 			// Prompt user to select a branch
-			_, selected, err := prompt.Select("Select branch to checkout:", allBranches, currentBranch)
-			if err != nil {
-				return fmt.Errorf("failed to select branch: %w", err)
-			}
-
-			// Check if already on the selected branch
-			if selected == currentBranch {
-				fmt.Printf("Already on branch '%s'\n", currentBranch)
-				return nil
-			}
-
-			// Check if it's a remote branch (doesn't exist locally)
-			localBranches, err := git.GetLocalBranches()
-			if err != nil {
-				return fmt.Errorf("failed to check local branches: %w", err)
-			}
-
-			isLocal := false
-			for _, branch := range localBranches {
-				if branch == selected {
-					isLocal = true
-					break
+			labels := make([]string, len(allBranches))
+			defaultLabel := ""
+			for i, branch := range allBranches {
+				labels[i] = branch
+				if gone[branch] {
+					labels[i] = branch + " [gone]"
 				}
-			}
-
-			// If it's a remote branch, create a local tracking branch
-			if !isLocal {
-				fmt.Printf("Branch '%s' is a remote branch. Creating local tracking branch...\n", selected)
-				// Fetch the remote branch first
-				if err := git.FetchBranch(selected); err != nil {
-					fmt.Printf("[-] Failed to fetch branch: %v\n", err)
+				if branch == currentBranch {
+					defaultLabel = labels[i]
 				}
-				// Checkout with tracking 	- use git command directly
-				cmd := exec.Command("git", "checkout", "-b", selected, "origin/"+selected)
-				output, err := cmd.CombinedOutput()
-				if err != nil {
-					return fmt.Errorf("failed to checkout remote branch: %w\n%s", err, string(output))
-				}
-				fmt.Printf("[+] Created and checked out to branch '%s' (tracking origin/%s)\n", selected, selected)
-				return nil
 			}
-
-			// It's a local branch, just checkout
-			fmt.Printf("Checking out to branch '%s'...\n", selected)
-			if err := git.CheckoutBranch(selected); err != nil {
-				return fmt.Errorf("failed to checkout branch: %v", err)
+			idx, _, err := prompt.Select("Select branch:", labels, defaultLabel, prompt.WithPreview(func(_ string, i int) string {
+				return branchPreview(c.Context, gc, allBranches[i])
+			}))
+			if err != nil {
+				return fmt.Errorf("failed to select branch: %w", err)
 			}
 
-			fmt.Printf("[+] Checked out to branch '%s'\n", selected)
-			return nil
+			return branchActions(c.Context, gc, allBranches[idx], currentBranch)
 		},
 	}
 }
+
+// branchPreview lists the latest commits of branch, from origin when it isn't local.
+func branchPreview(ctx context.Context, gc git.GitClient, branch string) string {
+	commits, err := gc.RecentCommits(ctx, branch, 10)
+	if err != nil {
+		commits, err = gc.RecentCommits(ctx, "origin/"+branch, 10)
+	}
+	if err != nil {
+		return ""
+	}
+	return strings.Join(commits, "\n")
+}
+
+// availableBranches returns the local branches and the remote ones not checked
+// out, taking the remote branches from the daemon when it runs: it fetches
+// them in the background.
+func availableBranches(ctx context.Context, gc git.GitClient) ([]string, error) {
+	localBranches, err := gc.LocalBranches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var remoteBranches []string
+	fromDaemon := false
+	if top, err := gc.TopLevel(ctx); err == nil {
+		_, fromDaemon = daemon.Query(daemon.KindBranches, []string{top}, &remoteBranches)
+	}
+	if !fromDaemon {
+		if remoteBranches, err = gc.RemoteBranches(ctx); err != nil {
+			// Without the remote branches, just offer the local ones
+			return localBranches, nil
+		}
+	}
+	return git.CombineBranches(localBranches, remoteBranches), nil
+}
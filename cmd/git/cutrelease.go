@@ -0,0 +1,201 @@
+package git
+
+import (
+	"cli-aio/cmd/ztag"
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/pkg/release"
+	"cli-aio/internal/prompt"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var releaseVersionRegex = regexp.MustCompile(`^\d+\.\d+$`)
+
+func cutReleaseCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "cut-release",
+		Usage: "Create a release/x.y branch from main at a chosen commit, push and protect it",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "version",
+				Usage: "Release version x.y (default: next minor after the latest tag)",
+			},
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "Base branch to cut from (default: origin/main or origin/master)",
+			},
+			&cli.StringFlag{
+				Name:  "commit",
+				Usage: "Commit to cut at (default: pick from the latest commits of the base branch)",
+			},
+			&cli.StringSliceFlag{
+				Name:    "env",
+				Aliases: []string{"e"},
+				Usage:   "Environments ztag may deploy the release branch to",
+				Value:   cli.NewStringSlice(string(ztag.EnvStg), string(ztag.EnvProd)),
+			},
+			&cli.BoolFlag{
+				Name:  "no-protect",
+				Usage: "Don't protect the branch on GitLab",
+			},
+		},
+		Before: cmd.Require(cmd.RequireTool("git"), cmd.RequireGitRepo()),
+		Action: func(c *cli.Context) error {
+			projectID, err := git.ExtractProjectID(c.Context)
+			if err != nil {
+				return err
+			}
+
+			base, err := releaseBase(c.Context, c.String("from"))
+			if err != nil {
+				return err
+			}
+
+			version := c.String("version")
+			if version == "" {
+				version, err = prompt.Input("Release version (x.y):", nextReleaseVersion(c.Context), true)
+				if err != nil {
+					return fmt.Errorf("input cancelled: %w", err)
+				}
+			}
+			version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+			if !releaseVersionRegex.MatchString(version) {
+				return fmt.Errorf("invalid release version: %s (expected x.y)", version)
+			}
+			branch := "release/" + version
+			if exists, _ := git.BranchExists(c.Context, branch); exists {
+				return fmt.Errorf("branch '%s' already exists", branch)
+			}
+
+			commit, err := releaseCommit(c.Context, c.String("commit"), base)
+			if err != nil {
+				return err
+			}
+
+			envs := c.StringSlice("env")
+			for _, env := range envs {
+				switch ztag.Env(env) {
+				case ztag.EnvQC, ztag.EnvStg, ztag.EnvProd:
+				default:
+					return fmt.Errorf("invalid environment: %s", env)
+				}
+			}
+
+			fmt.Printf("Creating branch '%s' at %s (from %s)...\n", branch, git.ShortSHA(commit), base)
+			if err := git.CreateBranchAt(c.Context, branch, commit); err != nil {
+				return err
+			}
+			fmt.Printf("Pushing branch '%s'...\n", branch)
+			if err := git.PushBranch(c.Context, branch); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Pushed branch '%s'\n", branch)
+
+			if !c.Bool("no-protect") {
+				protectReleaseBranch(c.Context, projectID, branch)
+			}
+
+			err = release.RecordBranch(projectID, &release.Branch{
+				Name:      branch,
+				Version:   version,
+				Commit:    commit,
+				Envs:      envs,
+				CreatedAt: time.Now(),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to record release branch: %w", err)
+			}
+			fmt.Printf("[+] Recorded '%s' for %s, tag it with: aio ztag <env> on that branch\n", branch, strings.Join(envs, ", "))
+			return nil
+		},
+	}
+}
+
+// releaseBase returns the ref to cut the release branch from, fetching it first.
+func releaseBase(ctx context.Context, from string) (string, error) {
+	candidates := []string{"main", "master"}
+	if from != "" {
+		candidates = []string{strings.TrimPrefix(from, "origin/")}
+	}
+	for _, branch := range candidates {
+		if err := git.FetchBranch(ctx, branch); err != nil && from == "" {
+			continue
+		}
+		if git.RemoteBranchExists(ctx, branch) {
+			return "origin/" + branch, nil
+		}
+		if exists, _ := git.BranchExists(ctx, branch); exists {
+			return branch, nil
+		}
+	}
+	if from != "" {
+		return "", fmt.Errorf("base branch '%s' does not exist", from)
+	}
+	return "", fmt.Errorf("neither main nor master exists on origin, use --from")
+}
+
+// nextReleaseVersion suggests the minor version after the latest tag, e.g. v1.4.2 -> 1.5.
+func nextReleaseVersion(ctx context.Context) string {
+	tags, err := git.GetLatestTags(ctx, 1)
+	if err != nil || len(tags) == 0 {
+		return ""
+	}
+	v, err := ztag.ParseTag(tags[0])
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor+1)
+}
+
+// releaseCommit resolves --commit, or lets the user pick one of the latest commits of base.
+func releaseCommit(ctx context.Context, commit string, base string) (string, error) {
+	if commit == "" {
+		commits, err := git.RecentCommits(ctx, base, 20)
+		if err != nil {
+			return "", err
+		}
+		if len(commits) == 0 {
+			return "", fmt.Errorf("no commits on %s", base)
+		}
+		_, selected, err := prompt.SelectWithFuzzy(fmt.Sprintf("Cut release at (%s):", base), commits, commits[0], false)
+		if err != nil {
+			return "", fmt.Errorf("selection cancelled: %w", err)
+		}
+		commit = strings.Fields(selected)[0]
+	}
+
+	sha, err := git.ResolveCommit(ctx, commit)
+	if err != nil {
+		return "", err
+	}
+	if !git.IsAncestor(ctx, sha, base) {
+		fmt.Printf("[!] %s is not on %s\n", git.ShortSHA(sha), base)
+	}
+	return sha, nil
+}
+
+// protectReleaseBranch protects branch on GitLab. Failures only warn: the
+// branch is already pushed and protecting needs Maintainer access.
+func protectReleaseBranch(ctx context.Context, projectID string, branch string) {
+	client, err := gitlab.NewClient(ctx)
+	if err != nil {
+		fmt.Printf("[!] Branch not protected: %v\n", err)
+		return
+	}
+	if _, err := client.ProtectBranch(projectID, branch); err != nil {
+		if gitlab.IsForbidden(err) {
+			fmt.Printf("[!] Branch not protected: Maintainer access to %s is required\n", projectID)
+			return
+		}
+		fmt.Printf("[!] Branch not protected: %v\n", err)
+		return
+	}
+	fmt.Printf("[+] Protected branch '%s'\n", branch)
+}
@@ -0,0 +1,107 @@
+package git
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/git"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// hookChecks are the aio commands a pre-push hook can run, all taking --hook.
+var hookChecks = []string{"lint-branch", "lint-commits"}
+
+// prePushHook returns the aio part of the pre-push hook running checks. The
+// pushed refs arrive on stdin once, so they are kept for each check.
+func prePushHook(checks []string) string {
+	var b strings.Builder
+	b.WriteString("if command -v aio >/dev/null 2>&1; then\n  refs=$(cat)\n")
+	for _, check := range checks {
+		fmt.Fprintf(&b, "  printf '%%s\\n' \"$refs\" | aio --plain git %s --hook || exit 1\n", check)
+	}
+	b.WriteString("else\n  echo \"[!] aio not found, pre-push checks skipped\" >&2\nfi")
+	return b.String()
+}
+
+// hooksCmd installs the git hooks running aio's checks.
+func hooksCmd() *cli.Command {
+	return &cli.Command{
+		Name:   "hooks",
+		Usage:  "Install or remove the pre-push hook checking branch names and commit messages",
+		Before: cmd.Require(cmd.RequireTool("git"), cmd.RequireGitRepo()),
+		Subcommands: []*cli.Command{
+			{
+				Name:  "install",
+				Usage: "Add the checks to the pre-push hook (other hook content is kept)",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "commits",
+						Usage: "Also check the messages of the pushed commits (aio git lint-commits)",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					root, err := git.GetTopLevel(c.Context)
+					if err != nil {
+						return err
+					}
+					checks := hookChecks[:1]
+					if c.Bool("commits") {
+						checks = hookChecks
+					}
+					file, err := git.InstallHookIn(c.Context, root, "pre-push", prePushHook(checks))
+					if err != nil {
+						return err
+					}
+					fmt.Printf("[+] Installed %s\n", file)
+					return nil
+				},
+			},
+			{
+				Name:  "uninstall",
+				Usage: "Remove the checks from the pre-push hook",
+				Action: func(c *cli.Context) error {
+					root, err := git.GetTopLevel(c.Context)
+					if err != nil {
+						return err
+					}
+					removed, err := git.UninstallHookIn(c.Context, root, "pre-push")
+					if err != nil {
+						return err
+					}
+					if !removed {
+						fmt.Println("[!] No aio checks in the pre-push hook")
+						return nil
+					}
+					fmt.Println("[+] Removed the aio checks from the pre-push hook")
+					return nil
+				},
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+			}
+			root, err := git.GetTopLevel(c.Context)
+			if err != nil {
+				return err
+			}
+			block, err := git.HookBlockIn(c.Context, root, "pre-push")
+			if err != nil {
+				return err
+			}
+			if block == "" {
+				fmt.Println("[!] No aio checks installed, use 'aio git hooks install'")
+				return nil
+			}
+			var checks []string
+			for _, check := range hookChecks {
+				if strings.Contains(block, "git "+check) {
+					checks = append(checks, check)
+				}
+			}
+			fmt.Printf("[+] pre-push runs: %s\n", strings.Join(checks, ", "))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,303 @@
+package git
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/identity"
+	"cli-aio/internal/prompt"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+// idCmd manages git identities and which one each repository uses.
+func idCmd() *cli.Command {
+	show := idShowCmd()
+	subcommands := []*cli.Command{
+		show,
+		idUseCmd(),
+		idAddCmd(),
+		idListCmd(),
+		idRemoveCmd(),
+		idRuleCmd(),
+	}
+
+	return cmd.Describe(&cli.Command{
+		Name:        "id",
+		Usage:       "Manage git identities (name, email, signing key) and switch the repository to one",
+		Subcommands: subcommands,
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				if !cmd.ValidateSubcommand(c, subcommands) {
+					return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+				}
+				return nil
+			}
+			return show.Action(c)
+		},
+	}, `Identities are profiles of user.name, user.email and optionally user.signingkey (which also turns
+on commit.gpgsign). 'use' writes them to the repository's local config. Rules map directories to a
+profile, e.g. ~/work/** to work: 'use' without a profile applies the matching rule, 'prj clone' applies
+it to new clones and 'id' warns when a repository doesn't follow it.`,
+		cmd.Example{Command: "aio git id add work", Comment: "Create a profile"},
+		cmd.Example{Command: "aio git id rule ~/work/** work", Comment: "Use it for every repository under ~/work"},
+		cmd.Example{Command: "aio git id use", Comment: "Switch the current repository to its rule's profile (or pick one)"},
+	)
+}
+
+func idShowCmd() *cli.Command {
+	return &cli.Command{
+		Name:   "show",
+		Usage:  "Show the identity the current repository commits with",
+		Before: cmd.Require(cmd.RequireTool("git"), cmd.RequireGitRepo()),
+		Action: func(c *cli.Context) error {
+			store, err := identity.Load()
+			if err != nil {
+				return err
+			}
+			name, email := git.GetConfig(c.Context, "user.name"), git.GetConfig(c.Context, "user.email")
+			if email == "" {
+				fmt.Println("[!] No identity configured (user.email is not set)")
+			} else {
+				scope := "global"
+				if git.GetLocalConfig(c.Context, "user.email") != "" {
+					scope = "local"
+				}
+				label := "not a saved profile"
+				if p, ok := store.ByEmail(email); ok {
+					label = "profile " + p.Name
+				}
+				fmt.Printf("%s <%s> (%s, %s)\n", name, email, scope, label)
+				if key := git.GetConfig(c.Context, "user.signingkey"); key != "" {
+					fmt.Printf("Signing key: %s (commit.gpgsign=%s)\n", key, orDefault(git.GetConfig(c.Context, "commit.gpgsign"), "false"))
+				}
+			}
+
+			root, err := git.GetTopLevel(c.Context)
+			if err != nil {
+				return err
+			}
+			if rule := store.Match(root); rule != nil {
+				if p, ok := store.Find(rule.Profile); ok && p.Email != email {
+					fmt.Printf("[!] Rule %s expects profile %s, run 'aio git id use' to switch\n", rule.Pattern, p.Name)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func idUseCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "use",
+		Usage:     "Switch the repository's local config to a profile (default: the matching rule's)",
+		ArgsUsage: "[profile]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "auto",
+				Usage: "Only apply the matching rule, never prompt (does nothing without one)",
+			},
+		},
+		Before: cmd.Require(cmd.RequireTool("git"), cmd.RequireGitRepo()),
+		Action: func(c *cli.Context) error {
+			store, err := identity.Load()
+			if err != nil {
+				return err
+			}
+			root, err := git.GetTopLevel(c.Context)
+			if err != nil {
+				return err
+			}
+
+			name := c.Args().First()
+			if name == "" {
+				if rule := store.Match(root); rule != nil {
+					name = rule.Profile
+					fmt.Printf("Rule %s -> %s\n", rule.Pattern, name)
+				} else if c.Bool("auto") {
+					return nil
+				}
+			}
+			p, err := selectIdentity(store, name)
+			if err != nil {
+				return err
+			}
+			if err := identity.Apply(c.Context, root, *p); err != nil {
+				return err
+			}
+			fmt.Printf("[+] %s now commits as %s <%s>\n", root, p.UserName, p.Email)
+			return nil
+		},
+	}
+}
+
+// selectIdentity returns the named profile, or lets the user pick one when name is empty.
+func selectIdentity(store *identity.Store, name string) (*identity.Profile, error) {
+	if len(store.Profiles) == 0 {
+		return nil, fmt.Errorf("no identities saved, add one with 'aio git id add <name>'")
+	}
+	if name != "" {
+		p, ok := store.Find(name)
+		if !ok {
+			return nil, fmt.Errorf("identity '%s' not found, see 'aio git id list'", name)
+		}
+		return p, nil
+	}
+	labels := make([]string, len(store.Profiles))
+	for i, p := range store.Profiles {
+		labels[i] = p.String()
+	}
+	idx, _, err := prompt.Select("Select identity:", labels, "")
+	if err != nil {
+		return nil, fmt.Errorf("selection cancelled: %w", err)
+	}
+	return &store.Profiles[idx], nil
+}
+
+func idAddCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "add",
+		Usage:     "Add or update an identity profile",
+		ArgsUsage: "[profile]",
+		Action: func(c *cli.Context) error {
+			store, err := identity.Load()
+			if err != nil {
+				return err
+			}
+			name := c.Args().First()
+			if name == "" {
+				if name, err = prompt.Input("Profile name (e.g. work):", "", true); err != nil {
+					return fmt.Errorf("input cancelled: %w", err)
+				}
+			}
+			current := identity.Profile{UserName: git.GetConfig(c.Context, "user.name"), Email: git.GetConfig(c.Context, "user.email")}
+			if existing, ok := store.Find(name); ok {
+				current = *existing
+			}
+
+			p := identity.Profile{Name: name}
+			if p.UserName, err = prompt.Input("Name:", current.UserName, true); err != nil {
+				return fmt.Errorf("input cancelled: %w", err)
+			}
+			if p.Email, err = prompt.Input("Email:", current.Email, true); err != nil {
+				return fmt.Errorf("input cancelled: %w", err)
+			}
+			if p.SigningKey, err = prompt.Input("Signing key (optional):", current.SigningKey, false); err != nil {
+				return fmt.Errorf("input cancelled: %w", err)
+			}
+			store.Put(p)
+			if err := identity.Save(store); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Saved identity %s\n", p)
+			return nil
+		},
+	}
+}
+
+func idListCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List the identity profiles and rules",
+		Action: func(c *cli.Context) error {
+			store, err := identity.Load()
+			if err != nil {
+				return err
+			}
+			if len(store.Profiles) == 0 {
+				fmt.Println("[!] No identities saved. Use 'aio git id add <name>' to add one.")
+				return nil
+			}
+			for _, p := range store.Profiles {
+				fmt.Println(p)
+			}
+			if len(store.Rules) > 0 {
+				fmt.Println("\nRules:")
+				for _, r := range store.Rules {
+					fmt.Printf("  %-30s -> %s\n", r.Pattern, r.Profile)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func idRemoveCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "rm",
+		Usage:     "Remove an identity profile and its rules",
+		ArgsUsage: "[profile]",
+		Action: func(c *cli.Context) error {
+			store, err := identity.Load()
+			if err != nil {
+				return err
+			}
+			p, err := selectIdentity(store, c.Args().First())
+			if err != nil {
+				return err
+			}
+			name := p.Name
+			store.Remove(name)
+			if err := identity.Save(store); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Removed identity '%s'\n", name)
+			return nil
+		},
+	}
+}
+
+func idRuleCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "rule",
+		Usage:     "Use a profile for every repository under a directory, e.g. rule ~/work/** work",
+		ArgsUsage: "<pattern> <profile>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "remove",
+				Aliases: []string{"r"},
+				Usage:   "Remove the rule with this pattern",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			store, err := identity.Load()
+			if err != nil {
+				return err
+			}
+			if pattern := c.String("remove"); pattern != "" {
+				if !store.RemoveRule(pattern) {
+					return fmt.Errorf("no rule for %s", pattern)
+				}
+				if err := identity.Save(store); err != nil {
+					return err
+				}
+				fmt.Printf("[+] Removed rule %s\n", pattern)
+				return nil
+			}
+
+			if c.Args().Len() != 2 {
+				return fmt.Errorf("usage: aio git id rule <pattern> <profile>")
+			}
+			pattern, name := c.Args().Get(0), c.Args().Get(1)
+			if err := identity.ValidatePattern(pattern); err != nil {
+				return err
+			}
+			if _, ok := store.Find(name); !ok {
+				return fmt.Errorf("identity '%s' not found, see 'aio git id list'", name)
+			}
+			store.AddRule(pattern, name)
+			if err := identity.Save(store); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Repositories under %s use %s\n", pattern, name)
+			return nil
+		},
+	}
+}
+
+func orDefault(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
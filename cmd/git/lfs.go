@@ -0,0 +1,146 @@
+package git
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/ui"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// lfsLargest is how many of the largest LFS files status lists.
+const lfsLargest = 5
+
+// lfsCmd summarises and cleans up Git LFS storage.
+func lfsCmd() *cli.Command {
+	status := lfsStatusCmd()
+	subcommands := []*cli.Command{
+		status,
+		lfsPruneCmd(),
+	}
+
+	return &cli.Command{
+		Name:        "lfs",
+		Usage:       "Show Git LFS usage and storage of the repository, prune old objects",
+		Subcommands: subcommands,
+		Before:      cmd.Require(cmd.RequireTool("git"), cmd.RequireGitRepo()),
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				if !cmd.ValidateSubcommand(c, subcommands) {
+					return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+				}
+				return nil
+			}
+			return status.Action(c)
+		},
+	}
+}
+
+func lfsStatusCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "status",
+		Usage: "Show the LFS patterns, files and local storage of the repository",
+		Action: func(c *cli.Context) error {
+			root, err := git.GetTopLevel(c.Context)
+			if err != nil {
+				return err
+			}
+			version := git.LFSVersion(c.Context)
+			patterns := git.LFSPatternsIn(c.Context, root)
+			if len(patterns) == 0 {
+				fmt.Println("[!] This repository doesn't use Git LFS (no filter=lfs in .gitattributes)")
+				if version == "" {
+					fmt.Println("    git-lfs is not installed either")
+				}
+				return nil
+			}
+
+			files, err := git.LFSFilesIn(c.Context, root)
+			if err != nil {
+				return err
+			}
+			var total, local int64
+			downloaded := 0
+			for _, f := range files {
+				total += f.Size
+				if f.Local {
+					local += f.Size
+					downloaded++
+				}
+			}
+			storage, err := git.LFSStorageIn(c.Context, root)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("git-lfs:  %s\n", orDefault(version, ui.Colorize(ui.Red, "not installed")))
+			fmt.Printf("Patterns: %s\n", strings.Join(patterns, " "))
+			fmt.Printf("Files:    %d (%s), %d downloaded (%s)\n", len(files), ui.Size(total), downloaded, ui.Size(local))
+			fmt.Printf("Storage:  %s in .git/lfs\n", ui.Size(storage))
+
+			if len(files) > 0 {
+				sort.SliceStable(files, func(i, j int) bool { return files[i].Size > files[j].Size })
+				fmt.Println("\nLargest:")
+				for _, f := range files[:min(len(files), lfsLargest)] {
+					fmt.Printf("  %10s  %s\n", ui.Size(f.Size), f.Path)
+				}
+			}
+
+			if pointers := git.LFSPointerFilesIn(root, files); len(pointers) > 0 {
+				fmt.Printf("\n[!] %d file(s) are still LFS pointers in the working tree, ", len(pointers))
+				if version == "" {
+					fmt.Println("install git-lfs then run 'git lfs pull'")
+				} else {
+					fmt.Println("run 'git lfs pull' to download them")
+				}
+			}
+			if storage > local && version != "" {
+				fmt.Printf("[!] %s of the store is not used by the current files, 'aio git lfs prune' may free some\n", ui.Size(storage-local))
+			}
+			return nil
+		},
+	}
+}
+
+func lfsPruneCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "prune",
+		Usage: "Delete local LFS objects not needed by recent commits (git lfs prune) and show the space freed",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "Only show what would be deleted",
+			},
+		},
+		Before: cmd.Require(cmd.RequireTool("git-lfs")),
+		Action: func(c *cli.Context) error {
+			root, err := git.GetTopLevel(c.Context)
+			if err != nil {
+				return err
+			}
+			before, err := git.LFSStorageIn(c.Context, root)
+			if err != nil {
+				return err
+			}
+			output, err := git.LFSPrune(c.Context, c.Bool("dry-run"))
+			if err != nil {
+				return err
+			}
+			fmt.Print(output)
+			if c.Bool("dry-run") {
+				return nil
+			}
+
+			after, err := git.LFSStorageIn(c.Context, root)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("[+] Freed %s (%s -> %s)\n", ui.Size(before-after), ui.Size(before), ui.Size(after))
+			return nil
+		},
+	}
+}
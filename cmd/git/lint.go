@@ -0,0 +1,279 @@
+package git
+
+import (
+	"bufio"
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/convention"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/prompt"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// lintBranchCmd checks branch names against the configured conventions.
+func lintBranchCmd() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:      "lint-branch",
+		Usage:     "Check branch names against the naming convention and Jira key rules",
+		ArgsUsage: "[branch...]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "all",
+				Aliases: []string{"a"},
+				Usage:   "Check every local branch instead of the current one",
+			},
+			&cli.BoolFlag{
+				Name:  "fix",
+				Usage: "Rename the branches to the suggested names (asks for each)",
+			},
+			&cli.BoolFlag{
+				Name:  "hook",
+				Usage: "Read the pushed refs from stdin, as a pre-push hook",
+			},
+		},
+		Before: cmd.Require(cmd.RequireTool("git"), cmd.RequireGitRepo()),
+		Action: func(c *cli.Context) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			rules, err := convention.NewRules(cfg.Conventions)
+			if err != nil {
+				return err
+			}
+			branches, err := lintTargets(c)
+			if err != nil {
+				return err
+			}
+
+			failed := 0
+			for _, branch := range branches {
+				problems := rules.CheckBranch(branch)
+				if len(problems) == 0 {
+					if !c.Bool("hook") {
+						fmt.Printf("[+] %s\n", branch)
+					}
+					continue
+				}
+				failed++
+				fmt.Fprintf(os.Stderr, "[-] %s\n", branch)
+				for _, problem := range problems {
+					fmt.Fprintf(os.Stderr, "    - %s\n", problem)
+				}
+				suggestion := rules.SuggestBranch(branch)
+				if suggestion == "" {
+					continue
+				}
+				if c.Bool("fix") && !c.Bool("hook") {
+					if renamed, err := fixBranch(c.Context, branch, suggestion); err != nil {
+						return err
+					} else if renamed {
+						failed--
+					}
+					continue
+				}
+				fmt.Fprintf(os.Stderr, "    -> suggested: %s (aio git lint-branch --fix)\n", suggestion)
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d branch name(s) don't follow the convention", failed)
+			}
+			return nil
+		},
+	}, `Without conventions in config.json, branches are named <type>/<description>, where type is one
+of feature, fix, hotfix, chore, docs, refactor or test and the description is lowercase words separated
+by '-', optionally starting with a Jira key (feature/ABC-123-login-form). main, master, develop and
+release/* are not checked. Change the rules with conventions.branch_types, branch_pattern (a regular
+expression), require_jira_key, jira_projects and exempt_branches. Exits non-zero when a name is
+wrong, so it can block a push: see 'aio git hooks install'.`,
+		cmd.Example{Command: "aio git lint-branch", Comment: "Check the current branch"},
+		cmd.Example{Command: "aio git lint-branch --all --fix", Comment: "Check and rename all local branches"},
+	)
+}
+
+// lintCommitsCmd checks commit messages against the configured conventions.
+func lintCommitsCmd() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:      "lint-commits",
+		Usage:     "Check commit messages against the conventional commit and Jira key rules",
+		ArgsUsage: "[range]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "hook",
+				Usage: "Check the pushed commits read from stdin, as a pre-push hook",
+			},
+		},
+		Before: cmd.Require(cmd.RequireTool("git"), cmd.RequireGitRepo()),
+		Action: func(c *cli.Context) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			rules, err := convention.NewRules(cfg.Conventions)
+			if err != nil {
+				return err
+			}
+			ranges, err := commitRanges(c)
+			if err != nil {
+				return err
+			}
+			var commits []git.CommitMessage
+			seen := map[string]bool{}
+			for _, revs := range ranges {
+				listed, err := git.CommitMessages(c.Context, revs)
+				if err != nil {
+					return err
+				}
+				for _, commit := range listed {
+					if !seen[commit.SHA] && !convention.SkipCommit(commit.Subject, commit.Merge) {
+						seen[commit.SHA] = true
+						commits = append(commits, commit)
+					}
+				}
+			}
+			if len(commits) == 0 {
+				if !c.Bool("hook") {
+					fmt.Println("[+] No commits to check")
+				}
+				return nil
+			}
+
+			var oldest string
+			failed := 0
+			for _, commit := range commits {
+				problems := rules.CheckCommit(commit.Subject, commit.Body)
+				if len(problems) == 0 {
+					if !c.Bool("hook") {
+						fmt.Printf("[+] %s %s\n", commit.Short(), commit.Subject)
+					}
+					continue
+				}
+				failed++
+				oldest = commit.Short()
+				fmt.Fprintf(os.Stderr, "[-] %s %s\n", commit.Short(), commit.Subject)
+				for _, problem := range problems {
+					fmt.Fprintf(os.Stderr, "    - %s\n", problem)
+				}
+				if suggestion := rules.SuggestSubject(commit.Subject); suggestion != "" {
+					fmt.Fprintf(os.Stderr, "    -> suggested: %s\n", suggestion)
+				}
+			}
+
+			if failed > 0 {
+				fmt.Fprintf(os.Stderr, "-> Reword them with 'git rebase -i %s^' (mark the commits 'reword')\n", oldest)
+				return fmt.Errorf("%d of %d commit message(s) don't follow the convention", failed, len(commits))
+			}
+			return nil
+		},
+	}, `Without conventions in config.json, subjects are conventional commits: <type>(<scope>): <description>,
+where type is one of feat, fix, docs, style, refactor, perf, test, build, ci, chore or revert, the
+scope is optional, a '!' before ':' marks a breaking change and the description doesn't end with a
+period. Subjects are at most 72 characters and followed by a blank line when there is a body.
+fixup!/squash! commits are reported, merges and 'git revert' commits are not checked. Change the rules
+with conventions.commit_types, commit_pattern (a regular expression), require_commit_jira_key,
+jira_projects and subject_max_length. The range defaults to the commits not pushed to the upstream
+branch (or to any remote). Exits non-zero when a message is wrong, for CI or a pre-push hook: see
+'aio git hooks install --commits'.`,
+		cmd.Example{Command: "aio git lint-commits", Comment: "Check the commits not pushed yet"},
+		cmd.Example{Command: "aio git lint-commits origin/main..HEAD", Comment: "Check a merge request's commits in CI"},
+	)
+}
+
+// commitRanges returns the rev-list arguments of the commits to check: the
+// arguments, the pushed commits not on a remote yet with --hook, or the
+// commits of the current branch not pushed.
+func commitRanges(c *cli.Context) ([][]string, error) {
+	switch {
+	case c.Args().Len() > 0:
+		return [][]string{c.Args().Slice()}, nil
+	case c.Bool("hook"):
+		refs, err := pushedRefs(os.Stdin)
+		var ranges [][]string
+		for _, ref := range refs {
+			ranges = append(ranges, git.PatchRange("", ref.SHA))
+		}
+		return ranges, err
+	}
+	return [][]string{git.PatchRange(git.Upstream(c.Context), "HEAD")}, nil
+}
+
+// lintTargets returns the branches to check: the arguments, the pushed
+// branches with --hook, every local branch with --all, or the current one.
+func lintTargets(c *cli.Context) ([]string, error) {
+	switch {
+	case c.Args().Len() > 0:
+		return c.Args().Slice(), nil
+	case c.Bool("hook"):
+		return pushedBranches(os.Stdin)
+	case c.Bool("all"):
+		return git.GetLocalBranches(c.Context)
+	}
+	head, err := git.GetCurrentBranch(c.Context)
+	if err != nil {
+		return nil, err
+	}
+	if head.Branch == "" {
+		return nil, fmt.Errorf("HEAD is detached, pass a branch name or use --all")
+	}
+	return []string{head.Branch}, nil
+}
+
+// pushedRef is a branch created or updated by a push.
+type pushedRef struct {
+	Branch string // the branch name on the remote
+	SHA    string // the commit pushed
+}
+
+// pushedRefs parses the refs git passes to a pre-push hook
+// ("<local ref> <local sha> <remote ref> <remote sha>" per line) and returns
+// the branches created or updated on the remote.
+func pushedRefs(r io.Reader) ([]pushedRef, error) {
+	var refs []pushedRef
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 || strings.Trim(fields[1], "0") == "" {
+			// Malformed, or a deletion
+			continue
+		}
+		if branch, ok := strings.CutPrefix(fields[2], "refs/heads/"); ok {
+			refs = append(refs, pushedRef{Branch: branch, SHA: fields[1]})
+		}
+	}
+	return refs, scanner.Err()
+}
+
+// pushedBranches returns the names of the branches a push creates or updates.
+func pushedBranches(r io.Reader) ([]string, error) {
+	refs, err := pushedRefs(r)
+	var branches []string
+	for _, ref := range refs {
+		branches = append(branches, ref.Branch)
+	}
+	return branches, err
+}
+
+// fixBranch renames branch to suggestion once confirmed. Returns whether it did.
+func fixBranch(ctx context.Context, branch string, suggestion string) (bool, error) {
+	ok, err := prompt.Confirm(fmt.Sprintf("Rename %s to %s?", branch, suggestion), true)
+	if err != nil {
+		return false, fmt.Errorf("confirmation cancelled: %w", err)
+	}
+	if !ok {
+		return false, nil
+	}
+	if err := git.RenameBranch(ctx, branch, suggestion); err != nil {
+		return false, err
+	}
+	fmt.Printf("[+] Renamed %s to %s\n", branch, suggestion)
+	if git.RemoteBranchExists(ctx, branch) {
+		fmt.Printf("[!] origin/%s keeps its name: push %s and delete the old one ('git push origin :%s')\n", branch, suggestion, branch)
+	}
+	return true, nil
+}
@@ -0,0 +1,149 @@
+package git
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/project"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+// remoteChange is a remote of a saved project whose URL moves.
+type remoteChange struct {
+	Project project.Project
+	Remote  string
+	From    string
+	To      string
+}
+
+// migrateRemoteCmd rewrites the remotes of the saved projects after a move between groups or hosts.
+func migrateRemoteCmd() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:      "migrate-remote",
+		Usage:     "Rewrite the remotes of saved projects moved to another GitLab group or host",
+		ArgsUsage: "<old> <new> [<old> <new>...]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "Only show the changes",
+			},
+			&cli.StringSliceFlag{
+				Name:    "tag",
+				Aliases: []string{"t"},
+				Usage:   "Only projects with this tag (repeatable)",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Apply the changes without asking",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			args := c.Args().Slice()
+			if len(args) == 0 || len(args)%2 != 0 {
+				return fmt.Errorf("expected pairs of <old> <new> locations, e.g. 'gitlab.old.vn/bank/ops gitlab.new.vn/bank/operation'")
+			}
+			store, err := project.Load()
+			if err != nil {
+				return err
+			}
+			projects := project.FilterByTags(store.Projects, c.StringSlice("tag"))
+
+			changes, err := remoteChanges(c.Context, projects, args)
+			if err != nil {
+				return err
+			}
+			if len(changes) == 0 {
+				fmt.Println("[+] No saved project has a remote matching the old locations")
+				return nil
+			}
+			printRemoteChanges(changes)
+			if c.Bool("dry-run") {
+				return nil
+			}
+			if !c.Bool("yes") {
+				ok, err := prompt.Confirm(fmt.Sprintf("Update %d remote(s)?", len(changes)), false)
+				if err != nil {
+					return fmt.Errorf("not updating, use --yes to apply the changes: %w", err)
+				}
+				if !ok {
+					fmt.Println("[-] Cancelled")
+					return nil
+				}
+			}
+
+			failed := 0
+			for _, change := range changes {
+				if err := git.SetRemoteURLIn(c.Context, change.Project.Path, change.Remote, change.To); err != nil {
+					fmt.Printf("[-] %s: %v\n", change.Project.Name, err)
+					failed++
+					continue
+				}
+				project.UpdateRemote(store, change.Project.Path, project.DetectRemote(c.Context, change.Project.Path))
+			}
+			if err := project.Save(store); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Updated %d remote(s)\n", len(changes)-failed)
+			if failed > 0 {
+				return fmt.Errorf("%d remote(s) could not be updated", failed)
+			}
+			return nil
+		},
+	}, `Locations are "host/path" prefixes (gitlab.old.vn/bank/ops) or path prefixes without a host
+(bank/ops), matched on whole path segments: bank/ops also moves bank/ops/api but not bank/ops-tools.
+Every remote of the saved projects is checked, keeping its form (https, ssh), user and .git suffix.
+The first pair matching a remote wins.`,
+		cmd.Example{Command: "aio git migrate-remote -n bank/ops bank/operation", Comment: "Preview a group rename"},
+		cmd.Example{Command: "aio git migrate-remote gitlab.old.vn gitlab.new.vn", Comment: "Move every project to a new host"},
+	)
+}
+
+// remoteChanges returns the remotes of projects matching one of the old
+// locations of pairs (old, new, old, new...), with their new URL.
+func remoteChanges(ctx context.Context, projects []project.Project, pairs []string) ([]remoteChange, error) {
+	var changes []remoteChange
+	for _, p := range projects {
+		remotes, err := git.RemotesIn(ctx, p.Path)
+		if err != nil {
+			// A missing folder is for 'prj doctor' to report
+			continue
+		}
+		for _, remote := range remotes {
+			url, err := git.RemoteURLIn(ctx, p.Path, remote)
+			if err != nil {
+				continue
+			}
+			for i := 0; i < len(pairs); i += 2 {
+				to, ok, err := git.RewriteRemoteURL(url, pairs[i], pairs[i+1])
+				if err != nil || !ok {
+					continue
+				}
+				if to != url {
+					changes = append(changes, remoteChange{Project: p, Remote: remote, From: url, To: to})
+				}
+				break
+			}
+		}
+	}
+	return changes, nil
+}
+
+// printRemoteChanges shows the changes as a diff per project.
+func printRemoteChanges(changes []remoteChange) {
+	last := ""
+	for _, change := range changes {
+		if change.Project.Path != last {
+			fmt.Printf("%s (%s)\n", change.Project.Name, change.Project.Path)
+			last = change.Project.Path
+		}
+		fmt.Printf("  %s\n", change.Remote)
+		fmt.Println(ui.Colorize(ui.Red, "    - "+change.From))
+		fmt.Println(ui.Colorize(ui.Green, "    + "+change.To))
+	}
+}
@@ -0,0 +1,209 @@
+package git
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/browser"
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/daemon"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/pkg/offline"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+func mrCmd() *cli.Command {
+	subcommands := []*cli.Command{
+		mrListCmd(),
+		mrCreateCmd(),
+	}
+
+	return &cli.Command{
+		Name:        "mr",
+		Usage:       "GitLab merge requests",
+		Subcommands: subcommands,
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				if !cmd.ValidateSubcommand(c, subcommands) {
+					return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+				}
+				return nil
+			}
+			return prompt.SelectCommand(c, subcommands, "Select a subcommand:", cli.ShowSubcommandHelp)
+		},
+	}
+}
+
+// mrListCmd shows MRs where the current user is assignee or reviewer and offers
+// approve, comment and checkout actions on a selected one.
+func mrListCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List open MRs where I'm assignee or reviewer (across gitlab.projects when configured)",
+		Action: func(c *cli.Context) error {
+			client, err := gitlab.NewClient(c.Context)
+			if err != nil {
+				return err
+			}
+			items, err := reviewQueue(client)
+			if err != nil {
+				return err
+			}
+			if len(items) == 0 {
+				fmt.Println("[+] No open merge requests waiting for you")
+				return nil
+			}
+
+			labels := make([]string, len(items))
+			for i, item := range items {
+				labels[i] = mrLabel(item)
+			}
+
+			if !term.IsTerminal(int(os.Stdin.Fd())) {
+				for _, label := range labels {
+					fmt.Println(label)
+				}
+				return nil
+			}
+
+			// Cut long titles rather than wrap the list; the cursor takes two columns
+			if width := ui.Width(); width > 2 {
+				for i := range labels {
+					labels[i] = ui.Truncate(labels[i], width-2)
+				}
+			}
+			idx, _, err := prompt.Select("Select merge request:", labels, "")
+			if err != nil {
+				return fmt.Errorf("selection cancelled: %w", err)
+			}
+			return mrActions(c.Context, client, items[idx].MergeRequest)
+		},
+	}
+}
+
+// reviewQueue returns the MRs waiting for the token's user, from the daemon when it runs.
+func reviewQueue(client *gitlab.Client) ([]gitlab.ReviewItem, error) {
+	var items []gitlab.ReviewItem
+	if _, ok := daemon.Query(daemon.KindReviewQueue, nil, &items); ok {
+		return items, nil
+	}
+	me, err := client.CurrentUser()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return client.ReviewQueue(me.ID, cfg.GitLab.Projects)
+}
+
+func mrLabel(item gitlab.ReviewItem) string {
+	mr := item.MergeRequest
+	title := mr.Title
+	if mr.Draft {
+		title = ui.Colorize(ui.Gray, "[draft] ") + title
+	}
+	return fmt.Sprintf("%-40s %-18s @%-14s %s", mr.References.Full, strings.Join(item.Roles, ","), mr.Author.Username, title)
+}
+
+// mrActions offers follow-up actions for a selected MR.
+func mrActions(ctx context.Context, client *gitlab.Client, mr gitlab.MergeRequest) error {
+	const (
+		actionApprove  = "Approve"
+		actionComment  = "Comment"
+		actionCheckout = "Checkout source branch"
+		actionOpen     = "Open in browser"
+		actionQuit     = "Quit"
+	)
+
+	fmt.Printf("\n%s %s\n", ui.Colorize(ui.Bold, mr.References.Full), mr.Title)
+	fmt.Printf("%s -> %s by @%s\n%s\n\n", mr.SourceBranch, mr.TargetBranch, mr.Author.Username, mr.WebURL)
+
+	options := []string{actionApprove, actionComment, actionCheckout, actionOpen, actionQuit}
+	_, action, err := prompt.SelectWithFuzzy("What next?", options, "", false)
+	if err != nil {
+		return nil
+	}
+
+	switch action {
+	case actionApprove:
+		if err := client.ApproveMergeRequest(mr.ProjectID, mr.IID); err != nil {
+			return err
+		}
+		fmt.Printf("[+] Approved %s\n", mr.References.Full)
+	case actionComment:
+		body, err := prompt.Input("Comment:", "", true)
+		if err != nil {
+			return fmt.Errorf("input cancelled: %w", err)
+		}
+		queued, err := offline.Run(offline.Action{
+			Kind:        offline.ActionComment,
+			Description: fmt.Sprintf("comment on %s", mr.References.Full),
+			Params: map[string]string{
+				"project_id": strconv.Itoa(mr.ProjectID),
+				"iid":        strconv.Itoa(mr.IID),
+				"reference":  mr.References.Full,
+				"body":       body,
+			},
+		}, func() error {
+			return client.CommentMergeRequest(mr.ProjectID, mr.IID, body)
+		})
+		if err != nil {
+			return err
+		}
+		if !queued {
+			fmt.Printf("[+] Commented on %s\n", mr.References.Full)
+		}
+	case actionCheckout:
+		return checkoutMergeRequest(ctx, mr)
+	case actionOpen:
+		return browser.Open(mr.WebURL)
+	}
+	return nil
+}
+
+// checkoutMergeRequest checks out the MR's source branch when the current
+// repository is the MR's project.
+func checkoutMergeRequest(ctx context.Context, mr gitlab.MergeRequest) error {
+	projectID, err := git.ExtractProjectID(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot checkout: %w", err)
+	}
+	if projectID != mr.ProjectPath() {
+		return fmt.Errorf("%s belongs to %s but the current repository is %s, run this from that repository", mr.References.Full, mr.ProjectPath(), projectID)
+	}
+
+	fmt.Printf("Fetching branch '%s'...\n", mr.SourceBranch)
+	if err := git.FetchBranch(ctx, mr.SourceBranch); err != nil {
+		return err
+	}
+
+	localBranches, err := git.GetLocalBranches(ctx)
+	if err != nil {
+		return err
+	}
+	for _, branch := range localBranches {
+		if branch == mr.SourceBranch {
+			if err := git.CheckoutBranch(ctx, branch); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Checked out to branch '%s'\n", branch)
+			return nil
+		}
+	}
+
+	if err := git.CheckoutTrackingBranch(ctx, mr.SourceBranch); err != nil {
+		return err
+	}
+	fmt.Printf("[+] Created and checked out to branch '%s' (tracking origin/%s)\n", mr.SourceBranch, mr.SourceBranch)
+	return nil
+}
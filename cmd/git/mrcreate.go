@@ -0,0 +1,309 @@
+package git
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/browser"
+	"cli-aio/internal/pkg/editor"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// mrTemplateDir is where GitLab looks for merge request description templates.
+const mrTemplateDir = ".gitlab/merge_request_templates"
+
+// changelogPlaceholder in a template is replaced by the changelog; without it
+// the changelog is appended.
+const changelogPlaceholder = "{changelog}"
+
+// noTemplate is the choice to start from the changelog alone.
+const noTemplate = "(no template)"
+
+// mrCreateCmd opens a merge request for the current branch.
+func mrCreateCmd() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:  "create",
+		Usage: "Open a merge request for the current branch, described from a repository template and the changelog",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "target",
+				Aliases: []string{"t"},
+				Usage:   "Target branch (default: the default branch of origin)",
+			},
+			&cli.StringFlag{
+				Name:  "title",
+				Usage: "Title of the merge request (default: the commit subject or the branch name)",
+			},
+			&cli.StringFlag{
+				Name:  "template",
+				Usage: "Description template from " + mrTemplateDir + ", 'none' for the changelog alone",
+			},
+			&cli.BoolFlag{
+				Name:    "draft",
+				Aliases: []string{"d"},
+				Usage:   "Mark the merge request as draft",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Don't ask for the title nor open the description in $EDITOR",
+			},
+			&cli.BoolFlag{
+				Name:    "web",
+				Aliases: []string{"w"},
+				Usage:   "Open the merge request in the browser once created",
+			},
+		},
+		Before: cmd.Require(cmd.RequireTool("git"), cmd.RequireGitRepo()),
+		Action: func(c *cli.Context) error {
+			head, err := git.GetCurrentBranch(c.Context)
+			if err != nil {
+				return err
+			}
+			if head.Detached {
+				return fmt.Errorf("HEAD is %s, checkout the branch to open a merge request for", head)
+			}
+			branch := head.Branch
+			root, err := git.GetTopLevel(c.Context)
+			if err != nil {
+				return err
+			}
+			target := c.String("target")
+			if target == "" {
+				target = git.DefaultBranchIn(c.Context, root)
+			}
+			if target == "" {
+				return fmt.Errorf("cannot tell the default branch of origin, pass --target")
+			}
+			if target == branch {
+				return fmt.Errorf("%s is the target branch, open the merge request from another branch", branch)
+			}
+
+			projectID, err := git.ExtractProjectID(c.Context)
+			if err != nil {
+				return err
+			}
+			client, err := gitlab.NewClient(c.Context)
+			if err != nil {
+				return err
+			}
+			open, err := client.OpenMergeRequests(projectID, url.Values{"source_branch": {branch}})
+			if err != nil {
+				return err
+			}
+			if len(open) > 0 {
+				fmt.Printf("[!] %s already has an open merge request: %s\n", branch, open[0].WebURL)
+				return nil
+			}
+
+			commits, err := git.CommitsBetween(c.Context, "origin/"+target, "HEAD")
+			if err != nil {
+				return err
+			}
+			if len(commits) == 0 {
+				return fmt.Errorf("%s has no commits that origin/%s doesn't have", branch, target)
+			}
+			template, err := mrTemplate(c, root)
+			if err != nil {
+				return err
+			}
+			if err := pushForMR(c.Context, branch); err != nil {
+				return err
+			}
+			title, err := mrTitle(c, branch, commits)
+			if err != nil {
+				return err
+			}
+			description := mrDescription(template, commits)
+			if !c.Bool("yes") && !ui.Plain() {
+				if description, err = editDescription(description); err != nil {
+					return err
+				}
+			}
+
+			mr, err := client.CreateMergeRequest(projectID, gitlab.NewMergeRequest{
+				SourceBranch: branch,
+				TargetBranch: target,
+				Title:        title,
+				Description:  description,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("[+] Created !%d %s\n", mr.IID, mr.WebURL)
+			if c.Bool("web") {
+				return browser.Open(mr.WebURL)
+			}
+			return nil
+		},
+	}, `Pushes the branch when needed, then picks a description template from `+mrTemplateDir+`
+(the one named Default first) and fills it with the changelog of the branch: the subjects of the
+commits origin/<target> doesn't have, in place of `+changelogPlaceholder+` or appended as a Changelog
+section. The description opens in $EDITOR before the merge request is created; an empty description
+cancels. With --yes (or --plain) the Default template, or none, is used as is.`,
+		cmd.Example{Command: "aio git mr create", Comment: "Pick a template and edit the description"},
+		cmd.Example{Command: "aio git mr create -d -t develop --template Bug", Comment: "Draft MR into develop from Bug.md"},
+		cmd.Example{Command: "aio git mr create -y --title \"feat: export\"", Comment: "No questions"},
+	)
+}
+
+// pushForMR pushes branch when origin doesn't have all of its commits.
+func pushForMR(ctx context.Context, branch string) error {
+	if upstream := git.Upstream(ctx); upstream != "" {
+		if n, err := git.CountCommits(ctx, git.PatchRange(upstream, "HEAD")); err == nil && n == 0 {
+			return nil
+		}
+	}
+	fmt.Fprintf(os.Stderr, "-> git push -u origin %s\n", branch)
+	return git.PushBranch(ctx, branch)
+}
+
+// mrTemplates returns the names of the description templates of the
+// repository at root, Default first.
+func mrTemplates(root string) []string {
+	files, _ := filepath.Glob(filepath.Join(root, mrTemplateDir, "*.md"))
+	var names []string
+	for _, file := range files {
+		names = append(names, strings.TrimSuffix(filepath.Base(file), ".md"))
+	}
+	slices.SortStableFunc(names, func(a, b string) int {
+		switch {
+		case strings.EqualFold(a, "default") == strings.EqualFold(b, "default"):
+			return strings.Compare(a, b)
+		case strings.EqualFold(a, "default"):
+			return -1
+		}
+		return 1
+	})
+	return names
+}
+
+// mrTemplate returns the content of the template named by --template, picked
+// from the repository's templates, or "" for none.
+func mrTemplate(c *cli.Context, root string) (string, error) {
+	names := mrTemplates(root)
+	name := c.String("template")
+	switch {
+	case name == "none":
+		return "", nil
+	case name != "":
+		i := slices.IndexFunc(names, func(n string) bool { return strings.EqualFold(n, name) })
+		if i < 0 {
+			return "", fmt.Errorf("no template %s in %s (%s)", name, mrTemplateDir, strings.Join(names, ", "))
+		}
+		name = names[i]
+	case len(names) == 0:
+		return "", nil
+	case c.Bool("yes") || ui.Plain():
+		if !strings.EqualFold(names[0], "default") {
+			return "", nil
+		}
+		name = names[0]
+	default:
+		_, choice, err := prompt.Select("Description template:", append(names, noTemplate), names[0])
+		if err != nil {
+			return "", fmt.Errorf("selection cancelled: %w", err)
+		}
+		if choice == noTemplate {
+			return "", nil
+		}
+		name = choice
+	}
+	data, err := os.ReadFile(filepath.Join(root, mrTemplateDir, name+".md"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+	return string(data), nil
+}
+
+// mrTitle returns --title, or asks for one defaulting to the subject of the
+// only commit or to the branch name.
+func mrTitle(c *cli.Context, branch string, commits []git.Commit) (string, error) {
+	title := c.String("title")
+	if title == "" {
+		title = branchTitle(branch)
+		if len(commits) == 1 {
+			title = commits[0].Subject
+		}
+		if !c.Bool("yes") && !ui.Plain() {
+			input, err := prompt.Input("Enter merge request title:", title, true)
+			if err != nil {
+				return "", fmt.Errorf("input cancelled: %w", err)
+			}
+			title = input
+		}
+	}
+	if c.Bool("draft") && !strings.HasPrefix(title, "Draft:") {
+		title = "Draft: " + title
+	}
+	return title, nil
+}
+
+// branchTitle turns a branch name into a title: feature/ABC-1-login-form -> "ABC-1 Login form".
+func branchTitle(branch string) string {
+	if _, rest, ok := strings.Cut(branch, "/"); ok {
+		branch = rest
+	}
+	key := ""
+	if k, rest, ok := strings.Cut(branch, "-"); ok && strings.ToUpper(k) == k && k != "" {
+		if number, rest2, _ := strings.Cut(rest, "-"); number != "" && strings.Trim(number, "0123456789") == "" {
+			key, branch = k+"-"+number+" ", rest2
+		}
+	}
+	words := strings.Join(strings.FieldsFunc(branch, func(r rune) bool { return r == '-' || r == '_' }), " ")
+	if words != "" {
+		words = strings.ToUpper(words[:1]) + words[1:]
+	}
+	return strings.TrimSpace(key + words)
+}
+
+// mrDescription fills template with the changelog of commits, listed newest
+// first by CommitsBetween and oldest first in the changelog.
+func mrDescription(template string, commits []git.Commit) string {
+	var changelog strings.Builder
+	for i := len(commits) - 1; i >= 0; i-- {
+		fmt.Fprintf(&changelog, "- %s (%s)\n", commits[i].Subject, commits[i].Short())
+	}
+	if strings.Contains(template, changelogPlaceholder) {
+		return strings.Replace(template, changelogPlaceholder, strings.TrimRight(changelog.String(), "\n"), 1)
+	}
+	if strings.TrimSpace(template) == "" {
+		return "## Changelog\n\n" + changelog.String()
+	}
+	return strings.TrimRight(template, "\n") + "\n\n## Changelog\n\n" + changelog.String()
+}
+
+// editDescription opens description in the editor and returns what was saved.
+func editDescription(description string) (string, error) {
+	file, err := os.CreateTemp("", "aio-mr-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(description)
+	file.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", file.Name(), err)
+	}
+	if err := editor.Open(file.Name(), 0); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file.Name(), err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return "", fmt.Errorf("empty description, merge request not created")
+	}
+	return string(data), nil
+}
@@ -0,0 +1,265 @@
+package git
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/codeowners"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/ui"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// ownersCmd reports who owns paths according to CODEOWNERS.
+func ownersCmd() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:      "owners",
+		Usage:     "Report who owns paths according to CODEOWNERS, or the files changed on the branch",
+		ArgsUsage: "[path...]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "changed",
+				Usage: "Group the files changed on the branch and in the working tree by owner (default without paths)",
+			},
+			&cli.StringFlag{
+				Name:  "base",
+				Usage: "With --changed, the branch the changes are compared to (default: origin's default branch)",
+			},
+			&cli.BoolFlag{
+				Name:    "all-projects",
+				Aliases: []string{"A"},
+				Usage:   "Look the paths up in every saved project instead of the current repository",
+			},
+			&cli.StringSliceFlag{
+				Name:    "tag",
+				Aliases: []string{"t"},
+				Usage:   "With --all-projects, only look in projects with this tag (repeatable)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.Bool("changed") || (c.Args().Len() == 0 && !c.Bool("all-projects")) {
+				return changedOwners(c.Context, c.String("base"))
+			}
+			if c.Args().Len() == 0 {
+				return fmt.Errorf("paths are required with --all-projects")
+			}
+			return pathOwners(c)
+		},
+	}, `CODEOWNERS is read from .github/, .gitlab/, the root or docs/, in that order. Patterns follow the
+gitignore rules and the last matching one wins; GitLab sections ([Backend] @backend-team) are each
+matched on their own, so a path can have owners in several sections.
+
+Without paths, the files changed since the branch left the base branch, plus the uncommitted ones, are
+grouped by owner: who to ask for a review before opening the merge request.`,
+		cmd.Example{Command: "aio git owners", Comment: "Owners of what the branch changes"},
+		cmd.Example{Command: "aio git owners internal/pkg/git docs/", Comment: "Owners of some paths"},
+		cmd.Example{Command: "aio git owners -A deploy/", Comment: "Who owns deploy/ in every saved project"},
+	)
+}
+
+// pathOwners prints the owners of the paths given as arguments.
+func pathOwners(c *cli.Context) error {
+	projects, err := scannedProjects(c)
+	if err != nil {
+		return err
+	}
+	multi := c.Bool("all-projects")
+
+	rows := [][]string{{"PATH", "OWNERS", "RULE"}}
+	if multi {
+		rows[0] = append([]string{"PROJECT"}, rows[0]...)
+	}
+	for _, p := range projects {
+		file, err := codeowners.Find(p.Path)
+		if err != nil {
+			return err
+		}
+		if file == nil {
+			if !multi {
+				return fmt.Errorf("no CODEOWNERS file in %s", p.Path)
+			}
+			continue
+		}
+		for _, arg := range c.Args().Slice() {
+			path := arg
+			if !multi {
+				if path, err = repoPath(p.Path, arg); err != nil {
+					return err
+				}
+			}
+			matches := file.Owners(path)
+			row := []string{path, describeOwners(matches), describeRules(file, matches)}
+			if multi {
+				row = append([]string{p.Name}, row...)
+			}
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 1 {
+		fmt.Println("[!] No project has a CODEOWNERS file")
+		return nil
+	}
+	for _, line := range ui.Table(rows, nil, 0) {
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// changedOwners groups the files changed since base, committed or not, by owner.
+func changedOwners(ctx context.Context, base string) error {
+	root, err := git.GetTopLevel(ctx)
+	if err != nil {
+		return err
+	}
+	file, err := codeowners.Find(root)
+	if err != nil {
+		return err
+	}
+	if file == nil {
+		return fmt.Errorf("no CODEOWNERS file in %s", root)
+	}
+	if base == "" {
+		branch := git.DefaultBranchIn(ctx, root)
+		if branch == "" {
+			return fmt.Errorf("origin's default branch is unknown, pass --base")
+		}
+		base = "origin/" + branch
+	}
+
+	stats, err := git.DiffStat(ctx, base, "HEAD")
+	if err != nil {
+		return err
+	}
+	uncommitted, err := git.ChangedFilesIn(ctx, root)
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	var files []string
+	for _, stat := range stats {
+		seen[stat.Path] = true
+		files = append(files, stat.Path)
+	}
+	for _, path := range uncommitted {
+		if !seen[path] {
+			files = append(files, path)
+		}
+	}
+	if len(files) == 0 {
+		fmt.Printf("[+] No changes since %s\n", base)
+		return nil
+	}
+	sort.Strings(files)
+
+	byOwner := map[string][]string{}
+	// required is false for owners only found in optional sections
+	required := map[string]bool{}
+	var unowned []string
+	for _, path := range files {
+		owned := false
+		for _, m := range file.Owners(path) {
+			for _, owner := range m.Owners {
+				byOwner[owner] = append(byOwner[owner], path)
+				required[owner] = required[owner] || !m.Section.Optional
+				owned = true
+			}
+		}
+		if !owned {
+			unowned = append(unowned, path)
+		}
+	}
+
+	owners := make([]string, 0, len(byOwner))
+	for owner := range byOwner {
+		owners = append(owners, owner)
+	}
+	// Owners of the most files first
+	sort.Slice(owners, func(i, j int) bool {
+		if len(byOwner[owners[i]]) != len(byOwner[owners[j]]) {
+			return len(byOwner[owners[i]]) > len(byOwner[owners[j]])
+		}
+		return owners[i] < owners[j]
+	})
+
+	fmt.Printf("%d file(s) changed since %s (%s):\n", len(files), base, file.Path)
+	for _, owner := range owners {
+		label := owner
+		if !required[owner] {
+			label += " (optional)"
+		}
+		printFileGroup(label, byOwner[owner])
+	}
+	if len(unowned) > 0 {
+		printFileGroup("[!] No owner", unowned)
+	}
+	return nil
+}
+
+// printFileGroup prints a heading with a file count and the files below it.
+func printFileGroup(heading string, files []string) {
+	fmt.Printf("\n%s  (%d file(s))\n", heading, len(files))
+	for _, f := range files {
+		fmt.Printf("  %s\n", f)
+	}
+}
+
+// repoPath returns arg, relative to the working directory, relative to root
+// with slashes.
+func repoPath(root string, arg string) (string, error) {
+	abs, err := filepath.Abs(arg)
+	if err != nil {
+		return "", err
+	}
+	// The top level is resolved, the working directory may not be
+	if resolved, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+		abs = filepath.Join(resolved, filepath.Base(abs))
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("%s is outside the repository", arg)
+	}
+	rel = filepath.ToSlash(rel)
+	if info, err := os.Stat(abs); err == nil && info.IsDir() && rel != "." {
+		// Directory rules (docs/) own the directory itself too
+		rel += "/"
+	}
+	return rel, nil
+}
+
+// describeOwners lists the owners of matches, prefixed with their section
+// when there are several, e.g. "@alice; [Docs] @docs-team".
+func describeOwners(matches []codeowners.Match) string {
+	var parts []string
+	for _, m := range matches {
+		owners := strings.Join(m.Owners, " ")
+		if owners == "" {
+			continue
+		}
+		if m.Section.Name != "" && len(matches) > 1 {
+			owners = "[" + m.Section.Name + "] " + owners
+		}
+		parts = append(parts, owners)
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// describeRules returns where the rules of matches are, e.g. ".github/CODEOWNERS:12".
+func describeRules(file *codeowners.File, matches []codeowners.Match) string {
+	var lines []string
+	for _, m := range matches {
+		lines = append(lines, strconv.Itoa(m.Rule.Line))
+	}
+	if len(lines) == 0 {
+		return "-"
+	}
+	return file.Path + ":" + strings.Join(lines, ",")
+}
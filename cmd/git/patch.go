@@ -0,0 +1,179 @@
+package git
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/git"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// patchCmd moves unpushed work between machines through a file.
+func patchCmd() *cli.Command {
+	subcommands := []*cli.Command{
+		patchExportCmd(),
+		patchImportCmd(),
+	}
+
+	return cmd.Describe(&cli.Command{
+		Name:        "patch",
+		Usage:       "Export unpushed commits (and uncommitted changes) to a file and import them elsewhere",
+		Subcommands: subcommands,
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				if !cmd.ValidateSubcommand(c, subcommands) {
+					return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+				}
+				return nil
+			}
+			return cli.ShowSubcommandHelp(c)
+		},
+	}, `Exports the commits not on any remote (or since --base) as a format-patch mailbox, or as a git
+bundle which keeps the exact commits. With --wip the uncommitted changes travel too, as a last WIP
+commit that import turns back into uncommitted changes. Import applies the file on the current branch
+and stops on a conflict, leaving the branch as it was.`,
+		cmd.Example{Command: "aio git patch export --wip", Comment: "Write <branch>-<date>.patch"},
+		cmd.Example{Command: "aio git patch export --format bundle --base origin/main", Comment: "Bundle the commits since origin/main"},
+		cmd.Example{Command: "aio git patch import feat-login-20260101.patch", Comment: "Apply it on the other machine"},
+	)
+}
+
+func patchExportCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "export",
+		Usage:     "Write the unpushed commits to a file (default: <branch>-<date>.patch or .bundle)",
+		ArgsUsage: "[file]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "patch (format-patch mailbox, applies on any base) or bundle (exact commits)",
+				Value:   "patch",
+			},
+			&cli.BoolFlag{
+				Name:    "wip",
+				Aliases: []string{"w"},
+				Usage:   "Include staged, unstaged and untracked changes as a last WIP commit",
+			},
+			&cli.StringFlag{
+				Name:    "base",
+				Aliases: []string{"b"},
+				Usage:   "Export the commits since this ref instead of those not on any remote",
+			},
+		},
+		Before: cmd.Require(cmd.RequireTool("git"), cmd.RequireGitRepo()),
+		Action: func(c *cli.Context) error {
+			format := c.String("format")
+			if format != "patch" && format != "bundle" {
+				return fmt.Errorf("unknown format '%s', use patch or bundle", format)
+			}
+			head, err := git.GetCurrentBranch(c.Context)
+			if err != nil {
+				return err
+			}
+
+			tip := "HEAD"
+			if c.Bool("wip") {
+				sha, err := git.WIPCommit(c.Context)
+				if err != nil {
+					return err
+				}
+				if sha == "" {
+					fmt.Println("[!] No uncommitted changes")
+				} else {
+					tip = sha
+				}
+			}
+			count, err := git.CountCommits(c.Context, git.PatchRange(c.String("base"), tip))
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				return fmt.Errorf("nothing to export: no commits that aren't on a remote (use --wip or --base)")
+			}
+
+			file := c.Args().First()
+			if file == "" {
+				name := head.Branch
+				if head.Detached {
+					name = head.SHA
+				}
+				file = fmt.Sprintf("%s-%s.%s", strings.ReplaceAll(name, "/", "-"), time.Now().Format("20060102"), format)
+			}
+			if format == "bundle" {
+				err = git.CreateBundle(c.Context, file, c.String("base"), tip)
+			} else {
+				err = git.FormatPatch(c.Context, file, git.PatchRange(c.String("base"), tip))
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("[+] Exported %d commit(s) to %s\n", count, file)
+			if tip != "HEAD" {
+				fmt.Println("    (the last one holds the uncommitted changes)")
+			}
+			return nil
+		},
+	}
+}
+
+func patchImportCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "import",
+		Usage:     "Apply a file from 'patch export' on the current branch, stopping on conflicts",
+		ArgsUsage: "<file>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "branch",
+				Aliases: []string{"b"},
+				Usage:   "Create this branch from HEAD and apply the file there",
+			},
+		},
+		Before: cmd.Require(cmd.RequireTool("git"), cmd.RequireGitRepo(), cmd.RequireCleanTree()),
+		Action: func(c *cli.Context) error {
+			file := c.Args().First()
+			if file == "" {
+				return fmt.Errorf("no file given, e.g. aio git patch import feat-login-20260101.patch")
+			}
+			if _, err := os.Stat(file); err != nil {
+				return fmt.Errorf("cannot read %s: %w", file, err)
+			}
+
+			if branch := c.String("branch"); branch != "" {
+				if err := git.CreateBranch(c.Context, branch, ""); err != nil {
+					return err
+				}
+				fmt.Printf("-> Created branch %s\n", branch)
+			}
+
+			var err error
+			if git.IsBundle(file) {
+				err = git.ApplyBundle(c.Context, file)
+			} else {
+				err = git.ApplyMailbox(c.Context, file)
+			}
+			var conflict *git.PatchConflict
+			if errors.As(err, &conflict) {
+				return fmt.Errorf("%w\nnothing was applied, rebase the branch closer to the exporting one and retry", conflict)
+			}
+			if err != nil {
+				return err
+			}
+
+			wip, err := git.UndoWIPCommit(c.Context)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("[+] Applied %s\n", file)
+			if wip {
+				fmt.Println("[+] Restored the uncommitted changes")
+			}
+			return nil
+		},
+	}
+}
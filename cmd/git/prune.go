@@ -0,0 +1,108 @@
+package git
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/prompt"
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+// pruneRemote prunes stale origin/* branches and returns the local branches whose upstream is gone.
+func pruneRemote(ctx context.Context, gc git.GitClient, dryRun bool) ([]string, error) {
+	fmt.Println("Pruning stale remote branches...")
+	pruned, err := gc.PruneRemote(ctx, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	verb := "Pruned"
+	if dryRun {
+		verb = "Would prune"
+	}
+	for _, ref := range pruned {
+		fmt.Printf("[+] %s %s\n", verb, ref)
+	}
+	if len(pruned) == 0 {
+		fmt.Println("[+] No stale remote branches")
+	}
+	return gc.GoneBranches(ctx)
+}
+
+func pruneRemoteCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "prune-remote",
+		Usage: "Prune stale origin/* branches and offer to delete local branches whose remote is gone",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Only show what would be pruned",
+			},
+		},
+		Before: cmd.Require(cmd.RequireGitRepo()),
+		Action: func(c *cli.Context) error {
+			dryRun := c.Bool("dry-run")
+			gc := git.NewClient()
+			gone, err := pruneRemote(c.Context, gc, dryRun)
+			if err != nil {
+				return err
+			}
+			if len(gone) == 0 {
+				return nil
+			}
+
+			fmt.Printf("\nLocal branches whose remote is gone:\n")
+			for _, branch := range gone {
+				fmt.Printf("  %s\n", branch)
+			}
+			if dryRun {
+				return nil
+			}
+
+			head, err := gc.Head(c.Context)
+			if err != nil {
+				return err
+			}
+			// Squash-merged branches don't look merged to git, so label instead of refusing them
+			var candidates, labels []string
+			for _, branch := range gone {
+				if branch == head.Branch {
+					continue
+				}
+				label := branch
+				if !gc.IsAncestor(c.Context, branch, "HEAD") {
+					label += " (not merged into HEAD)"
+				}
+				candidates = append(candidates, branch)
+				labels = append(labels, label)
+			}
+			if len(candidates) == 0 {
+				return nil
+			}
+
+			selected, err := prompt.MultiSelect("Delete local branches:", labels, nil)
+			if err != nil {
+				return fmt.Errorf("selection cancelled: %w", err)
+			}
+			for _, label := range selected {
+				branch := candidates[indexOf(labels, label)]
+				if err := gc.DeleteBranch(c.Context, branch, true); err != nil {
+					fmt.Printf("[-] %v\n", err)
+					continue
+				}
+				fmt.Printf("[+] Deleted branch '%s'\n", branch)
+			}
+			return nil
+		},
+	}
+}
+
+func indexOf(items []string, item string) int {
+	for i, v := range items {
+		if v == item {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,189 @@
+package git
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/audit"
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/convention"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/prompt"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/urfave/cli/v2"
+)
+
+// pushCmd pushes a branch after checking it is safe to.
+func pushCmd() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:      "push",
+		Usage:     "Push a branch after safety checks: no force-push to protected branches, no WIP commits, commit lint",
+		ArgsUsage: "[branch]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "Overwrite the remote branch, with --force-with-lease unless --no-lease",
+			},
+			&cli.BoolFlag{
+				Name:  "no-lease",
+				Usage: "With --force, use a plain --force even if the remote branch moved since the last fetch",
+			},
+			&cli.BoolFlag{
+				Name:  "no-lint",
+				Usage: "Don't check the commit messages",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Push despite warnings without asking",
+			},
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "Run the checks and print the git command instead of pushing",
+			},
+		},
+		Before: cmd.Require(cmd.RequireTool("git"), cmd.RequireGitRepo()),
+		Action: func(c *cli.Context) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			rules, err := convention.NewRules(cfg.Conventions)
+			if err != nil {
+				return err
+			}
+			branch := c.Args().First()
+			if branch == "" {
+				head, err := git.GetCurrentBranch(c.Context)
+				if err != nil {
+					return err
+				}
+				if head.Detached {
+					return fmt.Errorf("HEAD is %s, checkout a branch or pass its name", head)
+				}
+				branch = head.Branch
+			}
+
+			force := c.Bool("force")
+			if force && rules.Protected(branch) {
+				return fmt.Errorf("refusing to force-push to protected branch %s (conventions.protected_branches)", branch)
+			}
+
+			warnings, err := pushWarnings(c.Context, rules, branch, c.Bool("no-lint"))
+			if err != nil {
+				return err
+			}
+			if len(warnings) > 0 {
+				for _, w := range warnings {
+					fmt.Fprintf(os.Stderr, "[!] %s\n", w)
+				}
+				if !c.Bool("yes") && !c.Bool("dry-run") {
+					ok, err := prompt.Confirm("Push anyway?", false)
+					if err != nil {
+						return fmt.Errorf("not pushing, use --yes to push anyway: %w", err)
+					}
+					if !ok {
+						fmt.Println("[-] Push cancelled")
+						return nil
+					}
+				}
+			}
+
+			args := []string{"push"}
+			switch {
+			case force && c.Bool("no-lease"):
+				args = append(args, "--force")
+			case force:
+				args = append(args, "--force-with-lease")
+				fmt.Fprintln(os.Stderr, "-> Using --force-with-lease: the push fails if someone else pushed since your last fetch (--no-lease to overwrite anyway)")
+			}
+			if !git.RemoteBranchExists(c.Context, branch) {
+				args = append(args, "-u")
+			}
+			args = append(args, "origin", branch)
+
+			line := "git " + shellquote.Join(args...)
+			dir, _ := os.Getwd()
+			entry := audit.Entry{Time: time.Now(), Dir: dir, Command: append([]string{"git"}, args...), DryRun: c.Bool("dry-run")}
+			if len(warnings) > 0 && !entry.DryRun {
+				entry.Note = fmt.Sprintf("pushed despite %d warning(s)", len(warnings))
+			}
+			if c.Bool("dry-run") {
+				fmt.Println(line)
+				recordAudit(entry)
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "-> %s\n", line)
+			code, err := git.Passthrough(c.Context, args)
+			if err != nil {
+				return err
+			}
+			entry.Exit = code
+			entry.Duration = time.Since(entry.Time).Round(time.Millisecond).String()
+			recordAudit(entry)
+			if code != 0 {
+				return cli.Exit("", code)
+			}
+			return nil
+		},
+	}, `Checks before pushing the branch (default: the current one) to origin:
+
+  - a force-push to a protected branch (main, master, develop, release/* unless
+    conventions.protected_branches says otherwise) is refused
+  - --force uses --force-with-lease, which fails instead of overwriting commits
+    someone else pushed since your last fetch
+  - WIP and fixup!/squash! commits about to be pushed are reported
+  - the commit messages are checked like 'aio git lint-commits'
+
+Warnings ask for confirmation (--yes to skip). A branch without a remote branch yet is pushed with
+-u. Every push is recorded in the history shown by 'aio git audit'.`,
+		cmd.Example{Command: "aio git push", Comment: "Push the current branch"},
+		cmd.Example{Command: "aio git push -f", Comment: "After a rebase, with --force-with-lease"},
+		cmd.Example{Command: "aio git push -n", Comment: "Only run the checks"},
+	)
+}
+
+// pushWarnings checks the commits of branch origin doesn't have yet.
+func pushWarnings(ctx context.Context, rules *convention.Rules, branch string, noLint bool) ([]string, error) {
+	revs := git.PatchRange("", branch)
+	if git.RemoteBranchExists(ctx, branch) {
+		revs = git.PatchRange("origin/"+branch, branch)
+	}
+	commits, err := git.CommitMessages(ctx, revs)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	var wip, badMessages []string
+	for _, commit := range commits {
+		if convention.SkipCommit(commit.Subject, commit.Merge) {
+			continue
+		}
+		if convention.WorkInProgress(commit.Subject) {
+			wip = append(wip, commit.Short()+" "+commit.Subject)
+			continue
+		}
+		if noLint {
+			continue
+		}
+		if problems := rules.CheckCommit(commit.Subject, commit.Body); len(problems) > 0 {
+			badMessages = append(badMessages, fmt.Sprintf("%s %s (%s)", commit.Short(), commit.Subject, strings.Join(problems, "; ")))
+		}
+	}
+	if len(wip) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d work-in-progress commit(s), squash them first (git rebase -i --autosquash):\n    %s",
+			len(wip), strings.Join(wip, "\n    ")))
+	}
+	if len(badMessages) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d commit message(s) don't follow the convention (aio git lint-commits):\n    %s",
+			len(badMessages), strings.Join(badMessages, "\n    ")))
+	}
+	return warnings, nil
+}
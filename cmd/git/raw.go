@@ -0,0 +1,140 @@
+package git
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/audit"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/ui"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/urfave/cli/v2"
+)
+
+// rawCmd runs any git command through aio, logged and recorded in the audit history.
+func rawCmd() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:      "raw",
+		Usage:     "Run any git command, logged and recorded in the audit history",
+		ArgsUsage: "-- <git args...>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "Print the git command instead of running it",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			args := c.Args().Slice()
+			if len(args) == 0 {
+				return fmt.Errorf("missing git arguments, e.g. 'aio git raw -- log --oneline -5'")
+			}
+			dir, _ := os.Getwd()
+			line := "git " + shellquote.Join(args...)
+			entry := audit.Entry{Time: time.Now(), Dir: dir, Command: append([]string{"git"}, args...), DryRun: c.Bool("dry-run")}
+			if c.Bool("dry-run") {
+				fmt.Println(line)
+				recordAudit(entry)
+				return nil
+			}
+
+			fmt.Fprintf(os.Stderr, "-> %s\n", line)
+			if ui.Plain() {
+				// Scripts get bare output: no pager waiting for a key, no escape codes
+				args = append([]string{"--no-pager", "-c", "color.ui=never"}, args...)
+			}
+			code, err := git.Passthrough(c.Context, args)
+			if err != nil {
+				return err
+			}
+			entry.Exit = code
+			entry.Duration = time.Since(entry.Time).Round(time.Millisecond).String()
+			recordAudit(entry)
+			if code != 0 {
+				// git already explained the failure, only pass its status on
+				return cli.Exit("", code)
+			}
+			return nil
+		},
+	}, `Runs git with the given arguments in the current directory (or -C <dir>), printing the command
+to stderr first and appending it, with its exit status and duration, to the audit history shown by
+'aio git audit'. With --plain (or when piped) the pager and colors are turned off. Put git's own
+flags after --.`,
+		cmd.Example{Command: "aio git raw -- log --oneline -5", Comment: "Any git command"},
+		cmd.Example{Command: "aio git raw -n -- push --force-with-lease", Comment: "Print instead of running"},
+		cmd.Example{Command: "aio -C ~/src/api git raw -- status -s", Comment: "In another repository"},
+	)
+}
+
+// auditCmd shows the commands recorded by 'git raw'.
+func auditCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "audit",
+		Usage: "Show the history of commands run through 'aio git raw' and 'aio git push', the release freeze overrides and the pre-tag checks",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:    "limit",
+				Aliases: []string{"l"},
+				Usage:   "Show at most this many entries, newest last",
+				Value:   20,
+			},
+			&cli.BoolFlag{
+				Name:  "here",
+				Usage: "Only commands run in the current directory",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			entries, err := audit.Read()
+			if err != nil {
+				return err
+			}
+			if c.Bool("here") {
+				dir, _ := os.Getwd()
+				var here []audit.Entry
+				for _, e := range entries {
+					if e.Dir == dir {
+						here = append(here, e)
+					}
+				}
+				entries = here
+			}
+			if len(entries) == 0 {
+				fmt.Println("[!] No commands recorded. Use 'aio git raw -- <args>' to run git through aio.")
+				return nil
+			}
+			if limit := c.Int("limit"); limit > 0 && len(entries) > limit {
+				entries = entries[len(entries)-limit:]
+			}
+
+			for _, e := range entries {
+				status := fmt.Sprintf("%-8s", fmt.Sprintf("exit %d", e.Exit))
+				switch {
+				case e.DryRun:
+					status = "dry-run "
+				case e.Note != "" && e.Duration == "":
+					// Recorded before running, e.g. a freeze override
+					status = "note    "
+				case e.Exit != 0:
+					status = ui.Colorize(ui.Red, status)
+				}
+				fmt.Printf("%s  %s  %s\n", e.Time.Format("2006-01-02 15:04:05"), status, shellquote.Join(e.Command...))
+				if !c.Bool("here") {
+					fmt.Printf("%21s in %s\n", "", e.Dir)
+				}
+				if e.Note != "" {
+					fmt.Printf("%21s %s\n", "", e.Note)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// recordAudit appends e to the audit history, warning when it can't.
+func recordAudit(e audit.Entry) {
+	if err := audit.Record(e); err != nil {
+		fmt.Fprintf(os.Stderr, "[!] Warning: %v\n", err)
+	}
+}
@@ -0,0 +1,495 @@
+package git
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Outcome of reverse merging into one target.
+const (
+	rmergeMerged    = "merged"
+	rmergeConflicts = "conflicts"
+	rmergeFailed    = "failed"
+	rmergeSkipped   = "not run"
+	rmergeCancelled = "interrupted"
+	rmergeDeclined  = "declined"
+)
+
+// Limits of the incoming changes summary shown before merging.
+const (
+	incomingMaxCommits = 10
+	incomingMaxFiles   = 20
+)
+
+type rmergeResult struct {
+	target string
+	status string
+}
+
+func reversedMergeBranch() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:      "rmerge",
+		Usage:     "Reverse merge current branch into target branches (checkout to each target, then merge current into it)",
+		ArgsUsage: "[target...]",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "target",
+				Aliases: []string{"t"},
+				Usage:   "Target branch (repeatable, same as positional arguments)",
+			},
+			&cli.StringFlag{
+				Name:  "on-conflict",
+				Usage: "What to do when a target fails with multiple targets: stop or skip (default: ask)",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Merge without confirming the incoming changes summary",
+			},
+		},
+		Before: cmd.Require(cmd.RequireGitRepo(), cmd.RequireCleanTree()),
+		Action: func(c *cli.Context) error {
+			onConflict := c.String("on-conflict")
+			if onConflict != "" && onConflict != "stop" && onConflict != "skip" {
+				return fmt.Errorf("invalid --on-conflict value: %s (expected stop or skip)", onConflict)
+			}
+
+			gc := git.NewClient()
+			// Get current branch (A)
+			head, err := gc.Head(c.Context)
+			if err != nil {
+				return err
+			}
+			if head.Detached {
+				return fmt.Errorf("HEAD is %s, checkout the branch to reverse merge first", head)
+			}
+			currentBranch := head.Branch
+			fmt.Printf("Current branch: %s\n", currentBranch)
+
+			// Get target branches (B...) from args/flags or prompt
+			targets, err := rmergeTargets(c, gc, currentBranch)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Target branches: %s\n", strings.Join(targets, ", "))
+
+			confirm := !c.Bool("yes")
+			if len(targets) == 1 {
+				err := mergeInto(c.Context, gc, currentBranch, targets[0], confirm)
+				if isDeclined(err) {
+					return abortToBranch(c.Context, gc, currentBranch, err.Error())
+				}
+				if err != nil && c.Context.Err() != nil {
+					if err := restoreBranch(c.Context, gc, currentBranch); err != nil {
+						return err
+					}
+					return fmt.Errorf("reverse merge interrupted, back on '%s'", currentBranch)
+				}
+				return err
+			}
+
+			results := make([]rmergeResult, len(targets))
+			for i, target := range targets {
+				results[i] = rmergeResult{target: target, status: rmergeSkipped}
+			}
+			for i, target := range targets {
+				if cmd.Stopping(c.Context) {
+					fmt.Printf("\n[!] Interrupted before '%s'\n", target)
+					break
+				}
+				fmt.Printf("\n=== [%d/%d] %s ===\n", i+1, len(targets), target)
+				err := mergeInto(c.Context, gc, currentBranch, target, confirm)
+				switch {
+				case err == nil:
+					results[i].status = rmergeMerged
+					continue
+				case isDeclined(err):
+					results[i].status = rmergeDeclined
+					fmt.Printf("[!] Not merging into '%s': %v\n", target, err)
+					continue
+				case c.Context.Err() != nil:
+					results[i].status = rmergeCancelled
+				case isConflictError(err):
+					results[i].status = rmergeConflicts
+				default:
+					results[i].status = rmergeFailed
+				}
+				fmt.Printf("[-] %v\n", err)
+				if results[i].status == rmergeCancelled {
+					break
+				}
+				if i < len(targets)-1 && !continueAfterFailure(onConflict, target) {
+					break
+				}
+			}
+
+			// Leave the repository where the user started
+			if err := restoreBranch(c.Context, gc, currentBranch); err != nil {
+				fmt.Printf("[!] Warning: %v\n", err)
+			}
+
+			failed := 0
+			fmt.Printf("\nSummary (merged '%s' into):\n", currentBranch)
+			for _, r := range results {
+				marker := "[+]"
+				if r.status != rmergeMerged {
+					marker = "[-]"
+					failed++
+				}
+				fmt.Printf("  %s %-30s %s\n", marker, r.target, r.status)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d target(s) not merged", failed, len(results))
+			}
+			return nil
+		},
+	}, `Merges the current branch into each target: fetch, checkout and pull the target, check for
+conflicts without touching the tree, then merge. Without targets you pick them from the local
+branches. When pulling a target fails (diverged from origin) you can rebase it, reset it to origin
+or abort. With several targets a failure asks whether to skip or stop (--on-conflict answers it
+up front), you end up back on the original branch and a summary lists what was merged. Ctrl+C
+stops before the next target; pressed again it aborts the running merge and returns to the
+original branch.`,
+		cmd.Example{Command: "aio git rmerge develop", Comment: "Merge the current branch into develop"},
+		cmd.Example{Command: "aio git rmerge --on-conflict skip qc staging", Comment: "Several targets, skip the ones that fail"},
+		cmd.Example{Command: "aio git rmerge", Comment: "Pick the targets interactively"},
+	)
+}
+
+// rmergeTargets returns the target branches from args and --target, or lets the user pick them.
+// Targets that don't exist are replaced by a selection from the local branches.
+func rmergeTargets(c *cli.Context, gc git.GitClient, currentBranch string) ([]string, error) {
+	targets := append(c.Args().Slice(), c.StringSlice("target")...)
+
+	localBranches, err := gc.LocalBranches(c.Context)
+	if err != nil {
+		return nil, err
+	}
+	// Filter out current branch from the list
+	availableBranches := []string{}
+	for _, branch := range localBranches {
+		if branch != currentBranch {
+			availableBranches = append(availableBranches, branch)
+		}
+	}
+
+	if len(targets) == 0 {
+		if len(availableBranches) == 0 {
+			return nil, fmt.Errorf("no other local branches available to merge into")
+		}
+		// The targets picked last time in this repository are preselected
+		history := "target-branch"
+		if top, err := gc.TopLevel(c.Context); err == nil {
+			history += ":" + top
+		}
+		var defaults []string
+		if recent := prompt.History(history); len(recent) > 0 {
+			for _, branch := range strings.Fields(recent[0]) {
+				if slices.Contains(availableBranches, branch) {
+					defaults = append(defaults, branch)
+				}
+			}
+		}
+		selected, err := prompt.MultiSelect("Select target branches:", availableBranches, defaults, prompt.WithMin(1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to select branch: %v", err)
+		}
+		// Branch names can't contain spaces
+		prompt.Remember(history, strings.Join(selected, " "))
+		return selected, nil
+	}
+
+	seen := map[string]bool{}
+	var result []string
+	for _, target := range targets {
+		if target == currentBranch {
+			return nil, fmt.Errorf("already on target branch '%s'", target)
+		}
+		// Check if target branch exists
+		branchExists, err := gc.BranchExists(c.Context, target)
+		if err != nil {
+			return nil, err
+		}
+		if !branchExists {
+			if len(availableBranches) == 0 {
+				return nil, fmt.Errorf("branch '%s' does not exist and no other local branches available", target)
+			}
+			fmt.Printf("[!] Branch '%s' does not exist.\n", target)
+			_, selected, err := prompt.Select("Select target branch from available branches:", availableBranches, "")
+			if err != nil {
+				return nil, fmt.Errorf("failed to select branch: %w", err)
+			}
+			target = selected
+		}
+		if !seen[target] {
+			seen[target] = true
+			result = append(result, target)
+		}
+	}
+	return result, nil
+}
+
+// errMergeConflicts marks a target that can't be merged without manual conflict resolution.
+type errMergeConflicts struct {
+	source string
+	target string
+}
+
+func (e *errMergeConflicts) Error() string {
+	return fmt.Sprintf("merge conflicts detected! Cannot merge '%s' into '%s', please resolve conflicts manually", e.source, e.target)
+}
+
+func isConflictError(err error) bool {
+	_, ok := err.(*errMergeConflicts)
+	return ok
+}
+
+// errMergeDeclined marks a target not merged into because the user did not
+// confirm the incoming changes.
+type errMergeDeclined struct {
+	reason string
+}
+
+func (e *errMergeDeclined) Error() string {
+	return e.reason
+}
+
+func isDeclined(err error) bool {
+	_, ok := err.(*errMergeDeclined)
+	return ok
+}
+
+// continueAfterFailure decides whether a batch rmerge goes on after target failed.
+func continueAfterFailure(onConflict string, target string) bool {
+	switch onConflict {
+	case "skip":
+		return true
+	case "stop":
+		return false
+	}
+	const (
+		skip = "Skip and continue with the next target"
+		stop = "Stop here"
+	)
+	_, action, err := prompt.SelectWithFuzzy(fmt.Sprintf("'%s' was not merged:", target), []string{skip, stop}, skip, false)
+	return err == nil && action == skip
+}
+
+// mergeInto runs the checkout/pull/conflict-check/merge sequence of sourceBranch into targetBranch.
+// With confirm, the incoming changes are shown and the merge waits for the user's approval.
+func mergeInto(ctx context.Context, gc git.GitClient, sourceBranch string, targetBranch string, confirm bool) error {
+	// Fetch the target branch to make sure we have latest info
+	fmt.Printf("Fetching branch '%s'...\n", targetBranch)
+	if err := gc.FetchBranch(ctx, targetBranch); err != nil {
+		switch kind := git.KindOf(err); kind {
+		case git.ErrNotFound:
+			fmt.Printf("[!] '%s' is not on origin, merging into the local branch\n", targetBranch)
+		case git.ErrAuth, git.ErrCancelled:
+			// The pull would fail the same way
+			return fmt.Errorf("failed to fetch '%s': %w", targetBranch, git.WithHint(err))
+		default:
+			fmt.Printf("[!] Warning: Failed to fetch branch: %v\n", err)
+			// Continue anyway, might be a local branch
+		}
+	}
+
+	// Checkout to target branch
+	fmt.Printf("Checking out to branch '%s'...\n", targetBranch)
+	if err := gc.Checkout(ctx, targetBranch); err != nil {
+		return git.WithHint(err)
+	}
+
+	// Pull latest changes
+	fmt.Printf("Pulling latest changes for '%s'...\n", targetBranch)
+	if err := gc.Pull(ctx); err != nil {
+		if err := resolvePullFailure(ctx, gc, targetBranch, sourceBranch, err); err != nil {
+			return err
+		}
+	}
+
+	// Check for merge conflicts before merging
+	fmt.Printf("Checking for potential merge conflicts...\n")
+	hasConflicts, err := gc.CheckMergeConflicts(ctx, sourceBranch)
+	if err != nil {
+		return fmt.Errorf("failed to check merge conflicts: %w", err)
+	}
+
+	if hasConflicts {
+		return &errMergeConflicts{source: sourceBranch, target: targetBranch}
+	}
+
+	// Show the blast radius before altering the target
+	incoming, err := showIncoming(ctx, gc, sourceBranch, targetBranch)
+	if err != nil {
+		return err
+	}
+	if incoming == 0 {
+		fmt.Printf("[+] '%s' already contains '%s', nothing to merge\n", targetBranch, sourceBranch)
+		return nil
+	}
+	if confirm {
+		ok, err := prompt.Confirm(fmt.Sprintf("Merge %d commit(s) into '%s'?", incoming, targetBranch), true)
+		if err != nil {
+			return &errMergeDeclined{reason: fmt.Sprintf("cannot confirm the merge (%v), pass --yes to merge without asking", err)}
+		}
+		if !ok {
+			return &errMergeDeclined{reason: "merge declined"}
+		}
+	}
+
+	// Merge source branch into target branch
+	fmt.Printf("Merging '%s' into '%s'...\n", sourceBranch, targetBranch)
+	if err := gc.Merge(ctx, sourceBranch, false); err != nil {
+		return fmt.Errorf("failed to merge branch: %w", err)
+	}
+
+	// Show success result
+	fmt.Printf("[+] Successfully merged '%s' into '%s'\n", sourceBranch, targetBranch)
+	fmt.Printf("Current branch: %s\n", targetBranch)
+	return nil
+}
+
+// showIncoming prints the commits, authors and files merging sourceBranch
+// would bring into targetBranch, and returns the number of commits.
+func showIncoming(ctx context.Context, gc git.GitClient, sourceBranch string, targetBranch string) (int, error) {
+	commits, err := gc.CommitsBetween(ctx, targetBranch, sourceBranch)
+	if err != nil {
+		return 0, err
+	}
+	if len(commits) == 0 {
+		return 0, nil
+	}
+	stats, err := gc.DiffStat(ctx, targetBranch, sourceBranch)
+	if err != nil {
+		return 0, err
+	}
+
+	var authors []string
+	seen := map[string]bool{}
+	for _, commit := range commits {
+		if !seen[commit.Author] {
+			seen[commit.Author] = true
+			authors = append(authors, commit.Author)
+		}
+	}
+	fmt.Printf("\nIncoming from '%s': %d commit(s) by %s\n", sourceBranch, len(commits), strings.Join(authors, ", "))
+	for i, commit := range commits {
+		if i == incomingMaxCommits {
+			fmt.Printf("  ... and %d more\n", len(commits)-i)
+			break
+		}
+		fmt.Printf("  %s %s\n", commit.Short(), commit.Subject)
+	}
+
+	added, deleted, width := 0, 0, 0
+	for _, stat := range stats {
+		added += stat.Added
+		deleted += stat.Deleted
+		width = max(width, len(stat.Path))
+	}
+	width = min(width, 60)
+	fmt.Printf("%d file(s) changed, %s, %s\n", len(stats), ui.Colorize(ui.Green, fmt.Sprintf("+%d", added)), ui.Colorize(ui.Red, fmt.Sprintf("-%d", deleted)))
+	for i, stat := range stats {
+		if i == incomingMaxFiles {
+			fmt.Printf("  ... and %d more file(s)\n", len(stats)-i)
+			break
+		}
+		change := "binary"
+		if !stat.Binary {
+			change = ui.Colorize(ui.Green, fmt.Sprintf("+%d", stat.Added)) + " " + ui.Colorize(ui.Red, fmt.Sprintf("-%d", stat.Deleted))
+		}
+		fmt.Printf("  %-*s | %s\n", width, stat.Path, change)
+	}
+	fmt.Println()
+	return len(commits), nil
+}
+
+// Choices offered when pulling the rmerge target branch fails.
+const (
+	pullActionRebase = "Rebase local target onto origin"
+	pullActionReset  = "Reset target to origin (discard local target commits)"
+	pullActionAbort  = "Abort and return to original branch"
+)
+
+// resolvePullFailure is called while on targetBranch after 'git pull' failed
+// (usually because the local target diverged from origin). It lets the user
+// repair the target branch or abort back to originalBranch, instead of leaving
+// the repository on the target branch mid-flow. A nil error means the flow can continue.
+func resolvePullFailure(ctx context.Context, gc git.GitClient, targetBranch string, originalBranch string, pullErr error) error {
+	fmt.Printf("[-] Failed to pull '%s': %v\n", targetBranch, pullErr)
+	// Rebasing or resetting only helps a branch that diverged from origin
+	switch kind := git.KindOf(pullErr); kind {
+	case git.ErrAuth, git.ErrNetwork, git.ErrLocalChanges:
+		return abortToBranch(ctx, gc, originalBranch, "pull failed, "+kind.Hint())
+	case git.ErrCancelled:
+		return abortToBranch(ctx, gc, originalBranch, "pull interrupted")
+	}
+
+	upstream := "origin/" + targetBranch
+	options := []string{pullActionAbort}
+	if gc.RemoteBranchExists(ctx, targetBranch) {
+		options = []string{pullActionRebase, pullActionReset, pullActionAbort}
+	}
+
+	_, action, err := prompt.SelectWithFuzzy("How do you want to continue?", options, pullActionAbort, false)
+	if err != nil {
+		action = pullActionAbort
+	}
+
+	switch action {
+	case pullActionRebase:
+		fmt.Printf("Rebasing '%s' onto '%s'...\n", targetBranch, upstream)
+		if err := gc.Rebase(ctx, upstream); err != nil {
+			fmt.Printf("[-] %v\n", err)
+			return abortToBranch(ctx, gc, originalBranch, "rebase failed")
+		}
+		fmt.Printf("[+] Rebased '%s' onto '%s'\n", targetBranch, upstream)
+		return nil
+	case pullActionReset:
+		confirmed, err := prompt.Confirm(fmt.Sprintf("Local commits on '%s' not on %s will be lost. Continue?", targetBranch, upstream), false)
+		if err != nil || !confirmed {
+			return abortToBranch(ctx, gc, originalBranch, "reset cancelled")
+		}
+		if err := gc.ResetHard(ctx, upstream); err != nil {
+			fmt.Printf("[-] %v\n", err)
+			return abortToBranch(ctx, gc, originalBranch, "reset failed")
+		}
+		fmt.Printf("[+] Reset '%s' to '%s'\n", targetBranch, upstream)
+		return nil
+	default:
+		return abortToBranch(ctx, gc, originalBranch, "pull failed")
+	}
+}
+
+// abortToBranch checks out originalBranch and returns an error describing why rmerge stopped.
+func abortToBranch(ctx context.Context, gc git.GitClient, originalBranch string, reason string) error {
+	fmt.Printf("Returning to branch '%s'...\n", originalBranch)
+	if err := gc.Checkout(ctx, originalBranch); err != nil {
+		return fmt.Errorf("%s and failed to return to '%s': %w", reason, originalBranch, err)
+	}
+	return fmt.Errorf("reverse merge aborted (%s), back on '%s'", reason, originalBranch)
+}
+
+// restoreBranch aborts a merge left in progress and checks out originalBranch.
+// It runs detached from the context so it still works after Ctrl+C.
+func restoreBranch(ctx context.Context, gc git.GitClient, originalBranch string) error {
+	ctx = context.WithoutCancel(ctx)
+	if gc.MergeInProgress(ctx) {
+		fmt.Println("Aborting the partial merge...")
+		if err := gc.AbortMerge(ctx); err != nil {
+			return err
+		}
+	}
+	if err := gc.Checkout(ctx, originalBranch); err != nil {
+		return fmt.Errorf("failed to return to '%s': %w", originalBranch, err)
+	}
+	return nil
+}
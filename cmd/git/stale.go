@@ -0,0 +1,269 @@
+package git
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/pkg/offline"
+	"cli-aio/internal/pkg/project"
+	"cli-aio/internal/ui"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// staleKeep are branch patterns never reported as stale, next to the default branch.
+var staleKeep = []string{"main", "master", "develop", "release/*"}
+
+// staleBranch is a row of the stale branch report.
+type staleBranch struct {
+	Project    string    `json:"project"`
+	Branch     string    `json:"branch"`
+	LastCommit time.Time `json:"last_commit"`
+	Days       int       `json:"days"`
+	Author     string    `json:"author"`
+	// MRChecked is false when open MRs couldn't be looked up, so the branch may have one
+	MRChecked bool `json:"mr_checked"`
+}
+
+func staleCmd() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:  "stale",
+		Usage: "Report branches with no commits in N days and no open MR, in this repository or all saved projects",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:    "days",
+				Aliases: []string{"d"},
+				Usage:   "Branches whose last commit is older than this many days are stale",
+				Value:   90,
+			},
+			&cli.BoolFlag{
+				Name:    "all-projects",
+				Aliases: []string{"A"},
+				Usage:   "Scan every saved project instead of the current repository",
+			},
+			&cli.StringSliceFlag{
+				Name:    "tag",
+				Aliases: []string{"t"},
+				Usage:   "With --all-projects, only scan projects with this tag (repeatable)",
+			},
+			&cli.BoolFlag{
+				Name:  "local",
+				Usage: "Report local branches instead of origin/* branches",
+			},
+			&cli.BoolFlag{
+				Name:  "fetch",
+				Usage: "Fetch each project first (otherwise origin/* is as of the last fetch)",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the report as JSON",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			projects, err := scannedProjects(c)
+			if err != nil {
+				return err
+			}
+
+			client := staleClient(c.Context)
+			cutoff := time.Now().AddDate(0, 0, -c.Int("days"))
+			var report []staleBranch
+			for i, p := range projects {
+				if cmd.Stopping(c.Context) {
+					fmt.Fprintf(os.Stderr, "[!] Interrupted: %d of %d project(s) not scanned\n", len(projects)-i, len(projects))
+					break
+				}
+				rows, err := staleBranchesIn(c.Context, p, client, cutoff, c.Bool("local"), c.Bool("fetch"))
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "[-] %s: %v\n", p.Name, err)
+					continue
+				}
+				report = append(report, rows...)
+			}
+
+			if c.Bool("json") {
+				if report == nil {
+					report = []staleBranch{}
+				}
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(report)
+			}
+			printStaleReport(report, len(projects), c.Int("days"))
+			return nil
+		},
+	}, `Lists the branches whose last commit is older than --days and that are not the source of an open
+merge request (looked up on GitLab with GITLAB_PRIVATE_TOKEN; without it, or offline, the MR check is
+skipped and marked). The default branch, main, master, develop and release/* are never listed. Use
+--json to feed cleanup campaigns or spreadsheets.`,
+		cmd.Example{Command: "aio git stale", Comment: "origin/* branches of this repository untouched for 90 days"},
+		cmd.Example{Command: "aio git stale -A --days 180 --fetch", Comment: "Across every saved project"},
+		cmd.Example{Command: "aio git stale -A -t team-a --json > stale.json", Comment: "Report for a team's projects"},
+	)
+}
+
+// scannedProjects returns the current repository, or the saved projects with --all-projects.
+func scannedProjects(c *cli.Context) ([]project.Project, error) {
+	if !c.Bool("all-projects") {
+		root, err := git.GetTopLevel(c.Context)
+		if git.KindOf(err) == git.ErrNotARepo {
+			return nil, fmt.Errorf("not a git repository (use --all-projects to scan the saved projects)")
+		}
+		if err != nil {
+			return nil, git.WithHint(err)
+		}
+		return []project.Project{{Name: filepath.Base(root), Path: root}}, nil
+	}
+	store, err := project.Load()
+	if err != nil {
+		return nil, err
+	}
+	projects := project.FilterByTags(store.Projects, c.StringSlice("tag"))
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no projects to scan, use 'prj add' or 'prj git-add' to add projects")
+	}
+	return projects, nil
+}
+
+// staleClient returns the GitLab client used for the MR check, nil when it
+// can't be made.
+func staleClient(ctx context.Context) *gitlab.Client {
+	if offline.Enabled() {
+		fmt.Fprintln(os.Stderr, "[!] Offline: open MRs are not checked")
+		return nil
+	}
+	client, err := gitlab.NewClient(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[!] Open MRs are not checked: %v\n", err)
+		return nil
+	}
+	return client
+}
+
+// staleBranchesIn returns the stale branches of project p.
+func staleBranchesIn(ctx context.Context, p project.Project, client *gitlab.Client, cutoff time.Time, local bool, fetch bool) ([]staleBranch, error) {
+	if fetch {
+		if err := git.FetchIn(ctx, p.Path); err != nil {
+			return nil, err
+		}
+	}
+	branches, err := git.BranchActivityIn(ctx, p.Path, local)
+	if err != nil {
+		return nil, err
+	}
+	keep := append([]string{git.DefaultBranchIn(ctx, p.Path)}, staleKeep...)
+	var candidates []git.BranchActivity
+	for _, b := range branches {
+		if b.Date.Before(cutoff) && !matchesAny(b.Name, keep) {
+			candidates = append(candidates, b)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	openMRs, checked := staleOpenMRs(ctx, p, client)
+	var rows []staleBranch
+	for _, b := range candidates {
+		if openMRs[b.Name] {
+			continue
+		}
+		rows = append(rows, staleBranch{
+			Project:    p.Name,
+			Branch:     b.Name,
+			LastCommit: b.Date,
+			Days:       int(time.Since(b.Date).Hours() / 24),
+			Author:     b.Author,
+			MRChecked:  checked,
+		})
+	}
+	return rows, nil
+}
+
+// staleOpenMRs returns the source branches of the open MRs of p and whether
+// they could be looked up.
+func staleOpenMRs(ctx context.Context, p project.Project, client *gitlab.Client) (map[string]bool, bool) {
+	if client == nil {
+		return nil, false
+	}
+	projectID, err := git.ProjectIDIn(ctx, p.Path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[!] %s: open MRs not checked: %v\n", p.Name, err)
+		return nil, false
+	}
+	mrs, err := client.OpenMergeRequests(projectID, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[!] %s: open MRs not checked: %v\n", p.Name, err)
+		return nil, false
+	}
+	sources := map[string]bool{}
+	for _, mr := range mrs {
+		sources[mr.SourceBranch] = true
+	}
+	return sources, true
+}
+
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// printStaleReport prints the report as a table, oldest branches first per project.
+func printStaleReport(report []staleBranch, projects int, days int) {
+	if len(report) == 0 {
+		fmt.Printf("[+] No branches older than %d days without an open MR\n", days)
+		return
+	}
+	sort.SliceStable(report, func(i, j int) bool {
+		if report[i].Project != report[j].Project {
+			return report[i].Project < report[j].Project
+		}
+		return report[i].Days > report[j].Days
+	})
+
+	rows := [][]string{{"PROJECT", "BRANCH", "LAST", "DAYS", "AUTHOR"}}
+	unchecked := 0
+	for _, r := range report {
+		mark := ""
+		if !r.MRChecked {
+			mark = " *"
+			unchecked++
+		}
+		rows = append(rows, []string{r.Project, r.Branch, r.LastCommit.Format("2006-01-02"), strconv.Itoa(r.Days), r.Author + mark})
+	}
+	lines := ui.Table(rows, []ui.Column{
+		{Shrink: ui.TruncatePath, Min: 16},
+		{Shrink: ui.TruncateMiddle, Min: 16},
+		{},
+		{Right: true},
+		{Shrink: ui.Truncate},
+	}, ui.OutputWidth())
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+
+	touched := map[string]bool{}
+	for _, r := range report {
+		touched[r.Project] = true
+	}
+	fmt.Printf("\n%d stale branch(es) in %d of %d project(s)\n", len(report), len(touched), projects)
+	if unchecked > 0 {
+		fmt.Println("* open MRs not checked, the branch may still have one")
+	}
+}
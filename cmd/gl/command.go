@@ -0,0 +1,56 @@
+package gl
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/prompt"
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	subcommands := []*cli.Command{
+		varsCmd(),
+		snippetsCmd(),
+		wikiCmd(),
+		issuesCmd(),
+	}
+
+	return &cli.Command{
+		Name:        "gl",
+		Usage:       "GitLab issues, settings, snippets and wiki of the current repository's project",
+		Subcommands: subcommands,
+		Before:      cmd.Require(cmd.RequireTool("git"), cmd.RequireGitRepo()),
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				if !cmd.ValidateSubcommand(c, subcommands) {
+					return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+				}
+				return nil
+			}
+			return prompt.SelectCommand(c, subcommands, "Select a subcommand:", cli.ShowSubcommandHelp)
+		},
+	}
+}
+
+// project is the GitLab project of the current repository.
+type project struct {
+	client *gitlab.Client
+	id     string
+}
+
+// loadProject detects the GitLab project of the current repository.
+func loadProject(ctx context.Context) (*project, error) {
+	id, err := git.ExtractProjectID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client, err := gitlab.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &project{client: client, id: id}, nil
+}
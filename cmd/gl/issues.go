@@ -0,0 +1,234 @@
+package gl
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/browser"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+// issuesCmd lists the open issues of the project and lets the user triage them.
+func issuesCmd() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:  "issues",
+		Usage: "List the open issues of the project and triage them: assign, label, comment, close",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "label",
+				Aliases: []string{"l"},
+				Usage:   "Only issues with this label (repeatable, all must match)",
+			},
+			&cli.StringFlag{
+				Name:    "assignee",
+				Aliases: []string{"a"},
+				Usage:   "Only issues assigned to this username, 'me' or 'none'",
+			},
+			&cli.BoolFlag{
+				Name:  "no-triage",
+				Usage: "Only list the issues, don't offer actions",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			p, err := loadProject(c.Context)
+			if err != nil {
+				return err
+			}
+			filters := url.Values{}
+			if labels := c.StringSlice("label"); len(labels) > 0 {
+				filters.Set("labels", strings.Join(labels, ","))
+			}
+			switch assignee := c.String("assignee"); assignee {
+			case "":
+			case "none":
+				filters.Set("assignee_id", "None")
+			case "me":
+				me, err := p.client.CurrentUser()
+				if err != nil {
+					return err
+				}
+				filters.Set("assignee_username", me.Username)
+			default:
+				filters.Set("assignee_username", strings.TrimPrefix(assignee, "@"))
+			}
+
+			issues, err := p.client.OpenIssues(p.id, filters)
+			if err != nil {
+				return err
+			}
+			if len(issues) == 0 {
+				fmt.Println("[!] No open issues found")
+				return nil
+			}
+			printIssues(issues)
+
+			if c.Bool("no-triage") || ui.Plain() || !term.IsTerminal(int(os.Stdin.Fd())) {
+				return nil
+			}
+			fmt.Println()
+			return triage(p, issues)
+		},
+	}, `Issues are listed most recently updated first. In a terminal, pick an issue afterwards to assign it
+to yourself, add a label, comment, close it or open it in the browser, then go on with the next one.`,
+		cmd.Example{Command: "aio gl issues -l bug -a none", Comment: "Unassigned bugs"},
+		cmd.Example{Command: "aio gl issues -a me --no-triage", Comment: "Only list my issues"},
+	)
+}
+
+// printIssues prints issues as a table.
+func printIssues(issues []gitlab.Issue) {
+	rows := [][]string{{"IID", "TITLE", "LABELS", "ASSIGNEES", "UPDATED"}}
+	for _, issue := range issues {
+		rows = append(rows, []string{"#" + strconv.Itoa(issue.IID), issue.Title, strings.Join(issue.Labels, ","),
+			assignees(issue), issue.UpdatedAt.Format("2006-01-02")})
+	}
+	lines := ui.Table(rows, []ui.Column{
+		{Right: true},
+		{Shrink: ui.Truncate, Min: 20},
+		{Shrink: ui.Truncate},
+		{Shrink: ui.Truncate},
+	}, ui.OutputWidth())
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
+
+// triage lets the user pick issues and act on them until they quit.
+func triage(p *project, issues []gitlab.Issue) error {
+	const (
+		actionAssign  = "Assign to me"
+		actionLabel   = "Add a label"
+		actionComment = "Comment"
+		actionClose   = "Close"
+		actionOpen    = "Open in browser"
+		actionBack    = "Back to the issues"
+		done          = "Done"
+	)
+
+	var me *gitlab.User
+	for len(issues) > 0 {
+		labels := make([]string, len(issues)+1)
+		for i, issue := range issues {
+			labels[i] = issueLabel(issue)
+		}
+		labels[len(issues)] = done
+		idx, _, err := prompt.Select("Select issue:", labels, "")
+		if err != nil || idx == len(issues) {
+			return nil
+		}
+		issue := &issues[idx]
+
+		_, action, err := prompt.SelectWithFuzzy(fmt.Sprintf("#%d %s:", issue.IID, issue.Title),
+			[]string{actionAssign, actionLabel, actionComment, actionClose, actionOpen, actionBack}, actionBack, false)
+		if err != nil {
+			return nil
+		}
+
+		var updated *gitlab.Issue
+		switch action {
+		case actionAssign:
+			if me == nil {
+				if me, err = p.client.CurrentUser(); err != nil {
+					return err
+				}
+			}
+			if updated, err = p.client.UpdateIssue(p.id, issue.IID, map[string]interface{}{"assignee_ids": []int{me.ID}}); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Assigned #%d to %s\n", issue.IID, me.Username)
+		case actionLabel:
+			label, err := pickLabel(p, issue.Labels)
+			if err != nil {
+				return err
+			}
+			if label == "" {
+				continue
+			}
+			if updated, err = p.client.UpdateIssue(p.id, issue.IID, map[string]interface{}{"add_labels": label}); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Labeled #%d %s\n", issue.IID, label)
+		case actionComment:
+			body, err := prompt.Input("Comment:", "", true)
+			if err != nil {
+				continue
+			}
+			if err := p.client.CommentIssue(p.id, issue.IID, body); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Commented on #%d\n", issue.IID)
+		case actionClose:
+			if _, err := p.client.UpdateIssue(p.id, issue.IID, map[string]interface{}{"state_event": "close"}); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Closed #%d\n", issue.IID)
+			issues = append(issues[:idx], issues[idx+1:]...)
+		case actionOpen:
+			if err := browser.Open(issue.WebURL); err != nil {
+				return err
+			}
+		}
+		if updated != nil {
+			*issue = *updated
+		}
+	}
+	fmt.Println("[+] No open issues left")
+	return nil
+}
+
+// pickLabel lets the user choose a project label the issue doesn't have yet,
+// "" when there is none or the user cancelled.
+func pickLabel(p *project, current []string) (string, error) {
+	all, err := p.client.Labels(p.id)
+	if err != nil {
+		return "", err
+	}
+	has := map[string]bool{}
+	for _, label := range current {
+		has[label] = true
+	}
+	var options []string
+	for _, label := range all {
+		if !has[label.Name] {
+			options = append(options, label.Name)
+		}
+	}
+	if len(options) == 0 {
+		fmt.Printf("[!] The issue already has every label of %s\n", p.id)
+		return "", nil
+	}
+	_, label, err := prompt.Select("Select label:", options, "")
+	if err != nil {
+		return "", nil
+	}
+	return label, nil
+}
+
+// issueLabel describes an issue in the selector, e.g. "#12 Login fails [bug] @alice".
+func issueLabel(issue gitlab.Issue) string {
+	label := fmt.Sprintf("#%d %s", issue.IID, issue.Title)
+	if len(issue.Labels) > 0 {
+		label += " [" + strings.Join(issue.Labels, ", ") + "]"
+	}
+	if len(issue.Assignees) > 0 {
+		label += " " + assignees(issue)
+	}
+	return label
+}
+
+// assignees returns the usernames the issue is assigned to, e.g. "@alice,@bob".
+func assignees(issue gitlab.Issue) string {
+	names := make([]string, len(issue.Assignees))
+	for i, u := range issue.Assignees {
+		names[i] = "@" + u.Username
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,252 @@
+package gl
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/browser"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// snippetsCmd lists, shows, opens and creates the snippets of the project.
+func snippetsCmd() *cli.Command {
+	list := snippetsListCmd()
+	subcommands := []*cli.Command{
+		list,
+		snippetsShowCmd(),
+		snippetsOpenCmd(),
+		snippetsCreateCmd(),
+	}
+
+	return cmd.Describe(&cli.Command{
+		Name:        "snippets",
+		Usage:       "List, search, show, open and create the snippets of the project",
+		Subcommands: subcommands,
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				if !cmd.ValidateSubcommand(c, subcommands) {
+					return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+				}
+				return nil
+			}
+			return list.Action(c)
+		},
+	}, `A query matches the title, file name and description of the snippets, ignoring case. 'show' and
+'open' without an ID let you pick a snippet.`,
+		cmd.Example{Command: "aio gl snippets list deploy", Comment: "Snippets mentioning deploy"},
+		cmd.Example{Command: "aio gl snippets show 42 > rollback.sh", Comment: "Print the content of a snippet"},
+		cmd.Example{Command: "aio gl snippets create scripts/rollback.sh", Comment: "Share a file as a private snippet"},
+	)
+}
+
+func snippetsListCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "list",
+		Usage:     "List the snippets of the project, optionally matching a query",
+		ArgsUsage: "[query]",
+		Action: func(c *cli.Context) error {
+			p, err := loadProject(c.Context)
+			if err != nil {
+				return err
+			}
+			snippets, err := p.snippets(strings.Join(c.Args().Slice(), " "))
+			if err != nil {
+				return err
+			}
+			if len(snippets) == 0 {
+				fmt.Println("[!] No snippets found")
+				return nil
+			}
+			rows := [][]string{{"ID", "TITLE", "FILE", "AUTHOR", "UPDATED"}}
+			for _, s := range snippets {
+				rows = append(rows, []string{strconv.Itoa(s.ID), s.Title, s.FileName, s.Author.Username, s.UpdatedAt.Format("2006-01-02")})
+			}
+			lines := ui.Table(rows, []ui.Column{
+				{Right: true},
+				{Shrink: ui.Truncate, Min: 16},
+				{Shrink: ui.TruncatePath},
+				{Shrink: ui.Truncate},
+			}, ui.OutputWidth())
+			for _, line := range lines {
+				fmt.Println(line)
+			}
+			return nil
+		},
+	}
+}
+
+func snippetsShowCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "show",
+		Usage:     "Print the content of a snippet",
+		ArgsUsage: "[id]",
+		Action: func(c *cli.Context) error {
+			p, err := loadProject(c.Context)
+			if err != nil {
+				return err
+			}
+			s, err := p.pickSnippet(c.Args().First())
+			if err != nil {
+				return err
+			}
+			content, err := p.client.SnippetContent(p.id, s.ID)
+			if err != nil {
+				return err
+			}
+			fmt.Print(content)
+			if !strings.HasSuffix(content, "\n") {
+				fmt.Println()
+			}
+			return nil
+		},
+	}
+}
+
+func snippetsOpenCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "open",
+		Usage:     "Open a snippet in the browser",
+		ArgsUsage: "[id]",
+		Action: func(c *cli.Context) error {
+			p, err := loadProject(c.Context)
+			if err != nil {
+				return err
+			}
+			s, err := p.pickSnippet(c.Args().First())
+			if err != nil {
+				return err
+			}
+			return browser.Open(s.WebURL)
+		},
+	}
+}
+
+func snippetsCreateCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "create",
+		Usage:     "Create a snippet from a file",
+		ArgsUsage: "<file>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "title",
+				Aliases: []string{"t"},
+				Usage:   "Title of the snippet (default: the file name)",
+			},
+			&cli.StringFlag{
+				Name:    "description",
+				Aliases: []string{"d"},
+				Usage:   "Description of the snippet",
+			},
+			&cli.StringFlag{
+				Name:  "visibility",
+				Usage: "Who can see the snippet: private, internal or public",
+				Value: "private",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			file := c.Args().First()
+			if file == "" {
+				return fmt.Errorf("file is required")
+			}
+			switch c.String("visibility") {
+			case "private", "internal", "public":
+			default:
+				return fmt.Errorf("invalid visibility '%s': use private, internal or public", c.String("visibility"))
+			}
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", file, err)
+			}
+			if len(content) == 0 {
+				return fmt.Errorf("%s is empty", file)
+			}
+			title := c.String("title")
+			if title == "" {
+				title = filepath.Base(file)
+			}
+
+			p, err := loadProject(c.Context)
+			if err != nil {
+				return err
+			}
+			s, err := p.client.CreateSnippet(p.id, gitlab.NewSnippet{
+				Title:       title,
+				Description: c.String("description"),
+				Visibility:  c.String("visibility"),
+				Files:       []gitlab.SnippetFile{{FilePath: filepath.Base(file), Content: string(content)}},
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("[+] Created snippet $%d %s\n", s.ID, s.Title)
+			ui.PrintValue("URL", s.WebURL)
+			return nil
+		},
+	}
+}
+
+// snippets returns the snippets of the project matching query, all when it is empty.
+func (p *project) snippets(query string) ([]gitlab.Snippet, error) {
+	all, err := p.client.Snippets(p.id)
+	if err != nil {
+		return nil, err
+	}
+	var snippets []gitlab.Snippet
+	for _, s := range all {
+		if matches(query, s.Title, s.FileName, s.Description) {
+			snippets = append(snippets, s)
+		}
+	}
+	return snippets, nil
+}
+
+// pickSnippet returns the snippet with id, prompting for one when id is empty.
+func (p *project) pickSnippet(id string) (*gitlab.Snippet, error) {
+	snippets, err := p.snippets("")
+	if err != nil {
+		return nil, err
+	}
+	if id != "" {
+		n, err := strconv.Atoi(strings.TrimPrefix(id, "$"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid snippet id '%s'", id)
+		}
+		for i := range snippets {
+			if snippets[i].ID == n {
+				return &snippets[i], nil
+			}
+		}
+		return nil, fmt.Errorf("snippet $%d not found in %s", n, p.id)
+	}
+	if len(snippets) == 0 {
+		return nil, fmt.Errorf("%s has no snippets", p.id)
+	}
+	labels := make([]string, len(snippets))
+	for i, s := range snippets {
+		labels[i] = fmt.Sprintf("$%d %s (%s)", s.ID, s.Title, s.FileName)
+	}
+	idx, _, err := prompt.Select("Select snippet:", labels, "")
+	if err != nil {
+		return nil, fmt.Errorf("selection cancelled: %w", err)
+	}
+	return &snippets[idx], nil
+}
+
+// matches reports whether every word of query appears in one of fields,
+// ignoring case. An empty query matches everything.
+func matches(query string, fields ...string) bool {
+	text := strings.ToLower(strings.Join(fields, "\n"))
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,359 @@
+package gl
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/pkg/secrets"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// scopeFlag selects the environment scope of a variable defined for several environments.
+var scopeFlag = &cli.StringFlag{
+	Name:    "scope",
+	Aliases: []string{"s"},
+	Usage:   "Environment scope of the variable, when the key is defined for several environments",
+}
+
+// revealFlag shows values in clear instead of masked.
+var revealFlag = &cli.BoolFlag{
+	Name:  "reveal",
+	Usage: "Show values in clear instead of masked",
+}
+
+// varsCmd manages the CI/CD variables of the project.
+func varsCmd() *cli.Command {
+	list := varsListCmd()
+	subcommands := []*cli.Command{
+		list,
+		varsGetCmd(),
+		varsSetCmd(),
+		varsMaskCmd(),
+	}
+
+	return cmd.Describe(&cli.Command{
+		Name:        "vars",
+		Usage:       "List, get, set and mask the CI/CD variables of the project",
+		Subcommands: subcommands,
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				if !cmd.ValidateSubcommand(c, subcommands) {
+					return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+				}
+				return nil
+			}
+			return list.Action(c)
+		},
+	}, `Values are masked unless --reveal is given, so the list can be shown on a shared screen while
+debugging a pipeline. 'set' asks before overwriting an existing variable and keeps its flags unless
+new ones are given. Needs Maintainer access to the project.`,
+		cmd.Example{Command: "aio gl vars", Comment: "List the variables with masked values"},
+		cmd.Example{Command: "aio gl vars get DB_URL --reveal", Comment: "Print a value in clear"},
+		cmd.Example{Command: "aio gl vars set API_KEY --masked --protected", Comment: "Prompt for the value without echo"},
+		cmd.Example{Command: "aio gl vars set -s staging DB_URL - < url.txt", Comment: "Read the value from stdin, for one environment"},
+	)
+}
+
+func varsListCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List the variables of the project with masked values",
+		Flags: []cli.Flag{revealFlag},
+		Action: func(c *cli.Context) error {
+			p, err := loadProject(c.Context)
+			if err != nil {
+				return err
+			}
+			variables, err := p.client.Variables(p.id)
+			if err != nil {
+				return err
+			}
+			if len(variables) == 0 {
+				fmt.Printf("[!] %s has no CI/CD variables. Use 'aio gl vars set <key>' to add one.\n", p.id)
+				return nil
+			}
+			sort.SliceStable(variables, func(i, j int) bool {
+				if variables[i].Key != variables[j].Key {
+					return variables[i].Key < variables[j].Key
+				}
+				return variables[i].EnvironmentScope < variables[j].EnvironmentScope
+			})
+
+			rows := [][]string{{"KEY", "SCOPE", "FLAGS", "VALUE"}}
+			for _, v := range variables {
+				// File variables often span lines, the table needs one
+				value := strings.ReplaceAll(display(v.Value, c.Bool("reveal")), "\n", `\n`)
+				rows = append(rows, []string{v.Key, v.EnvironmentScope, flags(v), value})
+			}
+			lines := ui.Table(rows, []ui.Column{
+				{Shrink: ui.TruncateMiddle, Min: 16},
+				{Shrink: ui.Truncate},
+				{},
+				{Shrink: ui.Truncate},
+			}, ui.OutputWidth())
+			for _, line := range lines {
+				fmt.Println(line)
+			}
+			return nil
+		},
+	}
+}
+
+func varsGetCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "get",
+		Usage:     "Print the value of a variable (masked unless --reveal)",
+		ArgsUsage: "<key>",
+		Flags:     []cli.Flag{scopeFlag, revealFlag},
+		Action: func(c *cli.Context) error {
+			p, err := loadProject(c.Context)
+			if err != nil {
+				return err
+			}
+			v, err := p.variable(c.Args().First(), c.String("scope"))
+			if err != nil {
+				return err
+			}
+			ui.PrintValue(v.Key, display(v.Value, c.Bool("reveal")))
+			return nil
+		},
+	}
+}
+
+func varsSetCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "set",
+		Usage:     "Create or overwrite a variable (value prompted without echo, or '-' for stdin)",
+		ArgsUsage: "<key> [value|-]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "scope",
+				Aliases: []string{"s"},
+				Usage:   "Environment scope of the variable",
+				Value:   "*",
+			},
+			&cli.BoolFlag{
+				Name:  "protected",
+				Usage: "Only expose the variable to pipelines of protected branches and tags",
+			},
+			&cli.BoolFlag{
+				Name:  "masked",
+				Usage: "Hide the value in job logs",
+			},
+			&cli.BoolFlag{
+				Name:  "file",
+				Usage: "Expose the value as a file whose path is in the variable",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Overwrite an existing variable without asking",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			key := c.Args().Get(0)
+			if key == "" {
+				var err error
+				key, err = prompt.Input("Enter variable key:", "", true)
+				if err != nil {
+					return fmt.Errorf("input cancelled: %w", err)
+				}
+			}
+			value, err := readValue(key, c.Args().Get(1), c.Args().Len() > 1)
+			if err != nil {
+				return err
+			}
+
+			p, err := loadProject(c.Context)
+			if err != nil {
+				return err
+			}
+			scope := c.String("scope")
+			existing, err := p.client.GetVariable(p.id, key, scope)
+			if err != nil {
+				return err
+			}
+
+			v := gitlab.Variable{Key: key, Value: value, VariableType: "env_var", EnvironmentScope: scope}
+			if existing != nil {
+				// Flags not given keep their current value
+				v.VariableType, v.Protected, v.Masked = existing.VariableType, existing.Protected, existing.Masked
+			}
+			if c.IsSet("protected") {
+				v.Protected = c.Bool("protected")
+			}
+			if c.IsSet("masked") {
+				v.Masked = c.Bool("masked")
+			}
+			if c.IsSet("file") {
+				v.VariableType = "env_var"
+				if c.Bool("file") {
+					v.VariableType = "file"
+				}
+			}
+
+			if existing == nil {
+				if _, err := p.client.CreateVariable(p.id, v); err != nil {
+					return maskError(err, v)
+				}
+				fmt.Printf("[+] Created %s (%s)\n", key, summary(v))
+				return nil
+			}
+			if existing.Value == v.Value && flags(*existing) == flags(v) {
+				fmt.Printf("[+] %s is already up to date\n", key)
+				return nil
+			}
+			if !c.Bool("yes") {
+				msg := fmt.Sprintf("Overwrite %s (scope %s, currently %s)?", key, scope, display(existing.Value, false))
+				if ok, err := prompt.Confirm(msg, false); err != nil {
+					return fmt.Errorf("%s exists, use --yes to overwrite it: %w", key, err)
+				} else if !ok {
+					fmt.Println("[-] Cancelled")
+					return nil
+				}
+			}
+			if _, err := p.client.UpdateVariable(p.id, v); err != nil {
+				return maskError(err, v)
+			}
+			fmt.Printf("[+] Updated %s (%s)\n", key, summary(v))
+			return nil
+		},
+	}
+}
+
+func varsMaskCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "mask",
+		Usage:     "Hide the value of a variable in job logs",
+		ArgsUsage: "<key>",
+		Flags:     []cli.Flag{scopeFlag},
+		Action: func(c *cli.Context) error {
+			p, err := loadProject(c.Context)
+			if err != nil {
+				return err
+			}
+			v, err := p.variable(c.Args().First(), c.String("scope"))
+			if err != nil {
+				return err
+			}
+			if v.Masked {
+				fmt.Printf("[!] %s is already masked\n", v.Key)
+				return nil
+			}
+			v.Masked = true
+			if _, err := p.client.UpdateVariable(p.id, *v); err != nil {
+				return maskError(err, *v)
+			}
+			fmt.Printf("[+] Masked %s (scope %s)\n", v.Key, v.EnvironmentScope)
+			return nil
+		},
+	}
+}
+
+// variable returns the variable key of scope, prompting for one when key is empty.
+func (p *project) variable(key string, scope string) (*gitlab.Variable, error) {
+	if key == "" {
+		variables, err := p.client.Variables(p.id)
+		if err != nil {
+			return nil, err
+		}
+		if len(variables) == 0 {
+			return nil, fmt.Errorf("%s has no CI/CD variables", p.id)
+		}
+		labels := make([]string, len(variables))
+		for i, v := range variables {
+			labels[i] = fmt.Sprintf("%s (%s)", v.Key, v.EnvironmentScope)
+		}
+		idx, _, err := prompt.Select("Select variable:", labels, "")
+		if err != nil {
+			return nil, fmt.Errorf("selection cancelled: %w", err)
+		}
+		return &variables[idx], nil
+	}
+
+	v, err := p.client.GetVariable(p.id, key, scope)
+	if err != nil {
+		var apiErr *gitlab.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+			return nil, fmt.Errorf("%s is defined for several environments, pick one with --scope", key)
+		}
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("variable '%s' not found in %s", key, p.id)
+	}
+	return v, nil
+}
+
+// readValue returns the value to set for key: arg when given, stdin for "-",
+// otherwise prompted without echo.
+func readValue(key string, arg string, given bool) (string, error) {
+	switch {
+	case arg == "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the value from stdin: %w", err)
+		}
+		// A trailing newline comes from echo or the editor, not the value
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case given:
+		return arg, nil
+	}
+	value, err := prompt.Password(fmt.Sprintf("Value for %s:", key))
+	if err != nil {
+		return "", fmt.Errorf("input cancelled: %w", err)
+	}
+	return value, nil
+}
+
+// maskError explains why GitLab refused to save a masked variable.
+func maskError(err error, v gitlab.Variable) error {
+	var apiErr *gitlab.APIError
+	if v.Masked && errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+		return fmt.Errorf("%w\nmasked values must be a single line of at least 8 characters without spaces", err)
+	}
+	return err
+}
+
+// flags describes the flags of a variable, e.g. "protected, masked".
+func flags(v gitlab.Variable) string {
+	var set []string
+	if v.VariableType == "file" {
+		set = append(set, "file")
+	}
+	if v.Protected {
+		set = append(set, "protected")
+	}
+	if v.Masked {
+		set = append(set, "masked")
+	}
+	if len(set) == 0 {
+		return "-"
+	}
+	return strings.Join(set, ", ")
+}
+
+// summary describes the scope and flags of a variable, e.g. "scope *, masked".
+func summary(v gitlab.Variable) string {
+	if f := flags(v); f != "-" {
+		return "scope " + v.EnvironmentScope + ", " + f
+	}
+	return "scope " + v.EnvironmentScope
+}
+
+// display returns value as shown: masked unless reveal.
+func display(value string, reveal bool) string {
+	if reveal {
+		return value
+	}
+	return secrets.Mask(value)
+}
@@ -0,0 +1,174 @@
+package gl
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/browser"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// wikiCmd lists, searches, shows and opens the wiki pages of the project.
+func wikiCmd() *cli.Command {
+	list := wikiListCmd()
+	subcommands := []*cli.Command{
+		list,
+		wikiShowCmd(),
+		wikiOpenCmd(),
+	}
+
+	return cmd.Describe(&cli.Command{
+		Name:        "wiki",
+		Usage:       "List, search, show and open the wiki pages of the project",
+		Subcommands: subcommands,
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				if !cmd.ValidateSubcommand(c, subcommands) {
+					return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+				}
+				return nil
+			}
+			return list.Action(c)
+		},
+	}, `A query matches the title and slug of the pages, ignoring case, and their content with --content.
+'show' and 'open' without a slug let you pick a page.`,
+		cmd.Example{Command: "aio gl wiki list --content rollback", Comment: "Pages mentioning rollback"},
+		cmd.Example{Command: "aio gl wiki show runbooks/deploy", Comment: "Print a page"},
+		cmd.Example{Command: "aio gl wiki open", Comment: "Pick a page and open it in the browser"},
+	)
+}
+
+func wikiListCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "list",
+		Usage:     "List the wiki pages of the project, optionally matching a query",
+		ArgsUsage: "[query]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "content",
+				Aliases: []string{"c"},
+				Usage:   "Also search the content of the pages",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			p, err := loadProject(c.Context)
+			if err != nil {
+				return err
+			}
+			pages, err := p.wikiPages(strings.Join(c.Args().Slice(), " "), c.Bool("content"))
+			if err != nil {
+				return err
+			}
+			if len(pages) == 0 {
+				fmt.Println("[!] No wiki pages found")
+				return nil
+			}
+			rows := [][]string{{"SLUG", "TITLE"}}
+			for _, page := range pages {
+				rows = append(rows, []string{page.Slug, page.Title})
+			}
+			lines := ui.Table(rows, []ui.Column{
+				{Shrink: ui.TruncatePath, Min: 16},
+				{Shrink: ui.Truncate},
+			}, ui.OutputWidth())
+			for _, line := range lines {
+				fmt.Println(line)
+			}
+			return nil
+		},
+	}
+}
+
+func wikiShowCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "show",
+		Usage:     "Print the content of a wiki page",
+		ArgsUsage: "[slug]",
+		Action: func(c *cli.Context) error {
+			p, err := loadProject(c.Context)
+			if err != nil {
+				return err
+			}
+			slug, err := p.pickWikiPage(c.Args().First())
+			if err != nil {
+				return err
+			}
+			page, err := p.client.GetWikiPage(p.id, slug)
+			if err != nil {
+				return err
+			}
+			if page == nil {
+				return fmt.Errorf("wiki page '%s' not found in %s", slug, p.id)
+			}
+			fmt.Print(page.Content)
+			if !strings.HasSuffix(page.Content, "\n") {
+				fmt.Println()
+			}
+			return nil
+		},
+	}
+}
+
+func wikiOpenCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "open",
+		Usage:     "Open a wiki page in the browser",
+		ArgsUsage: "[slug]",
+		Action: func(c *cli.Context) error {
+			p, err := loadProject(c.Context)
+			if err != nil {
+				return err
+			}
+			slug, err := p.pickWikiPage(c.Args().First())
+			if err != nil {
+				return err
+			}
+			return browser.Open(p.client.WikiURL(p.id, slug))
+		},
+	}
+}
+
+// wikiPages returns the wiki pages matching query, all when it is empty.
+func (p *project) wikiPages(query string, inContent bool) ([]gitlab.WikiPage, error) {
+	all, err := p.client.WikiPages(p.id, inContent && query != "")
+	if err != nil {
+		return nil, err
+	}
+	var pages []gitlab.WikiPage
+	for _, page := range all {
+		if matches(query, page.Title, page.Slug, page.Content) {
+			pages = append(pages, page)
+		}
+	}
+	return pages, nil
+}
+
+// pickWikiPage returns slug, or prompts for a page when it is empty.
+func (p *project) pickWikiPage(slug string) (string, error) {
+	if slug != "" {
+		return slug, nil
+	}
+	pages, err := p.client.WikiPages(p.id, false)
+	if err != nil {
+		return "", err
+	}
+	if len(pages) == 0 {
+		return "", fmt.Errorf("%s has no wiki pages", p.id)
+	}
+	labels := make([]string, len(pages))
+	for i, page := range pages {
+		labels[i] = page.Slug
+		if page.Title != page.Slug {
+			labels[i] += " (" + page.Title + ")"
+		}
+	}
+	idx, _, err := prompt.Select("Select wiki page:", labels, "")
+	if err != nil {
+		return "", fmt.Errorf("selection cancelled: %w", err)
+	}
+	return pages[idx].Slug, nil
+}
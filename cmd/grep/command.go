@@ -0,0 +1,196 @@
+package grep
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/editor"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/project"
+	"cli-aio/internal/pkg/search"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// maxTextWidth is how much of a matching line the selector shows.
+const maxTextWidth = 120
+
+// root is a directory to search and the label its matches are shown under.
+type root struct {
+	name string
+	dir  string
+}
+
+func Command() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:      "grep",
+		Usage:     "Search the current project (or saved projects) and open a match in the editor",
+		ArgsUsage: "<pattern>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "project",
+				Aliases: []string{"p"},
+				Usage:   "Search this saved project (name or path query) instead of the current one",
+			},
+			&cli.BoolFlag{
+				Name:    "all",
+				Aliases: []string{"a"},
+				Usage:   "Search all saved projects",
+			},
+			&cli.BoolFlag{
+				Name:    "ignore-case",
+				Aliases: []string{"i"},
+				Usage:   "Match case-insensitively",
+			},
+			&cli.BoolFlag{
+				Name:    "fixed",
+				Aliases: []string{"F"},
+				Usage:   "Treat the pattern as a literal string",
+			},
+			&cli.BoolFlag{
+				Name:    "list",
+				Aliases: []string{"l"},
+				Usage:   "Print the matches as file:line:text instead of selecting one",
+			},
+			&cli.IntFlag{
+				Name:  "limit",
+				Usage: "Stop after this many matches",
+				Value: 500,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			pattern := c.Args().First()
+			if pattern == "" {
+				return fmt.Errorf("no pattern given, e.g. aio grep 'func main'")
+			}
+			roots, err := searchRoots(c)
+			if err != nil {
+				return err
+			}
+			if !search.HasRipgrep() {
+				fmt.Fprintln(os.Stderr, "[!] ripgrep (rg) not found, using the slower built-in search")
+			}
+
+			opts := search.Options{IgnoreCase: c.Bool("ignore-case"), Fixed: c.Bool("fixed"), Limit: c.Int("limit")}
+			var matches []search.Match
+			var labels []string
+			limited := false
+			for i, r := range roots {
+				if cmd.Stopping(c.Context) {
+					fmt.Fprintf(os.Stderr, "[!] Interrupted, %d of %d project(s) searched\n", i, len(roots))
+					break
+				}
+				found, err := search.Search(c.Context, r.dir, pattern, opts)
+				if errors.Is(err, search.ErrLimit) {
+					limited = true
+				} else if err != nil {
+					return err
+				}
+				for _, m := range found {
+					matches = append(matches, m)
+					labels = append(labels, matchLabel(r, m, len(roots) > 1))
+				}
+				if opts.Limit > 0 {
+					if opts.Limit -= len(found); opts.Limit <= 0 {
+						limited = true
+						break
+					}
+				}
+			}
+
+			if limited {
+				fmt.Fprintf(os.Stderr, "[!] Showing the first %d matches, narrow the pattern or raise --limit\n", len(matches))
+			}
+			if len(matches) == 0 {
+				fmt.Println("[!] No matches")
+				return nil
+			}
+			if c.Bool("list") || ui.Plain() {
+				for _, label := range labels {
+					fmt.Println(label)
+				}
+				return nil
+			}
+
+			idx, _, err := prompt.Select(fmt.Sprintf("%d match(es), open:", len(matches)), labels, "")
+			if err != nil {
+				return fmt.Errorf("selection cancelled: %w", err)
+			}
+			return editor.Open(matches[idx].Path, matches[idx].Line)
+		},
+	}, `Uses ripgrep when installed (respecting .gitignore), otherwise a built-in search that skips hidden
+directories, node_modules, vendor and binary files. The pattern is a regular expression unless -F is
+given. Without --project or --all, the git repository of the current directory is searched. Pick a
+match to open it at its line in $VISUAL/$EDITOR; when piped, matches are printed as file:line:text.`,
+		cmd.Example{Command: "aio grep 'func New'", Comment: "Search the current project"},
+		cmd.Example{Command: "aio grep -p payment -i timeout", Comment: "Search another saved project"},
+		cmd.Example{Command: "aio grep --all -F 'gitlab.zalopay.vn'", Comment: "Search every saved project"},
+	)
+}
+
+// searchRoots returns the directories to search from the flags.
+func searchRoots(c *cli.Context) ([]root, error) {
+	if c.Bool("all") || c.String("project") != "" {
+		store, err := project.Load()
+		if err != nil {
+			return nil, err
+		}
+		if c.Bool("all") {
+			if len(store.Projects) == 0 {
+				return nil, fmt.Errorf("no projects saved, use 'prj add' or 'prj git-add' to add projects")
+			}
+			roots := make([]root, len(store.Projects))
+			for i, p := range store.Projects {
+				roots[i] = root{name: p.Name, dir: p.Path}
+			}
+			return roots, nil
+		}
+
+		matches := project.Match(store.Projects, c.String("project"))
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("no project matches '%s'", c.String("project"))
+		case 1:
+			return []root{{name: matches[0].Name, dir: matches[0].Path}}, nil
+		}
+		labels := make([]string, len(matches))
+		for i, p := range matches {
+			labels[i] = p.DisplayLabel()
+		}
+		idx, _, err := prompt.Select("Select a project:", labels, "")
+		if err != nil {
+			return nil, fmt.Errorf("selection cancelled: %w", err)
+		}
+		return []root{{name: matches[idx].Name, dir: matches[idx].Path}}, nil
+	}
+
+	dir, err := git.GetTopLevel(c.Context)
+	if err != nil {
+		if dir, err = os.Getwd(); err != nil {
+			return nil, fmt.Errorf("cannot determine current directory: %w", err)
+		}
+	}
+	return []root{{name: filepath.Base(dir), dir: dir}}, nil
+}
+
+// matchLabel formats a match as file:line: text, the file relative to its
+// project (prefixed with the project name when several are searched).
+func matchLabel(r root, m search.Match, withProject bool) string {
+	path := m.Path
+	if rel, err := filepath.Rel(r.dir, m.Path); err == nil {
+		path = rel
+	}
+	if withProject {
+		path = filepath.Join(r.name, path)
+	}
+	text := strings.TrimSpace(m.Text)
+	if runes := []rune(text); len(runes) > maxTextWidth {
+		text = string(runes[:maxTextWidth]) + "..."
+	}
+	return fmt.Sprintf("%s:%d: %s", filepath.ToSlash(path), m.Line, text)
+}
@@ -0,0 +1,271 @@
+package jira
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/browser"
+	"cli-aio/internal/pkg/jira"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	subcommands := []*cli.Command{
+		listCmd(),
+		viewCmd(),
+		moveCmd(),
+		branchCmd(),
+	}
+
+	return &cli.Command{
+		Name:        "jira",
+		Usage:       "Jira quick actions for your assigned issues",
+		Subcommands: subcommands,
+		Before:      cmd.Require(cmd.RequireConfigKey("jira.base_url")),
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				if !cmd.ValidateSubcommand(c, subcommands) {
+					return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+				}
+				return nil
+			}
+			return prompt.SelectCommand(c, subcommands, "Select a subcommand:", cli.ShowSubcommandHelp)
+		},
+	}
+}
+
+// listCmd prints the unresolved issues assigned to the current user.
+func listCmd() *cli.Command {
+	return &cli.Command{
+		Name:    "list",
+		Aliases: []string{"mine"},
+		Usage:   "List unresolved issues assigned to me",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:    "limit",
+				Aliases: []string{"n"},
+				Usage:   "Maximum number of issues to show",
+				Value:   30,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			client, err := jira.NewClient(c.Context)
+			if err != nil {
+				return err
+			}
+			issues, err := client.MyIssues(c.Int("limit"))
+			if err != nil {
+				return err
+			}
+			if len(issues) == 0 {
+				fmt.Println("[+] No unresolved issues assigned to you")
+				return nil
+			}
+			for _, issue := range issues {
+				fmt.Printf("%-12s %-14s %s\n", issue.Key, ui.Colorize(ui.Cyan, issue.Fields.Status.Name), issue.Fields.Summary)
+			}
+			return nil
+		},
+	}
+}
+
+// viewCmd shows the details of an issue.
+func viewCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "view",
+		Usage:     "Show details of an issue",
+		ArgsUsage: "[issue key]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "web",
+				Usage: "Open the issue in the browser",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			client, err := jira.NewClient(c.Context)
+			if err != nil {
+				return err
+			}
+			key, err := issueKeyArg(c, client)
+			if err != nil {
+				return err
+			}
+			if c.Bool("web") {
+				return browser.Open(client.BrowseURL(key))
+			}
+
+			issue, err := client.GetIssue(key)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s  %s\n", ui.Colorize(ui.Bold, issue.Key), issue.Fields.Summary)
+			fmt.Printf("Type:     %s\n", issue.Fields.IssueType.Name)
+			fmt.Printf("Status:   %s\n", issue.Fields.Status.Name)
+			fmt.Printf("Priority: %s\n", issue.Fields.Priority.Name)
+			if issue.Fields.Assignee != nil {
+				fmt.Printf("Assignee: %s\n", issue.Fields.Assignee.DisplayName)
+			}
+			if issue.Fields.Reporter != nil {
+				fmt.Printf("Reporter: %s\n", issue.Fields.Reporter.DisplayName)
+			}
+			fmt.Printf("URL:      %s\n", client.BrowseURL(issue.Key))
+			if desc := strings.TrimSpace(issue.Fields.Description); desc != "" {
+				fmt.Printf("\n%s\n", desc)
+			}
+			return nil
+		},
+	}
+}
+
+// moveCmd transitions an issue to another status.
+func moveCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "move",
+		Usage:     "Transition an issue to another status",
+		ArgsUsage: "[issue key] [transition]",
+		Action: func(c *cli.Context) error {
+			client, err := jira.NewClient(c.Context)
+			if err != nil {
+				return err
+			}
+			key, err := issueKeyArg(c, client)
+			if err != nil {
+				return err
+			}
+
+			transitions, err := client.Transitions(key)
+			if err != nil {
+				return err
+			}
+			if len(transitions) == 0 {
+				return fmt.Errorf("no transitions available for %s", key)
+			}
+
+			var chosen *jira.Transition
+			if name := c.Args().Get(1); name != "" {
+				for i := range transitions {
+					if strings.EqualFold(transitions[i].Name, name) || strings.EqualFold(transitions[i].To.Name, name) {
+						chosen = &transitions[i]
+						break
+					}
+				}
+				if chosen == nil {
+					return fmt.Errorf("transition '%s' is not available for %s", name, key)
+				}
+			} else {
+				labels := make([]string, len(transitions))
+				for i, t := range transitions {
+					labels[i] = fmt.Sprintf("%s -> %s", t.Name, t.To.Name)
+				}
+				idx, _, err := prompt.Select("Select transition:", labels, "")
+				if err != nil {
+					return fmt.Errorf("selection cancelled: %w", err)
+				}
+				chosen = &transitions[idx]
+			}
+
+			if err := client.DoTransition(key, chosen.ID); err != nil {
+				return err
+			}
+			fmt.Printf("[+] %s moved to '%s'\n", key, chosen.To.Name)
+			return nil
+		},
+	}
+}
+
+// branchCmd creates a git branch for an issue by delegating to 'aio git nb'.
+func branchCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "branch",
+		Usage:     "Create a git branch for an issue (delegates to 'aio git nb')",
+		ArgsUsage: "[issue key]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "from",
+				Aliases: []string{"f"},
+				Usage:   "Base branch to create the new branch from",
+			},
+			&cli.BoolFlag{
+				Name:  "key-only",
+				Usage: "Use only the issue key as branch name (no summary)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			client, err := jira.NewClient(c.Context)
+			if err != nil {
+				return err
+			}
+			key, err := issueKeyArg(c, client)
+			if err != nil {
+				return err
+			}
+
+			name := key
+			if !c.Bool("key-only") {
+				issue, err := client.GetIssue(key)
+				if err != nil {
+					return err
+				}
+				name = key + " " + branchSummary(issue.Fields.Summary)
+			}
+
+			exe, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("cannot locate aio executable: %w", err)
+			}
+			args := []string{"git", "nb"}
+			if from := c.String("from"); from != "" {
+				args = append(args, "--from", from)
+			}
+			args = append(args, name)
+
+			nb := exec.Command(exe, args...)
+			nb.Stdin = os.Stdin
+			nb.Stdout = os.Stdout
+			nb.Stderr = os.Stderr
+			if err := nb.Run(); err != nil {
+				return fmt.Errorf("git nb failed: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// issueKeyArg returns the issue key from the first argument, or lets the user
+// pick one of their assigned issues.
+func issueKeyArg(c *cli.Context, client *jira.Client) (string, error) {
+	if key := c.Args().First(); key != "" {
+		return strings.ToUpper(key), nil
+	}
+
+	issues, err := client.MyIssues(50)
+	if err != nil {
+		return "", err
+	}
+	if len(issues) == 0 {
+		return "", fmt.Errorf("no issues assigned to you, pass an issue key")
+	}
+	labels := make([]string, len(issues))
+	for i, issue := range issues {
+		labels[i] = fmt.Sprintf("%-12s %s", issue.Key, issue.Fields.Summary)
+	}
+	idx, _, err := prompt.Select("Select issue:", labels, "")
+	if err != nil {
+		return "", fmt.Errorf("selection cancelled: %w", err)
+	}
+	return issues[idx].Key, nil
+}
+
+// branchSummary shortens an issue summary to at most 6 lowercase words for use in branch names.
+func branchSummary(summary string) string {
+	words := strings.Fields(strings.ToLower(summary))
+	if len(words) > 6 {
+		words = words[:6]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,190 @@
+package newproj
+
+import (
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/pkg/project"
+	"cli-aio/internal/pkg/scaffold"
+	"cli-aio/internal/prompt"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Command returns the new command which scaffolds a project from a registered
+// template repository (degit-style: no template history is kept).
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:      "new",
+		Usage:     "Scaffold a project from a registered template repository",
+		ArgsUsage: "[template] [name]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "module",
+				Aliases: []string{"m"},
+				Usage:   "Module path substituted for {{module_path}} (and the template's go.mod module)",
+			},
+			&cli.StringFlag{
+				Name:    "dir",
+				Aliases: []string{"d"},
+				Usage:   "Target directory (default: ./<name>)",
+			},
+			&cli.StringFlag{
+				Name:    "gitlab-group",
+				Aliases: []string{"g"},
+				Usage:   "Create a GitLab repository in this group and push the initial commit",
+			},
+			OrgTemplateFlag,
+			&cli.BoolFlag{
+				Name:  "no-register",
+				Usage: "Don't add the new project to the prj store",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			if len(cfg.Templates) == 0 {
+				return fmt.Errorf("no templates registered, add them under \"templates\" in config.json (name -> git URL)")
+			}
+
+			templateName := c.Args().Get(0)
+			if templateName == "" {
+				names := make([]string, 0, len(cfg.Templates))
+				for name := range cfg.Templates {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				_, templateName, err = prompt.Select("Select template:", names, "")
+				if err != nil {
+					return fmt.Errorf("selection cancelled: %w", err)
+				}
+			}
+			repoURL, ok := cfg.Templates[templateName]
+			if !ok {
+				return fmt.Errorf("unknown template: %s", templateName)
+			}
+
+			name := c.Args().Get(1)
+			if name == "" {
+				if name, err = prompt.Input("Project name:", "", true); err != nil {
+					return fmt.Errorf("input cancelled: %w", err)
+				}
+			}
+
+			group := c.String("gitlab-group")
+			modulePath := c.String("module")
+			if modulePath == "" {
+				defaultModule := name
+				if group != "" {
+					if u, err := url.Parse(cfg.GitLab.BaseURL); err == nil {
+						defaultModule = fmt.Sprintf("%s/%s/%s", u.Host, group, name)
+					}
+				}
+				modulePath, err = prompt.Input("Module path:", defaultModule, false)
+				if err != nil || modulePath == "" {
+					modulePath = defaultModule
+				}
+			}
+
+			dir := c.String("dir")
+			if dir == "" {
+				dir = name
+			}
+			absDir, err := filepath.Abs(dir)
+			if err != nil {
+				return fmt.Errorf("invalid path: %w", err)
+			}
+			if _, err := os.Stat(absDir); err == nil {
+				return fmt.Errorf("target directory already exists: %s", absDir)
+			}
+
+			fmt.Printf("Cloning template '%s'...\n", templateName)
+			if err := scaffold.Clone(c.Context, repoURL, absDir); err != nil {
+				return err
+			}
+			vars := scaffold.Vars{ProjectName: name, ModulePath: modulePath}
+			changed, err := scaffold.Substitute(absDir, vars)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("[+] Substituted variables in %d file(s)\n", changed)
+
+			hooks, err := ApplyOrgTemplate(c, absDir, vars)
+			if err != nil {
+				return err
+			}
+
+			if err := scaffold.InitRepo(c.Context, absDir); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Initialized git repository with an initial commit\n")
+			if hooks {
+				if err := scaffold.EnableHooks(c.Context, absDir); err != nil {
+					fmt.Printf("[!] Warning: %v\n", err)
+				}
+			}
+
+			if group != "" {
+				if err := createRemote(c.Context, absDir, group, name); err != nil {
+					fmt.Printf("[!] Warning: Failed to create GitLab repository: %v\n", err)
+				}
+			}
+
+			if !c.Bool("no-register") {
+				if err := registerProject(absDir); err != nil {
+					fmt.Printf("[!] Warning: Failed to register project: %v\n", err)
+				}
+			}
+
+			fmt.Printf("\n[+] Created %s\n", absDir)
+			return nil
+		},
+	}
+}
+
+// createRemote creates the GitLab project and pushes the initial commit to it.
+func createRemote(ctx context.Context, dir string, group string, name string) error {
+	client, err := gitlab.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+	ns, err := client.FindNamespace(group)
+	if err != nil {
+		return err
+	}
+	p, err := client.CreateProject(ns.ID, name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("[+] Created GitLab repository %s\n", p.WebURL)
+
+	if err := scaffold.PushToRemote(ctx, dir, p.SSHURLToRepo); err != nil {
+		return err
+	}
+	fmt.Printf("[+] Pushed initial commit to %s\n", p.SSHURLToRepo)
+	return nil
+}
+
+// registerProject adds the new directory to the prj store.
+func registerProject(dir string) error {
+	store, err := project.Load()
+	if err != nil {
+		return err
+	}
+	p := project.Project{Name: filepath.Base(dir), Path: dir}
+	if !project.Add(store, p) {
+		return nil
+	}
+	if err := project.Save(store); err != nil {
+		return err
+	}
+	fmt.Printf("[+] Added project: %s (%s)\n", p.Name, p.Path)
+	return nil
+}
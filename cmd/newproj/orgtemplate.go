@@ -0,0 +1,85 @@
+package newproj
+
+import (
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/scaffold"
+	"cli-aio/internal/prompt"
+	"fmt"
+	"sort"
+
+	"github.com/urfave/cli/v2"
+)
+
+// OrgTemplateFlag selects the org template set applied to a new repository.
+var OrgTemplateFlag = &cli.StringFlag{
+	Name:  "org-template",
+	Usage: "Org template set from config.json to apply (\"none\" to skip, default: ask)",
+}
+
+// ApplyOrgTemplate adds the files of an org template set (CI config,
+// CODEOWNERS, hooks, ...) to the repository in dir, asking per file. The set
+// comes from --org-template or is selected when several are configured.
+// Reports whether hooks were written, so the caller can enable them once the
+// repository exists.
+func ApplyOrgTemplate(c *cli.Context, dir string, vars scaffold.Vars) (bool, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return false, err
+	}
+	name := c.String(OrgTemplateFlag.Name)
+	if name == "none" || len(cfg.OrgTemplates) == 0 {
+		if name != "" && name != "none" {
+			return false, fmt.Errorf("unknown org template: %s (none configured under \"org_templates\")", name)
+		}
+		return false, nil
+	}
+
+	if name == "" {
+		const skip = "None"
+		names := make([]string, 0, len(cfg.OrgTemplates))
+		for n := range cfg.OrgTemplates {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		defaultName := names[0]
+		if _, ok := cfg.OrgTemplates["default"]; ok {
+			defaultName = "default"
+		}
+		_, name, err = prompt.Select("Apply org template:", append(names, skip), defaultName)
+		if err != nil || name == skip {
+			return false, nil
+		}
+	}
+	files, ok := cfg.OrgTemplates[name]
+	if !ok {
+		return false, fmt.Errorf("unknown org template: %s", name)
+	}
+	orgFiles, err := scaffold.LoadOrgTemplate(files)
+	if err != nil {
+		return false, err
+	}
+
+	hooks := false
+	for _, f := range orgFiles {
+		exists := f.Exists(dir)
+		question := fmt.Sprintf("Add %s?", f.Path)
+		if exists {
+			question = fmt.Sprintf("%s already exists, overwrite it?", f.Path)
+		}
+		// Without a terminal, new files are added and existing ones kept
+		apply, err := prompt.Confirm(question, !exists)
+		if err != nil {
+			apply = !exists
+		}
+		if !apply {
+			fmt.Printf("[!] Skipped %s\n", f.Path)
+			continue
+		}
+		if err := f.Write(dir, vars); err != nil {
+			return hooks, err
+		}
+		hooks = hooks || f.IsHook()
+		fmt.Printf("[+] Added %s\n", f.Path)
+	}
+	return hooks, nil
+}
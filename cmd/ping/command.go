@@ -0,0 +1,128 @@
+package ping
+
+import (
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/health"
+	"cli-aio/internal/ui"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Command returns the ping command which probes the health endpoints of a project.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:      "ping",
+		Usage:     "Probe the project's health endpoints (qc/stg/prod) and show status, latency and version",
+		ArgsUsage: "[project]",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "env",
+				Aliases: []string{"e"},
+				Usage:   "Only probe these environments (repeatable)",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Value: 5 * time.Second,
+				Usage: "Timeout per request",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			name, endpoints, err := projectEndpoints(c.Context, cfg, c.Args().First())
+			if err != nil {
+				return err
+			}
+
+			if envs := c.StringSlice("env"); len(envs) > 0 {
+				filtered := map[string]string{}
+				for _, env := range envs {
+					url, ok := endpoints[env]
+					if !ok {
+						return fmt.Errorf("no '%s' endpoint configured for %s", env, name)
+					}
+					filtered[env] = url
+				}
+				endpoints = filtered
+			}
+
+			fmt.Printf("-> Probing %d endpoint(s) of %s\n\n", len(endpoints), name)
+			results := health.ProbeAll(endpoints, c.Duration("timeout"))
+
+			failed := 0
+			fmt.Printf("%-8s %-8s %-9s %-16s %s\n", "ENV", "STATUS", "LATENCY", "VERSION", "URL")
+			for _, r := range results {
+				status := "ERR"
+				if r.Err == nil {
+					status = fmt.Sprintf("%d", r.Status)
+				}
+				color := ui.Green
+				if !r.OK() {
+					color = ui.Red
+					failed++
+				}
+				version := r.Version
+				if version == "" {
+					version = "-"
+				}
+				fmt.Printf("%-8s %s %-9s %-16s %s\n", r.Env, ui.Colorize(color, fmt.Sprintf("%-8s", status)),
+					r.Latency.Round(time.Millisecond), version, r.URL)
+				if r.Err != nil {
+					fmt.Printf("         %s\n", ui.Colorize(ui.Gray, r.Err.Error()))
+				}
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d endpoint(s) unhealthy", failed, len(results))
+			}
+			return nil
+		},
+	}
+}
+
+// projectEndpoints returns the health endpoints of the named project, or of the
+// current repository looked up by its GitLab path and then its folder name.
+func projectEndpoints(ctx context.Context, cfg *config.Config, name string) (string, map[string]string, error) {
+	if name != "" {
+		endpoints, ok := cfg.Health[name]
+		if !ok || len(endpoints) == 0 {
+			return "", nil, fmt.Errorf("no health endpoints configured for %s (available: %s)", name, strings.Join(healthProjects(cfg), ", "))
+		}
+		return name, endpoints, nil
+	}
+
+	var candidates []string
+	if projectID, err := git.ExtractProjectID(ctx); err == nil {
+		candidates = append(candidates, projectID)
+	}
+	if root, err := git.GetTopLevel(ctx); err == nil {
+		candidates = append(candidates, filepath.Base(root))
+	}
+	if len(candidates) == 0 {
+		return "", nil, fmt.Errorf("not a git repository, pass the project name (available: %s)", strings.Join(healthProjects(cfg), ", "))
+	}
+	for _, candidate := range candidates {
+		if endpoints, ok := cfg.Health[candidate]; ok && len(endpoints) > 0 {
+			return candidate, endpoints, nil
+		}
+	}
+	return "", nil, fmt.Errorf("no health endpoints configured for %s, add them under \"health\" in config.json", candidates[0])
+}
+
+func healthProjects(cfg *config.Config) []string {
+	names := make([]string, 0, len(cfg.Health))
+	for name := range cfg.Health {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,175 @@
+package prj
+
+import (
+	"cli-aio/internal/pkg/project"
+	"cli-aio/internal/prompt"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// actCmd runs the shell commands bookmarked on a project.
+func actCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "act",
+		Usage:     "Run a command bookmarked on a project (the current one, or pick one)",
+		ArgsUsage: "[action]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "project",
+				Aliases: []string{"p"},
+				Usage:   "Project to use (name or path query) instead of the current one",
+			},
+			&cli.StringFlag{
+				Name:  "add",
+				Usage: "Bookmark a command under this name: prj act --add test go test ./...",
+			},
+			&cli.StringFlag{
+				Name:    "remove",
+				Aliases: []string{"r"},
+				Usage:   "Remove the named action",
+			},
+			&cli.BoolFlag{
+				Name:    "list",
+				Aliases: []string{"l"},
+				Usage:   "List the actions instead of running one",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			store, err := project.Load()
+			if err != nil {
+				return err
+			}
+			i, err := actProject(c.Context, store, c.String("project"))
+			if err != nil {
+				return err
+			}
+			p := &store.Projects[i]
+
+			switch {
+			case c.String("add") != "":
+				command := strings.Join(c.Args().Slice(), " ")
+				if command == "" {
+					if command, err = prompt.Input("Command:", "", true); err != nil {
+						return fmt.Errorf("input cancelled: %w", err)
+					}
+				}
+				if p.Actions == nil {
+					p.Actions = map[string]string{}
+				}
+				p.Actions[c.String("add")] = command
+				if err := project.Save(store); err != nil {
+					return err
+				}
+				fmt.Printf("[+] Added action '%s' to %s\n", c.String("add"), p.Name)
+				return nil
+			case c.String("remove") != "":
+				name := c.String("remove")
+				if _, ok := p.Actions[name]; !ok {
+					return fmt.Errorf("%s has no action '%s'", p.Name, name)
+				}
+				delete(p.Actions, name)
+				if err := project.Save(store); err != nil {
+					return err
+				}
+				fmt.Printf("[+] Removed action '%s' from %s\n", name, p.Name)
+				return nil
+			}
+
+			names := actionNames(p.Actions)
+			if len(names) == 0 {
+				return fmt.Errorf("%s has no actions, add one with 'aio prj act --add <name> <command>'", p.Name)
+			}
+			if c.Bool("list") {
+				for _, name := range names {
+					fmt.Printf("  %-15s %s\n", name, p.Actions[name])
+				}
+				return nil
+			}
+
+			name := c.Args().First()
+			if name == "" {
+				labels := make([]string, len(names))
+				for j, n := range names {
+					labels[j] = fmt.Sprintf("%-15s %s", n, p.Actions[n])
+				}
+				idx, _, err := prompt.Select(fmt.Sprintf("Run in %s:", p.Name), labels, "")
+				if err != nil {
+					return fmt.Errorf("selection cancelled: %w", err)
+				}
+				name = names[idx]
+			}
+			command, ok := p.Actions[name]
+			if !ok {
+				return fmt.Errorf("%s has no action '%s' (available: %s)", p.Name, name, strings.Join(names, ", "))
+			}
+			return runAction(p.Path, command, c.Args().Tail())
+		},
+	}
+}
+
+// actProject returns the project from --project, the one containing the
+// current directory, or lets the user pick one.
+func actProject(ctx context.Context, store *project.Store, query string) (int, error) {
+	if query != "" {
+		matches, err := project.Search(store.Projects, query)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[!] Warning: %v\n", err)
+			matches = project.Match(store.Projects, query)
+		}
+		if len(matches) == 0 {
+			return -1, fmt.Errorf("no project matches '%s'", query)
+		}
+		return project.Find(store, matches[0].Path), nil
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		if i := project.Containing(store, cwd); i >= 0 {
+			return i, nil
+		}
+	}
+	if len(store.Projects) == 0 {
+		return -1, fmt.Errorf("no projects saved, use 'prj add' or 'prj git-add' to add projects")
+	}
+
+	projects := append([]project.Project(nil), store.Projects...)
+	if err := project.SortByFrecency(projects); err != nil {
+		fmt.Fprintf(os.Stderr, "[!] Warning: %v\n", err)
+	}
+	labels, pathByLabel := projectLabels(projects)
+	_, selected, err := prompt.Select("Select a project:", labels, "", prompt.WithPreview(projectPreview(ctx, pathByLabel)))
+	if err != nil {
+		return -1, fmt.Errorf("selection cancelled: %w", err)
+	}
+	return project.Find(store, pathByLabel[selected]), nil
+}
+
+func actionNames(actions map[string]string) []string {
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// runAction runs command through the shell in dir; extra args are appended.
+func runAction(dir string, command string, args []string) error {
+	if len(args) > 0 {
+		command += " " + strings.Join(args, " ")
+	}
+	fmt.Fprintf(os.Stderr, "-> %s (in %s)\n", command, dir)
+	run := exec.Command("sh", "-c", command)
+	run.Dir = dir
+	run.Stdin = os.Stdin
+	run.Stdout = os.Stdout
+	run.Stderr = os.Stderr
+	if err := run.Run(); err != nil {
+		return fmt.Errorf("action failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package prj
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/notify"
+	"cli-aio/internal/pkg/project"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// defaultBackupKeep is how many bundles are kept per project without backup_keep.
+const defaultBackupKeep = 5
+
+// backupCmd bundles (or mirrors) saved projects for a local backup.
+func backupCmd() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:  "backup",
+		Usage: "Back up saved projects as git bundles (with rotation) or mirror them to a remote",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "all",
+				Aliases: []string{"a"},
+				Usage:   "Back up every saved project instead of selecting",
+			},
+			&cli.StringSliceFlag{
+				Name:    "tag",
+				Aliases: []string{"t"},
+				Usage:   "Back up the projects with this tag (repeatable, all must match)",
+			},
+			&cli.StringFlag{
+				Name:  "dir",
+				Usage: "Backup directory (default: projects.backup_dir or ~/.local/share/cli-aio/backups)",
+			},
+			&cli.IntFlag{
+				Name:  "keep",
+				Usage: "Bundles to keep per project (default: projects.backup_keep or 5)",
+			},
+			&cli.StringFlag{
+				Name:  "remote",
+				Usage: "Push a mirror to this remote of each project instead of writing bundles",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			store, err := project.Load()
+			if err != nil {
+				return err
+			}
+			projects, err := backupProjects(c, store.Projects)
+			if err != nil {
+				return err
+			}
+			if len(projects) == 0 {
+				fmt.Println("[!] No projects to back up")
+				return nil
+			}
+			if remote := c.String("remote"); remote != "" {
+				return mirrorProjects(c, projects, remote)
+			}
+
+			dir, keep, err := backupSettings(c)
+			if err != nil {
+				return err
+			}
+			done, unchanged, failed := 0, 0, 0
+			for i, p := range projects {
+				if cmd.Stopping(c.Context) {
+					fmt.Printf("[!] Interrupted: %d of %d project(s) not backed up\n", len(projects)-i, len(projects))
+					break
+				}
+				file, err := backupProject(c.Context, p, filepath.Join(dir, backupName(p.Path)), keep)
+				switch {
+				case err != nil:
+					failed++
+					fmt.Printf("[-] %-20s %v\n", p.Name, err)
+				case file == "":
+					unchanged++
+					fmt.Printf("    %-20s unchanged\n", p.Name)
+				default:
+					done++
+					size := int64(0)
+					if info, err := os.Stat(file); err == nil {
+						size = info.Size()
+					}
+					fmt.Printf("[+] %-20s %s\n", p.Name, ui.Size(size))
+				}
+			}
+
+			summary := fmt.Sprintf("Backed up %d, unchanged %d, failed %d in %s", done, unchanged, failed, dir)
+			fmt.Printf("\n%s\n", summary)
+			notify.Announce(notify.EventBatch, notify.Message{Title: "prj backup finished", Text: summary, OK: failed == 0})
+			if failed > 0 {
+				return fmt.Errorf("%d project(s) could not be backed up", failed)
+			}
+			return nil
+		},
+	}, `Each project is written as a git bundle of all its refs to <dir>/<path>/<date>-<time>.bundle and
+only the newest --keep bundles are kept. A project whose refs didn't change since its last bundle is
+skipped. Restore with 'git clone <file> <dir>'. Only committed work is backed up: check 'prj dirty'
+first. With --remote, each project instead pushes a mirror of its refs to that remote (e.g. a
+secondary server added with 'git remote add backup <url>'); projects without it are skipped.`,
+		cmd.Example{Command: "aio prj backup --all", Comment: "Bundle every saved project"},
+		cmd.Example{Command: "aio prj backup -t work --keep 10 --dir /mnt/nas/backups", Comment: "Tagged projects to a NAS"},
+		cmd.Example{Command: "aio prj backup --all --remote backup", Comment: "Mirror to each project's 'backup' remote"},
+	)
+}
+
+// backupProjects returns the projects selected by --all, --tag or interactively.
+func backupProjects(c *cli.Context, projects []project.Project) ([]project.Project, error) {
+	if c.Bool("all") {
+		return projects, nil
+	}
+	if tags := c.StringSlice("tag"); len(tags) > 0 {
+		return project.FilterByTags(projects, tags), nil
+	}
+	if len(projects) == 0 {
+		return nil, nil
+	}
+	labels, pathByLabel := projectLabels(projects)
+	selected, err := prompt.MultiSelect("Projects to back up:", labels, nil, prompt.WithMin(1))
+	if err != nil {
+		return nil, fmt.Errorf("selection cancelled: %w", err)
+	}
+	var chosen []project.Project
+	for _, label := range selected {
+		for _, p := range projects {
+			if p.Path == pathByLabel[label] {
+				chosen = append(chosen, p)
+			}
+		}
+	}
+	return chosen, nil
+}
+
+// backupSettings returns the backup directory and rotation from the flags,
+// then the config, then the defaults.
+func backupSettings(c *cli.Context) (string, int, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", 0, err
+	}
+	dir := c.String("dir")
+	if dir == "" {
+		dir = cfg.Projects.BackupDir
+	}
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", 0, fmt.Errorf("cannot determine home directory: %w", err)
+		}
+		dir = filepath.Join(home, ".local", "share", "cli-aio", "backups")
+	}
+	if dir, err = expandPath(dir); err != nil {
+		return "", 0, err
+	}
+	keep := c.Int("keep")
+	if keep == 0 {
+		keep = cfg.Projects.BackupKeep
+	}
+	if keep <= 0 {
+		keep = defaultBackupKeep
+	}
+	return dir, keep, nil
+}
+
+// backupName is the folder of a project in the backup directory: its path
+// relative to home, so projects with the same name don't collide.
+func backupName(path string) string {
+	if home, err := os.UserHomeDir(); err == nil {
+		if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	path = strings.Trim(filepath.ToSlash(path), "/")
+	return strings.NewReplacer("/", "_", ":", "").Replace(path)
+}
+
+// backupProject writes a bundle of the repository at path into dir and keeps
+// the newest keep bundles. Returns the new file, or "" when the refs didn't
+// change since the last bundle.
+func backupProject(ctx context.Context, p project.Project, dir string, keep int) (string, error) {
+	refs, err := git.RefsIn(ctx, p.Path)
+	if err != nil {
+		return "", err
+	}
+	if len(refs) == 0 {
+		return "", fmt.Errorf("no commits to back up")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	bundles, err := listBundles(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(bundles) > 0 {
+		if last, err := git.BundleRefs(ctx, bundles[len(bundles)-1]); err == nil && slices.Equal(last, refs) {
+			return "", nil
+		}
+	}
+
+	file := filepath.Join(dir, time.Now().Format("20060102-150405")+".bundle")
+	// Write next to the final name so an interrupted run leaves no half bundle behind
+	tmp := file + ".tmp"
+	if err := git.BundleAllIn(ctx, p.Path, tmp); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to write %s: %w", file, err)
+	}
+
+	bundles = append(bundles, file)
+	for _, old := range bundles[:max(0, len(bundles)-keep)] {
+		if err := os.Remove(old); err != nil {
+			fmt.Printf("[!] Warning: Failed to remove old backup %s: %v\n", old, err)
+		}
+	}
+	return file, nil
+}
+
+// listBundles returns the bundles in dir, oldest first.
+func listBundles(dir string) ([]string, error) {
+	bundles, err := filepath.Glob(filepath.Join(dir, "*.bundle"))
+	if err != nil {
+		return nil, err
+	}
+	// Names are timestamps, so they sort chronologically
+	sort.Strings(bundles)
+	return bundles, nil
+}
+
+// mirrorProjects pushes a mirror of each project to its remote.
+func mirrorProjects(c *cli.Context, projects []project.Project, remote string) error {
+	done, skipped, failed := 0, 0, 0
+	for i, p := range projects {
+		if cmd.Stopping(c.Context) {
+			fmt.Printf("[!] Interrupted: %d of %d project(s) not mirrored\n", len(projects)-i, len(projects))
+			break
+		}
+		remotes, err := git.RemotesIn(c.Context, p.Path)
+		if err != nil {
+			failed++
+			fmt.Printf("[-] %-20s %v\n", p.Name, err)
+			continue
+		}
+		if !slices.Contains(remotes, remote) {
+			skipped++
+			fmt.Printf("[!] %-20s no remote '%s'\n", p.Name, remote)
+			continue
+		}
+		if err := git.PushMirrorIn(c.Context, p.Path, remote); err != nil {
+			failed++
+			fmt.Printf("[-] %-20s %v\n", p.Name, err)
+			continue
+		}
+		done++
+		fmt.Printf("[+] %-20s mirrored to %s\n", p.Name, remote)
+	}
+
+	summary := fmt.Sprintf("Mirrored %d, skipped %d, failed %d", done, skipped, failed)
+	fmt.Printf("\n%s\n", summary)
+	notify.Announce(notify.EventBatch, notify.Message{Title: "prj backup finished", Text: summary, OK: failed == 0})
+	if failed > 0 {
+		return fmt.Errorf("%d project(s) could not be mirrored", failed)
+	}
+	return nil
+}
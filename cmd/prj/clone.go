@@ -0,0 +1,129 @@
+package prj
+
+import (
+	"cli-aio/cmd/newproj"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/identity"
+	"cli-aio/internal/pkg/project"
+	"cli-aio/internal/pkg/scaffold"
+	"cli-aio/internal/prompt"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/urfave/cli/v2"
+)
+
+// cloneCmd clones a repository, adds it to the project list and optionally
+// applies an org template set to it.
+func cloneCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "clone",
+		Usage:     "Clone a repository, add it as a project and apply an org template",
+		ArgsUsage: "<url> [path]",
+		Flags: []cli.Flag{
+			newproj.OrgTemplateFlag,
+		},
+		Action: func(c *cli.Context) error {
+			repoURL := c.Args().Get(0)
+			if repoURL == "" {
+				var err error
+				repoURL, err = prompt.Input("Repository URL:", "", true)
+				if err != nil {
+					return fmt.Errorf("input cancelled: %w", err)
+				}
+			}
+
+			remote, err := git.ParseRemoteURL(repoURL)
+			if err != nil {
+				return err
+			}
+			name := path.Base(remote.Path)
+
+			dir := c.Args().Get(1)
+			if dir == "" {
+				dir = name
+			}
+			expanded, err := expandPath(dir)
+			if err != nil {
+				return err
+			}
+			absPath, err := filepath.Abs(expanded)
+			if err != nil {
+				return fmt.Errorf("invalid path: %w", err)
+			}
+			if _, err := os.Stat(absPath); err == nil {
+				return fmt.Errorf("path already exists: %s", absPath)
+			}
+
+			lfsMissing := !git.LFSInstalled(c.Context)
+			fmt.Printf("Cloning %s...\n", repoURL)
+			if err := git.Clone(c.Context, repoURL, absPath); err != nil {
+				if git.KindOf(err) == git.ErrCancelled {
+					// git usually removes a partial clone itself when interrupted
+					os.RemoveAll(absPath)
+					return fmt.Errorf("clone interrupted, no project added")
+				}
+				return err
+			}
+			fmt.Printf("[+] Cloned into %s\n", absPath)
+			if lfsMissing && git.UsesLFSIn(c.Context, absPath) {
+				fmt.Println("[!] This repository uses Git LFS but git-lfs is not installed: large files are checked out as pointers.")
+				fmt.Printf("    Install git-lfs, then run 'git lfs install && git lfs pull' in %s\n", absPath)
+			}
+			applyIdentityRule(c.Context, absPath)
+
+			vars := scaffold.Vars{ProjectName: name, ModulePath: remote.FullName()}
+			hooks, err := newproj.ApplyOrgTemplate(c, absPath, vars)
+			if err != nil {
+				fmt.Printf("[!] Warning: Failed to apply org template: %v\n", err)
+			}
+			if hooks {
+				if err := scaffold.EnableHooks(c.Context, absPath); err != nil {
+					fmt.Printf("[!] Warning: %v\n", err)
+				}
+			}
+
+			store, err := project.Load()
+			if err != nil {
+				return err
+			}
+			p := project.Project{Name: filepath.Base(absPath), Path: absPath}
+			if project.Add(store, p) {
+				if err := project.Save(store); err != nil {
+					return err
+				}
+				fmt.Printf("[+] Added project: %s (%s)\n", p.Name, p.Path)
+			}
+
+			if status, _ := git.ShortStatusIn(c.Context, absPath); status != "" {
+				fmt.Printf("[!] Org template files are not committed yet:\n%s", status)
+			}
+			return nil
+		},
+	}
+}
+
+// applyIdentityRule switches a new clone to the git identity its directory rule selects.
+func applyIdentityRule(ctx context.Context, dir string) {
+	store, err := identity.Load()
+	if err != nil {
+		fmt.Printf("[!] Warning: %v\n", err)
+		return
+	}
+	rule := store.Match(dir)
+	if rule == nil {
+		return
+	}
+	p, ok := store.Find(rule.Profile)
+	if !ok {
+		return
+	}
+	if err := identity.Apply(ctx, dir, *p); err != nil {
+		fmt.Printf("[!] Warning: Failed to set identity %s: %v\n", p.Name, err)
+		return
+	}
+	fmt.Printf("[+] Using identity %s (rule %s)\n", p, rule.Pattern)
+}
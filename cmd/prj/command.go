@@ -2,11 +2,13 @@ package prj
 
 import (
 	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/editor"
 	"cli-aio/internal/pkg/project"
 	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -32,6 +34,15 @@ func Command() *cli.Command {
 		addCmd(),
 		gitAddCmd(),
 		gitRefreshCmd(),
+		cloneCmd(),
+		tagCmd(),
+		actCmd(),
+		filesCmd(),
+		backupCmd(),
+		gcCmd(),
+		expireCmd(),
+		dirtyCmd(),
+		doctorCmd(),
 		editConfigCmd(),
 		installCmd(),
 	}
@@ -60,8 +71,35 @@ func Command() *cli.Command {
 //	prj() { local p; p=$(cli-aio prj cd) && cd "$p"; }
 func cdCmd() *cli.Command {
 	return &cli.Command{
-		Name:  "cd",
-		Usage: "List projects and print the selected project's path (use with shell wrapper to cd)",
+		Name:      "cd",
+		Usage:     "List projects and print the selected project's path (use with shell wrapper to cd)",
+		ArgsUsage: "[query | -]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "multi",
+				Aliases: []string{"m"},
+				Usage:   "Select several projects and print their paths, one per line",
+			},
+			&cli.StringSliceFlag{
+				Name:    "tag",
+				Aliases: []string{"t"},
+				Usage:   "Only list projects with this tag (repeatable, all must match)",
+			},
+			&cli.StringFlag{
+				Name:  "picker",
+				Usage: "Selector to use: auto (fzf when installed), fzf or builtin (default: config or auto)",
+			},
+			&cli.StringFlag{
+				Name:    "org",
+				Aliases: []string{"o"},
+				Usage:   "Only list projects of this GitLab group / GitHub organization (subgroups included)",
+			},
+			&cli.BoolFlag{
+				Name:    "group",
+				Aliases: []string{"g"},
+				Usage:   "Group the list by organization (default: projects.group_by_org)",
+			},
+		},
 		Action: func(c *cli.Context) error {
 			if term.IsTerminal(int(os.Stdout.Fd())) {
 				fmt.Fprintln(os.Stderr, "[!] 'aio prj cd' is meant to be called via the 'prj' shell wrapper, not directly.")
@@ -77,33 +115,81 @@ func cdCmd() *cli.Command {
 				fmt.Fprintln(os.Stderr, "[!] No projects saved. Use 'prj add' or 'prj git-add' to add projects.")
 				return nil
 			}
+			query := strings.Join(c.Args().Slice(), " ")
+			if query == "-" {
+				return cdPrevious()
+			}
 
-			home, _ := os.UserHomeDir()
+			projects := project.FilterByTags(store.Projects, c.StringSlice("tag"))
+			if len(projects) == 0 {
+				return fmt.Errorf("no projects tagged %s", strings.Join(c.StringSlice("tag"), ", "))
+			}
+
+			if org := c.String("org"); org != "" {
+				if projects = project.FilterByOrg(projects, org); len(projects) == 0 {
+					return fmt.Errorf("no project in %s (run 'aio prj git-refresh' to record the remotes of older projects)", org)
+				}
+			}
+
+			// Best matches first, the most used first among them; ranking is
+			// best effort
+			if ranked, err := project.Search(projects, query); err != nil {
+				fmt.Fprintf(os.Stderr, "[!] Warning: %v\n", err)
+				projects = project.Match(projects, query)
+			} else {
+				projects = ranked
+			}
 
-			// Find max name length for alignment
-			maxName := 0
-			for _, p := range store.Projects {
-				if len(p.Name) > maxName {
-					maxName = len(p.Name)
+			// A query jumps straight to the best match, like 'z <query>';
+			// with --multi it narrows the list instead
+			if query != "" {
+				if len(projects) == 0 {
+					return fmt.Errorf("no project matches '%s'", query)
 				}
+				if !c.Bool("multi") {
+					return printProjectPath(projects[0].Path)
+				}
+			}
+			grouped := groupByOrg(c)
+			if grouped {
+				project.GroupByOrg(projects)
 			}
 
-			// Build pretty labels: "name (padded)  ~/short/path"
-			labels := make([]string, len(store.Projects))
-			pathByLabel := make(map[string]string, len(store.Projects))
-			for i, p := range store.Projects {
-				shortPath := p.Path
-				if home != "" && strings.HasPrefix(p.Path, home) {
-					shortPath = "~" + p.Path[len(home):]
+			picker, err := resolvePicker(c.String("picker"))
+			if err != nil {
+				return err
+			}
+			labels, pathByLabel := projectLabels(projects)
+			if grouped {
+				labels, pathByLabel = orgLabels(projects)
+			}
+
+			if picker == pickerFzf {
+				paths, err := fzfSelect(labels, pathByLabel, c.Bool("multi"))
+				if err != nil {
+					return err
 				}
-				label := fmt.Sprintf("%-*s  %s", maxName, p.Name, shortPath)
-				labels[i] = label
-				pathByLabel[label] = p.Path
+				if c.Bool("multi") {
+					fmt.Println(strings.Join(paths, "\n"))
+					return nil
+				}
+				return printProjectPath(paths[0])
 			}
 
-			// SelectOnTTY renders on /dev/tty directly so ANSI escape codes
+			// The *OnTTY prompts render on /dev/tty directly so ANSI escape codes
 			// don't leak into the $(...) capture in the shell wrapper.
-			_, selected, err := prompt.SelectOnTTY("Select a project:", labels, "")
+			if c.Bool("multi") {
+				selected, err := prompt.MultiSelectOnTTY("Select projects:", labels, prompt.WithMin(1))
+				if err != nil {
+					return fmt.Errorf("selection cancelled: %w", err)
+				}
+				for _, label := range selected {
+					fmt.Println(pathByLabel[label])
+				}
+				return nil
+			}
+
+			_, selected, err := prompt.SelectOnTTY("Select a project:", labels, "", prompt.WithPreview(projectPreview(c.Context, pathByLabel)))
 			if err != nil {
 				return fmt.Errorf("selection cancelled: %w", err)
 			}
@@ -112,13 +198,114 @@ func cdCmd() *cli.Command {
 			if !ok {
 				return fmt.Errorf("selected project not found")
 			}
-			// Print path to stdout so the shell wrapper can cd to it
-			fmt.Print(targetPath)
-			return nil
+			return printProjectPath(targetPath)
 		},
 	}
 }
 
+// printProjectPath records the visit and prints the path to stdout so the shell wrapper can cd to it.
+func printProjectPath(path string) error {
+	if err := project.RecordVisit(path); err != nil {
+		fmt.Fprintf(os.Stderr, "[!] Warning: %v\n", err)
+	}
+	fmt.Print(path)
+	return nil
+}
+
+// cdPrevious prints the project opened before the current one, like 'cd -'.
+func cdPrevious() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("cannot determine current directory: %w", err)
+	}
+	previous, err := project.Previous(cwd)
+	if err != nil {
+		return err
+	}
+	if previous == "" {
+		return fmt.Errorf("no previous project")
+	}
+	if _, err := os.Stat(previous); err != nil {
+		return fmt.Errorf("previous project no longer exists: %s", previous)
+	}
+	return printProjectPath(previous)
+}
+
+// projectLabels builds aligned "name  ~/short/path  [tags]" labels and maps them back to paths.
+// Long names, paths and tags are shortened so the labels fit on one line of the terminal.
+func projectLabels(projects []project.Project) ([]string, map[string]string) {
+	rows := make([][]string, len(projects))
+	for i, p := range projects {
+		rows[i] = projectRow(p)
+	}
+	return fitLabels(projects, rows, []ui.Column{
+		{Shrink: ui.Truncate, Min: 16},
+		{Shrink: ui.TruncatePath, Min: 20},
+		{Shrink: ui.Truncate},
+	})
+}
+
+// projectRow returns the cells of the label of p: name, short path and tags.
+func projectRow(p project.Project) []string {
+	home, _ := os.UserHomeDir()
+	shortPath := p.Path
+	if home != "" && strings.HasPrefix(p.Path, home) {
+		shortPath = "~" + p.Path[len(home):]
+	}
+	row := []string{p.Name, shortPath}
+	if len(p.Tags) > 0 {
+		row = append(row, "["+strings.Join(p.Tags, ", ")+"]")
+	}
+	return row
+}
+
+// fitLabels lays rows out as labels fitting the terminal, minus the cursor
+// column of the selector. A label that shortening made ambiguous is kept whole.
+func fitLabels(projects []project.Project, rows [][]string, columns []ui.Column) ([]string, map[string]string) {
+	width := ui.Width()
+	if width > 0 {
+		width -= 2
+	}
+	labels := ui.Table(rows, columns, width)
+	full := ui.Table(rows, nil, 0)
+	pathByLabel := make(map[string]string, len(projects))
+	for i, p := range projects {
+		if _, taken := pathByLabel[labels[i]]; taken {
+			labels[i] = full[i]
+		}
+		pathByLabel[labels[i]] = p.Path
+	}
+	return labels, pathByLabel
+}
+
+// groupByOrg reports whether 'prj cd' groups projects by organization: --group, or projects.group_by_org.
+func groupByOrg(c *cli.Context) bool {
+	if c.IsSet("group") {
+		return c.Bool("group")
+	}
+	cfg, err := config.Load()
+	return err == nil && cfg.Projects.GroupByOrg
+}
+
+// orgLabels prefixes the labels of projects with their organization, so the
+// sections made by project.GroupByOrg read as a column and can be filtered on.
+func orgLabels(projects []project.Project) ([]string, map[string]string) {
+	rows := make([][]string, len(projects))
+	for i, p := range projects {
+		org := p.Org()
+		if org == "" {
+			org = "(no remote)"
+		}
+		rows[i] = append([]string{org}, projectRow(p)...)
+	}
+	return fitLabels(projects, rows, []ui.Column{
+		{Shrink: ui.TruncateMiddle, Min: 12},
+		{Shrink: ui.Truncate, Min: 16},
+		{Shrink: ui.TruncatePath, Min: 20},
+		{Shrink: ui.Truncate},
+	})
+}
+
 // addCmd adds a single folder path to the project list.
 func addCmd() *cli.Command {
 	return &cli.Command{
@@ -164,8 +351,9 @@ func addCmd() *cli.Command {
 			}
 
 			p := project.Project{
-				Name: filepath.Base(absPath),
-				Path: absPath,
+				Name:   filepath.Base(absPath),
+				Path:   absPath,
+				Remote: project.DetectRemote(c.Context, absPath),
 			}
 
 			added := project.Add(store, p)
@@ -223,7 +411,7 @@ func gitAddCmd() *cli.Command {
 			}
 
 			fmt.Printf("Scanning %s for git repositories...\n", absPath)
-			repos, err := project.FindGitRepos(absPath)
+			repos, err := project.FindGitRepos(c.Context, absPath)
 			if err != nil {
 				return err
 			}
@@ -240,16 +428,13 @@ func gitAddCmd() *cli.Command {
 
 			addedProjects := 0
 			skippedProjects := 0
-			for _, repoPath := range repos {
-				p := project.Project{
-					Name: filepath.Base(repoPath),
-					Path: repoPath,
-				}
+			for _, p := range scannedProjects(c, repos) {
 				if wasAdded := project.Add(store, p); wasAdded {
 					addedProjects++
-					fmt.Printf("  [+] %s (%s)\n", p.Name, p.Path)
+					fmt.Printf("  [+] %s (%s)%s\n", p.Name, p.Path, remoteSuffix(p.Remote))
 				} else {
 					skippedProjects++
+					project.UpdateRemote(store, p.Path, p.Remote)
 					fmt.Printf("  [-] already exists: %s\n", p.Path)
 				}
 			}
@@ -264,11 +449,30 @@ func gitAddCmd() *cli.Command {
 	}
 }
 
+// scannedProjects returns the projects of the repositories found by a scan,
+// with their remote.
+func scannedProjects(c *cli.Context, repos []string) []project.Project {
+	projects := make([]project.Project, len(repos))
+	for i, repoPath := range repos {
+		projects[i] = project.Project{Name: filepath.Base(repoPath), Path: repoPath}
+	}
+	project.DetectRemotes(c.Context, projects)
+	return projects
+}
+
+// remoteSuffix shows where a project is hosted in scan results.
+func remoteSuffix(remote *project.Remote) string {
+	if remote == nil || remote.ProjectPath == "" {
+		return ""
+	}
+	return " -> " + remote.Host + "/" + remote.ProjectPath
+}
+
 // gitRefreshCmd re-scans all saved git roots for new repositories.
 func gitRefreshCmd() *cli.Command {
 	return &cli.Command{
 		Name:  "git-refresh",
-		Usage: "Re-scan all saved git roots for new repositories",
+		Usage: "Re-scan all saved git roots for new repositories and refresh the remotes of known ones",
 		Action: func(c *cli.Context) error {
 			store, err := project.Load()
 			if err != nil {
@@ -282,36 +486,40 @@ func gitRefreshCmd() *cli.Command {
 
 			totalAdded := 0
 			totalSkipped := 0
+			updated := 0
 
-			for _, root := range store.GitRoots {
+			for i, root := range store.GitRoots {
+				if cmd.Stopping(c.Context) {
+					fmt.Printf("[!] Interrupted: %d of %d root(s) not refreshed\n", len(store.GitRoots)-i, len(store.GitRoots))
+					break
+				}
 				fmt.Printf("Refreshing root: %s\n", root)
-				repos, err := project.FindGitRepos(root)
+				repos, err := project.FindGitRepos(c.Context, root)
 				if err != nil {
 					fmt.Printf("  [!] Error scanning %s: %v\n", root, err)
 					continue
 				}
 
-				for _, repoPath := range repos {
-					p := project.Project{
-						Name: filepath.Base(repoPath),
-						Path: repoPath,
-					}
+				for _, p := range scannedProjects(c, repos) {
 					if wasAdded := project.Add(store, p); wasAdded {
 						totalAdded++
-						fmt.Printf("  [+] %s (%s)\n", p.Name, p.Path)
+						fmt.Printf("  [+] %s (%s)%s\n", p.Name, p.Path, remoteSuffix(p.Remote))
 					} else {
 						totalSkipped++
+						if project.UpdateRemote(store, p.Path, p.Remote) {
+							updated++
+						}
 					}
 				}
 			}
 
-			if totalAdded > 0 {
+			if totalAdded > 0 || updated > 0 {
 				if err := project.Save(store); err != nil {
 					return err
 				}
 			}
 
-			fmt.Printf("\nDone. Total added: %d, Total already exist: %d\n", totalAdded, totalSkipped)
+			fmt.Printf("\nDone. Total added: %d, Total already exist: %d (remote updated: %d)\n", totalAdded, totalSkipped, updated)
 			return nil
 		},
 	}
@@ -323,6 +531,9 @@ func editConfigCmd() *cli.Command {
 		Name:  "config",
 		Usage: "Open the projects config file in $EDITOR (fallback: nvim)",
 		Action: func(c *cli.Context) error {
+			if backend := project.BackendName(); backend != project.DefaultBackend {
+				return fmt.Errorf("projects are stored in the %s backend, 'prj config' only edits projects.json", backend)
+			}
 			configPath, err := project.ConfigPath()
 			if err != nil {
 				return err
@@ -335,28 +546,7 @@ func editConfigCmd() *cli.Command {
 				}
 			}
 
-			editor := os.Getenv("EDITOR")
-			if editor == "" {
-				// Try common editors in order of preference
-				for _, candidate := range []string{"nvim", "vim", "nano", "vi", "notepad"} {
-					if _, err := exec.LookPath(candidate); err == nil {
-						editor = candidate
-						break
-					}
-				}
-			}
-			if editor == "" {
-				return fmt.Errorf("no editor found; set the $EDITOR environment variable")
-			}
-
-			cmdExec := exec.Command(editor, configPath)
-			cmdExec.Stdin = os.Stdin
-			cmdExec.Stdout = os.Stdout
-			cmdExec.Stderr = os.Stderr
-			if err := cmdExec.Run(); err != nil {
-				return fmt.Errorf("editor exited with error: %w", err)
-			}
-			return nil
+			return editor.Open(configPath, 0)
 		},
 	}
 }
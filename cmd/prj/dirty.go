@@ -0,0 +1,122 @@
+package prj
+
+import (
+	"cli-aio/internal/pkg/daemon"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/project"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// dirtyCmd lists the saved projects with uncommitted changes or unpushed commits.
+func dirtyCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "dirty",
+		Usage: "List saved projects with uncommitted changes or unpushed commits",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "tag",
+				Aliases: []string{"t"},
+				Usage:   "Only check projects with this tag (repeatable, all must match)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			store, err := project.Load()
+			if err != nil {
+				return err
+			}
+			projects := project.FilterByTags(store.Projects, c.StringSlice("tag"))
+			if len(projects) == 0 {
+				fmt.Println("[!] No projects to check")
+				return nil
+			}
+
+			states, scannedAt := scanProjects(c.Context, projects)
+			if age := time.Since(scannedAt); age > time.Second {
+				fmt.Printf("Daemon scan from %s ago\n", age.Round(time.Second))
+			}
+
+			home, _ := os.UserHomeDir()
+			dirty, skipped := 0, 0
+			for i, state := range states {
+				if state.Error == project.ErrNotScanned {
+					skipped++
+					continue
+				}
+				name := projects[i].Name
+				path := state.Path
+				if home != "" && strings.HasPrefix(path, home) {
+					path = "~" + path[len(home):]
+				}
+				if state.Error != "" {
+					fmt.Printf("[-] %-20s %s: %s\n", name, path, state.Error)
+					continue
+				}
+				if !state.Status.Dirty() {
+					continue
+				}
+				dirty++
+				fmt.Printf("[!] %-20s %s  %s\n", name, path, describeStatus(state.Status))
+			}
+			if skipped > 0 {
+				return fmt.Errorf("interrupted, %d of %d project(s) scanned", len(projects)-skipped, len(projects))
+			}
+			if dirty == 0 {
+				fmt.Println("[+] All projects are clean and pushed")
+				return nil
+			}
+			fmt.Printf("\n%d of %d project(s) need attention\n", dirty, len(projects))
+			return nil
+		},
+	}
+}
+
+// scanProjects returns the git status of projects, from the daemon when it
+// runs and has scanned all of them, and when they were scanned.
+func scanProjects(ctx context.Context, projects []project.Project) ([]project.RepoState, time.Time) {
+	var cached []project.RepoState
+	if scannedAt, ok := daemon.Query(daemon.KindProjects, nil, &cached); ok {
+		byPath := make(map[string]project.RepoState, len(cached))
+		for _, state := range cached {
+			byPath[state.Path] = state
+		}
+		states := make([]project.RepoState, 0, len(projects))
+		for _, p := range projects {
+			state, ok := byPath[p.Path]
+			if !ok {
+				break
+			}
+			states = append(states, state)
+		}
+		if len(states) == len(projects) {
+			return states, scannedAt
+		}
+	}
+	fmt.Printf("Checking %d project(s)...\n", len(projects))
+	return project.Scan(ctx, projects), time.Now()
+}
+
+// describeStatus formats a status as e.g. "main: 3 changed, 2 unpushed".
+func describeStatus(s git.RepoStatus) string {
+	var parts []string
+	if s.Changes > 0 {
+		parts = append(parts, fmt.Sprintf("%d changed", s.Changes))
+	}
+	if s.Unpushed > 0 {
+		unpushed := fmt.Sprintf("%d unpushed", s.Unpushed)
+		if !s.Upstream {
+			unpushed += " (no upstream)"
+		}
+		parts = append(parts, unpushed)
+	}
+	branch := s.Branch
+	if branch == "" {
+		branch = "detached HEAD"
+	}
+	return branch + ": " + strings.Join(parts, ", ")
+}
@@ -0,0 +1,105 @@
+package prj
+
+import (
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/pkg/offline"
+	"cli-aio/internal/pkg/project"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// doctorCmd checks the project store: it normalizes paths, merges entries
+// pointing at the same directory and reports projects that no longer exist.
+// It also checks the GitLab token the project commands use.
+func doctorCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "doctor",
+		Usage: "Check the project list (normalize paths, merge duplicates, report missing folders) and the GitLab token",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Only report, don't save changes",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			store, err := project.Load()
+			if err != nil {
+				return err
+			}
+
+			normalized, removed := project.Dedupe(store)
+			for _, p := range removed {
+				fmt.Printf("[+] Merged duplicate: %s (%s)\n", p.Name, p.Path)
+			}
+			if normalized > 0 {
+				fmt.Printf("[+] Normalized %d path(s)\n", normalized)
+			}
+
+			missing := 0
+			for _, p := range store.Projects {
+				if _, err := os.Stat(p.Path); os.IsNotExist(err) {
+					fmt.Printf("[!] Missing folder: %s (%s)\n", p.Name, p.Path)
+					missing++
+				}
+			}
+
+			checkGitLabToken(c.Context)
+
+			if normalized == 0 && len(removed) == 0 {
+				if missing == 0 {
+					fmt.Println("[+] Project list looks good")
+				}
+				return nil
+			}
+			if c.Bool("dry-run") {
+				fmt.Println("[!] Dry run, nothing saved")
+				return nil
+			}
+			if err := project.Save(store); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Saved %d project(s)\n", len(store.Projects))
+			return nil
+		},
+	}
+}
+
+// checkGitLabToken reports the scopes and expiry of GITLAB_PRIVATE_TOKEN.
+func checkGitLabToken(ctx context.Context) {
+	if os.Getenv("GITLAB_PRIVATE_TOKEN") == "" || offline.Enabled() {
+		return
+	}
+	client, err := gitlab.NewClient(ctx)
+	if err != nil {
+		fmt.Printf("[!] GitLab token: %v\n", err)
+		return
+	}
+	info, err := client.TokenInfo()
+	if err != nil {
+		if errors.Is(err, gitlab.ErrTokenInvalid) {
+			fmt.Printf("[-] GitLab token: %v, create a new one at %s\n", err, client.TokenSettingsURL())
+		} else {
+			fmt.Printf("[!] GitLab token: cannot check it: %v\n", err)
+		}
+		return
+	}
+	problems := info.Problems(time.Now())
+	for _, problem := range problems {
+		fmt.Printf("[!] GitLab %s\n", problem)
+	}
+	if len(problems) > 0 {
+		fmt.Printf("    Manage tokens at %s\n", client.TokenSettingsURL())
+		return
+	}
+	expires := "never expires"
+	if info.ExpiresAt != "" {
+		expires = "expires " + info.ExpiresAt
+	}
+	fmt.Printf("[+] GitLab token %s: %s, %s\n", info.Name, strings.Join(info.Scopes, ", "), expires)
+}
@@ -0,0 +1,147 @@
+package prj
+
+import (
+	"cli-aio/internal/pkg/editor"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/project"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// recentFile is a file of the project worth reopening.
+type recentFile struct {
+	path    string    // relative to the project
+	commit  string    // latest commit touching it, empty for uncommitted changes
+	subject string    // subject of that commit
+	when    time.Time // modification or commit time
+}
+
+// filesCmd lists the recently changed files of a project to reopen one.
+func filesCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "files",
+		Usage: "Pick a recently modified or committed file of a project and open it in $EDITOR at the change",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "project",
+				Aliases: []string{"p"},
+				Usage:   "Project to use (name or path query) instead of the current one",
+			},
+			&cli.IntFlag{
+				Name:    "commits",
+				Aliases: []string{"n"},
+				Usage:   "Number of recent commits to take files from",
+				Value:   20,
+			},
+			&cli.BoolFlag{
+				Name:    "list",
+				Aliases: []string{"l"},
+				Usage:   "Print the files instead of opening one",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			store, err := project.Load()
+			if err != nil {
+				return err
+			}
+			i, err := actProject(c.Context, store, c.String("project"))
+			if err != nil {
+				return err
+			}
+			p := store.Projects[i]
+
+			files, err := recentFiles(c.Context, p.Path, c.Int("commits"))
+			if err != nil {
+				return err
+			}
+			if len(files) == 0 {
+				fmt.Printf("[!] No recent files in %s\n", p.Name)
+				return nil
+			}
+
+			labels := make([]string, len(files))
+			for j, f := range files {
+				labels[j] = fileLabel(f)
+			}
+			if c.Bool("list") || ui.Plain() {
+				for _, label := range labels {
+					fmt.Println(label)
+				}
+				return nil
+			}
+
+			idx, _, err := prompt.Select(fmt.Sprintf("Recent files of %s:", p.Name), labels, "")
+			if err != nil {
+				return fmt.Errorf("selection cancelled: %w", err)
+			}
+			f := files[idx]
+			return editor.Open(filepath.Join(p.Path, f.path), git.FirstChangedLineIn(c.Context, p.Path, f.commit, f.path))
+		},
+	}
+}
+
+// recentFiles returns the uncommitted files of the repository at dir, most
+// recently modified first, then the files of its last commits.
+func recentFiles(ctx context.Context, dir string, commits int) ([]recentFile, error) {
+	changed, err := git.ChangedFilesIn(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []recentFile
+	seen := map[string]bool{}
+	for _, path := range changed {
+		info, err := os.Stat(filepath.Join(dir, path))
+		if err != nil || info.IsDir() {
+			continue
+		}
+		seen[path] = true
+		files = append(files, recentFile{path: path, when: info.ModTime()})
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].when.After(files[j].when) })
+
+	committed, err := git.RecentlyCommittedFilesIn(ctx, dir, commits)
+	if err != nil {
+		return nil, err
+	}
+	for _, fc := range committed {
+		if seen[fc.Path] {
+			continue
+		}
+		// Skip files deleted since
+		if _, err := os.Stat(filepath.Join(dir, fc.Path)); err != nil {
+			continue
+		}
+		seen[fc.Path] = true
+		files = append(files, recentFile{path: fc.Path, commit: fc.SHA, subject: fc.Subject, when: fc.Time})
+	}
+	return files, nil
+}
+
+// fileLabel formats a file as e.g. "M path (2h ago)" or "  path (3d ago: Fix login)".
+func fileLabel(f recentFile) string {
+	if f.commit == "" {
+		return fmt.Sprintf("M %s (%s ago)", f.path, since(f.when))
+	}
+	return fmt.Sprintf("  %s (%s ago: %s)", f.path, since(f.when), f.subject)
+}
+
+// since formats the time elapsed since t coarsely: 5m, 3h, 2d.
+func since(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,121 @@
+package prj
+
+import (
+	"bytes"
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/prompt"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Selectors for 'prj cd --picker'.
+const (
+	pickerAuto    = "auto"
+	pickerFzf     = "fzf"
+	pickerBuiltin = "builtin"
+)
+
+// fzfPreview shows the branch, changes and the top of the README of the highlighted project.
+// fzf substitutes {1} (the path column) already quoted.
+const fzfPreview = `git -C {1} status -sb 2>/dev/null; echo; head -n 20 {1}/README.md 2>/dev/null`
+
+// projectPreview shows the same as fzfPreview in the built-in selector.
+func projectPreview(ctx context.Context, pathByLabel map[string]string) prompt.Preview {
+	return func(label string, _ int) string {
+		path := pathByLabel[label]
+		var preview strings.Builder
+		if s, err := git.StatusOf(ctx, path); err == nil {
+			switch {
+			case s.Dirty():
+				preview.WriteString(describeStatus(s))
+			case s.Branch != "":
+				preview.WriteString(s.Branch + ": clean")
+			default:
+				preview.WriteString("detached HEAD: clean")
+			}
+			preview.WriteString("\n\n")
+		}
+		if data, err := os.ReadFile(filepath.Join(path, "README.md")); err == nil {
+			preview.WriteString(string(data))
+		}
+		return preview.String()
+	}
+}
+
+// resolvePicker returns the selector to use from --picker, falling back to the
+// config and then to fzf when it is installed.
+func resolvePicker(flag string) (string, error) {
+	picker := flag
+	if picker == "" {
+		if cfg, err := config.Load(); err == nil {
+			picker = cfg.Projects.Picker
+		}
+	}
+	switch picker {
+	case "", pickerAuto:
+		if _, err := exec.LookPath("fzf"); err == nil {
+			return pickerFzf, nil
+		}
+		return pickerBuiltin, nil
+	case pickerFzf:
+		if _, err := exec.LookPath("fzf"); err != nil {
+			fmt.Fprintln(os.Stderr, "[!] fzf not found on PATH, using the built-in selector")
+			return pickerBuiltin, nil
+		}
+		return pickerFzf, nil
+	case pickerBuiltin:
+		return pickerBuiltin, nil
+	}
+	return "", fmt.Errorf("invalid picker: %s (expected auto, fzf or builtin)", picker)
+}
+
+// fzfSelect pipes the labels into fzf and returns the paths of the selected
+// projects. fzf draws on /dev/tty itself, so its stdout is only the selection.
+func fzfSelect(labels []string, pathByLabel map[string]string, multi bool) ([]string, error) {
+	// Each line is "<path>\t<label>": only the label is shown, the path feeds the preview
+	var input bytes.Buffer
+	for _, label := range labels {
+		fmt.Fprintf(&input, "%s\t%s\n", pathByLabel[label], label)
+	}
+
+	args := []string{
+		"--delimiter", "\t",
+		"--with-nth", "2..",
+		"--height", "40%",
+		"--reverse",
+		"--prompt", "Select a project> ",
+		"--preview", fzfPreview,
+	}
+	if multi {
+		args = append(args, "--multi")
+	}
+	cmd := exec.Command("fzf", args...)
+	cmd.Stdin = &input
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		// 1: no match, 130: interrupted with Esc/Ctrl+C
+		if errors.As(err, &exitErr) && (exitErr.ExitCode() == 1 || exitErr.ExitCode() == 130) {
+			return nil, fmt.Errorf("selection cancelled")
+		}
+		return nil, fmt.Errorf("fzf failed: %w", err)
+	}
+
+	var paths []string
+	for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		if path, _, ok := strings.Cut(line, "\t"); ok {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("selection cancelled")
+	}
+	return paths, nil
+}
@@ -0,0 +1,182 @@
+package prj
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/project"
+	"cli-aio/internal/pkg/scratch"
+	"cli-aio/internal/prompt"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// gcCmd deletes the expired throwaway projects.
+func gcCmd() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:  "gc",
+		Usage: "Delete expired scratch projects (directory and store entry) after confirmation",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "all",
+				Aliases: []string{"a"},
+				Usage:   "Include scratch and expiring projects that didn't expire yet",
+			},
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "Only list what would be deleted",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Delete without asking for confirmation",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			store, err := project.Load()
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			var due []project.Project
+			waiting := 0
+			for _, p := range store.Projects {
+				switch {
+				case !p.Disposable():
+				case c.Bool("all") || p.Expired(now) || p.Expires == nil:
+					due = append(due, p)
+				default:
+					waiting++
+				}
+			}
+			if len(due) == 0 {
+				fmt.Println("[+] No expired projects")
+				if waiting > 0 {
+					fmt.Printf("    %d project(s) not expired yet, --all to include them\n", waiting)
+				}
+				return nil
+			}
+
+			var deletable []project.Project
+			for _, p := range due {
+				state := "scratch"
+				if p.Expires != nil {
+					state = "expires " + p.Expires.Format("2006-01-02")
+					if p.Expired(now) {
+						state = "expired " + p.Expires.Format("2006-01-02")
+					}
+				}
+				if _, err := os.Stat(p.Path); os.IsNotExist(err) {
+					state = "directory gone"
+				} else if reason := project.DeleteBlocker(c.Context, p.Path); reason != "" {
+					fmt.Printf("[!] %-20s %s, kept: %s\n", p.Name, p.Path, reason)
+					continue
+				}
+				deletable = append(deletable, p)
+				fmt.Printf("    %-20s %s (%s)\n", p.Name, p.Path, state)
+			}
+			if len(deletable) == 0 {
+				fmt.Println("\n[!] Nothing to delete")
+				return nil
+			}
+			if c.Bool("dry-run") {
+				fmt.Printf("\n%d project(s) would be deleted\n", len(deletable))
+				return nil
+			}
+			if !c.Bool("yes") {
+				ok, err := prompt.Confirm(fmt.Sprintf("Delete %d project(s) and their directories?", len(deletable)), false)
+				if err != nil {
+					return fmt.Errorf("confirmation cancelled: %w", err)
+				}
+				if !ok {
+					return nil
+				}
+			}
+
+			deleted := 0
+			for i, p := range deletable {
+				if cmd.Stopping(c.Context) {
+					fmt.Printf("[!] Interrupted: %d of %d project(s) not deleted\n", len(deletable)-i, len(deletable))
+					break
+				}
+				if err := project.Delete(store, p.Path); err != nil {
+					fmt.Printf("[-] %v\n", err)
+					continue
+				}
+				deleted++
+			}
+			if deleted > 0 {
+				if err := project.Save(store); err != nil {
+					return err
+				}
+			}
+			fmt.Printf("[+] Deleted %d project(s)\n", deleted)
+			return nil
+		},
+	}, `Projects tagged "scratch" (see 'aio scratch') or given an expiry with 'prj expire' are listed once
+expired, and deleted with their directory after confirmation. A git repository with commits no remote
+has is never deleted: push them, or keep the project with 'aio scratch keep' ('prj expire never' when
+it isn't a scratch project).`,
+		cmd.Example{Command: "aio prj gc -n", Comment: "What would be deleted"},
+		cmd.Example{Command: "aio prj gc", Comment: "Delete the expired projects after confirmation"},
+		cmd.Example{Command: "aio prj gc --all -y", Comment: "Every scratch project, without asking"},
+	)
+}
+
+// expireCmd sets or clears the expiry of projects.
+func expireCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "expire",
+		Usage:     "Mark projects for 'prj gc' after a delay, e.g. 30d, or 'never' to clear it",
+		ArgsUsage: "<delay|never> [path...]",
+		Action: func(c *cli.Context) error {
+			delay := c.Args().First()
+			if delay == "" {
+				return fmt.Errorf("missing delay, e.g. 'prj expire 30d' or 'prj expire never'")
+			}
+			var expires *time.Time
+			if delay != "never" {
+				ttl, err := scratch.TTL(delay)
+				if err != nil {
+					return err
+				}
+				at := time.Now().Add(ttl)
+				expires = &at
+			}
+
+			store, err := project.Load()
+			if err != nil {
+				return err
+			}
+			paths := c.Args().Tail()
+			if len(paths) == 0 {
+				paths = []string{"."}
+			}
+			for _, path := range paths {
+				expanded, err := expandPath(path)
+				if err != nil {
+					return err
+				}
+				absPath, err := filepath.Abs(expanded)
+				if err != nil {
+					return fmt.Errorf("invalid path: %w", err)
+				}
+				i := project.Containing(store, absPath)
+				if i < 0 {
+					return fmt.Errorf("not a saved project: %s (add it with 'prj add')", absPath)
+				}
+				store.Projects[i].Expires = expires
+				if expires == nil {
+					fmt.Printf("[+] %s no longer expires\n", store.Projects[i].Path)
+				} else {
+					fmt.Printf("[+] %s expires %s\n", store.Projects[i].Path, expires.Format("2006-01-02 15:04"))
+				}
+			}
+			return project.Save(store)
+		},
+	}
+}
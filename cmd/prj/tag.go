@@ -0,0 +1,142 @@
+package prj
+
+import (
+	"cli-aio/internal/pkg/project"
+	"cli-aio/internal/prompt"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/urfave/cli/v2"
+)
+
+// tagCmd labels projects so lists can be filtered with 'prj cd --tag'.
+func tagCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "tag",
+		Usage:     "Tag projects (no arguments: list tags, tag only: choose the tagged projects)",
+		ArgsUsage: "[tag] [path...]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "remove",
+				Aliases: []string{"r"},
+				Usage:   "Remove the tag from the given paths",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			store, err := project.Load()
+			if err != nil {
+				return err
+			}
+
+			tag := c.Args().First()
+			if tag == "" {
+				listTags(store.Projects)
+				return nil
+			}
+
+			paths := c.Args().Tail()
+			var changed int
+			if len(paths) == 0 {
+				if changed, err = selectTagged(store.Projects, tag); err != nil {
+					return err
+				}
+			} else {
+				for _, path := range paths {
+					expanded, err := expandPath(path)
+					if err != nil {
+						return err
+					}
+					absPath, err := filepath.Abs(expanded)
+					if err != nil {
+						return fmt.Errorf("invalid path: %w", err)
+					}
+					i := project.Find(store, absPath)
+					if i < 0 {
+						return fmt.Errorf("not a saved project: %s (add it with 'prj add')", absPath)
+					}
+					if setTag(&store.Projects[i], tag, !c.Bool("remove")) {
+						changed++
+					}
+				}
+			}
+
+			if changed == 0 {
+				fmt.Println("[+] Nothing changed")
+				return nil
+			}
+			if err := project.Save(store); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Updated tag '%s' on %d project(s)\n", tag, changed)
+			return nil
+		},
+	}
+}
+
+// selectTagged lets the user pick exactly which projects carry tag.
+func selectTagged(projects []project.Project, tag string) (int, error) {
+	labels, pathByLabel := projectLabels(projects)
+	var defaults []string
+	for i, p := range projects {
+		if p.HasTag(tag) {
+			defaults = append(defaults, labels[i])
+		}
+	}
+	selected, err := prompt.MultiSelect(fmt.Sprintf("Projects tagged '%s':", tag), labels, defaults)
+	if err != nil {
+		return 0, fmt.Errorf("selection cancelled: %w", err)
+	}
+	want := map[string]bool{}
+	for _, label := range selected {
+		want[pathByLabel[label]] = true
+	}
+	changed := 0
+	for i := range projects {
+		if setTag(&projects[i], tag, want[projects[i].Path]) {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// setTag adds or removes tag and reports whether the project changed.
+func setTag(p *project.Project, tag string, on bool) bool {
+	if p.HasTag(tag) == on {
+		return false
+	}
+	if on {
+		p.Tags = append(p.Tags, tag)
+		sort.Strings(p.Tags)
+		return true
+	}
+	tags := p.Tags[:0]
+	for _, t := range p.Tags {
+		if t != tag {
+			tags = append(tags, t)
+		}
+	}
+	p.Tags = tags
+	return true
+}
+
+func listTags(projects []project.Project) {
+	counts := map[string]int{}
+	for _, p := range projects {
+		for _, t := range p.Tags {
+			counts[t]++
+		}
+	}
+	if len(counts) == 0 {
+		fmt.Println("[!] No tags yet. Use 'prj tag <tag> [path...]' to tag projects.")
+		return
+	}
+	tags := make([]string, 0, len(counts))
+	for t := range counts {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+	for _, t := range tags {
+		fmt.Printf("%-20s %d project(s)\n", t, counts[t])
+	}
+}
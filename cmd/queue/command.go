@@ -0,0 +1,174 @@
+package queue
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/offline"
+	"cli-aio/internal/prompt"
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	subcommands := []*cli.Command{
+		listCmd(),
+		runCmd(),
+	}
+
+	return &cli.Command{
+		Name:        "queue",
+		Usage:       "Actions deferred while offline (tag pushes, releases, notifications, MR comments)",
+		Subcommands: subcommands,
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				if !cmd.ValidateSubcommand(c, subcommands) {
+					return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+				}
+				return nil
+			}
+			return prompt.SelectCommand(c, subcommands, "Select a subcommand:", cli.ShowSubcommandHelp)
+		},
+	}
+}
+
+func listCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List the queued actions, oldest first",
+		Action: func(c *cli.Context) error {
+			q, err := offline.LoadQueue()
+			if err != nil {
+				return err
+			}
+			if len(q.Actions) == 0 {
+				fmt.Println("[+] Nothing queued")
+				return nil
+			}
+			for _, a := range q.Actions {
+				fmt.Printf("#%-3d %-16s %-45s %s\n", a.ID, a.QueuedAt.Format("2006-01-02 15:04"), a.Description, a.Dir)
+			}
+			fmt.Println("\nReplay them with 'aio queue run'")
+			return nil
+		},
+	}
+}
+
+// Choices offered when replaying an action conflicts with what happened meanwhile.
+const (
+	conflictKeep  = "Keep it queued"
+	conflictDrop  = "Drop it"
+	conflictForce = "Run it anyway"
+)
+
+func runCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "run",
+		Usage:     "Replay the queued actions (all, or the given IDs) now that the network is back",
+		ArgsUsage: "[id...]",
+		Action: func(c *cli.Context) error {
+			if offline.Enabled() {
+				return fmt.Errorf("cannot replay the queue in offline mode")
+			}
+			q, err := offline.LoadQueue()
+			if err != nil {
+				return err
+			}
+			actions, err := selectActions(q, c.Args().Slice())
+			if err != nil {
+				return err
+			}
+			if len(actions) == 0 {
+				fmt.Println("[+] Nothing queued")
+				return nil
+			}
+
+			done, failed := 0, 0
+			for _, a := range actions {
+				stop := false
+				fmt.Printf("-> #%d %s\n", a.ID, a.Description)
+				err := replay(c.Context, a, false)
+				if conflict, ok := err.(*errConflict); ok {
+					err = resolveConflict(c.Context, a, conflict)
+				}
+				switch {
+				case err == errDropped:
+					q.Remove(a.ID)
+					fmt.Printf("[+] Dropped #%d\n", a.ID)
+				case err == errKept:
+					failed++
+				case err != nil && offline.IsNetworkError(err):
+					// The rest would fail the same way
+					fmt.Printf("[-] Still offline: %s\n", offline.Reason(err))
+					failed++
+					stop = true
+				case err != nil:
+					fmt.Printf("[-] %v\n", err)
+					failed++
+				default:
+					q.Remove(a.ID)
+					done++
+				}
+				if stop {
+					break
+				}
+			}
+
+			if err := offline.SaveQueue(q); err != nil {
+				return err
+			}
+			fmt.Printf("\n%d done, %d left in the queue\n", done, len(q.Actions))
+			if failed > 0 {
+				return fmt.Errorf("%d action(s) not replayed", failed)
+			}
+			return nil
+		},
+	}
+}
+
+// selectActions returns the queued actions with the given IDs, or all of them.
+func selectActions(q *offline.Queue, ids []string) ([]offline.Action, error) {
+	if len(ids) == 0 {
+		return append([]offline.Action(nil), q.Actions...), nil
+	}
+	var actions []offline.Action
+	for _, raw := range ids {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid action ID: %s", raw)
+		}
+		found := false
+		for _, a := range q.Actions {
+			if a.ID == id {
+				actions = append(actions, a)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no queued action #%d, see 'aio queue list'", id)
+		}
+	}
+	return actions, nil
+}
+
+// resolveConflict asks what to do with an action that conflicts; without a
+// terminal it stays queued.
+func resolveConflict(ctx context.Context, a offline.Action, conflict *errConflict) error {
+	fmt.Printf("[!] Conflict: %s\n", conflict.reason)
+	options := []string{conflictKeep, conflictDrop}
+	if conflict.forcible {
+		options = append(options, conflictForce)
+	}
+	_, choice, err := prompt.SelectWithFuzzy(fmt.Sprintf("#%d %s:", a.ID, a.Description), options, conflictKeep, false)
+	if err != nil {
+		return errKept
+	}
+	switch choice {
+	case conflictDrop:
+		return errDropped
+	case conflictForce:
+		return replay(ctx, a, true)
+	}
+	return errKept
+}
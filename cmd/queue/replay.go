@@ -0,0 +1,183 @@
+package queue
+
+import (
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/pkg/notify"
+	"cli-aio/internal/pkg/offline"
+	"cli-aio/internal/pkg/secrets"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// staleAfter is how old a notification or comment may be before replaying it
+// needs confirmation: it may no longer be news.
+const staleAfter = 24 * time.Hour
+
+// errConflict means replaying the action would clash with what happened since
+// it was queued. forcible conflicts can be overridden.
+type errConflict struct {
+	reason   string
+	forcible bool
+}
+
+func (e *errConflict) Error() string {
+	return e.reason
+}
+
+// Outcomes of resolveConflict besides running the action.
+var (
+	errDropped = errors.New("dropped")
+	errKept    = errors.New("kept")
+)
+
+// replay runs a queued action in the directory it was queued in. force skips
+// the forcible conflict checks.
+func replay(ctx context.Context, a offline.Action, force bool) error {
+	if a.Dir != "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		if err := os.Chdir(a.Dir); err != nil {
+			return fmt.Errorf("cannot enter %s: %w", a.Dir, err)
+		}
+		defer os.Chdir(wd)
+	}
+
+	switch a.Kind {
+	case offline.ActionPushTag:
+		return replayPushTag(ctx, a)
+	case offline.ActionRelease:
+		return replayRelease(ctx, a)
+	case offline.ActionNotify:
+		return replayNotify(a, force)
+	case offline.ActionComment:
+		return replayComment(ctx, a, force)
+	}
+	return fmt.Errorf("unknown action kind: %s", a.Kind)
+}
+
+// replayPushTag pushes the tag unless origin got the same tag name meanwhile.
+func replayPushTag(ctx context.Context, a offline.Action) error {
+	tag := a.Params["tag"]
+	local, err := git.ResolveCommit(ctx, tag)
+	if err != nil {
+		return &errConflict{reason: fmt.Sprintf("local tag %s no longer exists", tag)}
+	}
+	remote, err := git.RemoteTagCommit(ctx, tag)
+	if err != nil {
+		return err
+	}
+	switch remote {
+	case "":
+	case local:
+		fmt.Printf("[+] origin already has %s\n", tag)
+		return nil
+	default:
+		return &errConflict{reason: fmt.Sprintf("origin already has a different %s (%s), delete the local tag and tag again", tag, remote[:min(8, len(remote))])}
+	}
+	if err := git.PushTag(ctx, tag); err != nil {
+		return err
+	}
+	fmt.Printf("[+] Pushed tag %s\n", tag)
+	return nil
+}
+
+// replayRelease creates the release once its tag is on origin.
+func replayRelease(ctx context.Context, a offline.Action) error {
+	project, tag := a.Params["project"], a.Params["tag"]
+	client, err := gitlab.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+	existing, err := client.GetRelease(project, tag)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		fmt.Printf("[+] Release %s already exists\n", tag)
+		return nil
+	}
+	if remote, err := git.RemoteTagCommit(ctx, tag); err != nil {
+		return err
+	} else if remote == "" {
+		return &errConflict{reason: fmt.Sprintf("tag %s is not on origin, push it first", tag)}
+	}
+	if _, err := client.CreateRelease(project, tag, a.Params["description"]); err != nil {
+		return err
+	}
+	fmt.Printf("[+] Created release %s\n", tag)
+	return nil
+}
+
+// replayNotify sends the notification, confirming first when it is old.
+func replayNotify(a offline.Action, force bool) error {
+	if !force && time.Since(a.QueuedAt) > staleAfter {
+		return &errConflict{reason: fmt.Sprintf("the notification was queued %s ago", age(a)), forcible: true}
+	}
+	msg := notify.Message{Title: a.Params["title"], Text: a.Params["text"], OK: true}
+	var err error
+	if secret := a.Params["secret"]; secret != "" {
+		// Queued before notification routes: straight to the webhook
+		var webhook string
+		if webhook, err = secrets.Require(secret); err != nil {
+			return err
+		}
+		err = notify.Slack{WebhookURL: webhook}.Send(msg)
+	} else {
+		err = notify.Send(a.Params["event"], msg)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println("[+] Sent notification")
+	return nil
+}
+
+// replayComment comments on the MR unless it was closed or merged meanwhile.
+func replayComment(ctx context.Context, a offline.Action, force bool) error {
+	projectID, err := strconv.Atoi(a.Params["project_id"])
+	if err != nil {
+		return fmt.Errorf("invalid queued project ID: %s", a.Params["project_id"])
+	}
+	iid, err := strconv.Atoi(a.Params["iid"])
+	if err != nil {
+		return fmt.Errorf("invalid queued MR IID: %s", a.Params["iid"])
+	}
+	client, err := gitlab.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+	if !force {
+		mr, err := client.GetMergeRequest(projectID, iid)
+		if err != nil {
+			return err
+		}
+		if mr.State != "opened" {
+			return &errConflict{reason: fmt.Sprintf("%s is %s now", a.Params["reference"], mr.State), forcible: true}
+		}
+		if time.Since(a.QueuedAt) > staleAfter {
+			return &errConflict{reason: fmt.Sprintf("the comment was written %s ago", age(a)), forcible: true}
+		}
+	}
+	if err := client.CommentMergeRequest(projectID, iid, a.Params["body"]); err != nil {
+		return err
+	}
+	fmt.Printf("[+] Commented on %s\n", a.Params["reference"])
+	return nil
+}
+
+// age formats how long ago an action was queued, e.g. "3 days" or "5h20m".
+func age(a offline.Action) string {
+	d := time.Since(a.QueuedAt)
+	if d >= 48*time.Hour {
+		return fmt.Sprintf("%d days", int(d.Hours()/24))
+	}
+	return strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+}
@@ -0,0 +1,66 @@
+package release
+
+import (
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/release"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// calendarLayout formats the bounds of a freeze window.
+const calendarLayout = "Mon 2006-01-02 15:04"
+
+// calendarCmd lists the coming release freeze windows.
+func calendarCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "calendar",
+		Usage: "Show the release freeze windows of the coming days",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:    "days",
+				Aliases: []string{"d"},
+				Usage:   "How many days ahead to show",
+				Value:   14,
+			},
+			&cli.StringFlag{
+				Name:    "env",
+				Aliases: []string{"e"},
+				Usage:   "Environment (qc, stg or prod)",
+				Value:   "prod",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			if len(cfg.Release.Freezes) == 0 {
+				fmt.Println("[!] No freeze windows configured (release.freezes in config.json)")
+				return nil
+			}
+			env := c.String("env")
+			now := time.Now()
+			windows, err := release.Windows(cfg.Release, env, now, now.AddDate(0, 0, c.Int("days")))
+			if err != nil {
+				return err
+			}
+
+			if frozen, _ := release.Frozen(cfg.Release, env, now); frozen != nil {
+				fmt.Printf("[!] %s is frozen now: %s until %s\n", env, frozen.Name, frozen.End.Format(calendarLayout))
+			} else {
+				fmt.Printf("[+] %s can be released now\n", env)
+			}
+			if len(windows) == 0 {
+				fmt.Printf("No freeze for %s in the next %d days\n", env, c.Int("days"))
+				return nil
+			}
+			fmt.Printf("Freezes for %s in the next %d days:\n", env, c.Int("days"))
+			for _, w := range windows {
+				fmt.Printf("  %s -> %s  %s\n", w.Start.Format(calendarLayout), w.End.Format(calendarLayout), w.Name)
+			}
+			return nil
+		},
+	}
+}
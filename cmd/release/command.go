@@ -0,0 +1,507 @@
+package release
+
+import (
+	"cli-aio/cmd/ztag"
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/pkg/notify"
+	"cli-aio/internal/pkg/offline"
+	"cli-aio/internal/pkg/release"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Release steps, in execution order. Their names are persisted in the release state.
+const (
+	stepCheck     = "check"
+	stepTest      = "test"
+	stepChangelog = "changelog"
+	stepTag       = "tag"
+	stepRelease   = "release"
+	stepNotify    = "notify"
+	stepWatch     = "watch"
+)
+
+// run carries everything the steps need.
+type run struct {
+	c     *cli.Context
+	cfg   *config.Config
+	root  string
+	state *release.State
+}
+
+type step struct {
+	name string
+	fn   func(r *run) error
+}
+
+var steps = []step{
+	{stepCheck, checkStep},
+	{stepTest, testStep},
+	{stepChangelog, changelogStep},
+	{stepTag, tagStep},
+	{stepRelease, releaseStep},
+	{stepNotify, notifyStep},
+	{stepWatch, watchStep},
+}
+
+// Command returns the release command which chains the whole release flow.
+// Progress is saved after each step; rerunning after a failure resumes at the failed step.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:      "release",
+		Usage:     "Run the release train: check, test, changelog, tag, release, notify, watch pipeline",
+		ArgsUsage: "[qc|stg|prod]",
+		Subcommands: []*cli.Command{
+			calendarCmd(),
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "level",
+				Aliases: []string{"l"},
+				Usage:   "Level of the tag: b (default) for bug, m for minor and M for major",
+				Value:   "b",
+			},
+			&cli.StringFlag{
+				Name:  "ticket",
+				Usage: "Jira ticket for the release (required for stg/prod, prompted when missing)",
+			},
+			&cli.StringFlag{
+				Name:  "test-cmd",
+				Usage: "Test command to run (default: release.test_command from config, or 'go test ./...')",
+			},
+			&cli.BoolFlag{
+				Name:  "skip-tests",
+				Usage: "Skip the test step",
+			},
+			&cli.BoolFlag{
+				Name:  "no-watch",
+				Usage: "Don't wait for the tag pipeline",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Don't ask for confirmation (resumes an interrupted release automatically)",
+			},
+			&cli.BoolFlag{
+				Name:  "restart",
+				Usage: "Discard an interrupted release and start over",
+			},
+			&cli.StringFlag{
+				Name:  "override",
+				Usage: "Release during a freeze window (release.freezes), giving the reason recorded in the audit history",
+			},
+			&cli.DurationFlag{
+				Name:  "interval",
+				Usage: "Pipeline polling interval",
+				Value: 10 * time.Second,
+			},
+		},
+		Before: cmd.Require(cmd.RequireTool("git"), cmd.RequireGitRepo()),
+		Action: func(c *cli.Context) error {
+			root, err := git.GetTopLevel(c.Context)
+			if err != nil {
+				return fmt.Errorf("not a git repository")
+			}
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			state, err := resumeOrStart(c, root)
+			if err != nil {
+				return err
+			}
+			r := &run{c: c, cfg: cfg, root: root, state: state}
+
+			for i, s := range steps {
+				label := fmt.Sprintf("[%d/%d] %s", i+1, len(steps), s.name)
+				if state.IsDone(s.name) {
+					fmt.Println(ui.Colorize(ui.Gray, label+" (done)"))
+					continue
+				}
+				if cmd.Stopping(c.Context) {
+					printInterrupted(state)
+					return fmt.Errorf("release interrupted before step '%s'", s.name)
+				}
+				fmt.Println(ui.Colorize(ui.Bold, label))
+				if err := s.fn(r); err != nil {
+					if cmd.Stopping(c.Context) {
+						printInterrupted(state)
+						return fmt.Errorf("release interrupted during step '%s': %w", s.name, err)
+					}
+					fmt.Printf("\n[!] Release stopped at step '%s'. Fix the problem and rerun 'aio release' to resume.\n", s.name)
+					return err
+				}
+				state.MarkDone(s.name)
+				if err := release.Save(root, state); err != nil {
+					return err
+				}
+			}
+
+			if err := release.RecordDeployment(c.Context, state.Env, state.Tag, state.Ticket); err != nil {
+				fmt.Printf("[!] Warning: Failed to record deployment: %v\n", err)
+			}
+			if err := release.Clear(root); err != nil {
+				return err
+			}
+			fmt.Printf("\n[+] Released %s to %s\n", state.Tag, state.Env)
+			return nil
+		},
+	}
+}
+
+// printInterrupted lists the steps done and not run when the release is stopped with Ctrl+C.
+func printInterrupted(state *release.State) {
+	var done, notRun []string
+	for _, s := range steps {
+		if state.IsDone(s.name) {
+			done = append(done, s.name)
+		} else {
+			notRun = append(notRun, s.name)
+		}
+	}
+	if len(done) == 0 {
+		done = []string{"none"}
+	}
+	fmt.Printf("\n[!] Release interrupted. Done: %s; not run: %s\n", strings.Join(done, ", "), strings.Join(notRun, ", "))
+	fmt.Println("Rerun 'aio release' to resume.")
+}
+
+// resumeOrStart returns the interrupted release of the project when the user
+// wants to resume it, or a new state built from the arguments.
+func resumeOrStart(c *cli.Context, root string) (*release.State, error) {
+	existing, err := release.Load(root)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && c.Bool("restart") {
+		if err := release.Clear(root); err != nil {
+			return nil, err
+		}
+		existing = nil
+	}
+	if existing != nil {
+		if existing.Ticket == "" {
+			existing.Ticket = c.String("ticket")
+		}
+		what := existing.Env
+		if existing.Tag != "" {
+			what = existing.Tag
+		}
+		msg := fmt.Sprintf("Resume release %s started %s (done: %s)?", what,
+			existing.StartedAt.Format("2006-01-02 15:04"), strings.Join(existing.Done, ", "))
+		if c.Bool("yes") {
+			fmt.Printf("-> Resuming release %s\n", what)
+			return existing, nil
+		}
+		if resume, err := prompt.Confirm(msg, true); err == nil && resume {
+			return existing, nil
+		}
+	}
+
+	env := c.Args().First()
+	if env == "" {
+		_, selected, err := prompt.Select("Select environment:", []string{string(ztag.EnvQC), string(ztag.EnvStg), string(ztag.EnvProd)}, "")
+		if err != nil {
+			return nil, fmt.Errorf("selection cancelled: %w", err)
+		}
+		env = selected
+	}
+	switch ztag.Env(env) {
+	case ztag.EnvQC, ztag.EnvStg, ztag.EnvProd:
+	default:
+		return nil, fmt.Errorf("unknown environment: %s (expected qc, stg or prod)", env)
+	}
+
+	commit, err := git.GetHeadCommit(c.Context)
+	if err != nil {
+		return nil, err
+	}
+	return &release.State{
+		Env:       env,
+		Level:     c.String("level"),
+		Commit:    commit,
+		Ticket:    c.String("ticket"),
+		StartedAt: time.Now(),
+	}, nil
+}
+
+func checkStep(r *run) error {
+	clean, err := git.IsWorkingTreeClean(r.c.Context)
+	if err != nil {
+		return err
+	}
+	if !clean {
+		return fmt.Errorf("working tree has uncommitted changes, commit or stash them first")
+	}
+	head, err := git.GetCurrentBranch(r.c.Context)
+	if err != nil {
+		return err
+	}
+	if err := ztag.CheckDeployBranch(r.c.Context, os.Stdout, ztag.Env(r.state.Env), head); err != nil {
+		return err
+	}
+	if commit, err := git.GetHeadCommit(r.c.Context); err == nil && commit != r.state.Commit {
+		fmt.Printf("[!] HEAD moved since the release started (%s -> %s)\n", git.ShortSHA(r.state.Commit), git.ShortSHA(commit))
+		r.state.Commit = commit
+	}
+	if err := ztag.CheckApprovals(r.c.Context, os.Stdout, ztag.Env(r.state.Env), r.state.Commit); err != nil {
+		return err
+	}
+	if err := ztag.CheckFreeze(os.Stdout, ztag.Env(r.state.Env), r.c.String("override")); err != nil {
+		return err
+	}
+	if err := git.FetchTags(r.c.Context); err != nil {
+		fmt.Printf("[!] Warning: %v\n", err)
+	}
+	fmt.Printf("[+] Working tree clean on %s\n", head)
+	return nil
+}
+
+func testStep(r *run) error {
+	if r.c.Bool("skip-tests") {
+		fmt.Println("[!] Tests skipped")
+		return nil
+	}
+	command := r.c.String("test-cmd")
+	if command == "" {
+		command = r.cfg.Release.TestCommand
+	}
+	if command == "" {
+		if _, err := os.Stat(filepath.Join(r.root, "go.mod")); err != nil {
+			fmt.Println("[!] No test command configured (release.test_command), skipping")
+			return nil
+		}
+		command = "go test ./..."
+	}
+
+	fmt.Printf("-> %s\n", command)
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = r.root
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("tests failed: %w", err)
+	}
+	fmt.Println("[+] Tests passed")
+	return nil
+}
+
+func changelogStep(r *run) error {
+	latestTags, err := git.GetLatestTags(r.c.Context, 1)
+	if err != nil {
+		return err
+	}
+	r.state.PrevTag = latestTags[0]
+
+	since := r.state.PrevTag
+	if since == "v0.0.0" {
+		// GetLatestTags returns v0.0.0 when the repository has no tags yet
+		since = ""
+	}
+	subjects, err := git.CommitSubjects(r.c.Context, since, 50)
+	if err != nil && since != "" {
+		fmt.Printf("[!] Tag %s not available locally, using the latest commits\n", since)
+		subjects, err = git.CommitSubjects(r.c.Context, "", 50)
+	}
+	if err != nil {
+		return err
+	}
+	if len(subjects) == 0 {
+		return fmt.Errorf("no new commits since %s", r.state.PrevTag)
+	}
+	r.state.Changelog = subjects
+
+	fmt.Printf("Changes since %s:\n", r.state.PrevTag)
+	for _, s := range subjects {
+		fmt.Printf("  - %s\n", s)
+	}
+	return nil
+}
+
+func tagStep(r *run) error {
+	nextTag, err := ztag.GenerateNextTag(r.state.PrevTag, ztag.Level(r.state.Level), ztag.Env(r.state.Env))
+	if err != nil {
+		return err
+	}
+	if nextTag, err = ztag.EnsureFreeTag(r.c.Context, os.Stdout, nextTag, ztag.Env(r.state.Env), r.c.Bool("yes")); err != nil {
+		return err
+	}
+	if !r.c.Bool("yes") {
+		if ok, err := prompt.Confirm(fmt.Sprintf("Create and push tag %s (previous %s)?", nextTag, r.state.PrevTag), true); err != nil || !ok {
+			return fmt.Errorf("tagging cancelled")
+		}
+	}
+	queued, err := ztag.CreateAndPushTag(r.c.Context, os.Stdout, nextTag, fmt.Sprintf("Release %s", nextTag))
+	if err != nil {
+		return err
+	}
+	r.state.Tag = nextTag
+	if !queued {
+		fmt.Printf("[+] Pushed tag %s\n", nextTag)
+	}
+	return nil
+}
+
+func releaseStep(r *run) error {
+	// Same rule as ztag: qc tags are not released
+	if ztag.Env(r.state.Env) == ztag.EnvQC {
+		fmt.Println("[!] No release for qc tags")
+		return nil
+	}
+	if r.state.Ticket == "" {
+		ticket, err := ztag.PromptTicket(r.c.Context)
+		if err != nil {
+			return fmt.Errorf("input cancelled: %w", err)
+		}
+		r.state.Ticket = ticket
+	}
+
+	projectID, err := git.ExtractProjectID(r.c.Context)
+	if err != nil {
+		return err
+	}
+	client, err := gitlab.NewClient(r.c.Context)
+	if err != nil {
+		return err
+	}
+	notes := releaseNotes(r.state)
+	queued, err := offline.Run(offline.Action{
+		Kind:        offline.ActionRelease,
+		Description: fmt.Sprintf("create release %s of %s", r.state.Tag, projectID),
+		Params:      map[string]string{"project": projectID, "tag": r.state.Tag, "description": notes},
+	}, func() error {
+		_, err := client.CreateRelease(projectID, r.state.Tag, notes)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !queued {
+		fmt.Printf("[+] Created release %s\n", r.state.Tag)
+	}
+	return nil
+}
+
+func notifyStep(r *run) error {
+	channels, err := notify.Channels(notify.EventRelease)
+	if err != nil {
+		return err
+	}
+	if len(channels) == 0 {
+		fmt.Printf("[!] No notification channel configured, set notify.routes.release in config.json or a webhook with 'aio secrets set %s'\n", notify.LegacySecret)
+		return nil
+	}
+	project, err := git.ExtractProjectID(r.c.Context)
+	if err != nil {
+		project = filepath.Base(r.root)
+	}
+	msg := notify.Message{
+		Title: fmt.Sprintf("Released %s %s to %s", project, r.state.Tag, r.state.Env),
+		Text:  releaseNotes(r.state),
+		OK:    true,
+	}
+	queued, err := offline.Run(offline.Action{
+		Kind:        offline.ActionNotify,
+		Description: fmt.Sprintf("send release notification for %s", r.state.Tag),
+		Params:      map[string]string{"event": notify.EventRelease, "title": msg.Title, "text": msg.Text},
+	}, func() error {
+		return notify.Send(notify.EventRelease, msg)
+	})
+	if err != nil {
+		return err
+	}
+	if !queued {
+		fmt.Println("[+] Sent release notification")
+	}
+	return nil
+}
+
+func watchStep(r *run) error {
+	if r.c.Bool("no-watch") {
+		return nil
+	}
+	if offline.Enabled() {
+		fmt.Println("[!] Offline: not watching the pipeline")
+		return nil
+	}
+	projectID, err := git.ExtractProjectID(r.c.Context)
+	if err != nil {
+		return err
+	}
+	client, err := gitlab.NewClient(r.c.Context)
+	if err != nil {
+		return err
+	}
+	interval := r.c.Duration("interval")
+
+	// The pipeline is created asynchronously after the tag push
+	var pipeline *gitlab.Pipeline
+	for deadline := time.Now().Add(2 * time.Minute); ; {
+		pipeline, err = client.LatestPipeline(projectID, r.state.Tag)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		if !wait(r, interval) {
+			return fmt.Errorf("stopped waiting for the pipeline")
+		}
+	}
+
+	fmt.Printf("-> Watching pipeline #%d %s\n", pipeline.ID, pipeline.WebURL)
+	last := ""
+	for {
+		if pipeline.Status != last {
+			fmt.Printf("   %s %s\n", time.Now().Format("15:04:05"), pipeline.Status)
+			last = pipeline.Status
+		}
+		if gitlab.IsFinished(pipeline.Status) {
+			if pipeline.Status != "success" && pipeline.Status != "manual" {
+				return fmt.Errorf("pipeline #%d finished with status %s", pipeline.ID, pipeline.Status)
+			}
+			fmt.Printf("[+] Pipeline #%d %s\n", pipeline.ID, pipeline.Status)
+			return nil
+		}
+		if !wait(r, interval) {
+			return fmt.Errorf("stopped watching pipeline #%d", pipeline.ID)
+		}
+		if pipeline, err = client.GetPipeline(projectID, pipeline.ID); err != nil {
+			return err
+		}
+	}
+}
+
+// wait sleeps for d, returning false early when the user pressed Ctrl+C.
+func wait(r *run, d time.Duration) bool {
+	select {
+	case <-cmd.Stopped(r.c.Context):
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// releaseNotes renders the Jira ticket and changelog as the release description.
+func releaseNotes(state *release.State) string {
+	var b strings.Builder
+	if state.Ticket != "" {
+		fmt.Fprintf(&b, "Jira: %s\n\n", state.Ticket)
+	}
+	for _, s := range state.Changelog {
+		fmt.Fprintf(&b, "- %s\n", s)
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,171 @@
+package remind
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/remind"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	subcommands := []*cli.Command{
+		listCmd(),
+		addCmd(),
+		doneCmd(),
+	}
+
+	return &cli.Command{
+		Name:        "remind",
+		Usage:       "Recurring reminders for team rituals, shown as a banner when due",
+		Subcommands: subcommands,
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				if !cmd.ValidateSubcommand(c, subcommands) {
+					return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+				}
+				return nil
+			}
+			return prompt.SelectCommand(c, subcommands, "Select a subcommand:", cli.ShowSubcommandHelp)
+		},
+	}
+}
+
+func listCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List all reminders and when they are due",
+		Action: func(c *cli.Context) error {
+			store, err := remind.Load()
+			if err != nil {
+				return err
+			}
+			if len(store.Reminders) == 0 {
+				fmt.Println("[!] No reminders. Use 'aio remind add' to create one.")
+				return nil
+			}
+
+			now := time.Now()
+			for _, r := range store.Reminders {
+				due := r.NextDue.Format("2006-01-02 15:04")
+				if !r.NextDue.After(now) {
+					due = ui.Colorize(ui.Yellow, "DUE "+due)
+				}
+				fmt.Printf("#%-3d %-8s %-22s %s\n", r.ID, r.Every, due, r.Message)
+			}
+			return nil
+		},
+	}
+}
+
+func addCmd() *cli.Command {
+	return cmd.AskFlags(&cli.Command{
+		Name:      "add",
+		Usage:     "Add a recurring reminder",
+		ArgsUsage: "[message]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "every",
+				Aliases: []string{"e"},
+				Usage:   "Interval: 12h, 1d, 2w, 3m, daily, weekly, monthly",
+			},
+			&cli.StringFlag{
+				Name:  "start",
+				Usage: "First due date (YYYY-MM-DD), defaults to now + interval",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			var err error
+			message := strings.Join(c.Args().Slice(), " ")
+			if message == "" {
+				message, err = prompt.Input("Reminder message:", "", true)
+				if err != nil {
+					return fmt.Errorf("input cancelled: %w", err)
+				}
+			}
+
+			every := c.String("every")
+			interval, err := remind.ParseInterval(every)
+			if err != nil {
+				return err
+			}
+
+			start := time.Now().Add(interval)
+			if s := c.String("start"); s != "" {
+				start, err = time.ParseInLocation("2006-01-02", s, time.Local)
+				if err != nil {
+					return fmt.Errorf("invalid start date '%s' (expected YYYY-MM-DD)", s)
+				}
+			}
+
+			store, err := remind.Load()
+			if err != nil {
+				return err
+			}
+			r, err := remind.Add(store, message, every, start)
+			if err != nil {
+				return err
+			}
+			if err := remind.Save(store); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Added reminder #%d, first due %s\n", r.ID, r.NextDue.Format("2006-01-02 15:04"))
+			return nil
+		},
+	}, cmd.Ask{Flag: "every", Message: "Repeat every (e.g. 1d, 2w, monthly):", Default: "weekly"})
+}
+
+func doneCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "done",
+		Usage:     "Mark a reminder as done and schedule the next occurrence",
+		ArgsUsage: "[id]",
+		Action: func(c *cli.Context) error {
+			store, err := remind.Load()
+			if err != nil {
+				return err
+			}
+
+			var id int
+			if arg := strings.TrimPrefix(c.Args().First(), "#"); arg != "" {
+				id, err = strconv.Atoi(arg)
+				if err != nil {
+					return fmt.Errorf("invalid reminder id: %s", c.Args().First())
+				}
+			} else {
+				// Offer due reminders first, fall back to all of them
+				candidates := remind.Due(store, time.Now())
+				if len(candidates) == 0 {
+					candidates = store.Reminders
+				}
+				if len(candidates) == 0 {
+					return fmt.Errorf("no reminders to mark as done")
+				}
+				labels := make([]string, len(candidates))
+				for i, r := range candidates {
+					labels[i] = fmt.Sprintf("#%d %s", r.ID, r.Message)
+				}
+				idx, _, err := prompt.Select("Select reminder:", labels, "")
+				if err != nil {
+					return fmt.Errorf("selection cancelled: %w", err)
+				}
+				id = candidates[idx].ID
+			}
+
+			r, err := remind.Done(store, id, time.Now())
+			if err != nil {
+				return err
+			}
+			if err := remind.Save(store); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Done: %s (next due %s)\n", r.Message, r.NextDue.Format("2006-01-02 15:04"))
+			return nil
+		},
+	}
+}
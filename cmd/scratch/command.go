@@ -0,0 +1,253 @@
+package scratch
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/project"
+	"cli-aio/internal/pkg/scratch"
+	"cli-aio/internal/prompt"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:      "scratch",
+		Usage:     "Create a throwaway project for a quick experiment and print its path",
+		ArgsUsage: "[lang]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "ttl",
+				Usage: "How long the project lives, e.g. 12h, 7d, 2w (default: projects.scratch_ttl or 7d)",
+			},
+			&cli.BoolFlag{
+				Name:  "git",
+				Usage: "Initialize a git repository",
+			},
+		},
+		Subcommands: []*cli.Command{
+			listCmd(),
+			keepCmd(),
+			cleanCmd(),
+		},
+		Action: func(c *cli.Context) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			dir, ttl, err := scratch.Settings(cfg)
+			if err != nil {
+				return err
+			}
+			if s := c.String("ttl"); s != "" {
+				if ttl, err = scratch.TTL(s); err != nil {
+					return err
+				}
+			}
+			lang := c.Args().First()
+			template, err := scratch.Lookup(lang, cfg.Projects.ScratchTemplates)
+			if err != nil {
+				return err
+			}
+			if c.Bool("git") {
+				template.Commands = append([]string{"git init -q"}, template.Commands...)
+			}
+
+			store, err := project.Load()
+			if err != nil {
+				return err
+			}
+			removeExpired(c.Context, store, dir, time.Now(), false)
+
+			path, err := scratch.Create(dir, lang, template)
+			if err != nil {
+				return err
+			}
+			expires := time.Now().Add(ttl)
+			p := project.Project{Name: filepath.Base(path), Path: path, Tags: []string{scratch.Tag}, Expires: &expires}
+			project.Add(store, p)
+			if err := project.Save(store); err != nil {
+				return err
+			}
+
+			// Only the path goes to stdout, for cd "$(aio scratch go)"
+			fmt.Fprintf(os.Stderr, "[+] Scratch project expires %s ('aio scratch keep' to keep it)\n", expires.Format("2006-01-02 15:04"))
+			fmt.Println(path)
+			return nil
+		},
+	}, `Creates a new directory under projects.scratch_dir (default <tmp>/aio-scratch), initialized for lang:
+go (go mod init), node (npm init) or python (venv), or the commands in projects.scratch_templates.
+It is saved in the project store with the "scratch" tag, so 'prj' finds it, and removed with its
+directory by the next 'aio scratch' (or 'prj gc') once it expires, unless it has unpushed commits.
+Messages go to stderr and only the path to stdout.`,
+		cmd.Example{Command: `cd "$(aio scratch go)"`, Comment: "New Go module, then jump into it"},
+		cmd.Example{Command: "aio scratch --ttl 1d --git python", Comment: "Python venv for a day"},
+		cmd.Example{Command: "aio scratch list", Comment: "Scratch projects and when they expire"},
+	)
+}
+
+func listCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List scratch projects and when they expire",
+		Action: func(c *cli.Context) error {
+			store, err := project.Load()
+			if err != nil {
+				return err
+			}
+			projects := project.FilterByTags(store.Projects, []string{scratch.Tag})
+			if len(projects) == 0 {
+				fmt.Println("[!] No scratch projects. Use 'aio scratch [lang]' to create one.")
+				return nil
+			}
+			now := time.Now()
+			for _, p := range projects {
+				expires := "kept"
+				if p.Expires != nil {
+					expires = p.Expires.Format("2006-01-02 15:04")
+					if p.Expired(now) {
+						expires = "expired " + expires
+					}
+				}
+				fmt.Printf("%-22s %s\n", expires, p.Path)
+			}
+			return nil
+		},
+	}
+}
+
+func keepCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "keep",
+		Usage:     "Keep a scratch project: remove its expiry and the scratch tag",
+		ArgsUsage: "[dir]",
+		Action: func(c *cli.Context) error {
+			store, err := project.Load()
+			if err != nil {
+				return err
+			}
+			dir := c.Args().First()
+			if dir == "" {
+				if dir, err = os.Getwd(); err != nil {
+					return err
+				}
+			}
+			i := project.Containing(store, dir)
+			if i < 0 || !store.Projects[i].HasTag(scratch.Tag) {
+				return fmt.Errorf("%s is not a scratch project", dir)
+			}
+
+			p := &store.Projects[i]
+			p.Expires = nil
+			var tags []string
+			for _, tag := range p.Tags {
+				if tag != scratch.Tag {
+					tags = append(tags, tag)
+				}
+			}
+			p.Tags = tags
+			if err := project.Save(store); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Kept %s\n", p.Path)
+			if strings.HasPrefix(p.Path, project.NormalizePath(os.TempDir())) {
+				fmt.Println("[!] It is in the temp directory, move it somewhere safe (then 'prj add' the new place)")
+			}
+			return nil
+		},
+	}
+}
+
+func cleanCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "clean",
+		Usage: "Remove expired scratch projects now",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Remove every scratch project, expired or not",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Don't ask for confirmation with --all",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			dir, _, err := scratch.Settings(cfg)
+			if err != nil {
+				return err
+			}
+			store, err := project.Load()
+			if err != nil {
+				return err
+			}
+
+			if c.Bool("all") && !c.Bool("yes") {
+				count := len(project.FilterByTags(store.Projects, []string{scratch.Tag}))
+				if count == 0 {
+					fmt.Println("[!] No scratch projects")
+					return nil
+				}
+				ok, err := prompt.Confirm(fmt.Sprintf("Delete %d scratch project(s) and their directories?", count), false)
+				if err != nil {
+					return fmt.Errorf("confirmation cancelled: %w", err)
+				}
+				if !ok {
+					return nil
+				}
+			}
+			if removeExpired(c.Context, store, dir, time.Now(), c.Bool("all")) == 0 {
+				fmt.Fprintln(os.Stderr, "[+] Nothing removed")
+			}
+			return nil
+		},
+	}
+}
+
+// removeExpired deletes the scratch projects expired at now (all of them with
+// all) from store, and their directories when they are under the scratch
+// directory. Repositories with unpushed commits are kept. The store is saved when something was removed. Returns the
+// number removed.
+func removeExpired(ctx context.Context, store *project.Store, scratchDir string, now time.Time, all bool) int {
+	scratchDir = project.NormalizePath(scratchDir)
+	var expired []project.Project
+	for _, p := range project.FilterByTags(store.Projects, []string{scratch.Tag}) {
+		if all || p.Expired(now) {
+			expired = append(expired, p)
+		}
+	}
+	removed := 0
+	for _, p := range expired {
+		if reason := project.DeleteBlocker(ctx, p.Path); reason != "" {
+			fmt.Fprintf(os.Stderr, "[!] Scratch project %s kept: %s\n", p.Path, reason)
+			continue
+		}
+		if scratch.Owned(scratchDir, p.Path) {
+			if err := project.Delete(store, p.Path); err != nil {
+				fmt.Fprintf(os.Stderr, "[!] Warning: %v\n", err)
+				continue
+			}
+		} else {
+			project.Remove(store, p.Path)
+		}
+		removed++
+		fmt.Fprintf(os.Stderr, "[+] Removed scratch project %s\n", p.Path)
+	}
+	if removed > 0 {
+		if err := project.Save(store); err != nil {
+			fmt.Fprintf(os.Stderr, "[!] Warning: %v\n", err)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,201 @@
+package secrets
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/secrets"
+	"cli-aio/internal/prompt"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	subcommands := []*cli.Command{
+		setCmd(),
+		listCmd(),
+		removeCmd(),
+		encryptCmd(),
+		decryptCmd(),
+		keyCmd(),
+	}
+
+	return &cli.Command{
+		Name:        "secrets",
+		Usage:       "Manage credentials used by integrations (stored with 0600 permissions)",
+		Subcommands: subcommands,
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				if !cmd.ValidateSubcommand(c, subcommands) {
+					return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+				}
+				return nil
+			}
+			return prompt.SelectCommand(c, subcommands, "Select a subcommand:", cli.ShowSubcommandHelp)
+		},
+	}
+}
+
+func setCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "set",
+		Usage:     "Store a secret (value is read without echo)",
+		ArgsUsage: "<name>",
+		Action: func(c *cli.Context) error {
+			name := c.Args().First()
+			if name == "" {
+				var err error
+				name, err = prompt.Input("Enter secret name (e.g. jira.token):", "", true)
+				if err != nil {
+					return fmt.Errorf("input cancelled: %w", err)
+				}
+			}
+
+			value, err := prompt.Password(fmt.Sprintf("Value for %s:", name))
+			if err != nil {
+				return fmt.Errorf("input cancelled: %w", err)
+			}
+			if err := secrets.Set(name, value); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Saved secret '%s'\n", name)
+			return nil
+		},
+	}
+}
+
+func listCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List stored secrets with masked values",
+		Action: func(c *cli.Context) error {
+			names, err := secrets.Names()
+			if err != nil {
+				return err
+			}
+			if len(names) == 0 {
+				fmt.Println("[!] No secrets stored. Use 'aio secrets set <name>' to add one.")
+				return nil
+			}
+			for _, name := range names {
+				value, err := secrets.Get(name)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("%-24s %s\n", name, secrets.Mask(value))
+			}
+			return nil
+		},
+	}
+}
+
+func removeCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "rm",
+		Usage:     "Remove a stored secret",
+		ArgsUsage: "<name>",
+		Action: func(c *cli.Context) error {
+			name := c.Args().First()
+			if name == "" {
+				names, err := secrets.Names()
+				if err != nil {
+					return err
+				}
+				_, name, err = prompt.Select("Select secret to remove:", names, "")
+				if err != nil {
+					return fmt.Errorf("selection cancelled: %w", err)
+				}
+			}
+			removed, err := secrets.Delete(name)
+			if err != nil {
+				return err
+			}
+			if !removed {
+				return fmt.Errorf("secret '%s' not found", name)
+			}
+			fmt.Printf("[+] Removed secret '%s'\n", name)
+			return nil
+		},
+	}
+}
+
+// encryptCmd encrypts config sections at rest so the config dir can be synced safely.
+func encryptCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "encrypt",
+		Usage:     "Encrypt config sections at rest with a key kept in the OS keychain (default: secrets and db)",
+		ArgsUsage: "[section...]",
+		Action: func(c *cli.Context) error {
+			sections := c.Args().Slice()
+			if len(sections) == 0 {
+				sections = []string{"secrets", "db"}
+			}
+			for _, section := range sections {
+				if err := config.SetEncrypted(section, true); err != nil {
+					return fmt.Errorf("failed to encrypt %s: %w", section, err)
+				}
+				fmt.Printf("[+] Encrypted '%s'\n", section)
+			}
+			fmt.Printf("Other machines need the key: copy 'aio secrets key' output to 'aio secrets key --set' there, or export %s\n", config.KeyEnv)
+			return nil
+		},
+	}
+}
+
+func decryptCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "decrypt",
+		Usage:     "Store encrypted config sections in plain text again (default: all)",
+		ArgsUsage: "[section...]",
+		Action: func(c *cli.Context) error {
+			sections := c.Args().Slice()
+			if len(sections) == 0 {
+				sections = config.EncryptedSections()
+			}
+			if len(sections) == 0 {
+				fmt.Println("[!] No encrypted sections")
+				return nil
+			}
+			for _, section := range sections {
+				if err := config.SetEncrypted(section, false); err != nil {
+					return fmt.Errorf("failed to decrypt %s: %w", section, err)
+				}
+				fmt.Printf("[+] Decrypted '%s'\n", section)
+			}
+			return nil
+		},
+	}
+}
+
+// keyCmd prints or imports the encryption key, to share encrypted config between machines.
+func keyCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "key",
+		Usage: "Print the config encryption key, or store one from another machine with --set",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "set",
+				Usage: "Read a key (without echo) and store it in the keychain",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.Bool("set") {
+				key, err := prompt.Password("Key:")
+				if err != nil {
+					return fmt.Errorf("input cancelled: %w", err)
+				}
+				if err := config.SetKey(key); err != nil {
+					return err
+				}
+				fmt.Println("[+] Stored the encryption key")
+				return nil
+			}
+			key, err := config.Key()
+			if err != nil {
+				return err
+			}
+			fmt.Println(key)
+			return nil
+		},
+	}
+}
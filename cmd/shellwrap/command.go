@@ -0,0 +1,171 @@
+package shellwrap
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/shellwrap"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// installer is recorded in the markers of the wrappers this command writes.
+const installer = "aio shellwrap"
+
+// validName matches the function names every supported shell accepts.
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// Command returns the shellwrap command which installs a shell function that
+// cd's into the directory printed by an aio command.
+func Command() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:      "shellwrap",
+		Usage:     "Install a shell function that runs an aio command and cd's into the directory it prints",
+		ArgsUsage: "<command...>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "name",
+				Aliases: []string{"n"},
+				Usage:   "Name of the shell function (default: the command words joined with '-')",
+			},
+			&cli.StringFlag{
+				Name:    "shell",
+				Aliases: []string{"s"},
+				Usage:   "Override shell detection (" + strings.Join(shellwrap.Shells, ", ") + ")",
+			},
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "Replace an installed wrapper (e.g. to change its command)",
+			},
+			&cli.BoolFlag{
+				Name:    "print",
+				Aliases: []string{"p"},
+				Usage:   "Print the function instead of installing it",
+			},
+			&cli.BoolFlag{
+				Name:    "remove",
+				Aliases: []string{"r"},
+				Usage:   "Remove the wrapper called --name (or named after the command)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			args := c.Args().Slice()
+			name := c.String("name")
+			if len(args) == 0 && (name == "" || !c.Bool("remove")) {
+				return listWrappers(c.String("shell"))
+			}
+			if name == "" {
+				name = strings.Join(args, "-")
+			}
+			if !validName.MatchString(name) {
+				return fmt.Errorf("invalid function name %q, pass --name", name)
+			}
+			if len(args) > 0 && args[0] == "aio" {
+				args = args[1:]
+			}
+
+			rc, err := shellwrap.Locate(c.String("shell"), name)
+			if err != nil {
+				return err
+			}
+			block := shellwrap.NewBlock(name, installer)
+			if c.Bool("remove") {
+				return removeWrapper(rc, name, block)
+			}
+			if len(args) == 0 {
+				return fmt.Errorf("missing the aio command to wrap")
+			}
+			snippet := shellwrap.Snippet(rc.Shell, name, args)
+			if c.Bool("print") {
+				fmt.Println(snippet)
+				return nil
+			}
+			return installWrapper(c, rc, name, block, snippet)
+		},
+	}, `A command can't change the directory of the shell that started it, so commands that pick a
+directory (a worktree, a scratch workspace, a fresh clone) print its path instead. The installed
+function runs the command with its own arguments and cd's into the path it prints; any other
+output is printed as is. Prompts of the wrapped command must go to the terminal, not stdout.
+
+The function is written between markers in the shell's rc file (functions/<name>.fish for fish),
+so --force replaces it and --remove deletes it without touching the rest of the file. Without
+arguments, lists the wrappers aio installed.`,
+		cmd.Example{Command: "aio shellwrap scratch", Comment: "'scratch go' creates a scratch project and cd's into it"},
+		cmd.Example{Command: "aio shellwrap --name sg scratch --git go", Comment: "'sg' for a Go scratch project with a repository"},
+		cmd.Example{Command: "aio shellwrap --print scratch", Comment: "Show the function instead of installing it"},
+		cmd.Example{Command: "aio shellwrap --remove scratch", Comment: "Uninstall it"},
+		cmd.Example{Command: "aio shellwrap", Comment: "List installed wrappers"},
+	)
+}
+
+// installWrapper writes the block holding snippet to the rc file.
+func installWrapper(c *cli.Context, rc *shellwrap.RC, name string, block shellwrap.Block, snippet string) error {
+	installed, err := block.In(rc.File)
+	if err != nil {
+		return fmt.Errorf("cannot check %s: %w", rc.File, err)
+	}
+	if !installed {
+		// The same function may come from another installer, e.g. 'aio prj install'
+		names, err := shellwrap.Installed(rc.Shell)
+		if err != nil {
+			return fmt.Errorf("cannot check %s: %w", rc.File, err)
+		}
+		if slices.Contains(names, name) {
+			return fmt.Errorf("%s is already installed in %s by another command, choose another --name", name, rc.File)
+		}
+	}
+	if installed && !c.Bool("force") {
+		fmt.Printf("[!] %s wrapper is already installed in %s\n", name, rc.File)
+		fmt.Printf("    To replace it, run again with --force\n")
+		return nil
+	}
+	if installed {
+		if err := block.Remove(rc.File); err != nil {
+			return err
+		}
+	}
+	if err := block.Write(rc.File, snippet); err != nil {
+		return err
+	}
+
+	fmt.Printf("[+] Installed %s wrapper into %s\n\n", name, rc.File)
+	fmt.Printf("    Reload your shell to activate:\n")
+	fmt.Printf("      %s\n", rc.Reload)
+	return nil
+}
+
+// removeWrapper deletes the block of the wrapper called name from the rc file.
+func removeWrapper(rc *shellwrap.RC, name string, block shellwrap.Block) error {
+	installed, err := block.In(rc.File)
+	if err != nil {
+		return fmt.Errorf("cannot check %s: %w", rc.File, err)
+	}
+	if !installed {
+		return fmt.Errorf("no %s wrapper installed by %s in %s", name, installer, rc.File)
+	}
+	if err := block.Remove(rc.File); err != nil {
+		return err
+	}
+	fmt.Printf("[-] Removed %s wrapper from %s\n", name, rc.File)
+	fmt.Printf("    It stays defined in open shells until they restart\n")
+	return nil
+}
+
+// listWrappers prints the wrappers installed for shell.
+func listWrappers(shell string) error {
+	names, err := shellwrap.Installed(shell)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		fmt.Println("[!] No wrappers installed, e.g. 'aio shellwrap scratch'")
+		return nil
+	}
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package snip
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/snippet"
+	"cli-aio/internal/prompt"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	subcommands := []*cli.Command{
+		listCmd(),
+		runCmd(),
+		addCmd(),
+		removeCmd(),
+		importHistoryCmd(),
+	}
+
+	return &cli.Command{
+		Name:        "snip",
+		Usage:       "Named shell command snippets",
+		Subcommands: subcommands,
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 0 {
+				if !cmd.ValidateSubcommand(c, subcommands) {
+					return fmt.Errorf("unknown subcommand: %s", c.Args().First())
+				}
+				return nil
+			}
+			return prompt.SelectCommand(c, subcommands, "Select a subcommand:", cli.ShowSubcommandHelp)
+		},
+	}
+}
+
+func listCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List saved snippets",
+		Action: func(c *cli.Context) error {
+			store, err := snippet.Load()
+			if err != nil {
+				return err
+			}
+			if len(store.Snippets) == 0 {
+				fmt.Println("[!] No snippets. Use 'aio snip add' or 'aio snip import-history' to create some.")
+				return nil
+			}
+
+			width := 0
+			for _, s := range store.Snippets {
+				width = max(width, len(s.Name))
+			}
+			for _, s := range store.Snippets {
+				fmt.Printf("%-*s  %s\n", width, s.Name, oneLine(s.Command))
+				if s.Description != "" {
+					fmt.Printf("%-*s  # %s\n", width, "", s.Description)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func runCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "run",
+		Usage:     "Run a snippet in the current directory, extra arguments are appended",
+		ArgsUsage: "[name] [args...]",
+		Action: func(c *cli.Context) error {
+			store, err := snippet.Load()
+			if err != nil {
+				return err
+			}
+			s, err := findOrSelect(store, c.Args().First(), "Snippet to run:")
+			if err != nil {
+				return err
+			}
+
+			command := s.Command
+			if c.Args().Len() > 1 {
+				command += " " + strings.Join(c.Args().Slice()[1:], " ")
+			}
+			fmt.Fprintf(os.Stderr, "-> %s\n", oneLine(command))
+			run := exec.Command("sh", "-c", command)
+			run.Stdin = os.Stdin
+			run.Stdout = os.Stdout
+			run.Stderr = os.Stderr
+			if err := run.Run(); err != nil {
+				return fmt.Errorf("snippet %s failed: %w", s.Name, err)
+			}
+			return nil
+		},
+	}
+}
+
+func addCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "add",
+		Usage:     "Save a command as a snippet",
+		ArgsUsage: "[name] [command...]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "description",
+				Aliases: []string{"d"},
+				Usage:   "What the snippet does",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			var err error
+			name := c.Args().First()
+			if name == "" {
+				if name, err = prompt.Input("Snippet name:", "", true); err != nil {
+					return fmt.Errorf("input cancelled: %w", err)
+				}
+			}
+			command := ""
+			if c.Args().Len() > 1 {
+				command = strings.Join(c.Args().Slice()[1:], " ")
+			} else if command, err = prompt.Input("Command:", "", true); err != nil {
+				return fmt.Errorf("input cancelled: %w", err)
+			}
+
+			store, err := snippet.Load()
+			if err != nil {
+				return err
+			}
+			if _, err := snippet.Add(store, name, command, c.String("description")); err != nil {
+				return err
+			}
+			if err := snippet.Save(store); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Saved snippet %s\n", name)
+			return nil
+		},
+	}
+}
+
+func removeCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "rm",
+		Usage:     "Remove a snippet",
+		ArgsUsage: "[name]",
+		Action: func(c *cli.Context) error {
+			store, err := snippet.Load()
+			if err != nil {
+				return err
+			}
+			s, err := findOrSelect(store, c.Args().First(), "Snippet to remove:")
+			if err != nil {
+				return err
+			}
+			name := s.Name
+			if err := snippet.Remove(store, name); err != nil {
+				return err
+			}
+			if err := snippet.Save(store); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Removed snippet %s\n", name)
+			return nil
+		},
+	}
+}
+
+// findOrSelect returns the snippet called name, or asks for one when name is empty.
+func findOrSelect(store *snippet.Store, name string, message string) (*snippet.Snippet, error) {
+	if name != "" {
+		s := snippet.Find(store, name)
+		if s == nil {
+			return nil, fmt.Errorf("snippet '%s' not found", name)
+		}
+		return s, nil
+	}
+	if len(store.Snippets) == 0 {
+		return nil, fmt.Errorf("no snippets, use 'aio snip add' to create one")
+	}
+	labels := make([]string, len(store.Snippets))
+	for i, s := range store.Snippets {
+		labels[i] = fmt.Sprintf("%s  %s", s.Name, oneLine(s.Command))
+	}
+	idx, _, err := prompt.SelectWithFuzzy(message, labels, "", true)
+	if err != nil {
+		return nil, fmt.Errorf("selection cancelled: %w", err)
+	}
+	return &store.Snippets[idx], nil
+}
+
+// oneLine shows a multi-line command on one line.
+func oneLine(command string) string {
+	return strings.ReplaceAll(command, "\n", " ⏎ ")
+}
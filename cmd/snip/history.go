@@ -0,0 +1,167 @@
+package snip
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/snippet"
+	"cli-aio/internal/prompt"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// importHistoryCmd offers the commands run most often as snippets.
+func importHistoryCmd() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:  "import-history",
+		Usage: "Save frequently used commands from the shell history as snippets",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "shell",
+				Usage: "History format: bash, zsh or fish (default: from $SHELL)",
+			},
+			&cli.StringFlag{
+				Name:    "file",
+				Aliases: []string{"f"},
+				Usage:   "History file (default: the shell's usual location)",
+			},
+			&cli.IntFlag{
+				Name:  "min-count",
+				Usage: "Only offer commands run at least this many times",
+				Value: 3,
+			},
+			&cli.IntFlag{
+				Name:  "min-length",
+				Usage: "Only offer commands at least this long",
+				Value: 25,
+			},
+			&cli.IntFlag{
+				Name:  "limit",
+				Usage: "Offer at most this many commands",
+				Value: 30,
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Save every offered command under its suggested name without asking",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			shell := c.String("shell")
+			if shell == "" {
+				if shell = snippet.DetectShell(); shell == "" {
+					return fmt.Errorf("cannot tell the shell from $SHELL, use --shell bash|zsh|fish")
+				}
+			}
+			file := c.String("file")
+			if file == "" {
+				var err error
+				if file, err = snippet.HistoryFile(shell); err != nil {
+					return err
+				}
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read history: %w", err)
+			}
+
+			store, err := snippet.Load()
+			if err != nil {
+				return err
+			}
+			var candidates []snippet.Candidate
+			for _, candidate := range snippet.Cluster(snippet.ParseHistory(shell, data), c.Int("min-length"), c.Int("min-count")) {
+				if snippet.HasCommand(store, candidate.Command) || strings.HasPrefix(candidate.Command, "aio snip") {
+					continue
+				}
+				candidates = append(candidates, candidate)
+			}
+			if len(candidates) == 0 {
+				fmt.Printf("[!] No new command of %d+ characters was run %d+ times in %s\n", c.Int("min-length"), c.Int("min-count"), file)
+				return nil
+			}
+			candidates = candidates[:min(len(candidates), c.Int("limit"))]
+
+			chosen := candidates
+			if !c.Bool("yes") {
+				if chosen, err = selectCandidates(candidates); err != nil {
+					return err
+				}
+			}
+
+			saved := 0
+			for _, candidate := range chosen {
+				name := snippet.SuggestName(store, candidate.Command)
+				if !c.Bool("yes") {
+					if name, err = askName(store, candidate.Command, name); err != nil {
+						return err
+					}
+				}
+				if _, err := snippet.Add(store, name, candidate.Command, ""); err != nil {
+					return err
+				}
+				saved++
+			}
+			if saved == 0 {
+				fmt.Println("[!] Nothing saved")
+				return nil
+			}
+			if err := snippet.Save(store); err != nil {
+				return err
+			}
+			fmt.Printf("[+] Saved %d snippet(s), run them with 'aio snip run <name>'\n", saved)
+			return nil
+		},
+	}, `Reads the bash, zsh (plain or extended) or fish history, groups identical commands and offers
+the long ones run most often. Pick the commands to keep, then name each (a name is suggested
+from its first words). Commands already saved, and commands with an inline password, token,
+secret or API key, are not offered.`,
+		cmd.Example{Command: "aio snip import-history", Comment: "From the history of the current shell"},
+		cmd.Example{Command: "aio snip import-history --shell zsh --min-count 10", Comment: "Only commands run 10+ times"},
+		cmd.Example{Command: "aio snip import-history -f ~/old_history --shell bash -y", Comment: "Save all with suggested names"},
+	)
+}
+
+// selectCandidates asks which of the candidates to save.
+func selectCandidates(candidates []snippet.Candidate) ([]snippet.Candidate, error) {
+	labels := make([]string, len(candidates))
+	byLabel := map[string]snippet.Candidate{}
+	for i, candidate := range candidates {
+		command := oneLine(candidate.Command)
+		if len(command) > 100 {
+			command = command[:97] + "..."
+		}
+		labels[i] = fmt.Sprintf("%4dx  %s", candidate.Count, command)
+		byLabel[labels[i]] = candidate
+	}
+	selected, err := prompt.MultiSelect("Commands to save as snippets:", labels, nil)
+	if err != nil {
+		return nil, fmt.Errorf("selection cancelled: %w", err)
+	}
+	chosen := make([]snippet.Candidate, len(selected))
+	for i, label := range selected {
+		chosen[i] = byLabel[label]
+	}
+	return chosen, nil
+}
+
+// askName asks for the name of a new snippet until it is valid and free.
+func askName(store *snippet.Store, command string, suggested string) (string, error) {
+	fmt.Printf("\n  %s\n", oneLine(command))
+	for {
+		name, err := prompt.Input("Snippet name:", suggested, true)
+		if err != nil {
+			return "", fmt.Errorf("input cancelled: %w", err)
+		}
+		if err := snippet.ValidateName(name); err != nil {
+			fmt.Printf("[-] %v\n", err)
+			continue
+		}
+		if snippet.Find(store, name) != nil {
+			fmt.Printf("[-] Snippet '%s' already exists\n", name)
+			continue
+		}
+		return name, nil
+	}
+}
@@ -0,0 +1,126 @@
+package task
+
+import (
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/taskrunner"
+	"cli-aio/internal/prompt"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Command returns the task command, a uniform "what can I run here" entry
+// point over Makefile targets, package.json scripts and Taskfile tasks.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:      "task",
+		Usage:     "List and run Makefile targets, package.json scripts or Taskfile tasks of the current project",
+		ArgsUsage: "[task]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "list",
+				Aliases: []string{"l"},
+				Usage:   "Only print the available tasks",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			dir, err := projectRoot(c.Context)
+			if err != nil {
+				return err
+			}
+			tasks, err := taskrunner.Discover(dir)
+			if err != nil {
+				return err
+			}
+			if len(tasks) == 0 {
+				return fmt.Errorf("no Makefile, package.json scripts or Taskfile found in %s", dir)
+			}
+
+			if c.Bool("list") {
+				for _, t := range tasks {
+					fmt.Println(taskLabel(t))
+				}
+				return nil
+			}
+
+			var selected taskrunner.Task
+			if name := c.Args().First(); name != "" {
+				t, err := findTask(tasks, name)
+				if err != nil {
+					return err
+				}
+				selected = t
+			} else {
+				labels := make([]string, len(tasks))
+				for i, t := range tasks {
+					labels[i] = taskLabel(t)
+				}
+				idx, _, err := prompt.Select("Select task:", labels, "")
+				if err != nil {
+					return fmt.Errorf("selection cancelled: %w", err)
+				}
+				selected = tasks[idx]
+			}
+
+			bin, args := selected.Command(dir)
+			if _, err := exec.LookPath(bin); err != nil {
+				return fmt.Errorf("%s is not installed (needed to run %s tasks)", bin, selected.Source)
+			}
+			fmt.Fprintf(os.Stderr, "-> %s %s\n", bin, strings.Join(args, " "))
+			run := exec.Command(bin, args...)
+			run.Dir = dir
+			run.Stdin = os.Stdin
+			run.Stdout = os.Stdout
+			run.Stderr = os.Stderr
+			return run.Run()
+		},
+	}
+}
+
+// projectRoot returns the git repository root, or the current directory outside of a repository.
+func projectRoot(ctx context.Context) (string, error) {
+	if root, err := git.GetTopLevel(ctx); err == nil {
+		return root, nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine current directory: %w", err)
+	}
+	return wd, nil
+}
+
+// findTask looks a task up by name, or by "source:name" when several sources define it.
+func findTask(tasks []taskrunner.Task, name string) (taskrunner.Task, error) {
+	matches := matchTasks(tasks, "", name)
+	// npm scripts often contain colons (build:prod), so only treat the prefix
+	// as a source when nothing matches the full name
+	if source, n, ok := strings.Cut(name, ":"); ok && len(matches) == 0 {
+		matches = matchTasks(tasks, source, n)
+	}
+	switch len(matches) {
+	case 0:
+		return taskrunner.Task{}, fmt.Errorf("task not found: %s", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return taskrunner.Task{}, fmt.Errorf("task '%s' is defined by several sources, use <source>:%s (make, npm, task)", name, name)
+	}
+}
+
+func matchTasks(tasks []taskrunner.Task, source string, name string) []taskrunner.Task {
+	var matches []taskrunner.Task
+	for _, t := range tasks {
+		if t.Name == name && (source == "" || t.Source == source) {
+			matches = append(matches, t)
+		}
+	}
+	return matches
+}
+
+func taskLabel(t taskrunner.Task) string {
+	return strings.TrimSpace(fmt.Sprintf("%-5s %-20s %s", t.Source, t.Name, t.Description))
+}
@@ -0,0 +1,207 @@
+package tour
+
+import (
+	"cli-aio/internal/cmd"
+	"cli-aio/internal/pkg/sandbox"
+	"cli-aio/internal/prompt"
+	"cli-aio/internal/ui"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// tourRemote is the origin URL of the sandbox: a GitLab-like URL so
+// commands reading the project path work, rewritten to the local bare origin.
+const tourRemote = "git@gitlab.example.com:tour/demo-service.git"
+
+// step is one workflow of the tour: what it is for and the commands to try.
+type step struct {
+	title string
+	text  string
+	// setup prepares the sandbox before the commands, e.g. checks out a branch
+	setup    [][]string
+	commands [][]string
+}
+
+var steps = []step{
+	{
+		title: "Projects",
+		text: `aio remembers the repositories on your laptop, so you can jump between them by name.
+'prj add' saves a folder, 'prj git-add <dir>' every repository under it. 'prj cd' picks one and
+prints its path: the shell wrapper ('aio prj install') turns that into a real cd.`,
+		commands: [][]string{{"prj", "add", "."}, {"prj", "cd"}},
+	},
+	{
+		title: "Checkout list",
+		text: `'git ckl' lists the local and remote branches in a fuzzy picker, then checks out, deletes,
+renames or branches from the one you pick. Type to filter, e.g. "login" for the feature branch.`,
+		commands: [][]string{{"git", "ckl"}},
+	},
+	{
+		title: "Reverse merge",
+		text: `'git rmerge' merges the current branch into the targets you pick (develop, qc, ...), one after
+the other, then comes back. It stops at the first conflict unless told to skip it. The tour
+checks out the feature branch first.`,
+		setup:    [][]string{{"git", "checkout", "-q", "feature/ABC-123-login-form"}},
+		commands: [][]string{{"git", "rmerge"}},
+	},
+	{
+		title: "Tagging",
+		text: `'ztag qc' bumps the latest tag for QC and pushes it, after checking the branch policy,
+freezes and pre-tag checks. stg and prod also create the GitLab release with the Jira ticket,
+which the sandbox can't do. 'ztag deployments' shows what was tagged for each environment.`,
+		setup:    [][]string{{"git", "checkout", "-q", "develop"}},
+		commands: [][]string{{"ztag", "qc"}, {"ztag", "deployments"}},
+	},
+}
+
+// Command returns the tour command.
+func Command() *cli.Command {
+	return cmd.Describe(&cli.Command{
+		Name:  "tour",
+		Usage: "Walk through the core workflows (prj, ckl, rmerge, ztag) in a sandbox repository",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "dir",
+				Usage: "Build the sandbox in this empty directory (default: a temporary one)",
+			},
+			&cli.BoolFlag{
+				Name:  "keep",
+				Usage: "Keep the sandbox after the tour to keep trying commands in it",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			exe, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("cannot locate the aio binary: %w", err)
+			}
+			root := c.String("dir")
+			if root == "" {
+				if root, err = os.MkdirTemp("", "aio-tour-"); err != nil {
+					return fmt.Errorf("failed to create a directory: %w", err)
+				}
+			}
+			if root, err = filepath.Abs(root); err != nil {
+				return err
+			}
+			repo, home, err := buildSandbox(c.Context, root)
+			if err != nil {
+				return err
+			}
+			keep := c.Bool("keep") || c.String("dir") != ""
+			if !keep {
+				defer os.RemoveAll(root)
+			}
+
+			fmt.Printf("[+] Built a sandbox service in %s\n", repo)
+			fmt.Println("    Commands run with their own home, your projects, config and tokens are not touched.")
+			for i, s := range steps {
+				fmt.Println()
+				fmt.Println(ui.Colorize(ui.Bold, fmt.Sprintf("[%d/%d] %s", i+1, len(steps), s.title)))
+				fmt.Println(s.text)
+				for _, args := range s.setup {
+					if err := run(repo, home, args[0], args[1:], false); err != nil {
+						return err
+					}
+				}
+				for _, args := range s.commands {
+					line := "aio " + strings.Join(args, " ")
+					if cmd.Stopping(c.Context) {
+						return fmt.Errorf("tour interrupted")
+					}
+					_, action, err := prompt.SelectWithFuzzy(fmt.Sprintf("$ %s", line), []string{"Run it", "Skip", "End the tour"}, "Run it", false)
+					if err != nil {
+						return fmt.Errorf("selection cancelled: %w", err)
+					}
+					switch action {
+					case "Skip":
+						continue
+					case "End the tour":
+						return finish(root, repo, keep)
+					}
+					if err := run(repo, home, exe, args, true); err != nil {
+						fmt.Printf("[!] %s failed: %v, the tour goes on\n", line, err)
+					}
+				}
+			}
+			return finish(root, repo, keep)
+		},
+	}, `
+Builds a throwaway service repository (the demo of 'aio devtool mkrepo': develop, qc, staging, a
+feature branch, environment tags and a local origin) and walks through the everyday workflows on
+it: saving and jumping to projects, the checkout list, reverse merges and QC tags. Each command is
+explained, then run for real on the sandbox, skipped, or the tour ended. The commands run with a
+home of their own and without GITLAB_PRIVATE_TOKEN, so nothing outside the sandbox changes. The
+sandbox is removed afterwards unless --keep or --dir is given.`,
+		cmd.Example{Command: "aio tour", Comment: "Take the tour"},
+		cmd.Example{Command: "aio tour --dir /tmp/tour", Comment: "Keep the sandbox in /tmp/tour to explore it afterwards"},
+	)
+}
+
+// buildSandbox builds the demo repository in root/repo, its origin posing as
+// a GitLab project, and the home the commands of the tour run with.
+func buildSandbox(ctx context.Context, root string) (string, string, error) {
+	repo, err := sandbox.Build(ctx, root, sandbox.DemoSpec())
+	if err != nil {
+		return "", "", err
+	}
+	home := filepath.Join(root, "home")
+	if err := os.Mkdir(home, 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create the tour home: %w", err)
+	}
+	for _, args := range [][]string{
+		{"config", "user.name", "Tour"},
+		{"config", "user.email", "tour@example.com"},
+		{"config", "remote.origin.url", tourRemote},
+		{"config", "url." + repo.Origin + ".insteadOf", tourRemote},
+	} {
+		if err := run(repo.Dir, home, "git", args, false); err != nil {
+			return "", "", err
+		}
+	}
+	return repo.Dir, home, nil
+}
+
+// run runs a command in the sandbox with the tour home, attached to the
+// terminal when interactive.
+func run(dir string, home string, name string, args []string, interactive bool) error {
+	command := exec.Command(name, args...)
+	command.Dir = dir
+	command.Env = append(tourEnv(), "HOME="+home, "AIO_NO_DAEMON=1")
+	if interactive {
+		command.Stdin, command.Stdout, command.Stderr = os.Stdin, os.Stdout, os.Stderr
+		return command.Run()
+	}
+	if output, err := command.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s %s failed: %w\n%s", name, strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// tourEnv is the environment without HOME and the tokens, so the commands
+// of the tour can't reach the user's state or GitLab.
+func tourEnv() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if name == "HOME" || name == "GITLAB_PRIVATE_TOKEN" || name == "AIO_JIRA_TOKEN" {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
+
+func finish(root string, repo string, keep bool) error {
+	fmt.Println()
+	fmt.Println("[+] That's the tour. 'aio <command> --help' explains every command with examples.")
+	if keep {
+		fmt.Printf("    The sandbox stays in %s, remove it with: rm -rf %s\n", repo, root)
+	}
+	return nil
+}
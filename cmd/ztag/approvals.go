@@ -0,0 +1,90 @@
+package ztag
+
+import (
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/gitlab"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// CheckApprovals verifies, when ztag.approvals configures the project, that
+// the merged MR bringing commit into main has enough approvals before it is
+// tagged for prod: the CLI enforces the same gate as the GitLab UI. Progress
+// is written to out.
+func CheckApprovals(ctx context.Context, out io.Writer, env Env, commit string) error {
+	gc := git.NewClient()
+	if env != EnvProd {
+		return nil
+	}
+	projectID, _ := gc.ProjectID(ctx)
+	required, source := requiredApprovals(out, projectID)
+	if required <= 0 {
+		return nil
+	}
+	gate := fmt.Sprintf("ztag.approvals[%q] requires %d approval(s)", source, required)
+
+	client, err := gitlab.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot check the approvals, %s: %w", gate, err)
+	}
+	mrs, err := client.CommitMergeRequests(projectID, commit)
+	if err != nil {
+		return fmt.Errorf("cannot check the approvals, %s: %w", gate, err)
+	}
+	mr := releaseMergeRequest(mrs)
+	if mr == nil {
+		return fmt.Errorf("no merged merge request brings %s into main, but %s", git.Commit{SHA: commit}.Short(), gate)
+	}
+	approvals, err := client.MergeRequestApprovals(mr.ProjectID, mr.IID)
+	if err != nil {
+		return fmt.Errorf("cannot check the approvals of %s: %w", mr.References.Full, err)
+	}
+
+	var approvers []string
+	for _, a := range approvals.ApprovedBy {
+		approvers = append(approvers, a.User.Username)
+	}
+	if len(approvers) < required {
+		return fmt.Errorf("%s has %d approval(s), %s: %s", mr.References.Full, len(approvers), gate, mr.WebURL)
+	}
+	fmt.Fprintf(out, "[+] %s approved by %s\n", mr.References.Full, strings.Join(approvers, ", "))
+	return nil
+}
+
+// requiredApprovals returns the approvals configured for the project and the
+// key they come from (the project path or "*"). 0 means no gate.
+func requiredApprovals(out io.Writer, projectID string) (int, string) {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(out, "[!] Warning: Failed to load the approval rules: %v\n", err)
+		return 0, ""
+	}
+	for _, key := range []string{projectID, "*"} {
+		if n, ok := cfg.ZTag.Approvals[key]; ok && key != "" {
+			return n, key
+		}
+	}
+	return 0, ""
+}
+
+// releaseMergeRequest picks, among the MRs containing a commit, the merged
+// one targeting main/master, or any merged one (e.g. into a release branch).
+func releaseMergeRequest(mrs []gitlab.MergeRequest) *gitlab.MergeRequest {
+	var merged *gitlab.MergeRequest
+	for i, mr := range mrs {
+		if mr.State != "merged" {
+			continue
+		}
+		if slices.Contains(defaultProdBranches, mr.TargetBranch) {
+			return &mrs[i]
+		}
+		if merged == nil {
+			merged = &mrs[i]
+		}
+	}
+	return merged
+}
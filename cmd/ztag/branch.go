@@ -0,0 +1,77 @@
+package ztag
+
+import (
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/release"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// defaultProdBranches are the branches prod may be deployed from when no policy is configured.
+var defaultProdBranches = []string{"main", "master"}
+
+// CheckDeployBranch verifies that HEAD may be tagged for env, in order:
+//
+//  1. A release branch cut with 'aio git cut-release' may only be deployed
+//     to the environments it was cut for.
+//  2. When ztag.policies configures the env for the project (or "*"), the
+//     branch must match one of its patterns.
+//  3. Otherwise prod is deployed from main/master or a release branch mapped
+//     to prod, and the other environments from any branch.
+//
+// Progress is written to out.
+func CheckDeployBranch(ctx context.Context, out io.Writer, env Env, head git.HeadInfo) error {
+	gc := git.NewClient()
+	projectID, _ := gc.ProjectID(ctx)
+
+	var branch *release.Branch
+	if head.Branch != "" && projectID != "" {
+		branch, _ = release.FindBranch(projectID, head.Branch)
+	}
+	if branch != nil && !branch.HasEnv(string(env)) {
+		return fmt.Errorf("release branch %s is not mapped to %s environment (mapped: %s)", branch.Name, string(env), strings.Join(branch.Envs, ", "))
+	}
+
+	if patterns, source := branchPolicy(out, projectID, env); patterns != nil {
+		if !matchBranch(patterns, head.Branch) {
+			return fmt.Errorf("HEAD is %s, but policy ztag.policies[%q].%s only allows branches %s", head, source, string(env), strings.Join(patterns, ", "))
+		}
+	} else if env == EnvProd && branch == nil && !matchBranch(defaultProdBranches, head.Branch) {
+		return fmt.Errorf("only main/master or release branches are allowed to be deployed to %s environment (HEAD is %s)", string(env), head)
+	}
+
+	if branch != nil {
+		fmt.Fprintf(out, "Deploying release branch %s (%s) to %s\n", branch.Name, branch.Version, string(env))
+	}
+	return nil
+}
+
+// branchPolicy returns the configured branch patterns for env and the policy
+// key they come from (the project path or "*"). nil means no policy.
+func branchPolicy(out io.Writer, projectID string, env Env) ([]string, string) {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(out, "[!] Warning: Failed to load branch policy: %v\n", err)
+		return nil, ""
+	}
+	for _, key := range []string{projectID, "*"} {
+		if patterns, ok := cfg.ZTag.Policies[key][string(env)]; ok && key != "" {
+			return patterns, key
+		}
+	}
+	return nil, ""
+}
+
+// matchBranch reports whether branch matches one of the glob patterns (e.g. release/*).
+func matchBranch(patterns []string, branch string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return true
+		}
+	}
+	return false
+}
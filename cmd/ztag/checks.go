@@ -0,0 +1,82 @@
+package ztag
+
+import (
+	"cli-aio/internal/pkg/audit"
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/git"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// RunChecks runs the pre-tag command of ztag.checks for the project at the
+// repository root and fails when it does, so a commit that doesn't pass the
+// local checks is never tagged. skip bypasses it. The outcome is recorded in
+// the audit history ('aio git audit'). The command and progress are written
+// to out.
+func RunChecks(ctx context.Context, out io.Writer, env Env, skip bool) error {
+	gc := git.NewClient()
+	projectID, _ := gc.ProjectID(ctx)
+	command, source := checkCommand(out, projectID)
+	if command == "" {
+		return nil
+	}
+	root, err := gc.TopLevel(ctx)
+	if err != nil {
+		return err
+	}
+	entry := audit.Entry{Time: time.Now(), Dir: root, Command: []string{"sh", "-c", command}}
+	if skip {
+		fmt.Fprintf(out, "[!] Pre-tag check skipped: %s\n", command)
+		entry.Note = fmt.Sprintf("ztag %s: pre-tag check of ztag.checks[%q] skipped (--skip-checks)", string(env), source)
+		recordCheck(out, entry)
+		return nil
+	}
+
+	fmt.Fprintf(out, "-> %s\n", command)
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = root
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	entry.Duration = time.Since(entry.Time).Round(time.Millisecond).String()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		entry.Exit = exitErr.ExitCode()
+	} else if err != nil {
+		entry.Exit = -1
+	}
+	entry.Note = fmt.Sprintf("ztag %s: pre-tag check of ztag.checks[%q]", string(env), source)
+	recordCheck(out, entry)
+	if err != nil {
+		return fmt.Errorf("pre-tag check failed (%w), fix it or pass --skip-checks to tag anyway", err)
+	}
+	fmt.Fprintln(out, "[+] Pre-tag check passed")
+	return nil
+}
+
+// checkCommand returns the pre-tag command configured for the project and
+// the key it comes from (the project path or "*"). "" means no check.
+func checkCommand(out io.Writer, projectID string) (string, string) {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(out, "[!] Warning: Failed to load the pre-tag checks: %v\n", err)
+		return "", ""
+	}
+	for _, key := range []string{projectID, "*"} {
+		if command, ok := cfg.ZTag.Checks[key]; ok && key != "" {
+			return command, key
+		}
+	}
+	return "", ""
+}
+
+func recordCheck(out io.Writer, entry audit.Entry) {
+	if err := audit.Record(entry); err != nil {
+		fmt.Fprintf(out, "[!] Warning: %v\n", err)
+	}
+}
@@ -3,8 +3,13 @@ package ztag
 import (
 	"cli-aio/internal/cmd"
 	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/offline"
+	"cli-aio/internal/pkg/release"
 	"cli-aio/internal/prompt"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/urfave/cli/v2"
 )
@@ -37,13 +42,14 @@ type VersionInfo struct {
 }
 
 func Command() *cli.Command {
-	subcommands := []*cli.Command{
+	envCommands := []*cli.Command{
 		createGenerateTagCommand(EnvQC),
 		createGenerateTagCommand(EnvStg),
 		createGenerateTagCommand(EnvProd),
 	}
+	subcommands := append(envCommands, diffCmd(), deploymentsCmd(), notesCmd(), pruneCmd())
 
-	return &cli.Command{
+	return cmd.Describe(&cli.Command{
 		Name:  "ztag",
 		Usage: "Generate a new tag for a specific environment",
 		Flags: []cli.Flag{
@@ -52,14 +58,40 @@ func Command() *cli.Command {
 				Aliases: []string{"l"},
 				Usage:   "Level of the tag: b (default) for bug, m for minor and M for major",
 				Value:   "b",
+				EnvVars: []string{"AIO_TAG_LEVEL"},
+			},
+			&cli.StringFlag{
+				Name:    "env",
+				Aliases: []string{"e"},
+				Usage:   "Environment to tag (qc, stg or prod), instead of the subcommand",
+				EnvVars: []string{"AIO_TAG_ENV"},
+			},
+			&cli.StringFlag{
+				Name:    "ticket",
+				Usage:   "Jira ticket for the stg/prod release (default: ask)",
+				EnvVars: []string{"AIO_JIRA_TICKET"},
+			},
+			&cli.StringFlag{
+				Name:  "override",
+				Usage: "Release during a freeze window (release.freezes), giving the reason recorded in the audit history",
+			},
+			&cli.BoolFlag{
+				Name:  "skip-checks",
+				Usage: "Tag without running the pre-tag command of ztag.checks (recorded in the audit history)",
+			},
+			&cli.BoolFlag{
+				Name:    "ci",
+				Usage:   "Non-interactive mode for pipelines: never prompt, fail on missing input (auto-enabled by GITLAB_CI)",
+				EnvVars: []string{"GITLAB_CI"},
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the created tag as a JSON line on stdout (other output goes to stderr)",
 			},
 		},
 		Subcommands: subcommands,
+		Before:      cmd.Require(cmd.RequireGitRepo()),
 		Action: func(c *cli.Context) error {
-			if isGitRepo, err := git.CheckIfGitRepo(); err != nil || !isGitRepo {
-				return fmt.Errorf("not a git repository")
-			}
-
 			if c.Args().Len() > 0 {
 				// Validate subcommand exists
 				if !cmd.ValidateSubcommand(c, subcommands) {
@@ -69,26 +101,67 @@ func Command() *cli.Command {
 				return nil
 			}
 
-			projectID, err := git.ExtractProjectID()
+			if env := c.String("env"); env != "" {
+				for _, sub := range envCommands {
+					if sub.Name == env {
+						return sub.Action(c)
+					}
+				}
+				return fmt.Errorf("unknown environment: %s (expected qc, stg or prod)", env)
+			}
+
+			projectID, err := git.NewClient().ProjectID(c.Context)
 			if err != nil {
 				return err
 			}
-			fmt.Printf("Project ID: %s\n", projectID)
+			fmt.Fprintf(progressOutput(c), "Project ID: %s\n", projectID)
 
 			envs, ok := defaultEnvMap[projectID]
 			if ok {
+				// One round of remote queries for all the environments
+				remote, err := fetchRemote(c.Context, envs)
+				if err != nil {
+					return err
+				}
 				for _, env := range envs {
-					err = createGenerateTagCommand(env).Action(c)
-					if err != nil {
+					if err := runTag(c, env, remote); err != nil {
 						return err
 					}
 				}
 				return nil
 			}
 
-			return prompt.SelectCommand(c, subcommands, "Select a Environment:", cli.ShowSubcommandHelp)
+			if c.Bool("ci") {
+				return fmt.Errorf("environment is required in CI mode (--env or AIO_TAG_ENV)")
+			}
+			return prompt.SelectCommand(c, envCommands, "Select a Environment:", cli.ShowSubcommandHelp)
 		},
-	}
+	}, `Creates and pushes the next tag for an environment (qc, stg or prod), bumping the latest tag of
+that environment by --level. The branch is checked against the deploy policy, a tag that already
+exists is bumped to the next free one, and the deployment is recorded in the local ledger. In a
+freeze window of release.freezes (prod only by default, see 'aio release calendar') nothing is
+tagged unless --override gives a reason, recorded in the audit history. The pre-tag command of
+ztag.checks (e.g. "make test") must pass unless --skip-checks is given, both recorded in the audit
+history. With --ci (set by GitLab
+CI) nothing is prompted and missing input is an error; --json prints the result for scripts.`,
+		cmd.Example{Command: "aio ztag qc", Comment: "Next bug-fix tag for QC"},
+		cmd.Example{Command: "aio ztag -l m --ticket PAY-123 stg", Comment: "Minor bump for staging, linked to a ticket"},
+		cmd.Example{Command: "aio ztag --ci --json -e prod", Comment: "In a pipeline, JSON result on stdout"},
+		cmd.Example{Command: "aio ztag diff --jira qc stg", Comment: "What QC has that staging does not"},
+		cmd.Example{Command: "aio ztag notes prod --since 2026-10-01 -o notes.md", Comment: "Release notes of everything shipped to prod since October"},
+		cmd.Example{Command: "aio ztag prune --keep 20 --delete --remote", Comment: "Delete all but the 20 newest tags per environment"},
+	)
+}
+
+// tagResult is the machine-readable outcome of a ztag run (--json).
+type tagResult struct {
+	Env      string `json:"env"`
+	Tag      string `json:"tag"`
+	Previous string `json:"previous"`
+	Commit   string `json:"commit"`
+	Ticket   string `json:"ticket,omitempty"`
+	Released bool   `json:"released"`
+	Queued   bool   `json:"queued,omitempty"` // push or release deferred while offline
 }
 
 func createGenerateTagCommand(env Env) *cli.Command {
@@ -96,53 +169,147 @@ func createGenerateTagCommand(env Env) *cli.Command {
 		Name:  string(env),
 		Usage: fmt.Sprintf("Generate a new tag for %s environment", string(env)),
 		Action: func(c *cli.Context) error {
-			currentBranch, err := git.GetCurrentBranch()
-			if err != nil {
-				return err
-			}
-			if env == EnvProd && currentBranch != "main" && currentBranch != "master" {
-				return fmt.Errorf("only main/master branches are allowed to be deployed to %s environment", string(env))
-			}
+			return runTag(c, env, nil)
+		},
+	}
+}
 
-			latestTags, err := git.GetLatestTags(1)
-			if err != nil {
-				return err
-			}
+// runTag creates the tag for env, printing the result as JSON with --json.
+// remote is what was already fetched for it, nil to fetch it now.
+func runTag(c *cli.Context, env Env, remote *remoteState) error {
+	result, err := createTag(c, progressOutput(c), env, remote)
+	if err != nil || !c.Bool("json") {
+		return err
+	}
+	return json.NewEncoder(os.Stdout).Encode(result)
+}
 
-			nextTag, err := GenerateNextTag(latestTags[0], Level(c.String("level")), env)
-			if err != nil {
-				return err
-			}
+// progressOutput is where tagging reports its progress: stderr with --json,
+// to keep stdout for the JSON lines pipelines parse.
+func progressOutput(c *cli.Context) io.Writer {
+	if c.Bool("json") {
+		return os.Stderr
+	}
+	return os.Stdout
+}
 
-			fmt.Printf("Latest tag: %s, Next tag: %s\n", latestTags[0], nextTag)
-			err = git.CreateAndPushTag(nextTag, fmt.Sprintf("Release %s", nextTag))
-			if err != nil {
-				return err
-			}
+// createTag creates and pushes the next tag for env and, except for qc,
+// the GitLab release, writing its progress to out. With --ci nothing is
+// prompted.
+func createTag(c *cli.Context, out io.Writer, env Env, remote *remoteState) (*tagResult, error) {
+	gc := git.NewClient()
+	ci := c.Bool("ci")
+	head, err := gc.Head(c.Context)
+	if err != nil {
+		return nil, err
+	}
+	if ci {
+		head = ciHead(head)
+	}
+	if err := CheckDeployBranch(c.Context, out, env, head); err != nil {
+		return nil, err
+	}
+	if commit, err := gc.HeadCommit(c.Context); err != nil {
+		return nil, err
+	} else if err := CheckApprovals(c.Context, out, env, commit); err != nil {
+		return nil, err
+	}
+	if err := CheckFreeze(out, env, c.String("override")); err != nil {
+		return nil, err
+	}
+	if err := RunChecks(c.Context, out, env, c.Bool("skip-checks")); err != nil {
+		return nil, err
+	}
+	if head.Detached {
+		fmt.Fprintf(out, "[!] HEAD is %s, the tag will point to this commit\n", head)
+	}
 
-			// require user input jira ticket
-			if env == EnvQC {
-				return nil
-			}
+	// The ticket is needed after tagging, so a pipeline must fail before creating the tag
+	ticket := c.String("ticket")
+	if ci && env != EnvQC && ticket == "" {
+		return nil, fmt.Errorf("jira ticket is required for %s in CI mode (--ticket or AIO_JIRA_TICKET)", string(env))
+	}
 
-			jiraTicket, err := prompt.Input("Enter Jira ticket (required):", "", true)
-			if err != nil {
-				return err
-			}
+	if remote == nil {
+		if remote, err = fetchRemote(c.Context, []Env{env}); err != nil {
+			return nil, err
+		}
+	}
+	latestTag := "v0.0.0"
+	if len(remote.tags) > 0 {
+		latestTag = remote.tags[0]
+	}
+	checkDeployedTag(out, env, remote)
 
-			projectID, err := git.ExtractProjectID()
-			if err != nil {
-				return err
-			}
+	nextTag, err := GenerateNextTag(latestTag, Level(c.String("level")), env)
+	if err != nil {
+		return nil, err
+	}
+	if nextTag, err = EnsureFreeTag(c.Context, out, nextTag, env, ci); err != nil {
+		return nil, err
+	}
 
-			fmt.Printf("Release project with tag %s and Jira ticket %s\n", nextTag, jiraTicket)
-			err = git.CreateZalopayRelease(projectID, nextTag, jiraTicket)
-			if err != nil {
-				return err
-			}
-			fmt.Printf("Released %s successfully\n", nextTag)
+	fmt.Fprintf(out, "Latest tag: %s, Next tag: %s\n", latestTag, nextTag)
+	pushQueued, err := CreateAndPushTag(c.Context, out, nextTag, fmt.Sprintf("Release %s", nextTag))
+	if err != nil {
+		return nil, err
+	}
+	// The next environment tagged from the same state sees the new tag
+	remote.tags = append([]string{nextTag}, remote.tags...)
+	result := &tagResult{Env: string(env), Tag: nextTag, Previous: latestTag, Queued: pushQueued}
+	// Available to the next steps of 'aio do'
+	cmd.Publish("tag", nextTag)
+	cmd.Publish("previous_tag", latestTag)
+	cmd.Publish("env", string(env))
+	result.Commit, _ = gc.HeadCommit(c.Context)
 
-			return nil
-		},
+	// require user input jira ticket
+	if env == EnvQC {
+		if err := release.RecordDeployment(c.Context, string(env), nextTag, ""); err != nil {
+			fmt.Fprintf(out, "[!] Warning: Failed to record deployment: %v\n", err)
+		}
+		return result, nil
+	}
+
+	if ticket == "" {
+		ticket, err = PromptTicket(c.Context)
+		if err != nil {
+			return nil, err
+		}
+	}
+	result.Ticket = ticket
+
+	projectID, err := gc.ProjectID(c.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(out, "Release project with tag %s and Jira ticket %s\n", nextTag, ticket)
+	releaseQueued, err := offline.RunTo(out, releaseAction(projectID, nextTag, ticket), func() error {
+		return git.CreateZalopayRelease(c.Context, projectID, nextTag, ticket)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if releaseQueued {
+		result.Queued = true
+	} else {
+		fmt.Fprintf(out, "Released %s successfully\n", nextTag)
+		result.Released = true
+	}
+	if err := release.RecordDeployment(c.Context, string(env), nextTag, ticket); err != nil {
+		fmt.Fprintf(out, "[!] Warning: Failed to record deployment: %v\n", err)
+	}
+
+	return result, nil
+}
+
+// ciHead fills in the branch of a pipeline checkout: GitLab CI checks out
+// the commit detached, and names the branch in CI_COMMIT_BRANCH.
+func ciHead(head git.HeadInfo) git.HeadInfo {
+	if branch := os.Getenv("CI_COMMIT_BRANCH"); head.Detached && branch != "" {
+		head.Branch = branch
+		head.Detached = false
 	}
+	return head
 }
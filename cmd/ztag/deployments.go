@@ -0,0 +1,132 @@
+package ztag
+
+import (
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/pkg/jira"
+	"cli-aio/internal/pkg/release"
+	"cli-aio/internal/prompt"
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+// PromptTicket asks for the Jira ticket of a release, offering the tickets
+// given last, then completing the keys of the current branch, the recent
+// commits and the previous releases (Tab).
+func PromptTicket(ctx context.Context) (string, error) {
+	gc := git.NewClient()
+	keys := jira.RecentKeys(ctx)
+	if projectID, err := gc.ProjectID(ctx); err == nil {
+		if deployments, err := release.Deployments(projectID); err == nil {
+			for _, d := range deployments {
+				if d.Ticket != "" {
+					keys = append(keys, d.Ticket)
+				}
+			}
+		}
+	}
+	return prompt.InputWithHistory("jira-ticket", "Enter Jira ticket (required):", true, prompt.CompleteWords(keys))
+}
+
+// deploymentsCmd shows what is believed to be deployed on each environment.
+func deploymentsCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "deployments",
+		Usage: "Show the tag recorded as deployed on each environment",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "all",
+				Aliases: []string{"a"},
+				Usage:   "Show the deployment history instead of the current state",
+			},
+			&cli.BoolFlag{
+				Name:  "gitlab",
+				Usage: "Also show what GitLab reports as deployed on each environment",
+			},
+			&cli.IntFlag{
+				Name:  "limit",
+				Usage: "Number of history entries to show with --all",
+				Value: 20,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			projectID, err := git.NewClient().ProjectID(c.Context)
+			if err != nil {
+				return err
+			}
+			deployments, err := release.Deployments(projectID)
+			if err != nil {
+				return err
+			}
+			if len(deployments) == 0 {
+				fmt.Printf("[!] No deployments recorded for %s yet, they are recorded by 'aio ztag' and 'aio release'\n", projectID)
+				return nil
+			}
+
+			if c.Bool("all") {
+				if limit := c.Int("limit"); limit > 0 && len(deployments) > limit {
+					deployments = deployments[:limit]
+				}
+				for _, d := range deployments {
+					printDeployment(d)
+				}
+				return nil
+			}
+
+			var client *gitlab.Client
+			var cfg *config.Config
+			if c.Bool("gitlab") {
+				if client, err = gitlab.NewClient(c.Context); err != nil {
+					return err
+				}
+				if cfg, err = config.Load(); err != nil {
+					return err
+				}
+			}
+
+			envs := []Env{EnvQC, EnvStg, EnvProd}
+			gitlabDeployed := make([]*gitlab.Deployment, len(envs))
+			gitlabErrs := make([]error, len(envs))
+			if client != nil {
+				parallel(len(envs), func(i int) {
+					gitlabDeployed[i], gitlabErrs[i] = client.LatestDeployment(projectID, gitlabEnvironment(cfg, envs[i]))
+				})
+			}
+
+			current := release.Current(deployments)
+			for i, env := range envs {
+				d, ok := current[string(env)]
+				if ok {
+					printDeployment(d)
+				} else {
+					fmt.Printf("%-5s -\n", env)
+				}
+				if client == nil {
+					continue
+				}
+				deployed := gitlabDeployed[i]
+				switch {
+				case gitlabErrs[i] != nil:
+					fmt.Printf("      [!] GitLab: %v\n", gitlabErrs[i])
+				case deployed == nil:
+					fmt.Printf("      GitLab: no deployment\n")
+				case ok && deployed.Ref != d.Tag:
+					fmt.Printf("      [!] GitLab: %s deployed at %s\n", deployed.Ref, deployed.CreatedAt.Local().Format("2006-01-02 15:04"))
+				default:
+					fmt.Printf("      GitLab: %s\n", deployed.Ref)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func printDeployment(d release.Deployment) {
+	fmt.Printf("%-5s %-20s %s  %-16s %s\n", d.Env, d.Tag, d.CreatedAt.Local().Format("2006-01-02 15:04"), d.Author, d.Ticket)
+	if d.PipelineURL != "" {
+		fmt.Printf("      %s\n", d.PipelineURL)
+	}
+}
@@ -0,0 +1,153 @@
+package ztag
+
+import (
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/pkg/jira"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// diffCmd compares the latest tags of two environments, e.g. what qc has
+// that stg doesn't yet, as a report that can be pasted into chat.
+func diffCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "diff",
+		Usage:     "Show commits in the latest tag of one environment but not yet in another (promotion report)",
+		ArgsUsage: "<from-env> <to-env>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "mrs",
+				Usage: "Look up the merge requests of the commits on GitLab",
+			},
+			&cli.BoolFlag{
+				Name:  "jira",
+				Usage: "List the Jira keys mentioned in the commits",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			from, to := Env(c.Args().Get(0)), Env(c.Args().Get(1))
+			if from == "" || to == "" {
+				return fmt.Errorf("usage: aio ztag diff <from-env> <to-env>, e.g. aio ztag diff qc stg")
+			}
+
+			// The fetch brings the tags in for git log, ls-remote orders them
+			gc := git.NewClient()
+			var tags []string
+			var fetchErr, err error
+			parallel(2, func(i int) {
+				if i == 0 {
+					fetchErr = gc.FetchTags(c.Context)
+				} else {
+					tags, err = gc.RemoteTags(c.Context)
+				}
+			})
+			if fetchErr != nil {
+				fmt.Printf("[!] Warning: %v\n", fetchErr)
+			}
+			if err != nil {
+				return git.WithHint(err)
+			}
+			fromTag := LatestEnvTag(tags, from)
+			if fromTag == "" {
+				return fmt.Errorf("no %s tag found", string(from))
+			}
+			toTag := LatestEnvTag(tags, to)
+			if toTag == "" {
+				return fmt.Errorf("no %s tag found", string(to))
+			}
+
+			commits, err := gc.CommitsBetween(c.Context, toTag, fromTag)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Promotion %s -> %s: %s is %d commit(s) ahead of %s\n", from, to, fromTag, len(commits), toTag)
+			if len(commits) == 0 {
+				fmt.Printf("[+] %s already contains everything in %s\n", to, fromTag)
+				return nil
+			}
+
+			mrs := map[string]string{}
+			if c.Bool("mrs") {
+				mrs = commitMergeRequests(c.Context, commits)
+			}
+			for _, commit := range commits {
+				line := fmt.Sprintf("- %s %s", commit.Short(), commit.Subject)
+				if mr := mrs[commit.SHA]; mr != "" {
+					line += " (" + mr + ")"
+				}
+				fmt.Println(line)
+			}
+
+			if c.Bool("mrs") {
+				var refs []string
+				seen := map[string]bool{}
+				for _, commit := range commits {
+					if mr := mrs[commit.SHA]; mr != "" && !seen[mr] {
+						seen[mr] = true
+						refs = append(refs, mr)
+					}
+				}
+				fmt.Printf("\nMerge requests (%d): %s\n", len(refs), strings.Join(refs, ", "))
+			}
+			if c.Bool("jira") {
+				var subjects []string
+				for _, commit := range commits {
+					subjects = append(subjects, commit.Subject)
+				}
+				keys := jira.ExtractKeys(strings.Join(subjects, "\n"))
+				fmt.Printf("\nJira (%d): %s\n", len(keys), strings.Join(keys, ", "))
+			}
+			return nil
+		},
+	}
+}
+
+// commitMergeRequests maps commit SHAs to the "!iid title" of their MR,
+// looked up concurrently. Lookup failures only warn once and skip the commit,
+// the report is still useful without some MRs.
+func commitMergeRequests(ctx context.Context, commits []git.Commit) map[string]string {
+	gc := git.NewClient()
+	result := map[string]string{}
+	projectID, err := gc.ProjectID(ctx)
+	if err != nil {
+		fmt.Printf("[!] Warning: %v\n", err)
+		return result
+	}
+	client, err := gitlab.NewClient(ctx)
+	if err != nil {
+		fmt.Printf("[!] Warning: %v\n", err)
+		return result
+	}
+	refs := make([]string, len(commits))
+	errs := make([]error, len(commits))
+	parallel(len(commits), func(i int) {
+		mrs, err := client.CommitMergeRequests(projectID, commits[i].SHA)
+		if err != nil {
+			errs[i] = err
+		} else if len(mrs) > 0 {
+			refs[i] = fmt.Sprintf("!%d %s", mrs[0].IID, mrs[0].Title)
+		}
+	})
+	failed := 0
+	for i, commit := range commits {
+		if errs[i] != nil {
+			if failed == 0 {
+				fmt.Printf("[!] Warning: Failed to look up MRs of %s: %v\n", commit.Short(), errs[i])
+			}
+			failed++
+			continue
+		}
+		if refs[i] != "" {
+			result[commit.SHA] = refs[i]
+		}
+	}
+	if failed > 1 {
+		fmt.Printf("[!] Warning: Failed to look up MRs of %d commits\n", failed)
+	}
+	return result
+}
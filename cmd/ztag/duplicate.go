@@ -0,0 +1,66 @@
+package ztag
+
+import (
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/prompt"
+	"context"
+	"fmt"
+	"io"
+)
+
+// maxTagAttempts bounds the search for a free tag.
+const maxTagAttempts = 20
+
+// EnsureFreeTag returns tag when it doesn't exist yet, locally or on origin.
+// Otherwise it finds the next free patch version after it and, unless auto,
+// asks whether to use it, enter another tag or abort. Taken tags are reported
+// to out.
+func EnsureFreeTag(ctx context.Context, out io.Writer, tag string, env Env, auto bool) (string, error) {
+	gc := git.NewClient()
+	candidate := tag
+	for attempt := 0; ; attempt++ {
+		exists, err := gc.TagExists(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			break
+		}
+		if attempt == maxTagAttempts {
+			return "", fmt.Errorf("no free tag found after %s", tag)
+		}
+		fmt.Fprintf(out, "[!] Tag %s already exists\n", candidate)
+		if candidate, err = GenerateNextTag(candidate, LevelBug, env); err != nil {
+			return "", err
+		}
+	}
+	if candidate == tag || auto {
+		return candidate, nil
+	}
+
+	var (
+		useNext = fmt.Sprintf("Use %s", candidate)
+		enter   = "Enter another tag"
+		abort   = "Abort"
+	)
+	_, action, err := prompt.SelectWithFuzzy(fmt.Sprintf("Tag %s is taken:", tag), []string{useNext, enter, abort}, useNext, false)
+	if err != nil {
+		return "", fmt.Errorf("selection cancelled: %w", err)
+	}
+	switch action {
+	case useNext:
+		return candidate, nil
+	case enter:
+		custom, err := prompt.Input("Tag:", candidate, true)
+		if err != nil {
+			return "", fmt.Errorf("input cancelled: %w", err)
+		}
+		if exists, err := gc.TagExists(ctx, custom); err != nil {
+			return "", err
+		} else if exists {
+			return "", fmt.Errorf("tag %s already exists", custom)
+		}
+		return custom, nil
+	}
+	return "", fmt.Errorf("tagging aborted")
+}
@@ -0,0 +1,109 @@
+package ztag
+
+import (
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/pkg/offline"
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// gitlabEnvironment returns the GitLab environment name of env.
+func gitlabEnvironment(cfg *config.Config, env Env) string {
+	if name := cfg.ZTag.Environments[string(env)]; name != "" {
+		return name
+	}
+	return string(env)
+}
+
+// remoteState is what tagging needs from origin and GitLab, fetched once and
+// concurrently, so tagging several environments in a row doesn't wait for
+// each request in turn.
+type remoteState struct {
+	tags     []string
+	deployed map[Env]deployedLookup
+}
+
+// deployedLookup is what GitLab reports as deployed on an environment.
+type deployedLookup struct {
+	environment string
+	deployment  *gitlab.Deployment
+	err         error
+}
+
+// fetchRemote lists the remote tags and looks up the GitLab deployment of
+// each env concurrently. Deployments are skipped without a token.
+func fetchRemote(ctx context.Context, envs []Env) (*remoteState, error) {
+	gc := git.NewClient()
+	state := &remoteState{deployed: map[Env]deployedLookup{}}
+	lookups := make([]*deployedLookup, len(envs))
+	var tagsErr error
+	parallel(len(envs)+1, func(i int) {
+		if i == len(envs) {
+			state.tags, tagsErr = gc.RemoteTags(ctx)
+			return
+		}
+		lookups[i] = lookupDeployed(ctx, envs[i])
+	})
+	if tagsErr != nil {
+		return nil, tagsErr
+	}
+	for i, env := range envs {
+		if lookups[i] != nil {
+			state.deployed[env] = *lookups[i]
+		}
+	}
+	return state, nil
+}
+
+// lookupDeployed asks GitLab what is deployed on env, nil when it can't be
+// asked (no token, offline or outside a GitLab project).
+func lookupDeployed(ctx context.Context, env Env) *deployedLookup {
+	gc := git.NewClient()
+	if os.Getenv("GITLAB_PRIVATE_TOKEN") == "" || offline.Enabled() {
+		return nil
+	}
+	projectID, err := gc.ProjectID(ctx)
+	if err != nil {
+		return nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	client, err := gitlab.NewClient(ctx)
+	if err != nil {
+		return nil
+	}
+	lookup := &deployedLookup{environment: gitlabEnvironment(cfg, env)}
+	lookup.deployment, lookup.err = client.LatestDeployment(projectID, lookup.environment)
+	return lookup
+}
+
+// checkDeployedTag shows what GitLab says is deployed on env and warns when
+// it is not the latest env tag, i.e. the next tag would not be based on what
+// actually runs there, to out. It is informational and silently skipped
+// without a token.
+func checkDeployedTag(out io.Writer, env Env, state *remoteState) {
+	lookup, ok := state.deployed[env]
+	if !ok {
+		return
+	}
+	if lookup.err != nil {
+		fmt.Fprintf(out, "[!] Warning: Failed to get %s deployment from GitLab: %v\n", string(env), lookup.err)
+		return
+	}
+	deployment := lookup.deployment
+	if deployment == nil {
+		fmt.Fprintf(out, "[!] GitLab has no deployment for environment '%s'\n", lookup.environment)
+		return
+	}
+
+	fmt.Fprintf(out, "Deployed on %s (GitLab): %s at %s\n", string(env), deployment.Ref, deployment.CreatedAt.Local().Format("2006-01-02 15:04"))
+	if latest := LatestEnvTag(state.tags, env); latest != "" && latest != deployment.Ref {
+		fmt.Fprintf(out, "[!] The latest %s tag is %s, but GitLab shows %s deployed\n", string(env), latest, deployment.Ref)
+	}
+}
@@ -0,0 +1,46 @@
+package ztag
+
+import (
+	"cli-aio/internal/pkg/audit"
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/release"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// CheckFreeze fails when env is in a release freeze (release.freezes), unless
+// override gives a reason to release anyway, which is then recorded in the
+// audit history ('aio git audit'). Progress is written to out.
+func CheckFreeze(out io.Writer, env Env, override string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	window, err := release.Frozen(cfg.Release, string(env), time.Now())
+	if err != nil {
+		return err
+	}
+	if window == nil {
+		return nil
+	}
+	freeze := fmt.Sprintf("%s freeze (until %s)", window.Name, window.End.Format("Mon 2006-01-02 15:04"))
+	if strings.TrimSpace(override) == "" {
+		return fmt.Errorf("%s is in the %s, pass --override \"<reason>\" to release anyway", string(env), freeze)
+	}
+
+	fmt.Fprintf(out, "[!] Releasing to %s during the %s: %s\n", string(env), freeze, override)
+	dir, _ := os.Getwd()
+	entry := audit.Entry{
+		Time:    time.Now(),
+		Dir:     dir,
+		Command: append([]string{"aio"}, os.Args[1:]...),
+		Note:    fmt.Sprintf("%s override: %s", freeze, override),
+	}
+	if err := audit.Record(entry); err != nil {
+		fmt.Fprintf(out, "[!] Warning: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package ztag
+
+import (
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/pkg/release"
+	"cli-aio/internal/prompt"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+const notesDateLayout = "2006-01-02"
+
+// notesCmd aggregates the changelogs of the tags shipped to an environment
+// over a period into one Markdown document, e.g. for a sprint review.
+func notesCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "notes",
+		Usage:     "Aggregate the changelogs of the tags shipped to an environment into Markdown release notes",
+		ArgsUsage: "[env]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "since",
+				Usage: "First day of the period, e.g. 2026-10-01 (default: --days ago)",
+			},
+			&cli.StringFlag{
+				Name:  "until",
+				Usage: "Last day of the period (default: today)",
+			},
+			&cli.IntFlag{
+				Name:  "days",
+				Usage: "Length of the period when --since is not given",
+				Value: 14,
+			},
+			&cli.BoolFlag{
+				Name:  "mrs",
+				Usage: "Look up the merge requests of the commits on GitLab",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Write the notes to this file instead of stdout",
+			},
+			&cli.StringFlag{
+				Name:  "wiki",
+				Usage: "Also save the notes as this page of the project wiki, e.g. release-notes/prod-2026-10",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			env := Env(c.Args().First())
+			if env == "" {
+				if c.Bool("ci") {
+					return fmt.Errorf("usage: aio ztag notes <env>, e.g. aio ztag notes prod")
+				}
+				_, name, err := prompt.Select("Select a Environment:", []string{string(EnvQC), string(EnvStg), string(EnvProd)}, string(EnvProd))
+				if err != nil {
+					return err
+				}
+				env = Env(name)
+			}
+			if env != EnvQC && env != EnvStg && env != EnvProd {
+				return fmt.Errorf("unknown environment: %s (expected qc, stg or prod)", env)
+			}
+			since, until, err := notesPeriod(c)
+			if err != nil {
+				return err
+			}
+
+			gc := git.NewClient()
+			if err := gc.FetchTags(c.Context); err != nil {
+				fmt.Fprintf(os.Stderr, "[!] Warning: %v\n", err)
+			}
+			tags, err := gc.LocalTags(c.Context)
+			if err != nil {
+				return err
+			}
+			projectID, err := gc.ProjectID(c.Context)
+			if err != nil {
+				return err
+			}
+			notes, err := renderNotes(c.Context, projectID, env, tags, since, until, c.Bool("mrs"))
+			if err != nil {
+				return err
+			}
+
+			if output := c.String("output"); output != "" {
+				if err := os.WriteFile(output, []byte(notes), 0o644); err != nil {
+					return fmt.Errorf("error writing %s: %w", output, err)
+				}
+				fmt.Printf("[+] Release notes written to %s\n", output)
+			} else {
+				fmt.Print(notes)
+			}
+
+			slug := c.String("wiki")
+			if slug == "" {
+				return nil
+			}
+			client, err := gitlab.NewClient(c.Context)
+			if err != nil {
+				return err
+			}
+			page, err := client.SaveWikiPage(projectID, slug, notes)
+			if err != nil {
+				return fmt.Errorf("error saving the wiki page %s: %w", slug, err)
+			}
+			fmt.Fprintf(os.Stderr, "[+] Saved to %s\n", client.WikiURL(projectID, page.Slug))
+			return nil
+		},
+	}
+}
+
+// notesPeriod returns the period of --since/--until (or --days), as the
+// start of the first day and the end of the last one.
+func notesPeriod(c *cli.Context) (time.Time, time.Time, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	until, since := today, today.AddDate(0, 0, 1-c.Int("days"))
+	var err error
+	if s := c.String("until"); s != "" {
+		if until, err = time.ParseInLocation(notesDateLayout, s, time.Local); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --until %q, expected a date like 2026-10-31", s)
+		}
+	}
+	if s := c.String("since"); s != "" {
+		if since, err = time.ParseInLocation(notesDateLayout, s, time.Local); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --since %q, expected a date like 2026-10-01", s)
+		}
+	}
+	if since.After(until) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--since %s is after --until %s", since.Format(notesDateLayout), until.Format(notesDateLayout))
+	}
+	return since, until.AddDate(0, 0, 1), nil
+}
+
+// renderNotes renders, oldest first, each tag of env created in [since,
+// until) with the commits it added over the previous tag of env and the
+// Jira ticket recorded when it was deployed.
+func renderNotes(ctx context.Context, projectID string, env Env, tags []git.TagInfo, since time.Time, until time.Time, withMRs bool) (string, error) {
+	gc := git.NewClient()
+	// tags are newest first, so the previous tag of an env tag comes after it
+	var envTags []git.TagInfo
+	for _, tag := range tags {
+		if TagEnv(tag.Name) == env {
+			envTags = append(envTags, tag)
+		}
+	}
+	tickets := map[string]string{}
+	if deployments, err := release.Deployments(projectID); err == nil {
+		for _, d := range deployments {
+			if d.Env == string(env) && d.Ticket != "" {
+				tickets[d.Tag] = d.Ticket
+			}
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Release notes: %s %s\n\n", projectID, env)
+	fmt.Fprintf(&b, "%s to %s\n", since.Format(notesDateLayout), until.AddDate(0, 0, -1).Format(notesDateLayout))
+	shipped, total := 0, 0
+	for i := len(envTags) - 1; i >= 0; i-- {
+		tag := envTags[i]
+		if tag.Date.Before(since) || !tag.Date.Before(until) {
+			continue
+		}
+		shipped++
+		fmt.Fprintf(&b, "\n## %s (%s)\n\n", tag.Name, tag.Date.Local().Format("2006-01-02 15:04"))
+		if ticket := tickets[tag.Name]; ticket != "" {
+			fmt.Fprintf(&b, "Jira: %s\n\n", ticket)
+		}
+		if i == len(envTags)-1 {
+			fmt.Fprintf(&b, "First %s tag.\n", env)
+			continue
+		}
+		previous := envTags[i+1].Name
+		commits, err := gc.CommitsBetween(ctx, previous, tag.Name)
+		if err != nil {
+			return "", err
+		}
+		total += len(commits)
+		if len(commits) == 0 {
+			fmt.Fprintf(&b, "No changes since %s.\n", previous)
+			continue
+		}
+		mrs := map[string]string{}
+		if withMRs {
+			mrs = commitMergeRequests(ctx, commits)
+		}
+		for _, commit := range commits {
+			line := fmt.Sprintf("- %s (%s, %s)", commit.Subject, commit.Short(), commit.Author)
+			if mr := mrs[commit.SHA]; mr != "" {
+				line += " " + mr
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	if shipped == 0 {
+		fmt.Fprintf(&b, "\nNo %s tag in this period.\n", env)
+	} else {
+		fmt.Fprintf(&b, "\n%d tag(s), %d commit(s).\n", shipped, total)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,32 @@
+package ztag
+
+import (
+	"cli-aio/internal/pkg/crash"
+	"sync"
+)
+
+// remoteWorkers bounds the concurrent git and GitLab requests: enough to hide
+// the latency of a slow network, few enough not to be throttled.
+const remoteWorkers = 4
+
+// parallel calls fn for 0..n-1 on at most remoteWorkers goroutines and
+// returns when all calls are done. fn must only write to its own index.
+func parallel(n int, fn func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < remoteWorkers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer crash.Guard()
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
@@ -0,0 +1,265 @@
+package ztag
+
+import (
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/release"
+	"cli-aio/internal/prompt"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// pruneCmd deletes the tags falling out of the retention policy, keeping a
+// record of them so the release history is not silently lost.
+func pruneCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "prune",
+		Usage: "Delete tags older than the retention policy, recording what was pruned",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "keep",
+				Usage: "Newest tags kept per environment (default: ztag.retention)",
+			},
+			&cli.IntFlag{
+				Name:  "days",
+				Usage: "Only prune tags older than this many days (default: ztag.retention)",
+			},
+			&cli.StringSliceFlag{
+				Name:    "env",
+				Aliases: []string{"e"},
+				Usage:   "Environments to prune (default: qc, stg and prod)",
+			},
+			&cli.BoolFlag{
+				Name:  "delete",
+				Usage: "Delete the tags locally instead of only listing them",
+			},
+			&cli.BoolFlag{
+				Name:  "remote",
+				Usage: "With --delete, also delete the tags on origin",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Do not ask for confirmation",
+			},
+			&cli.StringFlag{
+				Name:  "export",
+				Usage: "Write the pruned tags as JSON to this file",
+			},
+			&cli.BoolFlag{
+				Name:  "history",
+				Usage: "Show the tags pruned from this project so far",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			gc := git.NewClient()
+			projectID, err := gc.ProjectID(c.Context)
+			if err != nil {
+				return err
+			}
+			if c.Bool("history") {
+				return printPruned(projectID)
+			}
+
+			envs := []Env{EnvQC, EnvStg, EnvProd}
+			if names := c.StringSlice("env"); len(names) > 0 {
+				envs = nil
+				for _, name := range names {
+					env := Env(name)
+					if env != EnvQC && env != EnvStg && env != EnvProd {
+						return fmt.Errorf("unknown environment: %s (expected qc, stg or prod)", name)
+					}
+					envs = append(envs, env)
+				}
+			}
+			policies, err := retentionPolicies(c, envs)
+			if err != nil {
+				return err
+			}
+
+			tags, err := gc.LocalTags(c.Context)
+			if err != nil {
+				return err
+			}
+			deployed := map[string]bool{}
+			if deployments, err := release.Deployments(projectID); err == nil {
+				for _, d := range release.Current(deployments) {
+					deployed[d.Tag] = true
+				}
+			}
+			candidates := pruneCandidates(tags, policies, deployed, time.Now())
+			if len(candidates) == 0 {
+				fmt.Println("Nothing to prune")
+				return nil
+			}
+			for _, tag := range candidates {
+				fmt.Printf("%-5s %-24s %s  %s\n", TagEnv(tag.Name), tag.Name, tag.Date.Local().Format("2006-01-02"), git.Commit{SHA: tag.Commit}.Short())
+			}
+			if !c.Bool("delete") {
+				fmt.Printf("%d tag(s) to prune, pass --delete (and --remote for origin) to delete them\n", len(candidates))
+				return nil
+			}
+
+			remote := c.Bool("remote")
+			if !c.Bool("yes") {
+				where := "locally"
+				if remote {
+					where = "locally and on origin"
+				}
+				ok, err := prompt.Confirm(fmt.Sprintf("Delete %d tag(s) %s?", len(candidates), where), false)
+				if err != nil || !ok {
+					return err
+				}
+			}
+			return pruneTags(c.Context, projectID, candidates, remote, c.String("export"))
+		},
+	}
+}
+
+// retentionPolicies returns the policy of each environment: --keep/--days
+// when given, otherwise ztag.retention for the environment or "*".
+// Environments without any policy are left alone.
+func retentionPolicies(c *cli.Context, envs []Env) (map[Env]config.TagRetention, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	policies := map[Env]config.TagRetention{}
+	for _, env := range envs {
+		policy, ok := cfg.ZTag.Retention[string(env)]
+		if !ok {
+			policy, ok = cfg.ZTag.Retention["*"]
+		}
+		if c.IsSet("keep") {
+			policy.Keep, ok = c.Int("keep"), true
+		}
+		if c.IsSet("days") {
+			policy.Days, ok = c.Int("days"), true
+		}
+		if ok && (policy.Keep > 0 || policy.Days > 0) {
+			policies[env] = policy
+		}
+	}
+	if len(policies) == 0 {
+		return nil, fmt.Errorf(`no retention policy, pass --keep/--days or set "ztag": {"retention": {"*": {"keep": 20}}}`)
+	}
+	return policies, nil
+}
+
+// pruneCandidates returns the tags (newest first) outside the policy of
+// their environment: not among the Keep newest and, with Days, older than
+// Days. Tags recorded as deployed are never pruned.
+func pruneCandidates(tags []git.TagInfo, policies map[Env]config.TagRetention, deployed map[string]bool, now time.Time) []git.TagInfo {
+	seen := map[Env]int{}
+	var candidates []git.TagInfo
+	for _, tag := range tags {
+		env := TagEnv(tag.Name)
+		policy, ok := policies[env]
+		if !ok {
+			continue
+		}
+		seen[env]++
+		if seen[env] <= policy.Keep || deployed[tag.Name] {
+			continue
+		}
+		if policy.Days > 0 && now.Sub(tag.Date) < time.Duration(policy.Days)*24*time.Hour {
+			continue
+		}
+		candidates = append(candidates, tag)
+	}
+	return candidates
+}
+
+// pruneTags records the tags and writes them to export when given, then
+// deletes them on origin with remote and locally. Nothing is deleted unless
+// both writes succeed; once a tag is gone from origin its record is kept even
+// if the local deletion fails.
+func pruneTags(ctx context.Context, projectID string, tags []git.TagInfo, remote bool, export string) error {
+	gc := git.NewClient()
+	var names, onOrigin []string
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	if remote {
+		remoteTags, err := gc.RemoteTags(ctx)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			if slices.Contains(remoteTags, name) {
+				onOrigin = append(onOrigin, name)
+			}
+		}
+	}
+
+	now := time.Now()
+	pruned := make([]release.PrunedTag, 0, len(tags))
+	for _, tag := range tags {
+		pruned = append(pruned, release.PrunedTag{
+			Tag:       tag.Name,
+			Env:       string(TagEnv(tag.Name)),
+			Commit:    tag.Commit,
+			CreatedAt: tag.Date,
+			PrunedAt:  now,
+			Remote:    slices.Contains(onOrigin, tag.Name),
+		})
+	}
+	if export != "" {
+		data, err := json.MarshalIndent(pruned, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(export, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("error writing %s, nothing was deleted: %w", export, err)
+		}
+	}
+	if err := release.RecordPruned(projectID, pruned); err != nil {
+		return fmt.Errorf("error recording the pruned tags, nothing was deleted: %w", err)
+	}
+
+	if len(onOrigin) > 0 {
+		if err := gc.DeleteRemoteTags(ctx, onOrigin); err != nil {
+			if err := release.ForgetPruned(projectID, now); err != nil {
+				fmt.Printf("[!] Warning: Failed to remove the pruned tags from the record: %v\n", err)
+			}
+			if export != "" {
+				os.Remove(export)
+			}
+			return git.WithHint(err)
+		}
+	}
+	if err := gc.DeleteLocalTags(ctx, names); err != nil {
+		return fmt.Errorf("%w (the tags are recorded, see 'aio ztag prune --history')", err)
+	}
+	fmt.Printf("Pruned %d tag(s), see 'aio ztag prune --history'\n", len(pruned))
+	if export != "" {
+		fmt.Printf("Exported to %s\n", export)
+	}
+	return nil
+}
+
+func printPruned(projectID string) error {
+	pruned, err := release.Pruned(projectID)
+	if err != nil {
+		return err
+	}
+	if len(pruned) == 0 {
+		fmt.Printf("[!] No tags pruned from %s yet\n", projectID)
+		return nil
+	}
+	for _, t := range pruned {
+		where := "local"
+		if t.Remote {
+			where = "origin"
+		}
+		fmt.Printf("%-5s %-24s %s  created %s, pruned %s (%s)\n", t.Env, t.Tag, git.Commit{SHA: t.Commit}.Short(),
+			t.CreatedAt.Local().Format("2006-01-02"), t.PrunedAt.Local().Format("2006-01-02"), where)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package ztag
+
+import (
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/offline"
+	"context"
+	"fmt"
+	"io"
+)
+
+// CreateAndPushTag tags HEAD and pushes the tag. Offline the tag is created
+// locally and the push queued; queued reports it, to out as well.
+func CreateAndPushTag(ctx context.Context, out io.Writer, tag string, message string) (queued bool, err error) {
+	gc := git.NewClient()
+	if err := gc.CreateTag(ctx, tag, message); err != nil {
+		if git.KindOf(err) == git.ErrExists {
+			return false, fmt.Errorf("tag %s already exists locally, fetch the tags (git fetch --tags) and rerun", tag)
+		}
+		return false, err
+	}
+	return offline.RunTo(out, offline.Action{
+		Kind:        offline.ActionPushTag,
+		Description: fmt.Sprintf("push tag %s", tag),
+		Params:      map[string]string{"tag": tag},
+	}, func() error {
+		err := gc.PushTag(ctx, tag)
+		if git.KindOf(err) == git.ErrRejected {
+			// Someone pushed the same tag since the remote tags were listed
+			return fmt.Errorf("origin already has a tag %s, delete the local one (git tag -d %s) and rerun: %w", tag, tag, err)
+		}
+		return git.WithHint(err)
+	})
+}
+
+// releaseAction is the queued form of a GitLab release of tag.
+func releaseAction(projectID string, tag string, description string) offline.Action {
+	return offline.Action{
+		Kind:        offline.ActionRelease,
+		Description: fmt.Sprintf("create release %s of %s", tag, projectID),
+		Params:      map[string]string{"project": projectID, "tag": tag, "description": description},
+	}
+}
@@ -25,6 +25,16 @@ func GenerateNextTag(oldTag string, level Level, env Env) (string, error) {
 	return "", fmt.Errorf("tag does not match any supported template")
 }
 
+// ParseTag extracts the version of a tag in any supported format.
+func ParseTag(tag string) (TagComponents, error) {
+	for _, template := range supportedTagTemplates {
+		if template.Regex().MatchString(tag) {
+			return template.Extractor(tag)
+		}
+	}
+	return TagComponents{}, fmt.Errorf("tag does not match any supported template")
+}
+
 // TagComponents holds all parts needed to reconstruct a tag.
 type TagComponents struct {
 	Major int
@@ -54,6 +64,8 @@ type TagTemplate interface {
 	Regex() *regexp.Regexp
 	Extractor(tag string) (TagComponents, error)
 	Generator(c TagComponents, env Env) string
+	// Env returns the environment a matching tag was created for.
+	Env(tag string) Env
 }
 
 type TagTemplate1 struct{} // qc-v1.0.0, stg-v1.0.0, prod-v1.0.0
@@ -85,6 +97,13 @@ func (t *TagTemplate1) Generator(c TagComponents, env Env) string {
 	return fmt.Sprintf("%s-v%d.%d.%d", string(env), c.Major, c.Minor, c.Patch)
 }
 
+func (t *TagTemplate1) Env(tag string) Env {
+	if match := t.Regex().FindStringSubmatch(tag); len(match) > 1 {
+		return Env(match[1])
+	}
+	return ""
+}
+
 type TagTemplate2 struct{} // v1.0.0, v1.0.0-beta, v1.0.0-alpha, v1.0.0-rc
 
 func (t *TagTemplate2) Regex() *regexp.Regexp {
@@ -114,6 +133,33 @@ func (t *TagTemplate2) Generator(c TagComponents, env Env) string {
 	return fmt.Sprintf("v%d.%d.%d-%s", c.Major, c.Minor, c.Patch, string(env))
 }
 
+func (t *TagTemplate2) Env(tag string) Env {
+	if match := t.Regex().FindStringSubmatch(tag); len(match) > 5 {
+		return Env(match[5])
+	}
+	return ""
+}
+
+// TagEnv returns the environment of a tag in any supported format.
+func TagEnv(tag string) Env {
+	for _, template := range supportedTagTemplates {
+		if template.Regex().MatchString(tag) {
+			return template.Env(tag)
+		}
+	}
+	return ""
+}
+
+// LatestEnvTag returns the first tag of env in tags (newest first), or "" when there is none.
+func LatestEnvTag(tags []string, env Env) string {
+	for _, tag := range tags {
+		if TagEnv(tag) == env {
+			return tag
+		}
+	}
+	return ""
+}
+
 func mustAtoi(s string) int {
 	if s == "" {
 		return 0
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"cli-aio/internal/pkg/golden"
+	"context"
+	"flag"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "record the golden transcripts instead of comparing them")
+
+// TestGolden replays every session of testdata/golden with a fresh build of
+// aio, like 'aio devtool golden'. Run it with -update to record the
+// transcripts again, and review them with git diff.
+func TestGolden(t *testing.T) {
+	if testing.Short() {
+		t.Skip("golden sessions take a few seconds each")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("golden sessions need a pseudo-terminal")
+	}
+	paths, err := golden.Glob(filepath.Join("testdata", "golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := filepath.Join(t.TempDir(), "aio")
+	if output, err := exec.Command("go", "build", "-o", bin, ".").CombinedOutput(); err != nil {
+		t.Fatalf("failed to build aio: %v\n%s", err, output)
+	}
+
+	for _, path := range paths {
+		script, err := golden.Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Run(script.Name, func(t *testing.T) {
+			result, err := golden.Run(context.Background(), bin, script)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := golden.Check(script, result.String(), *update); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
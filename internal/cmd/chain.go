@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+)
+
+// shared holds the values published by the steps of an 'aio do' chain.
+var shared = map[string]string{}
+
+// Publish makes value available to the next steps of 'aio do' as {key}, and
+// to child processes as AIO_<KEY>. Outside a chain it only costs a map entry.
+func Publish(key string, value string) {
+	shared[key] = value
+	os.Setenv("AIO_"+strings.ToUpper(key), value)
+}
+
+// Shared returns the value published under key.
+func Shared(key string) (string, bool) {
+	value, ok := shared[key]
+	return value, ok
+}
+
+// ExpandShared replaces the {key} placeholders of published values in s.
+func ExpandShared(s string) string {
+	for key, value := range shared {
+		s = strings.ReplaceAll(s, "{"+key+"}", value)
+	}
+	return s
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"cli-aio/internal/prompt"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Ask describes how to prompt for a flag left out on the command line.
+type Ask struct {
+	Flag    string
+	Message string // defaults to the flag's usage
+	// Options are the allowed values: asked with a selector, and checked
+	// when the flag is given on the command line.
+	Options     []string
+	Default     string
+	DefaultFunc func(c *cli.Context) string // computed default, e.g. from git config
+}
+
+// asks holds the prompts registered by AskFlags, per command.
+var asks = map[*cli.Command][]Ask{}
+
+// AskFlags registers prompts for flags of command. Missing flags are asked in
+// interactive mode and are an error otherwise, like required flags.
+func AskFlags(command *cli.Command, flagAsks ...Ask) *cli.Command {
+	asks[command] = append(asks[command], flagAsks...)
+	return command
+}
+
+// InstallFlagPrompts walks the command tree and makes every command prompt
+// for its missing required flags (Required: true) and the flags registered
+// with AskFlags before its Action runs. Called once on the root commands, so
+// new commands get it without extra code.
+func InstallFlagPrompts(commands []*cli.Command) {
+	for _, command := range commands {
+		installFlagPrompts(command)
+		InstallFlagPrompts(command.Subcommands)
+	}
+}
+
+func installFlagPrompts(command *cli.Command) {
+	commandAsks := asks[command]
+	for _, flag := range command.Flags {
+		if !clearRequired(flag) {
+			continue
+		}
+		name := flag.Names()[0]
+		if !hasAsk(commandAsks, name) {
+			commandAsks = append(commandAsks, Ask{Flag: name})
+		}
+	}
+	if len(commandAsks) == 0 {
+		return
+	}
+
+	before := command.Before
+	command.Before = func(c *cli.Context) error {
+		for _, ask := range commandAsks {
+			if err := askFlag(c, command, ask); err != nil {
+				return err
+			}
+		}
+		if before != nil {
+			return before(c)
+		}
+		return nil
+	}
+}
+
+// clearRequired turns off urfave/cli's own required check, which runs before
+// Before and would fail without prompting. Reports whether flag was required.
+func clearRequired(flag cli.Flag) bool {
+	switch f := flag.(type) {
+	case *cli.StringFlag:
+		required := f.Required
+		f.Required = false
+		return required
+	case *cli.IntFlag:
+		required := f.Required
+		f.Required = false
+		return required
+	case *cli.StringSliceFlag:
+		required := f.Required
+		f.Required = false
+		return required
+	case *cli.DurationFlag:
+		required := f.Required
+		f.Required = false
+		return required
+	case *cli.PathFlag:
+		required := f.Required
+		f.Required = false
+		return required
+	}
+	return false
+}
+
+func hasAsk(commandAsks []Ask, name string) bool {
+	for _, ask := range commandAsks {
+		if ask.Flag == name {
+			return true
+		}
+	}
+	return false
+}
+
+// askFlag checks a flag given on the command line, or prompts for it.
+func askFlag(c *cli.Context, command *cli.Command, ask Ask) error {
+	if c.IsSet(ask.Flag) {
+		value := c.String(ask.Flag)
+		if len(ask.Options) > 0 && !contains(ask.Options, value) {
+			return fmt.Errorf("invalid --%s value: %s (expected %s)", ask.Flag, value, strings.Join(ask.Options, ", "))
+		}
+		return nil
+	}
+
+	message := ask.Message
+	if message == "" {
+		message = flagUsage(command, ask.Flag)
+	}
+	if !prompt.IsInteractive(c.Bool("interactive")) {
+		return fmt.Errorf("missing --%s: %s", ask.Flag, strings.TrimSuffix(message, ":"))
+	}
+
+	def := ask.Default
+	if ask.DefaultFunc != nil {
+		def = ask.DefaultFunc(c)
+	}
+	var value string
+	var err error
+	if len(ask.Options) > 0 {
+		if _, value, err = prompt.SelectWithFuzzy(message, ask.Options, def, false); err != nil {
+			return fmt.Errorf("selection cancelled: %w", err)
+		}
+	} else if value, err = prompt.Input(message, def, true); err != nil {
+		return fmt.Errorf("input cancelled: %w", err)
+	}
+	if err := c.Set(ask.Flag, value); err != nil {
+		return fmt.Errorf("invalid --%s value: %s", ask.Flag, value)
+	}
+	return nil
+}
+
+// flagUsage returns the usage of the named flag as a prompt message.
+func flagUsage(command *cli.Command, name string) string {
+	for _, flag := range command.Flags {
+		if flag.Names()[0] != name {
+			continue
+		}
+		if f, ok := flag.(cli.DocGenerationFlag); ok && f.GetUsage() != "" {
+			return f.GetUsage() + ":"
+		}
+	}
+	return name + ":"
+}
+
+func contains(items []string, item string) bool {
+	for _, v := range items {
+		if v == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Example is a sample invocation shown in help and the generated docs.
+type Example struct {
+	Command string // e.g. "aio git rmerge main develop"
+	Comment string // what it does, optional
+}
+
+// examples holds the examples registered by Describe, for the docs generator.
+var examples = map[*cli.Command][]Example{}
+
+// Describe sets the long description of command and registers its examples,
+// rendered as DESCRIPTION and EXAMPLES sections of its help:
+//
+//	return cmd.Describe(&cli.Command{...},
+//		"Longer explanation, wrapped to the terminal.",
+//		cmd.Example{Command: "aio ztag qc", Comment: "Tag HEAD for QC"},
+//	)
+func Describe(command *cli.Command, description string, exs ...Example) *cli.Command {
+	command.Description = strings.TrimSpace(description)
+	if len(exs) == 0 {
+		return command
+	}
+	examples[command] = exs
+
+	base := cli.CommandHelpTemplate
+	if len(command.Subcommands) > 0 {
+		base = cli.SubcommandHelpTemplate
+	}
+	command.CustomHelpTemplate = base + "\nEXAMPLES:\n" + escapeTemplate(FormatExamples(exs, "   "))
+	return command
+}
+
+// ExamplesOf returns the examples registered for command.
+func ExamplesOf(command *cli.Command) []Example {
+	return examples[command]
+}
+
+// FormatExamples renders examples one per line with aligned comments.
+func FormatExamples(exs []Example, indent string) string {
+	width := 0
+	for _, ex := range exs {
+		if len(ex.Command) > width {
+			width = len(ex.Command)
+		}
+	}
+	var b strings.Builder
+	for _, ex := range exs {
+		if ex.Comment == "" {
+			fmt.Fprintf(&b, "%s%s\n", indent, ex.Command)
+			continue
+		}
+		fmt.Fprintf(&b, "%s%-*s   # %s\n", indent, width, ex.Command, ex.Comment)
+	}
+	return b.String()
+}
+
+// escapeTemplate makes s print literally inside a text/template.
+func escapeTemplate(s string) string {
+	return strings.ReplaceAll(s, "{{", `{{"{{"}}`)
+}
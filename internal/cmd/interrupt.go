@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+
+	"cli-aio/internal/ui"
+
+	"golang.org/x/term"
+)
+
+// stopKey holds the channel closed by the first Ctrl+C in a run's context.
+type stopKey struct{}
+
+// WithInterrupts returns the context of a whole run, handling Ctrl+C in steps:
+//
+//  1. commands stop after their current step (see Stopping), printing what was done
+//  2. the context is cancelled: running git processes and API calls are aborted
+//  3. aio exits right away, restoring the terminal
+//
+// release stops the handling when the run ends.
+func WithInterrupts(parent context.Context) (ctx context.Context, release func()) {
+	stop := make(chan struct{})
+	var once sync.Once
+	closeStop := func() { once.Do(func() { close(stop) }) }
+	ctx, cancel := context.WithCancel(context.WithValue(parent, stopKey{}, stop))
+	// A cancelled run is also a stopped one
+	go func() {
+		<-ctx.Done()
+		closeStop()
+	}()
+
+	var saved *term.State
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		saved, _ = term.GetState(int(os.Stdin.Fd()))
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		for count := 1; ; count++ {
+			select {
+			case <-signals:
+			case <-done:
+				return
+			}
+			switch count {
+			case 1:
+				fmt.Fprintln(os.Stderr, "\n[!] Stopping after the current step, press Ctrl+C again to cancel it")
+				closeStop()
+			case 2:
+				fmt.Fprintln(os.Stderr, "\n[!] Cancelling, press Ctrl+C again to quit immediately")
+				cancel()
+			default:
+				if saved != nil {
+					term.Restore(int(os.Stdin.Fd()), saved)
+				}
+				// Show the cursor a prompt may have hidden
+				if ui.Accessible() {
+					fmt.Fprintln(os.Stderr)
+				} else {
+					fmt.Fprint(os.Stderr, "\033[?25h\n")
+				}
+				os.Exit(130)
+			}
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		close(done)
+		cancel()
+	}
+}
+
+// Stopped returns a channel closed when the user asked to stop (first Ctrl+C)
+// or the context is done.
+func Stopped(ctx context.Context) <-chan struct{} {
+	if stop, ok := ctx.Value(stopKey{}).(chan struct{}); ok {
+		return stop
+	}
+	return ctx.Done()
+}
+
+// Stopping reports whether the user asked to stop: loops check it before
+// starting the next item.
+func Stopping(ctx context.Context) bool {
+	select {
+	case <-Stopped(ctx):
+		return true
+	default:
+		return ctx.Err() != nil
+	}
+}
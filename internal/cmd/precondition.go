@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/git"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Precondition is something a command needs before its Action can run.
+type Precondition func(c *cli.Context) error
+
+// Require returns a Before hook running the preconditions in order. Commands
+// declare what they need instead of checking it at the top of their Action:
+//
+//	Before: cmd.Require(cmd.RequireGitRepo(), cmd.RequireCleanTree()),
+//
+// On a command with subcommands the hook also runs before each subcommand.
+func Require(preconditions ...Precondition) cli.BeforeFunc {
+	return func(c *cli.Context) error {
+		if helpRequested(c) {
+			return nil
+		}
+		for _, pre := range preconditions {
+			if err := pre(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// helpRequested reports whether the remaining args ask for a subcommand's help,
+// which should work anywhere (urfave/cli runs a parent's Before first).
+func helpRequested(c *cli.Context) bool {
+	for _, arg := range c.Args().Slice() {
+		switch arg {
+		case "--":
+			return false
+		case "-h", "--help", "help":
+			return true
+		}
+	}
+	return false
+}
+
+// RequireGitRepo fails outside a git working tree. It doesn't need git
+// installed: commands running git require it with RequireTool("git") first.
+func RequireGitRepo() Precondition {
+	return func(c *cli.Context) error {
+		isGitRepo, err := git.NewClient().IsRepo(c.Context)
+		if err != nil || !isGitRepo {
+			return fmt.Errorf("not a git repository")
+		}
+		return nil
+	}
+}
+
+// RequireCleanTree fails when there are staged, unstaged or untracked changes.
+func RequireCleanTree() Precondition {
+	return func(c *cli.Context) error {
+		clean, err := git.NewClient().IsClean(c.Context)
+		if err != nil {
+			return err
+		}
+		if !clean {
+			return fmt.Errorf("working tree has uncommitted changes, commit or stash them first")
+		}
+		return nil
+	}
+}
+
+// RequireTool fails when the executable is not on PATH.
+func RequireTool(name string) Precondition {
+	return func(c *cli.Context) error {
+		if _, err := exec.LookPath(name); err != nil {
+			return fmt.Errorf("%s is not installed or not on PATH", name)
+		}
+		return nil
+	}
+}
+
+// RequireConfigKey fails when the dotted config.json key (e.g. "jira.base_url")
+// is missing or empty.
+func RequireConfigKey(key string) Precondition {
+	return func(c *cli.Context) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		// Walk the JSON form so keys match what users write in config.json
+		data, err := json.Marshal(cfg)
+		if err != nil {
+			return err
+		}
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		for _, part := range strings.Split(key, ".") {
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				value = nil
+				break
+			}
+			value = obj[part]
+		}
+		if value == nil || value == "" {
+			path, _ := config.Path("config.json")
+			return fmt.Errorf("%s is not configured (set it in %s)", key, path)
+		}
+		return nil
+	}
+}
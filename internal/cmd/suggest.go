@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"cli-aio/internal/prompt"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+// Levenshtein returns the edit distance between a and b.
+func Levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// suggestion is a candidate command path and how far it is from what was typed.
+type suggestion struct {
+	path     []string
+	distance int
+	sibling  bool // under the command the word was typed after
+}
+
+func (s suggestion) better(o suggestion) bool {
+	if s.distance != o.distance {
+		return s.distance < o.distance
+	}
+	if s.sibling != o.sibling {
+		return s.sibling
+	}
+	return len(s.path) < len(o.path)
+}
+
+// Suggest returns the command path closest to typed anywhere in the tree, e.g.
+// ["git", "rmerge"] for "rmerg" or "rmerge" typed at the top level. Commands
+// under parent win ties. Nil when nothing is close enough.
+func Suggest(commands []*cli.Command, parent []string, typed string) []string {
+	typed = strings.ToLower(typed)
+	// Allow about one typo per three characters
+	maxDistance := len(typed)/3 + 1
+
+	var best *suggestion
+	var walk func(commands []*cli.Command, path []string)
+	walk = func(commands []*cli.Command, path []string) {
+		for _, command := range commands {
+			if command.Hidden || command.Name == "help" {
+				continue
+			}
+			full := append(append([]string{}, path...), command.Name)
+			for _, name := range command.Names() {
+				distance := Levenshtein(typed, strings.ToLower(name))
+				// A prefix of a long name is closer than its edit distance says
+				if len(typed) >= 2 && strings.HasPrefix(name, typed) {
+					distance = min(distance, 1)
+				}
+				if distance > maxDistance {
+					continue
+				}
+				s := suggestion{path: full, distance: distance, sibling: strings.Join(path, " ") == strings.Join(parent, " ")}
+				if best == nil || s.better(*best) {
+					best = &s
+				}
+			}
+			walk(command.Subcommands, full)
+		}
+	}
+	walk(commands, nil)
+
+	if best == nil {
+		return nil
+	}
+	return best.path
+}
+
+// OfferSuggestion prints the closest command to the unknown word typed after
+// parent and, on a terminal, offers to run it with the remaining args. It
+// reports whether the suggestion was run.
+func OfferSuggestion(c *cli.Context, parent []string, typed string, rest []string) bool {
+	path := Suggest(c.App.Commands, parent, typed)
+	if path == nil {
+		return false
+	}
+	suggested := strings.Join(path, " ")
+	fmt.Fprintf(os.Stderr, "\n[!] Did you mean '%s'?\n", suggested)
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+
+	run, err := prompt.Confirm(fmt.Sprintf("Run 'aio %s'?", strings.TrimSpace(suggested+" "+strings.Join(rest, " "))), true)
+	if err != nil || !run {
+		return false
+	}
+	args := append([]string{os.Args[0]}, append(path, rest...)...)
+	// Errors of the suggested command are reported and exit through the app's ExitErrHandler
+	_ = c.App.RunContext(c.Context, args)
+	return true
+}
@@ -3,13 +3,15 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/urfave/cli/v2"
 )
 
 // ValidateSubcommand checks if a subcommand exists and shows a warning if not.
 // This can be used in command Action handlers to validate subcommands.
-// Returns true if subcommand is valid or no subcommand provided, false if invalid.
+// Returns true if subcommand is valid, no subcommand provided or the closest
+// command was suggested and run instead, false if invalid.
 func ValidateSubcommand(c *cli.Context, subcommands []*cli.Command) bool {
 	if c.Args().Len() == 0 {
 		return true
@@ -24,6 +26,9 @@ func ValidateSubcommand(c *cli.Context, subcommands []*cli.Command) bool {
 
 	// Unknown subcommand
 	fmt.Fprintf(os.Stderr, "[!] Warning: Unknown subcommand '%s'\n", subcmdName)
+	if OfferSuggestion(c, strings.Fields(c.Command.FullName()), subcmdName, c.Args().Tail()) {
+		return true
+	}
 	fmt.Fprintf(os.Stderr, "\nAvailable subcommands:\n")
 	for _, subcmd := range subcommands {
 		fmt.Fprintf(os.Stderr, "  %s - %s\n", subcmd.Name, subcmd.Usage)
@@ -0,0 +1,92 @@
+// Package audit keeps a local history of the commands aio ran on the user's
+// behalf, so hand-typed git usage can be reviewed like the rest.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/crash"
+)
+
+// maxSize is the size at which audit.log is rotated to audit.log.1.
+const maxSize = 1 << 20
+
+// Entry is one recorded command.
+type Entry struct {
+	Time     time.Time `json:"time"`
+	Dir      string    `json:"dir"`
+	Command  []string  `json:"command"`
+	Exit     int       `json:"exit"`
+	Duration string    `json:"duration,omitempty"`
+	DryRun   bool      `json:"dry_run,omitempty"`
+	// Note says why the command ran, e.g. the reason for a freeze override.
+	Note string `json:"note,omitempty"`
+}
+
+func path() (string, error) {
+	return config.Path("audit.log")
+}
+
+// Record appends e to the history. The file is only readable by the user,
+// since commands can contain URLs or branch names worth keeping private, and
+// the credentials in the command are redacted before it is written.
+func Record(e Entry) error {
+	e.Command = crash.SanitizeArgs(e.Command)
+	file, err := path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if info, err := os.Stat(file); err == nil && info.Size() > maxSize {
+		os.Rename(file, file+".1")
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
+
+// Read returns the recorded entries, oldest first. Lines that can't be
+// parsed are skipped.
+func Read() ([]Entry, error) {
+	file, err := path()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxSize)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,228 @@
+// Package codeowners reads CODEOWNERS files in the GitHub and GitLab syntax
+// and finds who owns a path of the repository.
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Locations are where a CODEOWNERS file is looked up, relative to the root of
+// the repository, in order: the first one found is used.
+var Locations = []string{".github/CODEOWNERS", ".gitlab/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// File is a parsed CODEOWNERS file.
+type File struct {
+	// Path is where the file was read from, relative to the repository.
+	Path     string
+	Sections []*Section
+}
+
+// Section is a GitLab section ("[Backend] @backend-team") with its rules.
+// Rules before the first section, and every rule of a GitHub file, are in a
+// section without a name.
+type Section struct {
+	Name string
+	// Optional sections ("^[Docs]") don't require an approval on GitLab.
+	Optional bool
+	// Default owners apply to the rules of the section without owners.
+	Default []string
+	Rules   []Rule
+}
+
+// Rule is a pattern with its owners.
+type Rule struct {
+	Pattern string
+	Owners  []string
+	Line    int
+	re      *regexp.Regexp
+}
+
+// Match is the rule owning a path in a section.
+type Match struct {
+	Section *Section
+	Rule    Rule
+	// Owners are the owners of the rule, or the section's default ones.
+	Owners []string
+}
+
+// Find reads the CODEOWNERS file of the repository at root, nil when it has none.
+func Find(root string) (*File, error) {
+	for _, location := range Locations {
+		f, err := os.Open(filepath.Join(root, filepath.FromSlash(location)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", location, err)
+		}
+		defer f.Close()
+		file, err := Parse(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", location, err)
+		}
+		file.Path = location
+		return file, nil
+	}
+	return nil, nil
+}
+
+// sectionHeader matches "[Name]", "^[Name]" and "[Name][2]", followed by the
+// default owners of the section.
+var sectionHeader = regexp.MustCompile(`^(\^)?\[([^\]]+)\](?:\[\d+\])?\s*(.*)$`)
+
+// Parse reads a CODEOWNERS file.
+func Parse(r io.Reader) (*File, error) {
+	file := &File{}
+	section := &Section{}
+	file.Sections = append(file.Sections, section)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if m := sectionHeader.FindStringSubmatch(text); m != nil {
+			section = file.section(m[2])
+			section.Optional = m[1] != ""
+			if owners := fields(stripComment(m[3])); len(owners) > 0 {
+				section.Default = owners
+			}
+			continue
+		}
+		words := fields(stripComment(text))
+		if len(words) == 0 {
+			continue
+		}
+		re, err := compile(words[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid pattern %s: %w", line, words[0], err)
+		}
+		section.Rules = append(section.Rules, Rule{Pattern: words[0], Owners: words[1:], Line: line, re: re})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// section returns the section named name, created when it is new: GitLab
+// merges sections with the same name, ignoring case.
+func (f *File) section(name string) *Section {
+	for _, s := range f.Sections {
+		if strings.EqualFold(s.Name, name) {
+			return s
+		}
+	}
+	s := &Section{Name: name}
+	f.Sections = append(f.Sections, s)
+	return s
+}
+
+// Owners returns the rule owning path (relative to the repository, with
+// slashes) in every section where one matches: the last matching rule of a
+// section wins. A match without owners means the path is explicitly unowned.
+func (f *File) Owners(path string) []Match {
+	path = strings.TrimPrefix(filepath.ToSlash(path), "/")
+	var matches []Match
+	for _, section := range f.Sections {
+		for i := len(section.Rules) - 1; i >= 0; i-- {
+			rule := section.Rules[i]
+			if !rule.re.MatchString(path) {
+				continue
+			}
+			owners := rule.Owners
+			if len(owners) == 0 && section.Name != "" {
+				owners = section.Default
+			}
+			matches = append(matches, Match{Section: section, Rule: rule, Owners: owners})
+			break
+		}
+	}
+	return matches
+}
+
+// compile turns a gitignore-style pattern into a regular expression matching
+// the paths it owns: a pattern starting with or containing a slash is
+// relative to the root, otherwise it matches at any depth; a directory owns
+// everything below it.
+func compile(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.TrimSuffix(pattern, "/")
+	anchored := strings.HasPrefix(p, "/") || strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '\\' && i+1 < len(p):
+			i++
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	if dirOnly {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
+
+// stripComment removes a trailing "# comment"; "\#" is a literal #.
+func stripComment(s string) string {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+		} else if s[i] == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t') {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// fields splits s on spaces, keeping escaped ones ("docs/my\ file.md").
+func fields(s string) []string {
+	var words []string
+	var word strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && (s[i+1] == ' ' || s[i+1] == '#'):
+			word.WriteByte(s[i+1])
+			i++
+		case c == ' ' || c == '\t':
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+		default:
+			word.WriteByte(c)
+		}
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
@@ -0,0 +1,336 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DefaultGitLabURL is the GitLab instance used when none is configured.
+const DefaultGitLabURL = "https://gitlab.zalopay.vn"
+
+// GitLab holds settings for the GitLab API integration.
+type GitLab struct {
+	BaseURL string `json:"base_url,omitempty"`
+	// Projects limits multi-project views (e.g. MR review queue) to these project paths.
+	// When empty, GitLab-wide endpoints are used.
+	Projects []string `json:"projects,omitempty"`
+}
+
+// Jira holds settings for the Jira API integration.
+// Credentials are read from the secrets store ("jira.email", "jira.token").
+type Jira struct {
+	BaseURL string `json:"base_url,omitempty"`
+}
+
+// Release holds settings for 'aio release'.
+// Notifications go where notify.routes.release says, by default to the
+// webhook URL in the secrets store ("release.webhook").
+type Release struct {
+	// TestCommand is run through the shell before tagging (default "go test ./...").
+	TestCommand string `json:"test_command,omitempty"`
+	// Freezes are the periods 'aio ztag' and 'aio release' refuse to release
+	// in without --override.
+	Freezes []Freeze `json:"freezes,omitempty"`
+	// Timezone of the freeze times, e.g. "Asia/Ho_Chi_Minh" (default: local time).
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// Freeze is a release freeze window: weekly ("Fri 16:00" to "Mon 09:00") or
+// between dates ("2026-12-24" to "2027-01-02", whole days unless a time is given).
+type Freeze struct {
+	// Name is shown when the freeze blocks a release, e.g. "weekend".
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Envs are the environments frozen (default: prod).
+	Envs []string `json:"envs,omitempty"`
+}
+
+// ZTag holds settings for 'aio ztag'.
+type ZTag struct {
+	// Policies maps a project path (or "*" for every project) to the branch
+	// patterns allowed per environment, e.g. {"stg": ["release/*"], "qc": ["*"]}.
+	// Environments not listed keep the default rule: prod from
+	// main/master or a prod release branch, the others from any branch.
+	Policies map[string]map[string][]string `json:"policies,omitempty"`
+	// Environments maps qc/stg/prod to the GitLab environment names used to
+	// look up what is actually deployed, e.g. {"stg": "staging"}. Unmapped
+	// environments use their own name.
+	Environments map[string]string `json:"environments,omitempty"`
+	// Approvals maps a project path (or "*" for every project) to the number
+	// of approvals the merged MR bringing the commit into main needs before
+	// prod is tagged, e.g. {"bank/api": 2}.
+	Approvals map[string]int `json:"approvals,omitempty"`
+	// Retention maps an environment (or "*" for every environment) to the
+	// tags 'aio ztag prune' keeps, e.g. {"qc": {"keep": 20, "days": 30}}.
+	Retention map[string]TagRetention `json:"retention,omitempty"`
+	// Checks maps a project path (or "*" for every project) to the command
+	// run through the shell before tagging, e.g. {"bank/api": "make test"}.
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// TagRetention is how many tags of an environment are kept. A tag is pruned
+// when it is not one of the Keep newest and, with Days, is older than Days.
+type TagRetention struct {
+	Keep int `json:"keep,omitempty"`
+	Days int `json:"days,omitempty"`
+}
+
+// Projects holds settings for the 'prj' project store.
+type Projects struct {
+	// Backend is where projects are stored: "json" (default, projects.json)
+	// or "sqlite" (projects.db, only in builds with -tags sqlite).
+	Backend string `json:"backend,omitempty"`
+	// Ignore are glob patterns of directories skipped when scanning git roots,
+	// in addition to each root's .prjignore (e.g. "archived", "vendor").
+	Ignore []string `json:"ignore,omitempty"`
+	// Picker is the selector used by 'prj': "auto" (default, fzf when installed),
+	// "fzf" or "builtin".
+	Picker string `json:"picker,omitempty"`
+	// GroupByOrg shows 'prj' grouped by GitLab group / GitHub organization.
+	GroupByOrg bool `json:"group_by_org,omitempty"`
+	// BackupDir is where 'prj backup' writes bundles (default
+	// ~/.local/share/cli-aio/backups).
+	BackupDir string `json:"backup_dir,omitempty"`
+	// BackupKeep is how many bundles 'prj backup' keeps per project (default 5).
+	BackupKeep int `json:"backup_keep,omitempty"`
+	// ScratchDir is where 'aio scratch' creates projects (default <tmp>/aio-scratch).
+	ScratchDir string `json:"scratch_dir,omitempty"`
+	// ScratchTTL is how long scratch projects live, e.g. "12h", "7d", "2w" (default 7d).
+	ScratchTTL string `json:"scratch_ttl,omitempty"`
+	// ScratchTemplates maps a language to the shell commands initializing a
+	// scratch project, replacing the built-in go, node and python ones.
+	ScratchTemplates map[string][]string `json:"scratch_templates,omitempty"`
+}
+
+// Conventions holds the naming rules checked by 'aio git lint-branch' and
+// 'aio git lint-commits'.
+type Conventions struct {
+	// BranchTypes are the allowed branch prefixes, as in "<type>/<description>"
+	// (default: feature, fix, hotfix, chore, docs, refactor, test).
+	BranchTypes []string `json:"branch_types,omitempty"`
+	// BranchPattern is a regular expression branch names must match instead
+	// of the <type>/<description> rule.
+	BranchPattern string `json:"branch_pattern,omitempty"`
+	// RequireJiraKey requires a Jira issue key (e.g. ABC-123) in branch names.
+	RequireJiraKey bool `json:"require_jira_key,omitempty"`
+	// JiraProjects limits the accepted issue keys to these Jira projects, e.g. ["ABC"].
+	JiraProjects []string `json:"jira_projects,omitempty"`
+	// ExemptBranches are glob patterns never checked (default: main, master,
+	// develop, release/*).
+	ExemptBranches []string `json:"exempt_branches,omitempty"`
+	// ProtectedBranches are glob patterns 'aio git push' never force-pushes
+	// to (default: main, master, develop, release/*).
+	ProtectedBranches []string `json:"protected_branches,omitempty"`
+	// CommitTypes are the allowed conventional commit types, as in
+	// "<type>(<scope>): <description>" (default: feat, fix, docs, style,
+	// refactor, perf, test, build, ci, chore, revert).
+	CommitTypes []string `json:"commit_types,omitempty"`
+	// CommitPattern is a regular expression commit subjects must match instead
+	// of the conventional commit rule.
+	CommitPattern string `json:"commit_pattern,omitempty"`
+	// RequireCommitJiraKey requires a Jira issue key in the commit subject or body.
+	RequireCommitJiraKey bool `json:"require_commit_jira_key,omitempty"`
+	// SubjectMaxLength is the longest commit subject accepted (default 72).
+	SubjectMaxLength int `json:"subject_max_length,omitempty"`
+}
+
+// NotifyChannel is a destination for notifications.
+type NotifyChannel struct {
+	// Type is "slack" (incoming webhook), "telegram" (bot), "desktop" or "stdout".
+	Type string `json:"type"`
+	// Secret names the secret holding the webhook URL (slack) or bot token (telegram).
+	Secret string `json:"secret,omitempty"`
+	// ChatID is the Telegram chat the bot writes to.
+	ChatID string `json:"chat_id,omitempty"`
+}
+
+// Notify holds the notification channels and which events go where.
+type Notify struct {
+	// Channels maps a channel name to its destination.
+	Channels map[string]NotifyChannel `json:"channels,omitempty"`
+	// Routes maps an event ("release", "ci", "batch") or "*" to channel names.
+	// Without a route, release notifications go to the release.webhook secret.
+	Routes map[string][]string `json:"routes,omitempty"`
+	// DesktopAfter is how long one of LongCommands must run to end with a
+	// desktop notification when the terminal isn't focused (default "1m",
+	// "off" to disable).
+	DesktopAfter string `json:"desktop_after,omitempty"`
+	// LongCommands are the command paths watched for DesktopAfter, e.g.
+	// "ci status" (default: ci status, ci logs, release, do, prj backup, git rmerge).
+	LongCommands []string `json:"long_commands,omitempty"`
+}
+
+// Prompt holds the settings of the interactive prompts.
+type Prompt struct {
+	// Matcher filters selection lists while typing: "subsequence" (default,
+	// the typed letters in order), "fzf" (the same, best matches first) or
+	// "substring".
+	Matcher string `json:"matcher,omitempty"`
+	// Accessible switches every prompt to numbered plain-text menus read line
+	// by line, for screen readers and dumb terminals (same as --a11y).
+	Accessible bool `json:"accessible,omitempty"`
+	// Keys rebinds the keys of the selection lists: an action (up, down,
+	// accept, cancel, toggle, all, none, invert, preview, clear) to its keys,
+	// e.g. {"up": ["ctrl-k"], "down": ["ctrl-o", "tab"]}. Unlisted actions keep
+	// their default keys.
+	Keys map[string][]string `json:"keys,omitempty"`
+}
+
+// Profile holds the time budgets of the commands.
+type Profile struct {
+	// Budget maps a command path (e.g. "ztag qc", "git") or "*" to how long
+	// it may run, prompts aside, before aio warns and suggests --profile,
+	// e.g. {"*": "10s", "ztag": "30s"}. The longest matching path wins.
+	Budget map[string]string `json:"budget,omitempty"`
+}
+
+// Config is the user configuration stored in config.json.
+type Config struct {
+	GitLab   GitLab   `json:"gitlab"`
+	Jira     Jira     `json:"jira"`
+	Release  Release  `json:"release"`
+	ZTag     ZTag     `json:"ztag"`
+	Projects Projects `json:"projects"`
+	Notify   Notify   `json:"notify"`
+	Prompt   Prompt   `json:"prompt"`
+	Profile  Profile  `json:"profile"`
+	// Conventions are the branch and commit message rules of the team.
+	Conventions Conventions `json:"conventions"`
+	// Templates maps a template name to the git URL used by 'aio new'.
+	Templates map[string]string `json:"templates,omitempty"`
+	// Health maps a project (GitLab path or repository folder name) to its
+	// health endpoints per environment, e.g. {"qc": "https://.../health"}.
+	Health map[string]map[string]string `json:"health,omitempty"`
+	// OrgTemplates maps a template set name to the files 'prj clone' and
+	// 'aio new' can add to a repository (repository path -> content). Content
+	// starting with "@" is read from that file, relative to the config dir.
+	// Files under .githooks/ enable it as core.hooksPath.
+	OrgTemplates map[string]map[string]string `json:"org_templates,omitempty"`
+	// Encrypted lists the sections encrypted at rest with the keychain key:
+	// "secrets" (secrets.json), "db" (db.json) or a key of this file. Managed
+	// by 'aio secrets encrypt/decrypt'.
+	Encrypted []string `json:"encrypted,omitempty"`
+}
+
+// Dir returns the directory holding all cli-aio state (~/.config/cli-aio).
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "cli-aio"), nil
+}
+
+// Path returns the path of a file inside the config directory.
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// Load reads config.json, returning defaults when the file does not exist.
+func Load() (*Config, error) {
+	cfg := &Config{}
+	path, err := Path("config.json")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if data, err = decryptValues(data); err != nil {
+			return nil, fmt.Errorf("failed to decrypt config file %s: %w", path, err)
+		}
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	if cfg.GitLab.BaseURL == "" {
+		cfg.GitLab.BaseURL = DefaultGitLabURL
+	}
+	return cfg, nil
+}
+
+// Save writes the config to config.json. The sections listed in Encrypted,
+// decrypted by Load, are encrypted again.
+func Save(cfg *Config) error {
+	path, err := Path("config.json")
+	if err != nil {
+		return err
+	}
+	return WriteJSON(path, cfg)
+}
+
+// ReadJSON decodes the JSON file at path into v, decrypting it when encrypted.
+// It returns false without error when the file is missing or empty.
+func ReadJSON(path string, v interface{}) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return false, nil
+	}
+	if IsEncrypted(data) {
+		if data, err = decrypt(data); err != nil {
+			return false, fmt.Errorf("failed to decrypt %s: %w", path, err)
+		}
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// WriteJSON writes v as indented JSON to path, creating parent directories.
+func WriteJSON(path string, v interface{}) error {
+	return writeJSON(path, v, 0644)
+}
+
+// WritePrivateJSON is like WriteJSON but restricts the file to the current user (0600).
+// Use it for files holding credentials.
+func WritePrivateJSON(path string, v interface{}) error {
+	if err := writeJSON(path, v, 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file, so enforce it explicitly
+	return os.Chmod(path, 0600)
+}
+
+func writeJSON(path string, v interface{}, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", filepath.Base(path), err)
+	}
+	if isEncryptedFile(path) {
+		if data, err = encrypt(data); err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", filepath.Base(path), err)
+		}
+	}
+	// Whoever writes config.json, the sections marked encrypted stay so
+	if configPath, _ := Path("config.json"); path == configPath {
+		if data, err = encryptValues(data); err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", filepath.Base(path), err)
+		}
+	}
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,418 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// KeyEnv holds the base64 encryption key, overriding the keychain (e.g. on CI
+// or a machine without one).
+const KeyEnv = "AIO_CONFIG_KEY"
+
+// encPrefix marks an encrypted value: the prefix followed by the base64 of the
+// AES-GCM nonce and ciphertext.
+const encPrefix = "aio-enc:v1:"
+
+// Keychain entry holding the key.
+const (
+	keyService = "cli-aio"
+	keyAccount = "config-key"
+)
+
+// FileSections are the sections stored in their own file, encrypted as a whole.
+// Any other section is a key of config.json, e.g. "health" or "org_templates".
+var FileSections = map[string]string{
+	"secrets": "secrets.json", // tokens and webhooks
+	"db":      "db.json",      // database profiles
+}
+
+var (
+	keyMu  sync.Mutex
+	keyVal []byte
+)
+
+// IsEncrypted reports whether data (a file or a JSON value) is encrypted.
+func IsEncrypted(data []byte) bool {
+	var s string
+	return json.Unmarshal(bytes.TrimSpace(data), &s) == nil && strings.HasPrefix(s, encPrefix)
+}
+
+// encrypt returns plain encrypted as a JSON string value, creating the key
+// the first time.
+func encrypt(plain []byte) ([]byte, error) {
+	key, err := loadKey(true)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, plain, nil)
+	return json.Marshal(encPrefix + base64.StdEncoding.EncodeToString(sealed))
+}
+
+// decrypt reverses encrypt.
+func decrypt(data []byte) ([]byte, error) {
+	var s string
+	if err := json.Unmarshal(bytes.TrimSpace(data), &s); err != nil || !strings.HasPrefix(s, encPrefix) {
+		return nil, fmt.Errorf("not an encrypted value")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, encPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("corrupted encrypted value: %w", err)
+	}
+	key, err := loadKey(false)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("corrupted encrypted value")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt, wrong key? (set %s or run 'aio secrets key --set'): %w", KeyEnv, err)
+	}
+	return plain, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// Key returns the base64 encryption key, to copy it to another machine.
+func Key() (string, error) {
+	key, err := loadKey(false)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// SetKey stores the base64 key in the keychain, replacing the current one.
+func SetKey(encoded string) error {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != 32 {
+		return fmt.Errorf("invalid key, expected the base64 of 32 bytes as printed by 'aio secrets key'")
+	}
+	if err := storeKey(encoded); err != nil {
+		return err
+	}
+	keyMu.Lock()
+	keyVal = key
+	keyMu.Unlock()
+	return nil
+}
+
+// loadKey returns the key from AIO_CONFIG_KEY or the keychain, generating and
+// storing one when create is set and none exists.
+func loadKey(create bool) ([]byte, error) {
+	keyMu.Lock()
+	defer keyMu.Unlock()
+	if keyVal != nil {
+		return keyVal, nil
+	}
+
+	encoded := os.Getenv(KeyEnv)
+	if encoded == "" {
+		encoded = lookupKey()
+	}
+	if encoded == "" {
+		if !create {
+			return nil, fmt.Errorf("no encryption key found in the keychain, set %s or run 'aio secrets key --set'", KeyEnv)
+		}
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate key: %w", err)
+		}
+		encoded = base64.StdEncoding.EncodeToString(key)
+		if err := storeKey(encoded); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("invalid encryption key, expected the base64 of 32 bytes")
+	}
+	keyVal = key
+	return key, nil
+}
+
+// lookupKey reads the key from the macOS keychain, the Secret Service
+// (secret-tool) or, without either, the key file. Returns "" when not found.
+func lookupKey() string {
+	var out []byte
+	var err error
+	switch {
+	case runtime.GOOS == "darwin":
+		out, err = exec.Command("security", "find-generic-password", "-s", keyService, "-a", keyAccount, "-w").Output()
+	case hasSecretTool():
+		out, err = exec.Command("secret-tool", "lookup", "service", keyService, "account", keyAccount).Output()
+	default:
+		path, pathErr := keyFile()
+		if pathErr != nil {
+			return ""
+		}
+		out, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// storeKey saves the key where lookupKey finds it.
+func storeKey(encoded string) error {
+	var err error
+	switch {
+	case runtime.GOOS == "darwin":
+		// -w last without a value reads the key from stdin (twice, as it asks
+		// to retype it), keeping it out of the arguments other users can list
+		add := exec.Command("security", "add-generic-password", "-U", "-s", keyService, "-a", keyAccount, "-w")
+		add.Stdin = strings.NewReader(encoded + "\n" + encoded + "\n")
+		err = add.Run()
+	case hasSecretTool():
+		store := exec.Command("secret-tool", "store", "--label=cli-aio config key", "service", keyService, "account", keyAccount)
+		store.Stdin = strings.NewReader(encoded)
+		err = store.Run()
+	default:
+		path, pathErr := keyFile()
+		if pathErr != nil {
+			return pathErr
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return fmt.Errorf("failed to create key directory: %w", err)
+		}
+		err = os.WriteFile(path, []byte(encoded+"\n"), 0600)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store the encryption key: %w", err)
+	}
+	return nil
+}
+
+func hasSecretTool() bool {
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+// keyFile is where the key is kept without a keychain. It is outside the
+// config dir so syncing that dir never syncs the key.
+func keyFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", "cli-aio", "config.key"), nil
+}
+
+// EncryptedSections returns the sections marked encrypted in config.json.
+func EncryptedSections() []string {
+	raw, err := readRaw()
+	if err != nil {
+		return nil
+	}
+	var sections []string
+	if data, ok := raw["encrypted"]; ok {
+		_ = json.Unmarshal(data, &sections)
+	}
+	return sections
+}
+
+// isEncryptedFile reports whether the file at path belongs to a section marked encrypted.
+func isEncryptedFile(path string) bool {
+	for _, section := range EncryptedSections() {
+		if file, ok := FileSections[section]; ok && filepath.Base(path) == file {
+			return true
+		}
+	}
+	return false
+}
+
+// SetEncrypted encrypts (on) or decrypts a section at rest and records it in
+// config.json's "encrypted" list, so later writes keep it that way.
+func SetEncrypted(section string, on bool) error {
+	raw, err := readRaw()
+	if err != nil {
+		return err
+	}
+	if raw == nil {
+		raw = map[string]json.RawMessage{}
+	}
+
+	var sections []string
+	if data, ok := raw["encrypted"]; ok {
+		_ = json.Unmarshal(data, &sections)
+	}
+	sections = removeString(sections, section)
+	if on {
+		sections = append(sections, section)
+	}
+
+	if file, ok := FileSections[section]; ok {
+		if err := writeEncryptedList(raw, sections); err != nil {
+			return err
+		}
+		return reencryptFile(file)
+	}
+
+	value, ok := raw[section]
+	if !ok {
+		return fmt.Errorf("unknown section '%s': use secrets, db or a key of config.json", section)
+	}
+	if IsEncrypted(value) && !on {
+		if raw[section], err = decrypt(value); err != nil {
+			return err
+		}
+	} else if !IsEncrypted(value) && on {
+		if raw[section], err = encrypt(value); err != nil {
+			return err
+		}
+	}
+	return writeEncryptedList(raw, sections)
+}
+
+// reencryptFile rewrites a section file so it matches the encrypted list.
+func reencryptFile(name string) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+	var v interface{}
+	found, err := ReadJSON(path, &v)
+	if err != nil || !found {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return writeJSON(path, v, info.Mode().Perm())
+}
+
+func writeEncryptedList(raw map[string]json.RawMessage, sections []string) error {
+	if len(sections) == 0 {
+		delete(raw, "encrypted")
+	} else {
+		data, err := json.Marshal(sections)
+		if err != nil {
+			return err
+		}
+		raw["encrypted"] = data
+	}
+	path, err := Path("config.json")
+	if err != nil {
+		return err
+	}
+	return writeJSON(path, raw, 0644)
+}
+
+// readRaw reads config.json as its top-level keys, nil when it does not exist.
+func readRaw() (map[string]json.RawMessage, error) {
+	path, err := Path("config.json")
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) || len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return raw, nil
+}
+
+// encryptValues encrypts the top-level values of a config.json document
+// listed in its "encrypted" key that are in clear, e.g. after Load decrypted
+// them. Sections stored in their own file are left to writeJSON.
+func encryptValues(data []byte) ([]byte, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	var sections []string
+	if list, ok := raw["encrypted"]; ok {
+		_ = json.Unmarshal(list, &sections)
+	}
+	changed := false
+	for _, section := range sections {
+		value, ok := raw[section]
+		if _, own := FileSections[section]; own || !ok || IsEncrypted(value) {
+			continue
+		}
+		encrypted, err := encrypt(value)
+		if err != nil {
+			return nil, fmt.Errorf("section '%s': %w", section, err)
+		}
+		raw[section] = encrypted
+		changed = true
+	}
+	if !changed {
+		return data, nil
+	}
+	return json.MarshalIndent(raw, "", "  ")
+}
+
+// decryptValues replaces the encrypted top-level values of a config.json document.
+func decryptValues(data []byte) ([]byte, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	changed := false
+	for k, v := range raw {
+		if !IsEncrypted(v) {
+			continue
+		}
+		plain, err := decrypt(v)
+		if err != nil {
+			return nil, fmt.Errorf("section '%s': %w", k, err)
+		}
+		raw[k] = plain
+		changed = true
+	}
+	if !changed {
+		return data, nil
+	}
+	return json.Marshal(raw)
+}
+
+func removeString(list []string, s string) []string {
+	out := list[:0]
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
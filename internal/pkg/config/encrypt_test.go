@@ -0,0 +1,96 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// testKey is the base64 of 32 bytes, so the tests never touch the keychain.
+const testKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+func TestSaveKeepsSectionsEncrypted(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(KeyEnv, testKey)
+	health := map[string]map[string]string{"payment-api": {"qc": "https://qc.example.com/health"}}
+	if err := Save(&Config{Health: health}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetEncrypted("health", true); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Health["payment-api"]["qc"] == "" {
+		t.Fatalf("Load didn't decrypt health: %+v", cfg.Health)
+	}
+	cfg.Jira.BaseURL = "https://jira.example.com"
+	if err := Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := readRaw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(raw["health"]) {
+		t.Errorf("Save wrote health in clear: %s", raw["health"])
+	}
+	if IsEncrypted(raw["jira"]) {
+		t.Errorf("Save encrypted jira, which isn't listed: %s", raw["jira"])
+	}
+	cfg, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Health["payment-api"]["qc"] != "https://qc.example.com/health" || cfg.Jira.BaseURL != "https://jira.example.com" {
+		t.Errorf("config after saving = %+v", cfg)
+	}
+}
+
+func TestSetEncryptedFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(KeyEnv, testKey)
+	path, err := Path(FileSections["secrets"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	secrets := map[string]string{"gitlab": "glpat-123"}
+	if err := WritePrivateJSON(path, secrets); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetEncrypted("secrets", true); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(data) {
+		t.Fatalf("%s not encrypted: %s", path, data)
+	}
+
+	// Later writes keep it encrypted
+	secrets["jira"] = "jira-456"
+	if err := WritePrivateJSON(path, secrets); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); !IsEncrypted(data) {
+		t.Errorf("%s written in clear: %s", path, data)
+	}
+	var got map[string]string
+	if _, err := ReadJSON(path, &got); err != nil || got["jira"] != "jira-456" {
+		t.Errorf("ReadJSON = %v, %v", got, err)
+	}
+
+	if err := SetEncrypted("secrets", false); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(path)
+	if err := json.Unmarshal(data, &got); err != nil || got["gitlab"] != "glpat-123" {
+		t.Errorf("%s after decrypting = %s", path, data)
+	}
+}
@@ -0,0 +1,224 @@
+// Package convention checks branch names and commit messages against the
+// team's rules from config.json.
+package convention
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"cli-aio/internal/pkg/config"
+)
+
+// DefaultBranchTypes are the branch prefixes accepted without conventions.branch_types.
+var DefaultBranchTypes = []string{"feature", "fix", "hotfix", "chore", "docs", "refactor", "test"}
+
+// DefaultExemptBranches are never checked without conventions.exempt_branches.
+var DefaultExemptBranches = []string{"main", "master", "develop", "release/*"}
+
+// DefaultProtectedBranches are never force-pushed without conventions.protected_branches.
+var DefaultProtectedBranches = []string{"main", "master", "develop", "release/*"}
+
+// typeAliases maps common spellings to the usual branch types.
+var typeAliases = map[string]string{
+	"feat": "feature", "features": "feature", "feature": "feature",
+	"bug": "fix", "bugfix": "fix", "fix": "fix",
+	"hot": "hotfix", "hotfix": "hotfix",
+	"doc": "docs", "docs": "docs",
+	"refactoring": "refactor", "refactor": "refactor",
+	"tests": "test", "test": "test",
+	"chores": "chore", "chore": "chore",
+}
+
+var (
+	issueKey    = regexp.MustCompile(`[A-Z][A-Z0-9]+-\d+`)
+	description = regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*$`)
+	nonSlug     = regexp.MustCompile(`[^a-z0-9.]+`)
+)
+
+// Rules are the conventions of config.json, ready to check names.
+type Rules struct {
+	cfg           config.Conventions
+	types         []string
+	exempt        []string
+	protected     []string
+	pattern       *regexp.Regexp
+	commitTypes   []string
+	commitPattern *regexp.Regexp
+	maxSubject    int
+}
+
+// NewRules compiles the conventions, filling in the defaults.
+func NewRules(cfg config.Conventions) (*Rules, error) {
+	r := &Rules{cfg: cfg, types: cfg.BranchTypes, exempt: cfg.ExemptBranches, protected: cfg.ProtectedBranches,
+		commitTypes: cfg.CommitTypes, maxSubject: cfg.SubjectMaxLength}
+	if len(r.types) == 0 {
+		r.types = DefaultBranchTypes
+	}
+	if len(r.exempt) == 0 {
+		r.exempt = DefaultExemptBranches
+	}
+	if len(r.protected) == 0 {
+		r.protected = DefaultProtectedBranches
+	}
+	if cfg.BranchPattern != "" {
+		pattern, err := regexp.Compile(cfg.BranchPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid conventions.branch_pattern: %w", err)
+		}
+		r.pattern = pattern
+	}
+	if len(r.commitTypes) == 0 {
+		r.commitTypes = DefaultCommitTypes
+	}
+	if r.maxSubject <= 0 {
+		r.maxSubject = DefaultSubjectMaxLength
+	}
+	if cfg.CommitPattern != "" {
+		pattern, err := regexp.Compile(cfg.CommitPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid conventions.commit_pattern: %w", err)
+		}
+		r.commitPattern = pattern
+	}
+	return r, nil
+}
+
+// BranchTypes returns the allowed branch prefixes, none when branch names
+// follow conventions.branch_pattern instead.
+func (r *Rules) BranchTypes() []string {
+	if r.pattern != nil {
+		return nil
+	}
+	return r.types
+}
+
+// Exempt reports whether branch is not subject to the conventions.
+func (r *Rules) Exempt(branch string) bool {
+	return matchAny(r.exempt, branch)
+}
+
+// Protected reports whether branch must never be force-pushed.
+func (r *Rules) Protected(branch string) bool {
+	return matchAny(r.protected, branch)
+}
+
+// matchAny reports whether branch matches one of the glob patterns.
+func matchAny(patterns []string, branch string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckBranch returns what is wrong with the branch name, nothing when it
+// follows the conventions.
+func (r *Rules) CheckBranch(branch string) []string {
+	if r.Exempt(branch) {
+		return nil
+	}
+	var problems []string
+	if r.pattern != nil {
+		if !r.pattern.MatchString(branch) {
+			problems = append(problems, fmt.Sprintf("doesn't match the branch pattern %s", r.pattern))
+		}
+	} else {
+		problems = append(problems, r.checkTypedName(branch)...)
+	}
+
+	return append(problems, r.checkJiraKey(branch, r.cfg.RequireJiraKey)...)
+}
+
+// checkJiraKey checks the first issue key of text: required or not, it must
+// belong to one of the configured Jira projects.
+func (r *Rules) checkJiraKey(text string, required bool) []string {
+	key := issueKey.FindString(text)
+	if key == "" && required {
+		return []string{"missing a Jira issue key (e.g. ABC-123)"}
+	}
+	if key != "" && len(r.cfg.JiraProjects) > 0 {
+		project, _, _ := strings.Cut(key, "-")
+		if !slices.Contains(r.cfg.JiraProjects, project) {
+			return []string{fmt.Sprintf("issue key %s is not from %s", key, strings.Join(r.cfg.JiraProjects, ", "))}
+		}
+	}
+	return nil
+}
+
+// checkTypedName checks the default "<type>/<KEY-123->description" rule.
+func (r *Rules) checkTypedName(branch string) []string {
+	kind, rest, ok := strings.Cut(branch, "/")
+	if !ok {
+		return []string{fmt.Sprintf("missing a <type>/ prefix (%s)", strings.Join(r.types, ", "))}
+	}
+	var problems []string
+	if !slices.Contains(r.types, kind) {
+		problems = append(problems, fmt.Sprintf("unknown type '%s' (%s)", kind, strings.Join(r.types, ", ")))
+	}
+	if loc := issueKey.FindStringIndex(rest); loc != nil && loc[0] == 0 {
+		rest = strings.TrimPrefix(rest[loc[1]:], "-")
+		if rest == "" {
+			return problems
+		}
+	}
+	if !description.MatchString(rest) {
+		problems = append(problems, "description should be lowercase words separated by '-'")
+	}
+	return problems
+}
+
+// SuggestBranch proposes a name following the conventions for branch, keeping
+// its type, issue key and words. It returns "" when none can be made, e.g.
+// when the issue key is required but missing.
+func (r *Rules) SuggestBranch(branch string) string {
+	kind := ""
+	rest := branch
+	if prefix, after, ok := strings.Cut(branch, "/"); ok {
+		if t := r.branchType(prefix); t != "" {
+			kind, rest = t, after
+		}
+	} else if prefix, after, ok := strings.Cut(branch, "-"); ok {
+		if t := r.branchType(prefix); t != "" {
+			kind, rest = t, after
+		}
+	}
+	if kind == "" {
+		kind = r.types[0]
+	}
+
+	key := issueKey.FindString(rest)
+	if key != "" {
+		rest = strings.Replace(rest, key, "", 1)
+	}
+	words := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(rest), "-"), "-.")
+
+	suggestion := kind + "/"
+	switch {
+	case key != "" && words != "":
+		suggestion += key + "-" + words
+	case key != "":
+		suggestion += key
+	default:
+		suggestion += words
+	}
+	if suggestion == branch || len(r.CheckBranch(suggestion)) > 0 {
+		return ""
+	}
+	return suggestion
+}
+
+// branchType returns the configured type meant by prefix, "" when none.
+func (r *Rules) branchType(prefix string) string {
+	prefix = strings.ToLower(prefix)
+	if slices.Contains(r.types, prefix) {
+		return prefix
+	}
+	if t, ok := typeAliases[prefix]; ok && slices.Contains(r.types, t) {
+		return t
+	}
+	return ""
+}
@@ -0,0 +1,165 @@
+package convention
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultCommitTypes are the conventional commit types accepted without conventions.commit_types.
+var DefaultCommitTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// DefaultSubjectMaxLength is the longest subject accepted without conventions.subject_max_length.
+const DefaultSubjectMaxLength = 72
+
+// commitAliases maps the first word of a free-form subject to a commit type.
+var commitAliases = map[string]string{
+	"add": "feat", "added": "feat", "adds": "feat", "implement": "feat", "implemented": "feat",
+	"feature": "feat", "feat": "feat", "support": "feat",
+	"fix": "fix", "fixed": "fix", "fixes": "fix", "bugfix": "fix", "hotfix": "fix",
+	"doc": "docs", "docs": "docs", "document": "docs", "documented": "docs",
+	"refactor": "refactor", "refactored": "refactor", "refactoring": "refactor", "simplify": "refactor",
+	"test": "test", "tests": "test",
+	"perf": "perf", "optimize": "perf", "optimise": "perf", "speed": "perf",
+	"bump": "build", "upgrade": "build", "build": "build",
+	"ci":    "ci",
+	"chore": "chore", "update": "chore", "cleanup": "chore", "remove": "chore",
+	"revert": "revert", "reverted": "revert",
+	"style": "style", "format": "style", "lint": "style",
+}
+
+var (
+	conventionalSubject = regexp.MustCompile(`^([a-zA-Z]+)(\([^()]*\))?(!)?: (.*)$`)
+	autosquash          = regexp.MustCompile(`^(fixup|squash|amend)! `)
+	wip                 = regexp.MustCompile(`(?i)^(\[wip\]|wip\b)`)
+)
+
+// WorkInProgress reports whether a commit isn't meant to be pushed as is: a
+// "WIP" commit or a fixup!/squash!/amend! commit waiting for an autosquash.
+func WorkInProgress(subject string) bool {
+	return wip.MatchString(subject) || autosquash.MatchString(subject)
+}
+
+// SkipCommit reports whether a commit is not checked: merges and the commits
+// made by 'git revert', whose messages git writes.
+func SkipCommit(subject string, merge bool) bool {
+	return merge || strings.HasPrefix(subject, "Merge ") || strings.HasPrefix(subject, `Revert "`)
+}
+
+// CheckCommit returns what is wrong with a commit message (its subject and the
+// rest of the message), nothing when it follows the conventions.
+func (r *Rules) CheckCommit(subject string, body string) []string {
+	if match := autosquash.FindStringSubmatch(subject); match != nil {
+		return []string{fmt.Sprintf("%s! commit, squash it before pushing (git rebase -i --autosquash)", match[1])}
+	}
+	var problems []string
+	if r.commitPattern != nil {
+		if !r.commitPattern.MatchString(subject) {
+			problems = append(problems, fmt.Sprintf("doesn't match the commit pattern %s", r.commitPattern))
+		}
+	} else {
+		problems = append(problems, r.checkConventional(subject)...)
+	}
+	if n := utf8.RuneCountInString(subject); n > r.maxSubject {
+		problems = append(problems, fmt.Sprintf("subject is %d characters long (max %d)", n, r.maxSubject))
+	}
+	if body != "" && !strings.HasPrefix(body, "\n") {
+		problems = append(problems, "missing a blank line after the subject")
+	}
+	return append(problems, r.checkJiraKey(subject+"\n"+body, r.cfg.RequireCommitJiraKey)...)
+}
+
+// checkConventional checks the default "<type>(<scope>): <description>" rule.
+func (r *Rules) checkConventional(subject string) []string {
+	match := conventionalSubject.FindStringSubmatch(subject)
+	if match == nil {
+		return []string{fmt.Sprintf("not a conventional commit: <type>(<scope>): <description> (%s)", strings.Join(r.commitTypes, ", "))}
+	}
+	var problems []string
+	if !slices.Contains(r.commitTypes, match[1]) {
+		problems = append(problems, fmt.Sprintf("unknown type '%s' (%s)", match[1], strings.Join(r.commitTypes, ", ")))
+	}
+	if match[2] == "()" {
+		problems = append(problems, "empty scope")
+	}
+	description := match[4]
+	switch {
+	case strings.TrimSpace(description) == "":
+		problems = append(problems, "missing a description after the type")
+	case strings.HasPrefix(description, " "):
+		problems = append(problems, "more than one space after ':'")
+	case strings.HasSuffix(description, "."):
+		problems = append(problems, "description ends with a period")
+	}
+	return problems
+}
+
+// SuggestSubject proposes a subject following the conventions for subject,
+// guessing the type from its first word ("Fixed login." -> "fix: login",
+// "Add export" -> "feat: add export").
+// It returns "" when none can be made, e.g. with conventions.commit_pattern.
+func (r *Rules) SuggestSubject(subject string) string {
+	if r.commitPattern != nil || autosquash.MatchString(subject) {
+		return ""
+	}
+	kind, scope, description := "", "", strings.TrimSpace(subject)
+	if match := conventionalSubject.FindStringSubmatch(subject); match != nil {
+		kind, scope, description = r.commitType(match[1]), match[2], strings.TrimSpace(match[4])
+		if scope == "()" {
+			scope = ""
+		}
+		if match[3] != "" {
+			scope += "!"
+		}
+	} else {
+		// A leading issue key stays in front of the description
+		key := ""
+		if loc := issueKey.FindStringIndex(description); loc != nil && loc[0] == 0 {
+			key, description = description[:loc[1]], strings.TrimLeft(description[loc[1]:], " :-")
+		}
+		// "Fixed x" becomes "fix: x" but "Add x" keeps its verb: "feat: add x"
+		word, rest, _ := strings.Cut(description, " ")
+		word = strings.ToLower(strings.Trim(word, ":"))
+		if kind = r.commitType(word); kind != "" && (strings.HasPrefix(word, kind) || strings.HasPrefix(kind, word)) {
+			description = rest
+		}
+		if key != "" {
+			description = key + " " + lowerFirst(description)
+		}
+	}
+	if kind == "" {
+		return ""
+	}
+	description = lowerFirst(strings.TrimRight(strings.TrimSpace(description), "."))
+	suggestion := kind + scope + ": " + description
+	if suggestion == subject || len(r.checkConventional(suggestion)) > 0 {
+		return ""
+	}
+	return suggestion
+}
+
+// commitType returns the configured commit type meant by word, "" when none.
+func (r *Rules) commitType(word string) string {
+	word = strings.ToLower(word)
+	if slices.Contains(r.commitTypes, word) {
+		return word
+	}
+	if t, ok := commitAliases[word]; ok && slices.Contains(r.commitTypes, t) {
+		return t
+	}
+	return ""
+}
+
+// lowerFirst lowercases the first letter of s unless its first word looks like
+// an acronym or an issue key (API, ABC-123).
+func lowerFirst(s string) string {
+	first, size := utf8.DecodeRuneInString(s)
+	word, _, _ := strings.Cut(s[size:], " ")
+	if size == 0 || strings.IndexFunc(word, unicode.IsUpper) >= 0 {
+		return s
+	}
+	return string(unicode.ToLower(first)) + s[size:]
+}
@@ -0,0 +1,196 @@
+// Package crash turns a panic into a diagnostic bundle in the config dir, so
+// users get a short message and a file to attach to an issue instead of a
+// raw stack trace.
+package crash
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"cli-aio/internal/pkg/config"
+)
+
+// DisableEnv set to a non-empty value lets panics crash as usual, with the
+// raw stack trace, e.g. when debugging aio itself.
+const DisableEnv = "AIO_NO_CRASH_REPORT"
+
+// logLines is how many lines of each log end up in the bundle.
+const logLines = 20
+
+// Build describes the aio binary, set by Setup.
+type Build struct {
+	Version   string
+	Commit    string
+	BuildTime string
+}
+
+var (
+	mu    sync.Mutex
+	build Build
+	args  []string
+	// reported makes the first panicking goroutine the one reported
+	reported bool
+)
+
+// Setup records what the bundle says about the binary and the command line.
+func Setup(b Build, commandLine []string) {
+	mu.Lock()
+	defer mu.Unlock()
+	build, args = b, commandLine
+}
+
+// Guard reports a panic of the calling goroutine and exits with status 2,
+// like an unrecovered panic. It must be deferred directly:
+//
+//	defer crash.Guard()
+func Guard() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if os.Getenv(DisableEnv) != "" {
+		panic(r)
+	}
+	stack := debug.Stack()
+	mu.Lock()
+	if reported {
+		// Another goroutine is writing its bundle and exits
+		mu.Unlock()
+		select {}
+	}
+	reported = true
+	mu.Unlock()
+
+	fmt.Fprintf(os.Stderr, "[-] aio crashed: %v\n", r)
+	path, err := write(r, stack)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[!] Failed to write the crash report (%v), the stack trace follows:\n%s", err, stack)
+		os.Exit(2)
+	}
+	fmt.Fprintf(os.Stderr, "    A crash report was written to %s\n", path)
+	fmt.Fprintln(os.Stderr, "    Please attach it to an issue with what you were doing. Tokens and passwords are removed, but review it before sharing.")
+	os.Exit(2)
+}
+
+// write writes the bundle of the panic r to crashes/ in the config dir.
+func write(r interface{}, stack []byte) (string, error) {
+	dir, err := config.Path("crashes")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	now := time.Now()
+	path := filepath.Join(dir, "crash-"+now.Format("20060102-150405")+".txt")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "aio crash report, %s\n\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&b, "aio:     %s (commit %s, built %s)\n", build.Version, build.Commit, build.BuildTime)
+	fmt.Fprintf(&b, "go:      %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "git:     %s\n", gitVersion())
+	fmt.Fprintf(&b, "command: aio %s\n", strings.Join(SanitizeArgs(args), " "))
+	if wd, err := os.Getwd(); err == nil {
+		fmt.Fprintf(&b, "dir:     %s\n", Sanitize(wd))
+	}
+	fmt.Fprintf(&b, "\npanic: %s\n\n%s", Sanitize(fmt.Sprint(r)), stack)
+	for _, name := range []string{"audit.log", "daemon.log"} {
+		if tail := logTail(name); tail != "" {
+			fmt.Fprintf(&b, "\nlast lines of %s:\n%s", name, tail)
+		}
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// gitVersion returns the output of git --version, or why there is none.
+func gitVersion() string {
+	output, err := exec.Command("git", "--version").Output()
+	if err != nil {
+		return "unknown (" + err.Error() + ")"
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// logTail returns the last logLines lines of the log name in the config dir,
+// sanitized, "" when there is none.
+func logTail(name string) string {
+	path, err := config.Path(name)
+	if err != nil {
+		return ""
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, Sanitize(scanner.Text()))
+		if len(lines) > logLines {
+			lines = lines[1:]
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// secretFlag matches the flags whose value is a credential, e.g. --token.
+var secretFlag = regexp.MustCompile(`(?i)^--?[a-z0-9-]*(token|password|passwd|secret|api-?key)[a-z0-9-]*$`)
+
+// SanitizeArgs returns the command line with the values of credential flags
+// and the credentials in the other arguments replaced by <redacted>.
+func SanitizeArgs(commandLine []string) []string {
+	sanitized := make([]string, len(commandLine))
+	for i, arg := range commandLine {
+		name, _, hasValue := strings.Cut(arg, "=")
+		switch {
+		case secretFlag.MatchString(name) && hasValue:
+			sanitized[i] = name + "=<redacted>"
+		case i > 0 && secretFlag.MatchString(commandLine[i-1]) && !strings.Contains(commandLine[i-1], "="):
+			sanitized[i] = "<redacted>"
+		default:
+			sanitized[i] = Sanitize(arg)
+		}
+	}
+	return sanitized
+}
+
+// credentials match the secrets Sanitize removes from text: passwords in
+// URLs, GitLab tokens, Authorization headers and key=value credentials.
+var credentials = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`://[^/\s:@]+:[^/\s@]+@`), "://<redacted>@"},
+	{regexp.MustCompile(`gl(pat|dt|rt|ptt)-[A-Za-z0-9_-]{10,}`), "<redacted>"},
+	{regexp.MustCompile(`(?i)(authorization:\s*(?:bearer|basic|token)\s+)[^\s"']+`), "${1}<redacted>"},
+	{regexp.MustCompile(`(?i)((?:token|password|passwd|secret|api[_-]?key)["']?\s*[:=]\s*["']?)[^\s"'&,}]+`), "${1}<redacted>"},
+}
+
+// Sanitize removes credentials from text and shortens the home directory to
+// ~, which holds the user name.
+func Sanitize(text string) string {
+	for _, c := range credentials {
+		text = c.pattern.ReplaceAllString(text, c.replacement)
+	}
+	if home, err := os.UserHomeDir(); err == nil && home != "" && home != "/" {
+		text = strings.ReplaceAll(text, home, "~")
+	}
+	return text
+}
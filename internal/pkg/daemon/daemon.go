@@ -0,0 +1,149 @@
+// Package daemon keeps slow-to-fetch data (branch lists, MR lists, pipeline
+// status, project scans) warm in a background process. Commands ask it over a
+// unix socket and fall back to fetching themselves when no daemon answers.
+package daemon
+
+import (
+	"cli-aio/internal/pkg/config"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DisableEnv set to a non-empty value makes commands ignore a running daemon.
+const DisableEnv = "AIO_NO_DAEMON"
+
+// Cached kinds served by the daemon.
+const (
+	KindBranches    = "branches"     // args: repository dir; branches of origin, listed with ls-remote
+	KindReviewQueue = "review-queue" // MRs waiting for the token's user
+	KindProjects    = "projects"     // git status of every saved project
+)
+
+// Control kinds.
+const (
+	kindPing    = "ping"
+	kindStop    = "stop"
+	kindEntries = "entries"
+)
+
+const (
+	// dialTimeout keeps commands fast when the socket is stale.
+	dialTimeout = 200 * time.Millisecond
+	// replyTimeout covers a cache miss, which the daemon fetches before answering.
+	replyTimeout = 60 * time.Second
+)
+
+// Request asks the daemon for the value of a kind.
+type Request struct {
+	Kind string   `json:"kind"`
+	Args []string `json:"args,omitempty"`
+}
+
+// Response carries a cached value, or why there is none.
+type Response struct {
+	Value     json.RawMessage `json:"value,omitempty"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// Entry describes a cached value, as listed by 'aio daemon status'.
+type Entry struct {
+	Kind      string    `json:"kind"`
+	Args      []string  `json:"args,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UsedAt    time.Time `json:"used_at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// SocketPath returns the daemon's unix socket in the config directory.
+func SocketPath() (string, error) {
+	return config.Path("daemon.sock")
+}
+
+// LogPath returns the file the daemon logs to, creating the config
+// directory holding it.
+func LogPath() (string, error) {
+	path, err := config.Path("daemon.log")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return path, nil
+}
+
+// Query asks a running daemon for kind and decodes its value into out. It
+// returns the time the value was fetched and false when there is no daemon or
+// no value, in which case the caller fetches the data itself.
+func Query(kind string, args []string, out interface{}) (time.Time, bool) {
+	if os.Getenv(DisableEnv) != "" {
+		return time.Time{}, false
+	}
+	resp, err := call(Request{Kind: kind, Args: args})
+	if err != nil || resp.Error != "" || len(resp.Value) == 0 {
+		return time.Time{}, false
+	}
+	if err := json.Unmarshal(resp.Value, out); err != nil {
+		return time.Time{}, false
+	}
+	return resp.UpdatedAt, true
+}
+
+// Running reports whether a daemon answers on the socket.
+func Running() bool {
+	_, err := call(Request{Kind: kindPing})
+	return err == nil
+}
+
+// Stop asks the running daemon to exit.
+func Stop() error {
+	resp, err := call(Request{Kind: kindStop})
+	if err != nil {
+		return fmt.Errorf("daemon is not running: %w", err)
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("daemon refused to stop: %s", resp.Error)
+	}
+	return nil
+}
+
+// Entries lists what the running daemon has cached.
+func Entries() ([]Entry, error) {
+	resp, err := call(Request{Kind: kindEntries})
+	if err != nil {
+		return nil, fmt.Errorf("daemon is not running: %w", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(resp.Value, &entries); err != nil {
+		return nil, fmt.Errorf("invalid daemon response: %w", err)
+	}
+	return entries, nil
+}
+
+// call sends one request per connection and reads the response.
+func call(req Request) (*Response, error) {
+	path, err := SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("unix", path, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(replyTimeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, err
+	}
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
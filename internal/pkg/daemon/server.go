@@ -0,0 +1,230 @@
+package daemon
+
+import (
+	"cli-aio/internal/pkg/offline"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// idleTimeout drops entries no command asked for in a while, so the daemon
+// stops fetching for repositories the user no longer works in.
+const idleTimeout = 2 * time.Hour
+
+// logger writes what the daemon reports to daemon.log, opened by Serve,
+// whether it runs in the background or in a terminal.
+var logger = log.New(os.Stderr, "", log.LstdFlags)
+
+// entry is a cached value. fetching serializes fetches of the same entry.
+type entry struct {
+	req       Request
+	value     json.RawMessage
+	updatedAt time.Time
+	usedAt    time.Time
+	err       string
+	warm      bool
+	fetching  sync.Mutex
+}
+
+// Server answers requests from the cache and refreshes it every Interval.
+type Server struct {
+	Interval time.Duration
+
+	mu       sync.Mutex
+	entries  map[string]*entry
+	listener net.Listener
+}
+
+// Listen creates the socket, replacing a stale one left by a crashed daemon.
+func Listen() (net.Listener, error) {
+	path, err := SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	if Running() {
+		return nil, fmt.Errorf("daemon is already running (%s)", path)
+	}
+	os.Remove(path)
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// Serve warms the cache and answers requests on l until a stop request.
+func (s *Server) Serve(l net.Listener) error {
+	s.entries = make(map[string]*entry)
+	s.listener = l
+	defer os.Remove(l.Addr().String())
+
+	logPath, err := LogPath()
+	if err != nil {
+		return err
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", logPath, err)
+	}
+	defer logFile.Close()
+	logger.SetOutput(logFile)
+	defer logger.SetOutput(os.Stderr)
+	logger.Printf("[+] Listening on %s (pid %d, refresh every %s)", l.Addr(), os.Getpid(), s.Interval)
+
+	for _, req := range warm {
+		e := s.lookup(req)
+		e.warm = true
+		go s.refresh(e)
+	}
+	go s.refreshLoop()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("daemon accept failed: %w", err)
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(replyTimeout))
+
+	var req Request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+	resp := s.answer(req)
+	json.NewEncoder(conn).Encode(resp)
+	if req.Kind == kindStop {
+		logger.Println("Stop requested")
+		s.listener.Close()
+	}
+}
+
+func (s *Server) answer(req Request) Response {
+	switch req.Kind {
+	case kindPing, kindStop:
+		return Response{}
+	case kindEntries:
+		data, err := json.Marshal(s.list())
+		if err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{Value: data}
+	}
+	if _, ok := sources[req.Kind]; !ok {
+		return Response{Error: fmt.Sprintf("unknown kind: %s", req.Kind)}
+	}
+
+	e := s.lookup(req)
+	s.mu.Lock()
+	e.usedAt = time.Now()
+	cached := e.value != nil
+	s.mu.Unlock()
+	// A miss is fetched right away; the entry is refreshed in the background from now on
+	if !cached {
+		s.refresh(e)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Response{Value: e.value, UpdatedAt: e.updatedAt, Error: e.err}
+}
+
+// lookup returns the entry for req, creating it when needed.
+func (s *Server) lookup(req Request) *entry {
+	key := req.Kind + "\x00" + strings.Join(req.Args, "\x00")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		e = &entry{req: req}
+		s.entries[key] = e
+	}
+	return e
+}
+
+// refresh fetches the entry's value. A failed fetch keeps the previous value.
+func (s *Server) refresh(e *entry) {
+	e.fetching.Lock()
+	defer e.fetching.Unlock()
+
+	value, err := sources[e.req.Kind](context.Background(), e.req.Args)
+	var data []byte
+	if err == nil {
+		data, err = json.Marshal(value)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		// Log once per distinct error, not on every refresh
+		if err.Error() != e.err {
+			logger.Printf("[-] %s: %v", strings.Join(append([]string{e.req.Kind}, e.req.Args...), " "), err)
+		}
+		e.err = err.Error()
+		return
+	}
+	e.value = data
+	e.updatedAt = time.Now()
+	e.err = ""
+}
+
+func (s *Server) refreshLoop() {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		// A network failure switched the process offline, try the network again every round
+		offline.Set(false)
+		now := time.Now()
+		var due []*entry
+		s.mu.Lock()
+		for key, e := range s.entries {
+			if !e.warm && now.Sub(e.usedAt) > idleTimeout {
+				delete(s.entries, key)
+				continue
+			}
+			due = append(due, e)
+		}
+		s.mu.Unlock()
+
+		var wg sync.WaitGroup
+		for _, e := range due {
+			wg.Add(1)
+			go func(e *entry) {
+				defer wg.Done()
+				s.refresh(e)
+			}(e)
+		}
+		wg.Wait()
+	}
+}
+
+func (s *Server) list() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, Entry{Kind: e.req.Kind, Args: e.req.Args, UpdatedAt: e.updatedAt, UsedAt: e.usedAt, Error: e.err})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Kind != entries[j].Kind {
+			return entries[i].Kind < entries[j].Kind
+		}
+		return strings.Join(entries[i].Args, " ") < strings.Join(entries[j].Args, " ")
+	})
+	return entries
+}
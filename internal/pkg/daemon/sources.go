@@ -0,0 +1,64 @@
+package daemon
+
+import (
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/gitlab"
+	"cli-aio/internal/pkg/project"
+	"context"
+	"fmt"
+)
+
+// source fetches the current value of a kind.
+type source func(ctx context.Context, args []string) (interface{}, error)
+
+var sources = map[string]source{
+	KindBranches:    fetchBranches,
+	KindReviewQueue: fetchReviewQueue,
+	KindProjects:    fetchProjects,
+}
+
+// warm lists what the daemon fetches on start and keeps refreshing even when unused.
+var warm = []Request{
+	{Kind: KindReviewQueue},
+	{Kind: KindProjects},
+}
+
+func fetchBranches(ctx context.Context, args []string) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%s expects a repository dir", KindBranches)
+	}
+	// ls-remote rather than fetch: the daemon must not update refs, run hooks
+	// or take the repository's lock behind the user's back
+	branches, err := git.LsRemoteBranchesIn(ctx, args[0])
+	if err == nil {
+		return branches, nil
+	}
+	// Offline or without origin, the branches of the last fetch still help
+	logger.Printf("[!] %v, using the last fetched branches", err)
+	return git.RemoteBranchesIn(ctx, args[0])
+}
+
+func fetchReviewQueue(ctx context.Context, args []string) (interface{}, error) {
+	client, err := gitlab.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	me, err := client.CurrentUser()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return client.ReviewQueue(me.ID, cfg.GitLab.Projects)
+}
+
+func fetchProjects(ctx context.Context, args []string) (interface{}, error) {
+	store, err := project.Load()
+	if err != nil {
+		return nil, err
+	}
+	return project.Scan(ctx, store.Projects), nil
+}
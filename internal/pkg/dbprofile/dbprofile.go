@@ -0,0 +1,203 @@
+package dbprofile
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/git"
+)
+
+// Supported drivers.
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	DriverRedis    = "redis"
+)
+
+// Drivers lists the supported drivers in display order.
+var Drivers = []string{DriverPostgres, DriverMySQL, DriverRedis}
+
+// DefaultPort returns the default port of a driver.
+func DefaultPort(driver string) int {
+	switch driver {
+	case DriverMySQL:
+		return 3306
+	case DriverRedis:
+		return 6379
+	default:
+		return 5432
+	}
+}
+
+// Profile is a named database connection. The password is never stored here,
+// only the name of the secret holding it.
+type Profile struct {
+	Name           string `json:"name"`
+	Driver         string `json:"driver"`
+	Host           string `json:"host"`
+	Port           int    `json:"port"`
+	User           string `json:"user,omitempty"`
+	Database       string `json:"database,omitempty"`
+	PasswordSecret string `json:"password_secret,omitempty"`
+}
+
+// Store maps a project root path to its profiles.
+type Store struct {
+	Projects map[string][]Profile `json:"projects"`
+}
+
+// ProjectKey returns the key profiles are stored under: the git repository
+// root, or the current directory outside of a repository.
+func ProjectKey(ctx context.Context) (string, error) {
+	if root, err := git.GetTopLevel(ctx); err == nil {
+		return root, nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine current directory: %w", err)
+	}
+	return wd, nil
+}
+
+// SecretName returns the default secret name for a profile's password.
+func SecretName(projectKey string, profile string) string {
+	return fmt.Sprintf("db.%s.%s", filepath.Base(projectKey), profile)
+}
+
+// Load reads db.json from the config directory.
+func Load() (*Store, error) {
+	path, err := config.Path("db.json")
+	if err != nil {
+		return nil, err
+	}
+	store := &Store{}
+	if _, err := config.ReadJSON(path, store); err != nil {
+		return nil, err
+	}
+	if store.Projects == nil {
+		store.Projects = map[string][]Profile{}
+	}
+	return store, nil
+}
+
+// Save writes the store to db.json.
+func Save(store *Store) error {
+	path, err := config.Path("db.json")
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, store)
+}
+
+// Profiles returns the profiles of a project sorted by name.
+func (s *Store) Profiles(projectKey string) []Profile {
+	profiles := append([]Profile(nil), s.Projects[projectKey]...)
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles
+}
+
+// Find returns the named profile of a project.
+func (s *Store) Find(projectKey string, name string) (*Profile, bool) {
+	for _, p := range s.Projects[projectKey] {
+		if p.Name == name {
+			return &p, true
+		}
+	}
+	return nil, false
+}
+
+// Put adds or replaces a profile of a project.
+func (s *Store) Put(projectKey string, p Profile) {
+	profiles := s.Projects[projectKey]
+	for i := range profiles {
+		if profiles[i].Name == p.Name {
+			profiles[i] = p
+			return
+		}
+	}
+	s.Projects[projectKey] = append(profiles, p)
+}
+
+// Remove deletes a profile of a project. Returns false if it did not exist.
+func (s *Store) Remove(projectKey string, name string) bool {
+	profiles := s.Projects[projectKey]
+	for i := range profiles {
+		if profiles[i].Name == name {
+			s.Projects[projectKey] = append(profiles[:i], profiles[i+1:]...)
+			if len(s.Projects[projectKey]) == 0 {
+				delete(s.Projects, projectKey)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// DSN builds a connection string for the profile using password.
+func (p Profile) DSN(password string) string {
+	hostPort := net.JoinHostPort(p.Host, strconv.Itoa(p.Port))
+	switch p.Driver {
+	case DriverMySQL:
+		cred := p.User
+		if password != "" {
+			cred += ":" + password
+		}
+		return fmt.Sprintf("%s@tcp(%s)/%s", cred, hostPort, p.Database)
+	case DriverRedis:
+		u := url.URL{Scheme: "redis", Host: hostPort, Path: "/" + p.Database}
+		if password != "" {
+			u.User = url.UserPassword(p.User, password)
+		}
+		return u.String()
+	default:
+		u := url.URL{Scheme: "postgres", Host: hostPort, Path: "/" + p.Database}
+		if password != "" {
+			u.User = url.UserPassword(p.User, password)
+		} else if p.User != "" {
+			u.User = url.User(p.User)
+		}
+		return u.String()
+	}
+}
+
+// ClientCommand returns the CLI client binary, its arguments and extra
+// environment variables (used to pass the password without exposing it in ps).
+func (p Profile) ClientCommand(password string) (string, []string, []string) {
+	port := strconv.Itoa(p.Port)
+	switch p.Driver {
+	case DriverMySQL:
+		args := []string{"-h", p.Host, "-P", port}
+		if p.User != "" {
+			args = append(args, "-u", p.User)
+		}
+		if p.Database != "" {
+			args = append(args, p.Database)
+		}
+		return "mysql", args, []string{"MYSQL_PWD=" + password}
+	case DriverRedis:
+		args := []string{"-h", p.Host, "-p", port}
+		if p.User != "" {
+			args = append(args, "--user", p.User)
+		}
+		if p.Database != "" {
+			args = append(args, "-n", p.Database)
+		}
+		return "redis-cli", args, []string{"REDISCLI_AUTH=" + password}
+	default:
+		args := []string{"-h", p.Host, "-p", port}
+		if p.User != "" {
+			args = append(args, "-U", p.User)
+		}
+		if p.Database != "" {
+			args = append(args, p.Database)
+		}
+		return "psql", args, []string{"PGPASSWORD=" + password}
+	}
+}
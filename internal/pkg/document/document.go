@@ -0,0 +1,367 @@
+package document
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"cli-aio/internal/ui"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a supported structured document format.
+type Format string
+
+const (
+	JSON Format = "json"
+	YAML Format = "yaml"
+)
+
+// ParseFormat converts a user-provided format name into a Format.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "json":
+		return JSON, nil
+	case "yaml", "yml":
+		return YAML, nil
+	default:
+		return "", fmt.Errorf("unsupported format: %s (supported: json, yaml)", name)
+	}
+}
+
+// Detect guesses the format of data by looking at its first non-space character.
+// Documents starting with '{' or '[' are treated as JSON, everything else as YAML.
+func Detect(data []byte) Format {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return JSON
+	}
+	return YAML
+}
+
+// Parse parses data into a yaml.Node tree and returns the detected format.
+// YAML is a superset of JSON, so both formats share the same node representation,
+// which keeps the original key order when converting between them.
+// A YAML stream of several documents (separated by ---) is returned as a
+// document node holding all of them, written as an array in JSON.
+func Parse(data []byte) (*yaml.Node, Format, error) {
+	f := Detect(data)
+	if f == JSON && !json.Valid(data) {
+		// Re-decode to get a descriptive error with the offending offset
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, f, fmt.Errorf("invalid json: %w", err)
+		}
+		return nil, f, fmt.Errorf("invalid json")
+	}
+
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, f, fmt.Errorf("invalid %s: %w", f, err)
+		}
+		// A trailing --- starts an empty document
+		if len(doc.Content) > 0 && !isEmpty(doc.Content[0]) {
+			docs = append(docs, doc.Content[0])
+		}
+	}
+	switch len(docs) {
+	case 0:
+		return nil, f, fmt.Errorf("empty document")
+	case 1:
+		return docs[0], f, nil
+	}
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: docs}, f, nil
+}
+
+// pathSegment matches one step of a query path: ".key", "[0]" or `["key"]`.
+var pathSegment = regexp.MustCompile(`^(?:\.([^.\[\]]+)|\[(\d+)\]|\["([^"]*)"\])`)
+
+// Query walks node following a simple path expression like ".spec.containers[0].image".
+// An empty path or "." returns the node itself.
+func Query(node *yaml.Node, path string) (*yaml.Node, error) {
+	path = strings.TrimSpace(path)
+	if path == "" || path == "." {
+		return node, nil
+	}
+	if !strings.HasPrefix(path, ".") && !strings.HasPrefix(path, "[") {
+		path = "." + path
+	}
+
+	current := node
+	walked := ""
+	for path != "" {
+		m := pathSegment.FindStringSubmatch(path)
+		if m == nil {
+			return nil, fmt.Errorf("invalid path near '%s'", path)
+		}
+		path = path[len(m[0]):]
+		walked += m[0]
+		current = resolveAlias(current)
+
+		if m[2] != "" {
+			if current.Kind != yaml.SequenceNode && current.Kind != yaml.DocumentNode {
+				return nil, fmt.Errorf("cannot index non-array at '%s'", walked)
+			}
+			idx, _ := strconv.Atoi(m[2])
+			if idx >= len(current.Content) {
+				return nil, fmt.Errorf("index out of range at '%s' (length %d)", walked, len(current.Content))
+			}
+			current = current.Content[idx]
+			continue
+		}
+
+		key := m[1]
+		if strings.HasPrefix(m[0], "[\"") {
+			key = m[3]
+		}
+		if current.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("cannot read key of non-object at '%s'", walked)
+		}
+		found := false
+		for i, content := 0, pairs(current); i+1 < len(content); i += 2 {
+			if content[i].Value == key {
+				current = content[i+1]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("key not found at '%s'", walked)
+		}
+	}
+	return resolveAlias(current), nil
+}
+
+// IsScalar reports whether node is a plain value rather than an object or array.
+func IsScalar(node *yaml.Node) bool {
+	return resolveAlias(node).Kind == yaml.ScalarNode
+}
+
+// ScalarString returns the raw (unquoted) value of a scalar node.
+func ScalarString(node *yaml.Node) string {
+	return resolveAlias(node).Value
+}
+
+// EncodeJSON renders node as indented JSON, optionally with ANSI colors.
+func EncodeJSON(node *yaml.Node, color bool) (string, error) {
+	var b strings.Builder
+	if err := writeJSON(&b, node, 0, color); err != nil {
+		return "", err
+	}
+	b.WriteString("\n")
+	return b.String(), nil
+}
+
+// EncodeYAML renders node as YAML with two-space indentation, optionally with ANSI colors.
+// The documents of a stream are separated by ---.
+func EncodeYAML(node *yaml.Node, color bool) (string, error) {
+	docs := []*yaml.Node{node}
+	if node.Kind == yaml.DocumentNode {
+		docs = node.Content
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, doc := range docs {
+		if err := enc.Encode(clearStyle(doc)); err != nil {
+			return "", fmt.Errorf("failed to encode yaml: %w", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode yaml: %w", err)
+	}
+	if !color {
+		return buf.String(), nil
+	}
+	return colorizeYAML(buf.String()), nil
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// pairs returns the keys and values of a mapping, alternating like its
+// Content, with the merge keys (<<) replaced by the pairs of the merged
+// mappings. Keys of the mapping win over merged ones, and earlier merged
+// mappings over later ones.
+func pairs(node *yaml.Node) []*yaml.Node {
+	own := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !isMerge(node.Content[i]) {
+			own[node.Content[i].Value] = true
+		}
+	}
+	var result []*yaml.Node
+	merged := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], resolveAlias(node.Content[i+1])
+		if !isMerge(key) {
+			result = append(result, key, node.Content[i+1])
+			continue
+		}
+		sources := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			sources = value.Content
+		}
+		for _, source := range sources {
+			if source = resolveAlias(source); source.Kind != yaml.MappingNode {
+				continue
+			}
+			content := pairs(source)
+			for j := 0; j+1 < len(content); j += 2 {
+				if name := content[j].Value; !own[name] && !merged[name] {
+					merged[name] = true
+					result = append(result, content[j], content[j+1])
+				}
+			}
+		}
+	}
+	return result
+}
+
+// isEmpty reports whether node is the null of an empty document, as opposed
+// to an explicit null or ~.
+func isEmpty(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" && node.Value == ""
+}
+
+func isMerge(key *yaml.Node) bool {
+	return key.Kind == yaml.ScalarNode && key.ShortTag() == "!!merge"
+}
+
+// clearStyle removes flow and quoting styles so documents (including converted
+// JSON) render as plain block YAML. Node tags still force quoting where needed.
+func clearStyle(node *yaml.Node) *yaml.Node {
+	node.Style = 0
+	if isMerge(node) {
+		// An explicit !!merge tag would be written out before the << key
+		node.Tag = ""
+	}
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+	return node
+}
+
+func writeJSON(b *strings.Builder, node *yaml.Node, depth int, color bool) error {
+	node = resolveAlias(node)
+	pad := strings.Repeat("  ", depth)
+	inner := strings.Repeat("  ", depth+1)
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		switch len(node.Content) {
+		case 0:
+			b.WriteString(paint(color, ui.Gray, "null"))
+			return nil
+		case 1:
+			return writeJSON(b, node.Content[0], depth, color)
+		}
+		// A stream of documents is an array
+		return writeJSON(b, &yaml.Node{Kind: yaml.SequenceNode, Content: node.Content}, depth, color)
+
+	case yaml.MappingNode:
+		content := pairs(node)
+		if len(content) == 0 {
+			b.WriteString("{}")
+			return nil
+		}
+		b.WriteString("{\n")
+		for i := 0; i+1 < len(content); i += 2 {
+			key, _ := json.Marshal(content[i].Value)
+			b.WriteString(inner)
+			b.WriteString(paint(color, ui.Blue, string(key)))
+			b.WriteString(": ")
+			if err := writeJSON(b, content[i+1], depth+1, color); err != nil {
+				return err
+			}
+			if i+2 < len(content) {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(pad + "}")
+		return nil
+
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			b.WriteString("[]")
+			return nil
+		}
+		b.WriteString("[\n")
+		for i, item := range node.Content {
+			b.WriteString(inner)
+			if err := writeJSON(b, item, depth+1, color); err != nil {
+				return err
+			}
+			if i+1 < len(node.Content) {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(pad + "]")
+		return nil
+
+	case yaml.ScalarNode:
+		var v interface{}
+		if err := node.Decode(&v); err != nil {
+			return fmt.Errorf("cannot decode value '%s': %w", node.Value, err)
+		}
+		out, err := json.Marshal(v)
+		if err != nil {
+			// Values like .inf or NaN have no JSON representation, keep them as strings
+			out, _ = json.Marshal(node.Value)
+		}
+		b.WriteString(paint(color, scalarColor(v), string(out)))
+		return nil
+	}
+	return fmt.Errorf("unsupported node kind: %d", node.Kind)
+}
+
+func scalarColor(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return ui.Gray
+	case bool:
+		return ui.Yellow
+	case string:
+		return ui.Green
+	default:
+		return ui.Cyan
+	}
+}
+
+// yamlKey matches the key part of a YAML mapping line, including list item prefixes.
+var yamlKey = regexp.MustCompile(`^(\s*(?:- )*)([^\s#'"][^:]*|'[^']*'|"[^"]*"):(\s|$)`)
+
+func colorizeYAML(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			lines[i] = ui.Gray + line + ui.Reset
+			continue
+		}
+		lines[i] = yamlKey.ReplaceAllString(line, "${1}"+ui.Blue+"${2}"+ui.Reset+":${3}")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func paint(enabled bool, color string, s string) string {
+	if !enabled {
+		return s
+	}
+	return color + s + ui.Reset
+}
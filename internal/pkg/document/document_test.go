@@ -0,0 +1,113 @@
+package document
+
+import (
+	"strings"
+	"testing"
+)
+
+const stream = `defaults: &defaults
+  image: nginx
+  port: 80
+debug: &debug
+  port: 8080
+  debug: true
+web:
+  <<: [*defaults, *debug]
+  port: 9000
+---
+kind: Service
+---
+`
+
+func TestEncodeJSONStream(t *testing.T) {
+	node, _, err := Parse([]byte(stream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := EncodeJSON(node, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[
+  {
+    "defaults": {
+      "image": "nginx",
+      "port": 80
+    },
+    "debug": {
+      "port": 8080,
+      "debug": true
+    },
+    "web": {
+      "image": "nginx",
+      "debug": true,
+      "port": 9000
+    }
+  },
+  {
+    "kind": "Service"
+  }
+]
+`
+	if out != want {
+		t.Errorf("EncodeJSON =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestEncodeYAMLStream(t *testing.T) {
+	node, _, err := Parse([]byte(stream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := EncodeYAML(node, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "\n  <<:\n") || !strings.Contains(out, "\n---\nkind: Service\n") {
+		t.Errorf("EncodeYAML should keep the merge key and the documents:\n%s", out)
+	}
+}
+
+func TestQueryStream(t *testing.T) {
+	node, _, err := Parse([]byte(stream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]string{
+		"[0].web.image": "nginx",
+		"[0].web.port":  "9000",
+		"[0].web.debug": "true",
+		"[1].kind":      "Service",
+	} {
+		got, err := Query(node, path)
+		if err != nil {
+			t.Errorf("Query(%q): %v", path, err)
+			continue
+		}
+		if ScalarString(got) != want {
+			t.Errorf("Query(%q) = %q, want %q", path, ScalarString(got), want)
+		}
+	}
+	if _, err := Query(node, "[2]"); err == nil {
+		t.Error("the empty document after the last --- should not be kept")
+	}
+}
+
+func TestParseSingleDocument(t *testing.T) {
+	for _, data := range []string{`{"<<": 1}`, "---\n~\n", "a: 1\n"} {
+		node, _, err := Parse([]byte(data))
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", data, err)
+		}
+		out, err := EncodeJSON(node, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasPrefix(out, "[") {
+			t.Errorf("Parse(%q) should return a single document, got %s", data, out)
+		}
+	}
+	if _, _, err := Parse([]byte("\n")); err == nil {
+		t.Error("Parse of nothing should fail")
+	}
+}
@@ -0,0 +1,73 @@
+// Package editor opens files in the user's editor.
+package editor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+)
+
+// fallbacks are tried in order when $VISUAL and $EDITOR are unset.
+var fallbacks = []string{"nvim", "vim", "nano", "vi", "notepad"}
+
+// Command returns the editor command from $VISUAL or $EDITOR (which may carry
+// arguments, e.g. "code --wait"), or the first installed fallback.
+func Command() ([]string, error) {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if value := strings.TrimSpace(os.Getenv(env)); value != "" {
+			args, err := shellquote.Split(value)
+			if err != nil || len(args) == 0 {
+				return nil, fmt.Errorf("invalid $%s: %s", env, value)
+			}
+			return args, nil
+		}
+	}
+	for _, candidate := range fallbacks {
+		if _, err := exec.LookPath(candidate); err == nil {
+			return []string{candidate}, nil
+		}
+	}
+	return nil, fmt.Errorf("no editor found; set the $EDITOR environment variable")
+}
+
+// Open edits path in the editor, at line when it is positive and the editor
+// is known to support it, and waits for the editor to exit.
+func Open(path string, line int) error {
+	command, err := Command()
+	if err != nil {
+		return err
+	}
+	args := append(command[1:], locationArgs(command[0], path, line)...)
+	run := exec.Command(command[0], args...)
+	run.Stdin = os.Stdin
+	run.Stdout = os.Stdout
+	run.Stderr = os.Stderr
+	if err := run.Run(); err != nil {
+		return fmt.Errorf("editor exited with error: %w", err)
+	}
+	return nil
+}
+
+// locationArgs returns the arguments opening path at line for the given editor.
+func locationArgs(editor string, path string, line int) []string {
+	if line <= 0 {
+		return []string{path}
+	}
+	n := strconv.Itoa(line)
+	switch strings.TrimSuffix(filepath.Base(editor), ".exe") {
+	case "vi", "vim", "nvim", "nano", "emacs", "emacsclient", "micro", "kak", "hx", "helix":
+		return []string{"+" + n, path}
+	case "code", "code-insiders", "codium", "cursor":
+		return []string{"--goto", path + ":" + n}
+	case "subl", "zed", "mate":
+		return []string{path + ":" + n}
+	case "idea", "goland", "pycharm", "webstorm":
+		return []string{"--line", n, path}
+	}
+	return []string{path}
+}
@@ -0,0 +1,148 @@
+// Package explain prints the raw git commands and API calls a command
+// performs, as the equivalent shell commands, so users can see what aio does
+// under the hood and replay a step by hand.
+package explain
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Env set to a non-empty value explains every command.
+const Env = "AIO_EXPLAIN"
+
+var (
+	mu      sync.Mutex
+	enabled *bool
+	// out is where the explanations go: stderr, so they never mix with the
+	// values printed on stdout for scripts.
+	out io.Writer = os.Stderr
+)
+
+// Set forces explaining on or off, overriding AIO_EXPLAIN.
+func Set(on bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = &on
+}
+
+// Enabled reports whether the calls of commands are printed.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if enabled != nil {
+		return *enabled
+	}
+	return os.Getenv(Env) != ""
+}
+
+// Command prints the command line of a process about to run, e.g.
+// "-> $ git rev-parse --show-toplevel". env is the environment of the
+// process, nil for the one of aio: only the variables it adds or changes
+// are printed, e.g. GIT_INDEX_FILE.
+func Command(env []string, args []string) {
+	if !Enabled() {
+		return
+	}
+	var line []string
+	if env != nil {
+		inherited := map[string]bool{}
+		for _, v := range os.Environ() {
+			inherited[v] = true
+		}
+		for _, v := range env {
+			if !inherited[v] {
+				line = append(line, v)
+			}
+		}
+	}
+	show(join(append(line, args...)))
+}
+
+// secretHeaders are the request headers whose values are hidden. The GitLab
+// token is shown as the variable holding it instead.
+var secretHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+}
+
+// Request prints req, about to be sent, as the equivalent curl command.
+// Credentials are never printed.
+func Request(req *http.Request) {
+	if !Enabled() {
+		return
+	}
+	line := "curl"
+	if req.Method != http.MethodGet {
+		line += " -X " + req.Method
+	}
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch {
+		case http.CanonicalHeaderKey(name) == "Private-Token":
+			// Let the shell expand the variable holding the token
+			line += ` -H "` + name + `: $GITLAB_PRIVATE_TOKEN"`
+		case secretHeaders[http.CanonicalHeaderKey(name)]:
+			line += " -H " + quote(name+": <redacted>")
+		default:
+			line += " -H " + quote(name+": "+req.Header.Get(name))
+		}
+	}
+	if body := requestBody(req); body != "" {
+		line += " -d " + quote(body)
+	}
+	show(line + " " + quote(req.URL.String()))
+}
+
+// requestBody returns a copy of the body of req, "" when it can't be read
+// without consuming it.
+func requestBody(req *http.Request) string {
+	if req.Body == nil || req.GetBody == nil {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	var b bytes.Buffer
+	if _, err := io.Copy(&b, io.LimitReader(body, 4096)); err != nil {
+		return ""
+	}
+	return b.String()
+}
+
+// show prints an explanation line.
+func show(line string) {
+	mu.Lock()
+	defer mu.Unlock()
+	fmt.Fprintf(out, "-> $ %s\n", line)
+}
+
+// join quotes args for a POSIX shell and joins them.
+func join(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// quote returns s as a single shell word: as is when it has no special
+// characters, in single quotes otherwise.
+func quote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_@%+=:,./-") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
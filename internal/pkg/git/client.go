@@ -0,0 +1,242 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+)
+
+// GitClient is what the commands working on the current repository (ckl,
+// rmerge, ztag and the git preconditions) need from git. NewClient returns
+// the go-git implementation, falling back to running git; ExecClient always
+// runs git.
+type GitClient interface {
+	// IsRepo reports whether the current directory is in a working tree.
+	IsRepo(ctx context.Context) (bool, error)
+	// TopLevel returns the root of the working tree.
+	TopLevel(ctx context.Context) (string, error)
+	// Head returns the branch HEAD points to, or where it is detached.
+	Head(ctx context.Context) (HeadInfo, error)
+	// HeadCommit returns the full SHA of HEAD.
+	HeadCommit(ctx context.Context) (string, error)
+	// Config returns a config value (e.g. user.name), "" when unset.
+	Config(ctx context.Context, key string) string
+	// ProjectID returns the path of origin, e.g. bank/operation/bank-config-fe-v2.
+	ProjectID(ctx context.Context) (string, error)
+	// IsClean reports whether there are no staged, unstaged or untracked changes.
+	IsClean(ctx context.Context) (bool, error)
+
+	// LocalBranches lists the local branches.
+	LocalBranches(ctx context.Context) ([]string, error)
+	// RemoteBranches lists the remote-tracking branches, without the remote.
+	RemoteBranches(ctx context.Context) ([]string, error)
+	// BranchExists reports whether branch exists locally or on origin.
+	BranchExists(ctx context.Context, branch string) (bool, error)
+	// RemoteBranchExists reports whether origin/<branch> is known locally.
+	RemoteBranchExists(ctx context.Context, branch string) bool
+	// IsValidBranchName reports whether git accepts name for a branch.
+	IsValidBranchName(ctx context.Context, name string) bool
+	// Checkout checks out branch.
+	Checkout(ctx context.Context, branch string) error
+	// CheckoutTracking creates branch tracking origin/<branch> and checks it out.
+	CheckoutTracking(ctx context.Context, branch string) error
+	// CreateBranch creates branch from base (HEAD when empty) and checks it out.
+	CreateBranch(ctx context.Context, branch string, base string) error
+	// RenameBranch renames a local branch.
+	RenameBranch(ctx context.Context, oldName string, newName string) error
+	// DeleteBranch deletes a local branch, unmerged ones too with force.
+	DeleteBranch(ctx context.Context, branch string, force bool) error
+	// DeleteRemoteBranch deletes branch on origin.
+	DeleteRemoteBranch(ctx context.Context, branch string) error
+	// FetchBranch fetches branch from origin.
+	FetchBranch(ctx context.Context, branch string) error
+	// Pull pulls the upstream of the current branch.
+	Pull(ctx context.Context) error
+	// PruneRemote removes (or with dryRun lists) the origin/* branches gone
+	// from origin, e.g. "origin/feature-x".
+	PruneRemote(ctx context.Context, dryRun bool) ([]string, error)
+	// GoneBranches lists the local branches whose upstream is gone.
+	GoneBranches(ctx context.Context) ([]string, error)
+
+	// IsAncestor reports whether ref is contained in of.
+	IsAncestor(ctx context.Context, ref string, of string) bool
+	// CommitsBetween lists the non-merge commits of to not in from, newest first.
+	CommitsBetween(ctx context.Context, from string, to string) ([]Commit, error)
+	// RecentCommits lists the latest limit commits of ref as "short-sha subject".
+	RecentCommits(ctx context.Context, ref string, limit int) ([]string, error)
+	// DiffStat lists the files merging to into from changes (from...to).
+	DiffStat(ctx context.Context, from string, to string) ([]FileStat, error)
+	// CheckMergeConflicts reports whether merging source into HEAD conflicts,
+	// leaving the repository as it was.
+	CheckMergeConflicts(ctx context.Context, source string) (bool, error)
+	// Merge merges source into the current branch.
+	Merge(ctx context.Context, source string, noFF bool) error
+	// MergeInProgress reports whether a merge was started but not concluded.
+	MergeInProgress(ctx context.Context) bool
+	// AbortMerge aborts the merge in progress.
+	AbortMerge(ctx context.Context) error
+	// Rebase rebases the current branch onto upstream, aborting a failed rebase.
+	Rebase(ctx context.Context, upstream string) error
+	// ResetHard resets the current branch, index and working tree to ref.
+	ResetHard(ctx context.Context, ref string) error
+
+	// LocalTags lists the local tags, newest first.
+	LocalTags(ctx context.Context) ([]TagInfo, error)
+	// RemoteTags lists the tags of origin, newest first, or the cached or
+	// local ones offline.
+	RemoteTags(ctx context.Context) ([]string, error)
+	// TagExists reports whether tag exists locally or on origin.
+	TagExists(ctx context.Context, tag string) (bool, error)
+	// CreateTag creates an annotated tag on HEAD.
+	CreateTag(ctx context.Context, tag string, message string) error
+	// PushTag pushes tag to origin.
+	PushTag(ctx context.Context, tag string) error
+	// FetchTags fetches the tags of origin.
+	FetchTags(ctx context.Context) error
+	// DeleteLocalTags deletes tags from the local repository.
+	DeleteLocalTags(ctx context.Context, tags []string) error
+	// DeleteRemoteTags deletes tags from origin in a single push.
+	DeleteRemoteTags(ctx context.Context, tags []string) error
+}
+
+// NewClient returns the client of the current repository: go-git for what it
+// does in process, running git for the rest when it is installed (see
+// GoGitClient). BackendEnv set to "exec" always runs git, and so do recorded
+// and replayed invocations, whose fixtures hold processes.
+func NewClient() GitClient {
+	if os.Getenv(BackendEnv) == "exec" || os.Getenv(RecordEnv) != "" || os.Getenv(ReplayEnv) != "" {
+		return ExecClient{}
+	}
+	if os.Getenv(BackendEnv) == "go-git" || !gitInstalled() {
+		return &GoGitClient{}
+	}
+	return &GoGitClient{Fallback: ExecClient{}}
+}
+
+// gitInstalled reports whether the git binary is on the PATH.
+func gitInstalled() bool {
+	_, err := exec.LookPath("git")
+	return err == nil
+}
+
+// ExecClient runs git for every operation, through the package functions.
+type ExecClient struct{}
+
+func (ExecClient) IsRepo(ctx context.Context) (bool, error)       { return CheckIfGitRepo(ctx) }
+func (ExecClient) TopLevel(ctx context.Context) (string, error)   { return GetTopLevel(ctx) }
+func (ExecClient) Head(ctx context.Context) (HeadInfo, error)     { return GetCurrentBranch(ctx) }
+func (ExecClient) HeadCommit(ctx context.Context) (string, error) { return GetHeadCommit(ctx) }
+func (ExecClient) Config(ctx context.Context, key string) string  { return GetConfig(ctx, key) }
+func (ExecClient) ProjectID(ctx context.Context) (string, error)  { return ExtractProjectID(ctx) }
+func (ExecClient) IsClean(ctx context.Context) (bool, error)      { return IsWorkingTreeClean(ctx) }
+
+func (ExecClient) LocalBranches(ctx context.Context) ([]string, error) {
+	return GetLocalBranches(ctx)
+}
+
+func (ExecClient) RemoteBranches(ctx context.Context) ([]string, error) {
+	return GetRemoteBranches(ctx)
+}
+
+func (ExecClient) BranchExists(ctx context.Context, branch string) (bool, error) {
+	return BranchExists(ctx, branch)
+}
+
+func (ExecClient) RemoteBranchExists(ctx context.Context, branch string) bool {
+	return RemoteBranchExists(ctx, branch)
+}
+
+func (ExecClient) IsValidBranchName(ctx context.Context, name string) bool {
+	return IsValidBranchName(ctx, name)
+}
+
+func (ExecClient) Checkout(ctx context.Context, branch string) error {
+	return CheckoutBranch(ctx, branch)
+}
+
+func (ExecClient) CheckoutTracking(ctx context.Context, branch string) error {
+	return CheckoutTrackingBranch(ctx, branch)
+}
+
+func (ExecClient) CreateBranch(ctx context.Context, branch string, base string) error {
+	return CreateBranch(ctx, branch, base)
+}
+
+func (ExecClient) RenameBranch(ctx context.Context, oldName string, newName string) error {
+	return RenameBranch(ctx, oldName, newName)
+}
+
+func (ExecClient) DeleteBranch(ctx context.Context, branch string, force bool) error {
+	return DeleteBranch(ctx, branch, force)
+}
+
+func (ExecClient) DeleteRemoteBranch(ctx context.Context, branch string) error {
+	return DeleteRemoteBranch(ctx, branch)
+}
+
+func (ExecClient) FetchBranch(ctx context.Context, branch string) error {
+	return FetchBranch(ctx, branch)
+}
+
+func (ExecClient) Pull(ctx context.Context) error { return PullBranch(ctx) }
+
+func (ExecClient) PruneRemote(ctx context.Context, dryRun bool) ([]string, error) {
+	return PruneRemote(ctx, dryRun)
+}
+
+func (ExecClient) GoneBranches(ctx context.Context) ([]string, error) { return GetGoneBranches(ctx) }
+
+func (ExecClient) IsAncestor(ctx context.Context, ref string, of string) bool {
+	return IsAncestor(ctx, ref, of)
+}
+
+func (ExecClient) CommitsBetween(ctx context.Context, from string, to string) ([]Commit, error) {
+	return CommitsBetween(ctx, from, to)
+}
+
+func (ExecClient) RecentCommits(ctx context.Context, ref string, limit int) ([]string, error) {
+	return RecentCommits(ctx, ref, limit)
+}
+
+func (ExecClient) DiffStat(ctx context.Context, from string, to string) ([]FileStat, error) {
+	return DiffStat(ctx, from, to)
+}
+
+func (ExecClient) CheckMergeConflicts(ctx context.Context, source string) (bool, error) {
+	return CheckMergeConflicts(ctx, source)
+}
+
+func (ExecClient) Merge(ctx context.Context, source string, noFF bool) error {
+	return MergeBranch(ctx, source, noFF)
+}
+
+func (ExecClient) MergeInProgress(ctx context.Context) bool { return MergeInProgress(ctx) }
+func (ExecClient) AbortMerge(ctx context.Context) error     { return AbortMerge(ctx) }
+
+func (ExecClient) Rebase(ctx context.Context, upstream string) error {
+	return RebaseBranch(ctx, upstream)
+}
+
+func (ExecClient) ResetHard(ctx context.Context, ref string) error { return ResetHard(ctx, ref) }
+
+func (ExecClient) LocalTags(ctx context.Context) ([]TagInfo, error) { return LocalTags(ctx) }
+func (ExecClient) RemoteTags(ctx context.Context) ([]string, error) { return ListRemoteTags(ctx) }
+
+func (ExecClient) TagExists(ctx context.Context, tag string) (bool, error) {
+	return TagExists(ctx, tag)
+}
+
+func (ExecClient) CreateTag(ctx context.Context, tag string, message string) error {
+	return CreateTag(ctx, tag, message)
+}
+
+func (ExecClient) PushTag(ctx context.Context, tag string) error { return PushTag(ctx, tag) }
+func (ExecClient) FetchTags(ctx context.Context) error           { return FetchTags(ctx) }
+
+func (ExecClient) DeleteLocalTags(ctx context.Context, tags []string) error {
+	return DeleteLocalTags(ctx, tags)
+}
+
+func (ExecClient) DeleteRemoteTags(ctx context.Context, tags []string) error {
+	return DeleteRemoteTags(ctx, tags)
+}
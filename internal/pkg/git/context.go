@@ -0,0 +1,18 @@
+package git
+
+import (
+	"context"
+)
+
+// command returns the process name args bound to ctx, run by the current
+// Runner: cancelling ctx interrupts it.
+func command(ctx context.Context, name string, args ...string) *Cmd {
+	return &Cmd{Name: name, Args: args, ctx: ctx}
+}
+
+// cleanup runs a git command that undoes a partial operation (merge --abort,
+// rebase --abort). It is not cancelled with ctx so it still runs after a
+// cancellation interrupted the operation.
+func cleanup(ctx context.Context, args ...string) error {
+	return command(context.WithoutCancel(ctx), "git", args...).Run()
+}
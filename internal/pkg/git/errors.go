@@ -0,0 +1,178 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"cli-aio/internal/pkg/offline"
+)
+
+// ErrorKind classifies why a git process failed, so callers can tell a
+// missing repository from a rejected push or an unreachable remote.
+type ErrorKind string
+
+// The kinds of GitError. ErrOther is anything not recognized.
+const (
+	ErrOther        ErrorKind = ""
+	ErrNotInstalled ErrorKind = "not-installed" // git is not on PATH
+	ErrNotARepo     ErrorKind = "not-a-repo"    // outside a working tree
+	ErrAuth         ErrorKind = "auth"          // credentials or SSH key refused
+	ErrNetwork      ErrorKind = "network"       // remote unreachable
+	ErrNotFound     ErrorKind = "not-found"     // unknown branch, tag, revision or remote ref
+	ErrConflict     ErrorKind = "conflict"      // merge, rebase or cherry-pick conflict
+	ErrLocalChanges ErrorKind = "local-changes" // uncommitted changes in the way
+	ErrExists       ErrorKind = "exists"        // the branch, tag or clone destination already exists
+	ErrRejected     ErrorKind = "rejected"      // push rejected by origin
+	ErrCancelled    ErrorKind = "cancelled"     // interrupted with Ctrl+C
+)
+
+// errorMessages are lowercase fragments of git and ssh errors for each kind,
+// in the order they are checked: "Permission denied (publickey)" comes with
+// "Could not read from remote repository", which is why auth goes first.
+// Fragments are whole git messages, not words a branch or file name could
+// contain. Network failures are recognized like offline mode does.
+var errorMessages = []struct {
+	kind      ErrorKind
+	fragments []string
+	// patterns match the messages with a name in the middle
+	patterns []*regexp.Regexp
+}{
+	{kind: ErrNotARepo, fragments: []string{"not a git repository"}},
+	// Hosts answer "Repository not found" for a private repository the
+	// credentials can't see, as much as for a missing one
+	{kind: ErrAuth, fragments: []string{"authentication failed", "permission denied (publickey", "could not read username",
+		"could not read password", "access denied", "http basic", "returned error: 401", "returned error: 403",
+		"host key verification failed", "invalid username or password", "repository not found"},
+		patterns: []*regexp.Regexp{regexp.MustCompile(`fatal: repository '[^']*' not found`)}},
+	{kind: ErrNetwork},
+	{kind: ErrConflict, fragments: []string{"conflict (", "automatic merge failed", "error: could not apply"}},
+	{kind: ErrLocalChanges, fragments: []string{"would be overwritten by", "please commit your changes or stash them",
+		"you have unstaged changes", "your index contains uncommitted changes"}},
+	{kind: ErrRejected, fragments: []string{"! [rejected]", "! [remote rejected]", "failed to push some refs",
+		"you are not allowed to push", "protected branch"}},
+	{kind: ErrExists, patterns: []*regexp.Regexp{regexp.MustCompile(`fatal: (a branch named|tag) '[^']*' already exists`),
+		regexp.MustCompile(`fatal: destination path '[^']*' already exists`)}},
+	{kind: ErrNotFound, fragments: []string{"unknown revision", "did not match any file(s) known to git",
+		"fatal: couldn't find remote ref", "not a valid object name", "fatal: invalid reference", "remote ref does not exist",
+		"no such ref", "fatal: bad revision", "is not a commit and a branch"}},
+}
+
+// GitError is the failure of a process started by the package: the command,
+// its exit code and what it printed on stderr. Its message is the one of the
+// underlying error (e.g. "exit status 128"), so wrapping it reads as before.
+type GitError struct {
+	Args []string
+	// Code is the exit code, -1 when the process didn't run or was killed.
+	Code   int
+	Stderr string
+	Err    error
+	kind   ErrorKind
+}
+
+// newGitError returns the failure err of c, with the stderr it printed.
+func newGitError(c *Cmd, err error, stderr string) *GitError {
+	e := &GitError{Args: append([]string{c.Name}, c.Args...), Code: -1, Stderr: stderr, Err: err}
+	if code, ok := exitCode(err); ok {
+		e.Code = code
+	}
+	switch {
+	case errors.Is(err, exec.ErrNotFound):
+		e.kind = ErrNotInstalled
+	case c.ctx != nil && c.ctx.Err() != nil:
+		e.kind = ErrCancelled
+	default:
+		e.kind = classify(stderr)
+	}
+	return e
+}
+
+// classify returns the kind of failure git reported in output.
+func classify(output string) ErrorKind {
+	lower := strings.ToLower(output)
+	for _, m := range errorMessages {
+		if m.kind == ErrNetwork && offline.NetworkMessage(output) != "" || containsAny(lower, m.fragments) {
+			return m.kind
+		}
+		for _, pattern := range m.patterns {
+			if pattern.MatchString(lower) {
+				return m.kind
+			}
+		}
+	}
+	return ErrOther
+}
+
+func containsAny(s string, fragments []string) bool {
+	for _, fragment := range fragments {
+		if strings.Contains(s, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *GitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *GitError) Unwrap() error {
+	return e.Err
+}
+
+// Kind returns why the process failed.
+func (e *GitError) Kind() ErrorKind {
+	return e.kind
+}
+
+// Message returns the last line git printed on stderr, usually the reason,
+// e.g. "fatal: couldn't find remote ref feature/x", "" when it printed none.
+func (e *GitError) Message() string {
+	lines := strings.Split(strings.TrimSpace(e.Stderr), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// ProcessStderr returns all git printed on stderr, where offline mode looks
+// for a network failure: a replayed failure has no *exec.ExitError holding it.
+func (e *GitError) ProcessStderr() string {
+	return e.Stderr
+}
+
+// Hint returns what the user can do about a failure of kind k, "" when
+// there is nothing general to say.
+func (k ErrorKind) Hint() string {
+	switch k {
+	case ErrNotInstalled:
+		return "install git or put it on PATH"
+	case ErrNotARepo:
+		return "run it inside a git repository, or pass -C <repo>"
+	case ErrAuth:
+		return "origin refused your credentials, check your SSH key or token (try: ssh -T git@<host>)"
+	case ErrNetwork:
+		return "origin is unreachable, check your VPN or network, or rerun with --offline"
+	case ErrLocalChanges:
+		return "uncommitted changes are in the way, commit or stash them first"
+	}
+	return ""
+}
+
+// WithHint returns err followed by the hint of its kind on a line of its own,
+// like git's "hint:" lines, or err itself when there is none.
+func WithHint(err error) error {
+	if hint := KindOf(err).Hint(); hint != "" {
+		return fmt.Errorf("%w\nhint: %s", err, hint)
+	}
+	return err
+}
+
+// KindOf returns the kind of the GitError in err's chain, ErrOther when there
+// is none.
+func KindOf(err error) ErrorKind {
+	var gitErr *GitError
+	if errors.As(err, &gitErr) {
+		return gitErr.Kind()
+	}
+	return ErrOther
+}
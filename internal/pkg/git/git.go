@@ -1,16 +1,20 @@
 package git
 
 import (
+	"cli-aio/internal/pkg/offline"
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
-	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // CheckIfGitRepo checks if the current directory is a git repository.
-func CheckIfGitRepo() (bool, error) {
-	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
+func CheckIfGitRepo(ctx context.Context) (bool, error) {
+	cmd := command(ctx, "git", "rev-parse", "--is-inside-work-tree")
 	output, err := cmd.Output()
 	if err != nil {
 		return false, fmt.Errorf("error running git command to check if git repository: %w", err)
@@ -18,51 +22,146 @@ func CheckIfGitRepo() (bool, error) {
 	return strings.TrimSpace(string(output)) == "true", nil
 }
 
-// GetCurrentBranch gets the current branch name using the git command.
-func GetCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+// GetTopLevel gets the absolute path of the repository's working tree root.
+func GetTopLevel(ctx context.Context) (string, error) {
+	cmd := command(ctx, "git", "rev-parse", "--show-toplevel")
 	output, err := cmd.Output()
 	if err != nil {
-		return "", fmt.Errorf("error running git command to get current branch: %w", err)
+		return "", fmt.Errorf("error running git command to get repository root: %w", err)
 	}
 	return strings.TrimSpace(string(output)), nil
 }
 
-// ExtractProjectFullName extracts the project full name from the remote origin URL
-// eg: https://gitlab.zalopay.vn/bank/operation/bank-config-fe-v2.git -> bank/operation/bank-config-fe-v2
-func ExtractProjectFullName() (string, error) {
-	url, err := GetRemoteOriginURL()
+// HeadInfo describes what HEAD points to: a branch, or a detached commit.
+type HeadInfo struct {
+	Branch   string // branch name, empty when detached
+	Detached bool
+	SHA      string // short SHA of HEAD, empty in a repository without commits
+	Describe string // nearest tag description (git describe --tags), empty when there are no tags
+}
+
+// String returns the branch name, or "detached at <describe or sha>".
+func (h HeadInfo) String() string {
+	if !h.Detached {
+		return h.Branch
+	}
+	if h.Describe != "" {
+		return fmt.Sprintf("detached at %s (%s)", h.Describe, h.SHA)
+	}
+	return fmt.Sprintf("detached at %s", h.SHA)
+}
+
+// GetCurrentBranch gets the current branch using the git command. When HEAD is
+// detached (e.g. a tag was checked out) Branch is empty and SHA/Describe tell where it is.
+func GetCurrentBranch(ctx context.Context) (HeadInfo, error) {
+	var head HeadInfo
+	if output, err := command(ctx, "git", "rev-parse", "--short", "HEAD").Output(); err == nil {
+		head.SHA = strings.TrimSpace(string(output))
+	}
+
+	cmd := command(ctx, "git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	output, err := cmd.Output()
+	if err == nil {
+		head.Branch = strings.TrimSpace(string(output))
+		return head, nil
+	}
+	// symbolic-ref exits with 1 (and no stderr with --quiet) only for a detached HEAD
+	if code, ok := exitCode(err); !ok || code != 1 || head.SHA == "" {
+		return head, fmt.Errorf("error running git command to get current branch: %w", err)
+	}
+
+	head.Detached = true
+	if output, err := command(ctx, "git", "describe", "--tags").Output(); err == nil {
+		head.Describe = strings.TrimSpace(string(output))
+	}
+	return head, nil
+}
+
+// GetHeadCommit gets the full SHA of the commit HEAD points to.
+func GetHeadCommit(ctx context.Context) (string, error) {
+	cmd := command(ctx, "git", "rev-parse", "HEAD")
+	output, err := cmd.Output()
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("error running git command to get HEAD commit: %w", err)
 	}
-	pattern := `(?:.*:?\/\/|.*@.*?[:/])(.*)\.git$`
-	re := regexp.MustCompile(pattern)
-	matches := re.FindStringSubmatch(url)
+	return strings.TrimSpace(string(output)), nil
+}
 
-	if len(matches) > 1 {
-		projectFullName := matches[1]
-		return projectFullName, nil
+// GetConfig reads a git config value (e.g. user.name). Returns an empty string when unset.
+func GetConfig(ctx context.Context, key string) string {
+	cmd := command(ctx, "git", "config", "--get", key)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
 	}
+	return strings.TrimSpace(string(output))
+}
 
-	return "", fmt.Errorf("could not extract project full name from URL: %s", url)
+// GetLocalConfig reads a value set in the repository's own config (.git/config),
+// ignoring the global one. Returns an empty string when unset.
+func GetLocalConfig(ctx context.Context, key string) string {
+	cmd := command(ctx, "git", "config", "--local", "--get", key)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
 }
 
-// ExtractProjectID extracts the project ID from the remote origin URL.
-// eg: https://gitlab.zalopay.vn/bank/operation/bank-config-fe-v2.git -> bank/operation/bank-config-fe-v2.git
-func ExtractProjectID() (string, error) {
-	fullName, err := ExtractProjectFullName()
+// SetLocalConfigIn sets a value in the config of the repository at dir.
+func SetLocalConfigIn(ctx context.Context, dir string, key string, value string) error {
+	cmd := command(ctx, "git", "-C", dir, "config", "--local", key, value)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error setting %s: %w\n%s", key, err, string(output))
+	}
+	return nil
+}
+
+// UnsetLocalConfigIn removes a value from the config of the repository at dir.
+// A value that is not set is not an error.
+func UnsetLocalConfigIn(ctx context.Context, dir string, key string) error {
+	cmd := command(ctx, "git", "-C", dir, "config", "--local", "--unset", key)
+	output, err := cmd.CombinedOutput()
+	// Exit code 5 means the key was not set
+	if code, ok := exitCode(err); err != nil && !(ok && code == 5) {
+		return fmt.Errorf("error unsetting %s: %w\n%s", key, err, string(output))
+	}
+	return nil
+}
+
+// ExtractProjectFullName extracts the project full name (host and path) from the remote origin URL,
+// the same for every form of the URL (ssh remotes used to give the path alone)
+// eg: git@gitlab.zalopay.vn:bank/operation/bank-config-fe-v2.git -> gitlab.zalopay.vn/bank/operation/bank-config-fe-v2
+func ExtractProjectFullName(ctx context.Context) (string, error) {
+	url, err := GetRemoteOriginURL(ctx)
 	if err != nil {
 		return "", err
 	}
-	parts := strings.Split(fullName, "/")
-	projectID := strings.Join(parts[1:], "/")
-	return projectID, nil
+	remote, err := ParseRemoteURL(url)
+	if err != nil {
+		return "", err
+	}
+	return remote.FullName(), nil
+}
 
+// ExtractProjectID extracts the project ID (path with namespace) from the remote origin URL.
+// eg: git@gitlab.zalopay.vn:bank/operation/bank-config-fe-v2.git -> bank/operation/bank-config-fe-v2
+func ExtractProjectID(ctx context.Context) (string, error) {
+	url, err := GetRemoteOriginURL(ctx)
+	if err != nil {
+		return "", err
+	}
+	remote, err := ParseRemoteURL(url)
+	if err != nil {
+		return "", err
+	}
+	return remote.Path, nil
 }
 
 // GetRemoteOriginURL gets the remote origin URL using the git command.
-func GetRemoteOriginURL() (string, error) {
-	cmd := exec.Command("git", "config", "--get", "remote.origin.url")
+func GetRemoteOriginURL(ctx context.Context) (string, error) {
+	cmd := command(ctx, "git", "config", "--get", "remote.origin.url")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("error running git command to get remote origin URL: %w", err)
@@ -76,17 +175,58 @@ func GetRemoteOriginURL() (string, error) {
 }
 
 // GetLatestTags gets the latest tags from the remote git repository using creatordate order.
-func GetLatestTags(limit int) ([]string, error) {
-	// git ls-remote --tags --refs --sort=-creatordate | head -n {limit}
-	cmd := exec.Command("git", "ls-remote", "--tags", "--refs", "--sort=-creatordate")
+func GetLatestTags(ctx context.Context, limit int) ([]string, error) {
+	tags, err := ListRemoteTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tags) == 0 {
+		return []string{"v0.0.0"}, nil
+	}
+
+	if len(tags) > limit {
+		return tags[:limit], nil
+	}
+	return tags, nil
+}
+
+// ListRemoteTags lists all tags of the remote repository, newest (creatordate) first.
+// Offline, or when the remote is unreachable, the tags cached by the last
+// successful listing (or else the local tags) are returned instead.
+func ListRemoteTags(ctx context.Context) ([]string, error) {
+	if offline.Enabled() {
+		return offlineTags(ctx)
+	}
+	// git ls-remote --tags --refs --sort=-creatordate
+	cmd := command(ctx, "git", "ls-remote", "--tags", "--refs", "--sort=-creatordate")
 	output, err := cmd.Output()
+	var gitErr *GitError
+	if errors.As(err, &gitErr) && strings.Contains(gitErr.Stderr, "fatal: missing object") {
+		// Sorting reads the tag objects, missing for the tags not fetched yet
+		output, err = command(ctx, "git", "ls-remote", "--tags", "--refs").Output()
+		if err == nil {
+			output, err = sortByLocalDate(ctx, output)
+		}
+	}
 	if err != nil {
+		if offline.Check(err) {
+			return offlineTags(ctx)
+		}
 		return nil, fmt.Errorf("error running git command to get latest tags: %w", err)
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	tags := parseRemoteTags(output)
+	if remote, err := GetRemoteOriginURL(ctx); err == nil {
+		offline.SaveTags(remote, tags)
+	}
+	return tags, nil
+}
+
+// parseRemoteTags returns the tag names of a git ls-remote listing.
+func parseRemoteTags(output []byte) []string {
 	var tags []string
-	for _, line := range lines {
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
 		parts := strings.Split(line, "\t")
 		if len(parts) == 2 {
 			ref := parts[1]
@@ -97,33 +237,145 @@ func GetLatestTags(limit int) ([]string, error) {
 			}
 		}
 	}
+	return tags
+}
 
-	if len(tags) == 0 {
-		return []string{"v0.0.0"}, nil
+// sortByLocalDate orders a git ls-remote listing of tags newest first by the
+// dates of the local tags, the ones not fetched last, by name on ties.
+func sortByLocalDate(ctx context.Context, output []byte) ([]byte, error) {
+	local, err := LocalTags(ctx)
+	if err != nil {
+		return nil, err
 	}
+	dates := make(map[string]time.Time, len(local))
+	for _, tag := range local {
+		dates["refs/tags/"+tag.Name] = tag.Date
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	ref := func(line string) string {
+		_, name, _ := strings.Cut(line, "\t")
+		return name
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		a, b := ref(lines[i]), ref(lines[j])
+		if !dates[a].Equal(dates[b]) {
+			return dates[a].After(dates[b])
+		}
+		return a < b
+	})
+	return []byte(strings.Join(lines, "\n")), nil
+}
 
-	if len(tags) > limit {
-		return tags[:limit], nil
+// offlineTags returns the cached remote tags, or the local tags when the
+// remote was never listed. Which one is said on stderr, out of the listings.
+func offlineTags(ctx context.Context) ([]string, error) {
+	if remote, err := GetRemoteOriginURL(ctx); err == nil {
+		if cached, ok := offline.Tags(remote); ok {
+			fmt.Fprintf(os.Stderr, "[!] Offline: using remote tags cached %s ago\n", time.Since(cached.UpdatedAt).Round(time.Minute))
+			return cached.Tags, nil
+		}
 	}
-	return tags, nil
+	cmd := command(ctx, "git", "tag", "--sort=-creatordate")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing local tags: %w", err)
+	}
+	fmt.Fprintln(os.Stderr, "[!] Offline: using local tags, they may miss tags pushed by others")
+	return strings.Fields(string(output)), nil
 }
 
-func CreateAndPushTag(tag string, message string) error {
-	if err := exec.Command("git", "tag", tag, "-m", message).Run(); err != nil {
+// CreateTag creates an annotated tag on HEAD.
+func CreateTag(ctx context.Context, tag string, message string) error {
+	if err := command(ctx, "git", "tag", tag, "-m", message).Run(); err != nil {
 		return fmt.Errorf("error running git command to create tag: %w", err)
 	}
-	if err := exec.Command("git", "push", "origin", tag).Run(); err != nil {
-		return fmt.Errorf("error running git command to push tag: %w", err)
+	return nil
+}
+
+// PushTag pushes a local tag to origin.
+func PushTag(ctx context.Context, tag string) error {
+	cmd := command(ctx, "git", "push", "origin", tag)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error running git command to push tag: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// TagInfo is a local tag with the commit it points to.
+type TagInfo struct {
+	Name   string
+	Commit string
+	// Date is when the tag was created, or the commit date of a lightweight tag.
+	Date time.Time
+}
+
+// LocalTags lists the local tags, newest (creatordate) first.
+func LocalTags(ctx context.Context) ([]TagInfo, error) {
+	cmd := command(ctx, "git", "for-each-ref", "--sort=-creatordate",
+		"--format=%(refname:short)%09%(creatordate:unix)%09%(objectname)%09%(objecttype)%09%(*objectname)%09%(*objecttype)", "refs/tags")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing local tags: %w", err)
+	}
+	var tags []TagInfo
+	// Lightweight tags end with empty fields, so only the newline is trimmed
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) != 6 {
+			continue
+		}
+		seconds, _ := strconv.ParseInt(parts[1], 10, 64)
+		tag := TagInfo{Name: parts[0], Commit: parts[2], Date: time.Unix(seconds, 0)}
+		kind := parts[3]
+		// An annotated tag points to the tag object, the peeled one to the commit
+		if parts[4] != "" {
+			tag.Commit, kind = parts[4], parts[5]
+		}
+		if kind == "tag" {
+			// A tag of a tag, peeled only once by older versions of git
+			peeled, err := command(ctx, "git", "rev-parse", "--verify", "--quiet", tag.Commit+"^{commit}").Output()
+			if err != nil {
+				continue
+			}
+			tag.Commit, kind = strings.TrimSpace(string(peeled)), "commit"
+		}
+		// Tags of trees or blobs have no commit to release
+		if kind != "commit" {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// DeleteLocalTags deletes tags from the local repository.
+func DeleteLocalTags(ctx context.Context, tags []string) error {
+	cmd := command(ctx, "git", append([]string{"tag", "-d"}, tags...)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error deleting local tags: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// DeleteRemoteTags deletes tags from origin in a single push.
+func DeleteRemoteTags(ctx context.Context, tags []string) error {
+	args := []string{"push", "origin", "--delete"}
+	for _, tag := range tags {
+		args = append(args, "refs/tags/"+tag)
+	}
+	cmd := command(ctx, "git", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error deleting tags on origin: %w\n%s", err, strings.TrimSpace(string(output)))
 	}
 	return nil
 }
 
-func CreateZalopayRelease(projectID string, tag string, message string) error {
+func CreateZalopayRelease(ctx context.Context, projectID string, tag string, message string) error {
 	gitlabToken := os.Getenv("GITLAB_PRIVATE_TOKEN")
 	if gitlabToken == "" {
 		return fmt.Errorf("GITLAB_PRIVATE_TOKEN is not set")
 	}
-	_, err := exec.Command("curl", "--header", "Content-Type: application/json", "--header",
+	_, err := command(ctx, "curl", "--header", "Content-Type: application/json", "--header",
 		fmt.Sprintf("PRIVATE-TOKEN: %s", gitlabToken),
 		"--data", fmt.Sprintf("{ \"name\": \"%s\", \"tag_name\": \"%s\", \"description\": \"%s\" }", tag, tag, message),
 		"--request", "POST", fmt.Sprintf("https://gitlab.zalopay.vn/api/v4/projects/%s/releases", projectID)).Output()
@@ -134,8 +386,8 @@ func CreateZalopayRelease(projectID string, tag string, message string) error {
 }
 
 // CheckoutBranch checks out to the specified branch.
-func CheckoutBranch(branch string) error {
-	cmd := exec.Command("git", "checkout", branch)
+func CheckoutBranch(ctx context.Context, branch string) error {
+	cmd := command(ctx, "git", "checkout", branch)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("error checking out branch %s: %w\n%s", branch, err, string(output))
@@ -143,9 +395,39 @@ func CheckoutBranch(branch string) error {
 	return nil
 }
 
+// CreateBranch creates branch from base (or HEAD when base is empty) and checks it out.
+// The new branch does not track base, so it can be pushed under its own name.
+func CreateBranch(ctx context.Context, branch string, base string) error {
+	args := []string{"checkout", "-b", branch}
+	if base != "" {
+		args = append(args, "--no-track", base)
+	}
+	cmd := command(ctx, "git", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error creating branch %s: %w\n%s", branch, err, string(output))
+	}
+	return nil
+}
+
+// IsValidBranchName checks whether name is a valid branch name according to git.
+func IsValidBranchName(ctx context.Context, name string) bool {
+	return command(ctx, "git", "check-ref-format", "--branch", name).Run() == nil
+}
+
+// CheckoutTrackingBranch creates a local branch tracking origin/<branch> and checks it out.
+func CheckoutTrackingBranch(ctx context.Context, branch string) error {
+	cmd := command(ctx, "git", "checkout", "-b", branch, "origin/"+branch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error checking out remote branch %s: %w\n%s", branch, err, string(output))
+	}
+	return nil
+}
+
 // PullBranch pulls the latest changes from remote for the current branch.
-func PullBranch() error {
-	cmd := exec.Command("git", "pull")
+func PullBranch(ctx context.Context) error {
+	cmd := command(ctx, "git", "pull")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("error pulling branch: %w\n%s", err, string(output))
@@ -156,16 +438,15 @@ func PullBranch() error {
 // CheckMergeConflicts checks if merging sourceBranch into current branch would cause conflicts.
 // Returns true if there would be conflicts, false otherwise.
 // Uses a test merge approach: attempts merge with --no-commit and --no-ff, then aborts.
-func CheckMergeConflicts(sourceBranch string) (bool, error) {
+func CheckMergeConflicts(ctx context.Context, sourceBranch string) (bool, error) {
 	// Ensure we clean up any merge state on exit
 	defer func() {
 		// Try to abort any ongoing merge
-		abortCmd := exec.Command("git", "merge", "--abort")
-		_ = abortCmd.Run() // Ignore errors, just try to clean up
+		_ = cleanup(ctx, "merge", "--abort") // Ignore errors, just try to clean up
 	}()
 
 	// First, check if branches are already merged
-	cmd := exec.Command("git", "merge-base", "--is-ancestor", sourceBranch, "HEAD")
+	cmd := command(ctx, "git", "merge-base", "--is-ancestor", sourceBranch, "HEAD")
 	err := cmd.Run()
 	if err == nil {
 		// sourceBranch is already an ancestor of HEAD, so it's already merged
@@ -174,43 +455,36 @@ func CheckMergeConflicts(sourceBranch string) (bool, error) {
 
 	// Try to do a test merge with --no-commit to check for conflicts
 	// This will not actually commit the merge, allowing us to check for conflicts
-	cmd = exec.Command("git", "merge", "--no-commit", "--no-ff", sourceBranch)
+	cmd = command(ctx, "git", "merge", "--no-commit", "--no-ff", sourceBranch)
 	output, err := cmd.CombinedOutput()
 
 	// Check if merge was successful (no conflicts)
 	if err == nil {
 		// Merge succeeded, abort it since we're just testing
-		abortCmd := exec.Command("git", "merge", "--abort")
-		_ = abortCmd.Run() // Ignore abort errors
+		_ = cleanup(ctx, "merge", "--abort") // Ignore abort errors
 		return false, nil
 	}
 
-	// Merge failed, check if it's due to conflicts
+	// Merge failed, check if it's due to conflicts: git reports them on stdout
 	outputStr := string(output)
-	hasConflicts := strings.Contains(outputStr, "CONFLICT") ||
-		strings.Contains(outputStr, "conflict") ||
-		strings.Contains(outputStr, "Automatic merge failed")
-
-	if hasConflicts {
+	if strings.Contains(outputStr, "CONFLICT (") || strings.Contains(outputStr, "Automatic merge failed") {
 		// Abort the merge attempt
-		abortCmd := exec.Command("git", "merge", "--abort")
-		_ = abortCmd.Run() // Ignore abort errors
+		_ = cleanup(ctx, "merge", "--abort") // Ignore abort errors
 		return true, nil
 	}
 
 	// Some other error occurred - abort and return error
-	abortCmd := exec.Command("git", "merge", "--abort")
-	_ = abortCmd.Run() // Try to clean up anyway
+	_ = cleanup(ctx, "merge", "--abort") // Try to clean up anyway
 	return false, fmt.Errorf("error checking merge conflicts: %w\n%s", err, outputStr)
 }
 
 // MergeBranch merges sourceBranch into the current branch.
-func MergeBranch(sourceBranch string, noFF bool) error {
+func MergeBranch(ctx context.Context, sourceBranch string, noFF bool) error {
 	args := []string{"merge", sourceBranch}
 	if noFF {
 		args = append(args, "--no-ff")
 	}
-	cmd := exec.Command("git", args...)
+	cmd := command(ctx, "git", args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("error merging branch %s: %w\n%s", sourceBranch, err, string(output))
@@ -218,9 +492,24 @@ func MergeBranch(sourceBranch string, noFF bool) error {
 	return nil
 }
 
+// MergeInProgress reports whether a merge was started but not concluded.
+func MergeInProgress(ctx context.Context) bool {
+	return command(ctx, "git", "rev-parse", "-q", "--verify", "MERGE_HEAD").Run() == nil
+}
+
+// AbortMerge aborts the merge in progress, restoring the pre-merge state.
+func AbortMerge(ctx context.Context) error {
+	cmd := command(ctx, "git", "merge", "--abort")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error aborting merge: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
 // FetchBranch fetches the specified branch from remote.
-func FetchBranch(branch string) error {
-	cmd := exec.Command("git", "fetch", "origin", branch)
+func FetchBranch(ctx context.Context, branch string) error {
+	cmd := command(ctx, "git", "fetch", "origin", branch)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("error fetching branch %s: %w\n%s", branch, err, string(output))
@@ -229,16 +518,16 @@ func FetchBranch(branch string) error {
 }
 
 // BranchExists checks if a branch exists (local or remote).
-func BranchExists(branch string) (bool, error) {
+func BranchExists(ctx context.Context, branch string) (bool, error) {
 	// Check local branches
-	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
+	cmd := command(ctx, "git", "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
 	err := cmd.Run()
 	if err == nil {
 		return true, nil
 	}
 
 	// Check remote branches
-	cmd = exec.Command("git", "show-ref", "--verify", "--quiet", "refs/remotes/origin/"+branch)
+	cmd = command(ctx, "git", "show-ref", "--verify", "--quiet", "refs/remotes/origin/"+branch)
 	err = cmd.Run()
 	if err == nil {
 		return true, nil
@@ -248,8 +537,8 @@ func BranchExists(branch string) (bool, error) {
 }
 
 // GetLocalBranches gets a list of all local branch names.
-func GetLocalBranches() ([]string, error) {
-	cmd := exec.Command("git", "branch", "--format", "%(refname:short)")
+func GetLocalBranches(ctx context.Context) ([]string, error) {
+	cmd := command(ctx, "git", "branch", "--format", "%(refname:short)")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("error getting local branches: %w", err)
@@ -268,13 +557,53 @@ func GetLocalBranches() ([]string, error) {
 }
 
 // GetRemoteBranches gets a list of all remote branch names (without remote prefix).
-func GetRemoteBranches() ([]string, error) {
-	cmd := exec.Command("git", "branch", "-r", "--format", "%(refname:short)")
+func GetRemoteBranches(ctx context.Context) ([]string, error) {
+	cmd := command(ctx, "git", "branch", "-r", "--format", "%(refname:short)")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("error getting remote branches: %w", err)
 	}
+	return parseRemoteBranches(output), nil
+}
+
+// RemoteBranchesIn is GetRemoteBranches for the repository at dir.
+func RemoteBranchesIn(ctx context.Context, dir string) ([]string, error) {
+	cmd := command(ctx, "git", "-C", dir, "branch", "-r", "--format", "%(refname:short)")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error getting remote branches in %s: %w", dir, err)
+	}
+	return parseRemoteBranches(output), nil
+}
 
+// LsRemoteBranchesIn lists the branches of origin of the repository at dir
+// with git ls-remote: unlike a fetch, it leaves the repository untouched.
+func LsRemoteBranchesIn(ctx context.Context, dir string) ([]string, error) {
+	cmd := command(ctx, "git", "-C", dir, "ls-remote", "--heads", "origin")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing the branches of origin in %s: %w", dir, err)
+	}
+	var branches []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		_, ref, _ := strings.Cut(line, "\t")
+		if branch, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			branches = append(branches, branch)
+		}
+	}
+	return branches, nil
+}
+
+// FetchIn fetches and prunes all remotes of the repository at dir.
+func FetchIn(ctx context.Context, dir string) error {
+	cmd := command(ctx, "git", "-C", dir, "fetch", "--all", "--prune", "--quiet")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error fetching in %s: %w (%s)", dir, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func parseRemoteBranches(output []byte) []string {
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 	var branches []string
 	seen := make(map[string]bool)
@@ -297,23 +626,27 @@ func GetRemoteBranches() ([]string, error) {
 		}
 	}
 
-	return branches, nil
+	return branches
 }
 
 // GetAllAvailableBranches gets a combined list of local and remote branches.
 // Remote branches are only included if they don't exist locally.
-func GetAllAvailableBranches() ([]string, error) {
-	localBranches, err := GetLocalBranches()
+func GetAllAvailableBranches(ctx context.Context) ([]string, error) {
+	localBranches, err := GetLocalBranches(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	remoteBranches, err := GetRemoteBranches()
+	remoteBranches, err := GetRemoteBranches(ctx)
 	if err != nil {
 		// If we can't get remote branches, just return local ones
 		return localBranches, nil
 	}
+	return CombineBranches(localBranches, remoteBranches), nil
+}
 
+// CombineBranches appends the remote branches that don't exist locally to the local ones.
+func CombineBranches(localBranches []string, remoteBranches []string) []string {
 	// Create a map of local branches for quick lookup
 	localMap := make(map[string]bool)
 	for _, branch := range localBranches {
@@ -330,5 +663,749 @@ func GetAllAvailableBranches() ([]string, error) {
 		}
 	}
 
-	return allBranches, nil
+	return allBranches
+}
+
+// IsWorkingTreeClean reports whether there are no staged, unstaged or untracked changes.
+func IsWorkingTreeClean(ctx context.Context) (bool, error) {
+	cmd := command(ctx, "git", "status", "--porcelain")
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("error running git command to get status: %w", err)
+	}
+	return strings.TrimSpace(string(output)) == "", nil
+}
+
+// FetchTags fetches all tags from origin.
+func FetchTags(ctx context.Context) error {
+	if offline.Enabled() {
+		return fmt.Errorf("skipped fetching tags: %w", offline.ErrOffline)
+	}
+	cmd := command(ctx, "git", "fetch", "--tags", "origin")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error fetching tags: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// CommitSubjects lists "subject (short sha)" of the commits reachable from HEAD
+// but not from since. When since is empty, the latest limit commits are returned.
+func CommitSubjects(ctx context.Context, since string, limit int) ([]string, error) {
+	args := []string{"log", "--no-merges", "--pretty=format:%s (%h)"}
+	if since != "" {
+		args = append(args, since+"..HEAD")
+	} else {
+		args = append(args, fmt.Sprintf("-%d", limit))
+	}
+	cmd := command(ctx, "git", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running git command to list commits: %w", err)
+	}
+	var subjects []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			subjects = append(subjects, line)
+		}
+	}
+	return subjects, nil
+}
+
+// IsAncestor reports whether ref is already contained in (merged into) of.
+func IsAncestor(ctx context.Context, ref string, of string) bool {
+	return command(ctx, "git", "merge-base", "--is-ancestor", ref, of).Run() == nil
+}
+
+// DeleteBranch deletes a local branch. With force, unmerged branches are deleted too.
+func DeleteBranch(ctx context.Context, branch string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	cmd := command(ctx, "git", "branch", flag, branch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error deleting branch %s: %w\n%s", branch, err, string(output))
+	}
+	return nil
+}
+
+// DeleteRemoteBranch deletes branch on origin.
+func DeleteRemoteBranch(ctx context.Context, branch string) error {
+	cmd := command(ctx, "git", "push", "origin", "--delete", branch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error deleting remote branch %s: %w\n%s", branch, err, string(output))
+	}
+	return nil
+}
+
+// RenameBranch renames a local branch.
+func RenameBranch(ctx context.Context, oldName string, newName string) error {
+	cmd := command(ctx, "git", "branch", "-m", oldName, newName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error renaming branch %s: %w\n%s", oldName, err, string(output))
+	}
+	return nil
+}
+
+// RemoteBranchExists checks if origin/<branch> is known locally.
+func RemoteBranchExists(ctx context.Context, branch string) bool {
+	return command(ctx, "git", "show-ref", "--verify", "--quiet", "refs/remotes/origin/"+branch).Run() == nil
+}
+
+// PruneRemote runs 'git remote prune origin' (or a dry run) and returns the
+// remote-tracking branches that were (or would be) pruned, e.g. "origin/feature-x".
+func PruneRemote(ctx context.Context, dryRun bool) ([]string, error) {
+	args := []string{"remote", "prune", "origin"}
+	if dryRun {
+		args = append(args, "--dry-run")
+	}
+	cmd := command(ctx, "git", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error pruning remote branches: %w\n%s", err, string(output))
+	}
+	var pruned []string
+	for _, line := range strings.Split(string(output), "\n") {
+		// " * [pruned] origin/feature-x" or " * [would prune] origin/feature-x"
+		line = strings.TrimSpace(line)
+		if i := strings.Index(line, "] "); i >= 0 && strings.HasPrefix(line, "* [") {
+			pruned = append(pruned, strings.TrimSpace(line[i+2:]))
+		}
+	}
+	return pruned, nil
+}
+
+// GetGoneBranches returns the local branches whose upstream branch no longer exists on the remote.
+func GetGoneBranches(ctx context.Context) ([]string, error) {
+	cmd := command(ctx, "git", "for-each-ref", "--format", "%(refname:short)\t%(upstream:track)", "refs/heads")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error getting branch upstream status: %w", err)
+	}
+	var gone []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) == 2 && parts[1] == "[gone]" {
+			gone = append(gone, parts[0])
+		}
+	}
+	return gone, nil
+}
+
+// RebaseBranch rebases the current branch onto upstream. A failed rebase is aborted
+// so the repository is never left mid-rebase.
+func RebaseBranch(ctx context.Context, upstream string) error {
+	cmd := command(ctx, "git", "rebase", upstream)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		_ = cleanup(ctx, "rebase", "--abort")
+		return fmt.Errorf("error rebasing onto %s: %w\n%s", upstream, err, string(output))
+	}
+	return nil
+}
+
+// ResetHard resets the current branch, index and working tree to ref.
+func ResetHard(ctx context.Context, ref string) error {
+	cmd := command(ctx, "git", "reset", "--hard", ref)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error resetting to %s: %w\n%s", ref, err, string(output))
+	}
+	return nil
+}
+
+// CreateBranchAt creates branch pointing at commit without checking it out.
+func CreateBranchAt(ctx context.Context, branch string, commit string) error {
+	cmd := command(ctx, "git", "branch", "--no-track", branch, commit)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error creating branch %s: %w\n%s", branch, err, string(output))
+	}
+	return nil
+}
+
+// PushBranch pushes branch to origin and sets it as upstream.
+func PushBranch(ctx context.Context, branch string) error {
+	cmd := command(ctx, "git", "push", "-u", "origin", branch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error pushing branch %s: %w\n%s", branch, err, string(output))
+	}
+	return nil
+}
+
+// RecentCommits lists the latest limit commits of ref as "short-sha subject".
+func RecentCommits(ctx context.Context, ref string, limit int) ([]string, error) {
+	cmd := command(ctx, "git", "log", "--pretty=format:%h %s", fmt.Sprintf("-%d", limit), ref, "--")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running git command to list commits of %s: %w", ref, err)
+	}
+	var commits []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			commits = append(commits, line)
+		}
+	}
+	return commits, nil
+}
+
+// ResolveCommit returns the full SHA of ref.
+func ResolveCommit(ctx context.Context, ref string) (string, error) {
+	cmd := command(ctx, "git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("unknown commit: %s", ref)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Clone clones repoURL into dir.
+func Clone(ctx context.Context, repoURL string, dir string) error {
+	cmd := command(ctx, "git", "clone", repoURL, dir)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error cloning %s: %w\n%s", repoURL, err, string(output))
+	}
+	return nil
+}
+
+// ShallowClone clones the latest commit of repoURL into dir, e.g. a template
+// whose history doesn't matter.
+func ShallowClone(ctx context.Context, repoURL string, dir string) error {
+	cmd := command(ctx, "git", "clone", "--depth", "1", repoURL, dir)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error cloning %s: %w\n%s", repoURL, err, string(output))
+	}
+	return nil
+}
+
+// InitIn initializes a repository in dir and commits everything in it with message.
+func InitIn(ctx context.Context, dir string, message string) error {
+	steps := [][]string{
+		{"init"},
+		{"add", "-A"},
+		{"commit", "-m", message},
+	}
+	for _, args := range steps {
+		cmd := command(ctx, "git", append([]string{"-C", dir}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("error running git %s: %w\n%s", args[0], err, string(output))
+		}
+	}
+	return nil
+}
+
+// AddRemoteIn adds the remote name with url to the repository at dir.
+func AddRemoteIn(ctx context.Context, dir string, name string, url string) error {
+	cmd := command(ctx, "git", "-C", dir, "remote", "add", name, url)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error adding remote %s: %w\n%s", name, err, string(output))
+	}
+	return nil
+}
+
+// PushHeadIn pushes the current branch of the repository at dir to remote and
+// sets it as upstream.
+func PushHeadIn(ctx context.Context, dir string, remote string) error {
+	cmd := command(ctx, "git", "-C", dir, "push", "-u", remote, "HEAD")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error pushing to %s: %w\n%s", remote, err, string(output))
+	}
+	return nil
+}
+
+// CommandIn returns git with args run in the repository at dir, for callers
+// that need more than the helpers here (e.g. their own Env). It is run like
+// the other processes of the package: recorded, replayed, explained and
+// interrupted with the run.
+func CommandIn(ctx context.Context, dir string, args ...string) *Cmd {
+	return command(ctx, "git", append([]string{"-C", dir}, args...)...)
+}
+
+// TagExists reports whether tag exists locally or on origin.
+func TagExists(ctx context.Context, tag string) (bool, error) {
+	if command(ctx, "git", "show-ref", "--verify", "--quiet", "refs/tags/"+tag).Run() == nil {
+		return true, nil
+	}
+	if offline.Enabled() {
+		return false, nil
+	}
+	cmd := command(ctx, "git", "ls-remote", "--tags", "--refs", "origin", "refs/tags/"+tag)
+	output, err := cmd.Output()
+	if err != nil {
+		// Offline only local tags can be checked, pushing a taken tag fails later
+		if offline.Check(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error checking remote tag %s: %w", tag, err)
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// RemoteTagCommit returns the commit tag points to on origin, or "" when origin has no such tag.
+func RemoteTagCommit(ctx context.Context, tag string) (string, error) {
+	cmd := command(ctx, "git", "ls-remote", "--tags", "origin", "refs/tags/"+tag, "refs/tags/"+tag+"^{}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error checking remote tag %s: %w", tag, err)
+	}
+	// An annotated tag is listed twice, the peeled ^{} line names the commit
+	commit := ""
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if commit == "" || strings.HasSuffix(fields[1], "^{}") {
+			commit = fields[0]
+		}
+	}
+	return commit, nil
+}
+
+// Commit is a commit as listed by CommitsBetween.
+type Commit struct {
+	SHA     string
+	Subject string
+	Author  string
+}
+
+// Short returns the abbreviated SHA.
+func (c Commit) Short() string {
+	return ShortSHA(c.SHA)
+}
+
+// ShortSHA abbreviates a commit SHA to its first 8 characters.
+func ShortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+// CommitsBetween lists the non-merge commits reachable from to but not from from, newest first.
+func CommitsBetween(ctx context.Context, from string, to string) ([]Commit, error) {
+	cmd := command(ctx, "git", "log", "--no-merges", "--pretty=format:%H\t%an\t%s", from+".."+to, "--")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running git command to list commits %s..%s: %w", from, to, err)
+	}
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if parts := strings.SplitN(line, "\t", 3); len(parts) == 3 {
+			commits = append(commits, Commit{SHA: parts[0], Author: parts[1], Subject: parts[2]})
+		}
+	}
+	return commits, nil
+}
+
+// CommitMessage is a commit with its full message.
+type CommitMessage struct {
+	SHA     string
+	Subject string
+	Body    string // the message after the subject line, as written
+	Merge   bool
+}
+
+// Short returns the abbreviated SHA.
+func (c CommitMessage) Short() string {
+	return Commit{SHA: c.SHA}.Short()
+}
+
+// CommitMessages lists the commits selected by rev-list arguments with their
+// messages, newest first.
+func CommitMessages(ctx context.Context, revs []string) ([]CommitMessage, error) {
+	args := append([]string{"log", "--format=%H%x00%P%x00%B%x1e"}, revs...)
+	output, err := command(ctx, "git", append(args, "--")...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing commits %s: %w", strings.Join(revs, " "), err)
+	}
+	var commits []CommitMessage
+	for _, record := range strings.Split(string(output), "\x1e") {
+		parts := strings.SplitN(strings.TrimLeft(record, "\n"), "\x00", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		subject, body, _ := strings.Cut(strings.TrimRight(parts[2], "\n"), "\n")
+		commits = append(commits, CommitMessage{
+			SHA:     parts[0],
+			Subject: subject,
+			Body:    body,
+			Merge:   len(strings.Fields(parts[1])) > 1,
+		})
+	}
+	return commits, nil
+}
+
+// Upstream returns the upstream branch of the current branch (e.g.
+// "origin/main"), "" when it has none.
+func Upstream(ctx context.Context) string {
+	output, err := command(ctx, "git", "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// FileStat is the line count of a file changed between two revisions.
+type FileStat struct {
+	Path    string
+	Added   int
+	Deleted int
+	Binary  bool
+}
+
+// DiffStat lists the files changed on to since its merge base with from, i.e.
+// what merging to into from brings (git diff --numstat from...to).
+func DiffStat(ctx context.Context, from string, to string) ([]FileStat, error) {
+	cmd := command(ctx, "git", "diff", "--numstat", from+"..."+to, "--")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running git diff %s...%s: %w", from, to, err)
+	}
+	var stats []FileStat
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		stat := FileStat{Path: parts[2]}
+		if parts[0] == "-" {
+			// Binary files have no line counts
+			stat.Binary = true
+		} else {
+			stat.Added, _ = strconv.Atoi(parts[0])
+			stat.Deleted, _ = strconv.Atoi(parts[1])
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+// RepoStatus summarizes the state of a repository for 'prj dirty'.
+type RepoStatus struct {
+	Branch   string // empty when HEAD is detached
+	Changes  int    // staged, unstaged and untracked files
+	Unpushed int    // commits ahead of upstream, or not on any remote when there is no upstream
+	Upstream bool
+}
+
+// Dirty reports whether the repository has changes or unpushed commits.
+func (s RepoStatus) Dirty() bool {
+	return s.Changes > 0 || s.Unpushed > 0
+}
+
+// StatusOf returns the status of the repository at dir without changing the working directory.
+func StatusOf(ctx context.Context, dir string) (RepoStatus, error) {
+	var s RepoStatus
+	cmd := command(ctx, "git", "-C", dir, "status", "--porcelain=v2", "--branch")
+	output, err := cmd.Output()
+	if err != nil {
+		return s, fmt.Errorf("error running git status in %s: %w", dir, err)
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "# branch.head "):
+			if head := strings.TrimPrefix(line, "# branch.head "); head != "(detached)" {
+				s.Branch = head
+			}
+		case strings.HasPrefix(line, "# branch.upstream "):
+			s.Upstream = true
+		case strings.HasPrefix(line, "# branch.ab "):
+			// # branch.ab +<ahead> -<behind>
+			if fields := strings.Fields(line); len(fields) == 4 {
+				s.Unpushed, _ = strconv.Atoi(strings.TrimPrefix(fields[2], "+"))
+			}
+		case !strings.HasPrefix(line, "#"):
+			s.Changes++
+		}
+	}
+
+	if !s.Upstream {
+		// Branches never pushed: count commits no remote has. Fails on an empty repository, which has none anyway
+		cmd := command(ctx, "git", "-C", dir, "rev-list", "--count", "HEAD", "--not", "--remotes")
+		if output, err := cmd.Output(); err == nil {
+			s.Unpushed, _ = strconv.Atoi(strings.TrimSpace(string(output)))
+		}
+	}
+	return s, nil
+}
+
+// ShortStatusIn returns git status --short of the repository at dir, empty
+// when it is clean.
+func ShortStatusIn(ctx context.Context, dir string) (string, error) {
+	output, err := command(ctx, "git", "-C", dir, "status", "--short").Output()
+	if err != nil {
+		return "", fmt.Errorf("error running git status in %s: %w", dir, err)
+	}
+	if strings.TrimSpace(string(output)) == "" {
+		return "", nil
+	}
+	return string(output), nil
+}
+
+// ChangedFilesIn returns the files with uncommitted changes (untracked
+// included, deleted ones left out) in the repository at dir, relative to it.
+func ChangedFilesIn(ctx context.Context, dir string) ([]string, error) {
+	cmd := command(ctx, "git", "-C", dir, "status", "--porcelain", "-z", "--untracked-files=all")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running git status in %s: %w", dir, err)
+	}
+	var files []string
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		status, path := entry[:2], entry[3:]
+		if status[0] == 'R' || status[0] == 'C' {
+			// The original path follows as its own entry
+			i++
+		}
+		if strings.Contains(status, "D") {
+			continue
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// FileCommit is the latest commit touching a file.
+type FileCommit struct {
+	Path    string // relative to the repository root
+	SHA     string
+	Subject string
+	Time    time.Time
+}
+
+// RecentlyCommittedFilesIn returns the files touched by the last limit
+// non-merge commits of HEAD in the repository at dir, most recent first, each
+// with the latest commit touching it.
+func RecentlyCommittedFilesIn(ctx context.Context, dir string, limit int) ([]FileCommit, error) {
+	cmd := command(ctx, "git", "-C", dir, "log", "--no-merges", "--name-only", "-n", strconv.Itoa(limit), "--format=%x00%H%x09%ct%x09%s")
+	output, err := cmd.Output()
+	if err != nil {
+		// An empty repository has no commits
+		return nil, nil
+	}
+	var files []FileCommit
+	seen := map[string]bool{}
+	for _, block := range strings.Split(string(output), "\x00") {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+		header := strings.SplitN(lines[0], "\t", 3)
+		if len(header) != 3 {
+			continue
+		}
+		unix, _ := strconv.ParseInt(header[1], 10, 64)
+		for _, path := range lines[1:] {
+			if path = strings.TrimSpace(path); path == "" || seen[path] {
+				continue
+			}
+			seen[path] = true
+			files = append(files, FileCommit{Path: path, SHA: header[0], Subject: header[2], Time: time.Unix(unix, 0)})
+		}
+	}
+	return files, nil
+}
+
+// FirstChangedLineIn returns the first line of path changed by commit, or by
+// the uncommitted changes when commit is empty, in the repository at dir.
+// Returns 0 when it cannot tell (e.g. an untracked file).
+func FirstChangedLineIn(ctx context.Context, dir string, commit string, path string) int {
+	args := []string{"-C", dir, "diff", "-U0", "HEAD", "--", path}
+	if commit != "" {
+		args = []string{"-C", dir, "show", "-U0", "--format=", commit, "--", path}
+	}
+	output, err := command(ctx, "git", args...).Output()
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		// @@ -12,3 +14,5 @@ ...
+		if !strings.HasPrefix(line, "@@ ") {
+			continue
+		}
+		for _, field := range strings.Fields(line)[1:] {
+			if strings.HasPrefix(field, "+") {
+				start, _, _ := strings.Cut(strings.TrimPrefix(field, "+"), ",")
+				n, _ := strconv.Atoi(start)
+				return max(n, 1)
+			}
+		}
+	}
+	return 0
+}
+
+// BundleAllIn writes every ref of the repository at dir into a bundle file.
+func BundleAllIn(ctx context.Context, dir string, file string) error {
+	cmd := command(ctx, "git", "-C", dir, "bundle", "create", "--quiet", file, "--all")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error creating bundle of %s: %w (%s)", dir, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// RefsIn returns the refs of the repository at dir as "sha ref" lines, sorted.
+func RefsIn(ctx context.Context, dir string) ([]string, error) {
+	output, err := command(ctx, "git", "-C", dir, "for-each-ref", "--sort=refname", "--format=%(objectname) %(refname)").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing refs in %s: %w", dir, err)
+	}
+	return refLines(output), nil
+}
+
+// BundleRefs returns the refs recorded in a bundle file as "sha ref" lines, sorted.
+func BundleRefs(ctx context.Context, file string) ([]string, error) {
+	output, err := command(ctx, "git", "bundle", "list-heads", file).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error reading bundle %s: %w", file, err)
+	}
+	return refLines(output), nil
+}
+
+// refLines returns the "sha refs/..." lines of output, sorted, leaving out HEAD.
+func refLines(output []byte) []string {
+	var refs []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if _, ref, ok := strings.Cut(strings.TrimSpace(line), " "); ok && strings.HasPrefix(ref, "refs/") {
+			refs = append(refs, strings.TrimSpace(line))
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// PushMirrorIn pushes every ref of the repository at dir to remote, deleting
+// the refs the remote has but dir no longer does.
+func PushMirrorIn(ctx context.Context, dir string, remote string) error {
+	cmd := command(ctx, "git", "-C", dir, "push", "--mirror", "--quiet", remote)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error pushing mirror to %s: %w (%s)", remote, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// RemotesIn returns the remote names of the repository at dir.
+func RemotesIn(ctx context.Context, dir string) ([]string, error) {
+	output, err := command(ctx, "git", "-C", dir, "remote").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing remotes in %s: %w", dir, err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// RemoteURLIn returns the URL of the remote called name in the repository at dir.
+func RemoteURLIn(ctx context.Context, dir string, name string) (string, error) {
+	output, err := command(ctx, "git", "-C", dir, "config", "--get", "remote."+name+".url").Output()
+	if err != nil || strings.TrimSpace(string(output)) == "" {
+		return "", fmt.Errorf("git remote '%s' URL not found in %s", name, dir)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// SetRemoteURLIn changes the URL of the remote called name in the repository at dir.
+func SetRemoteURLIn(ctx context.Context, dir string, name string, url string) error {
+	cmd := command(ctx, "git", "-C", dir, "remote", "set-url", name, url)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error setting the URL of remote %s in %s: %w\n%s", name, dir, err, string(output))
+	}
+	return nil
+}
+
+// ProjectIDIn returns the project path of the origin remote of the repository at dir,
+// like ExtractProjectID does for the current directory.
+func ProjectIDIn(ctx context.Context, dir string) (string, error) {
+	output, err := command(ctx, "git", "-C", dir, "config", "--get", "remote.origin.url").Output()
+	if err != nil || strings.TrimSpace(string(output)) == "" {
+		return "", fmt.Errorf("git remote 'origin' URL not found in %s", dir)
+	}
+	remote, err := ParseRemoteURL(strings.TrimSpace(string(output)))
+	if err != nil {
+		return "", err
+	}
+	return remote.Path, nil
+}
+
+// BranchActivity is a branch and its latest commit.
+type BranchActivity struct {
+	Name   string    // e.g. "feature/login", without the remote
+	Date   time.Time // committer date of the tip
+	Author string    // author of the tip
+}
+
+// BranchActivityIn lists the branches of the repository at dir with their
+// latest commit: the origin/* branches from the last fetch, or the local ones.
+func BranchActivityIn(ctx context.Context, dir string, local bool) ([]BranchActivity, error) {
+	ref, prefix := "refs/remotes/origin", "origin/"
+	if local {
+		ref, prefix = "refs/heads", ""
+	}
+	output, err := command(ctx, "git", "-C", dir, "for-each-ref", "--format=%(refname:short)\t%(committerdate:unix)\t%(authorname)", ref).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing branches in %s: %w", dir, err)
+	}
+	var branches []BranchActivity
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		name := strings.TrimPrefix(fields[0], prefix)
+		// origin/HEAD is the symbolic ref to the default branch
+		if name == "HEAD" || name == "origin" {
+			continue
+		}
+		unix, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		branches = append(branches, BranchActivity{Name: name, Date: time.Unix(unix, 0), Author: fields[2]})
+	}
+	return branches, nil
+}
+
+// DefaultBranchIn returns the branch origin/HEAD points to in the repository
+// at dir, or "" when it isn't known.
+func DefaultBranchIn(ctx context.Context, dir string) string {
+	output, err := command(ctx, "git", "-C", dir, "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(output)), "origin/")
+}
+
+// UnpushedCommitsIn returns the number of commits on local branches of the
+// repository at dir that no remote has.
+func UnpushedCommitsIn(ctx context.Context, dir string) (int, error) {
+	output, err := command(ctx, "git", "-C", dir, "rev-list", "--count", "--branches", "--not", "--remotes").Output()
+	if err != nil {
+		return 0, fmt.Errorf("error counting unpushed commits in %s: %w", dir, err)
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+// Passthrough runs git with args attached to the terminal and returns its
+// exit code. The error is only set when git could not be started.
+func Passthrough(ctx context.Context, args []string) (int, error) {
+	cmd := command(ctx, "git", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if code, ok := exitCode(err); ok {
+		return code, nil
+	}
+	if err != nil {
+		return -1, fmt.Errorf("error running git: %w", err)
+	}
+	return 0, nil
 }
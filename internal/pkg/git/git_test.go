@@ -0,0 +1,198 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cli-aio/internal/pkg/offline"
+)
+
+// The fixtures of testdata were recorded with AIO_GIT_RECORD in a repository
+// with a bare origin, the remote URL then replaced by a made-up one.
+
+func TestGetCurrentBranch(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    HeadInfo
+		kind    ErrorKind
+	}{
+		{"head_branch", HeadInfo{Branch: "main", SHA: "17295a1"}, ""},
+		{"head_detached", HeadInfo{Detached: true, SHA: "6106fbc", Describe: "v1.1.0"}, ""},
+		{"head_not_a_repo", HeadInfo{}, ErrNotARepo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			replayFixture(t, tt.fixture)
+			head, err := GetCurrentBranch(context.Background())
+			if tt.kind != "" {
+				if KindOf(err) != tt.kind {
+					t.Fatalf("GetCurrentBranch error = %v, want a %s error", err, tt.kind)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if head != tt.want {
+				t.Errorf("GetCurrentBranch = %+v, want %+v", head, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckMergeConflicts(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		branch   string
+		conflict bool
+		fails    bool
+	}{
+		{"merge_merged", "feature/merged", false, false},
+		{"merge_clean", "feature/clean", false, false},
+		{"merge_conflict", "feature/conflict", true, false},
+		{"merge_unknown", "feature/missing", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			// Every fixture but merge_merged ends with the merge --abort calls,
+			// so they also check the test merge is always undone
+			replayFixture(t, tt.fixture)
+			conflict, err := CheckMergeConflicts(context.Background(), tt.branch)
+			if (err != nil) != tt.fails {
+				t.Fatalf("CheckMergeConflicts(%s) error = %v, want failure %v", tt.branch, err, tt.fails)
+			}
+			if conflict != tt.conflict {
+				t.Errorf("CheckMergeConflicts(%s) = %v, want %v", tt.branch, conflict, tt.conflict)
+			}
+		})
+	}
+}
+
+func TestLocalTags(t *testing.T) {
+	replayFixture(t, "tags_local")
+	tags, err := LocalTags(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The annotated tags give the commit they point to, the tag of a tag is
+	// peeled to its commit, the tag of a tree is left out, and the
+	// lightweight tag of the last line keeps its empty last fields
+	want := []TagInfo{
+		{"release-1.1", "6106fbcb8d5299785f8ceeace9c4366be93a9e1e", time.Unix(1768122000, 0)},
+		{"v1.1.0", "6106fbcb8d5299785f8ceeace9c4366be93a9e1e", time.Unix(1768039200, 0)},
+		{"v1.0.0", "c56f6358da406f0dee35ff0722760c27b03f026f", time.Unix(1767866400, 0)},
+		{"v0.9.0", "c56f6358da406f0dee35ff0722760c27b03f026f", time.Unix(1767780000, 0)},
+	}
+	if fmt.Sprint(tags) != fmt.Sprint(want) {
+		t.Errorf("LocalTags = %v, want %v", tags, want)
+	}
+}
+
+func TestGetLatestTags(t *testing.T) {
+	tests := []struct {
+		fixture string
+		limit   int
+		want    []string
+	}{
+		{"tags_remote", 2, []string{"v1.1.0", "v1.1.1-rc"}},
+		{"tags_remote", 10, []string{"v1.1.0", "v1.1.1-rc", "v1.0.0"}},
+		{"tags_none", 2, []string{"v0.0.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s limit %d", tt.fixture, tt.limit), func(t *testing.T) {
+			online(t)
+			replayFixture(t, tt.fixture)
+			tags, err := GetLatestTags(context.Background(), tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(tags) != fmt.Sprint(tt.want) {
+				t.Errorf("GetLatestTags(%d) = %v, want %v", tt.limit, tags, tt.want)
+			}
+		})
+	}
+}
+
+// An unreachable origin falls back to the tags of the last listing.
+func TestListRemoteTagsUnreachable(t *testing.T) {
+	online(t)
+	replayFixture(t, "tags_remote")
+	listed, err := ListRemoteTags(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayFixture(t, "tags_unreachable")
+	cached, err := ListRemoteTags(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(cached) != fmt.Sprint(listed) {
+		t.Errorf("ListRemoteTags offline = %v, want the cached %v", cached, listed)
+	}
+	if !offline.Enabled() {
+		t.Error("the network failure should switch to offline mode")
+	}
+}
+
+// online keeps the tag cache of the test out of the user's config and the
+// package in online mode until a network failure.
+func online(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	offline.Set(false)
+	t.Cleanup(func() { offline.Set(false) })
+}
+
+// replayFixture answers the processes of the test with testdata/<name>.json,
+// and checks they all ran.
+func replayFixture(t *testing.T, name string) *Replayer {
+	t.Helper()
+	r, err := LoadFixture(filepath.Join("testdata", name+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return useReplayer(t, r)
+}
+
+func TestLsRemoteBranchesIn(t *testing.T) {
+	replay(t, Call{Args: []string{"git", "-C", "/work/app", "ls-remote", "--heads", "origin"},
+		Stdout: "6106fbcb8d5299785f8ceeace9c4366be93a9e1e\trefs/heads/main\n" +
+			"c56f6358da406f0dee35ff0722760c27b03f026f\trefs/heads/feature/ABC-1-login\n"})
+	branches, err := LsRemoteBranchesIn(context.Background(), "/work/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(branches) != "[main feature/ABC-1-login]" {
+		t.Errorf("LsRemoteBranchesIn = %v", branches)
+	}
+}
+
+// Commands that may prompt for credentials stay in the terminal's process
+// group, see ExecRunner.
+func TestRemoteCommand(t *testing.T) {
+	for _, tt := range []struct {
+		args   []string
+		remote bool
+	}{
+		{[]string{"fetch", "--prune", "origin"}, true},
+		{[]string{"-C", "/src/app", "ls-remote", "--heads", "origin"}, true},
+		{[]string{"-c", "http.sslVerify=false", "clone", "https://example.com/a.git"}, true},
+		{[]string{"--no-pager", "push", "origin", "main"}, true},
+		{[]string{"lfs", "pull"}, true},
+		{[]string{"rev-parse", "HEAD"}, false},
+		{[]string{"-C", "fetch", "status"}, false},
+		{[]string{"merge", "--no-ff", "origin/push"}, false},
+		{nil, false},
+	} {
+		if got := remoteCommand(&Cmd{Name: "git", Args: tt.args}); got != tt.remote {
+			t.Errorf("remoteCommand(git %v) = %v, want %v", tt.args, got, tt.remote)
+		}
+	}
+	if remoteCommand(&Cmd{Name: "ssh", Args: []string{"fetch"}}) {
+		t.Error("only git commands are remote commands")
+	}
+}
@@ -0,0 +1,993 @@
+package git
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cli-aio/internal/pkg/offline"
+
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	format "github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// GoGitClient does the work of a GitClient in process with go-git, without
+// spawning git. Reads (HEAD, config, branches, tags, history) always run in
+// process. Writes and network operations run with Fallback when it is set:
+// git runs the hooks, credential helpers, merge drivers and LFS filters
+// go-git ignores. Without git installed (no Fallback) they run with go-git
+// too, within its limits: pulls only fast-forward, merges only combine files
+// changed on one side, and rebasing needs git. Reads go-git can't answer,
+// e.g. in a shallow clone, also go to Fallback.
+type GoGitClient struct {
+	Fallback GitClient
+
+	mu   sync.Mutex
+	repo *gogit.Repository
+}
+
+// errUnsupported hands a call go-git can't answer to the fallback.
+var errUnsupported = errors.New("not supported by go-git")
+
+// open returns the repository of the current directory, opened once.
+func (g *GoGitClient) open(ctx context.Context) (*gogit.Repository, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, goGitError(err)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.repo != nil {
+		return g.repo, nil
+	}
+	repo, err := gogit.PlainOpenWithOptions(".", &gogit.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if errors.Is(err, gogit.ErrRepositoryNotExists) {
+		return nil, goGitError(err)
+	}
+	if err != nil {
+		// e.g. a repository extension go-git doesn't know
+		return nil, fmt.Errorf("%w: %v", errUnsupported, err)
+	}
+	g.repo = repo
+	return repo, nil
+}
+
+// read answers a read with go-git, or with fallback when go-git can't.
+func read[T any](ctx context.Context, g *GoGitClient, answer func(repo *gogit.Repository) (T, error), fallback func(GitClient) (T, error)) (T, error) {
+	repo, err := g.open(ctx)
+	if err == nil {
+		var value T
+		if value, err = answer(repo); !errors.Is(err, errUnsupported) {
+			return value, err
+		}
+	}
+	if errors.Is(err, errUnsupported) && g.Fallback != nil {
+		return fallback(g.Fallback)
+	}
+	var zero T
+	if errors.Is(err, errUnsupported) {
+		return zero, &GitError{Args: []string{"go-git"}, Code: -1, Stderr: err.Error(), Err: err, kind: ErrNotInstalled}
+	}
+	return zero, err
+}
+
+// write runs a write with go-git, or with Fallback when git is installed.
+func (g *GoGitClient) write(ctx context.Context, inProcess func(repo *gogit.Repository) error, fallback func(GitClient) error) error {
+	if g.Fallback != nil {
+		return fallback(g.Fallback)
+	}
+	repo, err := g.open(ctx)
+	if err != nil {
+		return err
+	}
+	return inProcess(repo)
+}
+
+// goGitError returns err as a *GitError of the kind git would have failed
+// with, so callers branch on KindOf whichever client ran.
+func goGitError(err error) error {
+	var gitErr *GitError
+	if err == nil || errors.As(err, &gitErr) {
+		return err
+	}
+	kind := ErrOther
+	var noMatch gogit.NoMatchingRefSpecError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		kind = ErrCancelled
+	case errors.Is(err, gogit.ErrRepositoryNotExists), errors.Is(err, gogit.ErrIsBareRepository):
+		kind = ErrNotARepo
+	case errors.Is(err, transport.ErrAuthenticationRequired), errors.Is(err, transport.ErrAuthorizationFailed),
+		errors.Is(err, transport.ErrInvalidAuthMethod), errors.Is(err, transport.ErrRepositoryNotFound):
+		kind = ErrAuth
+	case offline.IsNetworkError(err):
+		kind = ErrNetwork
+	case errors.Is(err, plumbing.ErrReferenceNotFound), errors.Is(err, plumbing.ErrObjectNotFound),
+		errors.Is(err, gogit.ErrBranchNotFound), errors.Is(err, gogit.ErrTagNotFound),
+		errors.Is(err, gogit.ErrRemoteNotFound), errors.As(err, &noMatch):
+		kind = ErrNotFound
+	case errors.Is(err, gogit.ErrUnstagedChanges), errors.Is(err, gogit.ErrWorktreeNotClean):
+		kind = ErrLocalChanges
+	case errors.Is(err, gogit.ErrBranchExists), errors.Is(err, gogit.ErrTagExists):
+		kind = ErrExists
+	case errors.Is(err, gogit.ErrForceNeeded):
+		kind = ErrRejected
+	default:
+		// e.g. the report of a push refused by a hook of origin
+		kind = classify(err.Error())
+	}
+	return &GitError{Args: []string{"go-git"}, Code: -1, Stderr: err.Error(), Err: err, kind: kind}
+}
+
+// kindError returns a *GitError of kind with message, for the failures
+// go-git doesn't report with an error of its own.
+func kindError(kind ErrorKind, message string) error {
+	return &GitError{Args: []string{"go-git"}, Code: -1, Stderr: message, Err: errors.New(message), kind: kind}
+}
+
+// upToDate drops go-git's "already up-to-date", not a failure for git.
+func upToDate(err error) error {
+	if errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return nil
+	}
+	return err
+}
+
+func (g *GoGitClient) IsRepo(ctx context.Context) (bool, error) {
+	return read(ctx, g, func(repo *gogit.Repository) (bool, error) {
+		_, err := repo.Worktree()
+		return err == nil, nil
+	}, func(fallback GitClient) (bool, error) { return fallback.IsRepo(ctx) })
+}
+
+func (g *GoGitClient) TopLevel(ctx context.Context) (string, error) {
+	return read(ctx, g, func(repo *gogit.Repository) (string, error) {
+		wt, err := repo.Worktree()
+		if err != nil {
+			return "", fmt.Errorf("error getting repository root: %w", goGitError(err))
+		}
+		// git prints the path with the symlinks resolved
+		return filepath.EvalSymlinks(wt.Filesystem.Root())
+	}, func(fallback GitClient) (string, error) { return fallback.TopLevel(ctx) })
+}
+
+func (g *GoGitClient) Head(ctx context.Context) (HeadInfo, error) {
+	return read(ctx, g, func(repo *gogit.Repository) (HeadInfo, error) {
+		var head HeadInfo
+		ref, err := repo.Storer.Reference(plumbing.HEAD)
+		if err != nil {
+			return head, fmt.Errorf("error getting current branch: %w", goGitError(err))
+		}
+		resolved, err := repo.Head()
+		if err == nil {
+			head.SHA = abbrev(repo, resolved.Hash())
+		} else if ref.Type() != plumbing.SymbolicReference {
+			return head, fmt.Errorf("error getting current branch: %w", goGitError(err))
+		}
+		if ref.Type() == plumbing.SymbolicReference {
+			head.Branch = ref.Target().Short()
+			return head, nil
+		}
+		head.Detached = true
+		if head.Describe, err = exactTag(repo, resolved.Hash()); err != nil {
+			return head, err
+		}
+		if head.Describe == "" && g.Fallback != nil {
+			// Only git describes a commit from the tag before it
+			if described, err := g.Fallback.Head(ctx); err == nil {
+				head.Describe = described.Describe
+			}
+		}
+		return head, nil
+	}, func(fallback GitClient) (HeadInfo, error) { return fallback.Head(ctx) })
+}
+
+func (g *GoGitClient) HeadCommit(ctx context.Context) (string, error) {
+	return read(ctx, g, func(repo *gogit.Repository) (string, error) {
+		head, err := repo.Head()
+		if err != nil {
+			return "", fmt.Errorf("error getting HEAD commit: %w", goGitError(err))
+		}
+		return head.Hash().String(), nil
+	}, func(fallback GitClient) (string, error) { return fallback.HeadCommit(ctx) })
+}
+
+func (g *GoGitClient) Config(ctx context.Context, key string) string {
+	value, _ := read(ctx, g, func(repo *gogit.Repository) (string, error) {
+		return configValue(repo, key)
+	}, func(fallback GitClient) (string, error) { return fallback.Config(ctx, key), nil })
+	return value
+}
+
+func (g *GoGitClient) ProjectID(ctx context.Context) (string, error) {
+	url := g.Config(ctx, "remote.origin.url")
+	if url == "" {
+		return "", fmt.Errorf("git remote 'origin' URL not found")
+	}
+	remote, err := ParseRemoteURL(url)
+	if err != nil {
+		return "", err
+	}
+	return remote.Path, nil
+}
+
+// IsClean runs git status when it can: it honors every ignore rule and is
+// faster than go-git's status on large trees.
+func (g *GoGitClient) IsClean(ctx context.Context) (bool, error) {
+	if g.Fallback != nil {
+		return g.Fallback.IsClean(ctx)
+	}
+	repo, err := g.open(ctx)
+	if err != nil {
+		return false, err
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return false, goGitError(err)
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return false, fmt.Errorf("error getting status: %w", goGitError(err))
+	}
+	return status.IsClean(), nil
+}
+
+func (g *GoGitClient) LocalBranches(ctx context.Context) ([]string, error) {
+	return read(ctx, g, func(repo *gogit.Repository) ([]string, error) {
+		names, err := refNames(repo, plumbing.ReferenceName.IsBranch)
+		if err != nil {
+			return nil, fmt.Errorf("error getting local branches: %w", goGitError(err))
+		}
+		return names, nil
+	}, func(fallback GitClient) ([]string, error) { return fallback.LocalBranches(ctx) })
+}
+
+func (g *GoGitClient) RemoteBranches(ctx context.Context) ([]string, error) {
+	return read(ctx, g, func(repo *gogit.Repository) ([]string, error) {
+		names, err := refNames(repo, plumbing.ReferenceName.IsRemote)
+		if err != nil {
+			return nil, fmt.Errorf("error getting remote branches: %w", goGitError(err))
+		}
+		return parseRemoteBranches([]byte(strings.Join(names, "\n"))), nil
+	}, func(fallback GitClient) ([]string, error) { return fallback.RemoteBranches(ctx) })
+}
+
+func (g *GoGitClient) BranchExists(ctx context.Context, branch string) (bool, error) {
+	return read(ctx, g, func(repo *gogit.Repository) (bool, error) {
+		return hasRef(repo, plumbing.NewBranchReferenceName(branch)) ||
+			hasRef(repo, plumbing.NewRemoteReferenceName("origin", branch)), nil
+	}, func(fallback GitClient) (bool, error) { return fallback.BranchExists(ctx, branch) })
+}
+
+func (g *GoGitClient) RemoteBranchExists(ctx context.Context, branch string) bool {
+	exists, _ := read(ctx, g, func(repo *gogit.Repository) (bool, error) {
+		return hasRef(repo, plumbing.NewRemoteReferenceName("origin", branch)), nil
+	}, func(fallback GitClient) (bool, error) { return fallback.RemoteBranchExists(ctx, branch), nil })
+	return exists
+}
+
+// IsValidBranchName asks git check-ref-format when git is installed. Without
+// it, go-git's checks of a reference name apply, which accept a few names git
+// refuses (e.g. "a.lock/b" or ones with "@{").
+func (g *GoGitClient) IsValidBranchName(ctx context.Context, name string) bool {
+	if g.Fallback != nil {
+		return g.Fallback.IsValidBranchName(ctx, name)
+	}
+	return name != "HEAD" && !strings.HasPrefix(name, "-") && plumbing.NewBranchReferenceName(name).Validate() == nil
+}
+
+func (g *GoGitClient) Checkout(ctx context.Context, branch string) error {
+	return g.write(ctx, func(repo *gogit.Repository) error {
+		if !hasRef(repo, plumbing.NewBranchReferenceName(branch)) {
+			if hasRef(repo, plumbing.NewRemoteReferenceName("origin", branch)) {
+				// git checkout creates the tracking branch of a remote one
+				return g.CheckoutTracking(ctx, branch)
+			}
+			return fmt.Errorf("error checking out branch %s: %w", branch,
+				kindError(ErrNotFound, fmt.Sprintf("error: pathspec '%s' did not match any file(s) known to git", branch)))
+		}
+		if err := checkout(repo, &gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branch)}); err != nil {
+			return fmt.Errorf("error checking out branch %s: %w", branch, err)
+		}
+		return nil
+	}, func(fallback GitClient) error { return fallback.Checkout(ctx, branch) })
+}
+
+func (g *GoGitClient) CheckoutTracking(ctx context.Context, branch string) error {
+	return g.write(ctx, func(repo *gogit.Repository) error {
+		if err := createBranch(repo, branch, "origin/"+branch); err != nil {
+			return fmt.Errorf("error checking out remote branch %s: %w", branch, err)
+		}
+		err := repo.CreateBranch(&gitconfig.Branch{Name: branch, Remote: "origin", Merge: plumbing.NewBranchReferenceName(branch)})
+		if err != nil && !errors.Is(err, gogit.ErrBranchExists) {
+			return fmt.Errorf("error setting the upstream of %s: %w", branch, goGitError(err))
+		}
+		return nil
+	}, func(fallback GitClient) error { return fallback.CheckoutTracking(ctx, branch) })
+}
+
+func (g *GoGitClient) CreateBranch(ctx context.Context, branch string, base string) error {
+	return g.write(ctx, func(repo *gogit.Repository) error {
+		if base == "" {
+			base = "HEAD"
+		}
+		if err := createBranch(repo, branch, base); err != nil {
+			return fmt.Errorf("error creating branch %s: %w", branch, err)
+		}
+		return nil
+	}, func(fallback GitClient) error { return fallback.CreateBranch(ctx, branch, base) })
+}
+
+func (g *GoGitClient) RenameBranch(ctx context.Context, oldName string, newName string) error {
+	return g.write(ctx, func(repo *gogit.Repository) error {
+		if err := renameBranch(repo, oldName, newName); err != nil {
+			return fmt.Errorf("error renaming branch %s: %w", oldName, err)
+		}
+		return nil
+	}, func(fallback GitClient) error { return fallback.RenameBranch(ctx, oldName, newName) })
+}
+
+func (g *GoGitClient) DeleteBranch(ctx context.Context, branch string, force bool) error {
+	return g.write(ctx, func(repo *gogit.Repository) error {
+		if err := deleteBranch(repo, branch, force); err != nil {
+			return fmt.Errorf("error deleting branch %s: %w", branch, err)
+		}
+		return nil
+	}, func(fallback GitClient) error { return fallback.DeleteBranch(ctx, branch, force) })
+}
+
+func (g *GoGitClient) DeleteRemoteBranch(ctx context.Context, branch string) error {
+	return g.write(ctx, func(repo *gogit.Repository) error {
+		spec := gitconfig.RefSpec(":" + plumbing.NewBranchReferenceName(branch))
+		err := repo.PushContext(ctx, &gogit.PushOptions{RemoteName: "origin", RefSpecs: []gitconfig.RefSpec{spec}})
+		if errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+			// Nothing was deleted: origin has no such branch
+			err = kindError(ErrNotFound, fmt.Sprintf("error: unable to delete '%s': remote ref does not exist", branch))
+		}
+		if err != nil {
+			return fmt.Errorf("error deleting remote branch %s: %w", branch, goGitError(err))
+		}
+		// git forgets the remote-tracking branch with it
+		repo.Storer.RemoveReference(plumbing.NewRemoteReferenceName("origin", branch))
+		return nil
+	}, func(fallback GitClient) error { return fallback.DeleteRemoteBranch(ctx, branch) })
+}
+
+func (g *GoGitClient) FetchBranch(ctx context.Context, branch string) error {
+	return g.write(ctx, func(repo *gogit.Repository) error {
+		spec := gitconfig.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(branch), plumbing.NewRemoteReferenceName("origin", branch)))
+		err := repo.FetchContext(ctx, &gogit.FetchOptions{RemoteName: "origin", RefSpecs: []gitconfig.RefSpec{spec}})
+		if err := upToDate(err); err != nil {
+			return fmt.Errorf("error fetching branch %s: %w", branch, goGitError(err))
+		}
+		return nil
+	}, func(fallback GitClient) error { return fallback.FetchBranch(ctx, branch) })
+}
+
+func (g *GoGitClient) Pull(ctx context.Context) error {
+	return g.write(ctx, func(repo *gogit.Repository) error {
+		if err := pull(ctx, repo); err != nil {
+			return fmt.Errorf("error pulling branch: %w", err)
+		}
+		return nil
+	}, func(fallback GitClient) error { return fallback.Pull(ctx) })
+}
+
+func (g *GoGitClient) PruneRemote(ctx context.Context, dryRun bool) ([]string, error) {
+	if g.Fallback != nil {
+		return g.Fallback.PruneRemote(ctx, dryRun)
+	}
+	repo, err := g.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pruned, err := pruneRemote(ctx, repo, dryRun)
+	if err != nil {
+		return nil, fmt.Errorf("error pruning remote branches: %w", err)
+	}
+	return pruned, nil
+}
+
+func (g *GoGitClient) GoneBranches(ctx context.Context) ([]string, error) {
+	return read(ctx, g, func(repo *gogit.Repository) ([]string, error) {
+		cfg, err := repo.Config()
+		if err != nil {
+			return nil, fmt.Errorf("error getting branch upstream status: %w", goGitError(err))
+		}
+		branches, err := refNames(repo, plumbing.ReferenceName.IsBranch)
+		if err != nil {
+			return nil, fmt.Errorf("error getting branch upstream status: %w", goGitError(err))
+		}
+		var gone []string
+		for _, name := range branches {
+			b, ok := cfg.Branches[name]
+			if !ok || b.Remote == "" || b.Remote == "." || !b.Merge.IsBranch() {
+				continue
+			}
+			if !hasRef(repo, plumbing.NewRemoteReferenceName(b.Remote, b.Merge.Short())) {
+				gone = append(gone, name)
+			}
+		}
+		return gone, nil
+	}, func(fallback GitClient) ([]string, error) { return fallback.GoneBranches(ctx) })
+}
+
+func (g *GoGitClient) IsAncestor(ctx context.Context, ref string, of string) bool {
+	contained, _ := read(ctx, g, func(repo *gogit.Repository) (bool, error) {
+		var hashes [2]plumbing.Hash
+		for i, rev := range []string{ref, of} {
+			hash, err := resolve(repo, rev)
+			if err != nil {
+				return false, err
+			}
+			hashes[i] = hash
+		}
+		commits, err := walkBetween(ctx, repo, hashes[1], hashes[0])
+		if err != nil {
+			return false, err
+		}
+		return len(commits) == 0, nil
+	}, func(fallback GitClient) (bool, error) { return fallback.IsAncestor(ctx, ref, of), nil })
+	return contained
+}
+
+func (g *GoGitClient) CommitsBetween(ctx context.Context, from string, to string) ([]Commit, error) {
+	return read(ctx, g, func(repo *gogit.Repository) ([]Commit, error) {
+		fromHash, err := resolve(repo, from)
+		if err != nil {
+			return nil, fmt.Errorf("error listing commits %s..%s: %w", from, to, err)
+		}
+		toHash, err := resolve(repo, to)
+		if err != nil {
+			return nil, fmt.Errorf("error listing commits %s..%s: %w", from, to, err)
+		}
+		walked, err := walkBetween(ctx, repo, fromHash, toHash)
+		if err != nil {
+			return nil, err
+		}
+		var commits []Commit
+		for _, c := range walked {
+			if c.NumParents() > 1 {
+				continue
+			}
+			commits = append(commits, Commit{SHA: c.Hash.String(), Author: c.Author.Name, Subject: subject(c.Message)})
+		}
+		return commits, nil
+	}, func(fallback GitClient) ([]Commit, error) { return fallback.CommitsBetween(ctx, from, to) })
+}
+
+func (g *GoGitClient) RecentCommits(ctx context.Context, ref string, limit int) ([]string, error) {
+	return read(ctx, g, func(repo *gogit.Repository) ([]string, error) {
+		hash, err := resolve(repo, ref)
+		if err != nil {
+			return nil, fmt.Errorf("error listing commits of %s: %w", ref, err)
+		}
+		iter, err := repo.Log(&gogit.LogOptions{From: hash, Order: gogit.LogOrderCommitterTime})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errUnsupported, err)
+		}
+		defer iter.Close()
+		length := abbrevLength(repo)
+		var commits []string
+		for len(commits) < limit {
+			c, err := iter.Next()
+			if err != nil {
+				break
+			}
+			commits = append(commits, c.Hash.String()[:length]+" "+subject(c.Message))
+		}
+		return commits, nil
+	}, func(fallback GitClient) ([]string, error) { return fallback.RecentCommits(ctx, ref, limit) })
+}
+
+func (g *GoGitClient) DiffStat(ctx context.Context, from string, to string) ([]FileStat, error) {
+	return read(ctx, g, func(repo *gogit.Repository) ([]FileStat, error) {
+		stats, err := diffStat(ctx, repo, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("error diffing %s...%s: %w", from, to, err)
+		}
+		return stats, nil
+	}, func(fallback GitClient) ([]FileStat, error) { return fallback.DiffStat(ctx, from, to) })
+}
+
+// CheckMergeConflicts compares the files changed on both sides in process:
+// when no file changed on both, the merge is clean. Otherwise git decides by
+// merging the contents, or without git the merge is reported conflicting.
+func (g *GoGitClient) CheckMergeConflicts(ctx context.Context, source string) (bool, error) {
+	repo, err := g.open(ctx)
+	if err == nil {
+		var plan *mergePlan
+		if plan, err = planMerge(ctx, repo, source); err == nil {
+			if len(plan.conflicts) == 0 {
+				return false, nil
+			}
+			if g.Fallback == nil {
+				return true, nil
+			}
+		}
+	}
+	if g.Fallback != nil && (err == nil || errors.Is(err, errUnsupported)) {
+		return g.Fallback.CheckMergeConflicts(ctx, source)
+	}
+	return false, fmt.Errorf("error checking merge conflicts: %w", err)
+}
+
+func (g *GoGitClient) Merge(ctx context.Context, source string, noFF bool) error {
+	return g.write(ctx, func(repo *gogit.Repository) error {
+		if err := merge(ctx, repo, source, noFF); err != nil {
+			return fmt.Errorf("error merging branch %s: %w", source, err)
+		}
+		return nil
+	}, func(fallback GitClient) error { return fallback.Merge(ctx, source, noFF) })
+}
+
+func (g *GoGitClient) MergeInProgress(ctx context.Context) bool {
+	inProgress, _ := read(ctx, g, func(repo *gogit.Repository) (bool, error) {
+		return hasRef(repo, "MERGE_HEAD"), nil
+	}, func(fallback GitClient) (bool, error) { return fallback.MergeInProgress(ctx), nil })
+	return inProgress
+}
+
+func (g *GoGitClient) AbortMerge(ctx context.Context) error {
+	return g.write(ctx, func(repo *gogit.Repository) error {
+		if err := abortMerge(repo); err != nil {
+			return fmt.Errorf("error aborting merge: %w", err)
+		}
+		return nil
+	}, func(fallback GitClient) error { return fallback.AbortMerge(ctx) })
+}
+
+func (g *GoGitClient) Rebase(ctx context.Context, upstream string) error {
+	return g.write(ctx, func(repo *gogit.Repository) error {
+		return fmt.Errorf("error rebasing onto %s: %w", upstream, kindError(ErrNotInstalled, "rebasing needs git, which is not installed"))
+	}, func(fallback GitClient) error { return fallback.Rebase(ctx, upstream) })
+}
+
+func (g *GoGitClient) ResetHard(ctx context.Context, ref string) error {
+	return g.write(ctx, func(repo *gogit.Repository) error {
+		if err := reset(repo, ref, gogit.HardReset); err != nil {
+			return fmt.Errorf("error resetting to %s: %w", ref, err)
+		}
+		return nil
+	}, func(fallback GitClient) error { return fallback.ResetHard(ctx, ref) })
+}
+
+func (g *GoGitClient) LocalTags(ctx context.Context) ([]TagInfo, error) {
+	return read(ctx, g, func(repo *gogit.Repository) ([]TagInfo, error) {
+		tags, err := sortedTags(repo)
+		if err != nil {
+			return nil, fmt.Errorf("error listing local tags: %w", goGitError(err))
+		}
+		infos := make([]TagInfo, len(tags))
+		for i, tag := range tags {
+			infos[i] = tag.TagInfo
+		}
+		return infos, nil
+	}, func(fallback GitClient) ([]TagInfo, error) { return fallback.LocalTags(ctx) })
+}
+
+func (g *GoGitClient) RemoteTags(ctx context.Context) ([]string, error) {
+	if g.Fallback != nil {
+		return g.Fallback.RemoteTags(ctx)
+	}
+	repo, err := g.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	url := g.Config(ctx, "remote.origin.url")
+	if offline.Enabled() {
+		return goGitOfflineTags(repo, url)
+	}
+	tags, err := remoteTags(ctx, repo)
+	if err != nil {
+		if offline.Check(err) {
+			return goGitOfflineTags(repo, url)
+		}
+		return nil, fmt.Errorf("error listing the tags of origin: %w", goGitError(err))
+	}
+	if url != "" {
+		offline.SaveTags(url, tags)
+	}
+	return tags, nil
+}
+
+func (g *GoGitClient) TagExists(ctx context.Context, tag string) (bool, error) {
+	repo, err := g.open(ctx)
+	if err != nil {
+		return false, err
+	}
+	if hasRef(repo, plumbing.NewTagReferenceName(tag)) {
+		return true, nil
+	}
+	if offline.Enabled() {
+		return false, nil
+	}
+	if g.Fallback != nil {
+		return g.Fallback.TagExists(ctx, tag)
+	}
+	refs, err := listRemote(ctx, repo)
+	if err != nil {
+		// Offline only local tags can be checked, pushing a taken tag fails later
+		if offline.Check(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error checking remote tag %s: %w", tag, goGitError(err))
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.NewTagReferenceName(tag) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (g *GoGitClient) CreateTag(ctx context.Context, tag string, message string) error {
+	return g.write(ctx, func(repo *gogit.Repository) error {
+		head, err := repo.Head()
+		if err == nil {
+			_, err = repo.CreateTag(tag, head.Hash(), &gogit.CreateTagOptions{Message: message})
+		}
+		if err != nil {
+			return fmt.Errorf("error creating tag: %w", goGitError(err))
+		}
+		return nil
+	}, func(fallback GitClient) error { return fallback.CreateTag(ctx, tag, message) })
+}
+
+func (g *GoGitClient) PushTag(ctx context.Context, tag string) error {
+	return g.write(ctx, func(repo *gogit.Repository) error {
+		ref := plumbing.NewTagReferenceName(tag)
+		spec := gitconfig.RefSpec(ref + ":" + ref)
+		err := repo.PushContext(ctx, &gogit.PushOptions{RemoteName: "origin", RefSpecs: []gitconfig.RefSpec{spec}})
+		if err := upToDate(err); err != nil {
+			return fmt.Errorf("error pushing tag: %w", goGitError(err))
+		}
+		return nil
+	}, func(fallback GitClient) error { return fallback.PushTag(ctx, tag) })
+}
+
+func (g *GoGitClient) FetchTags(ctx context.Context) error {
+	if offline.Enabled() {
+		return fmt.Errorf("skipped fetching tags: %w", offline.ErrOffline)
+	}
+	return g.write(ctx, func(repo *gogit.Repository) error {
+		err := repo.FetchContext(ctx, &gogit.FetchOptions{RemoteName: "origin", Tags: gogit.AllTags})
+		if err := upToDate(err); err != nil {
+			return fmt.Errorf("error fetching tags: %w", goGitError(err))
+		}
+		return nil
+	}, func(fallback GitClient) error { return fallback.FetchTags(ctx) })
+}
+
+func (g *GoGitClient) DeleteLocalTags(ctx context.Context, tags []string) error {
+	return g.write(ctx, func(repo *gogit.Repository) error {
+		for _, tag := range tags {
+			if err := repo.DeleteTag(tag); err != nil {
+				return fmt.Errorf("error deleting local tags: %w", goGitError(err))
+			}
+		}
+		return nil
+	}, func(fallback GitClient) error { return fallback.DeleteLocalTags(ctx, tags) })
+}
+
+func (g *GoGitClient) DeleteRemoteTags(ctx context.Context, tags []string) error {
+	return g.write(ctx, func(repo *gogit.Repository) error {
+		specs := make([]gitconfig.RefSpec, len(tags))
+		for i, tag := range tags {
+			specs[i] = gitconfig.RefSpec(":" + plumbing.NewTagReferenceName(tag))
+		}
+		err := repo.PushContext(ctx, &gogit.PushOptions{RemoteName: "origin", RefSpecs: specs})
+		if err := upToDate(err); err != nil {
+			return fmt.Errorf("error deleting tags on origin: %w", goGitError(err))
+		}
+		return nil
+	}, func(fallback GitClient) error { return fallback.DeleteRemoteTags(ctx, tags) })
+}
+
+// hasRef reports whether the reference name exists.
+func hasRef(repo *gogit.Repository, name plumbing.ReferenceName) bool {
+	_, err := repo.Storer.Reference(name)
+	return err == nil
+}
+
+// resolve returns the commit rev names. A revision go-git doesn't parse
+// goes to the fallback.
+func resolve(repo *gogit.Repository, rev string) (plumbing.Hash, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if errors.Is(err, plumbing.ErrReferenceNotFound) || errors.Is(err, plumbing.ErrObjectNotFound) {
+		return plumbing.ZeroHash, kindError(ErrNotFound, fmt.Sprintf("fatal: ambiguous argument '%s': unknown revision", rev))
+	}
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("%w: %v", errUnsupported, err)
+	}
+	return *hash, nil
+}
+
+// refNames returns the short names of the references kept by keep, sorted
+// like git branch. Symbolic ones (origin/HEAD) are left out.
+func refNames(repo *gogit.Repository, keep func(plumbing.ReferenceName) bool) ([]string, error) {
+	refs, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.HashReference && keep(ref.Name()) {
+			names = append(names, ref.Name().Short())
+		}
+		return nil
+	})
+	sort.Strings(names)
+	return names, err
+}
+
+// configValue returns key (section[.subsection].name) from the repository
+// config, then the global and system ones. Files with include directives
+// go-git doesn't follow are left to git.
+func configValue(repo *gogit.Repository, key string) (string, error) {
+	local, err := repo.Storer.Config()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", errUnsupported, err)
+	}
+	raws := []*format.Config{local.Raw}
+	for _, scope := range []gitconfig.Scope{gitconfig.GlobalScope, gitconfig.SystemScope} {
+		cfg, err := gitconfig.LoadConfig(scope)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", errUnsupported, err)
+		}
+		raws = append(raws, cfg.Raw)
+	}
+	for _, raw := range raws {
+		if raw.HasSection("include") || raw.HasSection("includeIf") {
+			return "", errUnsupported
+		}
+		if value, ok := rawOption(raw, key); ok {
+			return value, nil
+		}
+	}
+	return "", nil
+}
+
+// rawOption returns the value of key (section[.subsection].name) in raw.
+func rawOption(raw *format.Config, key string) (string, bool) {
+	first, last := strings.Index(key, "."), strings.LastIndex(key, ".")
+	if first < 0 {
+		return "", false
+	}
+	section, name := key[:first], key[last+1:]
+	if !raw.HasSection(section) {
+		return "", false
+	}
+	s := raw.Section(section)
+	if first == last {
+		return s.Option(name), s.HasOption(name)
+	}
+	subsection := key[first+1 : last]
+	if !s.HasSubsection(subsection) {
+		return "", false
+	}
+	ss := s.Subsection(subsection)
+	return ss.Option(name), ss.HasOption(name)
+}
+
+// abbrev returns hash abbreviated like git.
+func abbrev(repo *gogit.Repository, hash plumbing.Hash) string {
+	return hash.String()[:abbrevLength(repo)]
+}
+
+// abbrevLength returns core.abbrev, else the length git picks for the number
+// of objects: half the bits of the packed object count, at least 7.
+func abbrevLength(repo *gogit.Repository) int {
+	cfg, err := repo.Config()
+	if err == nil && cfg.Raw.Section("core").HasOption("abbrev") {
+		if n, err := strconv.Atoi(cfg.Raw.Section("core").Option("abbrev")); err == nil && n >= 4 && n <= 40 {
+			return n
+		}
+	}
+	return min(max(7, (bits.Len64(packedObjects(repo))+1)/2), 40)
+}
+
+// packedObjects returns the number of objects in the packs of repo, read from
+// the fan-out table of their indexes as git does.
+func packedObjects(repo *gogit.Repository) uint64 {
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return 0
+	}
+	packs, err := storage.ObjectPacks()
+	if err != nil {
+		return 0
+	}
+	var count uint64
+	fs := storage.Filesystem()
+	for _, pack := range packs {
+		f, err := fs.Open(fs.Join("objects", "pack", "pack-"+pack.String()+".idx"))
+		if err != nil {
+			continue
+		}
+		// Version 2 index: magic, version, then 256 cumulative counts
+		var header [8 + 256*4]byte
+		if _, err := f.Read(header[:]); err == nil && string(header[:4]) == "\377tOc" {
+			count += uint64(binary.BigEndian.Uint32(header[len(header)-4:]))
+		}
+		f.Close()
+	}
+	return count
+}
+
+// exactTag returns the tag pointing at hash as git describe --tags would
+// name it: annotated tags first, then the newest. "" when there is none.
+func exactTag(repo *gogit.Repository, hash plumbing.Hash) (string, error) {
+	tags, err := sortedTags(repo)
+	if err != nil {
+		return "", goGitError(err)
+	}
+	name := ""
+	for _, tag := range tags {
+		if tag.Commit != hash.String() {
+			continue
+		}
+		if tag.peeled != "" {
+			return tag.Name, nil
+		}
+		if name == "" {
+			name = tag.Name
+		}
+	}
+	return name, nil
+}
+
+// subject returns the subject of a commit message like git's %s: its first
+// paragraph on one line.
+func subject(message string) string {
+	paragraph, _, _ := strings.Cut(strings.TrimLeft(message, "\n"), "\n\n")
+	return strings.Join(strings.Fields(strings.ReplaceAll(paragraph, "\n", " ")), " ")
+}
+
+// goGitTag is a tag with the fields of git's --sort=-creatordate listing.
+type goGitTag struct {
+	TagInfo
+	// peeled is the commit of an annotated tag, "" for a lightweight one
+	peeled string
+}
+
+// sortedTags returns the tags of commits newest first by creator date: the
+// tagger date of annotated tags, the committer date of the commit of the
+// others. A tag of a tag is peeled down to its commit; tags of trees or blobs
+// are left out, like LocalTags does.
+func sortedTags(repo *gogit.Repository) ([]goGitTag, error) {
+	refs, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	var tags []goGitTag
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		tag := goGitTag{TagInfo: TagInfo{Name: ref.Name().Short()}}
+		hash := ref.Hash()
+		kind, err := objectType(repo, hash)
+		if err != nil {
+			return err
+		}
+		if kind == plumbing.TagObject {
+			annotated, err := repo.TagObject(hash)
+			if err != nil {
+				return err
+			}
+			tag.Date = annotated.Tagger.When
+			for annotated.TargetType == plumbing.TagObject {
+				if annotated, err = repo.TagObject(annotated.Target); err != nil {
+					return err
+				}
+			}
+			hash, kind = annotated.Target, annotated.TargetType
+			tag.peeled = hash.String()
+		}
+		if kind != plumbing.CommitObject {
+			return nil
+		}
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			return err
+		}
+		if tag.peeled == "" {
+			tag.Date = commit.Committer.When
+		}
+		tag.Commit = commit.Hash.String()
+		tags = append(tags, tag)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortByDate(tags)
+	return tags, nil
+}
+
+// objectType returns the type of the object hash names.
+func objectType(repo *gogit.Repository, hash plumbing.Hash) (plumbing.ObjectType, error) {
+	obj, err := repo.Storer.EncodedObject(plumbing.AnyObject, hash)
+	if err != nil {
+		return plumbing.InvalidObject, err
+	}
+	return obj.Type(), nil
+}
+
+// sortByDate sorts tags newest first, breaking ties by name like git.
+func sortByDate(tags []goGitTag) {
+	sort.SliceStable(tags, func(i, j int) bool {
+		if !tags[i].Date.Equal(tags[j].Date) {
+			return tags[i].Date.After(tags[j].Date)
+		}
+		return tags[i].Name < tags[j].Name
+	})
+}
+
+// listRemote lists the references of origin.
+func listRemote(ctx context.Context, repo *gogit.Repository) ([]*plumbing.Reference, error) {
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return nil, err
+	}
+	return remote.ListContext(ctx, &gogit.ListOptions{PeelingOption: gogit.IgnorePeeled})
+}
+
+// remoteTags lists the tags of origin newest first, dated by the local tags
+// like git ls-remote --sort=-creatordate: the ones not fetched come last.
+func remoteTags(ctx context.Context, repo *gogit.Repository) ([]string, error) {
+	refs, err := listRemote(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	local, err := sortedTags(repo)
+	if err != nil {
+		return nil, err
+	}
+	dates := make(map[string]time.Time, len(local))
+	for _, tag := range local {
+		dates[tag.Name] = tag.Date
+	}
+	var tags []goGitTag
+	for _, ref := range refs {
+		if ref.Name().IsTag() {
+			name := ref.Name().Short()
+			tags = append(tags, goGitTag{TagInfo: TagInfo{Name: name, Date: dates[name]}})
+		}
+	}
+	sortByDate(tags)
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names, nil
+}
+
+// goGitOfflineTags returns the cached tags of the remote url, or the local
+// tags when it was never listed.
+func goGitOfflineTags(repo *gogit.Repository, url string) ([]string, error) {
+	if cached, ok := offline.Tags(url); ok && url != "" {
+		fmt.Fprintf(os.Stderr, "[!] Offline: using remote tags cached %s ago\n", time.Since(cached.UpdatedAt).Round(time.Minute))
+		return cached.Tags, nil
+	}
+	tags, err := sortedTags(repo)
+	if err != nil {
+		return nil, fmt.Errorf("error listing local tags: %w", goGitError(err))
+	}
+	fmt.Fprintln(os.Stderr, "[!] Offline: using local tags, they may miss tags pushed by others")
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names, nil
+}
@@ -0,0 +1,359 @@
+package git
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+)
+
+// commitQueue is a max-heap of commits by committer date, the order git
+// walks history in.
+type commitQueue []*object.Commit
+
+func (q commitQueue) Len() int            { return len(q) }
+func (q commitQueue) Less(i, j int) bool  { return q[i].Committer.When.After(q[j].Committer.When) }
+func (q commitQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x interface{}) { *q = append(*q, x.(*object.Commit)) }
+func (q *commitQueue) Pop() interface{} {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+// walkBetween returns the commits reachable from to but not from from
+// (from..to), newest first. Like git rev-list it walks both sides at once
+// by date and stops once only commits reachable from from are left, instead
+// of walking the whole history.
+func walkBetween(ctx context.Context, repo *gogit.Repository, from plumbing.Hash, to plumbing.Hash) ([]*object.Commit, error) {
+	hidden := map[plumbing.Hash]bool{}
+	seen := map[plumbing.Hash]bool{}
+	queue := &commitQueue{}
+	add := func(hash plumbing.Hash, hide bool) error {
+		if hide && !hidden[hash] {
+			hidden[hash] = true
+		} else if seen[hash] {
+			return nil
+		}
+		seen[hash] = true
+		c, err := repo.CommitObject(hash)
+		if err != nil {
+			// e.g. the parents cut from a shallow clone
+			return fmt.Errorf("%w: %v", errUnsupported, err)
+		}
+		heap.Push(queue, c)
+		return nil
+	}
+	if err := add(from, true); err != nil {
+		return nil, err
+	}
+	if err := add(to, false); err != nil {
+		return nil, err
+	}
+
+	var walked []*object.Commit
+	for queue.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, goGitError(err)
+		}
+		interesting := false
+		for _, c := range *queue {
+			if !hidden[c.Hash] {
+				interesting = true
+				break
+			}
+		}
+		if !interesting {
+			break
+		}
+		c := heap.Pop(queue).(*object.Commit)
+		hide := hidden[c.Hash]
+		if !hide {
+			walked = append(walked, c)
+		}
+		for _, parent := range c.ParentHashes {
+			if err := add(parent, hide); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// A commit dated after its children may have been hidden late
+	commits := walked[:0]
+	for _, c := range walked {
+		if !hidden[c.Hash] {
+			commits = append(commits, c)
+		}
+	}
+	return commits, nil
+}
+
+// mergeBase returns the best common ancestor of a and b.
+func mergeBase(repo *gogit.Repository, a plumbing.Hash, b plumbing.Hash) (*object.Commit, error) {
+	ca, err := repo.CommitObject(a)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errUnsupported, err)
+	}
+	cb, err := repo.CommitObject(b)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errUnsupported, err)
+	}
+	bases, err := ca.MergeBase(cb)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errUnsupported, err)
+	}
+	if len(bases) == 0 {
+		return nil, kindError(ErrOther, "fatal: refusing to merge unrelated histories")
+	}
+	return bases[0], nil
+}
+
+// treeOf returns the tree of the commit hash.
+func treeOf(repo *gogit.Repository, hash plumbing.Hash) (*object.Tree, error) {
+	c, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errUnsupported, err)
+	}
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errUnsupported, err)
+	}
+	return tree, nil
+}
+
+// diffStat returns the line counts of git diff --numstat from...to: the
+// changes of to since its merge base with from, renames detected.
+func diffStat(ctx context.Context, repo *gogit.Repository, from string, to string) ([]FileStat, error) {
+	fromHash, err := resolve(repo, from)
+	if err != nil {
+		return nil, err
+	}
+	toHash, err := resolve(repo, to)
+	if err != nil {
+		return nil, err
+	}
+	base, err := mergeBase(repo, fromHash, toHash)
+	if err != nil {
+		return nil, err
+	}
+	baseTree, err := treeOf(repo, base.Hash)
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := treeOf(repo, toHash)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTreeWithOptions(ctx, baseTree, toTree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, goGitError(err)
+	}
+	patch, err := changes.PatchContext(ctx)
+	if err != nil {
+		return nil, goGitError(err)
+	}
+
+	var stats []FileStat
+	// git orders the files by their path after the change
+	var order []string
+	for _, fp := range patch.FilePatches() {
+		before, after := fp.Files()
+		var stat FileStat
+		switch {
+		case before == nil:
+			stat.Path = after.Path()
+		case after == nil, before.Path() == after.Path():
+			stat.Path = before.Path()
+		default:
+			stat.Path = renamePath(before.Path(), after.Path())
+		}
+		if after != nil {
+			order = append(order, after.Path())
+		} else {
+			order = append(order, before.Path())
+		}
+		stat.Binary = fp.IsBinary()
+		for _, chunk := range fp.Chunks() {
+			lines := strings.Count(chunk.Content(), "\n")
+			if content := chunk.Content(); content != "" && !strings.HasSuffix(content, "\n") {
+				lines++
+			}
+			switch chunk.Type() {
+			case diff.Add:
+				stat.Added += lines
+			case diff.Delete:
+				stat.Deleted += lines
+			}
+		}
+		stats = append(stats, stat)
+	}
+	sort.Sort(byPath{stats, order})
+	return stats, nil
+}
+
+// byPath sorts file stats by the paths in order.
+type byPath struct {
+	stats []FileStat
+	order []string
+}
+
+func (s byPath) Len() int           { return len(s.stats) }
+func (s byPath) Less(i, j int) bool { return s.order[i] < s.order[j] }
+func (s byPath) Swap(i, j int) {
+	s.stats[i], s.stats[j] = s.stats[j], s.stats[i]
+	s.order[i], s.order[j] = s.order[j], s.order[i]
+}
+
+// renamePath writes a rename like git's --numstat: the common leading and
+// trailing directories outside braces, e.g. "cmd/{old.go => new.go}".
+func renamePath(from string, to string) string {
+	prefix := 0
+	for i := 0; i < len(from) && i < len(to) && from[i] == to[i]; i++ {
+		if from[i] == '/' {
+			prefix = i + 1
+		}
+	}
+	suffix := 0
+	for i := 1; i <= len(from)-prefix && i <= len(to)-prefix && from[len(from)-i] == to[len(to)-i]; i++ {
+		if from[len(from)-i] == '/' {
+			suffix = i
+		}
+	}
+	if prefix == 0 && suffix == 0 {
+		return from + " => " + to
+	}
+	return from[:prefix] + "{" + from[prefix:len(from)-suffix] + " => " + to[prefix:len(to)-suffix] + "}" + from[len(from)-suffix:]
+}
+
+// fileChange is what one side of a merge did to a path: the entry it
+// ends with, or none when it deleted it.
+type fileChange struct {
+	hash    plumbing.Hash
+	mode    filemode.FileMode
+	deleted bool
+}
+
+// mergePlan is the merge of source into HEAD worked out at the level of
+// paths: the changes of source to apply and the paths both sides changed.
+type mergePlan struct {
+	head, source plumbing.Hash
+	// upToDate: source is already in HEAD; fastForward: HEAD is in source
+	upToDate, fastForward bool
+	theirs                map[string]fileChange
+	conflicts             []string
+}
+
+// planMerge works out the merge of source into HEAD without touching the
+// repository. A path changed differently on both sides, or a file on one
+// side where the other has a directory, is a conflict: git may still merge
+// the contents of such files, go-git can't.
+func planMerge(ctx context.Context, repo *gogit.Repository, source string) (*mergePlan, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, goGitError(err)
+	}
+	sourceHash, err := resolve(repo, source)
+	if err != nil {
+		return nil, err
+	}
+	plan := &mergePlan{head: head.Hash(), source: sourceHash}
+	if walked, err := walkBetween(ctx, repo, plan.head, sourceHash); err != nil {
+		return nil, err
+	} else if len(walked) == 0 {
+		plan.upToDate = true
+		return plan, nil
+	}
+	if walked, err := walkBetween(ctx, repo, sourceHash, plan.head); err != nil {
+		return nil, err
+	} else if len(walked) == 0 {
+		plan.fastForward = true
+	}
+
+	base, err := mergeBase(repo, plan.head, sourceHash)
+	if err != nil {
+		return nil, err
+	}
+	ours, err := changedPaths(ctx, repo, base.Hash, plan.head)
+	if err != nil {
+		return nil, err
+	}
+	if plan.theirs, err = changedPaths(ctx, repo, base.Hash, sourceHash); err != nil {
+		return nil, err
+	}
+	for path, theirs := range plan.theirs {
+		if mine, ok := ours[path]; ok && mine != theirs {
+			plan.conflicts = append(plan.conflicts, path)
+		}
+	}
+	for path := range plan.theirs {
+		for dir := parentDir(path); dir != ""; dir = parentDir(dir) {
+			if _, ok := ours[dir]; ok {
+				plan.conflicts = append(plan.conflicts, dir)
+			}
+		}
+	}
+	for path := range ours {
+		for dir := parentDir(path); dir != ""; dir = parentDir(dir) {
+			if _, ok := plan.theirs[dir]; ok {
+				plan.conflicts = append(plan.conflicts, dir)
+			}
+		}
+	}
+	sort.Strings(plan.conflicts)
+	return plan, nil
+}
+
+// parentDir returns the directory of path, "" at the top.
+func parentDir(path string) string {
+	if i := strings.LastIndexByte(path, '/'); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// changedPaths returns the files changed between the commits from and to.
+func changedPaths(ctx context.Context, repo *gogit.Repository, from plumbing.Hash, to plumbing.Hash) (map[string]fileChange, error) {
+	fromTree, err := treeOf(repo, from)
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := treeOf(repo, to)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTreeWithOptions(ctx, fromTree, toTree, &object.DiffTreeOptions{})
+	if err != nil {
+		return nil, goGitError(err)
+	}
+	paths := make(map[string]fileChange, len(changes))
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			return nil, goGitError(err)
+		}
+		if action == merkletrie.Delete {
+			paths[change.From.Name] = fileChange{deleted: true}
+			continue
+		}
+		paths[change.To.Name] = fileChange{hash: change.To.TreeEntry.Hash, mode: change.To.TreeEntry.Mode}
+	}
+	return paths, nil
+}
+
+// errConflicts reports the paths a merge without git can't combine.
+func errConflicts(conflicts []string) error {
+	lines := make([]string, len(conflicts))
+	for i, path := range conflicts {
+		lines[i] = "CONFLICT (content): Merge conflict in " + path
+	}
+	return kindError(ErrConflict, strings.Join(lines, "\n")+
+		"\nAutomatic merge failed: merging files changed on both sides needs git, which is not installed")
+}
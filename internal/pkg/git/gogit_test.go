@@ -0,0 +1,297 @@
+package git_test
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/sandbox"
+)
+
+// clientSpec is the sandbox both clients are compared on: the demo branches
+// and tags, a conflict, a branch ahead of origin and one behind it.
+var clientSpec = sandbox.Spec{
+	Branches:  []string{"develop", "qc", "staging", "feature/ABC-123-login-form"},
+	Tags:      []string{"qc-v1.0.0", "stg-v1.0.0", "v1.0.0", "qc-v1.0.1"},
+	Conflicts: [][2]string{{"develop", "qc"}},
+	Diverged:  []string{"staging"},
+	Remote:    true,
+}
+
+// TestClientReads asks GoGitClient, without git to fall back on, and
+// ExecClient the same questions about the same repository: the answers and
+// the kinds of the failures must match.
+func TestClientReads(t *testing.T) {
+	repo := buildClientRepo(t)
+	chdir(t, repo.Dir)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		read func(c git.GitClient) (any, error)
+	}{
+		{"IsRepo", func(c git.GitClient) (any, error) { return c.IsRepo(ctx) }},
+		{"TopLevel", func(c git.GitClient) (any, error) { return c.TopLevel(ctx) }},
+		{"Head", func(c git.GitClient) (any, error) { return c.Head(ctx) }},
+		{"HeadCommit", func(c git.GitClient) (any, error) { return c.HeadCommit(ctx) }},
+		{"Config", func(c git.GitClient) (any, error) {
+			return c.Config(ctx, "user.name") + "|" + c.Config(ctx, "branch.main.remote") + "|" + c.Config(ctx, "aio.unset"), nil
+		}},
+		{"ProjectID", func(c git.GitClient) (any, error) { return c.ProjectID(ctx) }},
+		{"IsClean", func(c git.GitClient) (any, error) { return c.IsClean(ctx) }},
+		{"LocalBranches", func(c git.GitClient) (any, error) { return c.LocalBranches(ctx) }},
+		{"RemoteBranches", func(c git.GitClient) (any, error) { return c.RemoteBranches(ctx) }},
+		{"BranchExists", func(c git.GitClient) (any, error) {
+			var found []bool
+			for _, branch := range []string{"develop", "gone-on-origin", "missing"} {
+				ok, err := c.BranchExists(ctx, branch)
+				if err != nil {
+					return nil, err
+				}
+				found = append(found, ok, c.RemoteBranchExists(ctx, branch))
+			}
+			return found, nil
+		}},
+		{"GoneBranches", func(c git.GitClient) (any, error) { return c.GoneBranches(ctx) }},
+		{"IsAncestor", func(c git.GitClient) (any, error) {
+			return []bool{c.IsAncestor(ctx, "main", "develop"), c.IsAncestor(ctx, "develop", "main"), c.IsAncestor(ctx, "v1.0.0", "qc")}, nil
+		}},
+		{"CommitsBetween", func(c git.GitClient) (any, error) { return c.CommitsBetween(ctx, "v1.0.0", "qc") }},
+		{"CommitsBetween unknown", func(c git.GitClient) (any, error) { return c.CommitsBetween(ctx, "main", "no-such-branch") }},
+		{"RecentCommits", func(c git.GitClient) (any, error) { return c.RecentCommits(ctx, "develop", 4) }},
+		{"DiffStat", func(c git.GitClient) (any, error) { return c.DiffStat(ctx, "main", "qc") }},
+		{"CheckMergeConflicts", func(c git.GitClient) (any, error) {
+			var conflicts []bool
+			for _, source := range []string{"develop", "qc", "staging"} {
+				conflict, err := c.CheckMergeConflicts(ctx, source)
+				if err != nil {
+					return nil, err
+				}
+				conflicts = append(conflicts, conflict)
+			}
+			return conflicts, nil
+		}},
+		{"MergeInProgress", func(c git.GitClient) (any, error) { return c.MergeInProgress(ctx), nil }},
+		{"LocalTags", func(c git.GitClient) (any, error) {
+			tags, err := c.LocalTags(ctx)
+			// git gives the dates in the local time zone, go-git in the tagger's
+			var listed []string
+			for _, tag := range tags {
+				listed = append(listed, fmt.Sprint(tag.Name, " ", tag.Commit, " ", tag.Date.Unix()))
+			}
+			return listed, err
+		}},
+		{"RemoteTags", func(c git.GitClient) (any, error) { return c.RemoteTags(ctx) }},
+		{"TagExists", func(c git.GitClient) (any, error) {
+			var found []bool
+			for _, tag := range []string{"v1.0.0", "nested", "v9.9.9"} {
+				ok, err := c.TagExists(ctx, tag)
+				if err != nil {
+					return nil, err
+				}
+				found = append(found, ok)
+			}
+			return found, nil
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, wantErr := tt.read(git.ExecClient{})
+			got, err := tt.read(&git.GoGitClient{})
+			if fmt.Sprint(got) != fmt.Sprint(want) || (err == nil) != (wantErr == nil) {
+				t.Errorf("go-git: %v, %v\ngit:    %v, %v", got, err, want, wantErr)
+			}
+			if wantErr != nil && git.KindOf(err) != git.KindOf(wantErr) {
+				t.Errorf("go-git failed with %s, git with %s", git.KindOf(err), git.KindOf(wantErr))
+			}
+		})
+	}
+}
+
+// The names go-git accepts but git doesn't are asked to git when it is there.
+func TestClientValidBranchNames(t *testing.T) {
+	repo := buildClientRepo(t)
+	chdir(t, repo.Dir)
+	ctx := context.Background()
+	client := &git.GoGitClient{Fallback: git.ExecClient{}}
+	for _, name := range []string{"feature/ABC-1", "release-1.0", "HEAD", "-x", "a..b", "a b", "a.lock/b", "x@{1}", "a/", "a//b", "@"} {
+		if got, want := client.IsValidBranchName(ctx, name), git.IsValidBranchName(ctx, name); got != want {
+			t.Errorf("IsValidBranchName(%q) = %v, git says %v", name, got, want)
+		}
+	}
+}
+
+// TestClientWrites runs each change with GoGitClient alone on one copy of a
+// sandbox and with ExecClient on another: the refs, HEAD and work trees must
+// end up the same.
+func TestClientWrites(t *testing.T) {
+	if testing.Short() {
+		t.Skip("each change runs on two copies of a sandbox")
+	}
+	ctx := context.Background()
+	template := buildClientRepo(t)
+	tests := []struct {
+		name  string
+		write func(c git.GitClient) (any, error)
+	}{
+		{"Checkout", func(c git.GitClient) (any, error) { return nil, c.Checkout(ctx, "develop") }},
+		{"Checkout missing", func(c git.GitClient) (any, error) { return nil, c.Checkout(ctx, "no-such-branch") }},
+		{"CheckoutTracking", func(c git.GitClient) (any, error) { return nil, c.CheckoutTracking(ctx, "only-on-origin") }},
+		{"CreateBranch", func(c git.GitClient) (any, error) { return nil, c.CreateBranch(ctx, "feature/new", "develop") }},
+		{"CreateBranch exists", func(c git.GitClient) (any, error) { return nil, c.CreateBranch(ctx, "qc", "") }},
+		{"RenameBranch", func(c git.GitClient) (any, error) { return nil, c.RenameBranch(ctx, "develop", "dev") }},
+		{"DeleteBranch", func(c git.GitClient) (any, error) { return nil, c.DeleteBranch(ctx, "qc", true) }},
+		{"DeleteBranch merged upstream", func(c git.GitClient) (any, error) { return nil, c.DeleteBranch(ctx, "qc", false) }},
+		{"DeleteBranch unmerged", func(c git.GitClient) (any, error) { return nil, c.DeleteBranch(ctx, "staging", false) }},
+		{"DeleteRemoteBranch", func(c git.GitClient) (any, error) { return nil, c.DeleteRemoteBranch(ctx, "qc") }},
+		{"FetchBranch", func(c git.GitClient) (any, error) { return nil, c.FetchBranch(ctx, "only-on-origin") }},
+		{"PruneRemote dry run", func(c git.GitClient) (any, error) { return c.PruneRemote(ctx, true) }},
+		{"PruneRemote", func(c git.GitClient) (any, error) { return c.PruneRemote(ctx, false) }},
+		{"Merge fast-forward", func(c git.GitClient) (any, error) { return nil, c.Merge(ctx, "develop", false) }},
+		{"ResetHard", func(c git.GitClient) (any, error) { return nil, c.ResetHard(ctx, "qc") }},
+		{"CreateTag and push", func(c git.GitClient) (any, error) {
+			if err := c.CreateTag(ctx, "v2.0.0", "Release v2.0.0"); err != nil {
+				return nil, err
+			}
+			return nil, c.PushTag(ctx, "v2.0.0")
+		}},
+		{"CreateTag exists", func(c git.GitClient) (any, error) { return nil, c.CreateTag(ctx, "v1.0.0", "Again") }},
+		{"FetchTags", func(c git.GitClient) (any, error) { return nil, c.FetchTags(ctx) }},
+		{"DeleteLocalTags", func(c git.GitClient) (any, error) { return nil, c.DeleteLocalTags(ctx, []string{"v1.0.0", "nested"}) }},
+		{"DeleteRemoteTags", func(c git.GitClient) (any, error) {
+			return nil, c.DeleteRemoteTags(ctx, []string{"qc-v1.0.0", "stg-v1.0.0"})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var states [2]string
+			var results [2]any
+			var errs [2]error
+			for i, client := range []git.GitClient{git.ExecClient{}, &git.GoGitClient{}} {
+				repo := copyClientRepo(t, template)
+				chdir(t, repo.Dir)
+				results[i], errs[i] = tt.write(client)
+				states[i] = repoState(t, repo)
+			}
+			if fmt.Sprint(results[1]) != fmt.Sprint(results[0]) || (errs[1] == nil) != (errs[0] == nil) {
+				t.Errorf("go-git: %v, %v\ngit:    %v, %v", results[1], errs[1], results[0], errs[0])
+			}
+			if errs[0] != nil && git.KindOf(errs[1]) != git.KindOf(errs[0]) {
+				t.Errorf("go-git failed with %s, git with %s", git.KindOf(errs[1]), git.KindOf(errs[0]))
+			}
+			if states[1] != states[0] {
+				t.Errorf("go-git left:\n%s\n\ngit left:\n%s", states[1], states[0])
+			}
+		})
+	}
+}
+
+// buildClientRepo builds the clientSpec sandbox, plus what the spec can't
+// describe: tags of a tag and of a tree, a lightweight tag, a branch only on
+// origin and one deleted there, and a tag only on origin.
+func buildClientRepo(t *testing.T) *sandbox.Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(git.BackendEnv, "")
+	repo, err := sandbox.Build(context.Background(), t.TempDir(), clientSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := func(dir string, date string, args ...string) {
+		t.Helper()
+		cmd := git.CommandIn(context.Background(), dir, args...)
+		cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+date, "GIT_AUTHOR_DATE="+date,
+			"GIT_COMMITTER_NAME=Sandbox", "GIT_COMMITTER_EMAIL=sandbox@example.com")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+	run(repo.Dir, "2024-02-01T09:00:00Z", "-c", "advice.nestedTag=false", "tag", "-a", "nested", "-m", "Tag of a tag", "v1.0.0")
+	run(repo.Dir, "2024-02-02T09:00:00Z", "tag", "-a", "tree", "-m", "Tag of a tree", "HEAD^{tree}")
+	run(repo.Dir, "2024-02-03T09:00:00Z", "tag", "light-tree", "HEAD^{tree}")
+	run(repo.Dir, "2024-02-04T09:00:00Z", "tag", "light", "develop")
+	run(repo.Origin, "2024-02-05T09:00:00Z", "branch", "only-on-origin", "qc")
+	run(repo.Origin, "2024-02-06T09:00:00Z", "tag", "-a", "v1.1.0", "-m", "Release v1.1.0", "qc")
+	run(repo.Dir, "2024-02-07T09:00:00Z", "push", "-q", "origin", "--delete", "feature/ABC-123-login-form")
+	run(repo.Dir, "2024-02-08T09:00:00Z", "branch", "gone-on-origin", "main")
+	run(repo.Dir, "2024-02-08T09:00:00Z", "push", "-q", "-u", "origin", "gone-on-origin")
+	run(repo.Origin, "2024-02-09T09:00:00Z", "branch", "-D", "gone-on-origin")
+	run(repo.Dir, "2024-02-10T09:00:00Z", "fetch", "-q", "origin", "only-on-origin")
+	run(repo.Dir, "2024-02-10T09:00:00Z", "update-ref", "-d", "refs/remotes/origin/only-on-origin")
+	return repo
+}
+
+// copyClientRepo copies the work tree and origin of repo to a new directory,
+// pointing the copy of the work tree to the copy of origin.
+func copyClientRepo(t *testing.T, repo *sandbox.Repo) *sandbox.Repo {
+	t.Helper()
+	dir := t.TempDir()
+	copied := &sandbox.Repo{Dir: filepath.Join(dir, "repo"), Origin: filepath.Join(dir, "origin.git")}
+	for from, to := range map[string]string{repo.Dir: copied.Dir, repo.Origin: copied.Origin} {
+		err := filepath.WalkDir(from, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(from, path)
+			if d.IsDir() {
+				return os.MkdirAll(filepath.Join(to, rel), 0755)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(filepath.Join(to, rel), data, 0644)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := git.CommandIn(context.Background(), copied.Dir, "remote", "set-url", "origin", copied.Origin).Run(); err != nil {
+		t.Fatal(err)
+	}
+	return copied
+}
+
+// repoState lists what a client may change: the refs of the work tree and
+// origin (tag objects by the commit they tag, as their own hash holds the
+// date), HEAD and the status of the work tree.
+func repoState(t *testing.T, repo *sandbox.Repo) string {
+	t.Helper()
+	var state []string
+	for _, dir := range []string{repo.Dir, repo.Origin} {
+		output, err := git.CommandIn(context.Background(), dir, "for-each-ref",
+			"--format=%(refname) %(objecttype) %(if)%(*objectname)%(then)%(*objectname)%(else)%(objectname)%(end)").Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		state = append(state, strings.TrimSpace(string(output)))
+	}
+	for _, args := range [][]string{{"symbolic-ref", "-q", "HEAD"}, {"rev-parse", "HEAD"}, {"status", "--porcelain"}} {
+		output, _ := git.CommandIn(context.Background(), repo.Dir, args...).Output()
+		state = append(state, strings.TrimSpace(string(output)))
+	}
+	return strings.Join(state, "\n")
+}
+
+// chdir moves to dir until the end of the test: the clients work on the
+// repository of the current directory.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
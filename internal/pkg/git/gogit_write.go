@@ -0,0 +1,314 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// The writes below run when git is not installed. They mirror what the git
+// commands of the package functions do, failing with the same kinds.
+
+// checkout runs a checkout in the working tree of repo.
+func checkout(repo *gogit.Repository, opts *gogit.CheckoutOptions) error {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return goGitError(err)
+	}
+	return goGitError(wt.Checkout(opts))
+}
+
+// createBranch creates branch at base and checks it out, like git checkout
+// -b branch base.
+func createBranch(repo *gogit.Repository, branch string, base string) error {
+	name := plumbing.NewBranchReferenceName(branch)
+	if hasRef(repo, name) {
+		return kindError(ErrExists, fmt.Sprintf("fatal: a branch named '%s' already exists", branch))
+	}
+	hash, err := resolve(repo, base)
+	if err != nil {
+		return err
+	}
+	return checkout(repo, &gogit.CheckoutOptions{Branch: name, Hash: hash, Create: true})
+}
+
+// renameBranch renames the branch oldName, its upstream config and HEAD
+// when it is the current branch.
+func renameBranch(repo *gogit.Repository, oldName string, newName string) error {
+	oldRef, err := repo.Storer.Reference(plumbing.NewBranchReferenceName(oldName))
+	if err != nil {
+		return goGitError(err)
+	}
+	newRef := plumbing.NewBranchReferenceName(newName)
+	if hasRef(repo, newRef) {
+		return kindError(ErrExists, fmt.Sprintf("fatal: a branch named '%s' already exists", newName))
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(newRef, oldRef.Hash())); err != nil {
+		return goGitError(err)
+	}
+	if head, err := repo.Storer.Reference(plumbing.HEAD); err == nil && head.Target() == oldRef.Name() {
+		if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, newRef)); err != nil {
+			return goGitError(err)
+		}
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return goGitError(err)
+	}
+	if b, ok := cfg.Branches[oldName]; ok {
+		delete(cfg.Branches, oldName)
+		b.Name = newName
+		cfg.Branches[newName] = b
+		if err := repo.SetConfig(cfg); err != nil {
+			return goGitError(err)
+		}
+	}
+	return goGitError(repo.Storer.RemoveReference(oldRef.Name()))
+}
+
+// deleteBranch deletes a local branch and its config. Without force it must
+// be merged into HEAD.
+func deleteBranch(repo *gogit.Repository, branch string, force bool) error {
+	name := plumbing.NewBranchReferenceName(branch)
+	ref, err := repo.Storer.Reference(name)
+	if err != nil {
+		return kindError(ErrNotFound, fmt.Sprintf("error: branch '%s' not found", branch))
+	}
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err == nil && head.Target() == name {
+		return kindError(ErrOther, fmt.Sprintf("error: cannot delete branch '%s' used by worktree", branch))
+	}
+	if !force {
+		// Like git, a branch is merged when its upstream has it, else HEAD
+		merged, err := repo.Head()
+		if err != nil {
+			return goGitError(err)
+		}
+		if cfg, err := repo.Config(); err == nil {
+			if b, ok := cfg.Branches[branch]; ok && b.Remote != "" && b.Remote != "." && b.Merge.IsBranch() {
+				if upstream, err := repo.Storer.Reference(plumbing.NewRemoteReferenceName(b.Remote, b.Merge.Short())); err == nil {
+					merged = upstream
+				}
+			}
+		}
+		walked, err := walkBetween(context.Background(), repo, merged.Hash(), ref.Hash())
+		if err != nil {
+			return goGitError(err)
+		}
+		if len(walked) > 0 {
+			return kindError(ErrOther, fmt.Sprintf("error: the branch '%s' is not fully merged", branch))
+		}
+	}
+	if err := repo.Storer.RemoveReference(name); err != nil {
+		return goGitError(err)
+	}
+	if err := repo.DeleteBranch(branch); err != nil && !errors.Is(err, gogit.ErrBranchNotFound) {
+		return goGitError(err)
+	}
+	return nil
+}
+
+// pull fast-forwards the current branch to its upstream. go-git doesn't
+// merge diverged branches, which fails like git pull --ff-only.
+func pull(ctx context.Context, repo *gogit.Repository) error {
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return goGitError(err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return goGitError(err)
+	}
+	upstream, ok := cfg.Branches[head.Target().Short()]
+	if head.Type() != plumbing.SymbolicReference || !ok || upstream.Remote == "" || upstream.Merge == "" {
+		return kindError(ErrOther, "There is no tracking information for the current branch.")
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return goGitError(err)
+	}
+	err = upToDate(wt.PullContext(ctx, &gogit.PullOptions{RemoteName: upstream.Remote, ReferenceName: upstream.Merge}))
+	if errors.Is(err, gogit.ErrNonFastForwardUpdate) {
+		return kindError(ErrOther, "fatal: Not possible to fast-forward, aborting.")
+	}
+	return goGitError(err)
+}
+
+// pruneRemote removes the origin/* branches origin no longer has, like git
+// remote prune origin, and returns them.
+func pruneRemote(ctx context.Context, repo *gogit.Repository, dryRun bool) ([]string, error) {
+	refs, err := listRemote(ctx, repo)
+	if err != nil {
+		return nil, goGitError(err)
+	}
+	onOrigin := map[string]bool{}
+	for _, ref := range refs {
+		if ref.Name().IsBranch() {
+			onOrigin[ref.Name().Short()] = true
+		}
+	}
+	tracking, err := refNames(repo, plumbing.ReferenceName.IsRemote)
+	if err != nil {
+		return nil, goGitError(err)
+	}
+	var pruned []string
+	for _, name := range tracking {
+		branch, ok := strings.CutPrefix(name, "origin/")
+		if !ok || onOrigin[branch] {
+			continue
+		}
+		if !dryRun {
+			if err := repo.Storer.RemoveReference(plumbing.NewRemoteReferenceName("origin", branch)); err != nil {
+				return pruned, goGitError(err)
+			}
+		}
+		pruned = append(pruned, name)
+	}
+	return pruned, nil
+}
+
+// merge merges source into the current branch: a fast-forward when
+// possible, else a merge commit of the files changed on one side only.
+func merge(ctx context.Context, repo *gogit.Repository, source string, noFF bool) error {
+	plan, err := planMerge(ctx, repo, source)
+	if errors.Is(err, errUnsupported) {
+		return kindError(ErrNotInstalled, fmt.Sprintf("merging needs git, which is not installed (%v)", err))
+	}
+	if err != nil {
+		return err
+	}
+	switch {
+	case plan.upToDate:
+		return nil
+	case plan.fastForward && !noFF:
+		return reset(repo, plan.source.String(), gogit.MergeReset)
+	case len(plan.conflicts) > 0:
+		return errConflicts(plan.conflicts)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return goGitError(err)
+	}
+	for file, change := range plan.theirs {
+		if err := ctx.Err(); err != nil {
+			// Leave the tree as it was
+			reset(repo, plan.head.String(), gogit.HardReset)
+			return goGitError(err)
+		}
+		if change.deleted {
+			_, err = wt.Remove(file)
+		} else if err = writeFile(repo, file, change); err == nil {
+			_, err = wt.Add(file)
+		}
+		if err != nil {
+			reset(repo, plan.head.String(), gogit.HardReset)
+			return goGitError(err)
+		}
+	}
+	_, err = wt.Commit(mergeMessage(repo, source), &gogit.CommitOptions{Parents: []plumbing.Hash{plan.head, plan.source}})
+	if err != nil {
+		reset(repo, plan.head.String(), gogit.HardReset)
+		return goGitError(err)
+	}
+	return nil
+}
+
+// writeFile writes the entry change of a merged file to the working tree.
+func writeFile(repo *gogit.Repository, file string, change fileChange) error {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	blob, err := repo.BlobObject(change.hash)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	fs := wt.Filesystem
+	if err := fs.MkdirAll(path.Dir(file), 0755); err != nil {
+		return err
+	}
+	fs.Remove(file)
+	if change.mode == filemode.Symlink {
+		target, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		return fs.Symlink(string(target), file)
+	}
+	perm := os.FileMode(0644)
+	if change.mode == filemode.Executable {
+		perm = 0755
+	}
+	f, err := fs.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, reader); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// mergeMessage returns the message git gives the merge of source into the
+// current branch, e.g. "Merge branch 'feature' into develop".
+func mergeMessage(repo *gogit.Repository, source string) string {
+	message := fmt.Sprintf("Merge commit '%s'", source)
+	if hasRef(repo, plumbing.NewBranchReferenceName(source)) {
+		message = fmt.Sprintf("Merge branch '%s'", source)
+	} else if hasRef(repo, plumbing.ReferenceName("refs/remotes/"+source)) {
+		message = fmt.Sprintf("Merge remote-tracking branch '%s'", source)
+	}
+	// git leaves out the main branches (merge.suppressDest)
+	if head, err := repo.Storer.Reference(plumbing.HEAD); err == nil {
+		if into := head.Target().Short(); into != "main" && into != "master" {
+			message += " into " + into
+		}
+	}
+	return message
+}
+
+// abortMerge drops a merge git left in progress and restores HEAD.
+func abortMerge(repo *gogit.Repository) error {
+	if !hasRef(repo, "MERGE_HEAD") {
+		return kindError(ErrOther, "fatal: There is no merge to abort (MERGE_HEAD missing).")
+	}
+	if err := reset(repo, "HEAD", gogit.HardReset); err != nil {
+		return err
+	}
+	if storage, ok := repo.Storer.(*filesystem.Storage); ok {
+		fs := storage.Filesystem()
+		for _, name := range []string{"MERGE_HEAD", "MERGE_MSG", "MERGE_MODE", "AUTO_MERGE"} {
+			fs.Remove(name)
+		}
+	}
+	return nil
+}
+
+// reset resets the current branch, index and working tree to ref.
+func reset(repo *gogit.Repository, ref string, mode gogit.ResetMode) error {
+	hash, err := resolve(repo, ref)
+	if err != nil {
+		return err
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return goGitError(err)
+	}
+	return goGitError(wt.Reset(&gogit.ResetOptions{Commit: hash, Mode: mode}))
+}
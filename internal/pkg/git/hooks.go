@@ -0,0 +1,124 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Markers around the part of a hook written by 'aio git hooks', so the rest
+// of an existing hook is left alone.
+const (
+	hookBegin = "# >>> aio hooks >>>"
+	hookEnd   = "# <<< aio hooks <<<"
+)
+
+// HooksDirIn returns the hooks directory of the repository at dir, honouring core.hooksPath.
+func HooksDirIn(ctx context.Context, dir string) (string, error) {
+	output, err := command(ctx, "git", "-C", dir, "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("error finding the hooks directory: %w", err)
+	}
+	hooks := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(hooks) {
+		hooks = filepath.Join(dir, hooks)
+	}
+	return hooks, nil
+}
+
+// HookBlockIn returns the aio part of the hook called name, "" when there is none.
+func HookBlockIn(ctx context.Context, dir string, name string) (string, error) {
+	file, err := hookFile(ctx, dir, name)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	_, block, _ := cutHookBlock(string(data))
+	return block, nil
+}
+
+// InstallHookIn writes body as the aio part of the hook called name,
+// replacing a previous one. A hook written by something else is kept and
+// body is appended to it, as long as it is a shell script. Returns the hook file.
+func InstallHookIn(ctx context.Context, dir string, name string, body string) (string, error) {
+	file, err := hookFile(ctx, dir, name)
+	if err != nil {
+		return "", err
+	}
+	content := "#!/bin/sh\n"
+	if data, err := os.ReadFile(file); err == nil {
+		content = string(data)
+		if firstLine, _, _ := strings.Cut(content, "\n"); !strings.HasSuffix(firstLine, "sh") {
+			return "", fmt.Errorf("%s is not a shell script (%s), add 'aio' to it by hand", file, firstLine)
+		}
+		before, _, after := cutHookBlock(content)
+		content = strings.TrimRight(before, "\n") + "\n" + after
+	}
+	content = strings.TrimRight(content, "\n") + "\n\n" + hookBegin + "\n" + strings.TrimSpace(body) + "\n" + hookEnd + "\n"
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(file), err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0755); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	// WriteFile keeps the mode of an existing file
+	return file, os.Chmod(file, 0755)
+}
+
+// UninstallHookIn removes the aio part of the hook called name, and the hook
+// itself when nothing else is left. Returns false when there was none.
+func UninstallHookIn(ctx context.Context, dir string, name string) (bool, error) {
+	file, err := hookFile(ctx, dir, name)
+	if err != nil {
+		return false, err
+	}
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	before, block, after := cutHookBlock(string(data))
+	if block == "" {
+		return false, nil
+	}
+	rest := strings.TrimSpace(before + after)
+	if rest == "" || rest == "#!/bin/sh" {
+		return true, os.Remove(file)
+	}
+	return true, os.WriteFile(file, []byte(strings.TrimRight(before, "\n")+"\n"+after), 0755)
+}
+
+func hookFile(ctx context.Context, dir string, name string) (string, error) {
+	hooks, err := HooksDirIn(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(hooks, name), nil
+}
+
+// cutHookBlock splits a hook into what is before the aio block, the block's
+// body and what follows it.
+func cutHookBlock(content string) (string, string, string) {
+	start := strings.Index(content, hookBegin)
+	if start < 0 {
+		return content, "", ""
+	}
+	end := strings.Index(content[start:], hookEnd)
+	if end < 0 {
+		return content, "", ""
+	}
+	end += start
+	block := strings.TrimSpace(content[start+len(hookBegin) : end])
+	return content[:start], block, strings.TrimLeft(content[end+len(hookEnd):], "\n")
+}
@@ -0,0 +1,185 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// lfsPointerVersion starts every Git LFS pointer file.
+const lfsPointerVersion = "version https://git-lfs.github.com/spec/v1"
+
+// LFSFile is a file stored in Git LFS, as recorded by its pointer in the index.
+type LFSFile struct {
+	Path  string
+	OID   string // sha256 of the content
+	Size  int64  // size of the content
+	Local bool   // the content is in the local LFS store
+}
+
+// LFSInstalled reports whether the git-lfs extension is installed.
+func LFSInstalled(ctx context.Context) bool {
+	return command(ctx, "git", "lfs", "version").Run() == nil
+}
+
+// LFSVersion returns the git-lfs version line, empty when it isn't installed.
+func LFSVersion(ctx context.Context) string {
+	output, err := command(ctx, "git", "lfs", "version").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// UsesLFSIn reports whether the repository at dir tracks files with Git LFS,
+// i.e. a committed .gitattributes sets filter=lfs. Works without git-lfs.
+func UsesLFSIn(ctx context.Context, dir string) bool {
+	return len(LFSPatternsIn(ctx, dir)) > 0
+}
+
+// LFSPatternsIn returns the patterns the committed .gitattributes files of the
+// repository at dir route through Git LFS.
+func LFSPatternsIn(ctx context.Context, dir string) []string {
+	output, err := command(ctx, "git", "-C", dir, "grep", "--cached", "-h", "-e", "filter=lfs", "--", ".gitattributes", "*/.gitattributes").Output()
+	if err != nil {
+		return nil
+	}
+	var patterns []string
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 1 && !strings.HasPrefix(fields[0], "#") {
+			patterns = appendUnique(patterns, fields[0])
+		}
+	}
+	return patterns
+}
+
+// LFSFilesIn lists the LFS files of the repository at dir from the pointers in
+// its index, so it works whether or not git-lfs is installed.
+func LFSFilesIn(ctx context.Context, dir string) ([]LFSFile, error) {
+	gitDir, err := gitDirIn(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	// -z separates path and line with NUL, pointers are tiny text files
+	output, err := command(ctx, "git", "-C", dir, "grep", "--cached", "-I", "-z", "-E",
+		"-e", "^"+lfsPointerVersion, "-e", "^oid sha256:", "-e", "^size [0-9]+").Output()
+	if err != nil {
+		// git grep exits with 1 when nothing matched
+		if code, ok := exitCode(err); ok && code == 1 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading LFS pointers: %w", err)
+	}
+
+	byPath := map[string]*LFSFile{}
+	var order []string
+	pointer := map[string]bool{}
+	for _, line := range strings.Split(string(output), "\n") {
+		path, text, ok := strings.Cut(line, "\x00")
+		if !ok {
+			continue
+		}
+		f := byPath[path]
+		if f == nil {
+			f = &LFSFile{Path: path}
+			byPath[path] = f
+			order = append(order, path)
+		}
+		switch {
+		case text == lfsPointerVersion:
+			pointer[path] = true
+		case strings.HasPrefix(text, "oid sha256:"):
+			f.OID = strings.TrimPrefix(text, "oid sha256:")
+		case strings.HasPrefix(text, "size "):
+			f.Size, _ = strconv.ParseInt(strings.TrimPrefix(text, "size "), 10, 64)
+		}
+	}
+
+	var files []LFSFile
+	for _, path := range order {
+		f := byPath[path]
+		if !pointer[path] || len(f.OID) < 4 {
+			continue
+		}
+		_, err := os.Stat(filepath.Join(gitDir, "lfs", "objects", f.OID[:2], f.OID[2:4], f.OID))
+		f.Local = err == nil
+		files = append(files, *f)
+	}
+	return files, nil
+}
+
+// LFSPointerFilesIn returns the LFS files of the repository at dir whose
+// working tree copy is still the pointer, i.e. the content was never pulled.
+func LFSPointerFilesIn(dir string, files []LFSFile) []string {
+	var pointers []string
+	for _, f := range files {
+		data := make([]byte, len(lfsPointerVersion))
+		file, err := os.Open(filepath.Join(dir, f.Path))
+		if err != nil {
+			continue
+		}
+		n, _ := file.Read(data)
+		file.Close()
+		if bytes.Equal(data[:n], []byte(lfsPointerVersion)) {
+			pointers = append(pointers, f.Path)
+		}
+	}
+	return pointers
+}
+
+// LFSStorageIn returns the disk space used by the local LFS store of the
+// repository at dir.
+func LFSStorageIn(ctx context.Context, dir string) (int64, error) {
+	gitDir, err := gitDirIn(ctx, dir)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	err = filepath.WalkDir(filepath.Join(gitDir, "lfs"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error measuring LFS storage: %w", err)
+	}
+	return total, nil
+}
+
+// LFSPrune deletes local LFS objects that are no longer referenced by recent
+// commits or unpushed work (git lfs prune), returning its output.
+func LFSPrune(ctx context.Context, dryRun bool) (string, error) {
+	args := []string{"lfs", "prune", "--verbose"}
+	if dryRun {
+		args = append(args, "--dry-run")
+	}
+	output, err := command(ctx, "git", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error running git lfs prune: %w\n%s", err, string(output))
+	}
+	return string(output), nil
+}
+
+// gitDirIn returns the absolute .git directory of the repository at dir.
+func gitDirIn(ctx context.Context, dir string) (string, error) {
+	output, err := command(ctx, "git", "-C", dir, "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %s", dir)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,223 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// WIPSubject is the subject of the commit carrying uncommitted changes in a
+// patch file. Importing turns it back into uncommitted changes.
+const WIPSubject = "WIP: uncommitted changes (aio git patch)"
+
+// patchRef temporarily points at the exported commits while a bundle is created.
+const patchRef = "refs/aio/patch"
+
+// PatchRange returns the rev-list arguments selecting the commits of tip not
+// on base, or not on any remote when base is empty.
+func PatchRange(base string, tip string) []string {
+	if base != "" {
+		return []string{base + ".." + tip}
+	}
+	return []string{tip, "--not", "--remotes"}
+}
+
+// CountCommits returns the number of commits selected by rev-list arguments.
+func CountCommits(ctx context.Context, revs []string) (int, error) {
+	args := append([]string{"rev-list", "--count"}, revs...)
+	output, err := command(ctx, "git", args...).Output()
+	if err != nil {
+		return 0, fmt.Errorf("error counting commits %s: %w", strings.Join(revs, " "), err)
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+// WIPCommit records the working tree (staged, unstaged and untracked files) as
+// a commit on top of HEAD, without touching the index, the working tree or any
+// branch. Returns "" when there are no changes.
+func WIPCommit(ctx context.Context) (string, error) {
+	index, err := os.CreateTemp("", "aio-index-*")
+	if err != nil {
+		return "", err
+	}
+	index.Close()
+	defer os.Remove(index.Name())
+
+	env := append(os.Environ(), "GIT_INDEX_FILE="+index.Name())
+	for _, args := range [][]string{{"read-tree", "HEAD"}, {"add", "-A"}} {
+		cmd := command(ctx, "git", args...)
+		cmd.Env = env
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("error running git %s: %w\n%s", args[0], err, string(output))
+		}
+	}
+	cmd := command(ctx, "git", "write-tree")
+	cmd.Env = env
+	tree, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error writing the working tree: %w", err)
+	}
+	headTree, err := command(ctx, "git", "rev-parse", "HEAD^{tree}").Output()
+	if err != nil {
+		return "", fmt.Errorf("error reading HEAD: %w", err)
+	}
+	if bytes.Equal(tree, headTree) {
+		return "", nil
+	}
+
+	output, err := command(ctx, "git", "commit-tree", strings.TrimSpace(string(tree)), "-p", "HEAD", "-m", WIPSubject).Output()
+	if err != nil {
+		return "", fmt.Errorf("error creating the WIP commit: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// FormatPatch writes the commits selected by revs to file as a mailbox
+// (git format-patch), which 'git am' applies.
+func FormatPatch(ctx context.Context, file string, revs []string) error {
+	// --root keeps a lone commit (no remotes to exclude) a range instead of "since"
+	args := append([]string{"format-patch", "--stdout", "--binary", "--root"}, revs...)
+	output, err := command(ctx, "git", args...).Output()
+	if err != nil {
+		return fmt.Errorf("error running git format-patch: %w", err)
+	}
+	if err := os.WriteFile(file, output, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
+
+// CreateBundle writes the commits from base (or not on any remote) to tip
+// into a git bundle file.
+func CreateBundle(ctx context.Context, file string, base string, tip string) error {
+	if output, err := command(ctx, "git", "update-ref", patchRef, tip).CombinedOutput(); err != nil {
+		return fmt.Errorf("error creating %s: %w\n%s", patchRef, err, string(output))
+	}
+	defer command(ctx, "git", "update-ref", "-d", patchRef).Run()
+
+	args := append([]string{"bundle", "create", file}, PatchRange(base, patchRef)...)
+	if output, err := command(ctx, "git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("error creating bundle: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// IsBundle reports whether file is a git bundle rather than a mailbox.
+func IsBundle(file string) bool {
+	f, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	header := make([]byte, 16)
+	n, _ := f.Read(header)
+	return bytes.HasPrefix(header[:n], []byte("# v2 git bundle")) || bytes.HasPrefix(header[:n], []byte("# v3 git bundle"))
+}
+
+// PatchConflict describes why a patch file could not be applied.
+type PatchConflict struct {
+	Patch string   // the commit that failed, e.g. "0002 Fix login"
+	Files []string // files that did not apply, when git reported them
+}
+
+func (e *PatchConflict) Error() string {
+	msg := fmt.Sprintf("patch %s does not apply", e.Patch)
+	if len(e.Files) > 0 {
+		msg += ": " + strings.Join(e.Files, ", ")
+	}
+	return msg
+}
+
+var (
+	failedPatch = regexp.MustCompile(`(?m)^Patch failed at (.+)$`)
+	failedFile  = regexp.MustCompile(`(?m)^(?:error: patch failed: |CONFLICT \(content\): Merge conflict in )([^:\s]+)`)
+)
+
+// ApplyMailbox applies a format-patch file on the current branch with a
+// three-way merge. On a conflict the whole import is aborted, leaving the
+// branch as it was, and a *PatchConflict is returned.
+func ApplyMailbox(ctx context.Context, file string) error {
+	output, err := command(ctx, "git", "am", "--3way", "--keep-non-patch", file).CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	_ = cleanup(ctx, "am", "--abort")
+	if m := failedPatch.FindStringSubmatch(string(output)); m != nil {
+		conflict := &PatchConflict{Patch: strings.TrimSpace(m[1])}
+		for _, f := range failedFile.FindAllStringSubmatch(string(output), -1) {
+			conflict.Files = appendUnique(conflict.Files, f[1])
+		}
+		return conflict
+	}
+	return fmt.Errorf("error applying %s: %w\n%s", filepath.Base(file), err, string(output))
+}
+
+// ApplyBundle fetches the commits of a bundle and replays them on the current
+// branch: a fast-forward when the branch is at their base, cherry-picks
+// otherwise. A conflicting cherry-pick is aborted and returned as *PatchConflict.
+func ApplyBundle(ctx context.Context, file string) error {
+	if output, err := command(ctx, "git", "bundle", "verify", "-q", file).CombinedOutput(); err != nil {
+		return fmt.Errorf("the bundle needs commits this repository doesn't have (fetch first?): %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	if output, err := command(ctx, "git", "fetch", "-q", file, patchRef).CombinedOutput(); err != nil {
+		return fmt.Errorf("error reading bundle: %w\n%s", err, string(output))
+	}
+	if command(ctx, "git", "merge-base", "--is-ancestor", "HEAD", "FETCH_HEAD").Run() == nil {
+		if output, err := command(ctx, "git", "merge", "--ff-only", "-q", "FETCH_HEAD").CombinedOutput(); err != nil {
+			return fmt.Errorf("error fast-forwarding: %w\n%s", err, string(output))
+		}
+		return nil
+	}
+
+	// The bundle's prerequisites are its base: pick what comes after them
+	output, err := command(ctx, "git", "rev-list", "--reverse", "FETCH_HEAD", "--not", "HEAD").Output()
+	if err != nil {
+		return fmt.Errorf("error listing bundle commits: %w", err)
+	}
+	start, err := command(ctx, "git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return err
+	}
+	for _, sha := range strings.Fields(string(output)) {
+		out, err := command(ctx, "git", "cherry-pick", "--allow-empty", sha).CombinedOutput()
+		if err == nil {
+			continue
+		}
+		_ = cleanup(ctx, "cherry-pick", "--abort")
+		_ = cleanup(ctx, "reset", "-q", "--hard", strings.TrimSpace(string(start)))
+		subject, _ := command(ctx, "git", "log", "-1", "--format=%h %s", sha).Output()
+		conflict := &PatchConflict{Patch: strings.TrimSpace(string(subject))}
+		for _, f := range failedFile.FindAllStringSubmatch(string(out), -1) {
+			conflict.Files = appendUnique(conflict.Files, f[1])
+		}
+		return conflict
+	}
+	return nil
+}
+
+// UndoWIPCommit turns HEAD back into uncommitted changes when it is the WIP
+// commit of a patch file, reporting whether it was.
+func UndoWIPCommit(ctx context.Context) (bool, error) {
+	subject, err := command(ctx, "git", "log", "-1", "--format=%s").Output()
+	if err != nil || strings.TrimSpace(string(subject)) != WIPSubject {
+		return false, nil
+	}
+	if output, err := command(ctx, "git", "reset", "-q", "HEAD~1").CombinedOutput(); err != nil {
+		return true, fmt.Errorf("error restoring uncommitted changes: %w\n%s", err, string(output))
+	}
+	return true, nil
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+package git
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// background starts cmd in a process group of its own, out of the terminal's
+// foreground group: Ctrl+C reaches aio only, which decides whether to stop
+// the process, instead of killing it halfway through a merge or a push.
+func background(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// interrupt asks the process group of cmd (git and the hooks or ssh it
+// started) to stop, like Ctrl+C would have.
+func interrupt(cmd *exec.Cmd) error {
+	if cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGINT)
+	}
+	return cmd.Process.Signal(syscall.SIGINT)
+}
@@ -0,0 +1,17 @@
+package git
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// background starts cmd in a process group of its own, which Ctrl+C in the
+// console doesn't reach: aio decides whether to stop the process.
+func background(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// interrupt stops cmd. Windows can't send it Ctrl+C, it is killed.
+func interrupt(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,342 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"cli-aio/internal/pkg/explain"
+	"cli-aio/internal/pkg/profile"
+
+	"golang.org/x/term"
+)
+
+// Environment variables switching the runner for a whole aio invocation:
+// RecordEnv records the processes run into the fixture file it names,
+// ReplayEnv answers them from one instead of running git, and BackendEnv
+// picks the client of NewClient: "exec" (always git) or "go-git" (never).
+const (
+	RecordEnv  = "AIO_GIT_RECORD"
+	ReplayEnv  = "AIO_GIT_REPLAY"
+	BackendEnv = "AIO_GIT_BACKEND"
+)
+
+// Cmd is a process started by the package: the subset of exec.Cmd it uses,
+// run by the current Runner.
+type Cmd struct {
+	Name   string
+	Args   []string
+	Env    []string
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	ctx    context.Context
+}
+
+// String returns the command line of c, e.g. "git rev-parse HEAD".
+func (c *Cmd) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// Run runs c and waits for it, as exec.Cmd.Run. A failure is a *GitError.
+func (c *Cmd) Run() error {
+	var stderr bytes.Buffer
+	c.Stderr = teeWriter(c.Stderr, &stderr)
+	return c.run(&stderr)
+}
+
+// run runs c, stderr being where its standard error is captured for the
+// *GitError of a failure.
+func (c *Cmd) run(stderr *bytes.Buffer) error {
+	explain.Command(c.Env, append([]string{c.Name}, c.Args...))
+	defer profile.Start(profile.Git, c.String())()
+	if err := currentRunner().Run(c.ctx, c); err != nil {
+		return newGitError(c, err, stderr.String())
+	}
+	return nil
+}
+
+// Output runs c and returns its standard output, as exec.Cmd.Output: the
+// standard error ends up in the *exec.ExitError of a failure.
+func (c *Cmd) Output() ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	c.Stderr = teeWriter(c.Stderr, &stderr)
+	err := c.run(&stderr)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.Stderr == nil {
+		exitErr.Stderr = stderr.Bytes()
+	}
+	return stdout.Bytes(), err
+}
+
+// CombinedOutput runs c and returns its standard output and error together.
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	var out bytes.Buffer
+	c.Stdout, c.Stderr = &out, &out
+	err := c.run(&out)
+	return out.Bytes(), err
+}
+
+// Runner runs the processes of the package. Swapping it with SetRunner makes
+// the package usable without git or a repository, e.g. replaying a fixture.
+type Runner interface {
+	Run(ctx context.Context, c *Cmd) error
+}
+
+var (
+	runnerMu sync.Mutex
+	runner   Runner = ExecRunner{}
+)
+
+// SetRunner makes r run the processes started from now on.
+func SetRunner(r Runner) {
+	runnerMu.Lock()
+	defer runnerMu.Unlock()
+	runner = r
+}
+
+func currentRunner() Runner {
+	runnerMu.Lock()
+	defer runnerMu.Unlock()
+	return runner
+}
+
+// UseRunnerFromEnv records or replays the processes of this invocation when
+// RecordEnv or ReplayEnv is set, and checks BackendEnv for NewClient.
+func UseRunnerFromEnv() error {
+	if path := os.Getenv(ReplayEnv); path != "" {
+		r, err := LoadFixture(path)
+		if err != nil {
+			return err
+		}
+		SetRunner(r)
+	} else if path := os.Getenv(RecordEnv); path != "" {
+		SetRunner(&Recorder{Runner: ExecRunner{}, Path: path})
+	}
+	switch backend := os.Getenv(BackendEnv); backend {
+	case "", "exec", "go-git":
+		return nil
+	default:
+		return fmt.Errorf("unknown %s %q (expected exec or go-git)", BackendEnv, backend)
+	}
+}
+
+// ExecRunner runs processes for real. A cancelled process is interrupted
+// rather than killed, so git can clean up (e.g. a partial clone).
+//
+// Processes that don't read the terminal run in a process group of their
+// own, so the first Ctrl+C only asks aio to stop at the next safe point.
+// Commands talking to a remote stay in the terminal's group when there is
+// one: git or ssh may prompt for credentials or a key passphrase on it,
+// which a background group can't do (ssh would be stopped by SIGTTIN).
+type ExecRunner struct{}
+
+// Run implements Runner.
+func (ExecRunner) Run(ctx context.Context, c *Cmd) error {
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Env, cmd.Stdin, cmd.Stdout, cmd.Stderr = c.Env, c.Stdin, c.Stdout, c.Stderr
+	if c.Stdin == nil && !(remoteCommand(c) && term.IsTerminal(int(os.Stdin.Fd()))) {
+		background(cmd)
+	}
+	cmd.Cancel = func() error { return interrupt(cmd) }
+	cmd.WaitDelay = 5 * time.Second
+	return cmd.Run()
+}
+
+// remoteCommands are the git subcommands that may authenticate to a remote.
+var remoteCommands = []string{"clone", "fetch", "pull", "push", "ls-remote", "lfs", "submodule", "remote"}
+
+// remoteCommand reports whether c is a git command that may talk to a
+// remote, skipping the global options before the subcommand.
+func remoteCommand(c *Cmd) bool {
+	if c.Name != "git" {
+		return false
+	}
+	for i := 0; i < len(c.Args); i++ {
+		switch arg := c.Args[i]; {
+		case arg == "-C" || arg == "-c":
+			i++
+		case strings.HasPrefix(arg, "-"):
+		default:
+			return slices.Contains(remoteCommands, arg)
+		}
+	}
+	return false
+}
+
+// Call is a recorded process: its command line and what it returned.
+type Call struct {
+	// Args is the command line, the program first.
+	Args []string `json:"args"`
+	// Stdout holds both outputs when they were read together.
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+	ExitCode int    `json:"exit_code,omitempty"`
+	// Error is why the process could not run, e.g. git not installed.
+	Error string `json:"error,omitempty"`
+}
+
+// Fixture is a list of recorded calls, stored as JSON.
+type Fixture struct {
+	Calls []Call `json:"calls"`
+}
+
+// Recorder runs processes with Runner and appends each call to the fixture
+// at Path. The file is written after every call, as aio may exit anywhere.
+type Recorder struct {
+	Runner Runner
+	Path   string
+
+	mu      sync.Mutex
+	fixture Fixture
+}
+
+// Run implements Runner.
+func (r *Recorder) Run(ctx context.Context, c *Cmd) error {
+	var stdout, stderr bytes.Buffer
+	outer, errWriter := c.Stdout, c.Stderr
+	c.Stdout = teeWriter(outer, &stdout)
+	if errWriter != nil && errWriter == outer {
+		// Read together: keep the interleaving in Stdout
+		c.Stderr = c.Stdout
+	} else {
+		c.Stderr = teeWriter(errWriter, &stderr)
+	}
+	err := r.Runner.Run(ctx, c)
+	c.Stdout, c.Stderr = outer, errWriter
+
+	call := Call{Args: append([]string{c.Name}, c.Args...), Stdout: stdout.String(), Stderr: stderr.String()}
+	if code, ok := exitCode(err); ok {
+		call.ExitCode = code
+	} else if err != nil {
+		call.Error = err.Error()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fixture.Calls = append(r.fixture.Calls, call)
+	data, jsonErr := json.MarshalIndent(r.fixture, "", "  ")
+	if jsonErr == nil {
+		jsonErr = os.WriteFile(r.Path, append(data, '\n'), 0644)
+	}
+	if jsonErr != nil {
+		fmt.Fprintf(os.Stderr, "[!] Warning: failed to record %s: %v\n", c, jsonErr)
+	}
+	return err
+}
+
+// teeWriter writes to w (when set) and to record, so what a process prints
+// is still captured when it goes to the terminal too.
+func teeWriter(w io.Writer, record *bytes.Buffer) io.Writer {
+	if w == nil {
+		return record
+	}
+	return io.MultiWriter(w, record)
+}
+
+// Replayer answers processes from recorded calls instead of running them: a
+// command line gets the first unused call recorded with it, so a command run
+// twice (git status before and after a merge) gets both answers in order.
+type Replayer struct {
+	mu    sync.Mutex
+	calls []Call
+	used  []bool
+}
+
+// NewReplayer returns a Replayer answering with calls.
+func NewReplayer(calls []Call) *Replayer {
+	return &Replayer{calls: calls, used: make([]bool, len(calls))}
+}
+
+// LoadFixture returns a Replayer answering with the fixture at path.
+func LoadFixture(path string) (*Replayer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+	return NewReplayer(fixture.Calls), nil
+}
+
+// Run implements Runner.
+func (r *Replayer) Run(ctx context.Context, c *Cmd) error {
+	args := append([]string{c.Name}, c.Args...)
+	r.mu.Lock()
+	var call *Call
+	for i := range r.calls {
+		if !r.used[i] && slices.Equal(r.calls[i].Args, args) {
+			r.used[i] = true
+			call = &r.calls[i]
+			break
+		}
+	}
+	r.mu.Unlock()
+	if call == nil {
+		return fmt.Errorf("no recorded call for %s", c)
+	}
+	if call.Error != "" {
+		return errors.New(call.Error)
+	}
+	if c.Stdout != nil {
+		io.WriteString(c.Stdout, call.Stdout)
+	}
+	if c.Stderr != nil && call.Stderr != "" {
+		io.WriteString(c.Stderr, call.Stderr)
+	}
+	if call.ExitCode != 0 {
+		return &exitStatus{code: call.ExitCode}
+	}
+	return nil
+}
+
+// Unused returns the recorded calls that weren't replayed, e.g. to check
+// that a command ran everything it was expected to.
+func (r *Replayer) Unused() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []Call
+	for i, call := range r.calls {
+		if !r.used[i] {
+			unused = append(unused, call)
+		}
+	}
+	return unused
+}
+
+// exitCode returns the exit code of a process that ran and failed, ok being
+// false for other errors (e.g. git not found). Replayed failures have one too.
+func exitCode(err error) (int, bool) {
+	var exited interface{ ExitCode() int }
+	if errors.As(err, &exited) {
+		return exited.ExitCode(), true
+	}
+	return 0, false
+}
+
+// exitStatus is the failure of a replayed process, reporting its exit code
+// like *exec.ExitError.
+type exitStatus struct {
+	code int
+}
+
+func (e *exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// ExitCode returns the exit code git exited with when it was recorded.
+func (e *exitStatus) ExitCode() int {
+	return e.code
+}
@@ -0,0 +1,35 @@
+package gitlab
+
+import "net/url"
+
+// Access levels used for protected branches.
+const (
+	AccessDeveloper  = 30
+	AccessMaintainer = 40
+)
+
+// ProtectedBranch is a branch protection rule.
+type ProtectedBranch struct {
+	Name string `json:"name"`
+}
+
+// ProtectBranch protects a branch so only maintainers can push and developers can merge.
+// Protecting requires Maintainer access to the project.
+func (c *Client) ProtectBranch(projectID string, branch string) (*ProtectedBranch, error) {
+	body := map[string]interface{}{
+		"name":               branch,
+		"push_access_level":  AccessMaintainer,
+		"merge_access_level": AccessDeveloper,
+	}
+	var p ProtectedBranch
+	if err := c.Post(ProjectPath(projectID)+"/protected_branches", body, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// IsBranchProtected reports whether a protection rule exists for branch.
+func (c *Client) IsBranchProtected(projectID string, branch string) bool {
+	var p ProtectedBranch
+	return c.Get(ProjectPath(projectID)+"/protected_branches/"+url.PathEscape(branch), &p) == nil
+}
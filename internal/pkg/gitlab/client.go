@@ -0,0 +1,220 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/httpcache"
+	"cli-aio/internal/pkg/offline"
+)
+
+// Client is a minimal GitLab REST API (v4) client.
+type Client struct {
+	BaseURL string
+	Token   string
+	http    *http.Client
+	// ctx aborts the requests in flight when cancelled
+	ctx context.Context
+}
+
+// NewClient creates a client for the configured GitLab instance,
+// authenticated with the GITLAB_PRIVATE_TOKEN environment variable.
+func NewClient(ctx context.Context) (*Client, error) {
+	token := os.Getenv("GITLAB_PRIVATE_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("GITLAB_PRIVATE_TOKEN is not set")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		BaseURL: strings.TrimRight(cfg.GitLab.BaseURL, "/"),
+		Token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second, Transport: httpcache.Default()},
+		ctx:     ctx,
+	}, nil
+}
+
+// ProjectPath returns the URL path segment for a project ID or full path,
+// e.g. "group/sub/repo" -> "/projects/group%2Fsub%2Frepo".
+func ProjectPath(projectID string) string {
+	return "/projects/" + url.PathEscape(projectID)
+}
+
+// WebURL returns the browser URL for a project path relative to the instance.
+func (c *Client) WebURL(projectID string, suffix string) string {
+	return fmt.Sprintf("%s/%s%s", c.BaseURL, projectID, suffix)
+}
+
+// Get performs a GET request and decodes the JSON response into out.
+func (c *Client) Get(path string, out interface{}) error {
+	return c.Do(http.MethodGet, path, nil, out)
+}
+
+// Post performs a POST request with a JSON body and decodes the response into out.
+func (c *Client) Post(path string, body interface{}, out interface{}) error {
+	return c.Do(http.MethodPost, path, body, out)
+}
+
+// Put performs a PUT request with a JSON body and decodes the response into out.
+func (c *Client) Put(path string, body interface{}, out interface{}) error {
+	return c.Do(http.MethodPut, path, body, out)
+}
+
+// getSecret is Get for responses holding secrets, e.g. CI/CD variable values:
+// they are never written to the HTTP cache.
+func (c *Client) getSecret(path string, out interface{}) error {
+	return c.do(http.MethodGet, path, nil, http.Header{"Cache-Control": {"no-store"}}, out)
+}
+
+// Do sends an API request. body is JSON-encoded when non-nil and the response
+// is decoded into out when out is non-nil.
+func (c *Client) Do(method string, path string, body interface{}, out interface{}) error {
+	return c.do(method, path, body, nil, out)
+}
+
+// do is Do with header added to the request.
+func (c *Client) do(method string, path string, body interface{}, header http.Header, out interface{}) error {
+	resp, err := c.request(method, path, body, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode gitlab response for %s: %w", path, err)
+	}
+	return nil
+}
+
+// GetRaw performs a GET request and returns the raw response body.
+func (c *Client) GetRaw(path string) ([]byte, error) {
+	resp, err := c.request(http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gitlab response for %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// GetRawFrom is GetRaw for the bytes of the body from offset on, asked with a
+// Range header. A server ignoring it sends the whole body, cut here; nil means
+// there is nothing past offset yet.
+func (c *Client) GetRawFrom(path string, offset int) ([]byte, error) {
+	if offset == 0 {
+		return c.GetRaw(path)
+	}
+	resp, err := c.request(http.MethodGet, path, nil, http.Header{"Range": {fmt.Sprintf("bytes=%d-", offset)}})
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gitlab response for %s: %w", path, err)
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		return data, nil
+	}
+	if len(data) <= offset {
+		return nil, nil
+	}
+	return data[offset:], nil
+}
+
+// APIError is returned for GitLab responses with a non-2xx status.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("gitlab api %s %s returned %d: %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// IsForbidden reports whether err is a GitLab 403, i.e. the token lacks permission.
+func IsForbidden(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden
+}
+
+// IsNotFound reports whether err is a GitLab 404.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// request sends an API request, checking the token first when it is the
+// first request of the run.
+func (c *Client) request(method string, path string, body interface{}, header http.Header) (*http.Response, error) {
+	if !offline.Enabled() {
+		preflight.Do(c.checkToken)
+	}
+	return c.send(method, path, body, header)
+}
+
+// send sends the request with header added to the client's.
+func (c *Client) send(method string, path string, body interface{}, header http.Header) (*http.Response, error) {
+	if offline.Enabled() {
+		return nil, fmt.Errorf("gitlab request %s %s: %w", method, path, offline.ErrOffline)
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, method, c.BaseURL+"/api/v4"+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// Later requests fail fast instead of waiting for the same timeout
+		offline.Check(err)
+		return nil, fmt.Errorf("gitlab request %s %s failed: %w", method, path, err)
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, &APIError{Method: method, Path: path, StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+	return resp, nil
+}
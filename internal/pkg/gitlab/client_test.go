@@ -0,0 +1,46 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetRawFrom(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	trace := []byte("Running with gitlab-runner\n$ go test ./...\nok\n")
+	var ranges []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/personal_access_tokens/self", http.NotFound)
+	// ServeContent answers ranges with 206, or 416 past the end
+	mux.HandleFunc("/api/v4/ranged", func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "trace", time.Time{}, bytes.NewReader(trace))
+	})
+	mux.HandleFunc("/api/v4/whole", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(trace)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c := &Client{BaseURL: server.URL, Token: "test", http: server.Client(), ctx: context.Background()}
+
+	for _, path := range []string{"/ranged", "/whole"} {
+		for _, offset := range []int{0, 8, len(trace) - 3, len(trace)} {
+			data, err := c.GetRawFrom(path, offset)
+			if err != nil {
+				t.Fatalf("GetRawFrom(%s, %d): %v", path, offset, err)
+			}
+			if want := trace[offset:]; !bytes.Equal(data, want) {
+				t.Errorf("GetRawFrom(%s, %d) = %q, want %q", path, offset, data, want)
+			}
+		}
+	}
+	want := []string{"", "bytes=8-", fmt.Sprintf("bytes=%d-", len(trace)-3), fmt.Sprintf("bytes=%d-", len(trace))}
+	if fmt.Sprint(ranges) != fmt.Sprint(want) {
+		t.Errorf("Range headers sent: %q, want %q", ranges, want)
+	}
+}
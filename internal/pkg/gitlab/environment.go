@@ -0,0 +1,31 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Deployment is a GitLab deployment of a ref to an environment.
+type Deployment struct {
+	ID        int       `json:"id"`
+	Ref       string    `json:"ref"`
+	SHA       string    `json:"sha"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	User      User      `json:"user"`
+}
+
+// LatestDeployment returns the latest successful deployment to environment, or nil when there is none.
+func (c *Client) LatestDeployment(projectID string, environment string) (*Deployment, error) {
+	var deployments []Deployment
+	path := fmt.Sprintf("%s/deployments?environment=%s&status=success&order_by=created_at&sort=desc&per_page=1",
+		ProjectPath(projectID), url.QueryEscape(environment))
+	if err := c.Get(path, &deployments); err != nil {
+		return nil, err
+	}
+	if len(deployments) == 0 {
+		return nil, nil
+	}
+	return &deployments[0], nil
+}
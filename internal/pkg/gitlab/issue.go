@@ -0,0 +1,67 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Issue is a GitLab issue.
+type Issue struct {
+	ID        int       `json:"id"`
+	IID       int       `json:"iid"`
+	Title     string    `json:"title"`
+	State     string    `json:"state"`
+	Labels    []string  `json:"labels"`
+	Author    User      `json:"author"`
+	Assignees []User    `json:"assignees"`
+	WebURL    string    `json:"web_url"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OpenIssues lists the open issues of a project matching the given query
+// filters (e.g. "labels", "assignee_username"), most recently updated first.
+func (c *Client) OpenIssues(projectID string, filters url.Values) ([]Issue, error) {
+	q := url.Values{}
+	for k, v := range filters {
+		q[k] = v
+	}
+	q.Set("state", "opened")
+	q.Set("order_by", "updated_at")
+	q.Set("per_page", "100")
+
+	var issues []Issue
+	if err := c.Get(ProjectPath(projectID)+"/issues?"+q.Encode(), &issues); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// UpdateIssue changes an issue with the fields of the issue edit API (e.g.
+// "assignee_ids", "add_labels", "state_event") and returns it as updated.
+func (c *Client) UpdateIssue(projectID string, iid int, fields map[string]interface{}) (*Issue, error) {
+	var issue Issue
+	if err := c.Put(fmt.Sprintf("%s/issues/%d", ProjectPath(projectID), iid), fields, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// CommentIssue adds a note to an issue.
+func (c *Client) CommentIssue(projectID string, iid int, body string) error {
+	return c.Post(fmt.Sprintf("%s/issues/%d/notes", ProjectPath(projectID), iid), map[string]string{"body": body}, nil)
+}
+
+// Label is a label of a project.
+type Label struct {
+	Name string `json:"name"`
+}
+
+// Labels lists the labels of a project, including those of its groups.
+func (c *Client) Labels(projectID string) ([]Label, error) {
+	var labels []Label
+	if err := c.Get(ProjectPath(projectID)+"/labels?per_page=100", &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
@@ -0,0 +1,172 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MergeRequest is a GitLab merge request.
+type MergeRequest struct {
+	ID           int    `json:"id"`
+	IID          int    `json:"iid"`
+	ProjectID    int    `json:"project_id"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	State        string `json:"state"`
+	Draft        bool   `json:"draft"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	WebURL       string `json:"web_url"`
+	Author       User   `json:"author"`
+	Assignees    []User `json:"assignees"`
+	Reviewers    []User `json:"reviewers"`
+	References   struct {
+		Full string `json:"full"`
+	} `json:"references"`
+}
+
+// ProjectPath returns the "group/project" path of the MR, derived from its full reference.
+func (mr MergeRequest) ProjectPath() string {
+	ref := mr.References.Full
+	if i := strings.LastIndex(ref, "!"); i >= 0 {
+		return ref[:i]
+	}
+	return ref
+}
+
+// OpenMergeRequests lists open MRs matching the given query filters
+// (e.g. "assignee_id", "reviewer_id"). projectID may be empty to search all projects.
+func (c *Client) OpenMergeRequests(projectID string, filters url.Values) ([]MergeRequest, error) {
+	q := url.Values{}
+	for k, v := range filters {
+		q[k] = v
+	}
+	q.Set("state", "opened")
+	q.Set("per_page", "100")
+
+	path := "/merge_requests"
+	if projectID != "" {
+		path = ProjectPath(projectID) + "/merge_requests"
+	} else {
+		q.Set("scope", "all")
+	}
+
+	var mrs []MergeRequest
+	if err := c.Get(path+"?"+q.Encode(), &mrs); err != nil {
+		return nil, err
+	}
+	return mrs, nil
+}
+
+// NewMergeRequest holds what CreateMergeRequest needs.
+type NewMergeRequest struct {
+	SourceBranch       string `json:"source_branch"`
+	TargetBranch       string `json:"target_branch"`
+	Title              string `json:"title"`
+	Description        string `json:"description,omitempty"`
+	RemoveSourceBranch bool   `json:"remove_source_branch,omitempty"`
+}
+
+// CreateMergeRequest opens an MR in a project.
+func (c *Client) CreateMergeRequest(projectID string, mr NewMergeRequest) (*MergeRequest, error) {
+	var created MergeRequest
+	if err := c.Post(ProjectPath(projectID)+"/merge_requests", mr, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// ApproveMergeRequest approves an MR as the current user.
+func (c *Client) ApproveMergeRequest(projectID int, iid int) error {
+	return c.Post(fmt.Sprintf("/projects/%d/merge_requests/%d/approve", projectID, iid), nil, nil)
+}
+
+// GetMergeRequest fetches a single MR.
+func (c *Client) GetMergeRequest(projectID int, iid int) (*MergeRequest, error) {
+	var mr MergeRequest
+	if err := c.Get(fmt.Sprintf("/projects/%d/merge_requests/%d", projectID, iid), &mr); err != nil {
+		return nil, err
+	}
+	return &mr, nil
+}
+
+// Approvals is the approval state of an MR.
+type Approvals struct {
+	ApprovalsRequired int `json:"approvals_required"`
+	ApprovalsLeft     int `json:"approvals_left"`
+	ApprovedBy        []struct {
+		User User `json:"user"`
+	} `json:"approved_by"`
+}
+
+// MergeRequestApprovals fetches who approved an MR.
+func (c *Client) MergeRequestApprovals(projectID int, iid int) (*Approvals, error) {
+	var approvals Approvals
+	if err := c.Get(fmt.Sprintf("/projects/%d/merge_requests/%d/approvals", projectID, iid), &approvals); err != nil {
+		return nil, err
+	}
+	return &approvals, nil
+}
+
+// CommentMergeRequest adds a note to an MR.
+func (c *Client) CommentMergeRequest(projectID int, iid int, body string) error {
+	return c.Post(fmt.Sprintf("/projects/%d/merge_requests/%d/notes", projectID, iid), map[string]string{"body": body}, nil)
+}
+
+// CommitMergeRequests lists the MRs a commit belongs to.
+func (c *Client) CommitMergeRequests(projectID string, sha string) ([]MergeRequest, error) {
+	var mrs []MergeRequest
+	if err := c.Get(fmt.Sprintf("%s/repository/commits/%s/merge_requests", ProjectPath(projectID), sha), &mrs); err != nil {
+		return nil, err
+	}
+	return mrs, nil
+}
+
+// ReviewItem is an MR in a user's review queue with the roles the user has on it.
+type ReviewItem struct {
+	MergeRequest MergeRequest `json:"merge_request"`
+	Roles        []string     `json:"roles"`
+}
+
+// ReviewQueue fetches the open MRs userID is assignee or reviewer of in each
+// project (all projects when projects is empty), sorted by reference.
+func (c *Client) ReviewQueue(userID int, projects []string) ([]ReviewItem, error) {
+	scopes := projects
+	if len(scopes) == 0 {
+		scopes = []string{""}
+	}
+
+	byID := map[int]*ReviewItem{}
+	var order []int
+	for _, scope := range scopes {
+		for _, role := range []string{"assignee", "reviewer"} {
+			filters := url.Values{}
+			filters.Set(role+"_id", strconv.Itoa(userID))
+			mrs, err := c.OpenMergeRequests(scope, filters)
+			if err != nil {
+				return nil, err
+			}
+			for _, mr := range mrs {
+				item, ok := byID[mr.ID]
+				if !ok {
+					item = &ReviewItem{MergeRequest: mr}
+					byID[mr.ID] = item
+					order = append(order, mr.ID)
+				}
+				item.Roles = append(item.Roles, role)
+			}
+		}
+	}
+
+	items := make([]ReviewItem, 0, len(order))
+	for _, id := range order {
+		items = append(items, *byID[id])
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].MergeRequest.References.Full < items[j].MergeRequest.References.Full
+	})
+	return items, nil
+}
@@ -0,0 +1,118 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Pipeline is a GitLab CI pipeline.
+type Pipeline struct {
+	ID        int       `json:"id"`
+	Status    string    `json:"status"`
+	Ref       string    `json:"ref"`
+	SHA       string    `json:"sha"`
+	WebURL    string    `json:"web_url"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Job is a single job of a pipeline.
+type Job struct {
+	ID       int     `json:"id"`
+	Name     string  `json:"name"`
+	Stage    string  `json:"stage"`
+	Status   string  `json:"status"`
+	Duration float64 `json:"duration"`
+	WebURL   string  `json:"web_url"`
+}
+
+// IsFinished reports whether a pipeline or job status is terminal.
+func IsFinished(status string) bool {
+	switch status {
+	case "success", "failed", "canceled", "skipped", "manual":
+		return true
+	}
+	return false
+}
+
+// LatestPipeline returns the most recent pipeline for ref.
+func (c *Client) LatestPipeline(projectID string, ref string) (*Pipeline, error) {
+	var pipelines []Pipeline
+	path := fmt.Sprintf("%s/pipelines?ref=%s&per_page=1", ProjectPath(projectID), url.QueryEscape(ref))
+	if err := c.Get(path, &pipelines); err != nil {
+		return nil, err
+	}
+	if len(pipelines) == 0 {
+		return nil, fmt.Errorf("no pipelines found for ref '%s'", ref)
+	}
+	return &pipelines[0], nil
+}
+
+// LatestCommitPipeline returns the most recent pipeline for ref at commit
+// sha, nil when there is none (yet).
+func (c *Client) LatestCommitPipeline(projectID string, ref string, sha string) (*Pipeline, error) {
+	var pipelines []Pipeline
+	path := fmt.Sprintf("%s/pipelines?ref=%s&sha=%s&per_page=1", ProjectPath(projectID), url.QueryEscape(ref), url.QueryEscape(sha))
+	if err := c.Get(path, &pipelines); err != nil {
+		return nil, err
+	}
+	if len(pipelines) == 0 {
+		return nil, nil
+	}
+	return &pipelines[0], nil
+}
+
+// GetPipeline fetches a single pipeline by ID.
+func (c *Client) GetPipeline(projectID string, pipelineID int) (*Pipeline, error) {
+	var p Pipeline
+	if err := c.Get(fmt.Sprintf("%s/pipelines/%d", ProjectPath(projectID), pipelineID), &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// PipelineJobs lists the jobs of a pipeline, all pages of them.
+func (c *Client) PipelineJobs(projectID string, pipelineID int) ([]Job, error) {
+	var all []Job
+	for page := 1; ; page++ {
+		var jobs []Job
+		path := fmt.Sprintf("%s/pipelines/%d/jobs?per_page=100&page=%d", ProjectPath(projectID), pipelineID, page)
+		if err := c.Get(path, &jobs); err != nil {
+			return nil, err
+		}
+		all = append(all, jobs...)
+		if len(jobs) < 100 {
+			return all, nil
+		}
+	}
+}
+
+// RetryJob retries a job and returns the newly created job.
+func (c *Client) RetryJob(projectID string, jobID int) (*Job, error) {
+	var job Job
+	if err := c.Post(fmt.Sprintf("%s/jobs/%d/retry", ProjectPath(projectID), jobID), nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetJob fetches a single job by ID.
+func (c *Client) GetJob(projectID string, jobID int) (*Job, error) {
+	var job Job
+	if err := c.Get(fmt.Sprintf("%s/jobs/%d", ProjectPath(projectID), jobID), &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// JobTrace returns the full log (trace) of a job.
+func (c *Client) JobTrace(projectID string, jobID int) ([]byte, error) {
+	return c.GetRaw(fmt.Sprintf("%s/jobs/%d/trace", ProjectPath(projectID), jobID))
+}
+
+// JobTraceFrom returns the bytes of the trace of a job from offset on, nil
+// when it hasn't grown past offset.
+func (c *Client) JobTraceFrom(projectID string, jobID int, offset int) ([]byte, error) {
+	return c.GetRawFrom(fmt.Sprintf("%s/jobs/%d/trace", ProjectPath(projectID), jobID), offset)
+}
@@ -0,0 +1,66 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// A pipeline with more than 100 jobs is listed over several pages.
+func TestPipelineJobsPages(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	const total = 230
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/personal_access_tokens/self", http.NotFound)
+	mux.HandleFunc("/api/v4/projects/group/app/pipelines/7/jobs", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		var jobs []Job
+		for id := (page-1)*100 + 1; id <= min(page*100, total); id++ {
+			jobs = append(jobs, Job{ID: id, Status: "success"})
+		}
+		json.NewEncoder(w).Encode(jobs)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c := &Client{BaseURL: server.URL, Token: "test", http: server.Client(), ctx: context.Background()}
+
+	jobs, err := c.PipelineJobs("group/app", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != total || jobs[total-1].ID != total {
+		t.Errorf("PipelineJobs returned %d jobs, want %d", len(jobs), total)
+	}
+}
+
+func TestLatestCommitPipeline(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/personal_access_tokens/self", http.NotFound)
+	mux.HandleFunc("/api/v4/projects/group/app/pipelines", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("ref") != "main" {
+			t.Errorf("ref = %q, want main", q.Get("ref"))
+		}
+		if q.Get("sha") == "abc123" {
+			w.Write([]byte(`[{"id":12,"status":"running","sha":"abc123"}]`))
+		} else {
+			w.Write([]byte(`[]`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c := &Client{BaseURL: server.URL, Token: "test", http: server.Client(), ctx: context.Background()}
+
+	pipeline, err := c.LatestCommitPipeline("group/app", "main", "abc123")
+	if err != nil || pipeline == nil || pipeline.ID != 12 {
+		t.Errorf("LatestCommitPipeline(abc123) = %+v, %v, want #12", pipeline, err)
+	}
+	pipeline, err = c.LatestCommitPipeline("group/app", "main", "def456")
+	if err != nil || pipeline != nil {
+		t.Errorf("LatestCommitPipeline(def456) = %+v, %v, want nil", pipeline, err)
+	}
+}
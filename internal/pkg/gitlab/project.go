@@ -0,0 +1,61 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Project is a GitLab project.
+type Project struct {
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	DefaultBranch     string `json:"default_branch"`
+	SSHURLToRepo      string `json:"ssh_url_to_repo"`
+	HTTPURLToRepo     string `json:"http_url_to_repo"`
+	WebURL            string `json:"web_url"`
+}
+
+// Namespace is a GitLab group or user namespace.
+type Namespace struct {
+	ID       int    `json:"id"`
+	FullPath string `json:"full_path"`
+}
+
+// FindNamespace returns the namespace with the exact full path (e.g. "bank/operation").
+func (c *Client) FindNamespace(fullPath string) (*Namespace, error) {
+	var namespaces []Namespace
+	if err := c.Get("/namespaces?per_page=100&search="+url.QueryEscape(fullPath), &namespaces); err != nil {
+		return nil, err
+	}
+	for _, ns := range namespaces {
+		if ns.FullPath == fullPath {
+			return &ns, nil
+		}
+	}
+	return nil, fmt.Errorf("namespace '%s' not found", fullPath)
+}
+
+// GetProject fetches a project by ID or full path.
+func (c *Client) GetProject(projectID string) (*Project, error) {
+	var p Project
+	if err := c.Get(ProjectPath(projectID), &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// CreateProject creates a private project named name inside the namespace.
+func (c *Client) CreateProject(namespaceID int, name string) (*Project, error) {
+	body := map[string]interface{}{
+		"name":         name,
+		"path":         name,
+		"namespace_id": namespaceID,
+		"visibility":   "private",
+	}
+	var p Project
+	if err := c.Post("/projects", body, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
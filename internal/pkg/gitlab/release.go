@@ -0,0 +1,36 @@
+package gitlab
+
+import "net/url"
+
+// Release is a GitLab release attached to a tag.
+type Release struct {
+	Name        string `json:"name"`
+	TagName     string `json:"tag_name"`
+	Description string `json:"description"`
+}
+
+// CreateRelease creates a release for an existing tag.
+func (c *Client) CreateRelease(projectID string, tag string, description string) (*Release, error) {
+	body := map[string]interface{}{
+		"name":        tag,
+		"tag_name":    tag,
+		"description": description,
+	}
+	var r Release
+	if err := c.Post(ProjectPath(projectID)+"/releases", body, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetRelease returns the release of tag, or nil when there is none.
+func (c *Client) GetRelease(projectID string, tag string) (*Release, error) {
+	var r Release
+	if err := c.Get(ProjectPath(projectID)+"/releases/"+url.PathEscape(tag), &r); err != nil {
+		if IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &r, nil
+}
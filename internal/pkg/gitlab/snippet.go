@@ -0,0 +1,59 @@
+package gitlab
+
+import (
+	"fmt"
+	"time"
+)
+
+// Snippet is a snippet of a project.
+type Snippet struct {
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	FileName    string    `json:"file_name"`
+	Description string    `json:"description"`
+	Visibility  string    `json:"visibility"`
+	WebURL      string    `json:"web_url"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Author      User      `json:"author"`
+}
+
+// SnippetFile is a file of a new snippet.
+type SnippetFile struct {
+	FilePath string `json:"file_path"`
+	Content  string `json:"content"`
+}
+
+// NewSnippet holds what CreateSnippet needs.
+type NewSnippet struct {
+	Title       string        `json:"title"`
+	Description string        `json:"description,omitempty"`
+	Visibility  string        `json:"visibility"`
+	Files       []SnippetFile `json:"files"`
+}
+
+// Snippets lists the snippets of a project, most recently updated first.
+func (c *Client) Snippets(projectID string) ([]Snippet, error) {
+	var snippets []Snippet
+	if err := c.Get(ProjectPath(projectID)+"/snippets?per_page=100", &snippets); err != nil {
+		return nil, err
+	}
+	return snippets, nil
+}
+
+// SnippetContent returns the raw content of a snippet.
+func (c *Client) SnippetContent(projectID string, id int) (string, error) {
+	data, err := c.GetRaw(fmt.Sprintf("%s/snippets/%d/raw", ProjectPath(projectID), id))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// CreateSnippet adds a snippet to a project.
+func (c *Client) CreateSnippet(projectID string, s NewSnippet) (*Snippet, error) {
+	var created Snippet
+	if err := c.Post(ProjectPath(projectID)+"/snippets", s, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
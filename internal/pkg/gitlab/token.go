@@ -0,0 +1,134 @@
+package gitlab
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"cli-aio/internal/pkg/config"
+)
+
+// TokenWarnDays is how long before expiry the token check starts warning.
+const TokenWarnDays = 14
+
+// tokenCheckInterval is how often the check before the first API call asks GitLab.
+const tokenCheckInterval = 24 * time.Hour
+
+// TokenInfo describes the API token, as returned by /personal_access_tokens/self.
+type TokenInfo struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	Active    bool     `json:"active"`
+	Revoked   bool     `json:"revoked"`
+	ExpiresAt string   `json:"expires_at"` // YYYY-MM-DD, empty when it never expires
+}
+
+// ErrTokenInvalid is returned by TokenInfo when GitLab rejects the token (401).
+var ErrTokenInvalid = errors.New("gitlab token is invalid, expired or revoked")
+
+// TokenInfo returns the scopes and expiry of the client's token. It returns
+// ErrTokenInvalid for a rejected token and an *APIError when GitLab can't
+// describe it (e.g. a 404 for tokens that aren't personal access tokens).
+func (c *Client) TokenInfo() (*TokenInfo, error) {
+	resp, err := c.send(http.MethodGet, "/personal_access_tokens/self", nil, nil)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+			return nil, ErrTokenInvalid
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var info TokenInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to decode gitlab token info: %w", err)
+	}
+	return &info, nil
+}
+
+// Problems returns what is wrong with the token or about to be: revoked,
+// expired or expiring within TokenWarnDays, or missing the api scope that
+// releases, approvals and branch protection need.
+func (t *TokenInfo) Problems(now time.Time) []string {
+	var problems []string
+	if t.Revoked || !t.Active {
+		problems = append(problems, fmt.Sprintf("token %s is revoked or inactive", t.Name))
+	}
+	if t.ExpiresAt != "" {
+		if expires, err := time.Parse("2006-01-02", t.ExpiresAt); err == nil {
+			days := int(expires.Sub(now.Truncate(24*time.Hour)).Hours() / 24)
+			switch {
+			case days < 0:
+				problems = append(problems, fmt.Sprintf("token %s expired on %s", t.Name, t.ExpiresAt))
+			case days <= TokenWarnDays:
+				problems = append(problems, fmt.Sprintf("token %s expires in %d day(s) (%s)", t.Name, days, t.ExpiresAt))
+			}
+		}
+	}
+	if !slices.Contains(t.Scopes, "api") {
+		if slices.Contains(t.Scopes, "read_api") {
+			problems = append(problems, fmt.Sprintf("token %s has read_api only: releases, approvals and branch protection will fail", t.Name))
+		} else {
+			problems = append(problems, fmt.Sprintf("token %s has neither api nor read_api scope (has: %v)", t.Name, t.Scopes))
+		}
+	}
+	return problems
+}
+
+// TokenSettingsURL is where the user renews or creates tokens.
+func (c *Client) TokenSettingsURL() string {
+	return c.BaseURL + "/-/user_settings/personal_access_tokens"
+}
+
+// tokenCheck records when the check before the first API call last ran.
+type tokenCheck struct {
+	Fingerprint string    `json:"fingerprint"` // hash of the token, so a new token is checked again
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+var preflight sync.Once
+
+// checkToken warns on stderr about token problems before the first API call
+// of a run. GitLab is asked, and problems reported, at most once a day per
+// token so the warning doesn't repeat on every command.
+func (c *Client) checkToken() {
+	path, err := config.Path("token-check.json")
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256([]byte(c.Token))
+	fingerprint := hex.EncodeToString(sum[:8])
+
+	var cached tokenCheck
+	if ok, _ := config.ReadJSON(path, &cached); ok && cached.Fingerprint == fingerprint && time.Since(cached.CheckedAt) < tokenCheckInterval {
+		return
+	}
+	info, err := c.TokenInfo()
+	if errors.Is(err, ErrTokenInvalid) {
+		fmt.Fprintf(os.Stderr, "[!] %v, create a new one at %s\n", err, c.TokenSettingsURL())
+		return
+	}
+	var apiErr *APIError
+	if err != nil && !errors.As(err, &apiErr) {
+		// Network trouble: the actual request reports it
+		return
+	}
+	_ = config.WriteJSON(path, tokenCheck{Fingerprint: fingerprint, CheckedAt: time.Now()})
+	if info == nil {
+		return
+	}
+	problems := info.Problems(time.Now())
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "[!] GitLab %s\n", problem)
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "    Manage tokens at %s\n", c.TokenSettingsURL())
+	}
+}
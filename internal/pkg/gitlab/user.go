@@ -0,0 +1,17 @@
+package gitlab
+
+// User is a GitLab user.
+type User struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+// CurrentUser returns the user owning the API token.
+func (c *Client) CurrentUser() (*User, error) {
+	var u User
+	if err := c.Get("/user", &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
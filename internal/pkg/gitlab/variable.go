@@ -0,0 +1,75 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Variable is a CI/CD variable of a project.
+type Variable struct {
+	Key          string `json:"key"`
+	Value        string `json:"value"`
+	VariableType string `json:"variable_type,omitempty"` // "env_var" or "file"
+	Protected    bool   `json:"protected"`
+	Masked       bool   `json:"masked"`
+	// EnvironmentScope is the environments the variable applies to, "*" for all.
+	EnvironmentScope string `json:"environment_scope,omitempty"`
+}
+
+// Variables lists the CI/CD variables of a project.
+func (c *Client) Variables(projectID string) ([]Variable, error) {
+	var all []Variable
+	for page := 1; ; page++ {
+		var variables []Variable
+		path := fmt.Sprintf("%s/variables?per_page=100&page=%d", ProjectPath(projectID), page)
+		if err := c.getSecret(path, &variables); err != nil {
+			return nil, err
+		}
+		all = append(all, variables...)
+		if len(variables) < 100 {
+			return all, nil
+		}
+	}
+}
+
+// GetVariable returns the variable key of the environment scope (empty when the
+// key has a single scope), or nil when there is none.
+func (c *Client) GetVariable(projectID string, key string, scope string) (*Variable, error) {
+	var v Variable
+	if err := c.getSecret(variablePath(projectID, key, scope), &v); err != nil {
+		if IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &v, nil
+}
+
+// CreateVariable adds a variable to a project.
+func (c *Client) CreateVariable(projectID string, v Variable) (*Variable, error) {
+	var created Variable
+	if err := c.Post(ProjectPath(projectID)+"/variables", v, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateVariable replaces the value and flags of an existing variable,
+// identified by its key and environment scope.
+func (c *Client) UpdateVariable(projectID string, v Variable) (*Variable, error) {
+	var updated Variable
+	if err := c.Put(variablePath(projectID, v.Key, v.EnvironmentScope), v, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// variablePath returns the path of a variable, filtered on its environment
+// scope so a key defined for several environments is not ambiguous.
+func variablePath(projectID string, key string, scope string) string {
+	path := ProjectPath(projectID) + "/variables/" + url.PathEscape(key)
+	if scope != "" {
+		path += "?filter[environment_scope]=" + url.QueryEscape(scope)
+	}
+	return path
+}
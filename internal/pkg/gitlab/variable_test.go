@@ -0,0 +1,58 @@
+package gitlab
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"cli-aio/internal/pkg/httpcache"
+)
+
+// Variable values are secrets: 'aio gl vars list' and 'get' must not leave
+// them in the HTTP cache, even though GitLab sends an ETag for them.
+func TestVariablesAreNotCached(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/personal_access_tokens/self", http.NotFound)
+	mux.HandleFunc("/api/v4/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `W/"vars"`)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v4/projects/group/app/variables" {
+			w.Write([]byte(`[{"key":"DB_PASSWORD","value":"hunter2","masked":true}]`))
+		} else {
+			w.Write([]byte(`{"key":"DB_PASSWORD","value":"hunter2","masked":true}`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	transport := httpcache.NewTransport()
+	transport.Base, transport.Dir = server.Client().Transport, t.TempDir()
+	c := &Client{BaseURL: server.URL, Token: "test", http: &http.Client{Transport: transport}, ctx: context.Background()}
+
+	variables, err := c.Variables("group/app")
+	if err != nil || len(variables) != 1 || variables[0].Value != "hunter2" {
+		t.Fatalf("Variables = %+v, %v", variables, err)
+	}
+	if v, err := c.GetVariable("group/app", "DB_PASSWORD", ""); err != nil || v == nil || v.Value != "hunter2" {
+		t.Fatalf("GetVariable = %+v, %v", v, err)
+	}
+	filepath.WalkDir(transport.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			t.Errorf("cached a variable response: %s", path)
+		}
+		return nil
+	})
+
+	// Other responses are still cached
+	var u User
+	if err := c.Get("/user", &u); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(transport.Dir, "*", "*.json")); len(files) != 1 {
+		t.Errorf("cache files after GET /user = %v, want one", files)
+	}
+}
@@ -0,0 +1,63 @@
+package gitlab
+
+import "net/url"
+
+// WikiPage is a page of a project wiki. Content is only set when asked for.
+type WikiPage struct {
+	Slug    string `json:"slug"`
+	Title   string `json:"title"`
+	Format  string `json:"format"`
+	Content string `json:"content"`
+}
+
+// WikiPages lists the pages of a project wiki, with their content when
+// withContent is set (e.g. to search it).
+func (c *Client) WikiPages(projectID string, withContent bool) ([]WikiPage, error) {
+	path := ProjectPath(projectID) + "/wikis"
+	if withContent {
+		path += "?with_content=1"
+	}
+	var pages []WikiPage
+	if err := c.Get(path, &pages); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// GetWikiPage returns a wiki page with its content, or nil when there is none.
+func (c *Client) GetWikiPage(projectID string, slug string) (*WikiPage, error) {
+	var page WikiPage
+	if err := c.Get(ProjectPath(projectID)+"/wikis/"+url.PathEscape(slug), &page); err != nil {
+		if IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &page, nil
+}
+
+// SaveWikiPage creates the Markdown page slug, or replaces its content when
+// it already exists, and returns the saved page. GitLab derives the slug
+// from the title, directories included, so the title is the slug.
+func (c *Client) SaveWikiPage(projectID string, slug string, content string) (*WikiPage, error) {
+	existing, err := c.GetWikiPage(projectID, slug)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]string{"title": slug, "content": content, "format": "markdown"}
+	var page WikiPage
+	if existing != nil {
+		err = c.Put(ProjectPath(projectID)+"/wikis/"+url.PathEscape(slug), body, &page)
+	} else {
+		err = c.Post(ProjectPath(projectID)+"/wikis", body, &page)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// WikiURL returns the browser URL of a wiki page.
+func (c *Client) WikiURL(projectID string, slug string) string {
+	return c.WebURL(projectID, "/-/wikis/"+slug)
+}
@@ -0,0 +1,205 @@
+// Package golden replays interactive aio sessions in a pseudo-terminal and
+// compares what the screen shows with recorded transcripts, so prompt-driven
+// flows (rmerge, ckl, prj cd, gencmd) can be checked for regressions.
+package golden
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Script is an interactive session to replay, read from a YAML file.
+type Script struct {
+	// Name is the file name without its extension, e.g. "rmerge".
+	Name string `yaml:"-"`
+	// Path is the file the script was read from.
+	Path string `yaml:"-"`
+	// Setup are shell commands run first in the work directory, e.g. to
+	// create a repository. They run with the same environment as Run.
+	Setup []string `yaml:"setup"`
+	// Run is the shell command line of the session, with aio on the PATH,
+	// e.g. "aio git rmerge" or `cd "$(aio prj cd)" && pwd`.
+	Run string `yaml:"run"`
+	// Env adds environment variables to the session.
+	Env map[string]string `yaml:"env"`
+	// Keys are typed one entry at a time, the screen being recorded after
+	// each. Named keys are written <enter>, <up>, <down>, <left>, <right>,
+	// <space>, <tab>, <bs>, <esc>, <ctrl-c>, <ctrl-d> or <ctrl-r>, anything
+	// else is typed as is: "pa<enter>".
+	Keys []string `yaml:"keys"`
+	// Timeout bounds the whole session (default 20s).
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Screen size of the sessions, fixed so transcripts don't depend on the
+// terminal the harness runs in.
+const (
+	Cols = 100
+	Rows = 30
+)
+
+// Transcript returns the path of the golden transcript of s, next to it.
+func (s *Script) Transcript() string {
+	return strings.TrimSuffix(s.Path, filepath.Ext(s.Path)) + ".golden"
+}
+
+// Load reads the script at path.
+func Load(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+	s := &Script{}
+	if err := yaml.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid script %s: %w", path, err)
+	}
+	if strings.TrimSpace(s.Run) == "" {
+		return nil, fmt.Errorf("invalid script %s: run is required", path)
+	}
+	s.Path = path
+	s.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if s.Timeout <= 0 {
+		s.Timeout = 20 * time.Second
+	}
+	return s, nil
+}
+
+// Glob returns the scripts (*.yaml) in dir, sorted by name.
+func Glob(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no scripts in %s", dir)
+	}
+	return paths, nil
+}
+
+// namedKeys are the bytes a terminal sends for the <name> keys of Script.Keys.
+var namedKeys = map[string]string{
+	"enter":  "\r",
+	"up":     "\x1b[A",
+	"down":   "\x1b[B",
+	"right":  "\x1b[C",
+	"left":   "\x1b[D",
+	"space":  " ",
+	"tab":    "\t",
+	"bs":     "\x7f",
+	"esc":    "\x1b",
+	"ctrl-c": "\x03",
+	"ctrl-d": "\x04",
+	"ctrl-k": "\x0b",
+	"ctrl-o": "\x0f",
+	"ctrl-r": "\x12",
+}
+
+var namedKeyPattern = regexp.MustCompile(`<([a-z-]+)>`)
+
+// keyBytes translates the named keys of a Script.Keys entry to what a
+// terminal sends.
+func keyBytes(keys string) (string, error) {
+	var err error
+	out := namedKeyPattern.ReplaceAllStringFunc(keys, func(m string) string {
+		name := m[1 : len(m)-1]
+		b, ok := namedKeys[name]
+		if !ok && err == nil {
+			err = fmt.Errorf("unknown key %s", m)
+		}
+		return b
+	})
+	return out, err
+}
+
+// Step is the screen after typing Keys ("" for the screen before any key).
+type Step struct {
+	Keys   string
+	Screen string
+}
+
+// Result is what a session showed.
+type Result struct {
+	Steps []Step
+	// ExitCode is the exit code of the session, -1 when it was killed.
+	ExitCode int
+}
+
+// String formats r as a transcript: each screen under a header naming the
+// keys typed before it, then the exit code.
+func (r *Result) String() string {
+	var b strings.Builder
+	for _, step := range r.Steps {
+		if step.Keys == "" {
+			b.WriteString("=== start\n")
+		} else {
+			fmt.Fprintf(&b, "=== keys %s\n", step.Keys)
+		}
+		if step.Screen != "" {
+			b.WriteString(step.Screen)
+			b.WriteString("\n")
+		}
+	}
+	fmt.Fprintf(&b, "=== exit %d\n", r.ExitCode)
+	return b.String()
+}
+
+// normalize trims the screen and replaces the temporary directories of the
+// session with $WORK and $HOME, so transcripts are the same on every run.
+func normalize(screen string, work string, home string) string {
+	lines := strings.Split(screen, "\n")
+	for i, line := range lines {
+		line = strings.ReplaceAll(line, work, "$WORK")
+		line = strings.ReplaceAll(line, home, "$HOME")
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// Check compares got with the golden transcript of s. With update, the
+// transcript is (re)written instead. A mismatch is reported with the first
+// differing line.
+func Check(s *Script, got string, update bool) error {
+	path := s.Transcript()
+	if update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			return fmt.Errorf("failed to write transcript: %w", err)
+		}
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no transcript %s, record it with --update", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read transcript: %w", err)
+	}
+	if want := string(data); want != got {
+		return fmt.Errorf("transcript differs: %s", firstDiff(want, got))
+	}
+	return nil
+}
+
+// firstDiff describes the first line where want and got differ.
+func firstDiff(want string, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < max(len(wantLines), len(gotLines)); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d\n    want: %q\n    got:  %q", i+1, w, g)
+		}
+	}
+	return "line endings"
+}
@@ -0,0 +1,179 @@
+//go:build !windows
+
+package golden
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/hinshun/vt10x"
+)
+
+// Timing of the sessions: the screen is recorded once the output has been
+// quiet for settleQuiet, or after settleMax for a command that keeps drawing.
+const (
+	settleQuiet = 300 * time.Millisecond
+	settleMax   = 5 * time.Second
+)
+
+// Run replays s with the aio binary at bin, in a temporary work directory and
+// home, and returns the screens it showed. The environment is fixed (git
+// identity and dates, time zone, no daemon) so runs are reproducible.
+func Run(ctx context.Context, bin string, s *Script) (*Result, error) {
+	root, err := os.MkdirTemp("", "aio-golden-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+	defer os.RemoveAll(root)
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return nil, err
+	}
+	work, home, binDir := filepath.Join(root, "work"), filepath.Join(root, "home"), filepath.Join(root, "bin")
+	for _, dir := range []string{work, home, binDir} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create session directory: %w", err)
+		}
+	}
+	if err := os.Symlink(bin, filepath.Join(binDir, "aio")); err != nil {
+		return nil, fmt.Errorf("failed to link aio: %w", err)
+	}
+	env := sessionEnv(s, work, home, binDir)
+
+	for _, line := range s.Setup {
+		setup := exec.CommandContext(ctx, "sh", "-c", line)
+		setup.Dir, setup.Env = work, env
+		if out, err := setup.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("setup %q failed: %w\n%s", line, err, strings.TrimSpace(string(out)))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+	session := exec.CommandContext(ctx, "sh", "-c", s.Run)
+	session.Dir, session.Env = work, env
+	tty, err := pty.StartWithSize(session, &pty.Winsize{Cols: Cols, Rows: Rows})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+	defer tty.Close()
+
+	// The virtual terminal answers the cursor position queries of survey
+	screen := &screen{vt: vt10x.New(vt10x.WithSize(Cols, Rows), vt10x.WithWriter(tty)), last: time.Now()}
+	done := make(chan struct{})
+	go func() {
+		screen.copy(tty)
+		close(done)
+	}()
+	snapshot := func() string {
+		return normalize(screen.vt.String(), work, home)
+	}
+
+	result := &Result{}
+	screen.settle(done)
+	result.Steps = append(result.Steps, Step{Screen: snapshot()})
+	for _, keys := range s.Keys {
+		b, err := keyBytes(keys)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tty.WriteString(b); err != nil {
+			return nil, fmt.Errorf("failed to type %q: %w", keys, err)
+		}
+		screen.settle(done)
+		result.Steps = append(result.Steps, Step{Keys: keys, Screen: snapshot()})
+	}
+
+	err = session.Wait()
+	<-done
+	// What was printed after the last key belongs to its screen
+	result.Steps[len(result.Steps)-1].Screen = snapshot()
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		return nil, fmt.Errorf("session timed out after %s", s.Timeout)
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		return nil, err
+	}
+	return result, nil
+}
+
+// sessionEnv returns the environment of the setup commands and the session.
+func sessionEnv(s *Script, work string, home string, binDir string) []string {
+	env := []string{
+		"PATH=" + binDir + string(os.PathListSeparator) + os.Getenv("PATH"),
+		"HOME=" + home,
+		"WORK=" + work,
+		"TERM=xterm-256color",
+		"LANG=C.UTF-8",
+		"TZ=UTC",
+		"AIO_NO_DAEMON=1",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=Golden",
+		"GIT_AUTHOR_EMAIL=golden@example.com",
+		"GIT_AUTHOR_DATE=2024-01-01T10:00:00Z",
+		"GIT_COMMITTER_NAME=Golden",
+		"GIT_COMMITTER_EMAIL=golden@example.com",
+		"GIT_COMMITTER_DATE=2024-01-01T10:00:00Z",
+	}
+	for k, v := range s.Env {
+		env = append(env, k+"="+v)
+	}
+	return env
+}
+
+// screen feeds the output of a session to a virtual terminal.
+type screen struct {
+	vt   vt10x.Terminal
+	mu   sync.Mutex
+	last time.Time
+}
+
+// copy writes the output of tty to the virtual terminal until it closes,
+// holding back the bytes of a rune split across reads.
+func (s *screen) copy(tty *os.File) {
+	buf := make([]byte, 4096)
+	var pending []byte
+	for {
+		n, err := tty.Read(buf)
+		if n > 0 {
+			pending = append(pending, buf[:n]...)
+			written, _ := s.vt.Write(pending)
+			pending = pending[written:]
+			s.mu.Lock()
+			s.last = time.Now()
+			s.mu.Unlock()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// settle waits until the output has been quiet for settleQuiet, the session
+// ended or settleMax passed.
+func (s *screen) settle(done <-chan struct{}) {
+	deadline := time.Now().Add(settleMax)
+	for time.Now().Before(deadline) {
+		select {
+		case <-done:
+			return
+		case <-time.After(50 * time.Millisecond):
+		}
+		s.mu.Lock()
+		quiet := time.Since(s.last) >= settleQuiet
+		s.mu.Unlock()
+		if quiet {
+			return
+		}
+	}
+}
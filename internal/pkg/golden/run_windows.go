@@ -0,0 +1,11 @@
+package golden
+
+import (
+	"context"
+	"errors"
+)
+
+// Run replays s in a pseudo-terminal, which Windows doesn't have.
+func Run(ctx context.Context, bin string, s *Script) (*Result, error) {
+	return nil, errors.New("golden sessions need a pseudo-terminal, not available on Windows")
+}
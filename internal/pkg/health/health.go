@@ -0,0 +1,87 @@
+package health
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// VersionHeaders are the response headers checked, in order, for the deployed version.
+var VersionHeaders = []string{"X-App-Version", "X-Version", "X-Build-Version", "X-Git-Commit"}
+
+// envOrder ranks the usual environments so they are listed in promotion order.
+var envOrder = map[string]int{"dev": 0, "qc": 1, "stg": 2, "staging": 2, "prod": 3, "production": 3}
+
+// Result is the outcome of probing one endpoint.
+type Result struct {
+	Env     string
+	URL     string
+	Status  int
+	Latency time.Duration
+	Version string
+	Err     error
+}
+
+// OK reports whether the endpoint answered with a 2xx status.
+func (r Result) OK() bool {
+	return r.Err == nil && r.Status >= 200 && r.Status < 300
+}
+
+// Probe sends a GET request to url and records status, latency and version header.
+func Probe(client *http.Client, env string, url string) Result {
+	r := Result{Env: env, URL: url}
+	start := time.Now()
+	resp, err := client.Get(url)
+	r.Latency = time.Since(start)
+	if err != nil {
+		r.Err = fmt.Errorf("request failed: %w", err)
+		return r
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	r.Status = resp.StatusCode
+	for _, h := range VersionHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			r.Version = v
+			break
+		}
+	}
+	return r
+}
+
+// ProbeAll probes all endpoints (env -> URL) concurrently. Results are sorted
+// in environment promotion order (dev, qc, stg, prod), then by name.
+func ProbeAll(endpoints map[string]string, timeout time.Duration) []Result {
+	client := &http.Client{Timeout: timeout}
+	results := make([]Result, 0, len(endpoints))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for env, url := range endpoints {
+		wg.Add(1)
+		go func(env string, url string) {
+			defer wg.Done()
+			r := Probe(client, env, url)
+			mu.Lock()
+			results = append(results, r)
+			mu.Unlock()
+		}(env, url)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		ri, iKnown := envOrder[results[i].Env]
+		rj, jKnown := envOrder[results[j].Env]
+		if iKnown != jKnown {
+			return iKnown
+		}
+		if ri != rj {
+			return ri < rj
+		}
+		return results[i].Env < results[j].Env
+	})
+	return results
+}
@@ -0,0 +1,331 @@
+// Package httpcache is an http.RoundTripper for the API clients: it
+// revalidates GET responses with ETag/Last-Modified so unchanged data costs a
+// 304, and backs off when the server reports its rate limit is exhausted.
+// Requests or responses with "Cache-Control: no-store", such as the ones
+// holding secrets, are never written to the cache.
+package httpcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cli-aio/internal/pkg/explain"
+	"cli-aio/internal/pkg/profile"
+)
+
+// DisableEnv turns caching off when set to a non-empty value (backoff stays on).
+const DisableEnv = "AIO_NO_HTTP_CACHE"
+
+const (
+	// maxBodySize is the largest response body kept in the cache.
+	maxBodySize = 2 << 20
+	// maxRetries is how often a rate-limited request is retried.
+	maxRetries = 3
+	// maxWait caps a single backoff, whatever the server asks for.
+	maxWait = time.Minute
+	// lowRemaining is the quota left below which requests are spaced out.
+	lowRemaining = 5
+)
+
+// entry is a cached response.
+type entry struct {
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+}
+
+// limit is the rate limit state last reported by a host.
+type limit struct {
+	remaining int
+	reset     time.Time
+}
+
+// Transport wraps another RoundTripper with caching and backoff.
+type Transport struct {
+	Base http.RoundTripper
+	Dir  string // cache directory, empty disables caching
+
+	mu     sync.Mutex
+	limits map[string]limit
+}
+
+// NewTransport returns a Transport over http.DefaultTransport caching in the
+// user cache directory (~/.cache/cli-aio/http on Linux).
+func NewTransport() *Transport {
+	t := &Transport{Base: http.DefaultTransport, limits: map[string]limit{}}
+	if os.Getenv(DisableEnv) == "" {
+		if dir, err := os.UserCacheDir(); err == nil {
+			t.Dir = filepath.Join(dir, "cli-aio", "http")
+		}
+	}
+	return t
+}
+
+var (
+	defaultOnce      sync.Once
+	defaultTransport *Transport
+)
+
+// Default returns the Transport shared by all API clients of the process, so
+// rate limit state carries over between clients talking to the same host.
+func Default() *Transport {
+	defaultOnce.Do(func() { defaultTransport = NewTransport() })
+	return defaultTransport
+}
+
+// RoundTrip sends req, answering from the cache when the server says the
+// cached response is still current, and retrying after rate limit responses.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	explain.Request(req)
+	defer profile.Start(profile.API, req.Method+" "+req.URL.Host+req.URL.Path)()
+	if err := t.throttle(req); err != nil {
+		return nil, err
+	}
+
+	var key string
+	var cached *entry
+	// Only whole responses are cached, not the ranges of one
+	if req.Method == http.MethodGet && noStore(req.Header) && t.Dir != "" {
+		// Drop what an older version may have stored for it
+		os.Remove(t.path(cacheKey(req)))
+	} else if req.Method == http.MethodGet && req.Header.Get("Range") == "" && t.Dir != "" {
+		key = cacheKey(req)
+		if cached = t.load(key); cached != nil {
+			req = req.Clone(req.Context())
+			if cached.ETag != "" {
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
+	}
+
+	var resp *http.Response
+	for attempt := 0; ; attempt++ {
+		var err error
+		if resp, err = t.Base.RoundTrip(req); err != nil {
+			return nil, err
+		}
+		t.record(req, resp)
+		wait, limited := retryAfter(resp)
+		if !limited || attempt == maxRetries || (req.Body != nil && req.GetBody == nil) {
+			break
+		}
+		resp.Body.Close()
+		fmt.Fprintf(os.Stderr, "[!] %s is rate limiting requests, retrying in %s\n", req.URL.Host, wait.Round(time.Second))
+		if err := sleep(req, wait); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+
+	if key == "" {
+		return resp, nil
+	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		return cached.response(req), nil
+	}
+	if resp.StatusCode == http.StatusOK && !noStore(resp.Header) && (resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "") {
+		return t.store(key, resp)
+	}
+	return resp, nil
+}
+
+// throttle waits for the rate limit window to reset when the host reported
+// its quota is (almost) used up.
+func (t *Transport) throttle(req *http.Request) error {
+	t.mu.Lock()
+	l, ok := t.limits[req.URL.Host]
+	t.mu.Unlock()
+	if !ok || l.remaining >= lowRemaining || l.reset.IsZero() {
+		return nil
+	}
+	wait := time.Until(l.reset)
+	if wait <= 0 {
+		return nil
+	}
+	if l.remaining > 0 {
+		// Spread the last requests over the rest of the window
+		wait /= time.Duration(l.remaining + 1)
+	} else {
+		fmt.Fprintf(os.Stderr, "[!] %s rate limit reached, waiting %s\n", req.URL.Host, min(wait, maxWait).Round(time.Second))
+	}
+	return sleep(req, min(wait, maxWait))
+}
+
+// record keeps the rate limit headers of resp for its host. GitLab sends
+// RateLimit-*, GitHub and Jira X-RateLimit-*.
+func (t *Transport) record(req *http.Request, resp *http.Response) {
+	remaining := header(resp, "RateLimit-Remaining", "X-RateLimit-Remaining")
+	n, err := strconv.Atoi(remaining)
+	if err != nil {
+		return
+	}
+	l := limit{remaining: n}
+	if reset, err := strconv.ParseInt(header(resp, "RateLimit-Reset", "X-RateLimit-Reset"), 10, 64); err == nil {
+		l.reset = time.Unix(reset, 0)
+	}
+	t.mu.Lock()
+	t.limits[req.URL.Host] = l
+	t.mu.Unlock()
+}
+
+// retryAfter reports whether resp is a rate limit response and how long to
+// wait before retrying: 429, or 403 with no quota left (GitHub).
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	limited := resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && header(resp, "RateLimit-Remaining", "X-RateLimit-Remaining") == "0")
+	if !limited {
+		return 0, false
+	}
+	wait := 5 * time.Second
+	if s := resp.Header.Get("Retry-After"); s != "" {
+		if secs, err := strconv.Atoi(s); err == nil {
+			wait = time.Duration(secs) * time.Second
+		} else if at, err := http.ParseTime(s); err == nil {
+			wait = time.Until(at)
+		}
+	} else if reset, err := strconv.ParseInt(header(resp, "RateLimit-Reset", "X-RateLimit-Reset"), 10, 64); err == nil {
+		wait = time.Until(time.Unix(reset, 0))
+	}
+	return max(min(wait, maxWait), time.Second), true
+}
+
+// noStore reports whether h has the no-store directive of Cache-Control.
+func noStore(h http.Header) bool {
+	for _, value := range h.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(directive), "no-store") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func header(resp *http.Response, names ...string) string {
+	for _, name := range names {
+		if v := resp.Header.Get(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// sleep waits for d or until the request is cancelled.
+func sleep(req *http.Request, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
+
+// cacheKey identifies a response by URL and credentials, so users or tokens
+// sharing a machine never see each other's responses.
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	for _, s := range []string{req.URL.String(), req.Header.Get("PRIVATE-TOKEN"), req.Header.Get("Authorization")} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (t *Transport) path(key string) string {
+	return filepath.Join(t.Dir, key[:2], key+".json")
+}
+
+func (t *Transport) load(key string) *entry {
+	data, err := os.ReadFile(t.path(key))
+	if err != nil {
+		return nil
+	}
+	var e entry
+	if json.Unmarshal(data, &e) != nil {
+		return nil
+	}
+	return &e
+}
+
+// store saves a 200 response and returns it with its body still readable.
+func (t *Transport) store(key string, resp *http.Response) (*http.Response, error) {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize+1))
+	rest := resp.Body
+	if err != nil || len(body) > maxBodySize {
+		// Too large (or failed): hand back what was read followed by the rest
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(body), rest), rest}
+		return resp, nil
+	}
+	rest.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	e := entry{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Header:       http.Header{"Content-Type": resp.Header.Values("Content-Type")},
+		Body:         body,
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return resp, nil
+	}
+	// Responses can hold private data: keep the cache readable by the user only
+	path := t.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err == nil {
+		tmp := path + ".tmp"
+		if os.WriteFile(tmp, data, 0600) == nil {
+			os.Rename(tmp, path)
+		}
+	}
+	return resp, nil
+}
+
+// response rebuilds the cached response as a 200 for req.
+func (e *entry) response(req *http.Request) *http.Response {
+	header := e.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("X-From-Cache", "1")
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,204 @@
+// Package identity stores git identities (name, email, signing key) and the
+// directory rules choosing one per repository.
+package identity
+
+import (
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/git"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Profile is a named git identity.
+type Profile struct {
+	Name       string `json:"name"`
+	UserName   string `json:"user_name"`
+	Email      string `json:"email"`
+	SigningKey string `json:"signing_key,omitempty"` // user.signingkey, enables commit.gpgsign
+}
+
+// Rule selects Profile for repositories under Pattern, e.g. "~/work/**".
+type Rule struct {
+	Pattern string `json:"pattern"`
+	Profile string `json:"profile"`
+}
+
+// Store holds the profiles and rules persisted in identities.json.
+type Store struct {
+	Profiles []Profile `json:"profiles"`
+	Rules    []Rule    `json:"rules,omitempty"`
+}
+
+// Load reads identities.json, returning an empty store when none exists.
+func Load() (*Store, error) {
+	path, err := config.Path("identities.json")
+	if err != nil {
+		return nil, err
+	}
+	store := &Store{}
+	if _, err := config.ReadJSON(path, store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Save writes the store to identities.json.
+func Save(store *Store) error {
+	path, err := config.Path("identities.json")
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, store)
+}
+
+// String formats the profile as e.g. "work: Jane Doe <jane@corp.com>".
+func (p Profile) String() string {
+	s := fmt.Sprintf("%s: %s <%s>", p.Name, p.UserName, p.Email)
+	if p.SigningKey != "" {
+		s += " (signed)"
+	}
+	return s
+}
+
+// Find returns the named profile.
+func (s *Store) Find(name string) (*Profile, bool) {
+	for i := range s.Profiles {
+		if s.Profiles[i].Name == name {
+			return &s.Profiles[i], true
+		}
+	}
+	return nil, false
+}
+
+// Put adds or replaces a profile, keeping them sorted by name.
+func (s *Store) Put(p Profile) {
+	if existing, ok := s.Find(p.Name); ok {
+		*existing = p
+		return
+	}
+	s.Profiles = append(s.Profiles, p)
+	sort.Slice(s.Profiles, func(i, j int) bool { return s.Profiles[i].Name < s.Profiles[j].Name })
+}
+
+// Remove deletes a profile and its rules. Returns false if it did not exist.
+func (s *Store) Remove(name string) bool {
+	for i := range s.Profiles {
+		if s.Profiles[i].Name == name {
+			s.Profiles = append(s.Profiles[:i], s.Profiles[i+1:]...)
+			rules := s.Rules[:0]
+			for _, r := range s.Rules {
+				if r.Profile != name {
+					rules = append(rules, r)
+				}
+			}
+			s.Rules = rules
+			return true
+		}
+	}
+	return false
+}
+
+// ByEmail returns the profile using email, e.g. to name the identity a repository uses.
+func (s *Store) ByEmail(email string) (*Profile, bool) {
+	for i := range s.Profiles {
+		if strings.EqualFold(s.Profiles[i].Email, email) {
+			return &s.Profiles[i], true
+		}
+	}
+	return nil, false
+}
+
+// AddRule adds or replaces the rule for pattern.
+func (s *Store) AddRule(pattern string, profile string) {
+	for i := range s.Rules {
+		if s.Rules[i].Pattern == pattern {
+			s.Rules[i].Profile = profile
+			return
+		}
+	}
+	s.Rules = append(s.Rules, Rule{Pattern: pattern, Profile: profile})
+}
+
+// RemoveRule deletes the rule for pattern. Returns false if it did not exist.
+func (s *Store) RemoveRule(pattern string) bool {
+	for i := range s.Rules {
+		if s.Rules[i].Pattern == pattern {
+			s.Rules = append(s.Rules[:i], s.Rules[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Apply sets the profile in the local config of the repository at dir. Without
+// a signing key, a signing setup left by another profile is removed.
+func Apply(ctx context.Context, dir string, p Profile) error {
+	if err := git.SetLocalConfigIn(ctx, dir, "user.name", p.UserName); err != nil {
+		return err
+	}
+	if err := git.SetLocalConfigIn(ctx, dir, "user.email", p.Email); err != nil {
+		return err
+	}
+	if p.SigningKey == "" {
+		if err := git.UnsetLocalConfigIn(ctx, dir, "user.signingkey"); err != nil {
+			return err
+		}
+		return git.UnsetLocalConfigIn(ctx, dir, "commit.gpgsign")
+	}
+	if err := git.SetLocalConfigIn(ctx, dir, "user.signingkey", p.SigningKey); err != nil {
+		return err
+	}
+	return git.SetLocalConfigIn(ctx, dir, "commit.gpgsign", "true")
+}
+
+// Match returns the first rule matching dir, nil when none does.
+func (s *Store) Match(dir string) *Rule {
+	for i := range s.Rules {
+		if matchDir(s.Rules[i].Pattern, dir) {
+			return &s.Rules[i]
+		}
+	}
+	return nil
+}
+
+// ValidatePattern checks a rule pattern: a directory glob, "~" for the home
+// directory and a trailing "/**" for every directory below.
+func ValidatePattern(pattern string) error {
+	if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil {
+		return fmt.Errorf("invalid pattern %s: %w", pattern, err)
+	}
+	return nil
+}
+
+// matchDir reports whether dir matches pattern. "~/work/**" matches ~/work and
+// everything below it, "~/oss/*" only its direct children.
+func matchDir(pattern string, dir string) bool {
+	if strings.HasPrefix(pattern, "~") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return false
+		}
+		pattern = home + strings.TrimPrefix(pattern, "~")
+	}
+	pattern = filepath.ToSlash(filepath.Clean(pattern))
+	dir = filepath.ToSlash(filepath.Clean(dir))
+
+	if base, ok := strings.CutSuffix(pattern, "/**"); ok {
+		// Match the base against dir and each of its parents
+		for d := dir; ; d = path.Dir(d) {
+			if ok, _ := path.Match(base, d); ok {
+				return true
+			}
+			if parent := path.Dir(d); parent == d {
+				return false
+			}
+		}
+	}
+	ok, _ := path.Match(pattern, dir)
+	return ok
+}
@@ -0,0 +1,182 @@
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/httpcache"
+	"cli-aio/internal/pkg/secrets"
+)
+
+// Client is a minimal Jira REST API (v2) client shared by all Jira-aware commands.
+type Client struct {
+	BaseURL string
+	email   string
+	token   string
+	http    *http.Client
+	// ctx aborts the requests in flight when cancelled
+	ctx context.Context
+}
+
+// Issue is a Jira issue with the fields used by the CLI.
+type Issue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string `json:"summary"`
+		Description string `json:"description"`
+		Status      struct {
+			Name string `json:"name"`
+		} `json:"status"`
+		IssueType struct {
+			Name string `json:"name"`
+		} `json:"issuetype"`
+		Priority struct {
+			Name string `json:"name"`
+		} `json:"priority"`
+		Assignee *struct {
+			DisplayName string `json:"displayName"`
+		} `json:"assignee"`
+		Reporter *struct {
+			DisplayName string `json:"displayName"`
+		} `json:"reporter"`
+	} `json:"fields"`
+}
+
+// Transition is a workflow transition available on an issue.
+type Transition struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	To   struct {
+		Name string `json:"name"`
+	} `json:"to"`
+}
+
+// NewClient creates a client for the configured Jira instance.
+// When the "jira.email" secret is set, basic auth (Jira Cloud) is used,
+// otherwise "jira.token" is sent as a bearer personal access token (Jira Server).
+func NewClient(ctx context.Context) (*Client, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Jira.BaseURL == "" {
+		return nil, fmt.Errorf("jira base URL is not configured (set jira.base_url in config.json)")
+	}
+	token, err := secrets.Require("jira.token")
+	if err != nil {
+		return nil, err
+	}
+	email, err := secrets.Get("jira.email")
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		BaseURL: strings.TrimRight(cfg.Jira.BaseURL, "/"),
+		email:   email,
+		token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second, Transport: httpcache.Default()},
+		ctx:     ctx,
+	}, nil
+}
+
+// BrowseURL returns the web URL of an issue.
+func (c *Client) BrowseURL(key string) string {
+	return fmt.Sprintf("%s/browse/%s", c.BaseURL, key)
+}
+
+// Search runs a JQL query and returns up to limit issues.
+func (c *Client) Search(jql string, limit int) ([]Issue, error) {
+	var result struct {
+		Issues []Issue `json:"issues"`
+	}
+	q := url.Values{}
+	q.Set("jql", jql)
+	q.Set("maxResults", fmt.Sprint(limit))
+	q.Set("fields", "summary,status,issuetype,priority,assignee")
+	if err := c.do(http.MethodGet, "/search?"+q.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Issues, nil
+}
+
+// MyIssues returns unresolved issues assigned to the current user, most recently updated first.
+func (c *Client) MyIssues(limit int) ([]Issue, error) {
+	return c.Search("assignee = currentUser() AND resolution = Unresolved ORDER BY updated DESC", limit)
+}
+
+// GetIssue fetches a single issue by key.
+func (c *Client) GetIssue(key string) (*Issue, error) {
+	var issue Issue
+	if err := c.do(http.MethodGet, "/issue/"+url.PathEscape(key), nil, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// Transitions lists the transitions currently available for an issue.
+func (c *Client) Transitions(key string) ([]Transition, error) {
+	var result struct {
+		Transitions []Transition `json:"transitions"`
+	}
+	if err := c.do(http.MethodGet, "/issue/"+url.PathEscape(key)+"/transitions", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Transitions, nil
+}
+
+// DoTransition moves an issue through the given transition.
+func (c *Client) DoTransition(key string, transitionID string) error {
+	body := map[string]interface{}{"transition": map[string]string{"id": transitionID}}
+	return c.do(http.MethodPost, "/issue/"+url.PathEscape(key)+"/transitions", body, nil)
+}
+
+func (c *Client) do(method string, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, method, c.BaseURL+"/rest/api/2"+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.email != "" {
+		req.SetBasicAuth(c.email, c.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("jira request %s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("jira api %s %s returned %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode jira response for %s: %w", path, err)
+	}
+	return nil
+}
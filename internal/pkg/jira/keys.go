@@ -0,0 +1,40 @@
+package jira
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"cli-aio/internal/pkg/git"
+)
+
+var issueKeyRegex = regexp.MustCompile(`\b[A-Z][A-Z0-9]+-\d+\b`)
+
+// ExtractKeys returns the distinct issue keys (e.g. ABC-123) mentioned in text, in order of appearance.
+func ExtractKeys(text string) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, key := range issueKeyRegex.FindAllString(text, -1) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// RecentKeys returns the issue keys of the current branch, the latest commits
+// and the local branches of the repository, in that order, for suggestions.
+func RecentKeys(ctx context.Context) []string {
+	var texts []string
+	if head, err := git.GetCurrentBranch(ctx); err == nil {
+		texts = append(texts, head.Branch)
+	}
+	if commits, err := git.RecentCommits(ctx, "HEAD", 50); err == nil {
+		texts = append(texts, commits...)
+	}
+	if branches, err := git.GetLocalBranches(ctx); err == nil {
+		texts = append(texts, branches...)
+	}
+	return ExtractKeys(strings.Join(texts, "\n"))
+}
@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"cli-aio/internal/pkg/config"
+)
+
+// DefaultDesktopAfter is how long a watched command runs before its end is
+// announced on the desktop.
+const DefaultDesktopAfter = time.Minute
+
+// DefaultLongCommands are the commands watched without notify.long_commands.
+var DefaultLongCommands = []string{"ci status", "ci logs", "release", "do", "prj backup", "git rmerge"}
+
+// running is the command being timed by StartCommand.
+var running struct {
+	path    []string
+	started time.Time
+}
+
+// StartCommand starts timing the command given by the arguments after the
+// global flags. Later calls (e.g. the steps of 'aio do') are ignored.
+func StartCommand(args []string) {
+	if !running.started.IsZero() {
+		return
+	}
+	running.started = time.Now()
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		running.path = append(running.path, arg)
+	}
+}
+
+// FinishCommand sends a desktop notification with the outcome of the timed
+// command when it is watched, ran longer than the threshold and the terminal
+// isn't the focused window (the user switched away while waiting).
+func FinishCommand(err error) {
+	if running.started.IsZero() {
+		return
+	}
+	elapsed := time.Since(running.started)
+	running.started = time.Time{}
+
+	cfg, cfgErr := config.Load()
+	if cfgErr != nil {
+		return
+	}
+	threshold := DefaultDesktopAfter
+	if after := cfg.Notify.DesktopAfter; after == "off" {
+		return
+	} else if after != "" {
+		if d, err := time.ParseDuration(after); err == nil {
+			threshold = d
+		}
+	}
+	if threshold <= 0 || elapsed < threshold || !watched(cfg.Notify.LongCommands) || terminalFocused() {
+		return
+	}
+
+	command := "aio " + strings.Join(running.path, " ")
+	m := Message{Title: command + " finished", Text: "after " + elapsed.Round(time.Second).String(), OK: err == nil}
+	if err != nil {
+		m.Title = command + " failed"
+		m.Text += ": " + err.Error()
+	}
+	if err := (Desktop{}).Send(m); err != nil {
+		fmt.Fprintf(os.Stderr, "[!] Warning: %v\n", err)
+	}
+}
+
+// watched reports whether the timed command starts with one of commands.
+func watched(commands []string) bool {
+	if len(commands) == 0 {
+		commands = DefaultLongCommands
+	}
+	path := " " + strings.Join(running.path, " ") + " "
+	for _, c := range commands {
+		if strings.HasPrefix(path, " "+strings.TrimSpace(c)+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// terminalFocused reports whether the window running aio has the focus.
+// When that can't be told (no X11 window id, unknown terminal, Windows) it
+// reports false, so a notification is sent rather than missed.
+func terminalFocused() bool {
+	switch runtime.GOOS {
+	case "darwin":
+		app := os.Getenv("TERM_PROGRAM")
+		if app == "" {
+			return false
+		}
+		output, err := exec.Command("osascript", "-e",
+			`tell application "System Events" to get name of first application process whose frontmost is true`).Output()
+		if err != nil {
+			return false
+		}
+		front := strings.ToLower(strings.TrimSpace(string(output)))
+		// TERM_PROGRAM names differ from the process names, e.g. Apple_Terminal -> Terminal
+		aliases := map[string]string{"apple_terminal": "terminal", "iterm.app": "iterm", "vscode": "code"}
+		name := strings.ToLower(app)
+		if alias, ok := aliases[name]; ok {
+			name = alias
+		}
+		return strings.Contains(front, name)
+	case "linux":
+		window := os.Getenv("WINDOWID")
+		if window == "" {
+			return false
+		}
+		output, err := exec.Command("xdotool", "getactivewindow").Output()
+		return err == nil && strings.TrimSpace(string(output)) == window
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+// Package notify announces events (a release, a finished pipeline, the end of
+// a batch command) on the channels the user routed them to in config.json.
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/secrets"
+)
+
+// Events sent by the commands.
+const (
+	EventRelease = "release" // aio release finished
+	EventCI      = "ci"      // a watched pipeline finished
+	EventBatch   = "batch"   // a command over many projects or steps finished
+)
+
+// LegacySecret holds the Slack-compatible webhook release notifications went
+// to before routes existed. It stays the default route of EventRelease.
+const LegacySecret = "release.webhook"
+
+// Message is what gets announced.
+type Message struct {
+	Title string
+	Text  string
+	OK    bool // success or failure, providers may show it differently
+}
+
+// String renders the message as plain text: the title, then the text.
+func (m Message) String() string {
+	if m.Text == "" {
+		return m.Title
+	}
+	if m.Title == "" {
+		return m.Text
+	}
+	return m.Title + "\n" + m.Text
+}
+
+// Provider delivers messages to one destination.
+type Provider interface {
+	Send(m Message) error
+}
+
+// ErrNoRoute is returned by Send when no channel is configured for the event.
+var ErrNoRoute = errors.New("no notification channel configured")
+
+// NewProvider returns the provider for a configured channel.
+func NewProvider(ch config.NotifyChannel) (Provider, error) {
+	switch ch.Type {
+	case "slack":
+		url, err := secretValue(ch.Secret, "webhook URL")
+		if err != nil {
+			return nil, err
+		}
+		return Slack{WebhookURL: url}, nil
+	case "telegram":
+		token, err := secretValue(ch.Secret, "bot token")
+		if err != nil {
+			return nil, err
+		}
+		if ch.ChatID == "" {
+			return nil, fmt.Errorf("telegram channel needs a chat_id")
+		}
+		return Telegram{Token: token, ChatID: ch.ChatID}, nil
+	case "desktop":
+		return Desktop{}, nil
+	case "stdout", "":
+		return Stdout{}, nil
+	}
+	return nil, fmt.Errorf("unknown notification channel type '%s' (slack, telegram, desktop, stdout)", ch.Type)
+}
+
+// Channels returns the configured channels for event, by name. Events
+// without a route use "*"; releases fall back to the legacy webhook secret.
+func Channels(event string) (map[string]config.NotifyChannel, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	names, ok := cfg.Notify.Routes[event]
+	if !ok {
+		names, ok = cfg.Notify.Routes["*"]
+	}
+	channels := map[string]config.NotifyChannel{}
+	if !ok {
+		if event == EventRelease {
+			if url, _ := secrets.Get(LegacySecret); url != "" {
+				channels[LegacySecret] = config.NotifyChannel{Type: "slack", Secret: LegacySecret}
+			}
+		}
+		return channels, nil
+	}
+	for _, name := range names {
+		ch, ok := cfg.Notify.Channels[name]
+		if !ok {
+			if name != "stdout" && name != "desktop" {
+				return nil, fmt.Errorf("notification route %s uses unknown channel '%s'", event, name)
+			}
+			// The built-in providers need no configuration
+			ch = config.NotifyChannel{Type: name}
+		}
+		channels[name] = ch
+	}
+	return channels, nil
+}
+
+// Send announces m on every channel routed for event. It returns ErrNoRoute
+// when there is none, and the failures of the channels that didn't work.
+func Send(event string, m Message) error {
+	channels, err := Channels(event)
+	if err != nil {
+		return err
+	}
+	if len(channels) == 0 {
+		return ErrNoRoute
+	}
+	names := make([]string, 0, len(channels))
+	for name := range channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		p, err := NewProvider(channels[name])
+		if err == nil {
+			err = p.Send(m)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Announce sends m for event from a command that is done anyway: failures are
+// printed as warnings and a missing route is ignored.
+func Announce(event string, m Message) {
+	if err := Send(event, m); err != nil && !errors.Is(err, ErrNoRoute) {
+		fmt.Printf("[!] Warning: Notification failed: %v\n", err)
+	}
+}
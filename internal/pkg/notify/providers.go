@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"cli-aio/internal/pkg/offline"
+	"cli-aio/internal/pkg/secrets"
+)
+
+// telegramAPI is the Telegram Bot API base URL.
+const telegramAPI = "https://api.telegram.org"
+
+// Slack posts to a Slack-compatible incoming webhook ({"text": "..."}).
+type Slack struct {
+	WebhookURL string
+}
+
+func (s Slack) Send(m Message) error {
+	text := m.String()
+	if m.Title != "" && m.Text != "" {
+		text = "*" + m.Title + "*\n" + m.Text
+	}
+	return postJSON(s.WebhookURL, map[string]string{"text": text})
+}
+
+// Telegram sends through a bot to a chat.
+type Telegram struct {
+	Token  string
+	ChatID string
+}
+
+func (t Telegram) Send(m Message) error {
+	endpoint := fmt.Sprintf("%s/bot%s/sendMessage", telegramAPI, url.PathEscape(t.Token))
+	return postJSON(endpoint, map[string]string{"chat_id": t.ChatID, "text": m.String()})
+}
+
+// Desktop shows a native notification: notify-send on Linux, Notification
+// Center on macOS, a tray balloon on Windows.
+type Desktop struct{}
+
+func (Desktop) Send(m Message) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(m.Text), appleScriptString(m.Title))
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms
+$n = New-Object System.Windows.Forms.NotifyIcon
+$n.Icon = [System.Drawing.SystemIcons]::Information
+$n.Visible = $true
+$n.ShowBalloonTip(10000, %s, %s, 'Info')
+Start-Sleep -Seconds 5
+$n.Dispose()`, powerShellString(m.Title), powerShellString(m.Text))
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", script)
+	default:
+		urgency := "normal"
+		if !m.OK {
+			urgency = "critical"
+		}
+		cmd = exec.Command("notify-send", "--app-name=aio", "--urgency="+urgency, m.Title, m.Text)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if _, ok := err.(*exec.Error); ok {
+			return fmt.Errorf("no desktop notifier found (%s)", cmd.Path)
+		}
+		return fmt.Errorf("desktop notification failed: %w (%s)", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Stdout prints the message, for terminals and CI logs.
+type Stdout struct{}
+
+func (Stdout) Send(m Message) error {
+	prefix := "[+]"
+	if !m.OK {
+		prefix = "[-]"
+	}
+	fmt.Printf("%s %s\n", prefix, m.Title)
+	if m.Text != "" {
+		fmt.Println(m.Text)
+	}
+	return nil
+}
+
+func postJSON(endpoint string, payload interface{}) error {
+	if offline.Enabled() {
+		return offline.ErrOffline
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		offline.Check(err)
+		// The URL can hold a token, don't echo it
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notification endpoint returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// secretValue reads a channel's secret, failing with a hint when it is unset.
+func secretValue(name string, what string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("channel needs a secret holding its %s", what)
+	}
+	return secrets.Require(name)
+}
+
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,51 @@
+package offline
+
+import (
+	"cli-aio/internal/pkg/config"
+	"time"
+)
+
+// CachedTags are the remote tags of a repository as last listed online.
+type CachedTags struct {
+	Tags      []string  `json:"tags"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// tagCache maps a remote URL to its cached tags.
+type tagCache map[string]CachedTags
+
+func loadTagCache() (tagCache, error) {
+	path, err := config.Path("tag-cache.json")
+	if err != nil {
+		return nil, err
+	}
+	cache := tagCache{}
+	if _, err := config.ReadJSON(path, &cache); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+// SaveTags remembers the remote tags of remote for offline use.
+func SaveTags(remote string, tags []string) error {
+	cache, err := loadTagCache()
+	if err != nil {
+		return err
+	}
+	cache[remote] = CachedTags{Tags: tags, UpdatedAt: time.Now()}
+	path, err := config.Path("tag-cache.json")
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, cache)
+}
+
+// Tags returns the cached remote tags of remote.
+func Tags(remote string) (CachedTags, bool) {
+	cache, err := loadTagCache()
+	if err != nil {
+		return CachedTags{}, false
+	}
+	cached, ok := cache[remote]
+	return cached, ok
+}
@@ -0,0 +1,135 @@
+// Package offline lets commands degrade gracefully without network: remote
+// reads fall back to cached data and mutations are queued for later.
+package offline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Env set to a non-empty value starts every command in offline mode.
+const Env = "AIO_OFFLINE"
+
+// ErrOffline is returned instead of touching the network in offline mode.
+var ErrOffline = errors.New("offline")
+
+var (
+	mu      sync.Mutex
+	enabled *bool
+)
+
+// Set forces offline mode on or off, overriding AIO_OFFLINE.
+func Set(on bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = &on
+}
+
+// Enabled reports whether commands should avoid the network.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if enabled != nil {
+		return *enabled
+	}
+	return os.Getenv(Env) != ""
+}
+
+// networkMessages are lowercase fragments of git, ssh and curl errors caused
+// by an unreachable network (as opposed to auth or server errors).
+var networkMessages = []string{
+	"could not resolve host",
+	"could not resolve hostname",
+	"temporary failure in name resolution",
+	"failed to connect",
+	"connection refused",
+	"connection timed out",
+	"operation timed out",
+	"network is unreachable",
+	"no route to host",
+	"i/o timeout",
+}
+
+// IsNetworkError reports whether err was caused by an unreachable network.
+func IsNetworkError(err error) bool {
+	return Reason(err) != ""
+}
+
+// Reason returns the part of err showing it is a network failure, e.g.
+// "Could not resolve host: gitlab.zalopay.vn", or "" when it isn't one.
+func Reason(err error) string {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, ErrOffline) {
+		return ErrOffline.Error()
+	}
+	// A call cancelled with Ctrl+C says nothing about the network
+	if errors.Is(err, context.Canceled) {
+		return ""
+	}
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	if errors.As(err, &dnsErr) {
+		return dnsErr.Error()
+	}
+	if errors.As(err, &opErr) {
+		return opErr.Error()
+	}
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return netErr.Error()
+	}
+
+	// git and curl report the cause on stderr, captured by exec's Output
+	msg := err.Error()
+	var procErr stderrError
+	var exitErr *exec.ExitError
+	if errors.As(err, &procErr) {
+		msg += "\n" + procErr.ProcessStderr()
+	} else if errors.As(err, &exitErr) {
+		msg += "\n" + string(exitErr.Stderr)
+	}
+	return NetworkMessage(msg)
+}
+
+// stderrError is the failure of a process that keeps what it printed on
+// stderr, like the git package's GitError.
+type stderrError interface {
+	error
+	ProcessStderr() string
+}
+
+// NetworkMessage returns the line of the output of git, ssh or curl saying
+// the network is unreachable, or "" when there is none.
+func NetworkMessage(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		lower := strings.ToLower(line)
+		for _, fragment := range networkMessages {
+			if strings.Contains(lower, fragment) {
+				return strings.TrimSpace(line)
+			}
+		}
+	}
+	return ""
+}
+
+// Check reports whether err is a network failure and, the first time, switches
+// to offline mode so the remaining steps don't wait for timeouts again.
+func Check(err error) bool {
+	reason := Reason(err)
+	if reason == "" {
+		return false
+	}
+	if !Enabled() {
+		Set(true)
+		fmt.Fprintf(os.Stderr, "[!] Network unreachable, continuing offline (%s)\n", reason)
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package offline
+
+import (
+	"cli-aio/internal/pkg/config"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Kinds of queued actions.
+const (
+	ActionPushTag = "push-tag"   // params: tag
+	ActionRelease = "release"    // params: project, tag, description
+	ActionNotify  = "notify"     // params: event, title, text (older entries: secret holding a webhook URL, text)
+	ActionComment = "mr-comment" // params: project_id, iid, reference, body
+)
+
+// Action is a mutation deferred while offline.
+type Action struct {
+	ID          int               `json:"id"`
+	Kind        string            `json:"kind"`
+	Description string            `json:"description"`
+	Dir         string            `json:"dir,omitempty"` // working directory the action was queued in
+	Params      map[string]string `json:"params"`
+	QueuedAt    time.Time         `json:"queued_at"`
+}
+
+// Queue holds the deferred actions persisted in queue.json, oldest first.
+type Queue struct {
+	NextID  int      `json:"next_id"`
+	Actions []Action `json:"actions"`
+}
+
+// LoadQueue reads the queue, returning an empty one when none exists.
+func LoadQueue() (*Queue, error) {
+	path, err := config.Path("queue.json")
+	if err != nil {
+		return nil, err
+	}
+	q := &Queue{}
+	if _, err := config.ReadJSON(path, q); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// SaveQueue writes the queue to disk.
+func SaveQueue(q *Queue) error {
+	path, err := config.Path("queue.json")
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, q)
+}
+
+// Remove deletes the action with id from the queue, reporting whether it was there.
+func (q *Queue) Remove(id int) bool {
+	for i, a := range q.Actions {
+		if a.ID == id {
+			q.Actions = append(q.Actions[:i], q.Actions[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Enqueue appends action to the queue, recording the current directory.
+func Enqueue(action Action) (*Action, error) {
+	q, err := LoadQueue()
+	if err != nil {
+		return nil, err
+	}
+	q.NextID++
+	action.ID = q.NextID
+	action.QueuedAt = time.Now()
+	if action.Dir == "" {
+		action.Dir, _ = os.Getwd()
+	}
+	q.Actions = append(q.Actions, action)
+	if err := SaveQueue(q); err != nil {
+		return nil, err
+	}
+	return &action, nil
+}
+
+// Run calls fn, or queues action instead when offline or when fn fails because
+// the network is unreachable. queued reports whether the action was deferred.
+func Run(action Action, fn func() error) (queued bool, err error) {
+	return RunTo(os.Stdout, action, fn)
+}
+
+// RunTo is Run reporting a queued action to out.
+func RunTo(out io.Writer, action Action, fn func() error) (queued bool, err error) {
+	if !Enabled() {
+		err := fn()
+		if err == nil || !Check(err) {
+			return false, err
+		}
+	}
+	queuedAction, err := Enqueue(action)
+	if err != nil {
+		return false, fmt.Errorf("offline and failed to queue '%s': %w", action.Description, err)
+	}
+	fmt.Fprintf(out, "[!] Offline: queued #%d %s\n", queuedAction.ID, action.Description)
+	return true, nil
+}
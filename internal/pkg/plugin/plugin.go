@@ -0,0 +1,55 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Prefix is the executable name prefix of plugins: 'aio foo' runs 'aio-foo' from PATH.
+const Prefix = "aio-"
+
+// ContextEnv is the environment variable holding the JSON encoded Context.
+const ContextEnv = "AIO_PLUGIN_CONTEXT"
+
+// ContextVersion is bumped whenever Context changes incompatibly.
+const ContextVersion = 1
+
+// Context is passed to plugins so they don't have to rediscover what aio already knows.
+type Context struct {
+	ContextVersion int      `json:"context_version"`
+	AioVersion     string   `json:"aio_version"`
+	Name           string   `json:"name"`
+	Args           []string `json:"args"`
+	Cwd            string   `json:"cwd"`
+	ConfigDir      string   `json:"config_dir"`
+	GitLabURL      string   `json:"gitlab_url"`
+	Interactive    bool     `json:"interactive"`
+}
+
+// Find returns the path of the plugin executable for name, if it is on PATH.
+func Find(name string) (string, bool) {
+	path, err := exec.LookPath(Prefix + name)
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// Run executes the plugin at path with the given context, attached to the terminal.
+func Run(path string, ctx Context) error {
+	data, err := json.Marshal(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to encode plugin context: %w", err)
+	}
+	cmd := exec.Command(path, ctx.Args...)
+	cmd.Env = append(os.Environ(), ContextEnv+"="+string(data))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("plugin %s%s failed: %w", Prefix, ctx.Name, err)
+	}
+	return nil
+}
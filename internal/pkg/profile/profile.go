@@ -0,0 +1,201 @@
+// Package profile times the steps of a command (git processes, GitLab/Jira
+// calls and prompts waiting for an answer) to show where the time of a slow
+// invocation went, and warns when a command goes over its time budget.
+package profile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cli-aio/internal/pkg/config"
+)
+
+// Env set to a non-empty value profiles every command.
+const Env = "AIO_PROFILE"
+
+// Kind is what a step spent its time on.
+type Kind string
+
+// The kinds of steps timed.
+const (
+	Git    Kind = "git"
+	API    Kind = "api"
+	Prompt Kind = "prompt"
+)
+
+// kinds is the order of the breakdown.
+var kinds = []Kind{Git, API, Prompt}
+
+// slowest is how many steps the breakdown lists.
+const slowest = 5
+
+// Step is a timed step of the command.
+type Step struct {
+	Kind     Kind
+	Label    string
+	Duration time.Duration
+}
+
+var (
+	mu      sync.Mutex
+	enabled *bool
+	started time.Time
+	path    []string
+	// totals are kept even when not profiling, for the budget
+	totals map[Kind]time.Duration
+	counts map[Kind]int
+	steps  []Step
+	// out is where the breakdown goes: stderr, away from the values
+	// printed on stdout for scripts.
+	out io.Writer = os.Stderr
+)
+
+// Set forces profiling on or off, overriding AIO_PROFILE.
+func Set(on bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = &on
+}
+
+// Enabled reports whether the breakdown is printed after the command.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return isEnabled()
+}
+
+func isEnabled() bool {
+	if enabled != nil {
+		return *enabled
+	}
+	return os.Getenv(Env) != ""
+}
+
+// Begin starts timing the command given by the arguments after the global
+// flags. Later calls (e.g. the steps of 'aio do') are ignored.
+func Begin(args []string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if !started.IsZero() {
+		return
+	}
+	started = time.Now()
+	totals, counts = map[Kind]time.Duration{}, map[Kind]int{}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		path = append(path, arg)
+	}
+}
+
+// Start times a step, e.g. "git fetch --tags", until the returned function
+// is called:
+//
+//	defer profile.Start(profile.Git, c.String())()
+func Start(kind Kind, label string) func() {
+	begin := time.Now()
+	return func() {
+		elapsed := time.Since(begin)
+		mu.Lock()
+		defer mu.Unlock()
+		if started.IsZero() {
+			return
+		}
+		totals[kind] += elapsed
+		counts[kind]++
+		if isEnabled() {
+			steps = append(steps, Step{Kind: kind, Label: label, Duration: elapsed})
+		}
+	}
+}
+
+// Finish prints the breakdown of the command when profiling, and a warning
+// when it ran longer than its budget (profile.budget in config.json), not
+// counting the time spent waiting at prompts.
+func Finish() {
+	mu.Lock()
+	defer mu.Unlock()
+	if started.IsZero() {
+		return
+	}
+	total := time.Since(started)
+	started = time.Time{}
+	command := "aio " + strings.Join(path, " ")
+	if isEnabled() {
+		report(command, total)
+		return
+	}
+	budget := commandBudget(path)
+	if worked := total - totals[Prompt]; budget > 0 && worked > budget {
+		fmt.Fprintf(out, "[!] %s took %s, over its budget of %s: rerun it with --profile to see where the time went\n",
+			command, round(worked), budget)
+	}
+}
+
+// report prints the time spent per kind of step and the slowest steps.
+func report(command string, total time.Duration) {
+	fmt.Fprintf(out, "[+] Profile of %s: %s\n", command, round(total))
+	var timed time.Duration
+	for _, kind := range kinds {
+		if counts[kind] == 0 {
+			continue
+		}
+		timed += totals[kind]
+		fmt.Fprintf(out, "    %-7s %8s  %d step(s)\n", kind, round(totals[kind]), counts[kind])
+	}
+	if timed > total {
+		// Remote queries run concurrently, their times overlap
+		fmt.Fprintf(out, "    %-7s %8s  (steps ran concurrently)\n", "other", "-")
+	} else {
+		fmt.Fprintf(out, "    %-7s %8s\n", "other", round(total-timed))
+	}
+	if len(steps) == 0 {
+		return
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].Duration > steps[j].Duration })
+	fmt.Fprintln(out, "    Slowest steps:")
+	for i, step := range steps {
+		if i == slowest {
+			break
+		}
+		fmt.Fprintf(out, "    %8s  %-7s %s\n", round(step.Duration), step.Kind, step.Label)
+	}
+	if budget := commandBudget(path); budget > 0 && total-totals[Prompt] > budget {
+		fmt.Fprintf(out, "[!] Over the budget of %s (not counting prompts)\n", budget)
+	}
+}
+
+// commandBudget returns the budget of the command path: the one of the
+// longest command of profile.budget it starts with, else "*". 0 means none.
+func commandBudget(path []string) time.Duration {
+	cfg, err := config.Load()
+	if err != nil || len(cfg.Profile.Budget) == 0 {
+		return 0
+	}
+	value := cfg.Profile.Budget["*"]
+	for n := len(path); n > 0; n-- {
+		if v, ok := cfg.Profile.Budget[strings.Join(path[:n], " ")]; ok {
+			value = v
+			break
+		}
+	}
+	budget, err := time.ParseDuration(value)
+	if err != nil {
+		return 0
+	}
+	return budget
+}
+
+// round rounds d for display: milliseconds under a second, else 10ms.
+func round(d time.Duration) time.Duration {
+	if d < time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(10 * time.Millisecond)
+}
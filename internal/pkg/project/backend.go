@@ -0,0 +1,246 @@
+package project
+
+import (
+	"cli-aio/internal/pkg/config"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultBackend is the backend used when projects.backend is not configured.
+const DefaultBackend = "json"
+
+// Backend persists the project store and its usage history.
+type Backend interface {
+	Load() (*Store, error)
+	// Save writes the store. Backends keeping each project apart (sqlite)
+	// only write what changed since the store was loaded.
+	Save(store *Store) error
+	// RecordVisit notes that the project at path was opened at the given time.
+	RecordVisit(path string, at time.Time) error
+	// Usage returns the visit statistics per project path.
+	Usage() (map[string]Usage, error)
+	Close() error
+}
+
+// Usage is how often and how recently a project was opened.
+type Usage struct {
+	Count int       `json:"count"`
+	Last  time.Time `json:"last"`
+}
+
+// Frecency scores usage by frequency weighted by recency, so a project used
+// a lot last month ranks below one used a few times today.
+func (u Usage) Frecency(now time.Time) float64 {
+	age := now.Sub(u.Last)
+	switch {
+	case age < time.Hour:
+		return float64(u.Count) * 4
+	case age < 24*time.Hour:
+		return float64(u.Count) * 2
+	case age < 7*24*time.Hour:
+		return float64(u.Count) / 2
+	}
+	return float64(u.Count) / 4
+}
+
+// ranker is implemented by backends able to rank projects themselves (sqlite,
+// in SQL) rather than load the whole usage history for Match.
+type ranker interface {
+	// Rank returns the paths of the projects matching query, ranked like
+	// Match over the projects sorted by frecency at now. An empty query
+	// matches every project.
+	Rank(query string, now time.Time) ([]string, error)
+}
+
+// backends maps a backend name to its constructor. Optional backends
+// register themselves from files behind build tags.
+var backends = map[string]func() (Backend, error){
+	DefaultBackend: func() (Backend, error) { return jsonBackend{}, nil },
+}
+
+// The backend opened by OpenBackend, shared by the whole invocation.
+var (
+	openMu sync.Mutex
+	opened Backend
+)
+
+// BackendName returns the configured backend name.
+func BackendName() string {
+	cfg, err := config.Load()
+	if err != nil || cfg.Projects.Backend == "" {
+		return DefaultBackend
+	}
+	return cfg.Projects.Backend
+}
+
+// OpenBackend returns the configured backend, opened on first use and then
+// shared by the rest of the invocation. CloseBackend closes it.
+func OpenBackend() (Backend, error) {
+	openMu.Lock()
+	defer openMu.Unlock()
+	if opened != nil {
+		return opened, nil
+	}
+	name := BackendName()
+	open, ok := backends[name]
+	if !ok {
+		if name == "sqlite" {
+			return nil, fmt.Errorf("the sqlite project backend is not compiled in, rebuild with -tags sqlite")
+		}
+		return nil, fmt.Errorf("unknown project backend: %s", name)
+	}
+	b, err := open()
+	if err != nil {
+		return nil, err
+	}
+	opened = b
+	return b, nil
+}
+
+// CloseBackend closes the backend opened by OpenBackend, if any. The next
+// OpenBackend opens it again.
+func CloseBackend() error {
+	openMu.Lock()
+	defer openMu.Unlock()
+	if opened == nil {
+		return nil
+	}
+	err := opened.Close()
+	opened = nil
+	return err
+}
+
+// Load reads the store from the configured backend.
+func Load() (*Store, error) {
+	b, err := OpenBackend()
+	if err != nil {
+		return nil, err
+	}
+	return b.Load()
+}
+
+// Save writes the store to the configured backend.
+func Save(store *Store) error {
+	b, err := OpenBackend()
+	if err != nil {
+		return err
+	}
+	return b.Save(store)
+}
+
+// RecordVisit notes that the project at path was opened now.
+func RecordVisit(path string) error {
+	b, err := OpenBackend()
+	if err != nil {
+		return err
+	}
+	return b.RecordVisit(path, time.Now())
+}
+
+// SortByFrecency orders projects by frecency, most used first. Projects that
+// were never opened keep their relative order after the used ones.
+func SortByFrecency(projects []Project) error {
+	sorted, err := Search(projects, "")
+	if err != nil {
+		return err
+	}
+	copy(projects, sorted)
+	return nil
+}
+
+// Search returns the projects matching query ranked like Match, the most
+// used first within a rank. An empty query sorts them all by frecency.
+func Search(projects []Project, query string) ([]Project, error) {
+	b, err := OpenBackend()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	r, ok := b.(ranker)
+	if !ok {
+		usage, err := b.Usage()
+		if err != nil {
+			return nil, err
+		}
+		sorted := append([]Project(nil), projects...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return usage[sorted[i].Path].Frecency(now) > usage[sorted[j].Path].Frecency(now)
+		})
+		return Match(sorted, query), nil
+	}
+
+	paths, err := r.Rank(query, now)
+	if err != nil {
+		return nil, err
+	}
+	byPath := make(map[string]Project, len(projects))
+	for _, p := range projects {
+		byPath[p.Path] = p
+	}
+	result := make([]Project, 0, len(projects))
+	for _, path := range paths {
+		if p, ok := byPath[path]; ok {
+			result = append(result, p)
+			delete(byPath, path)
+		}
+	}
+	// Projects the backend doesn't have yet (e.g. added but not saved) are
+	// matched here, after the ranked ones
+	var rest []Project
+	for _, p := range projects {
+		if _, ok := byPath[p.Path]; ok {
+			rest = append(rest, p)
+		}
+	}
+	return append(result, Match(rest, query)...), nil
+}
+
+// savedState is the store as a backend last read or wrote it, so Save can
+// tell what changed.
+type savedState struct {
+	projects map[string]savedProject // by path
+	roots    []string
+}
+
+// savedProject is a stored project: its JSON encoding and its position.
+type savedProject struct {
+	encoded  string
+	position int
+}
+
+// jsonBackend stores projects in projects.json and usage in project-usage.json.
+type jsonBackend struct{}
+
+func (jsonBackend) Load() (*Store, error)   { return loadFile() }
+func (jsonBackend) Save(store *Store) error { return saveFile(store) }
+func (jsonBackend) Close() error            { return nil }
+
+func (jsonBackend) Usage() (map[string]Usage, error) {
+	path, err := config.Path("project-usage.json")
+	if err != nil {
+		return nil, err
+	}
+	usage := map[string]Usage{}
+	if _, err := config.ReadJSON(path, &usage); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+func (b jsonBackend) RecordVisit(projectPath string, at time.Time) error {
+	usage, err := b.Usage()
+	if err != nil {
+		return err
+	}
+	u := usage[projectPath]
+	u.Count++
+	u.Last = at
+	usage[projectPath] = u
+	path, err := config.Path("project-usage.json")
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, usage)
+}
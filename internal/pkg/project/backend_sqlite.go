@@ -0,0 +1,435 @@
+//go:build sqlite
+
+package project
+
+import (
+	"cli-aio/internal/pkg/config"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	backends["sqlite"] = openSQLiteBackend
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS projects (
+	path     TEXT PRIMARY KEY,
+	name     TEXT NOT NULL,
+	position INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS project_tags (
+	path TEXT NOT NULL,
+	tag  TEXT NOT NULL,
+	PRIMARY KEY (path, tag)
+);
+CREATE TABLE IF NOT EXISTS project_actions (
+	path    TEXT NOT NULL,
+	name    TEXT NOT NULL,
+	command TEXT NOT NULL,
+	PRIMARY KEY (path, name)
+);
+CREATE TABLE IF NOT EXISTS project_expiry (
+	path    TEXT PRIMARY KEY,
+	expires INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS project_remote (
+	path           TEXT PRIMARY KEY,
+	name           TEXT NOT NULL,
+	url            TEXT NOT NULL,
+	host           TEXT NOT NULL,
+	grp            TEXT NOT NULL,
+	project_path   TEXT NOT NULL,
+	default_branch TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS git_roots (
+	path     TEXT PRIMARY KEY,
+	position INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS visits (
+	path TEXT NOT NULL,
+	at   INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS visits_path ON visits (path);
+`
+
+// sqliteBackend stores projects and their full visit history in projects.db.
+type sqliteBackend struct {
+	db *sql.DB
+}
+
+func openSQLiteBackend() (Backend, error) {
+	path, err := config.Path("projects.db")
+	if err != nil {
+		return nil, err
+	}
+	_, statErr := os.Stat(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialise %s: %w", path, err)
+	}
+	b := &sqliteBackend{db: db}
+
+	// Import the JSON store the first time the database is created
+	if os.IsNotExist(statErr) {
+		store, err := loadFile()
+		if err == nil && (len(store.Projects) > 0 || len(store.GitRoots) > 0) {
+			if err := b.Save(store); err != nil {
+				db.Close()
+				return nil, fmt.Errorf("failed to import projects.json: %w", err)
+			}
+		}
+	}
+	return b, nil
+}
+
+func (b *sqliteBackend) Close() error {
+	return b.db.Close()
+}
+
+func (b *sqliteBackend) Load() (*Store, error) {
+	store := newStore()
+	rows, err := b.db.Query(`SELECT name, path, position FROM projects ORDER BY position`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read projects: %w", err)
+	}
+	defer rows.Close()
+	var positions []int
+	for rows.Next() {
+		var p Project
+		var position int
+		if err := rows.Scan(&p.Name, &p.Path, &position); err != nil {
+			return nil, fmt.Errorf("failed to read projects: %w", err)
+		}
+		store.Projects = append(store.Projects, p)
+		positions = append(positions, position)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read projects: %w", err)
+	}
+
+	tags, err := b.tags()
+	if err != nil {
+		return nil, err
+	}
+	actions, err := b.actions()
+	if err != nil {
+		return nil, err
+	}
+	expiry, err := b.expiry()
+	if err != nil {
+		return nil, err
+	}
+	remotes, err := b.remotes()
+	if err != nil {
+		return nil, err
+	}
+	for i := range store.Projects {
+		store.Projects[i].Tags = tags[store.Projects[i].Path]
+		store.Projects[i].Actions = actions[store.Projects[i].Path]
+		if expires, ok := expiry[store.Projects[i].Path]; ok {
+			store.Projects[i].Expires = &expires
+		}
+		store.Projects[i].Remote = remotes[store.Projects[i].Path]
+	}
+
+	roots, err := b.db.Query(`SELECT path FROM git_roots ORDER BY position`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git roots: %w", err)
+	}
+	defer roots.Close()
+	for roots.Next() {
+		var root string
+		if err := roots.Scan(&root); err != nil {
+			return nil, fmt.Errorf("failed to read git roots: %w", err)
+		}
+		store.GitRoots = append(store.GitRoots, root)
+	}
+	if err := roots.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read git roots: %w", err)
+	}
+
+	store.saved = &savedState{projects: make(map[string]savedProject, len(store.Projects)), roots: slices.Clone(store.GitRoots)}
+	for i, p := range store.Projects {
+		encoded, err := json.Marshal(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read project %s: %w", p.Path, err)
+		}
+		store.saved.projects[p.Path] = savedProject{encoded: string(encoded), position: positions[i]}
+	}
+	return store, nil
+}
+
+// tags returns the tags per project path.
+func (b *sqliteBackend) tags() (map[string][]string, error) {
+	rows, err := b.db.Query(`SELECT path, tag FROM project_tags ORDER BY tag`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project tags: %w", err)
+	}
+	defer rows.Close()
+	tags := map[string][]string{}
+	for rows.Next() {
+		var path, tag string
+		if err := rows.Scan(&path, &tag); err != nil {
+			return nil, fmt.Errorf("failed to read project tags: %w", err)
+		}
+		tags[path] = append(tags[path], tag)
+	}
+	return tags, rows.Err()
+}
+
+// actions returns the actions per project path.
+func (b *sqliteBackend) actions() (map[string]map[string]string, error) {
+	rows, err := b.db.Query(`SELECT path, name, command FROM project_actions`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project actions: %w", err)
+	}
+	defer rows.Close()
+	actions := map[string]map[string]string{}
+	for rows.Next() {
+		var path, name, command string
+		if err := rows.Scan(&path, &name, &command); err != nil {
+			return nil, fmt.Errorf("failed to read project actions: %w", err)
+		}
+		if actions[path] == nil {
+			actions[path] = map[string]string{}
+		}
+		actions[path][name] = command
+	}
+	return actions, rows.Err()
+}
+
+// expiry returns the expiry per project path.
+func (b *sqliteBackend) expiry() (map[string]time.Time, error) {
+	rows, err := b.db.Query(`SELECT path, expires FROM project_expiry`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project expiry: %w", err)
+	}
+	defer rows.Close()
+	expiry := map[string]time.Time{}
+	for rows.Next() {
+		var path string
+		var expires int64
+		if err := rows.Scan(&path, &expires); err != nil {
+			return nil, fmt.Errorf("failed to read project expiry: %w", err)
+		}
+		expiry[path] = time.Unix(expires, 0)
+	}
+	return expiry, rows.Err()
+}
+
+// remotes returns the remote per project path.
+func (b *sqliteBackend) remotes() (map[string]*Remote, error) {
+	rows, err := b.db.Query(`SELECT path, name, url, host, grp, project_path, default_branch FROM project_remote`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project remotes: %w", err)
+	}
+	defer rows.Close()
+	remotes := map[string]*Remote{}
+	for rows.Next() {
+		var path string
+		var r Remote
+		if err := rows.Scan(&path, &r.Name, &r.URL, &r.Host, &r.Group, &r.ProjectPath, &r.DefaultBranch); err != nil {
+			return nil, fmt.Errorf("failed to read project remotes: %w", err)
+		}
+		remotes[path] = &r
+	}
+	return remotes, rows.Err()
+}
+
+// Save writes the projects and git roots that changed since the store was
+// loaded, so adding or removing one doesn't rewrite thousands. A store that
+// wasn't loaded from the database (e.g. imported from projects.json)
+// replaces its content. Visits are kept.
+func (b *sqliteBackend) Save(store *Store) error {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save projects: %w", err)
+	}
+	defer tx.Rollback()
+
+	saved := store.saved
+	replace := saved == nil
+	if replace {
+		if _, err := tx.Exec(`DELETE FROM projects; DELETE FROM project_tags; DELETE FROM project_actions; DELETE FROM project_expiry; DELETE FROM project_remote; DELETE FROM git_roots;`); err != nil {
+			return fmt.Errorf("failed to save projects: %w", err)
+		}
+		saved = &savedState{}
+	}
+
+	next := &savedState{projects: make(map[string]savedProject, len(store.Projects)), roots: slices.Clone(store.GitRoots)}
+	// Projects keep their position while the order holds, so removing one
+	// doesn't move the others; new ones go after the previous project
+	last := -1
+	for _, p := range store.Projects {
+		encoded, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("failed to save project %s: %w", p.Path, err)
+		}
+		old, ok := saved.projects[p.Path]
+		position := old.position
+		if !ok || position <= last {
+			position = last + 1
+		}
+		last = position
+		if !ok || old.encoded != string(encoded) || old.position != position {
+			if err := addProject(tx, p, position); err != nil {
+				return err
+			}
+		}
+		next.projects[p.Path] = savedProject{encoded: string(encoded), position: position}
+	}
+	for path := range saved.projects {
+		if _, ok := next.projects[path]; !ok {
+			if err := removeProject(tx, path); err != nil {
+				return err
+			}
+		}
+	}
+
+	if replace || !slices.Equal(saved.roots, store.GitRoots) {
+		if _, err := tx.Exec(`DELETE FROM git_roots`); err != nil {
+			return fmt.Errorf("failed to save git roots: %w", err)
+		}
+		for i, root := range store.GitRoots {
+			if _, err := tx.Exec(`INSERT OR REPLACE INTO git_roots (path, position) VALUES (?, ?)`, root, i); err != nil {
+				return fmt.Errorf("failed to save git root %s: %w", root, err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save projects: %w", err)
+	}
+	store.saved = next
+	return nil
+}
+
+// addProject stores p at position, replacing what was stored for its path.
+func addProject(tx *sql.Tx, p Project, position int) error {
+	if err := removeProject(tx, p.Path); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO projects (path, name, position) VALUES (?, ?, ?)`, p.Path, p.Name, position); err != nil {
+		return fmt.Errorf("failed to save project %s: %w", p.Path, err)
+	}
+	for _, tag := range p.Tags {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO project_tags (path, tag) VALUES (?, ?)`, p.Path, tag); err != nil {
+			return fmt.Errorf("failed to save tags of %s: %w", p.Path, err)
+		}
+	}
+	for name, command := range p.Actions {
+		if _, err := tx.Exec(`INSERT INTO project_actions (path, name, command) VALUES (?, ?, ?)`, p.Path, name, command); err != nil {
+			return fmt.Errorf("failed to save actions of %s: %w", p.Path, err)
+		}
+	}
+	if p.Expires != nil {
+		if _, err := tx.Exec(`INSERT INTO project_expiry (path, expires) VALUES (?, ?)`, p.Path, p.Expires.Unix()); err != nil {
+			return fmt.Errorf("failed to save expiry of %s: %w", p.Path, err)
+		}
+	}
+	if r := p.Remote; r != nil {
+		if _, err := tx.Exec(`INSERT INTO project_remote (path, name, url, host, grp, project_path, default_branch) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			p.Path, r.Name, r.URL, r.Host, r.Group, r.ProjectPath, r.DefaultBranch); err != nil {
+			return fmt.Errorf("failed to save remote of %s: %w", p.Path, err)
+		}
+	}
+	return nil
+}
+
+// removeProject deletes the project at path. Its visits are kept, should it
+// be added again.
+func removeProject(tx *sql.Tx, path string) error {
+	for _, table := range []string{"projects", "project_tags", "project_actions", "project_expiry", "project_remote"} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE path = ?`, path); err != nil {
+			return fmt.Errorf("failed to remove project %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func (b *sqliteBackend) RecordVisit(path string, at time.Time) error {
+	if _, err := b.db.Exec(`INSERT INTO visits (path, at) VALUES (?, ?)`, path, at.Unix()); err != nil {
+		return fmt.Errorf("failed to record visit: %w", err)
+	}
+	return nil
+}
+
+func (b *sqliteBackend) Usage() (map[string]Usage, error) {
+	rows, err := b.db.Query(`SELECT path, COUNT(*), MAX(at) FROM visits GROUP BY path`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read visits: %w", err)
+	}
+	defer rows.Close()
+	usage := map[string]Usage{}
+	for rows.Next() {
+		var path string
+		var count int
+		var last int64
+		if err := rows.Scan(&path, &count, &last); err != nil {
+			return nil, fmt.Errorf("failed to read visits: %w", err)
+		}
+		usage[path] = Usage{Count: count, Last: time.Unix(last, 0)}
+	}
+	return usage, rows.Err()
+}
+
+// Rank implements ranker: Match and the frecency sort in one query, without
+// loading the visits. lower() only folds ASCII, unlike Match.
+func (b *sqliteBackend) Rank(query string, now time.Time) ([]string, error) {
+	// The rank of a project is the worst of its words, NULL when one doesn't match
+	rank := "0"
+	var args []any
+	var ranks []string
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		ranks = append(ranks, `CASE WHEN lower(p.name) = ? THEN 1 WHEN instr(lower(p.name), ?) = 1 THEN 2
+			WHEN instr(lower(p.name), ?) > 0 THEN 3 WHEN instr(lower(p.path), ?) > 0 THEN 4 END`)
+		args = append(args, word, word, word, word)
+	}
+	switch len(ranks) {
+	case 0:
+	case 1:
+		rank = ranks[0]
+	default:
+		// max() of several values is NULL when one is
+		rank = "max(" + strings.Join(ranks, ", ") + ")"
+	}
+
+	// Usage.Frecency
+	frecency := `COALESCE(v.n * CASE WHEN ? - v.last < 3600 THEN 4.0 WHEN ? - v.last < 86400 THEN 2.0
+		WHEN ? - v.last < 604800 THEN 0.5 ELSE 0.25 END, 0)`
+	unix := now.Unix()
+	args = append(args, unix, unix, unix)
+
+	rows, err := b.db.Query(`SELECT path FROM (
+		SELECT p.path, p.position, `+rank+` AS rnk, `+frecency+` AS score
+		FROM projects p LEFT JOIN (SELECT path, COUNT(*) AS n, MAX(at) AS last FROM visits GROUP BY path) v ON v.path = p.path
+	) WHERE rnk IS NOT NULL ORDER BY rnk, score DESC, position`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search projects: %w", err)
+	}
+	defer rows.Close()
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, fmt.Errorf("failed to search projects: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
@@ -0,0 +1,128 @@
+//go:build sqlite
+
+package project
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSQLiteSaveWritesChanges(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	b := openSQLiteTest(t)
+	expires := time.Unix(1767225600, 0)
+	initial := &Store{
+		Projects: []Project{
+			{Name: "api", Path: "/work/api", Tags: []string{"svc"}},
+			{Name: "web", Path: "/work/web", Actions: map[string]string{"dev": "npm run dev"}},
+			{Name: "ledger", Path: "/work/ledger", Remote: &Remote{Name: "origin", Host: "gitlab.example.com", ProjectPath: "bank/ledger"}},
+		},
+		GitRoots: []string{"/work"},
+	}
+	if err := b.Save(initial); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := b.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	Remove(store, "/work/web")
+	Add(store, Project{Name: "scratch", Path: "/tmp/scratch", Expires: &expires})
+	store.Projects[0].Tags = append(store.Projects[0].Tags, "go")
+	if err := b.Save(store); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := b.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := projectNames(got.Projects); names != "[api ledger scratch]" {
+		t.Fatalf("projects = %s, want [api ledger scratch]", names)
+	}
+	if tags := got.Projects[0].Tags; len(tags) != 2 {
+		t.Errorf("tags of api = %v, want svc and go", tags)
+	}
+	if r := got.Projects[1].Remote; r == nil || r.ProjectPath != "bank/ledger" {
+		t.Errorf("remote of ledger = %+v", r)
+	}
+	if e := got.Projects[2].Expires; e == nil || !e.Equal(expires) {
+		t.Errorf("expiry of scratch = %v, want %v", e, expires)
+	}
+	if len(got.GitRoots) != 1 {
+		t.Errorf("git roots = %v, want /work", got.GitRoots)
+	}
+
+	// Unchanged projects keep their row: ledger stays where it was saved
+	// rather than moving up in place of web
+	positions := map[string]int{}
+	for path, saved := range got.saved.projects {
+		positions[path] = saved.position
+	}
+	if positions["/work/api"] != 0 || positions["/work/ledger"] != 2 || positions["/tmp/scratch"] != 3 {
+		t.Errorf("positions = %v, want api 0, ledger 2 and scratch 3", positions)
+	}
+	var actions int
+	if err := b.db.QueryRow(`SELECT COUNT(*) FROM project_actions`).Scan(&actions); err != nil || actions != 0 {
+		t.Errorf("actions of the removed project left behind: %d, %v", actions, err)
+	}
+}
+
+// A store that wasn't loaded from the database replaces its content.
+func TestSQLiteSaveReplaces(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	b := openSQLiteTest(t)
+	if err := b.Save(&Store{Projects: []Project{{Name: "api", Path: "/work/api"}}, GitRoots: []string{"/work"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Save(newStore()); err != nil {
+		t.Fatal(err)
+	}
+	store, err := b.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(store.Projects) != 0 || len(store.GitRoots) != 0 {
+		t.Errorf("store = %+v, want it empty", store)
+	}
+}
+
+// Rank gives in SQL the order Search gives with the JSON backend.
+func TestSQLiteRank(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	b := openSQLiteTest(t)
+	if err := b.Save(&Store{Projects: searchProjects(), GitRoots: []string{}}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	recordSearchVisits(t, b, now)
+
+	byPath := map[string]Project{}
+	for _, p := range searchProjects() {
+		byPath[p.Path] = p
+	}
+	for _, tt := range searchTests {
+		paths, err := b.Rank(tt.query, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ranked := []Project{}
+		for _, path := range paths {
+			ranked = append(ranked, byPath[path])
+		}
+		if names := projectNames(ranked); names != tt.want {
+			t.Errorf("Rank(%q) = %s, want %s", tt.query, names, tt.want)
+		}
+	}
+}
+
+func openSQLiteTest(t *testing.T) *sqliteBackend {
+	t.Helper()
+	b, err := openSQLiteBackend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return b.(*sqliteBackend)
+}
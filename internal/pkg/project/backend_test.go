@@ -0,0 +1,100 @@
+package project
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestOpenBackendIsShared(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(func() { CloseBackend() })
+	first, err := OpenBackend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := OpenBackend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("OpenBackend opened the backend twice")
+	}
+	if err := CloseBackend(); err != nil {
+		t.Fatal(err)
+	}
+	if err := CloseBackend(); err != nil {
+		t.Errorf("closing twice: %v", err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(func() { CloseBackend() })
+	projects := searchProjects()
+	b, err := OpenBackend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordSearchVisits(t, b, time.Now())
+
+	for _, tt := range searchTests {
+		got, err := Search(projects, tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if names := projectNames(got); names != tt.want {
+			t.Errorf("Search(%q) = %s, want %s", tt.query, names, tt.want)
+		}
+	}
+}
+
+// searchProjects are the projects of the Search tests, in store order.
+func searchProjects() []Project {
+	return []Project{
+		{Name: "payment-api", Path: "/work/bank/payment-api"},
+		{Name: "api", Path: "/work/bank/api"},
+		{Name: "ledger", Path: "/work/bank/ledger"},
+		{Name: "web", Path: "/work/api-clients/web"},
+		{Name: "api-gateway", Path: "/work/ops/api-gateway"},
+	}
+}
+
+// recordSearchVisits opens ledger often but long ago, api-gateway a few times
+// today and web once, an hour ago.
+func recordSearchVisits(t *testing.T, b Backend, now time.Time) {
+	t.Helper()
+	visits := map[string][]time.Duration{
+		"/work/bank/ledger":     {30 * 24 * time.Hour, 31 * 24 * time.Hour, 32 * 24 * time.Hour, 33 * 24 * time.Hour},
+		"/work/ops/api-gateway": {time.Minute, 2 * time.Minute},
+		"/work/api-clients/web": {2 * time.Hour},
+	}
+	for path, ages := range visits {
+		for _, age := range ages {
+			if err := b.RecordVisit(path, now.Add(-age)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+var searchTests = []struct {
+	query string
+	want  string
+}{
+	// Frecency: 8 for api-gateway, 2 for web, 1 for ledger
+	{"", "[api-gateway web ledger payment-api api]"},
+	// Exact name, name prefix, name substring, then path
+	{"api", "[api api-gateway payment-api web]"},
+	// The worst word ranks: both only match bank in their path
+	{"API bank", "[payment-api api]"},
+	{"ledger missing", "[]"},
+}
+
+func projectNames(projects []Project) string {
+	names := make([]string, len(projects))
+	for i, p := range projects {
+		names[i] = p.Name
+	}
+	return fmt.Sprint(names)
+}
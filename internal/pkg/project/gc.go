@@ -0,0 +1,49 @@
+package project
+
+import (
+	"cli-aio/internal/pkg/git"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ScratchTag labels throwaway projects, which 'prj gc' may delete.
+const ScratchTag = "scratch"
+
+// Disposable reports whether p is subject to garbage collection: tagged
+// scratch or with an expiry.
+func (p Project) Disposable() bool {
+	return p.HasTag(ScratchTag) || p.Expires != nil
+}
+
+// DeleteBlocker returns why the directory at path must not be deleted, ""
+// when it may be. Repositories with commits no remote has are protected, and
+// so are the ones that can't be checked.
+func DeleteBlocker(ctx context.Context, path string) string {
+	if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+		return ""
+	}
+	unpushed, err := git.UnpushedCommitsIn(ctx, path)
+	if err != nil {
+		return "cannot check for unpushed commits"
+	}
+	if unpushed > 0 {
+		return fmt.Sprintf("%d unpushed commit(s)", unpushed)
+	}
+	return ""
+}
+
+// Delete removes the directory of the project at path and its store entry.
+func Delete(store *Store, path string) error {
+	home, _ := os.UserHomeDir()
+	clean := filepath.Clean(path)
+	if !filepath.IsAbs(clean) || clean == filepath.Dir(clean) || (home != "" && pathKey(clean) == pathKey(NormalizePath(home))) {
+		return fmt.Errorf("refusing to delete %s", path)
+	}
+	if err := os.RemoveAll(clean); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", path, err)
+	}
+	Remove(store, path)
+	return nil
+}
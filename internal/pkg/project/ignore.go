@@ -0,0 +1,141 @@
+package project
+
+import (
+	"bufio"
+	"cli-aio/internal/pkg/config"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile is the file at a git root listing directories FindGitRepos skips.
+const IgnoreFile = ".prjignore"
+
+// ignoreMatcher matches directories against .prjignore patterns, a subset of
+// .gitignore:
+//
+//	archived        a directory named archived, at any depth
+//	build-*         any directory whose name matches the glob
+//	/tmp            tmp at the root only
+//	clients/legacy  a path relative to the root (a leading / is optional)
+//	**/cache        cache at any depth, like a name
+//	vendor/**       everything under vendor
+//	!build-keep     not ignored even though build-* matched it
+//
+// Blank lines and lines starting with # are ignored, a trailing / is allowed
+// (every match is a directory). The last pattern matching a directory
+// decides; as with git, a directory under an ignored one can't be brought
+// back, as the scan doesn't enter the ignored one.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// ignoreRule is one pattern, split on slashes.
+type ignoreRule struct {
+	segments []string
+	// anchored rules match the path relative to the root, the others the name
+	anchored bool
+	negate   bool
+}
+
+// loadIgnore reads root/.prjignore and the projects.ignore patterns from config.json.
+func loadIgnore(root string) (*ignoreMatcher, error) {
+	m := &ignoreMatcher{}
+	if cfg, err := config.Load(); err == nil {
+		for _, pattern := range cfg.Projects.Ignore {
+			if err := m.add(pattern); err != nil {
+				return nil, fmt.Errorf("invalid projects.ignore pattern in config.json: %w", err)
+			}
+		}
+	}
+
+	f, err := os.Open(filepath.Join(root, IgnoreFile))
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", IgnoreFile, err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if err := m.add(scanner.Text()); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", filepath.Join(root, IgnoreFile), line, err)
+		}
+	}
+	return m, scanner.Err()
+}
+
+func (m *ignoreMatcher) add(pattern string) error {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return nil
+	}
+	raw := pattern
+	var rule ignoreRule
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	}
+	pattern = strings.TrimSuffix(pattern, "/")
+	// Like git, a slash at the start or in the middle anchors the pattern
+	rule.anchored = strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if pattern == "" {
+		return fmt.Errorf("bad pattern %q: nothing to match", raw)
+	}
+	rule.segments = strings.Split(pattern, "/")
+	for _, segment := range rule.segments {
+		// Reject malformed globs up front instead of silently never matching
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("bad pattern %q: %w", raw, err)
+		}
+	}
+	m.rules = append(m.rules, rule)
+	return nil
+}
+
+// match reports whether the directory at rel (slash-separated, relative to the root) is ignored.
+func (m *ignoreMatcher) match(rel string) bool {
+	ignored := false
+	segments := strings.Split(rel, "/")
+	for _, rule := range m.rules {
+		matched := false
+		if rule.anchored {
+			matched = matchSegments(rule.segments, segments)
+		} else {
+			matched = matchSegments(rule.segments, segments[len(segments)-1:])
+		}
+		if matched {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// matchSegments matches the path segments against the glob segments of a
+// pattern, where ** stands for any number of segments: none at the start or
+// in the middle (**/cache, a/**/b), at least one at the end (vendor/**).
+func matchSegments(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		if len(pattern) == 1 {
+			return len(segments) > 0
+		}
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], segments[0])
+	return ok && matchSegments(pattern[1:], segments[1:])
+}
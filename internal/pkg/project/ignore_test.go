@@ -0,0 +1,121 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIgnoreMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		ignored  []string
+		kept     []string
+	}{
+		{"name at any depth", []string{"archived"},
+			[]string{"archived", "team/archived", "a/b/archived"},
+			[]string{"archived-2024", "team/archive", "archived2"}},
+		{"star in a name", []string{"build-*"},
+			[]string{"build-cache", "svc/build-", "svc/build-out"},
+			[]string{"build", "svc/rebuild-x"}},
+		{"star does not cross slashes", []string{"clients/*"},
+			[]string{"clients/legacy", "clients/new"},
+			[]string{"clients", "clients/legacy/app", "other/clients/legacy"}},
+		{"double star at the start", []string{"**/cache"},
+			[]string{"cache", "svc/cache", "a/b/c/cache"},
+			[]string{"cache-old", "cache/x"}},
+		{"double star in the middle", []string{"clients/**/legacy"},
+			[]string{"clients/legacy", "clients/a/legacy", "clients/a/b/legacy"},
+			[]string{"legacy", "other/clients/legacy", "clients/legacy-app"}},
+		{"double star at the end", []string{"vendor/**"},
+			[]string{"vendor/github.com", "vendor/a/b"},
+			[]string{"vendor", "svc/vendor/a"}},
+		{"negation", []string{"build-*", "!build-keep"},
+			[]string{"build-cache", "svc/build-tmp"},
+			[]string{"build-keep", "svc/build-keep"}},
+		{"last match wins", []string{"!build-keep", "build-*"},
+			[]string{"build-keep", "build-cache"},
+			nil},
+		{"negated path", []string{"clients/*", "!clients/current"},
+			[]string{"clients/legacy"},
+			[]string{"clients/current"}},
+		{"dir-only", []string{"archived/", "clients/legacy/"},
+			[]string{"archived", "x/archived", "clients/legacy"},
+			[]string{"clients/legacy/app"}},
+		{"anchored name", []string{"/tmp"},
+			[]string{"tmp"},
+			[]string{"svc/tmp", "tmp2"}},
+		{"anchored path", []string{"/clients/legacy", "clients/old"},
+			[]string{"clients/legacy", "clients/old"},
+			[]string{"team/clients/legacy", "team/clients/old"}},
+		{"comments and blanks", []string{"# archived", "", "   "},
+			nil,
+			[]string{"archived", "# archived"}},
+		{"character class", []string{"v[0-9]"},
+			[]string{"v1", "old/v2"},
+			[]string{"v10", "vx"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &ignoreMatcher{}
+			for _, pattern := range tt.patterns {
+				if err := m.add(pattern); err != nil {
+					t.Fatalf("add(%q): %v", pattern, err)
+				}
+			}
+			for _, rel := range tt.ignored {
+				if !m.match(rel) {
+					t.Errorf("%v should ignore %s", tt.patterns, rel)
+				}
+			}
+			for _, rel := range tt.kept {
+				if m.match(rel) {
+					t.Errorf("%v should not ignore %s", tt.patterns, rel)
+				}
+			}
+		})
+	}
+}
+
+func TestIgnoreBadPattern(t *testing.T) {
+	for _, pattern := range []string{"build-[", "clients/[a-", "/", "!"} {
+		if err := (&ignoreMatcher{}).add(pattern); err == nil {
+			t.Errorf("add(%q) should fail", pattern)
+		}
+	}
+}
+
+func TestLoadIgnore(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, IgnoreFile), []byte("# skipped\narchived/\n!archived/keep\nbuild-*\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := loadIgnore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.match("svc/archived") || !m.match("build-x") || m.match("svc") {
+		t.Errorf("patterns of %s not applied: %+v", IgnoreFile, m.rules)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, IgnoreFile), []byte("ok\nbad-[\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadIgnore(root); err == nil || !strings.Contains(err.Error(), IgnoreFile+":2:") {
+		t.Errorf("loadIgnore error = %v, want the line of the bad pattern", err)
+	}
+}
+
+func TestLoadIgnoreMissingFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m, err := loadIgnore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.match("archived") {
+		t.Error("nothing should be ignored without patterns")
+	}
+}
@@ -0,0 +1,87 @@
+package project
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Match ranks the projects matching query, best first: exact name, name
+// prefix, name substring, then path substring (case-insensitive). Within a
+// rank the input order is kept, so pass projects sorted by frecency. Several
+// space-separated words must all match.
+func Match(projects []Project, query string) []Project {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return projects
+	}
+
+	type ranked struct {
+		project Project
+		rank    int
+	}
+	var matches []ranked
+	for _, p := range projects {
+		name := strings.ToLower(p.Name)
+		path := strings.ToLower(p.Path)
+		worst := 0
+		for _, word := range words {
+			rank := 0
+			switch {
+			case name == word:
+				rank = 1
+			case strings.HasPrefix(name, word):
+				rank = 2
+			case strings.Contains(name, word):
+				rank = 3
+			case strings.Contains(path, word):
+				rank = 4
+			}
+			if rank == 0 {
+				worst = 0
+				break
+			}
+			if rank > worst {
+				worst = rank
+			}
+		}
+		if worst > 0 {
+			matches = append(matches, ranked{p, worst})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].rank < matches[j].rank })
+
+	result := make([]Project, len(matches))
+	for i, m := range matches {
+		result[i] = m.project
+	}
+	return result
+}
+
+// Previous returns the path of the most recently opened project that doesn't
+// contain cwd, i.e. where 'prj -' goes back to. Empty when there is none.
+func Previous(cwd string) (string, error) {
+	b, err := OpenBackend()
+	if err != nil {
+		return "", err
+	}
+	usage, err := b.Usage()
+	if err != nil {
+		return "", err
+	}
+
+	cwd = pathKey(NormalizePath(cwd))
+	best := ""
+	var bestAt time.Time
+	for path, u := range usage {
+		key := pathKey(path)
+		if cwd == key || strings.HasPrefix(cwd, key+string(filepath.Separator)) {
+			continue
+		}
+		if best == "" || u.Last.After(bestAt) {
+			best, bestAt = path, u.Last
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,83 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion is the projects.json schema written by this version.
+// Bump it together with a new entry in migrations when the format changes.
+const SchemaVersion = 1
+
+// migration upgrades the raw projects.json of schema version from to from+1.
+// Migrations work on raw JSON because older formats may not fit the current Store.
+type migration struct {
+	from        int
+	description string
+	apply       func(raw []byte) ([]byte, error)
+}
+
+var migrations = []migration{
+	{0, "wrap the legacy project list into a store with git roots", migrateV0},
+}
+
+// schemaVersion detects the schema version of raw projects.json content.
+// Files written before versioning (a bare project list, or a store without
+// schema_version) are version 0.
+func schemaVersion(raw []byte) (int, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		return 0, nil
+	}
+	var header struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return 0, fmt.Errorf("failed to parse projects file: %w", err)
+	}
+	return header.SchemaVersion, nil
+}
+
+// migrate upgrades raw to the current schema. It returns the upgraded content
+// and the version it started from.
+func migrate(raw []byte) ([]byte, int, error) {
+	from, err := schemaVersion(raw)
+	if err != nil {
+		return nil, 0, err
+	}
+	if from > SchemaVersion {
+		return nil, from, fmt.Errorf("projects file has schema version %d, this aio only supports up to %d (please upgrade aio)", from, SchemaVersion)
+	}
+
+	version := from
+	for _, m := range migrations {
+		if m.from != version {
+			continue
+		}
+		if raw, err = m.apply(raw); err != nil {
+			return nil, from, fmt.Errorf("failed to migrate projects file from schema version %d (%s): %w", m.from, m.description, err)
+		}
+		version++
+	}
+	if version != SchemaVersion {
+		return nil, from, fmt.Errorf("no migration from projects schema version %d", version)
+	}
+	return raw, from, nil
+}
+
+// migrateV0 turns the legacy []Project list, or an unversioned store, into a versioned store.
+func migrateV0(raw []byte) ([]byte, error) {
+	store := map[string]interface{}{}
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		var projects []Project
+		if err := json.Unmarshal(raw, &projects); err != nil {
+			return nil, err
+		}
+		store["projects"] = projects
+		store["git_roots"] = []string{}
+	} else if err := json.Unmarshal(raw, &store); err != nil {
+		return nil, err
+	}
+	store["schema_version"] = 1
+	return json.Marshal(store)
+}
@@ -0,0 +1,83 @@
+package project
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// NormalizePath returns the canonical form of a project path: absolute,
+// cleaned (no trailing separator) and with symlinks resolved. Paths that
+// don't exist (anymore) are only cleaned.
+func NormalizePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return filepath.Clean(path)
+}
+
+// pathKey is the key paths are compared by. The default macOS and Windows
+// filesystems are case-insensitive, so there ~/Work/App and ~/work/app are
+// the same project.
+func pathKey(path string) string {
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		return strings.ToLower(path)
+	}
+	return path
+}
+
+// Dedupe normalizes all project paths and git roots and merges duplicates,
+// keeping the first entry (and the union of the tags). It returns the number
+// of paths rewritten and the duplicate entries removed.
+func Dedupe(store *Store) (int, []Project) {
+	normalized := 0
+	var removed []Project
+	seen := map[string]int{}
+	projects := make([]Project, 0, len(store.Projects))
+	for _, p := range store.Projects {
+		if path := NormalizePath(p.Path); path != p.Path {
+			p.Path = path
+			normalized++
+		}
+		key := pathKey(p.Path)
+		if i, ok := seen[key]; ok {
+			for _, tag := range p.Tags {
+				if !projects[i].HasTag(tag) {
+					projects[i].Tags = append(projects[i].Tags, tag)
+				}
+			}
+			for name, command := range p.Actions {
+				if _, ok := projects[i].Actions[name]; !ok {
+					if projects[i].Actions == nil {
+						projects[i].Actions = map[string]string{}
+					}
+					projects[i].Actions[name] = command
+				}
+			}
+			removed = append(removed, p)
+			continue
+		}
+		seen[key] = len(projects)
+		projects = append(projects, p)
+	}
+	store.Projects = projects
+
+	roots := make([]string, 0, len(store.GitRoots))
+	seenRoots := map[string]bool{}
+	for _, root := range store.GitRoots {
+		if path := NormalizePath(root); path != root {
+			root = path
+			normalized++
+		}
+		if !seenRoots[pathKey(root)] {
+			seenRoots[pathKey(root)] = true
+			roots = append(roots, root)
+		}
+	}
+	store.GitRoots = roots
+	return normalized, removed
+}
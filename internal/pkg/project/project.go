@@ -2,35 +2,92 @@ package project
 
 import (
 	"bytes"
+	"cli-aio/internal/pkg/config"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 // Project represents a saved project entry.
 type Project struct {
-	Name string `json:"name"` // folder base name
-	Path string `json:"path"` // absolute path
+	Name string   `json:"name"`           // folder base name
+	Path string   `json:"path"`           // absolute path
+	Tags []string `json:"tags,omitempty"` // user labels, e.g. "svc", used to filter lists
+	// Actions are bookmarked shell commands run in the project folder by 'prj act', by name.
+	Actions map[string]string `json:"actions,omitempty"`
+	// Expires is when a throwaway project (e.g. from 'aio scratch') may be deleted.
+	Expires *time.Time `json:"expires,omitempty"`
+	// Remote is the git remote of the project, captured by 'prj add' and
+	// 'prj git-add'/'git-refresh'. nil for folders without a remote.
+	Remote *Remote `json:"remote,omitempty"`
+}
+
+// Expired reports whether the project has an expiry that has passed.
+func (p Project) Expired(now time.Time) bool {
+	return p.Expires != nil && !p.Expires.After(now)
+}
+
+// HasTag reports whether the project is labelled with tag.
+func (p Project) HasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterByTags returns the projects labelled with all of tags.
+func FilterByTags(projects []Project, tags []string) []Project {
+	if len(tags) == 0 {
+		return projects
+	}
+	var result []Project
+	for _, p := range projects {
+		matches := true
+		for _, tag := range tags {
+			if !p.HasTag(tag) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			result = append(result, p)
+		}
+	}
+	return result
 }
 
 // Store holds the overall project state.
 type Store struct {
-	Projects []Project `json:"projects"`
-	GitRoots []string  `json:"git_roots"`
+	SchemaVersion int       `json:"schema_version"`
+	Projects      []Project `json:"projects"`
+	GitRoots      []string  `json:"git_roots"`
+	// saved is set by the backends writing only the projects that changed.
+	saved *savedState
 }
 
 // ConfigPath returns the path to the projects config file.
 func ConfigPath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	return config.Path("projects.json")
+}
+
+// newStore returns an empty store at the current schema version.
+func newStore() *Store {
+	return &Store{
+		SchemaVersion: SchemaVersion,
+		Projects:      []Project{},
+		GitRoots:      []string{},
 	}
-	return filepath.Join(home, ".config", "cli-aio", "projects.json"), nil
 }
 
-// Load reads the store from disk.
-func Load() (*Store, error) {
+// loadFile reads the store from projects.json. Stores written by older
+// versions are migrated to the current schema and saved back.
+func loadFile() (*Store, error) {
 	path, err := ConfigPath()
 	if err != nil {
 		return nil, err
@@ -38,10 +95,7 @@ func Load() (*Store, error) {
 
 	data, err := os.ReadFile(path)
 	if os.IsNotExist(err) {
-		return &Store{
-			Projects: []Project{},
-			GitRoots: []string{},
-		}, nil
+		return newStore(), nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read projects file: %w", err)
@@ -49,40 +103,40 @@ func Load() (*Store, error) {
 
 	// Treat an empty file the same as an absent one
 	if len(bytes.TrimSpace(data)) == 0 {
-		return &Store{
-			Projects: []Project{},
-			GitRoots: []string{},
-		}, nil
+		return newStore(), nil
 	}
 
-	// Try parsing as the new Store format
-	var store Store
-	if err := json.Unmarshal(data, &store); err == nil && (len(store.Projects) > 0 || len(store.GitRoots) > 0) {
-		// New format successfully parsed (and not just an empty object)
-		if store.Projects == nil {
-			store.Projects = []Project{}
-		}
-		if store.GitRoots == nil {
-			store.GitRoots = []string{}
-		}
-		return &store, nil
+	migrated, from, err := migrate(data)
+	if err != nil {
+		return nil, err
 	}
 
-	// Fallback: parse as the old []Project format
-	var projects []Project
-	if err := json.Unmarshal(data, &projects); err != nil {
+	var store Store
+	if err := json.Unmarshal(migrated, &store); err != nil {
 		return nil, fmt.Errorf("failed to parse projects file: %w", err)
 	}
+	if store.Projects == nil {
+		store.Projects = []Project{}
+	}
+	if store.GitRoots == nil {
+		store.GitRoots = []string{}
+	}
 
-	// Return a new Store containing the old projects
-	return &Store{
-		Projects: projects,
-		GitRoots: []string{},
-	}, nil
+	if from != SchemaVersion {
+		// Keep the original next to the upgraded file in case something went wrong
+		backup := fmt.Sprintf("%s.v%d.bak", path, from)
+		if err := os.WriteFile(backup, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to back up projects file: %w", err)
+		}
+		if err := saveFile(&store); err != nil {
+			return nil, err
+		}
+	}
+	return &store, nil
 }
 
-// Save writes the store to disk.
-func Save(store *Store) error {
+// saveFile writes the store to projects.json.
+func saveFile(store *Store) error {
 	path, err := ConfigPath()
 	if err != nil {
 		return err
@@ -92,6 +146,7 @@ func Save(store *Store) error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	store.SchemaVersion = SchemaVersion
 	data, err := json.MarshalIndent(store, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal store: %w", err)
@@ -103,23 +158,63 @@ func Save(store *Store) error {
 	return nil
 }
 
-// Add appends a project to the project list if it doesn't already exist (by path).
+// Add appends a project to the project list if it doesn't already exist.
+// The path is normalized first (see NormalizePath), so the same repository
+// reached through a symlink or with a trailing separator isn't added twice.
 // Returns true if the project was newly added, false if it already existed.
 func Add(store *Store, p Project) bool {
-	for _, existing := range store.Projects {
-		if existing.Path == p.Path {
-			return false
-		}
+	p.Path = NormalizePath(p.Path)
+	if Find(store, p.Path) >= 0 {
+		return false
 	}
 	store.Projects = append(store.Projects, p)
 	return true
 }
 
+// Find returns the index of the project at path, or -1.
+func Find(store *Store, path string) int {
+	key := pathKey(NormalizePath(path))
+	for i, existing := range store.Projects {
+		if pathKey(existing.Path) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Remove deletes the project at path from the store.
+// Returns true if it was found.
+func Remove(store *Store, path string) bool {
+	i := Find(store, path)
+	if i < 0 {
+		return false
+	}
+	store.Projects = append(store.Projects[:i], store.Projects[i+1:]...)
+	return true
+}
+
+// Containing returns the index of the innermost project containing dir, or -1.
+func Containing(store *Store, dir string) int {
+	key := pathKey(NormalizePath(dir))
+	best := -1
+	for i, p := range store.Projects {
+		projectKey := pathKey(p.Path)
+		if key != projectKey && !strings.HasPrefix(key, projectKey+string(filepath.Separator)) {
+			continue
+		}
+		if best < 0 || len(p.Path) > len(store.Projects[best].Path) {
+			best = i
+		}
+	}
+	return best
+}
+
 // AddGitRoot appends a git root to the list if it doesn't already exist.
 // Returns true if the root was newly added, false if it already existed.
 func AddGitRoot(store *Store, gitRoot string) bool {
+	gitRoot = NormalizePath(gitRoot)
 	for _, existing := range store.GitRoots {
-		if existing == gitRoot {
+		if pathKey(existing) == pathKey(gitRoot) {
 			return false
 		}
 	}
@@ -129,11 +224,20 @@ func AddGitRoot(store *Store, gitRoot string) bool {
 
 // FindGitRepos recursively walks root and returns every directory that
 // contains a .git entry. It does not descend further into a found repo
-// (avoids counting submodules / nested repos separately).
-func FindGitRepos(root string) ([]string, error) {
+// (avoids counting submodules / nested repos separately). Directories matching
+// root/.prjignore or the projects.ignore config patterns are skipped. The walk
+// stops with ctx's error once ctx is done.
+func FindGitRepos(ctx context.Context, root string) ([]string, error) {
 	var repos []string
+	ignore, err := loadIgnore(root)
+	if err != nil {
+		return nil, err
+	}
 
-	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if err != nil {
 			// Skip directories we can't read (permissions, etc.)
 			return filepath.SkipDir
@@ -145,6 +249,11 @@ func FindGitRepos(root string) ([]string, error) {
 		if path != root && d.Name() != "." && len(d.Name()) > 0 && d.Name()[0] == '.' {
 			return filepath.SkipDir
 		}
+		if path != root {
+			if rel, err := filepath.Rel(root, path); err == nil && ignore.match(filepath.ToSlash(rel)) {
+				return filepath.SkipDir
+			}
+		}
 
 		gitPath := filepath.Join(path, ".git")
 		if _, err := os.Stat(gitPath); err == nil {
@@ -0,0 +1,138 @@
+package project
+
+import (
+	"cli-aio/internal/pkg/crash"
+	"cli-aio/internal/pkg/git"
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Remote is the git remote of a project, stored so selectors and API lookups
+// don't run git in every project.
+type Remote struct {
+	Name          string `json:"name"` // origin, or the first remote without one
+	URL           string `json:"url"`
+	Host          string `json:"host,omitempty"`           // e.g. gitlab.zalopay.vn, empty for a local path
+	Group         string `json:"group,omitempty"`          // namespace, e.g. bank/operation
+	ProjectPath   string `json:"project_path,omitempty"`   // group and project, e.g. bank/operation/app
+	DefaultBranch string `json:"default_branch,omitempty"` // branch origin/HEAD points to
+}
+
+// WebURL returns the browser URL of the project, "" when the remote isn't on a server.
+func (r *Remote) WebURL() string {
+	if r == nil || r.Host == "" {
+		return ""
+	}
+	return "https://" + r.Host + "/" + r.ProjectPath
+}
+
+// DetectRemote reads the remote of the repository at dir, nil when it has none.
+func DetectRemote(ctx context.Context, dir string) *Remote {
+	remotes, err := git.RemotesIn(ctx, dir)
+	if err != nil || len(remotes) == 0 {
+		return nil
+	}
+	name := remotes[0]
+	for _, r := range remotes {
+		if r == "origin" {
+			name = r
+		}
+	}
+	url, err := git.RemoteURLIn(ctx, dir, name)
+	if err != nil {
+		return nil
+	}
+	remote := &Remote{Name: name, URL: url}
+	if parsed, err := git.ParseRemoteURL(url); err == nil {
+		remote.Host = parsed.Host
+		remote.ProjectPath = parsed.Path
+		if group := path.Dir(parsed.Path); group != "." {
+			remote.Group = group
+		}
+	}
+	if name == "origin" {
+		remote.DefaultBranch = git.DefaultBranchIn(ctx, dir)
+	}
+	return remote
+}
+
+// DetectRemotes sets the Remote of every project concurrently. Once ctx is
+// done the remaining projects keep theirs.
+func DetectRemotes(ctx context.Context, projects []Project) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < scanWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer crash.Guard()
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() == nil {
+					projects[i].Remote = DetectRemote(ctx, projects[i].Path)
+				}
+			}
+		}()
+	}
+	for i := range projects {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// UpdateRemote sets the remote of the saved project at path, reporting
+// whether it changed.
+func UpdateRemote(store *Store, path string, remote *Remote) bool {
+	i := Find(store, path)
+	if i < 0 {
+		return false
+	}
+	old := store.Projects[i].Remote
+	if (old == nil && remote == nil) || (old != nil && remote != nil && *old == *remote) {
+		return false
+	}
+	store.Projects[i].Remote = remote
+	return true
+}
+
+// Org returns the group (GitLab) or organization (GitHub) of the project's
+// remote, "" when no remote was recorded.
+func (p Project) Org() string {
+	if p.Remote == nil {
+		return ""
+	}
+	return p.Remote.Group
+}
+
+// FilterByOrg returns the projects of org or of its subgroups, ignoring case.
+func FilterByOrg(projects []Project, org string) []Project {
+	org = strings.ToLower(strings.Trim(org, "/"))
+	var result []Project
+	for _, p := range projects {
+		if o := strings.ToLower(p.Org()); o == org || strings.HasPrefix(o, org+"/") {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// GroupByOrg reorders projects so those of an org are together. Orgs come
+// in the order of their first project, keeping a ranking such as frecency,
+// and projects without org come last.
+func GroupByOrg(projects []Project) {
+	rank := map[string]int{}
+	for _, p := range projects {
+		if _, ok := rank[p.Org()]; !ok {
+			rank[p.Org()] = len(rank)
+		}
+	}
+	if _, ok := rank[""]; ok {
+		rank[""] = len(rank)
+	}
+	sort.SliceStable(projects, func(i, j int) bool {
+		return rank[projects[i].Org()] < rank[projects[j].Org()]
+	})
+}
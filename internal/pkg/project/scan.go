@@ -0,0 +1,61 @@
+package project
+
+import (
+	"cli-aio/internal/pkg/crash"
+	"cli-aio/internal/pkg/git"
+	"context"
+	"os"
+	"sync"
+)
+
+// scanWorkers bounds the number of concurrent git processes.
+const scanWorkers = 8
+
+// RepoState is the git status of a saved project. Error is a string so states
+// can be cached by the daemon.
+type RepoState struct {
+	Path   string         `json:"path"`
+	Status git.RepoStatus `json:"status"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// ErrNotScanned is the Error of projects left out because the scan was cancelled.
+const ErrNotScanned = "not scanned (interrupted)"
+
+// Scan gets the git status of every project concurrently, keeping their order.
+// Once ctx is done the remaining projects are marked ErrNotScanned.
+func Scan(ctx context.Context, projects []Project) []RepoState {
+	states := make([]RepoState, len(projects))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < scanWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer crash.Guard()
+			defer wg.Done()
+			for i := range jobs {
+				p := projects[i]
+				states[i] = RepoState{Path: p.Path}
+				if ctx.Err() != nil {
+					states[i].Error = ErrNotScanned
+					continue
+				}
+				if _, err := os.Stat(p.Path); err != nil {
+					states[i].Error = "missing, run 'aio prj doctor'"
+					continue
+				}
+				status, err := git.StatusOf(ctx, p.Path)
+				states[i].Status = status
+				if err != nil {
+					states[i].Error = err.Error()
+				}
+			}
+		}()
+	}
+	for i := range projects {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return states
+}
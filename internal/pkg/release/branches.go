@@ -0,0 +1,85 @@
+package release
+
+import (
+	"sort"
+	"time"
+
+	"cli-aio/internal/pkg/config"
+)
+
+// Branch is a release branch cut with 'aio git cut-release' and the
+// environments it is allowed to be deployed to.
+type Branch struct {
+	Name      string    `json:"name"`
+	Version   string    `json:"version"`
+	Commit    string    `json:"commit"`
+	Envs      []string  `json:"envs"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HasEnv reports whether the branch is mapped to env.
+func (b *Branch) HasEnv(env string) bool {
+	for _, e := range b.Envs {
+		if e == env {
+			return true
+		}
+	}
+	return false
+}
+
+// branchStore maps a project ID to its release branches by name.
+type branchStore map[string]map[string]*Branch
+
+func loadBranches() (branchStore, error) {
+	path, err := config.Path("release-branches.json")
+	if err != nil {
+		return nil, err
+	}
+	s := branchStore{}
+	if _, err := config.ReadJSON(path, &s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// RecordBranch stores (or replaces) a release branch of a project.
+func RecordBranch(projectID string, branch *Branch) error {
+	s, err := loadBranches()
+	if err != nil {
+		return err
+	}
+	if s[projectID] == nil {
+		s[projectID] = map[string]*Branch{}
+	}
+	s[projectID][branch.Name] = branch
+	path, err := config.Path("release-branches.json")
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, s)
+}
+
+// FindBranch returns the recorded release branch of a project, or nil when name isn't one.
+func FindBranch(projectID string, name string) (*Branch, error) {
+	s, err := loadBranches()
+	if err != nil {
+		return nil, err
+	}
+	return s[projectID][name], nil
+}
+
+// Branches returns the recorded release branches of a project, newest first.
+func Branches(projectID string) ([]*Branch, error) {
+	s, err := loadBranches()
+	if err != nil {
+		return nil, err
+	}
+	var branches []*Branch
+	for _, b := range s[projectID] {
+		branches = append(branches, b)
+	}
+	sort.Slice(branches, func(i, j int) bool {
+		return branches[i].CreatedAt.After(branches[j].CreatedAt)
+	})
+	return branches, nil
+}
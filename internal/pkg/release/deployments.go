@@ -0,0 +1,114 @@
+package release
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/git"
+	"cli-aio/internal/pkg/gitlab"
+)
+
+// maxDeployments is how many deployments are kept per project.
+const maxDeployments = 200
+
+// Deployment is a tag deployed to an environment, as recorded by ztag and release.
+type Deployment struct {
+	Tag         string    `json:"tag"`
+	Env         string    `json:"env"`
+	Ticket      string    `json:"ticket,omitempty"`
+	Commit      string    `json:"commit,omitempty"`
+	Author      string    `json:"author,omitempty"`
+	PipelineURL string    `json:"pipeline_url,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// deploymentStore maps a project ID to its deployments, oldest first.
+type deploymentStore map[string][]Deployment
+
+func loadDeployments() (deploymentStore, error) {
+	path, err := config.Path("deployments.json")
+	if err != nil {
+		return nil, err
+	}
+	s := deploymentStore{}
+	if _, err := config.ReadJSON(path, &s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// RecordDeployment adds a tag created for env to the ledger of the current
+// project. The pipeline URL comes from the running pipeline in CI, otherwise
+// it is looked up on GitLab when a token is available.
+func RecordDeployment(ctx context.Context, env string, tag string, ticket string) error {
+	gc := git.NewClient()
+	projectID, err := gc.ProjectID(ctx)
+	if err != nil {
+		return err
+	}
+	d := Deployment{
+		Tag:         tag,
+		Env:         env,
+		Ticket:      ticket,
+		Author:      os.Getenv("GITLAB_USER_NAME"),
+		PipelineURL: os.Getenv("CI_PIPELINE_URL"),
+		CreatedAt:   time.Now(),
+	}
+	d.Commit, _ = gc.HeadCommit(ctx)
+	if d.Author == "" {
+		d.Author = gc.Config(ctx, "user.name")
+	}
+	if d.PipelineURL == "" && os.Getenv("GITLAB_PRIVATE_TOKEN") != "" {
+		if client, err := gitlab.NewClient(ctx); err == nil {
+			if pipeline, err := client.LatestPipeline(projectID, tag); err == nil {
+				d.PipelineURL = pipeline.WebURL
+			}
+		}
+	}
+	return appendDeployment(projectID, d)
+}
+
+// appendDeployment appends a deployment to the project's ledger.
+func appendDeployment(projectID string, d Deployment) error {
+	s, err := loadDeployments()
+	if err != nil {
+		return err
+	}
+	list := append(s[projectID], d)
+	if len(list) > maxDeployments {
+		list = list[len(list)-maxDeployments:]
+	}
+	s[projectID] = list
+	path, err := config.Path("deployments.json")
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, s)
+}
+
+// Deployments returns the recorded deployments of a project, newest first.
+func Deployments(projectID string) ([]Deployment, error) {
+	s, err := loadDeployments()
+	if err != nil {
+		return nil, err
+	}
+	list := s[projectID]
+	result := make([]Deployment, len(list))
+	for i, d := range list {
+		result[len(list)-1-i] = d
+	}
+	return result, nil
+}
+
+// Current returns the latest deployment per environment.
+func Current(deployments []Deployment) map[string]Deployment {
+	current := map[string]Deployment{}
+	for _, d := range deployments {
+		if existing, ok := current[d.Env]; !ok || d.CreatedAt.After(existing.CreatedAt) {
+			current[d.Env] = d
+		}
+	}
+	return current
+}
@@ -0,0 +1,155 @@
+package release
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"cli-aio/internal/pkg/config"
+)
+
+// Layouts of the freeze bounds in config.json.
+const (
+	weeklyLayout   = "15:04"
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02 15:04"
+)
+
+// Window is one occurrence of a freeze.
+type Window struct {
+	Name  string
+	Start time.Time
+	End   time.Time
+}
+
+// Frozen returns the freeze window of env that now falls in, nil when env may
+// be released. When windows overlap, the one ending last is returned.
+func Frozen(cfg config.Release, env string, now time.Time) (*Window, error) {
+	windows, err := Windows(cfg, env, now, now.Add(time.Nanosecond))
+	if err != nil || len(windows) == 0 {
+		return nil, err
+	}
+	last := windows[0]
+	for _, w := range windows[1:] {
+		if w.End.After(last.End) {
+			last = w
+		}
+	}
+	return &last, nil
+}
+
+// Windows returns the freeze windows of env overlapping [from, to), by start time.
+func Windows(cfg config.Release, env string, from time.Time, to time.Time) ([]Window, error) {
+	loc := time.Local
+	if cfg.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(cfg.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid release.timezone: %w", err)
+		}
+	}
+	from, to = from.In(loc), to.In(loc)
+
+	var windows []Window
+	for i, f := range cfg.Freezes {
+		envs := f.Envs
+		if len(envs) == 0 {
+			envs = []string{"prod"}
+		}
+		if !slices.Contains(envs, env) {
+			continue
+		}
+		occurrences, err := occurrences(f, from, to, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid release.freezes[%d] (%s): %w", i, f.Name, err)
+		}
+		windows = append(windows, occurrences...)
+	}
+	sort.SliceStable(windows, func(i, j int) bool { return windows[i].Start.Before(windows[j].Start) })
+	return windows, nil
+}
+
+// occurrences returns the windows of f overlapping [from, to).
+func occurrences(f config.Freeze, from time.Time, to time.Time, loc *time.Location) ([]Window, error) {
+	if startDay, startTime, ok := parseWeekly(f.From); ok {
+		endDay, endTime, ok := parseWeekly(f.To)
+		if !ok {
+			return nil, fmt.Errorf("'to' should be a day and time like 'Mon 09:00' as 'from' is, got %q", f.To)
+		}
+		var windows []Window
+		// A window starting up to a week before from may still be running
+		day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, -7)
+		for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+			if day.Weekday() != startDay {
+				continue
+			}
+			start := atClock(day, startTime)
+			end := atClock(day.AddDate(0, 0, (int(endDay)-int(startDay)+7)%7), endTime)
+			if !end.After(start) {
+				end = end.AddDate(0, 0, 7)
+			}
+			if end.After(from) && start.Before(to) {
+				windows = append(windows, Window{Name: f.Name, Start: start, End: end})
+			}
+		}
+		return windows, nil
+	}
+
+	start, _, err := parseDate(f.From, loc)
+	if err != nil {
+		return nil, err
+	}
+	end, wholeDay, err := parseDate(f.To, loc)
+	if err != nil {
+		return nil, err
+	}
+	if wholeDay {
+		end = end.AddDate(0, 0, 1)
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("ends (%s) before it starts (%s)", f.To, f.From)
+	}
+	if end.After(from) && start.Before(to) {
+		return []Window{{Name: f.Name, Start: start, End: end}}, nil
+	}
+	return nil, nil
+}
+
+// parseWeekly parses "Fri 16:00" into the weekday and the time of day.
+func parseWeekly(s string) (time.Weekday, time.Duration, bool) {
+	day, clock, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok {
+		return 0, 0, false
+	}
+	weekday := -1
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := d.String()
+		if strings.EqualFold(day, name) || strings.EqualFold(day, name[:3]) {
+			weekday = int(d)
+		}
+	}
+	t, err := time.Parse(weeklyLayout, strings.TrimSpace(clock))
+	if weekday < 0 || err != nil {
+		return 0, 0, false
+	}
+	return time.Weekday(weekday), time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, true
+}
+
+// atClock returns the time of day clock on day, in day's location.
+func atClock(day time.Time, clock time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, day.Location())
+}
+
+// parseDate parses "2026-12-24 18:00" or "2026-12-24", reporting a whole day for the latter.
+func parseDate(s string, loc *time.Location) (time.Time, bool, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.ParseInLocation(dateTimeLayout, s, loc); err == nil {
+		return t, false, nil
+	}
+	t, err := time.ParseInLocation(dateLayout, s, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%q is neither a date (2026-12-24, optionally with 18:00) nor a day and time (Fri 16:00)", s)
+	}
+	return t, true, nil
+}
@@ -0,0 +1,80 @@
+package release
+
+import (
+	"slices"
+	"time"
+
+	"cli-aio/internal/pkg/config"
+)
+
+// PrunedTag is a tag deleted by 'aio ztag prune', kept so the history of what
+// was released survives the cleanup.
+type PrunedTag struct {
+	Tag       string    `json:"tag"`
+	Env       string    `json:"env"`
+	Commit    string    `json:"commit"`
+	CreatedAt time.Time `json:"created_at"`
+	PrunedAt  time.Time `json:"pruned_at"`
+	// Remote is true when the tag was deleted on origin too.
+	Remote bool `json:"remote"`
+}
+
+// prunedStore maps a project ID to its pruned tags, oldest first.
+type prunedStore map[string][]PrunedTag
+
+func loadPruned() (prunedStore, error) {
+	path, err := config.Path("pruned-tags.json")
+	if err != nil {
+		return nil, err
+	}
+	s := prunedStore{}
+	if _, err := config.ReadJSON(path, &s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// RecordPruned appends pruned tags to the project's record.
+func RecordPruned(projectID string, tags []PrunedTag) error {
+	s, err := loadPruned()
+	if err != nil {
+		return err
+	}
+	s[projectID] = append(s[projectID], tags...)
+	path, err := config.Path("pruned-tags.json")
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, s)
+}
+
+// ForgetPruned removes the tags recorded at prunedAt from the project's
+// record, for a prune that failed before deleting anything.
+func ForgetPruned(projectID string, prunedAt time.Time) error {
+	s, err := loadPruned()
+	if err != nil {
+		return err
+	}
+	s[projectID] = slices.DeleteFunc(s[projectID], func(t PrunedTag) bool {
+		return t.PrunedAt.Equal(prunedAt)
+	})
+	path, err := config.Path("pruned-tags.json")
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, s)
+}
+
+// Pruned returns the tags pruned from a project, most recently pruned first.
+func Pruned(projectID string) ([]PrunedTag, error) {
+	s, err := loadPruned()
+	if err != nil {
+		return nil, err
+	}
+	list := s[projectID]
+	result := make([]PrunedTag, len(list))
+	for i, t := range list {
+		result[len(list)-1-i] = t
+	}
+	return result, nil
+}
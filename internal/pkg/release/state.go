@@ -0,0 +1,93 @@
+package release
+
+import (
+	"time"
+
+	"cli-aio/internal/pkg/config"
+)
+
+// State is the progress of a release run, persisted after every step so a
+// failed run can be resumed without re-tagging or re-notifying.
+type State struct {
+	Env       string    `json:"env"`
+	Level     string    `json:"level"`
+	Commit    string    `json:"commit"`
+	PrevTag   string    `json:"prev_tag,omitempty"`
+	Tag       string    `json:"tag,omitempty"`
+	Changelog []string  `json:"changelog,omitempty"`
+	Ticket    string    `json:"ticket,omitempty"`
+	Done      []string  `json:"done"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// IsDone reports whether step already completed.
+func (s *State) IsDone(step string) bool {
+	for _, d := range s.Done {
+		if d == step {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkDone records step as completed.
+func (s *State) MarkDone(step string) {
+	if !s.IsDone(step) {
+		s.Done = append(s.Done, step)
+	}
+}
+
+// store maps a project root path to its in-progress release.
+type store map[string]*State
+
+func load() (store, error) {
+	path, err := config.Path("release-state.json")
+	if err != nil {
+		return nil, err
+	}
+	s := store{}
+	if _, err := config.ReadJSON(path, &s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func save(s store) error {
+	path, err := config.Path("release-state.json")
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, s)
+}
+
+// Load returns the in-progress release of a project, or nil when there is none.
+func Load(projectKey string) (*State, error) {
+	s, err := load()
+	if err != nil {
+		return nil, err
+	}
+	return s[projectKey], nil
+}
+
+// Save persists the release state of a project.
+func Save(projectKey string, state *State) error {
+	s, err := load()
+	if err != nil {
+		return err
+	}
+	s[projectKey] = state
+	return save(s)
+}
+
+// Clear removes the release state of a project.
+func Clear(projectKey string) error {
+	s, err := load()
+	if err != nil {
+		return err
+	}
+	if _, ok := s[projectKey]; !ok {
+		return nil
+	}
+	delete(s, projectKey)
+	return save(s)
+}
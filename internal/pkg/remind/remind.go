@@ -0,0 +1,125 @@
+package remind
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"cli-aio/internal/pkg/config"
+)
+
+// Reminder is a recurring reminder such as "rotate GITLAB token" every 90 days.
+type Reminder struct {
+	ID       int       `json:"id"`
+	Message  string    `json:"message"`
+	Every    string    `json:"every"` // interval, e.g. "1d", "2w", "weekly"
+	NextDue  time.Time `json:"next_due"`
+	LastDone time.Time `json:"last_done,omitempty"`
+}
+
+// Store holds all reminders persisted in reminders.json.
+type Store struct {
+	Reminders []Reminder `json:"reminders"`
+}
+
+var intervalPattern = regexp.MustCompile(`^(\d+)([hdwm])$`)
+
+// ParseInterval parses an interval like "12h", "1d", "2w", "3m" (months of 30 days)
+// or one of the aliases daily, weekly, monthly.
+func ParseInterval(s string) (time.Duration, error) {
+	switch s {
+	case "daily":
+		s = "1d"
+	case "weekly":
+		s = "1w"
+	case "monthly":
+		s = "1m"
+	}
+	m := intervalPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid interval '%s' (use e.g. 12h, 1d, 2w, 3m, daily, weekly, monthly)", s)
+	}
+	n, _ := strconv.Atoi(m[1])
+	if n == 0 {
+		return 0, fmt.Errorf("interval must be greater than zero")
+	}
+	unit := map[string]time.Duration{
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+		"m": 30 * 24 * time.Hour,
+	}[m[2]]
+	return time.Duration(n) * unit, nil
+}
+
+// Load reads the reminders store, returning an empty store when none exists.
+func Load() (*Store, error) {
+	path, err := config.Path("reminders.json")
+	if err != nil {
+		return nil, err
+	}
+	store := &Store{}
+	if _, err := config.ReadJSON(path, store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Save writes the reminders store to disk.
+func Save(store *Store) error {
+	path, err := config.Path("reminders.json")
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, store)
+}
+
+// Add creates a new reminder that first fires at start.
+func Add(store *Store, message string, every string, start time.Time) (*Reminder, error) {
+	if _, err := ParseInterval(every); err != nil {
+		return nil, err
+	}
+	id := 1
+	for _, r := range store.Reminders {
+		if r.ID >= id {
+			id = r.ID + 1
+		}
+	}
+	store.Reminders = append(store.Reminders, Reminder{
+		ID:      id,
+		Message: message,
+		Every:   every,
+		NextDue: start,
+	})
+	return &store.Reminders[len(store.Reminders)-1], nil
+}
+
+// Due returns the reminders whose next due time is at or before now.
+func Due(store *Store, now time.Time) []Reminder {
+	var due []Reminder
+	for _, r := range store.Reminders {
+		if !r.NextDue.After(now) {
+			due = append(due, r)
+		}
+	}
+	return due
+}
+
+// Done marks the reminder with id as done and schedules its next occurrence.
+func Done(store *Store, id int, now time.Time) (*Reminder, error) {
+	for i := range store.Reminders {
+		r := &store.Reminders[i]
+		if r.ID != id {
+			continue
+		}
+		interval, err := ParseInterval(r.Every)
+		if err != nil {
+			return nil, err
+		}
+		r.LastDone = now
+		r.NextDue = now.Add(interval)
+		return r, nil
+	}
+	return nil, fmt.Errorf("reminder #%d not found", id)
+}
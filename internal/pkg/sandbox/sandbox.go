@@ -0,0 +1,269 @@
+// Package sandbox builds throwaway git repositories with branches, tags,
+// conflicts and an origin, to try commands like rmerge or ztag safely and to
+// set up the sessions of the golden tests.
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"cli-aio/internal/pkg/git"
+)
+
+// Spec describes a sandbox repository.
+type Spec struct {
+	// Main is the default branch (default "main").
+	Main string
+	// Branches are created from Main, each with a commit of its own.
+	Branches []string
+	// Tags are annotated tags on Main, each on a commit of its own, in order.
+	Tags []string
+	// Conflicts are pairs of branches changing the same line of conflict.txt,
+	// so merging one into the other conflicts. Missing branches are created.
+	Conflicts [][2]string
+	// Diverged are branches with a commit origin doesn't have and origin a
+	// commit they don't have, so pulling them fails. Needs Remote.
+	Diverged []string
+	// Remote adds a bare origin next to the work tree, with every branch
+	// pushed and tracked.
+	Remote bool
+}
+
+// DemoSpec is the repository built without options: the environment branches
+// and tags of a service, a feature branch and an origin.
+func DemoSpec() Spec {
+	return Spec{
+		Branches: []string{"develop", "qc", "staging", "feature/ABC-123-login-form"},
+		Tags:     []string{"qc-v1.0.0", "stg-v1.0.0", "v1.0.0", "qc-v1.0.1"},
+		Remote:   true,
+	}
+}
+
+// Repo is a built sandbox.
+type Repo struct {
+	// Dir is the work tree.
+	Dir string
+	// Origin is the bare repository of origin, "" without Remote.
+	Origin string
+}
+
+// Identity of the sandbox commits, which get dates a minute apart from
+// commitEpoch so the hashes are the same on every build.
+const (
+	authorName  = "Sandbox"
+	authorEmail = "sandbox@example.com"
+)
+
+var commitEpoch = time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+// builder runs the git commands building a sandbox in dir.
+type builder struct {
+	ctx     context.Context
+	dir     string
+	commits int
+	// now is the date of the last commit, used for the tags too
+	now time.Time
+}
+
+// Build creates the sandbox described by spec in dir, which must not exist or
+// be empty: the work tree in dir/repo and origin in dir/origin.git.
+func Build(ctx context.Context, dir string, spec Spec) (*Repo, error) {
+	if spec.Main == "" {
+		spec.Main = "main"
+	}
+	if len(spec.Diverged) > 0 && !spec.Remote {
+		return nil, fmt.Errorf("diverged branches need a remote")
+	}
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("%s is not empty", dir)
+	}
+	repo := &Repo{Dir: filepath.Join(dir, "repo")}
+	if err := os.MkdirAll(repo.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", repo.Dir, err)
+	}
+
+	b := &builder{ctx: ctx, dir: repo.Dir, now: commitEpoch}
+	if err := b.init(spec.Main); err != nil {
+		return nil, err
+	}
+	for _, tag := range spec.Tags {
+		if err := b.commit("CHANGELOG.md", "## "+tag+"\n", "Release "+tag); err != nil {
+			return nil, err
+		}
+		if err := b.git("tag", "-a", tag, "-m", "Release "+tag); err != nil {
+			return nil, err
+		}
+	}
+	for _, branch := range spec.Branches {
+		if err := b.branch(spec.Main, branch); err != nil {
+			return nil, err
+		}
+	}
+	for _, pair := range spec.Conflicts {
+		for _, branch := range pair {
+			if err := b.ensureBranch(spec.Main, branch); err != nil {
+				return nil, err
+			}
+			if err := b.git("checkout", "-q", branch); err != nil {
+				return nil, err
+			}
+			if err := b.commit("conflict.txt", "changed on "+branch+"\n", "Change conflict.txt on "+branch); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := b.git("checkout", "-q", spec.Main); err != nil {
+		return nil, err
+	}
+
+	if spec.Remote {
+		repo.Origin = filepath.Join(dir, "origin.git")
+		if err := b.remote(repo.Origin, spec.Main); err != nil {
+			return nil, err
+		}
+		for _, branch := range spec.Diverged {
+			if err := b.diverge(spec.Main, branch); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return repo, nil
+}
+
+// init creates the repository with a first commit on main.
+func (b *builder) init(main string) error {
+	if err := b.git("init", "-q"); err != nil {
+		return err
+	}
+	// init -b needs git 2.28
+	if err := b.git("symbolic-ref", "HEAD", "refs/heads/"+main); err != nil {
+		return err
+	}
+	// Commits made by hand in the sandbox don't need a global identity
+	if err := b.git("config", "user.name", authorName); err != nil {
+		return err
+	}
+	if err := b.git("config", "user.email", authorEmail); err != nil {
+		return err
+	}
+	return b.commit("README.md", "# Sandbox\n\nA throwaway repository made by aio devtool mkrepo.\n", "Initial commit")
+}
+
+// branch creates branch from main with a commit adding a file named after it.
+func (b *builder) branch(main string, branch string) error {
+	if err := b.git("checkout", "-q", "-b", branch, main); err != nil {
+		return err
+	}
+	file := slug(branch) + ".txt"
+	if err := b.commit(file, "work on "+branch+"\n", commitMessage(branch)); err != nil {
+		return err
+	}
+	return b.git("checkout", "-q", main)
+}
+
+// ensureBranch creates branch from main unless it exists.
+func (b *builder) ensureBranch(main string, branch string) error {
+	if b.git("show-ref", "--verify", "--quiet", "refs/heads/"+branch) == nil {
+		return nil
+	}
+	return b.branch(main, branch)
+}
+
+// remote creates the bare origin and pushes every branch and tag to it.
+func (b *builder) remote(origin string, main string) error {
+	if err := b.run("", "init", "-q", "--bare", origin); err != nil {
+		return err
+	}
+	if err := b.run("", "-C", origin, "symbolic-ref", "HEAD", "refs/heads/"+main); err != nil {
+		return err
+	}
+	if err := b.git("remote", "add", "origin", origin); err != nil {
+		return err
+	}
+	if err := b.git("push", "-q", "-u", "origin", "--all"); err != nil {
+		return err
+	}
+	if err := b.git("push", "-q", "origin", "--tags"); err != nil {
+		return err
+	}
+	return b.git("remote", "set-head", "origin", main)
+}
+
+// diverge gives origin a commit on branch the local branch doesn't have, and
+// the local branch one origin doesn't have.
+func (b *builder) diverge(main string, branch string) error {
+	if err := b.ensureBranch(main, branch); err != nil {
+		return err
+	}
+	if err := b.git("checkout", "-q", branch); err != nil {
+		return err
+	}
+	if err := b.commit("remote.txt", "pushed by someone else\n", "Change pushed by someone else"); err != nil {
+		return err
+	}
+	if err := b.git("push", "-q", "-u", "origin", branch); err != nil {
+		return err
+	}
+	if err := b.git("reset", "-q", "--hard", "HEAD~1"); err != nil {
+		return err
+	}
+	if err := b.commit("local.txt", "not pushed yet\n", "Local change not pushed yet"); err != nil {
+		return err
+	}
+	return b.git("checkout", "-q", main)
+}
+
+// commit writes content to file and commits it.
+func (b *builder) commit(file string, content string, message string) error {
+	if err := os.WriteFile(filepath.Join(b.dir, file), []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	if err := b.git("add", file); err != nil {
+		return err
+	}
+	b.now = commitEpoch.Add(time.Duration(b.commits) * time.Minute)
+	b.commits++
+	return b.git("-c", "commit.gpgsign=false", "commit", "-q", "-m", message)
+}
+
+// git runs git in the work tree.
+func (b *builder) git(args ...string) error {
+	return b.run(b.dir, args...)
+}
+
+// run runs git in dir as the sandbox identity, at the date of the last commit.
+func (b *builder) run(dir string, args ...string) error {
+	cmd := git.CommandIn(b.ctx, dir, args...)
+	date := b.now.Format(time.RFC3339)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+authorName, "GIT_AUTHOR_EMAIL="+authorEmail, "GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME="+authorName, "GIT_COMMITTER_EMAIL="+authorEmail, "GIT_COMMITTER_DATE="+date,
+		// The hooks and templates of the user's config don't apply here
+		"GIT_CONFIG_NOSYSTEM=1", "GIT_TEMPLATE_DIR=",
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error running git %s: %w\n%s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+var jiraKeyPattern = regexp.MustCompile(`[A-Z][A-Z0-9]+-\d+`)
+
+// commitMessage returns the message of the commit of branch, starting with
+// its Jira key when it has one, as the commit conventions expect.
+func commitMessage(branch string) string {
+	if key := jiraKeyPattern.FindString(branch); key != "" {
+		return key + " Work on " + branch
+	}
+	return "Work on " + branch
+}
+
+// slug turns a branch name into a file name.
+func slug(branch string) string {
+	return strings.NewReplacer("/", "-", " ", "-").Replace(branch)
+}
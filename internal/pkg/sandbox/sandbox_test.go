@@ -0,0 +1,125 @@
+package sandbox
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cli-aio/internal/pkg/git"
+)
+
+func TestBuildDemo(t *testing.T) {
+	dir := sandboxDir(t)
+	repo, err := Build(context.Background(), dir, DemoSpec())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.Dir != filepath.Join(dir, "repo") || repo.Origin != filepath.Join(dir, "origin.git") {
+		t.Errorf("Build = %+v, want repo and origin.git in %s", repo, dir)
+	}
+	if head := gitOutput(t, repo.Dir, "symbolic-ref", "--short", "HEAD"); head != "main" {
+		t.Errorf("HEAD is on %s, want main", head)
+	}
+	if status := gitOutput(t, repo.Dir, "status", "--porcelain"); status != "" {
+		t.Errorf("work tree not clean:\n%s", status)
+	}
+
+	branches := gitOutput(t, repo.Dir, "for-each-ref", "--format=%(refname:short)", "refs/heads")
+	if want := "develop\nfeature/ABC-123-login-form\nmain\nqc\nstaging"; branches != want {
+		t.Errorf("branches:\n%s\nwant:\n%s", branches, want)
+	}
+	// Every branch is pushed and tracks its origin side
+	for _, branch := range strings.Split(branches, "\n") {
+		if upstream := gitOutput(t, repo.Dir, "rev-parse", "--abbrev-ref", branch+"@{upstream}"); upstream != "origin/"+branch {
+			t.Errorf("%s tracks %q, want origin/%s", branch, upstream, branch)
+		}
+	}
+	tags := gitOutput(t, repo.Origin, "tag", "--sort=creatordate")
+	if want := "qc-v1.0.0\nstg-v1.0.0\nv1.0.0\nqc-v1.0.1"; tags != want {
+		t.Errorf("origin tags:\n%s\nwant:\n%s", tags, want)
+	}
+	if message := gitOutput(t, repo.Dir, "log", "-1", "--format=%s", "feature/ABC-123-login-form"); !strings.HasPrefix(message, "ABC-123 ") {
+		t.Errorf("commit of the feature branch %q doesn't start with its Jira key", message)
+	}
+}
+
+// The golden sessions print hashes, which only works if every build of a
+// spec gives the same ones.
+func TestBuildIsReproducible(t *testing.T) {
+	spec := Spec{Branches: []string{"develop"}, Tags: []string{"v1.0.0"}, Conflicts: [][2]string{{"develop", "qc"}}, Remote: true}
+	var refs []string
+	for i := 0; i < 2; i++ {
+		repo, err := Build(context.Background(), sandboxDir(t), spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		refs = append(refs, gitOutput(t, repo.Dir, "show-ref"))
+	}
+	if refs[0] != refs[1] {
+		t.Errorf("two builds differ:\n%s\n\n%s", refs[0], refs[1])
+	}
+}
+
+func TestBuildConflicts(t *testing.T) {
+	repo, err := Build(context.Background(), sandboxDir(t), Spec{Conflicts: [][2]string{{"develop", "qc"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.Origin != "" {
+		t.Errorf("origin %s built without Remote", repo.Origin)
+	}
+	if err := git.CommandIn(context.Background(), repo.Dir, "checkout", "-q", "qc").Run(); err != nil {
+		t.Fatal(err)
+	}
+	output, err := git.CommandIn(context.Background(), repo.Dir, "merge", "--no-edit", "develop").CombinedOutput()
+	if git.KindOf(err) != git.ErrConflict {
+		t.Errorf("merging develop into qc: %v\n%s\nwant a conflict", err, output)
+	}
+}
+
+func TestBuildDiverged(t *testing.T) {
+	repo, err := Build(context.Background(), sandboxDir(t), Spec{Diverged: []string{"qc"}, Remote: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One commit on each side
+	if counts := gitOutput(t, repo.Dir, "rev-list", "--left-right", "--count", "qc...origin/qc"); counts != "1\t1" {
+		t.Errorf("qc...origin/qc = %q, want 1\\t1", counts)
+	}
+}
+
+func TestBuildRejects(t *testing.T) {
+	if _, err := Build(context.Background(), sandboxDir(t), Spec{Diverged: []string{"qc"}}); err == nil {
+		t.Error("diverged branches without a remote should fail")
+	}
+
+	dir := sandboxDir(t)
+	if _, err := Build(context.Background(), dir, Spec{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Build(context.Background(), dir, Spec{}); err == nil {
+		t.Error("building in a non-empty directory should fail")
+	}
+}
+
+// sandboxDir returns a directory to build a sandbox in, skipping the test
+// without git. The user's git config stays out of it.
+func sandboxDir(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	return t.TempDir()
+}
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	output, err := git.CommandIn(context.Background(), dir, args...).Output()
+	if err != nil {
+		t.Fatalf("git %s: %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(output))
+}
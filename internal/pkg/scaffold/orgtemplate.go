@@ -0,0 +1,89 @@
+package scaffold
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/git"
+)
+
+// HooksDir is the repository directory org templates put git hooks in.
+const HooksDir = ".githooks"
+
+// OrgFile is one file of an org template set.
+type OrgFile struct {
+	Path    string // relative to the repository root
+	Content []byte
+}
+
+// LoadOrgTemplate resolves the files of an org template set, sorted by path.
+func LoadOrgTemplate(files map[string]string) ([]OrgFile, error) {
+	var result []OrgFile
+	for path, content := range files {
+		clean := filepath.Clean(filepath.FromSlash(path))
+		if filepath.IsAbs(clean) || clean == "." || strings.HasPrefix(clean, "..") {
+			return nil, fmt.Errorf("invalid org template path: %s", path)
+		}
+		data := []byte(content)
+		if strings.HasPrefix(content, "@") {
+			source := strings.TrimPrefix(content, "@")
+			if !filepath.IsAbs(source) {
+				dir, err := config.Dir()
+				if err != nil {
+					return nil, err
+				}
+				source = filepath.Join(dir, source)
+			}
+			var err error
+			if data, err = os.ReadFile(source); err != nil {
+				return nil, fmt.Errorf("failed to read org template file for %s: %w", path, err)
+			}
+		}
+		result = append(result, OrgFile{Path: clean, Content: data})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	return result, nil
+}
+
+// Exists reports whether the file is already present in dir.
+func (f OrgFile) Exists(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, f.Path))
+	return err == nil
+}
+
+// IsHook reports whether the file is a git hook.
+func (f OrgFile) IsHook() bool {
+	return strings.HasPrefix(filepath.ToSlash(f.Path), HooksDir+"/")
+}
+
+// Write writes the file into dir with placeholders substituted. Hooks and
+// scripts (content starting with "#!") are made executable.
+func (f OrgFile) Write(dir string, vars Vars) error {
+	target := filepath.Join(dir, f.Path)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", f.Path, err)
+	}
+	content := f.Content
+	if bytes.IndexByte(content, 0) < 0 {
+		content = []byte(strings.NewReplacer(vars.placeholders()...).Replace(string(content)))
+	}
+	perm := os.FileMode(0644)
+	if f.IsHook() || bytes.HasPrefix(content, []byte("#!")) {
+		perm = 0755
+	}
+	if err := os.WriteFile(target, content, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", f.Path, err)
+	}
+	return os.Chmod(target, perm)
+}
+
+// EnableHooks points core.hooksPath of the repository in dir to HooksDir.
+func EnableHooks(ctx context.Context, dir string) error {
+	return git.SetLocalConfigIn(ctx, dir, "core.hooksPath", HooksDir)
+}
@@ -0,0 +1,124 @@
+package scaffold
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"cli-aio/internal/pkg/git"
+)
+
+// Vars are the values substituted into template files.
+type Vars struct {
+	ProjectName string
+	ModulePath  string
+}
+
+// placeholders returns the placeholder -> value pairs used in templates.
+func (v Vars) placeholders() []string {
+	return []string{
+		"{{project_name}}", v.ProjectName,
+		"{{module_path}}", v.ModulePath,
+	}
+}
+
+// Clone copies the template repository at repoURL into dir without its history.
+func Clone(ctx context.Context, repoURL string, dir string) error {
+	if err := git.ShallowClone(ctx, repoURL, dir); err != nil {
+		return fmt.Errorf("error cloning template: %w", err)
+	}
+	if err := os.RemoveAll(filepath.Join(dir, ".git")); err != nil {
+		return fmt.Errorf("failed to remove template history: %w", err)
+	}
+	return nil
+}
+
+var goModuleLine = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+
+// templateModule returns the module path declared in the template's go.mod, if any.
+func templateModule(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	if m := goModuleLine.FindSubmatch(data); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+// Substitute replaces placeholders in file contents and file names under dir.
+// When the template is a Go module, its original module path is also rewritten
+// to vars.ModulePath so imports keep compiling. Binary files are left untouched.
+// Returns the number of files changed.
+func Substitute(dir string, vars Vars) (int, error) {
+	pairs := vars.placeholders()
+	if oldModule := templateModule(dir); oldModule != "" && vars.ModulePath != "" && oldModule != vars.ModulePath {
+		pairs = append(pairs, oldModule, vars.ModulePath)
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	changed := 0
+	var renames [][2]string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if newName := replacer.Replace(d.Name()); newName != d.Name() {
+			renames = append(renames, [2]string{path, filepath.Join(filepath.Dir(path), newName)})
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.IndexByte(data, 0) >= 0 {
+			return nil
+		}
+		updated := replacer.Replace(string(data))
+		if updated == string(data) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		changed++
+		return os.WriteFile(path, []byte(updated), info.Mode().Perm())
+	})
+	if err != nil {
+		return changed, fmt.Errorf("failed to substitute template variables: %w", err)
+	}
+
+	// Rename deepest paths first so parent renames don't invalidate child paths
+	for i := len(renames) - 1; i >= 0; i-- {
+		if err := os.Rename(renames[i][0], renames[i][1]); err != nil {
+			return changed, fmt.Errorf("failed to rename %s: %w", renames[i][0], err)
+		}
+	}
+	return changed, nil
+}
+
+// InitRepo initializes a fresh git repository in dir with a single initial commit.
+func InitRepo(ctx context.Context, dir string) error {
+	return git.InitIn(ctx, dir, "Initial commit")
+}
+
+// PushToRemote adds origin and pushes the current branch to it.
+func PushToRemote(ctx context.Context, dir string, remoteURL string) error {
+	if err := git.AddRemoteIn(ctx, dir, "origin", remoteURL); err != nil {
+		return err
+	}
+	return git.PushHeadIn(ctx, dir, "origin")
+}
@@ -0,0 +1,156 @@
+// Package scratch creates throwaway projects for quick experiments.
+package scratch
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"cli-aio/internal/pkg/config"
+	"cli-aio/internal/pkg/project"
+	"cli-aio/internal/pkg/remind"
+)
+
+// Tag labels scratch projects in the project store.
+const Tag = project.ScratchTag
+
+// DefaultTTL is how long scratch projects live without projects.scratch_ttl.
+const DefaultTTL = "7d"
+
+// Template is what a new scratch project starts with.
+type Template struct {
+	// Files maps a path relative to the project to its content.
+	Files map[string]string
+	// Commands are run through the shell in the project, after the files are written.
+	Commands []string
+}
+
+// Templates are the built-in templates by language.
+var Templates = map[string]Template{
+	"go": {
+		Files:    map[string]string{"main.go": "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n"},
+		Commands: []string{"go mod init scratch"},
+	},
+	"node": {
+		Files:    map[string]string{"index.js": "console.log(\"hello\");\n"},
+		Commands: []string{"npm init -y >/dev/null"},
+	},
+	"python": {
+		Files:    map[string]string{"main.py": "print(\"hello\")\n"},
+		Commands: []string{"python3 -m venv .venv"},
+	},
+}
+
+// aliases are other names accepted for the built-in languages.
+var aliases = map[string]string{"golang": "go", "js": "node", "npm": "node", "py": "python", "python3": "python"}
+
+// Languages returns the languages with a template, built-in or configured.
+func Languages(custom map[string][]string) []string {
+	var langs []string
+	for lang := range Templates {
+		if _, ok := custom[lang]; !ok {
+			langs = append(langs, lang)
+		}
+	}
+	for lang := range custom {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Lookup returns the template for lang. Configured templates replace the
+// built-in ones; an empty lang is an empty project.
+func Lookup(lang string, custom map[string][]string) (Template, error) {
+	if lang == "" {
+		return Template{}, nil
+	}
+	if commands, ok := custom[lang]; ok {
+		return Template{Commands: commands}, nil
+	}
+	if alias, ok := aliases[lang]; ok {
+		lang = alias
+	}
+	if commands, ok := custom[lang]; ok {
+		return Template{Commands: commands}, nil
+	}
+	if t, ok := Templates[lang]; ok {
+		return t, nil
+	}
+	return Template{}, fmt.Errorf("no scratch template for '%s' (%s)", lang, strings.Join(Languages(custom), ", "))
+}
+
+// Settings returns the scratch directory and time to live from the config.
+func Settings(cfg *config.Config) (string, time.Duration, error) {
+	dir := cfg.Projects.ScratchDir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "aio-scratch")
+	}
+	ttl, err := TTL(cfg.Projects.ScratchTTL)
+	return dir, ttl, err
+}
+
+// TTL parses a time to live like "12h", "7d" or "2w", DefaultTTL when empty.
+func TTL(s string) (time.Duration, error) {
+	if s == "" {
+		s = DefaultTTL
+	}
+	return remind.ParseInterval(s)
+}
+
+// Create makes a new directory for lang under parent and applies t to it.
+// Output of the template commands goes to stderr, so stdout stays free for
+// the path. The directory is removed again when a command fails.
+func Create(parent string, lang string, t Template) (string, error) {
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", parent, err)
+	}
+	prefix := time.Now().Format("20060102-")
+	if lang != "" {
+		prefix = lang + "-" + prefix
+	}
+	dir, err := os.MkdirTemp(parent, prefix)
+	if err != nil {
+		return "", fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+
+	if err := apply(dir, t); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+func apply(dir string, t Template) error {
+	for name, content := range t.Files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	for _, command := range t.Commands {
+		fmt.Fprintf(os.Stderr, "-> %s\n", command)
+		run := exec.Command("sh", "-c", command)
+		run.Dir = dir
+		run.Stdout = os.Stderr
+		run.Stderr = os.Stderr
+		if err := run.Run(); err != nil {
+			return fmt.Errorf("'%s' failed: %w", command, err)
+		}
+	}
+	return nil
+}
+
+// Owned reports whether dir is a project created under the scratch directory,
+// so it may be deleted without asking.
+func Owned(scratchDir string, dir string) bool {
+	rel, err := filepath.Rel(scratchDir, dir)
+	return err == nil && rel != "." && !strings.HasPrefix(rel, "..") && !strings.ContainsRune(rel, filepath.Separator)
+}
@@ -0,0 +1,191 @@
+// Package search finds a pattern in the files of a directory tree, with
+// ripgrep when it is installed and a slower pure-Go walk otherwise.
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Options tune a search.
+type Options struct {
+	IgnoreCase bool // match case-insensitively
+	Fixed      bool // pattern is a literal string, not a regular expression
+	Limit      int  // stop after this many matches, 0 for no limit
+}
+
+// Match is a line matching the pattern.
+type Match struct {
+	Path string // absolute path of the file
+	Line int    // 1-based line number
+	Text string // the line, without its line ending
+}
+
+// ErrLimit is returned with the matches found when Options.Limit was reached.
+var ErrLimit = errors.New("too many matches")
+
+// maxFileSize is the largest file the Go fallback reads.
+const maxFileSize = 2 << 20
+
+// skipDirs are directories the Go fallback never descends into (ripgrep
+// skips what .gitignore lists instead).
+var skipDirs = map[string]bool{"node_modules": true, "vendor": true}
+
+// HasRipgrep reports whether the rg binary is installed.
+func HasRipgrep() bool {
+	_, err := exec.LookPath("rg")
+	return err == nil
+}
+
+// Search finds pattern in the files under dir.
+func Search(ctx context.Context, dir string, pattern string, opts Options) ([]Match, error) {
+	if HasRipgrep() {
+		return ripgrep(ctx, dir, pattern, opts)
+	}
+	return walk(ctx, dir, pattern, opts)
+}
+
+// rgEvent is the part of ripgrep's --json output used here.
+type rgEvent struct {
+	Type string `json:"type"`
+	Data struct {
+		Path       struct{ Text string } `json:"path"`
+		Lines      struct{ Text string } `json:"lines"`
+		LineNumber int                   `json:"line_number"`
+	} `json:"data"`
+}
+
+func ripgrep(ctx context.Context, dir string, pattern string, opts Options) ([]Match, error) {
+	args := []string{"--json", "--max-filesize", "2M"}
+	if opts.IgnoreCase {
+		args = append(args, "--ignore-case")
+	}
+	if opts.Fixed {
+		args = append(args, "--fixed-strings")
+	}
+	args = append(args, "-e", pattern, dir)
+
+	cmd := exec.CommandContext(ctx, "rg", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to run rg: %w", err)
+	}
+
+	var matches []Match
+	limited := false
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		var event rgEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.Type != "match" {
+			continue
+		}
+		path := event.Data.Path.Text
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		matches = append(matches, Match{
+			Path: path,
+			Line: event.Data.LineNumber,
+			Text: strings.TrimRight(event.Data.Lines.Text, "\r\n"),
+		})
+		if opts.Limit > 0 && len(matches) >= opts.Limit {
+			limited = true
+			cmd.Process.Kill()
+			break
+		}
+	}
+	err = cmd.Wait()
+	if limited {
+		return matches, ErrLimit
+	}
+	// rg exits with 1 when nothing matched
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		if ctx.Err() != nil {
+			return matches, ctx.Err()
+		}
+		return matches, fmt.Errorf("rg failed: %w\n%s", err, strings.TrimSpace(stderr.String()))
+	}
+	return matches, nil
+}
+
+// walk is the Go fallback: it reads every text file below dir, skipping
+// hidden directories, node_modules and vendor.
+func walk(ctx context.Context, dir string, pattern string, opts Options) ([]Match, error) {
+	expr := pattern
+	if opts.Fixed {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if opts.IgnoreCase {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	var matches []Match
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			// Skip unreadable entries
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != dir && (strings.HasPrefix(name, ".") || skipDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() > maxFileSize {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || isBinary(data) {
+			return nil
+		}
+		for i, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimRight(line, "\r")
+			if !re.MatchString(line) {
+				continue
+			}
+			matches = append(matches, Match{Path: path, Line: i + 1, Text: line})
+			if opts.Limit > 0 && len(matches) >= opts.Limit {
+				return ErrLimit
+			}
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// isBinary guesses like git does: a NUL byte in the first 8000 bytes.
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0
+}
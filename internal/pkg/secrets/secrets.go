@@ -0,0 +1,110 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"cli-aio/internal/pkg/config"
+)
+
+// fileName is the secrets file inside the config directory. It is written with 0600 permissions.
+const fileName = "secrets.json"
+
+// EnvName returns the environment variable that overrides a secret,
+// e.g. "jira.token" -> "AIO_JIRA_TOKEN".
+func EnvName(name string) string {
+	r := strings.NewReplacer(".", "_", "-", "_")
+	return "AIO_" + strings.ToUpper(r.Replace(name))
+}
+
+// Get returns the secret value for name. The AIO_<NAME> environment variable
+// takes precedence over the secrets file. Returns an empty string when unset.
+func Get(name string) (string, error) {
+	if v := os.Getenv(EnvName(name)); v != "" {
+		return v, nil
+	}
+	all, err := load()
+	if err != nil {
+		return "", err
+	}
+	return all[name], nil
+}
+
+// Require is like Get but fails with a hint on how to set the secret when it is missing.
+func Require(name string) (string, error) {
+	v, err := Get(name)
+	if err != nil {
+		return "", err
+	}
+	if v == "" {
+		return "", fmt.Errorf("secret '%s' is not set (run 'aio secrets set %s' or export %s)", name, name, EnvName(name))
+	}
+	return v, nil
+}
+
+// Set stores a secret value.
+func Set(name string, value string) error {
+	all, err := load()
+	if err != nil {
+		return err
+	}
+	all[name] = value
+	return save(all)
+}
+
+// Delete removes a secret. Returns false if it did not exist.
+func Delete(name string) (bool, error) {
+	all, err := load()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := all[name]; !ok {
+		return false, nil
+	}
+	delete(all, name)
+	return true, save(all)
+}
+
+// Names returns the sorted names of all stored secrets.
+func Names() ([]string, error) {
+	all, err := load()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Mask hides all but the last 4 characters of a secret value.
+func Mask(value string) string {
+	if len(value) <= 4 {
+		return strings.Repeat("*", len(value))
+	}
+	return strings.Repeat("*", len(value)-4) + value[len(value)-4:]
+}
+
+func load() (map[string]string, error) {
+	path, err := config.Path(fileName)
+	if err != nil {
+		return nil, err
+	}
+	all := map[string]string{}
+	if _, err := config.ReadJSON(path, &all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+func save(all map[string]string) error {
+	path, err := config.Path(fileName)
+	if err != nil {
+		return err
+	}
+	return config.WritePrivateJSON(path, all)
+}
@@ -0,0 +1,191 @@
+// Package shellwrap installs shell functions around aio commands that print
+// a directory, so the shell can cd into it: a child process cannot change its
+// parent's working directory. Each function lives between markers in the
+// shell's rc file, so it can be upgraded or removed without touching the rest.
+package shellwrap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+)
+
+// Shells are the shells a wrapper can be installed for.
+var Shells = []string{"zsh", "bash", "fish", "ksh"}
+
+// RC is where a shell loads a wrapper from.
+type RC struct {
+	Shell  string
+	File   string // rc file the wrapper is appended to
+	Reload string // command reloading the shell configuration
+}
+
+// Locate returns where the function called name is installed for shell,
+// detected from $SHELL when empty. Unknown shells fall back to ~/.profile.
+func Locate(shell string, name string) (*RC, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	explicit := shell != ""
+	if !explicit {
+		shell = filepath.Base(os.Getenv("SHELL"))
+	}
+
+	switch shell {
+	case "zsh":
+		return &RC{Shell: shell, File: filepath.Join(home, ".zshrc"), Reload: "exec zsh"}, nil
+	case "bash":
+		// Prefer .bashrc; fall back to .bash_profile (macOS default login shell)
+		rc := filepath.Join(home, ".bashrc")
+		if _, err := os.Stat(rc); os.IsNotExist(err) {
+			rc = filepath.Join(home, ".bash_profile")
+		}
+		return &RC{Shell: shell, File: rc, Reload: "source " + rc}, nil
+	case "fish":
+		// Fish loads every file in functions/ automatically
+		file := filepath.Join(home, ".config", "fish", "functions", name+".fish")
+		return &RC{Shell: shell, File: file, Reload: "source ~/.config/fish/functions/" + name + ".fish"}, nil
+	case "ksh", "ksh93", "mksh":
+		return &RC{Shell: "ksh", File: filepath.Join(home, ".kshrc"), Reload: "source ~/.kshrc"}, nil
+	}
+	if explicit {
+		return nil, fmt.Errorf("unsupported shell: %s (supported: %s)", shell, strings.Join(Shells, ", "))
+	}
+	// Unknown shell: ~/.profile is the POSIX lowest common denominator
+	return &RC{Shell: "sh", File: filepath.Join(home, ".profile"), Reload: "source ~/.profile"}, nil
+}
+
+// Snippet returns the function called name for shell, running 'aio <args>'
+// with the function's arguments and cd-ing into the directory it prints.
+// Output that isn't a directory is printed as is.
+func Snippet(shell string, name string, args []string) string {
+	command := "aio " + shellquote.Join(args...)
+	if shell == "fish" {
+		return fmt.Sprintf(`function %s
+  set target (%s $argv)
+  or return
+  if test -d "$target"
+    cd $target
+  else if test -n "$target"
+    printf '%%s\n' $target
+  end
+end`, name, command)
+	}
+	return fmt.Sprintf(`function %s() {
+  local target
+  target=$(%s "$@") || return
+  if [ -d "$target" ]; then
+    cd "$target"
+  elif [ -n "$target" ]; then
+    printf '%%s\n' "$target"
+  fi
+}`, name, command)
+}
+
+// Block is the markers around a wrapper in an rc file.
+type Block struct {
+	Begin string
+	End   string
+}
+
+// NewBlock returns the markers of the wrapper called name written by installer,
+// e.g. "aio shellwrap".
+func NewBlock(name string, installer string) Block {
+	return Block{
+		Begin: fmt.Sprintf("# >>> %s wrapper (added by %s) >>>", name, installer),
+		End:   fmt.Sprintf("# <<< %s wrapper <<<", name),
+	}
+}
+
+// installedBlock matches the first marker of any wrapper written by aio.
+var installedBlock = regexp.MustCompile(`(?m)^# >>> (\S+) wrapper \(added by aio[^)]*\) >>>$`)
+
+// In reports whether the block is in file.
+func (b Block) In(file string) (bool, error) {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return strings.Contains(string(data), b.Begin), nil
+}
+
+// Write appends the block holding snippet to file.
+func (b Block) Write(file string, snippet string) error {
+	// Ensure parent directory exists (e.g. fish functions/)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("cannot create directory: %w", err)
+	}
+
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot open %s: %w", file, err)
+	}
+	defer f.Close()
+
+	block := fmt.Sprintf("\n%s\n%s\n%s\n", b.Begin, snippet, b.End)
+	if _, err := f.WriteString(block); err != nil {
+		return fmt.Errorf("cannot write to %s: %w", file, err)
+	}
+	return nil
+}
+
+// Remove deletes the block from file.
+func (b Block) Remove(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", file, err)
+	}
+	content := string(data)
+	begin := strings.Index(content, b.Begin)
+	end := strings.Index(content, b.End)
+	if begin < 0 || end < begin {
+		return fmt.Errorf("cannot find the wrapper block in %s", file)
+	}
+	end += len(b.End)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	// Drop the blank line Write puts before the block
+	if begin > 0 && content[begin-1] == '\n' {
+		begin--
+	}
+	content = content[:begin] + content[end:]
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		return fmt.Errorf("cannot write to %s: %w", file, err)
+	}
+	return nil
+}
+
+// Installed returns the names of the wrappers aio installed for shell.
+func Installed(shell string) ([]string, error) {
+	rc, err := Locate(shell, "*")
+	if err != nil {
+		return nil, err
+	}
+	files := []string{rc.File}
+	if rc.Shell == "fish" {
+		files, _ = filepath.Glob(rc.File)
+	}
+	var names []string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range installedBlock.FindAllStringSubmatch(string(data), -1) {
+			names = append(names, m[1])
+		}
+	}
+	return names, nil
+}
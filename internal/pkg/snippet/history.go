@@ -0,0 +1,198 @@
+package snippet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Shells whose history can be imported.
+var Shells = []string{"bash", "zsh", "fish"}
+
+// Candidate is a command that was run often enough to be worth a snippet.
+type Candidate struct {
+	Command string
+	Count   int
+}
+
+// secretPattern matches commands that carry a credential inline; they are
+// never offered as snippets.
+var secretPattern = regexp.MustCompile(`(?i)(password|passwd|token|secret|api[_-]?key)\s*[=:]|authorization:`)
+
+// DetectShell returns the current shell from $SHELL, "" when it isn't supported.
+func DetectShell() string {
+	shell := filepath.Base(os.Getenv("SHELL"))
+	for _, s := range Shells {
+		if s == shell {
+			return s
+		}
+	}
+	return ""
+}
+
+// HistoryFile returns where shell keeps its history.
+func HistoryFile(shell string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	switch shell {
+	case "bash":
+		return filepath.Join(home, ".bash_history"), nil
+	case "zsh":
+		if dir := os.Getenv("ZDOTDIR"); dir != "" {
+			home = dir
+		}
+		return filepath.Join(home, ".zsh_history"), nil
+	case "fish":
+		data := os.Getenv("XDG_DATA_HOME")
+		if data == "" {
+			data = filepath.Join(home, ".local", "share")
+		}
+		return filepath.Join(data, "fish", "fish_history"), nil
+	}
+	return "", fmt.Errorf("unsupported shell '%s' (%s)", shell, strings.Join(Shells, ", "))
+}
+
+// ParseHistory returns the commands of a history file of shell, oldest first.
+func ParseHistory(shell string, data []byte) []string {
+	switch shell {
+	case "zsh":
+		return parseZsh(data)
+	case "fish":
+		return parseFish(data)
+	}
+	return parseBash(data)
+}
+
+// parseBash reads one command per line, skipping the "#<epoch>" lines
+// written with HISTTIMEFORMAT.
+func parseBash(data []byte) []string {
+	var commands []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, "#") && isDigits(line[1:]) {
+			continue
+		}
+		commands = append(commands, line)
+	}
+	return commands
+}
+
+// parseZsh reads plain or extended (": <epoch>:<duration>;<command>") history.
+// Multi-line commands continue while a line ends with a backslash.
+func parseZsh(data []byte) []string {
+	var commands []string
+	var current []string
+	for _, line := range strings.Split(unmetafy(data), "\n") {
+		if len(current) == 0 && strings.HasPrefix(line, ": ") {
+			if _, command, ok := strings.Cut(line, ";"); ok {
+				line = command
+			}
+		}
+		if strings.HasSuffix(line, `\`) {
+			current = append(current, strings.TrimSuffix(line, `\`))
+			continue
+		}
+		current = append(current, line)
+		commands = append(commands, strings.Join(current, "\n"))
+		current = nil
+	}
+	return commands
+}
+
+// unmetafy undoes zsh's encoding of bytes >= 0x83 in the history file.
+func unmetafy(data []byte) string {
+	const meta = 0x83
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] == meta && i+1 < len(data) {
+			i++
+			out = append(out, data[i]^32)
+			continue
+		}
+		out = append(out, data[i])
+	}
+	return string(out)
+}
+
+// parseFish reads the "- cmd: <command>" entries of fish_history.
+func parseFish(data []byte) []string {
+	var commands []string
+	unescape := strings.NewReplacer(`\\`, `\`, `\n`, "\n")
+	for _, line := range strings.Split(string(data), "\n") {
+		if command, ok := strings.CutPrefix(line, "- cmd: "); ok {
+			commands = append(commands, unescape.Replace(command))
+		}
+	}
+	return commands
+}
+
+// Cluster groups identical commands (ignoring spacing) and returns those of
+// at least minLength characters run at least minCount times, most used first.
+// Commands with inline credentials are left out.
+func Cluster(commands []string, minLength int, minCount int) []Candidate {
+	counts := map[string]int{}
+	latest := map[string]string{}
+	for _, command := range commands {
+		command = strings.TrimSpace(command)
+		if len(command) < minLength || secretPattern.MatchString(command) {
+			continue
+		}
+		key := strings.Join(strings.Fields(command), " ")
+		counts[key]++
+		latest[key] = command
+	}
+
+	var candidates []Candidate
+	for key, count := range counts {
+		if count >= minCount {
+			candidates = append(candidates, Candidate{Command: latest[key], Count: count})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Count != candidates[j].Count {
+			return candidates[i].Count > candidates[j].Count
+		}
+		return candidates[i].Command < candidates[j].Command
+	})
+	return candidates
+}
+
+var nameWord = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// SuggestName proposes a snippet name from the first words of command that
+// aren't taken in store, e.g. "kubectl get pods -n prod" -> "kubectl-get-pods".
+func SuggestName(store *Store, command string) string {
+	var words []string
+	for _, word := range strings.Fields(command) {
+		if len(words) == 3 || !nameWord.MatchString(word) {
+			break
+		}
+		words = append(words, strings.ToLower(word))
+	}
+	name := strings.Join(words, "-")
+	if name == "" {
+		name = "snippet"
+	}
+	suggested := name
+	for i := 2; Find(store, suggested) != nil; i++ {
+		suggested = fmt.Sprintf("%s-%d", name, i)
+	}
+	return suggested
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package snippet
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"cli-aio/internal/pkg/config"
+)
+
+// Snippet is a named shell command kept for reuse.
+type Snippet struct {
+	Name        string    `json:"name"`
+	Command     string    `json:"command"`
+	Description string    `json:"description,omitempty"`
+	Created     time.Time `json:"created"`
+}
+
+// Store holds all snippets persisted in snippets.json.
+type Store struct {
+	Snippets []Snippet `json:"snippets"`
+}
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateName checks that name can be typed as an argument.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid snippet name '%s' (use letters, digits, '.', '_' and '-')", name)
+	}
+	return nil
+}
+
+// Load reads the snippet store, returning an empty store when none exists.
+func Load() (*Store, error) {
+	path, err := config.Path("snippets.json")
+	if err != nil {
+		return nil, err
+	}
+	store := &Store{}
+	if _, err := config.ReadJSON(path, store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Save writes the snippet store to disk, sorted by name.
+func Save(store *Store) error {
+	path, err := config.Path("snippets.json")
+	if err != nil {
+		return err
+	}
+	sort.Slice(store.Snippets, func(i, j int) bool { return store.Snippets[i].Name < store.Snippets[j].Name })
+	return config.WriteJSON(path, store)
+}
+
+// Find returns the snippet called name, or nil.
+func Find(store *Store, name string) *Snippet {
+	for i := range store.Snippets {
+		if store.Snippets[i].Name == name {
+			return &store.Snippets[i]
+		}
+	}
+	return nil
+}
+
+// HasCommand reports whether a snippet already holds command.
+func HasCommand(store *Store, command string) bool {
+	for _, s := range store.Snippets {
+		if s.Command == command {
+			return true
+		}
+	}
+	return false
+}
+
+// Add saves command as a new snippet called name.
+func Add(store *Store, name string, command string, description string) (*Snippet, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	if Find(store, name) != nil {
+		return nil, fmt.Errorf("snippet '%s' already exists", name)
+	}
+	store.Snippets = append(store.Snippets, Snippet{
+		Name:        name,
+		Command:     command,
+		Description: description,
+		Created:     time.Now(),
+	})
+	return &store.Snippets[len(store.Snippets)-1], nil
+}
+
+// Remove deletes the snippet called name.
+func Remove(store *Store, name string) error {
+	for i, s := range store.Snippets {
+		if s.Name == name {
+			store.Snippets = append(store.Snippets[:i], store.Snippets[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("snippet '%s' not found", name)
+}
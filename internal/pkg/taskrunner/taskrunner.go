@@ -0,0 +1,208 @@
+package taskrunner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Task sources.
+const (
+	SourceMake = "make"
+	SourceNPM  = "npm"
+	SourceTask = "task"
+)
+
+// Task is a runnable entry discovered in a project.
+type Task struct {
+	Name        string
+	Description string
+	Source      string
+}
+
+// Command returns the binary and arguments that run the task in dir.
+func (t Task) Command(dir string) (string, []string) {
+	switch t.Source {
+	case SourceNPM:
+		return npmClient(dir), []string{"run", t.Name}
+	case SourceTask:
+		return "task", []string{t.Name}
+	default:
+		return "make", []string{t.Name}
+	}
+}
+
+// npmClient picks the package manager matching the project's lockfile.
+func npmClient(dir string) string {
+	if fileExists(filepath.Join(dir, "pnpm-lock.yaml")) {
+		return "pnpm"
+	}
+	if fileExists(filepath.Join(dir, "yarn.lock")) {
+		return "yarn"
+	}
+	return "npm"
+}
+
+// Discover collects tasks from the Makefile, package.json and Taskfile in dir.
+// Sources that don't exist are skipped; a source that exists but can't be
+// parsed is reported as an error.
+func Discover(dir string) ([]Task, error) {
+	var tasks []Task
+	for _, parse := range []func(string) ([]Task, error){makeTasks, npmTasks, taskfileTasks} {
+		found, err := parse(dir)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, found...)
+	}
+	return tasks, nil
+}
+
+var (
+	makeTargetPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_./-]*)\s*:([^=].*)?$`)
+	makeHelpPattern   = regexp.MustCompile(`##\s*(.*)$`)
+)
+
+// makeTasks parses targets from the Makefile. A description is taken from a
+// trailing "## text" comment (the self-documenting Makefile convention) or
+// from the comment line right above the target.
+func makeTasks(dir string) ([]Task, error) {
+	var path string
+	for _, name := range []string{"Makefile", "makefile", "GNUmakefile"} {
+		if p := filepath.Join(dir, name); fileExists(p) {
+			path = p
+			break
+		}
+	}
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var tasks []Task
+	seen := map[string]bool{}
+	lastComment := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			lastComment = strings.TrimSpace(strings.TrimLeft(line, "#"))
+			continue
+		}
+		m := makeTargetPattern.FindStringSubmatch(line)
+		if m == nil {
+			lastComment = ""
+			continue
+		}
+		desc := lastComment
+		lastComment = ""
+		if h := makeHelpPattern.FindStringSubmatch(m[2]); h != nil {
+			desc = strings.TrimSpace(h[1])
+		}
+		for _, name := range strings.Fields(strings.SplitN(line, ":", 2)[0]) {
+			if seen[name] || strings.HasPrefix(name, ".") || strings.Contains(name, "%") {
+				continue
+			}
+			seen[name] = true
+			tasks = append(tasks, Task{Name: name, Description: desc, Source: SourceMake})
+		}
+	}
+	return tasks, nil
+}
+
+// npmTasks parses the "scripts" of package.json; the script body is used as description.
+func npmTasks(dir string) ([]Task, error) {
+	path := filepath.Join(dir, "package.json")
+	if !fileExists(path) {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(pkg.Scripts))
+	for name := range pkg.Scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	tasks := make([]Task, 0, len(names))
+	for _, name := range names {
+		tasks = append(tasks, Task{Name: name, Description: pkg.Scripts[name], Source: SourceNPM})
+	}
+	return tasks, nil
+}
+
+// taskfileTasks parses tasks of a go-task Taskfile, keeping their declaration order.
+func taskfileTasks(dir string) ([]Task, error) {
+	var path string
+	for _, name := range []string{"Taskfile.yml", "Taskfile.yaml", "taskfile.yml", "taskfile.yaml"} {
+		if p := filepath.Join(dir, name); fileExists(p) {
+			path = p
+			break
+		}
+	}
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var file struct {
+		Tasks yaml.Node `yaml:"tasks"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if file.Tasks.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var tasks []Task
+	for i := 0; i+1 < len(file.Tasks.Content); i += 2 {
+		name := file.Tasks.Content[i].Value
+		var def struct {
+			Desc     string `yaml:"desc"`
+			Summary  string `yaml:"summary"`
+			Internal bool   `yaml:"internal"`
+		}
+		// Short forms (a string or a list of commands) have no description
+		if body := file.Tasks.Content[i+1]; body.Kind == yaml.MappingNode {
+			if err := body.Decode(&def); err != nil {
+				return nil, fmt.Errorf("failed to parse task '%s' in %s: %w", name, path, err)
+			}
+		}
+		if def.Internal {
+			continue
+		}
+		desc := def.Desc
+		if desc == "" {
+			desc = strings.TrimSpace(strings.SplitN(def.Summary, "\n", 2)[0])
+		}
+		tasks = append(tasks, Task{Name: name, Description: desc, Source: SourceTask})
+	}
+	return tasks, nil
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
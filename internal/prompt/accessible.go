@@ -0,0 +1,290 @@
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"cli-aio/internal/pkg/profile"
+	"github.com/AlecAivazis/survey/v2/core"
+	"golang.org/x/term"
+)
+
+// menuListed caps the options listed at once by an accessible menu, so a
+// screen reader doesn't read hundreds of branches: typing text narrows them.
+const menuListed = 30
+
+// menu asks questions line by line for screen readers and dumb terminals
+// (ui.Accessible): numbered options, answers typed and ended with Enter, and
+// no cursor movement, colors or redraws.
+type menu struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// stdinReader is shared by the menus on stdin, so lines read ahead by one
+// prompt are there for the next one when answers are piped in.
+var stdinReader *bufio.Reader
+
+// stdMenu returns the menu on stdin and stdout.
+func stdMenu() *menu {
+	if stdinReader == nil {
+		stdinReader = bufio.NewReader(os.Stdin)
+	}
+	return &menu{in: stdinReader, out: os.Stdout}
+}
+
+// ttyMenu returns the menu on tty, for the *OnTTY prompts.
+func ttyMenu(tty *os.File) *menu {
+	return &menu{in: bufio.NewReader(tty), out: tty}
+}
+
+// ask prints question and returns the line typed, without its newline.
+// io.EOF means nothing more can be read.
+func (m *menu) ask(question string) (string, error) {
+	defer profile.Start(profile.Prompt, strings.TrimSpace(question))()
+	fmt.Fprint(m.out, question)
+	line, err := m.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		fmt.Fprintln(m.out)
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// list prints the numbered options, up to menuListed of them.
+func (m *menu) list(options []core.OptionAnswer, mark func(index int) string) {
+	for i, opt := range options {
+		if i == menuListed {
+			fmt.Fprintf(m.out, "  ... %d more, type text to narrow the list\n", len(options)-menuListed)
+			break
+		}
+		fmt.Fprintf(m.out, "  %d) %s%s\n", i+1, mark(opt.Index), opt.Value)
+	}
+}
+
+// selectOne asks for one of options, by number or by narrowing the list with
+// text; "?<number>" shows the preview of an option when there is one.
+func (m *menu) selectOne(message string, options []string, defaultOption string, matcher Matcher, preview Preview) (int, string, error) {
+	filter := ""
+	for {
+		matches := rank(matcher, filter, options)
+		if len(matches) == 0 {
+			fmt.Fprintf(m.out, "No option matches %q.\n", filter)
+			filter = ""
+			continue
+		}
+		fmt.Fprintln(m.out, message)
+		if filter != "" {
+			fmt.Fprintf(m.out, "%d option(s) matching %q:\n", len(matches), filter)
+		}
+		m.list(matches, func(int) string { return "" })
+
+		hint := "Number or text to narrow the list"
+		if preview != nil {
+			hint += ", ?number for details"
+		}
+		def := -1
+		for i, opt := range matches {
+			if opt.Value == defaultOption {
+				def = i
+				break
+			}
+		}
+		if def >= 0 {
+			hint += fmt.Sprintf(", Enter for %d (%s)", def+1, defaultOption)
+		} else if len(matches) == 1 {
+			def = 0
+			hint += ", Enter for 1"
+		}
+		answer, err := m.ask(hint + ": ")
+		if err != nil {
+			return -1, "", err
+		}
+
+		switch n, isNumber := menuNumber(strings.TrimPrefix(answer, "?"), len(matches)); {
+		case answer == "" && def >= 0:
+			return matches[def].Index, matches[def].Value, nil
+		case answer == "":
+			filter = ""
+		case strings.HasPrefix(answer, "?") && preview != nil && isNumber:
+			opt := matches[n]
+			if text := strings.TrimRight(preview(opt.Value, opt.Index), "\n"); text != "" {
+				fmt.Fprintln(m.out, text)
+			} else {
+				fmt.Fprintf(m.out, "No details for %s.\n", opt.Value)
+			}
+		case isNumber && !strings.HasPrefix(answer, "?"):
+			return matches[n].Index, matches[n].Value, nil
+		default:
+			filter = answer
+		}
+	}
+}
+
+// selectMany asks for several options of ms: numbers toggle them, "all" and
+// "none" apply to the listed ones, text narrows the list and an empty line
+// submits the selection when it satisfies the bounds.
+func (m *menu) selectMany(ms *multiSelect) ([]string, error) {
+	if len(ms.options) == 0 {
+		return nil, fmt.Errorf("no options to select from")
+	}
+	ms.checked = map[int]bool{}
+	for i, opt := range ms.options {
+		for _, d := range ms.defaults {
+			if opt == d {
+				ms.checked[i] = true
+			}
+		}
+	}
+	filter := ""
+	for {
+		matches := rank(ms.matcher, filter, ms.options)
+		if len(matches) == 0 {
+			fmt.Fprintf(m.out, "No option matches %q.\n", filter)
+			filter = ""
+			continue
+		}
+		fmt.Fprintf(m.out, "%s (%d selected)\n", ms.message, ms.count())
+		if filter != "" {
+			fmt.Fprintf(m.out, "%d option(s) matching %q:\n", len(matches), filter)
+		}
+		m.list(matches, func(index int) string {
+			if ms.checked[index] {
+				return "[selected] "
+			}
+			return ""
+		})
+		answer, err := m.ask("Numbers to select or unselect, all, none, text to narrow the list, Enter when done: ")
+		if err != nil {
+			return nil, err
+		}
+
+		switch strings.ToLower(answer) {
+		case "":
+			if problem := ms.checkCount(); problem != "" {
+				fmt.Fprintf(m.out, "Cannot continue: %s.\n", problem)
+				continue
+			}
+			var result []string
+			for i, opt := range ms.options {
+				if ms.checked[i] {
+					result = append(result, opt)
+				}
+			}
+			return result, nil
+		case "all", "none":
+			for _, opt := range matches {
+				ms.checked[opt.Index] = answer == "all"
+			}
+			continue
+		}
+		fields := strings.FieldsFunc(answer, func(r rune) bool { return r == ' ' || r == ',' })
+		var picked []int
+		for _, field := range fields {
+			n, ok := menuNumber(field, len(matches))
+			if !ok {
+				picked = nil
+				break
+			}
+			picked = append(picked, n)
+		}
+		if picked == nil {
+			filter = answer
+			continue
+		}
+		for _, n := range picked {
+			index := matches[n].Index
+			ms.checked[index] = !ms.checked[index]
+		}
+	}
+}
+
+// input asks for a line of text, defaultVal when left empty; "?" lists the
+// suggestions for the text typed before it.
+func (m *menu) input(message string, defaultVal string, required bool, suggest func(toComplete string) []string) (string, error) {
+	question := message + " "
+	if defaultVal != "" {
+		question += fmt.Sprintf("(Enter for %s) ", defaultVal)
+	}
+	if suggest != nil {
+		question += "(end with ? for suggestions) "
+	}
+	for {
+		answer, err := m.ask(question)
+		if err != nil {
+			return "", err
+		}
+		if suggest != nil && strings.HasSuffix(answer, "?") {
+			suggestions := suggest(strings.TrimSuffix(answer, "?"))
+			if len(suggestions) == 0 {
+				fmt.Fprintln(m.out, "No suggestions.")
+			}
+			for _, s := range suggestions {
+				fmt.Fprintf(m.out, "  %s\n", s)
+			}
+			continue
+		}
+		if answer == "" {
+			answer = defaultVal
+		}
+		if answer == "" && required {
+			fmt.Fprintln(m.out, "A value is required.")
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// confirm asks a yes/no question.
+func (m *menu) confirm(message string, defaultVal bool) (bool, error) {
+	choices := "yes or no, Enter for no"
+	if defaultVal {
+		choices = "yes or no, Enter for yes"
+	}
+	for {
+		answer, err := m.ask(fmt.Sprintf("%s (%s) ", message, choices))
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return defaultVal, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(m.out, "Please answer yes or no.")
+	}
+}
+
+// password asks for a required value without echoing it.
+func (m *menu) password(message string) (string, error) {
+	for {
+		fmt.Fprint(m.out, message+" ")
+		stop := profile.Start(profile.Prompt, message)
+		data, err := term.ReadPassword(int(os.Stdin.Fd()))
+		stop()
+		fmt.Fprintln(m.out)
+		if err != nil {
+			return "", err
+		}
+		if value := strings.TrimSpace(string(data)); value != "" {
+			return value, nil
+		}
+		fmt.Fprintln(m.out, "A value is required.")
+	}
+}
+
+// menuNumber parses a 1-based option number of a list of n, returning its index.
+func menuNumber(s string, n int) (int, bool) {
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 1 || i > n {
+		return 0, false
+	}
+	return i - 1, true
+}
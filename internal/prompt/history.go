@@ -0,0 +1,80 @@
+package prompt
+
+import (
+	"fmt"
+	"slices"
+
+	"cli-aio/internal/pkg/config"
+)
+
+// historySize is how many answers are kept per prompt.
+const historySize = 10
+
+// historyFile keeps the recent answers of named prompts, most recent first.
+const historyFile = "prompt-history.json"
+
+// newAnswer is the choice to type an answer that isn't in the history.
+const newAnswer = "(new value)"
+
+// History returns the recent answers to the prompt called name, most recent first.
+func History(name string) []string {
+	path, err := config.Path(historyFile)
+	if err != nil {
+		return nil
+	}
+	history := map[string][]string{}
+	if _, err := config.ReadJSON(path, &history); err != nil {
+		return nil
+	}
+	return history[name]
+}
+
+// Remember records answer as the most recent to the prompt called name, e.g.
+// "jira-ticket". History is a convenience: failing to save it only warns.
+func Remember(name string, answer string) {
+	if answer == "" {
+		return
+	}
+	path, err := config.Path(historyFile)
+	if err != nil {
+		return
+	}
+	history := map[string][]string{}
+	if _, err := config.ReadJSON(path, &history); err != nil {
+		return
+	}
+	recent := slices.DeleteFunc(history[name], func(a string) bool { return a == answer })
+	recent = append([]string{answer}, recent...)
+	history[name] = recent[:min(len(recent), historySize)]
+	if err := config.WriteJSON(path, history); err != nil {
+		fmt.Printf("[!] Warning: failed to save prompt history: %v\n", err)
+	}
+}
+
+// InputWithHistory is InputWithSuggestions for the prompt called name: the
+// recent answers are offered first, the last one preselected, before typing a
+// new one. The answer is remembered for next time.
+func InputWithHistory(name string, message string, required bool, suggest func(toComplete string) []string) (string, error) {
+	if err := checkPlain(); err != nil {
+		return "", err
+	}
+	recent := History(name)
+	answer := ""
+	if len(recent) > 0 {
+		_, choice, err := SelectWithFuzzy(message, append(slices.Clone(recent), newAnswer), recent[0], false)
+		if err != nil {
+			return "", err
+		}
+		if choice != newAnswer {
+			answer = choice
+		}
+	}
+	if answer == "" {
+		var err error
+		if answer, err = InputWithSuggestions(message, "", required, suggest); err != nil {
+			return "", err
+		}
+	}
+	Remember(name, answer)
+	return answer, nil
+}
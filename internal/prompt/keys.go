@@ -0,0 +1,195 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"cli-aio/internal/pkg/config"
+	"github.com/AlecAivazis/survey/v2/terminal"
+)
+
+// Action is what a key does in a selection list.
+type Action string
+
+// The actions prompt.keys in config.json can bind keys to.
+const (
+	ActionUp      Action = "up"
+	ActionDown    Action = "down"
+	ActionAccept  Action = "accept"
+	ActionCancel  Action = "cancel"
+	ActionToggle  Action = "toggle"  // multi-select: (un)select the highlighted option
+	ActionAll     Action = "all"     // multi-select: select the filtered options
+	ActionNone    Action = "none"    // multi-select: unselect the filtered options
+	ActionInvert  Action = "invert"  // multi-select: invert the filtered options
+	ActionPreview Action = "preview" // show or hide the preview of the highlighted option
+	ActionClear   Action = "clear"   // clear the filter
+)
+
+// KeyTogglePreview shows or hides the preview of a selection list (Ctrl+T).
+const KeyTogglePreview rune = 20
+
+// defaultKeys are the keys of each action without prompt.keys.
+var defaultKeys = map[Action][]rune{
+	ActionUp:      {terminal.KeyArrowUp},
+	ActionDown:    {terminal.KeyArrowDown, terminal.KeyTab},
+	ActionAccept:  {terminal.KeyEnter, terminal.KeyEndTransmission},
+	ActionCancel:  nil, // ctrl-c always cancels
+	ActionToggle:  {terminal.KeySpace},
+	ActionAll:     {terminal.KeyArrowRight},
+	ActionNone:    {terminal.KeyArrowLeft},
+	ActionInvert:  {KeyInvert},
+	ActionPreview: {KeyTogglePreview},
+	ActionClear:   {terminal.KeyDeleteWord, terminal.KeyDeleteLine},
+}
+
+// keyNames are the named keys of prompt.keys. survey reads the arrows as the
+// Emacs keys (up is ctrl-p, down ctrl-n, right ctrl-f, left ctrl-b), so
+// binding one binds the other.
+var keyNames = map[string]rune{
+	"up":        terminal.KeyArrowUp,
+	"down":      terminal.KeyArrowDown,
+	"left":      terminal.KeyArrowLeft,
+	"right":     terminal.KeyArrowRight,
+	"enter":     terminal.KeyEnter,
+	"tab":       terminal.KeyTab,
+	"space":     terminal.KeySpace,
+	"esc":       terminal.KeyEscape,
+	"backspace": terminal.KeyDelete,
+}
+
+// ParseKey parses a key of prompt.keys: a name (up, enter, space, esc, ...),
+// ctrl-<letter> or a single character.
+func ParseKey(name string) (rune, error) {
+	lower := strings.ToLower(name)
+	if r, ok := keyNames[lower]; ok {
+		return r, nil
+	}
+	if letter, ok := strings.CutPrefix(lower, "ctrl-"); ok && len(letter) == 1 && letter[0] >= 'a' && letter[0] <= 'z' {
+		switch letter {
+		case "c":
+			return 0, fmt.Errorf("ctrl-c always cancels and can't be rebound")
+		case "j", "m":
+			// The terminal sends Enter as one of them, turned into the other
+			return terminal.KeyEnter, nil
+		}
+		return rune(letter[0]-'a') + 1, nil
+	}
+	if runes := []rune(name); len(runes) == 1 && runes[0] > terminal.KeySpace {
+		return runes[0], nil
+	}
+	return 0, fmt.Errorf("unknown key %q (expected e.g. up, enter, space, esc, ctrl-j or a character)", name)
+}
+
+// Keymap maps keys to the actions of the selection lists.
+type Keymap struct {
+	keys map[rune]Action
+	// rebound are the actions not on their default keys, whose help changes
+	rebound map[Action]bool
+}
+
+// NewKeymap returns the default keys with the actions of bindings (action ->
+// keys) bound to their keys instead. A key bound by bindings is taken from
+// the default action that had it.
+func NewKeymap(bindings map[string][]string) (Keymap, error) {
+	keymap := Keymap{keys: map[rune]Action{}, rebound: map[Action]bool{}}
+	for action, keys := range defaultKeys {
+		if _, ok := bindings[string(action)]; ok {
+			continue
+		}
+		for _, key := range keys {
+			keymap.keys[key] = action
+		}
+	}
+	actions := make([]string, 0, len(bindings))
+	for action := range bindings {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	bound := map[rune]string{}
+	for _, action := range actions {
+		if _, ok := defaultKeys[Action(action)]; !ok {
+			return Keymap{}, fmt.Errorf("unknown action %q in prompt.keys (expected up, down, accept, cancel, toggle, all, none, invert, preview or clear)", action)
+		}
+		for _, name := range bindings[action] {
+			key, err := ParseKey(name)
+			if err != nil {
+				return Keymap{}, fmt.Errorf("prompt.keys.%s: %w", action, err)
+			}
+			if other, ok := bound[key]; ok && other != action {
+				return Keymap{}, fmt.Errorf("prompt.keys: %s is bound to both %s and %s", name, other, action)
+			}
+			bound[key] = action
+			if previous, ok := keymap.keys[key]; ok && previous != Action(action) {
+				keymap.rebound[previous] = true
+			}
+			keymap.keys[key] = Action(action)
+		}
+		keymap.rebound[Action(action)] = true
+	}
+	return keymap, nil
+}
+
+// configuredKeymap returns the keymap of config.json, warning and falling
+// back to the default keys when it's invalid. Like configuredMatcher it
+// warns on stderr for the *OnTTY prompts.
+func configuredKeymap() Keymap {
+	cfg, err := config.Load()
+	if err != nil {
+		keymap, _ := NewKeymap(nil)
+		return keymap
+	}
+	keymap, err := NewKeymap(cfg.Prompt.Keys)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[!] Warning: %v\n", err)
+		keymap, _ = NewKeymap(nil)
+	}
+	return keymap
+}
+
+// Action returns the action of key, "" for a key typed into the filter.
+func (k Keymap) Action(key rune) Action {
+	if key == '\n' {
+		key = terminal.KeyEnter
+	}
+	return k.keys[key]
+}
+
+// Rebound reports whether any of actions is not on its default keys.
+func (k Keymap) Rebound(actions ...Action) bool {
+	for _, action := range actions {
+		if k.rebound[action] {
+			return true
+		}
+	}
+	return false
+}
+
+// Describe returns the keys of action for the help line, e.g. "space".
+func (k Keymap) Describe(action Action) string {
+	var names []string
+	for key, a := range k.keys {
+		if a == action {
+			names = append(names, keyName(key))
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, "/")
+}
+
+// keyName is the name of key as prompt.keys spells it.
+func keyName(key rune) string {
+	if key == terminal.KeyEndTransmission {
+		return "ctrl-d"
+	}
+	for name, r := range keyNames {
+		if r == key {
+			return name
+		}
+	}
+	if key < terminal.KeySpace {
+		return fmt.Sprintf("ctrl-%c", 'a'+key-1)
+	}
+	return string(key)
+}
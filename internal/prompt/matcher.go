@@ -0,0 +1,202 @@
+package prompt
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"cli-aio/internal/pkg/config"
+	"github.com/AlecAivazis/survey/v2/core"
+)
+
+// Matcher decides which options of a selection list match the filter typed
+// so far, and how well. It runs on every option at each key press, so it must
+// stay well under a millisecond for thousands of options.
+type Matcher interface {
+	// Match reports whether option matches filter, with a score ranking the
+	// matches: higher first, equal scores in list order.
+	Match(filter string, option string) (int, bool)
+}
+
+// Matchers are the matchers prompt.matcher in config.json can name.
+var Matchers = map[string]Matcher{
+	"subsequence": Subsequence{},
+	"fzf":         FzfScore{},
+	"substring":   Substring{},
+}
+
+// DefaultMatcher is the matcher used without prompt.matcher.
+const DefaultMatcher = "subsequence"
+
+// MatcherNamed returns the matcher called name, the default one for "".
+func MatcherNamed(name string) (Matcher, error) {
+	if name == "" {
+		name = DefaultMatcher
+	}
+	m, ok := Matchers[name]
+	if !ok {
+		return nil, fmt.Errorf("invalid prompt.matcher: %s (expected subsequence, fzf or substring)", name)
+	}
+	return m, nil
+}
+
+// configuredMatcher returns the matcher of config.json, warning and falling
+// back to the default one when it's invalid. The warning goes to stderr as
+// the *OnTTY prompts run with stdout captured.
+func configuredMatcher() Matcher {
+	cfg, err := config.Load()
+	if err != nil {
+		return Subsequence{}
+	}
+	m, err := MatcherNamed(cfg.Prompt.Matcher)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[!] Warning: %v\n", err)
+		return Subsequence{}
+	}
+	return m
+}
+
+// rank returns the options matching filter, best first.
+func rank(m Matcher, filter string, options []string) []core.OptionAnswer {
+	if filter == "" {
+		return core.OptionAnswerList(options)
+	}
+	var matches []core.OptionAnswer
+	var scores []int
+	for i, opt := range options {
+		if score, ok := m.Match(filter, opt); ok {
+			matches = append(matches, core.OptionAnswer{Index: i, Value: opt})
+			scores = append(scores, score)
+		}
+	}
+	sort.Stable(byScore{matches, scores})
+	return matches
+}
+
+type byScore struct {
+	matches []core.OptionAnswer
+	scores  []int
+}
+
+func (b byScore) Len() int           { return len(b.matches) }
+func (b byScore) Less(i, j int) bool { return b.scores[i] > b.scores[j] }
+func (b byScore) Swap(i, j int) {
+	b.matches[i], b.matches[j] = b.matches[j], b.matches[i]
+	b.scores[i], b.scores[j] = b.scores[j], b.scores[i]
+}
+
+// Subsequence matches when the letters of the filter appear in the option in
+// order, case-insensitively, keeping the list order.
+type Subsequence struct{}
+
+// Match implements Matcher.
+func (Subsequence) Match(filter string, option string) (int, bool) {
+	want := []rune(strings.ToLower(filter))
+	i := 0
+	for _, r := range strings.ToLower(option) {
+		if i < len(want) && r == want[i] {
+			i++
+		}
+	}
+	return 0, i == len(want)
+}
+
+// Substring matches when the option contains the filter, case-insensitively,
+// the earliest occurrences first.
+type Substring struct{}
+
+// Match implements Matcher.
+func (Substring) Match(filter string, option string) (int, bool) {
+	i := strings.Index(strings.ToLower(option), strings.ToLower(filter))
+	return -i, i >= 0
+}
+
+// Scores of FzfScore, after fzf's: matched letters earn points, more at the
+// start of words and next to each other, gaps cost some.
+const (
+	scoreMatch       = 16
+	bonusBoundary    = 8
+	bonusConsecutive = 4
+	bonusFirst       = 8
+	penaltyGapStart  = 3
+	penaltyGapExtend = 1
+)
+
+// FzfScore matches as Subsequence does and ranks the matches as fzf does:
+// compact matches at word starts first, e.g. "pa" ranks payment-api above
+// backup-all.
+type FzfScore struct{}
+
+// Match implements Matcher. It scores the best of the ways the filter's
+// letters can be found in the option, keeping per position the best score of
+// a match of the filter so far ending there.
+func (FzfScore) Match(filter string, option string) (int, bool) {
+	want := []rune(strings.ToLower(filter))
+	if len(want) == 0 {
+		return 0, true
+	}
+	if _, ok := (Subsequence{}).Match(filter, option); !ok {
+		return 0, false
+	}
+	text := []rune(option)
+	lower := []rune(strings.ToLower(option))
+
+	const none = math.MinInt / 2
+	prev := make([]int, len(text))
+	cur := make([]int, len(text))
+	for i, w := range want {
+		// reach is the best score of the previous letters followed by a gap
+		// ending before j, plus j*penaltyGapExtend so it doesn't need updating
+		reach := none
+		for j, r := range lower {
+			cur[j] = none
+			if r == w {
+				bonus := scoreMatch
+				if j == 0 || isBoundary(text[j-1], text[j]) {
+					bonus += bonusBoundary
+					if i == 0 {
+						bonus += bonusFirst
+					}
+				}
+				switch {
+				case i == 0:
+					cur[j] = bonus
+				case j > 0:
+					best := reach - (j-1)*penaltyGapExtend - (penaltyGapStart - penaltyGapExtend)
+					if prev[j-1] > none {
+						best = max(best, prev[j-1]+bonusConsecutive)
+					}
+					if best > none/2 {
+						cur[j] = best + bonus
+					}
+				}
+			}
+			if i > 0 && j > 0 && prev[j-1] > none {
+				reach = max(reach, prev[j-1]+(j-1)*penaltyGapExtend)
+			}
+		}
+		prev, cur = cur, prev
+	}
+	score := none
+	for _, s := range prev {
+		score = max(score, s)
+	}
+	return score, true
+}
+
+// isBoundary reports whether cur starts a word after prev: after a separator
+// or a lowercase letter followed by an uppercase one (camelCase).
+func isBoundary(prev rune, cur rune) bool {
+	switch {
+	case strings.ContainsRune("/-_. :\\#", prev):
+		return true
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return true
+	case !unicode.IsLetter(prev) && !unicode.IsDigit(prev) && unicode.IsLetter(cur):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package prompt
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMatchers(t *testing.T) {
+	tests := []struct {
+		matcher Matcher
+		filter  string
+		option  string
+		ok      bool
+	}{
+		{Subsequence{}, "pa", "payment-api", true},
+		{Subsequence{}, "PAPI", "payment-api", true},
+		{Subsequence{}, "ap", "pa", false},
+		{Subsequence{}, "", "anything", true},
+		{Substring{}, "api", "payment-API", true},
+		{Substring{}, "papi", "payment-api", false},
+		{FzfScore{}, "fb", "feature/billing", true},
+		{FzfScore{}, "bf", "feature/billing", false},
+	}
+	for _, tt := range tests {
+		if _, ok := tt.matcher.Match(tt.filter, tt.option); ok != tt.ok {
+			t.Errorf("%T.Match(%q, %q) = %v, want %v", tt.matcher, tt.filter, tt.option, ok, tt.ok)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	options := []string{"backup-all", "payment-api", "ops/pay-admin"}
+	tests := []struct {
+		matcher Matcher
+		filter  string
+		want    []string
+	}{
+		// Subsequence keeps the list order
+		{Subsequence{}, "pa", []string{"backup-all", "payment-api", "ops/pay-admin"}},
+		// Earliest occurrence first
+		{Substring{}, "pa", []string{"payment-api", "ops/pay-admin"}},
+		// Compact matches at word starts first
+		{FzfScore{}, "pa", []string{"payment-api", "ops/pay-admin", "backup-all"}},
+		{FzfScore{}, "pad", []string{"ops/pay-admin"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, match := range rank(tt.matcher, tt.filter, options) {
+			got = append(got, match.Value)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("rank(%T, %q) = %v, want %v", tt.matcher, tt.filter, got, tt.want)
+		}
+	}
+}
+
+// benchOptions are 10k branch and project names like the ones of a big
+// monorepo, the size the matchers must stay fast for.
+var benchOptions = func() []string {
+	kinds := []string{"feature", "bugfix", "hotfix", "release", "chore", "ops/deploy", "bank/operation", "payment"}
+	words := []string{"billing", "payment-api", "user_profile", "checkoutFlow", "ledger", "risk.engine", "notify", "kyc"}
+	options := make([]string, 10000)
+	for i := range options {
+		options[i] = fmt.Sprintf("%s/%s-%s-%04d", kinds[i%len(kinds)], words[i/len(kinds)%len(words)], words[i*7%len(words)], i)
+	}
+	return options
+}()
+
+// benchFilters are typed one key at a time, as the list is filtered again
+// at each key press.
+var benchFilters = []string{"p", "pa", "pay", "paym", "payapi", "fbill", "zzz"}
+
+func benchmarkMatcher(b *testing.B, m Matcher) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, filter := range benchFilters {
+			rank(m, filter, benchOptions)
+		}
+	}
+}
+
+func BenchmarkSubsequence(b *testing.B) { benchmarkMatcher(b, Subsequence{}) }
+func BenchmarkSubstring(b *testing.B)   { benchmarkMatcher(b, Substring{}) }
+func BenchmarkFzfScore(b *testing.B)    { benchmarkMatcher(b, FzfScore{}) }
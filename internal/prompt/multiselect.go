@@ -0,0 +1,272 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/core"
+	"github.com/AlecAivazis/survey/v2/terminal"
+)
+
+// KeyInvert inverts the selection of the filtered options in MultiSelect (Ctrl+R).
+const KeyInvert rune = 18
+
+// MultiSelectOption customizes MultiSelect and MultiSelectOnTTY.
+type MultiSelectOption func(*multiSelect)
+
+// WithMin refuses to submit fewer than n selected options.
+func WithMin(n int) MultiSelectOption {
+	return func(m *multiSelect) {
+		m.min = n
+	}
+}
+
+// WithMax refuses to submit more than n selected options.
+func WithMax(n int) MultiSelectOption {
+	return func(m *multiSelect) {
+		m.max = n
+	}
+}
+
+// multiSelect is survey's MultiSelect with a key inverting the selection and
+// bounds on the number of selected options, checked before the prompt closes
+// so the selection isn't lost as it is with a survey validator.
+type multiSelect struct {
+	survey.Renderer
+	message  string
+	options  []string
+	defaults []string
+	min, max int
+	matcher  Matcher
+	keymap   Keymap
+
+	filter        string
+	selectedIndex int
+	checked       map[int]bool
+	problem       string
+}
+
+// multiSelectTemplate is survey's multi-select template with the keys (%s,
+// see keysHelp) and the selection problem in the help line.
+const multiSelectTemplate = `
+{{- define "option"}}
+    {{- if eq .SelectedIndex .CurrentIndex }}{{color .Config.Icons.SelectFocus.Format }}{{ .Config.Icons.SelectFocus.Text }}{{color "reset"}}{{else}} {{end}}
+    {{- if index .Checked .CurrentOpt.Index }}{{color .Config.Icons.MarkedOption.Format }} {{ .Config.Icons.MarkedOption.Text }} {{else}}{{color .Config.Icons.UnmarkedOption.Format }} {{ .Config.Icons.UnmarkedOption.Text }} {{end}}
+    {{- color "reset"}}
+    {{- " "}}{{- .CurrentOpt.Value}}
+{{end}}
+{{- color .Config.Icons.Question.Format }}{{ .Config.Icons.Question.Text }} {{color "reset"}}
+{{- color "default+hb"}}{{ .Message }}{{ .FilterMessage }}{{color "reset"}}
+{{- if .ShowAnswer}}{{color "cyan"}} {{.Answer}}{{color "reset"}}{{"\n"}}
+{{- else }}
+  {{- if .Help }}{{- "  "}}{{- color "red"}}[{{ .Help }}]{{color "reset"}}
+  {{- else }}{{- "  "}}{{- color "cyan"}}[%s]{{color "reset"}}{{end}}
+  {{- "\n"}}
+  {{- range $ix, $option := .PageEntries}}
+    {{- template "option" $.IterateOption $ix $option}}
+  {{- end}}
+{{- end}}`
+
+// filtered returns the options matching the filter typed so far, best first.
+func (m *multiSelect) filtered() []core.OptionAnswer {
+	return rank(m.matcher, m.filter, m.options)
+}
+
+// count returns the number of selected options.
+func (m *multiSelect) count() int {
+	n := 0
+	for _, on := range m.checked {
+		if on {
+			n++
+		}
+	}
+	return n
+}
+
+// checkCount returns why the selection can't be submitted, "" when it can.
+func (m *multiSelect) checkCount() string {
+	switch n := m.count(); {
+	case m.min > 0 && n < m.min:
+		return fmt.Sprintf("select at least %d, %d selected", m.min, n)
+	case m.max > 0 && n > m.max:
+		return fmt.Sprintf("select at most %d, %d selected", m.max, n)
+	}
+	return ""
+}
+
+// render draws the prompt with the options of the current page.
+func (m *multiSelect) render(config *survey.PromptConfig) error {
+	options := m.filtered()
+	opts, idx := paginate(config.PageSize, options, m.selectedIndex)
+	data := survey.MultiSelectTemplateData{
+		MultiSelect:   m.templateFields(),
+		Checked:       m.checked,
+		SelectedIndex: idx,
+		PageEntries:   opts,
+		Config:        config,
+	}
+	return m.RenderWithCursorOffset(fmt.Sprintf(multiSelectTemplate, m.keysHelp()), data, opts, idx)
+}
+
+// keysHelp describes the keys in the help line, naming them as prompt.keys
+// does when they were rebound.
+func (m *multiSelect) keysHelp() string {
+	k := m.keymap
+	if !k.Rebound(ActionUp, ActionDown, ActionToggle, ActionAll, ActionNone, ActionInvert) {
+		return "Use arrows to move, space to select, <right> all, <left> none, ctrl-r invert, type to filter"
+	}
+	return fmt.Sprintf("Use %s/%s to move, %s to select, %s all, %s none, %s invert, type to filter",
+		k.Describe(ActionUp), k.Describe(ActionDown), k.Describe(ActionToggle), k.Describe(ActionAll), k.Describe(ActionNone), k.Describe(ActionInvert))
+}
+
+// templateFields returns the fields of survey.MultiSelect the template reads.
+func (m *multiSelect) templateFields() survey.MultiSelect {
+	fields := survey.MultiSelect{Message: m.message, Help: m.problem}
+	if m.filter != "" {
+		fields.FilterMessage = " " + m.filter
+	}
+	return fields
+}
+
+// onKey updates the state for key and reports whether the prompt is done.
+func (m *multiSelect) onKey(key rune, config *survey.PromptConfig) bool {
+	options := m.filtered()
+	oldFilter := m.filter
+	m.problem = ""
+
+	action := m.keymap.Action(key)
+	switch {
+	case action == ActionAccept:
+		if m.problem = m.checkCount(); m.problem == "" {
+			return true
+		}
+	case action == ActionUp:
+		m.selectedIndex--
+		if m.selectedIndex < 0 {
+			m.selectedIndex = len(options) - 1
+		}
+	case action == ActionDown:
+		m.selectedIndex++
+		if m.selectedIndex >= len(options) {
+			m.selectedIndex = 0
+		}
+	case action == ActionToggle:
+		if m.selectedIndex < len(options) {
+			index := options[m.selectedIndex].Index
+			m.checked[index] = !m.checked[index]
+		}
+	case action == ActionAll, action == ActionNone, action == ActionInvert:
+		for _, opt := range options {
+			switch action {
+			case ActionAll:
+				m.checked[opt.Index] = true
+			case ActionNone:
+				m.checked[opt.Index] = false
+			default:
+				m.checked[opt.Index] = !m.checked[opt.Index]
+			}
+		}
+	case action == ActionClear:
+		m.filter = ""
+	case key == terminal.KeyDelete || key == terminal.KeyBackspace:
+		if m.filter != "" {
+			runes := []rune(m.filter)
+			m.filter = string(runes[:len(runes)-1])
+		}
+	case key > terminal.KeySpace:
+		m.filter += string(key)
+	}
+
+	if oldFilter != m.filter {
+		// The best match comes first: highlight it
+		m.selectedIndex = 0
+	}
+	return false
+}
+
+// Prompt implements survey.Prompt.
+func (m *multiSelect) Prompt(config *survey.PromptConfig) (interface{}, error) {
+	if len(m.options) == 0 {
+		return nil, errors.New("no options to select from")
+	}
+	m.checked = map[int]bool{}
+	for i, opt := range m.options {
+		for _, d := range m.defaults {
+			if opt == d {
+				m.checked[i] = true
+			}
+		}
+	}
+
+	cursor := m.NewCursor()
+	cursor.Save()          // for proper cursor placement during selection
+	cursor.Hide()          // hide the cursor
+	defer cursor.Show()    // show the cursor when we're done
+	defer cursor.Restore() // clear any accessibility offsetting on exit
+	if err := m.render(config); err != nil {
+		return nil, err
+	}
+
+	rr := m.NewRuneReader()
+	_ = rr.SetTermMode()
+	defer func() {
+		_ = rr.RestoreTermMode()
+	}()
+	for {
+		r, _, err := rr.ReadRune()
+		if err != nil {
+			return nil, err
+		}
+		if r == terminal.KeyInterrupt || m.keymap.Action(r) == ActionCancel {
+			return nil, terminal.InterruptErr
+		}
+		if m.onKey(r, config) {
+			break
+		}
+		_ = m.render(config)
+	}
+	m.filter = ""
+
+	var answers []core.OptionAnswer
+	for i, opt := range m.options {
+		if m.checked[i] {
+			answers = append(answers, core.OptionAnswer{Value: opt, Index: i})
+		}
+	}
+	return answers, nil
+}
+
+// Cleanup implements survey.Prompt: it replaces the list with the answer.
+func (m *multiSelect) Cleanup(config *survey.PromptConfig, val interface{}) error {
+	var values []string
+	for _, answer := range val.([]core.OptionAnswer) {
+		values = append(values, answer.Value)
+	}
+	return m.Render(fmt.Sprintf(multiSelectTemplate, m.keysHelp()), survey.MultiSelectTemplateData{
+		MultiSelect: m.templateFields(),
+		Checked:     m.checked,
+		Answer:      strings.Join(values, ", "),
+		ShowAnswer:  true,
+		Config:      config,
+	})
+}
+
+// paginate returns the page of choices around sel, as survey does, and the
+// index of sel in it.
+func paginate(pageSize int, choices []core.OptionAnswer, sel int) ([]core.OptionAnswer, int) {
+	if pageSize <= 0 || len(choices) <= pageSize {
+		return choices, sel
+	}
+	var start int
+	switch {
+	case sel < pageSize/2:
+		start = 0
+	case len(choices)-sel-1 < pageSize/2:
+		start = len(choices) - pageSize
+	default:
+		start = sel - pageSize/2
+	}
+	return choices[start : start+pageSize], sel - start
+}
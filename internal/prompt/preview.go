@@ -0,0 +1,111 @@
+package prompt
+
+import (
+	"cli-aio/internal/ui"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// Preview returns the text shown below the list for the highlighted option,
+// e.g. the git log of a branch. index is the option's position in the list.
+// It runs once per option, the first time it's highlighted.
+type Preview func(option string, index int) string
+
+// SelectOption customizes Select, SelectWithFuzzy and SelectOnTTY.
+type SelectOption func(*selectOptions)
+
+type selectOptions struct {
+	preview Preview
+}
+
+// WithPreview shows preview(option) below the list for the highlighted option.
+func WithPreview(preview Preview) SelectOption {
+	return func(o *selectOptions) {
+		o.preview = preview
+	}
+}
+
+// previewOf returns the preview set by opts, nil without one.
+func previewOf(opts []SelectOption) Preview {
+	var o selectOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o.preview
+}
+
+// previewLines caps the height of a preview so the list stays on screen.
+const previewLines = 12
+
+// previewTemplate is survey's select template without the option descriptions,
+// which carry the preview, and with the description of the highlighted option
+// below the list instead.
+const previewTemplate = `
+{{- define "option"}}
+    {{- if eq .SelectedIndex .CurrentIndex }}{{color .Config.Icons.SelectFocus.Format }}{{ .Config.Icons.SelectFocus.Text }} {{else}}{{color "default"}}  {{end}}
+    {{- .CurrentOpt.Value}}
+    {{- color "reset"}}
+{{end}}
+{{- if .ShowHelp }}{{- color .Config.Icons.Help.Format }}{{ .Config.Icons.Help.Text }} {{ .Help }}{{color "reset"}}{{"\n"}}{{end}}
+{{- color .Config.Icons.Question.Format }}{{ .Config.Icons.Question.Text }} {{color "reset"}}
+{{- color "default+hb"}}{{ .Message }}{{ .FilterMessage }}{{color "reset"}}
+{{- if .ShowAnswer}}{{color "cyan"}} {{.Answer}}{{color "reset"}}{{"\n"}}
+{{- else}}
+  {{- "  "}}{{- color "cyan"}}[Use arrows to move, type to filter{{- if and .Help (not .ShowHelp)}}, {{ .Config.HelpInput }} for more help{{end}}]{{color "reset"}}
+  {{- "\n"}}
+  {{- range $ix, $option := .PageEntries}}
+    {{- template "option" $.IterateOption $ix $option}}
+  {{- end}}
+  {{- range $ix, $option := .PageEntries}}
+    {{- if eq $ix $.SelectedIndex }}{{ with $.GetDescription $option }}{{"\n"}}{{color "default"}}{{ . }}{{color "reset"}}{{"\n"}}{{end}}{{end}}
+  {{- end}}
+{{- end}}`
+
+// applySelectOptions sets opts up on p and returns the function undoing the
+// global changes they need, to defer.
+func applySelectOptions(p *survey.Select, opts []SelectOption) func() {
+	var o selectOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.preview == nil {
+		return func() {}
+	}
+
+	cache := map[int]string{}
+	p.Description = func(value string, index int) string {
+		text, ok := cache[index]
+		if !ok {
+			text = formatPreview(o.preview(value, index))
+			cache[index] = text
+		}
+		return text
+	}
+	// survey renders every Select with this global template
+	original := survey.SelectQuestionTemplate
+	survey.SelectQuestionTemplate = previewTemplate
+	return func() { survey.SelectQuestionTemplate = original }
+}
+
+// formatPreview indents text and cuts it to previewLines lines that don't
+// wrap, as survey redraws the prompt by counting the lines it printed.
+func formatPreview(text string) string {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\t", "    "), "\n")
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) > previewLines {
+		lines = append(lines[:previewLines], "…")
+	}
+	width := ui.Width()
+	for i, line := range lines {
+		line = "  " + strings.TrimRight(line, "\r")
+		if width > 1 {
+			line = ui.Truncate(line, width-1)
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -1,6 +1,9 @@
 package prompt
 
 import (
+	"cli-aio/internal/pkg/profile"
+	"cli-aio/internal/ui"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -22,19 +25,49 @@ func IsInteractive(interactiveFlag bool) bool {
 	return term.IsTerminal(int(os.Stdin.Fd()))
 }
 
+// ErrPlain is returned by the prompts in plain mode, where nobody is there to answer.
+var ErrPlain = errors.New("no interactive prompt in plain mode (stdout is not a terminal), pass the value as a flag or argument")
+
+// checkPlain refuses to prompt in plain mode: survey renders on stdout, which
+// would end up in the pipe. The *OnTTY prompts render on /dev/tty and are exempt.
+func checkPlain() error {
+	if ui.Plain() {
+		return ErrPlain
+	}
+	return nil
+}
+
+// askOne is survey.AskOne, timed as a prompt step for --profile.
+func askOne(message string, p survey.Prompt, response interface{}, opts ...survey.AskOpt) error {
+	defer profile.Start(profile.Prompt, message)()
+	return survey.AskOne(p, response, opts...)
+}
+
 // Select prompts the user to select from a list of options.
 // Returns the selected option index and value.
 // If defaultOption is empty, the first option will be used as default.
-func Select(message string, options []string, defaultOption string) (int, string, error) {
-	return SelectWithFuzzy(message, options, defaultOption, true)
+func Select(message string, options []string, defaultOption string, opts ...SelectOption) (int, string, error) {
+	return SelectWithFuzzy(message, options, defaultOption, true, opts...)
 }
 
 // SelectWithFuzzy prompts the user to select from a list of options with optional fuzzy search.
 // If fuzzy is true, enables fuzzy search filtering.
-func SelectWithFuzzy(message string, options []string, defaultOption string, fuzzy bool) (int, string, error) {
+func SelectWithFuzzy(message string, options []string, defaultOption string, fuzzy bool, opts ...SelectOption) (int, string, error) {
 	if len(options) == 0 {
 		return -1, "", fmt.Errorf("no options to select from")
 	}
+	if err := checkPlain(); err != nil {
+		return -1, "", err
+	}
+
+	// Without fuzzy search, options match when they contain the filter
+	var matcher Matcher = Substring{}
+	if fuzzy {
+		matcher = configuredMatcher()
+	}
+	if ui.Accessible() {
+		return stdMenu().selectOne(message, options, defaultOption, matcher, previewOf(opts))
+	}
 
 	var selected string
 	prompt := &survey.Select{
@@ -52,14 +85,9 @@ func SelectWithFuzzy(message string, options []string, defaultOption string, fuz
 		}
 	}
 
-	var err error
-	if fuzzy {
-		// Enable fuzzy search with a custom filter
-		err = survey.AskOne(prompt, &selected, survey.WithFilter(fuzzyFilter))
-	} else {
-		err = survey.AskOne(prompt, &selected)
-	}
+	defer applySelectOptions(prompt, opts)()
 
+	err := askOne(message, &rankedSelect{Select: prompt, matcher: matcher, keymap: configuredKeymap()}, &selected)
 	if err != nil {
 		return -1, "", err
 	}
@@ -76,7 +104,7 @@ func SelectWithFuzzy(message string, options []string, defaultOption string, fuz
 // SelectOnTTY is like Select but forces all survey I/O through /dev/tty.
 // Use this when stdout is captured (e.g. inside $(...)) so that the
 // interactive UI is shown on the terminal instead of being swallowed.
-func SelectOnTTY(message string, options []string, defaultOption string) (int, string, error) {
+func SelectOnTTY(message string, options []string, defaultOption string, opts ...SelectOption) (int, string, error) {
 	if len(options) == 0 {
 		return -1, "", fmt.Errorf("no options to select from")
 	}
@@ -84,9 +112,12 @@ func SelectOnTTY(message string, options []string, defaultOption string) (int, s
 	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
 	if err != nil {
 		// Fallback to normal select if /dev/tty is unavailable
-		return Select(message, options, defaultOption)
+		return Select(message, options, defaultOption, opts...)
 	}
 	defer tty.Close()
+	if ui.Accessible() {
+		return ttyMenu(tty).selectOne(message, options, defaultOption, configuredMatcher(), previewOf(opts))
+	}
 
 	var selected string
 	p := &survey.Select{
@@ -102,8 +133,9 @@ func SelectOnTTY(message string, options []string, defaultOption string) (int, s
 		}
 	}
 
-	err = survey.AskOne(p, &selected,
-		survey.WithFilter(fuzzyFilter),
+	defer applySelectOptions(p, opts)()
+
+	err = askOne(message, &rankedSelect{Select: p, matcher: configuredMatcher(), keymap: configuredKeymap()}, &selected,
 		survey.WithStdio(tty, tty, tty),
 	)
 	if err != nil {
@@ -118,29 +150,14 @@ func SelectOnTTY(message string, options []string, defaultOption string) (int, s
 	return -1, selected, nil
 }
 
-// fuzzyFilter implements fuzzy matching for survey prompts.
-// It matches if all characters in the filter appear in order in the option.
-func fuzzyFilter(filter string, option string, index int) bool {
-	if filter == "" {
-		return true
-	}
-
-	filter = strings.ToLower(filter)
-	option = strings.ToLower(option)
-
-	// Simple fuzzy matching: all characters in filter must appear in order in option
-	filterIdx := 0
-	for i := 0; i < len(option) && filterIdx < len(filter); i++ {
-		if option[i] == filter[filterIdx] {
-			filterIdx++
-		}
-	}
-
-	return filterIdx == len(filter)
-}
-
 // Input prompts the user for text input.
 func Input(message string, defaultVal string, required bool) (string, error) {
+	if err := checkPlain(); err != nil {
+		return "", err
+	}
+	if ui.Accessible() {
+		return stdMenu().input(message, defaultVal, required, nil)
+	}
 	var result string
 	prompt := &survey.Input{
 		Message: message,
@@ -148,36 +165,137 @@ func Input(message string, defaultVal string, required bool) (string, error) {
 	}
 	var err error
 	if required {
-		err = survey.AskOne(prompt, &result, survey.WithValidator(survey.Required))
+		err = askOne(message, prompt, &result, survey.WithValidator(survey.Required))
 	} else {
-		err = survey.AskOne(prompt, &result)
+		err = askOne(message, prompt, &result)
+	}
+	return result, err
+}
+
+// InputWithSuggestions is Input offering completions while typing: Tab lists
+// suggest(typed so far), e.g. branch names or recent Jira keys.
+func InputWithSuggestions(message string, defaultVal string, required bool, suggest func(toComplete string) []string) (string, error) {
+	if err := checkPlain(); err != nil {
+		return "", err
+	}
+	if ui.Accessible() {
+		return stdMenu().input(message, defaultVal, required, suggest)
+	}
+	var result string
+	prompt := &survey.Input{
+		Message: message,
+		Default: defaultVal,
+		Suggest: suggest,
+	}
+	var opts []survey.AskOpt
+	if required {
+		opts = append(opts, survey.WithValidator(survey.Required))
+	}
+	err := askOne(message, prompt, &result, opts...)
+	return result, err
+}
+
+// CompleteWords returns a suggest function for InputWithSuggestions completing
+// the word being typed, after the last space or '/', with the words it's a
+// case-insensitive prefix of: "feature/ab" completes to "feature/ABC-123".
+func CompleteWords(words []string) func(toComplete string) []string {
+	return func(toComplete string) []string {
+		start := strings.LastIndexAny(toComplete, " /") + 1
+		typed := strings.ToLower(toComplete[start:])
+		var suggestions []string
+		seen := map[string]bool{}
+		for _, word := range words {
+			if seen[word] || !strings.HasPrefix(strings.ToLower(word), typed) {
+				continue
+			}
+			seen[word] = true
+			suggestions = append(suggestions, toComplete[:start]+word)
+		}
+		return suggestions
+	}
+}
+
+// Password prompts the user for a required value without echoing it.
+func Password(message string) (string, error) {
+	if err := checkPlain(); err != nil {
+		return "", err
+	}
+	if ui.Accessible() {
+		return stdMenu().password(message)
+	}
+	var result string
+	prompt := &survey.Password{
+		Message: message,
 	}
+	err := askOne(message, prompt, &result, survey.WithValidator(survey.Required))
 	return result, err
 }
 
 // Confirm prompts the user for a yes/no confirmation.
 func Confirm(message string, defaultVal bool) (bool, error) {
+	if err := checkPlain(); err != nil {
+		return false, err
+	}
+	if ui.Accessible() {
+		return stdMenu().confirm(message, defaultVal)
+	}
 	var result bool
 	prompt := &survey.Confirm{
 		Message: message,
 		Default: defaultVal,
 	}
-	err := survey.AskOne(prompt, &result)
+	err := askOne(message, prompt, &result)
 	return result, err
 }
 
 // MultiSelect prompts the user to select multiple options from a list.
-func MultiSelect(message string, options []string, defaults []string) ([]string, error) {
+// Besides space, <right> selects all the filtered options, <left> none and
+// ctrl-r inverts their selection; WithMin and WithMax bound the selection.
+func MultiSelect(message string, options []string, defaults []string, opts ...MultiSelectOption) ([]string, error) {
+	if err := checkPlain(); err != nil {
+		return nil, err
+	}
+	if ui.Accessible() {
+		return stdMenu().selectMany(newMultiSelect(message, options, defaults, opts))
+	}
 	var result []string
-	prompt := &survey.MultiSelect{
-		Message: message,
-		Options: options,
-		Default: defaults,
+	err := askOne(message, newMultiSelect(message, options, defaults, opts), &result)
+	return result, err
+}
+
+// MultiSelectOnTTY is like MultiSelect but renders on /dev/tty, so it can be
+// used by commands whose stdout is captured (e.g. by a shell wrapper).
+func MultiSelectOnTTY(message string, options []string, opts ...MultiSelectOption) ([]string, error) {
+	if len(options) == 0 {
+		return nil, fmt.Errorf("no options to select from")
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		// Fallback to normal multi-select if /dev/tty is unavailable
+		return MultiSelect(message, options, nil, opts...)
 	}
-	err := survey.AskOne(prompt, &result)
+	defer tty.Close()
+	if ui.Accessible() {
+		return ttyMenu(tty).selectMany(newMultiSelect(message, options, nil, opts))
+	}
+
+	var result []string
+	err = askOne(message, newMultiSelect(message, options, nil, opts), &result,
+		survey.WithStdio(tty, tty, tty),
+	)
 	return result, err
 }
 
+// newMultiSelect returns the multi-select prompt with opts applied.
+func newMultiSelect(message string, options []string, defaults []string, opts []MultiSelectOption) *multiSelect {
+	m := &multiSelect{message: message, options: options, defaults: defaults, matcher: configuredMatcher(), keymap: configuredKeymap()}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
 // ShouldUseInteractive checks if interactive mode should be used.
 // Returns true if:
 //   - We're in a TTY (terminal), AND
@@ -219,7 +337,7 @@ func SelectCommand(c *cli.Context, commands []*cli.Command, message string, onCa
 	}
 
 	// Check if we're in a TTY - if not, show help
-	if !term.IsTerminal(int(os.Stdin.Fd())) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || ui.Plain() {
 		if onCancel != nil {
 			return onCancel(c)
 		}
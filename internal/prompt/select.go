@@ -0,0 +1,171 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/core"
+	"github.com/AlecAivazis/survey/v2/terminal"
+)
+
+// rankedSelect is survey's Select listing the options matching the filter in
+// the order of a Matcher's scores, as survey's Filter can only keep or drop
+// them. The prompt's fields (message, options, default, description) and the
+// global select template are survey's, so previews work the same.
+type rankedSelect struct {
+	*survey.Select
+	matcher Matcher
+	keymap  Keymap
+
+	filter        string
+	selectedIndex int
+	showingHelp   bool
+	hidePreview   bool
+}
+
+// filtered returns the options matching the filter typed so far, best first.
+func (s *rankedSelect) filtered() []core.OptionAnswer {
+	return rank(s.matcher, s.filter, s.Options)
+}
+
+// render draws the prompt with the options of the current page.
+func (s *rankedSelect) render(config *survey.PromptConfig) error {
+	s.FilterMessage = ""
+	if s.filter != "" {
+		s.FilterMessage = " " + s.filter
+	}
+	opts, idx := paginate(config.PageSize, s.filtered(), s.selectedIndex)
+	description := s.Description
+	if s.hidePreview {
+		description = nil
+	}
+	return s.RenderWithCursorOffset(s.template(), survey.SelectTemplateData{
+		Select:        *s.Select,
+		SelectedIndex: idx,
+		ShowHelp:      s.showingHelp,
+		Description:   description,
+		PageEntries:   opts,
+		Config:        config,
+	}, opts, idx)
+}
+
+// template is the global select template, its help naming the keys that
+// move when they were rebound (prompt.keys).
+func (s *rankedSelect) template() string {
+	if !s.keymap.Rebound(ActionUp, ActionDown) {
+		return survey.SelectQuestionTemplate
+	}
+	move := fmt.Sprintf("Use %s/%s to move", s.keymap.Describe(ActionUp), s.keymap.Describe(ActionDown))
+	return strings.Replace(survey.SelectQuestionTemplate, "Use arrows to move", move, 1)
+}
+
+// onKey updates the state for key and reports whether the prompt is done.
+func (s *rankedSelect) onKey(key rune, config *survey.PromptConfig) bool {
+	options := s.filtered()
+	oldFilter := s.filter
+
+	action := s.keymap.Action(key)
+	switch {
+	case action == ActionAccept:
+		return len(options) > 0
+	case action == ActionUp:
+		if len(options) > 0 {
+			s.selectedIndex--
+			if s.selectedIndex < 0 {
+				s.selectedIndex = len(options) - 1
+			}
+		}
+	case action == ActionDown:
+		if len(options) > 0 {
+			s.selectedIndex++
+			if s.selectedIndex >= len(options) {
+				s.selectedIndex = 0
+			}
+		}
+	case action == ActionPreview && s.Description != nil:
+		s.hidePreview = !s.hidePreview
+	case string(key) == config.HelpInput && s.Help != "":
+		s.showingHelp = true
+	case action == ActionClear:
+		s.filter = ""
+	case key == terminal.KeyDelete || key == terminal.KeyBackspace:
+		if s.filter != "" {
+			runes := []rune(s.filter)
+			s.filter = string(runes[:len(runes)-1])
+		}
+	case key >= terminal.KeySpace:
+		s.filter += string(key)
+	}
+
+	if oldFilter != s.filter {
+		// The best match comes first: highlight it
+		s.selectedIndex = 0
+	}
+	return false
+}
+
+// Prompt implements survey.Prompt.
+func (s *rankedSelect) Prompt(config *survey.PromptConfig) (interface{}, error) {
+	if len(s.Options) == 0 {
+		return nil, errors.New("no options to select from")
+	}
+	s.selectedIndex = 0
+	if def, ok := s.Default.(string); ok {
+		for i, opt := range s.Options {
+			if opt == def {
+				s.selectedIndex = i
+				break
+			}
+		}
+	}
+
+	cursor := s.NewCursor()
+	cursor.Save()          // for proper cursor placement during selection
+	cursor.Hide()          // hide the cursor
+	defer cursor.Show()    // show the cursor when we're done
+	defer cursor.Restore() // clear any accessibility offsetting on exit
+	if err := s.render(config); err != nil {
+		return nil, err
+	}
+
+	rr := s.NewRuneReader()
+	_ = rr.SetTermMode()
+	defer func() {
+		_ = rr.RestoreTermMode()
+	}()
+	for {
+		r, _, err := rr.ReadRune()
+		if err != nil {
+			return nil, err
+		}
+		if r == terminal.KeyInterrupt || s.keymap.Action(r) == ActionCancel {
+			return nil, terminal.InterruptErr
+		}
+		if s.onKey(r, config) {
+			break
+		}
+		_ = s.render(config)
+	}
+
+	options := s.filtered()
+	s.filter, s.FilterMessage = "", ""
+	if s.selectedIndex < len(options) {
+		return options[s.selectedIndex], nil
+	}
+	return options[0], nil
+}
+
+// Cleanup implements survey.Prompt: it replaces the list with the answer.
+func (s *rankedSelect) Cleanup(config *survey.PromptConfig, val interface{}) error {
+	cursor := s.NewCursor()
+	cursor.Restore()
+	return s.Render(s.template(), survey.SelectTemplateData{
+		Select:      *s.Select,
+		Answer:      val.(core.OptionAnswer).Value,
+		ShowAnswer:  true,
+		Description: s.Description,
+		Config:      config,
+	})
+}
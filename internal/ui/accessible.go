@@ -0,0 +1,25 @@
+package ui
+
+import "os"
+
+// AccessibleEnv turns accessible mode on when set to a non-empty value.
+const AccessibleEnv = "AIO_A11Y"
+
+// accessibleOverride is set by SetAccessible from the global flags and config.
+var accessibleOverride *bool
+
+// SetAccessible forces accessible mode on or off for the rest of the run.
+func SetAccessible(on bool) {
+	accessibleOverride = &on
+}
+
+// Accessible reports whether output must suit screen readers and dumb
+// terminals: prompts are numbered menus read line by line, and nothing is
+// colored, animated or redrawn. It is on with --a11y, AIO_A11Y,
+// prompt.accessible in config.json, or on a dumb terminal.
+func Accessible() bool {
+	if accessibleOverride != nil {
+		return *accessibleOverride
+	}
+	return os.Getenv("TERM") == "dumb"
+}
@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"os"
+)
+
+// ANSI color codes used across commands.
+const (
+	Reset  = "\033[0m"
+	Bold   = "\033[1m"
+	Dim    = "\033[2m"
+	Red    = "\033[31m"
+	Green  = "\033[32m"
+	Yellow = "\033[33m"
+	Blue   = "\033[34m"
+	Cyan   = "\033[36m"
+	Gray   = "\033[90m"
+)
+
+// ColorEnabled reports whether stdout is a terminal that should receive colors.
+// Colors are disabled in plain and accessible modes or when NO_COLOR is set.
+func ColorEnabled() bool {
+	if os.Getenv("NO_COLOR") != "" || Accessible() {
+		return false
+	}
+	return !Plain()
+}
+
+// Colorize wraps s in the given color code when colors are enabled.
+func Colorize(color string, s string) string {
+	if !ColorEnabled() {
+		return s
+	}
+	return color + s + Reset
+}
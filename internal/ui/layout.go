@@ -0,0 +1,291 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// Ellipsis marks the text Truncate and its variants cut out.
+const Ellipsis = "…"
+
+// Width returns the number of columns of the terminal: $COLUMNS when set,
+// else the size of the first of stdout, stderr and stdin that is a terminal,
+// so a command whose stdout is captured by a shell wrapper still fits the
+// screen. 0 means unknown, e.g. all three are redirected: don't truncate.
+func Width() int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	for _, f := range []*os.File{os.Stdout, os.Stderr, os.Stdin} {
+		if w, _, err := term.GetSize(int(f.Fd())); err == nil && w > 0 {
+			return w
+		}
+	}
+	return 0
+}
+
+// OutputWidth is Width for lines printed to stdout: 0 (no limit) in plain
+// mode, where the output goes to a program that wants whole values.
+func OutputWidth() int {
+	if Plain() {
+		return 0
+	}
+	return Width()
+}
+
+// TextWidth returns the number of columns s takes on the terminal: ANSI
+// escape codes take none, combining marks none and wide (CJK, emoji) runes two.
+func TextWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		width += runeWidth(r)
+		i += size
+	}
+	return width
+}
+
+// Truncate shortens s to width columns, replacing its end with an ellipsis.
+// Escape codes are kept, and colors are reset after the ellipsis.
+func Truncate(s string, width int) string {
+	if width <= 0 || TextWidth(s) <= width {
+		return s
+	}
+	head, colored := cut(s, width-1)
+	if colored {
+		return head + Ellipsis + Reset
+	}
+	return head + Ellipsis
+}
+
+// TruncateMiddle shortens s to width columns, replacing its middle with an
+// ellipsis, for values told apart by both ends like branch names:
+// feature/ABC-123-…-form.
+func TruncateMiddle(s string, width int) string {
+	if width <= 0 || TextWidth(s) <= width {
+		return s
+	}
+	if width < 3 {
+		return Truncate(s, width)
+	}
+	plain := stripEscapes(s)
+	keep := width - 1
+	head, _ := cut(plain, (keep+1)/2)
+	tail := plain
+	for TextWidth(tail) > keep/2 {
+		_, size := utf8.DecodeRuneInString(tail)
+		tail = tail[size:]
+	}
+	return head + Ellipsis + tail
+}
+
+// TruncatePath shortens path to width columns, replacing whole directories
+// after the first one with an ellipsis so the last element stays readable:
+// ~/work/…/payment-api. Falls back to TruncateMiddle when that isn't enough.
+func TruncatePath(path string, width int) string {
+	if width <= 0 || TextWidth(path) <= width {
+		return path
+	}
+	sep := string(filepath.Separator)
+	parts := strings.Split(path, sep)
+	if len(parts) > 3 {
+		first, last := parts[0]+sep+parts[1], parts[len(parts)-1]
+		// Drop directories from the middle, keeping the ones near the end
+		for i := 2; i < len(parts)-1; i++ {
+			short := strings.Join(append([]string{first, Ellipsis}, parts[i+1:]...), sep)
+			if TextWidth(short) <= width {
+				return short
+			}
+		}
+		if short := first + sep + Ellipsis + sep + last; TextWidth(short) <= width {
+			return short
+		}
+		if short := Ellipsis + sep + last; TextWidth(short) <= width {
+			return short
+		}
+	}
+	return TruncateMiddle(path, width)
+}
+
+// Pad pads s with spaces to width columns, measured with TextWidth so colors
+// and wide runes don't break the alignment as they do with %-*s.
+func Pad(s string, width int) string {
+	if n := width - TextWidth(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+// PadLeft is Pad aligning s to the right.
+func PadLeft(s string, width int) string {
+	if n := width - TextWidth(s); n > 0 {
+		return strings.Repeat(" ", n) + s
+	}
+	return s
+}
+
+// Column describes a column of Table.
+type Column struct {
+	// Shrink truncates cells when the lines are too wide, e.g. Truncate or
+	// TruncatePath; nil keeps the column at its full width.
+	Shrink func(s string, width int) string
+	// Min is the width the column is never shrunk below (default 8).
+	Min int
+	// Right aligns the cells to the right, for numbers.
+	Right bool
+}
+
+// columnGap separates the columns of Table.
+const columnGap = "  "
+
+// Table lays rows out in columns separated by two spaces and aligned on the
+// widest cell, shrinking the widest shrinkable columns until the lines fit in
+// width (0 for no limit). The last column isn't padded. columns may be shorter
+// than the rows: the extra columns never shrink.
+func Table(rows [][]string, columns []Column, width int) []string {
+	widths := []int{}
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], TextWidth(cell))
+		}
+	}
+	column := func(i int) Column {
+		if i < len(columns) {
+			return columns[i]
+		}
+		return Column{}
+	}
+
+	if width > 0 {
+		total := len(columnGap) * max(len(widths)-1, 0)
+		for _, w := range widths {
+			total += w
+		}
+		for total > width {
+			widest := -1
+			for i, w := range widths {
+				col := column(i)
+				minWidth := col.Min
+				if minWidth == 0 {
+					minWidth = 8
+				}
+				if col.Shrink != nil && w > minWidth && (widest < 0 || w > widths[widest]) {
+					widest = i
+				}
+			}
+			if widest < 0 {
+				break
+			}
+			widths[widest]--
+			total--
+		}
+	}
+
+	lines := make([]string, len(rows))
+	for r, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			col := column(i)
+			if col.Shrink != nil {
+				cell = col.Shrink(cell, widths[i])
+			}
+			if i > 0 {
+				line.WriteString(columnGap)
+			}
+			switch {
+			case col.Right:
+				cell = PadLeft(cell, widths[i])
+			case i < len(row)-1:
+				cell = Pad(cell, widths[i])
+			}
+			line.WriteString(cell)
+		}
+		lines[r] = line.String()
+	}
+	return lines
+}
+
+// cut returns the longest prefix of s at most width columns wide, with its
+// escape codes, and whether it has any.
+func cut(s string, width int) (string, bool) {
+	used, colored := 0, false
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			i += n
+			colored = true
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if used+runeWidth(r) > width {
+			return s[:i], colored
+		}
+		used += runeWidth(r)
+		i += size
+	}
+	return s, colored
+}
+
+// stripEscapes removes the ANSI escape codes from s.
+func stripEscapes(s string) string {
+	if !strings.Contains(s, "\033") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
+
+// escapeLen returns the length of the ANSI escape sequence (ESC [ ... letter)
+// s starts with, 0 if it doesn't start with one.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != '\033' || s[1] != '[' {
+		return 0
+	}
+	for i := 2; i < len(s); i++ {
+		if c := s[i]; c >= '@' && c <= '~' {
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// runeWidth returns the number of columns r takes: 0 for combining marks and
+// control characters, 2 for East Asian wide runes and emoji, 1 otherwise.
+func runeWidth(r rune) int {
+	switch {
+	case r < 0x20 || r == 0x7f || unicode.Is(unicode.Mn, r) || r == 0x200b:
+		return 0
+	case r >= 0x1100 && r <= 0x115f, // Hangul Jamo
+		r >= 0x2e80 && r <= 0xa4cf && r != 0x303f, // CJK ... Yi
+		r >= 0xac00 && r <= 0xd7a3,                // Hangul syllables
+		r >= 0xf900 && r <= 0xfaff,                // CJK compatibility ideographs
+		r >= 0xfe30 && r <= 0xfe4f,                // CJK compatibility forms
+		r >= 0xff00 && r <= 0xff60,                // Fullwidth forms
+		r >= 0xffe0 && r <= 0xffe6,
+		r >= 0x1f300 && r <= 0x1f64f, // Emoji
+		r >= 0x1f900 && r <= 0x1f9ff,
+		r >= 0x20000 && r <= 0x3fffd:
+		return 2
+	}
+	return 1
+}
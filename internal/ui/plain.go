@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"golang.org/x/term"
+)
+
+// PlainEnv forces plain mode when set to a true value (1, t, true...), read
+// like the --plain flag it backs.
+const PlainEnv = "AIO_PLAIN"
+
+// plainOverride is set by SetPlain from the global flags; nil follows stdout.
+var plainOverride *bool
+
+// SetPlain forces plain mode on or off for the rest of the run.
+func SetPlain(on bool) {
+	plainOverride = &on
+}
+
+// Plain reports whether output is consumed by a program rather than a person:
+// stdout is piped or redirected, or plain mode was forced with --plain or
+// AIO_PLAIN. Plain mode means no colors, no interactive prompts and bare
+// values for single-value outputs.
+func Plain() bool {
+	if plainOverride != nil {
+		return *plainOverride
+	}
+	if on, err := strconv.ParseBool(os.Getenv(PlainEnv)); err == nil && on {
+		return true
+	}
+	return !term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// PrintValue prints a single value, labelled for people ("Label: value") and
+// bare in plain mode so scripts can capture it directly.
+func PrintValue(label string, value string) {
+	if Plain() {
+		fmt.Println(value)
+		return
+	}
+	fmt.Printf("%s: %s\n", label, value)
+}